@@ -0,0 +1,293 @@
+// Package archive publishes every order event emitted by a Mesh node to an
+// external message queue (e.g. Kafka or NATS) so that downstream data
+// pipelines can consume them without using the JSON-RPC API. Events are
+// buffered in memory and each batch is retried until the message queue has
+// acknowledged it, which means that every event is delivered at least once as
+// long as Mesh keeps running and the buffer doesn't overflow. Consumers should
+// be prepared to receive duplicates. Support for other message queues can be
+// added by implementing the Sink interface.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/jpillora/backoff"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultMaxBufferedEvents is the default value for
+	// Config.MaxBufferedEvents.
+	defaultMaxBufferedEvents = 100000
+	// defaultMaxBatchSize is the default value for Config.MaxBatchSize.
+	defaultMaxBatchSize = 500
+	// publishTimeout is the maximum amount of time to wait for a single batch
+	// to be acknowledged.
+	publishTimeout = 30 * time.Second
+)
+
+// Message is a single message that is published to a Sink.
+type Message struct {
+	// Topic is the Kafka topic or NATS subject the message is published to.
+	Topic string
+	// Key determines the partition of the message in message queues which
+	// support partitioning. It is the hash of the order, so that all events
+	// for an order are delivered in order.
+	Key string
+	// ID uniquely identifies the message. Message queues which support it use
+	// the ID to discard duplicates when a batch is retried.
+	ID string
+	// Value is the JSON encoded order event.
+	Value []byte
+}
+
+// Sink publishes messages to a message queue.
+type Sink interface {
+	// Publish publishes the given messages. It must only return nil once the
+	// message queue has acknowledged every message. If it returns an error,
+	// the same messages will be published again later.
+	Publish(ctx context.Context, messages []*Message) error
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// Config contains configuration options for an Archiver.
+type Config struct {
+	// Sink is the sink that order events are published to.
+	Sink Sink
+	// TopicTemplate is the topic that order events are published to. It may
+	// contain the placeholders "{chainId}" and "{endState}" (e.g. "ADDED" or
+	// "FILLED"), which are replaced for each event so that events can be
+	// split across several topics.
+	TopicTemplate string
+	// ChainID is the chain ID of the Mesh node.
+	ChainID int
+	// MaxBufferedEvents is the maximum number of events that can be waiting to
+	// be published. If the message queue is unavailable for long enough that
+	// the buffer fills up, the oldest events are dropped. If 0, a default of
+	// 100,000 is used.
+	MaxBufferedEvents int
+	// MaxBatchSize is the maximum number of events that are published at
+	// once. If 0, a default of 500 is used.
+	MaxBatchSize int
+}
+
+// Stats contains statistics about an Archiver.
+type Stats struct {
+	// Buffered is the number of events that are waiting to be published.
+	Buffered int
+	// Published is the total number of events that have been acknowledged by
+	// the message queue.
+	Published uint64
+	// Dropped is the total number of events that were dropped because the
+	// buffer was full.
+	Dropped uint64
+}
+
+// Archiver publishes order events to a Sink.
+type Archiver struct {
+	config Config
+	mu     sync.Mutex
+	// buffered contains the events that have not been acknowledged yet, in
+	// the order they were emitted.
+	buffered []*zeroex.OrderEvent
+	// offset is the total number of events that have been removed from the
+	// front of buffered, either because they were acknowledged or dropped.
+	offset    uint64
+	published uint64
+	dropped   uint64
+	// notify has a buffer of 1 and receives a value whenever events are added
+	// to the buffer.
+	notify chan struct{}
+}
+
+// New creates and returns a new Archiver with the given config.
+func New(config Config) (*Archiver, error) {
+	if config.Sink == nil {
+		return nil, errors.New("config.Sink is required")
+	}
+	if config.TopicTemplate == "" {
+		return nil, errors.New("config.TopicTemplate is required")
+	}
+	if config.MaxBufferedEvents < 0 || config.MaxBatchSize < 0 {
+		return nil, errors.New("config.MaxBufferedEvents and config.MaxBatchSize cannot be negative")
+	}
+	if config.MaxBufferedEvents == 0 {
+		config.MaxBufferedEvents = defaultMaxBufferedEvents
+	}
+	if config.MaxBatchSize == 0 {
+		config.MaxBatchSize = defaultMaxBatchSize
+	}
+	return &Archiver{
+		config: config,
+		notify: make(chan struct{}, 1),
+	}, nil
+}
+
+// Run publishes the order events received from the given channel until ctx is
+// canceled. Events are received as fast as they arrive so that a slow or
+// unavailable message queue never blocks the sender. The sink is closed before
+// Run returns.
+func (a *Archiver) Run(ctx context.Context, orderEvents <-chan []*zeroex.OrderEvent) {
+	defer func() {
+		if err := a.config.Sink.Close(); err != nil {
+			log.WithError(err).Warn("could not close archive sink")
+		}
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case events := <-orderEvents:
+				a.add(events)
+			}
+		}
+	}()
+	defer wg.Wait()
+
+	retryBackoff := &backoff.Backoff{
+		Min:    250 * time.Millisecond,
+		Max:    1 * time.Minute,
+		Factor: 2,
+	}
+	for {
+		batch, batchOffset := a.nextBatch()
+		if len(batch) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.notify:
+				continue
+			}
+		}
+		if err := a.publish(ctx, batch); err != nil {
+			delay := retryBackoff.Duration()
+			log.WithFields(log.Fields{
+				"error":                err.Error(),
+				"numEvents":            len(batch),
+				"delayBeforeNextRetry": delay.String(),
+			}).Warn("could not publish order events to archive sink (trying again soon)")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+				continue
+			}
+		}
+		retryBackoff.Reset()
+		a.remove(batchOffset + uint64(len(batch)))
+	}
+}
+
+// Stats returns statistics about the Archiver.
+func (a *Archiver) Stats() Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Stats{
+		Buffered:  len(a.buffered),
+		Published: a.published,
+		Dropped:   a.dropped,
+	}
+}
+
+// add adds events to the buffer, dropping the oldest buffered events if it is
+// full.
+func (a *Archiver) add(events []*zeroex.OrderEvent) {
+	a.mu.Lock()
+	a.buffered = append(a.buffered, events...)
+	overflow := len(a.buffered) - a.config.MaxBufferedEvents
+	if overflow > 0 {
+		a.buffered = a.buffered[overflow:]
+		a.offset += uint64(overflow)
+		a.dropped += uint64(overflow)
+	}
+	a.mu.Unlock()
+	if overflow > 0 {
+		log.WithFields(log.Fields{
+			"numDropped":        overflow,
+			"maxBufferedEvents": a.config.MaxBufferedEvents,
+		}).Error("dropped order events because the archive buffer is full")
+	}
+	select {
+	case a.notify <- struct{}{}:
+	default:
+	}
+}
+
+// nextBatch returns up to MaxBatchSize of the oldest buffered events along
+// with the offset of the first of them.
+func (a *Archiver) nextBatch() ([]*zeroex.OrderEvent, uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := len(a.buffered)
+	if n > a.config.MaxBatchSize {
+		n = a.config.MaxBatchSize
+	}
+	batch := make([]*zeroex.OrderEvent, n)
+	copy(batch, a.buffered)
+	return batch, a.offset
+}
+
+// remove removes the acknowledged events before the given offset from the
+// buffer. Some of them may already have been dropped if the buffer overflowed
+// while they were being published.
+func (a *Archiver) remove(end uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if end <= a.offset {
+		return
+	}
+	n := end - a.offset
+	if n > uint64(len(a.buffered)) {
+		n = uint64(len(a.buffered))
+	}
+	a.buffered = a.buffered[n:]
+	a.offset += n
+	a.published += n
+}
+
+// publish converts the given events to messages and publishes them.
+func (a *Archiver) publish(ctx context.Context, events []*zeroex.OrderEvent) error {
+	messages := make([]*Message, len(events))
+	for i, event := range events {
+		message, err := a.newMessage(event)
+		if err != nil {
+			return err
+		}
+		messages[i] = message
+	}
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	return a.config.Sink.Publish(ctx, messages)
+}
+
+func (a *Archiver) newMessage(event *zeroex.OrderEvent) (*Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	topic := strings.NewReplacer(
+		"{chainId}", strconv.Itoa(a.config.ChainID),
+		"{endState}", string(event.EndState),
+	).Replace(a.config.TopicTemplate)
+	orderHash := event.OrderHash.Hex()
+	return &Message{
+		Topic: topic,
+		Key:   orderHash,
+		ID:    fmt.Sprintf("%s-%s-%d", orderHash, event.EndState, event.Timestamp.UnixNano()),
+		Value: value,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink records published messages. It fails the first failures calls to
+// Publish.
+type fakeSink struct {
+	mu        sync.Mutex
+	failures  int
+	calls     int
+	published []*Message
+	closed    bool
+}
+
+func (s *fakeSink) Publish(ctx context.Context, messages []*Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("fake sink failure")
+	}
+	s.published = append(s.published, messages...)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) publishedMessages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message{}, s.published...)
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	_, err := New(Config{TopicTemplate: "mesh"})
+	assert.Error(t, err)
+	_, err = New(Config{Sink: &fakeSink{}})
+	assert.Error(t, err)
+	_, err = New(Config{Sink: &fakeSink{}, TopicTemplate: "mesh", MaxBatchSize: -1})
+	assert.Error(t, err)
+}
+
+func TestArchiverPublishesEvents(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	archiver, err := New(Config{
+		Sink:          sink,
+		TopicTemplate: "mesh.{chainId}.{endState}",
+		ChainID:       1337,
+		MaxBatchSize:  2,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	orderEvents := make(chan []*zeroex.OrderEvent)
+	done := make(chan struct{})
+	go func() {
+		archiver.Run(ctx, orderEvents)
+		close(done)
+	}()
+
+	events := []*zeroex.OrderEvent{
+		newTestOrderEvent(1, zeroex.ESOrderAdded),
+		newTestOrderEvent(2, zeroex.ESOrderFilled),
+		newTestOrderEvent(3, zeroex.ESOrderCancelled),
+	}
+	orderEvents <- events
+
+	// The first two attempts fail, so this also checks that failed batches are
+	// retried.
+	var messages []*Message
+	deadline := time.Now().Add(5 * time.Second)
+	for len(messages) < len(events) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for events to be published")
+		}
+		time.Sleep(10 * time.Millisecond)
+		messages = sink.publishedMessages()
+	}
+	require.Len(t, messages, len(events))
+	for i, message := range messages {
+		orderHash := events[i].OrderHash.Hex()
+		assert.Equal(t, "mesh.1337."+string(events[i].EndState), message.Topic)
+		assert.Equal(t, orderHash, message.Key)
+		assert.Contains(t, message.ID, orderHash)
+		assert.Contains(t, string(message.Value), orderHash)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+	assert.Equal(t, Stats{Published: 3}, archiver.Stats())
+	assert.True(t, sink.closed)
+}
+
+func TestArchiverDropsOldestEventsWhenBufferIsFull(t *testing.T) {
+	archiver, err := New(Config{
+		Sink:              &fakeSink{},
+		TopicTemplate:     "mesh",
+		MaxBufferedEvents: 2,
+	})
+	require.NoError(t, err)
+
+	events := []*zeroex.OrderEvent{
+		newTestOrderEvent(1, zeroex.ESOrderAdded),
+		newTestOrderEvent(2, zeroex.ESOrderAdded),
+		newTestOrderEvent(3, zeroex.ESOrderAdded),
+	}
+	archiver.add(events[:1])
+	batch, offset := archiver.nextBatch()
+	assert.Equal(t, events[:1], batch)
+	assert.Equal(t, uint64(0), offset)
+
+	// The event that is being published is dropped when the buffer overflows.
+	archiver.add(events[1:])
+	assert.Equal(t, Stats{Buffered: 2, Dropped: 1}, archiver.Stats())
+
+	// Acknowledging the dropped event must not remove any newer events.
+	archiver.remove(offset + uint64(len(batch)))
+	assert.Equal(t, Stats{Buffered: 2, Dropped: 1}, archiver.Stats())
+	batch, offset = archiver.nextBatch()
+	assert.Equal(t, events[1:], batch)
+	assert.Equal(t, uint64(1), offset)
+	archiver.remove(offset + uint64(len(batch)))
+	assert.Equal(t, Stats{Published: 2, Dropped: 1}, archiver.Stats())
+}
+
+func newTestOrderEvent(orderHash int64, endState zeroex.OrderEventEndState) *zeroex.OrderEvent {
+	return &zeroex.OrderEvent{
+		Timestamp: time.Unix(orderHash, 0),
+		OrderHash: common.BigToHash(big.NewInt(orderHash)),
+		EndState:  endState,
+	}
+}
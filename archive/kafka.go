@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const (
+	// kafkaWriteTimeout is the deadline for writing a batch of messages to a
+	// broker and for reading its response.
+	kafkaWriteTimeout = 10 * time.Second
+	// kafkaDialTimeout is the maximum amount of time to wait for a connection
+	// to a broker.
+	kafkaDialTimeout = 10 * time.Second
+	// kafkaMaxBatchBytes is the maximum size of the messages which are sent to
+	// a single partition in one request.
+	kafkaMaxBatchBytes = 1024 * 1024
+	// kafkaMsgIDHeader is the header which contains Message.ID.
+	kafkaMsgIDHeader = "Mesh-Msg-Id"
+)
+
+// KafkaConfig determines how a KafkaSink connects to Kafka.
+type KafkaConfig struct {
+	// Brokers are the addresses (e.g. "localhost:9092") of the Kafka brokers
+	// used to discover the cluster.
+	Brokers []string
+	// TLS enables TLS for connections to the brokers.
+	TLS bool
+	// Username and Password, if not empty, are used to authenticate with
+	// SASL/PLAIN.
+	Username string
+	Password string
+}
+
+// KafkaSink publishes messages to Kafka. Messages are partitioned by their key
+// and are acknowledged once all in-sync replicas have stored them. Writes are
+// synchronous, so at most one batch is buffered by the Kafka client at a time.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// Ensure that KafkaSink implements Sink.
+var _ Sink = &KafkaSink{}
+
+// NewKafkaSink creates a new KafkaSink with the given config. Connections to
+// the brokers are opened lazily.
+func NewKafkaSink(config KafkaConfig) (*KafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("at least one Kafka broker is required")
+	}
+	transport := &kafka.Transport{
+		DialTimeout: kafkaDialTimeout,
+	}
+	if config.TLS {
+		transport.TLS = &tls.Config{}
+	}
+	if config.Username != "" {
+		transport.SASL = plain.Mechanism{
+			Username: config.Username,
+			Password: config.Password,
+		}
+	}
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(config.Brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// The archiver already batches messages, so they don't need to be
+			// held back by the writer.
+			BatchSize:    defaultMaxBatchSize,
+			BatchBytes:   kafkaMaxBatchBytes,
+			BatchTimeout: time.Millisecond,
+			WriteTimeout: kafkaWriteTimeout,
+			ReadTimeout:  kafkaWriteTimeout,
+			Transport:    transport,
+		},
+	}, nil
+}
+
+// Publish implements Sink.
+func (s *KafkaSink) Publish(ctx context.Context, messages []*Message) error {
+	return s.writer.WriteMessages(ctx, newKafkaMessages(messages)...)
+}
+
+// Close implements Sink.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+func newKafkaMessages(messages []*Message) []kafka.Message {
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		kafkaMessages[i] = kafka.Message{
+			Topic: message.Topic,
+			Key:   []byte(message.Key),
+			Value: message.Value,
+			Headers: []kafka.Header{
+				{Key: kafkaMsgIDHeader, Value: []byte(message.ID)},
+			},
+		}
+	}
+	return kafkaMessages
+}
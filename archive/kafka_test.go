@@ -0,0 +1,40 @@
+package archive
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKafkaSinkRequiresBrokers(t *testing.T) {
+	_, err := NewKafkaSink(KafkaConfig{})
+	assert.Error(t, err)
+
+	sink, err := NewKafkaSink(KafkaConfig{Brokers: []string{"localhost:9092"}})
+	require.NoError(t, err)
+	assert.NoError(t, sink.Close())
+}
+
+func TestNewKafkaMessages(t *testing.T) {
+	messages := []*Message{
+		{Topic: "mesh.added", Key: "0x1", ID: "id1", Value: []byte(`{"n":1}`)},
+		{Topic: "mesh.filled", Key: "0x2", ID: "id2", Value: []byte(`{"n":2}`)},
+	}
+	expected := []kafka.Message{
+		{
+			Topic:   "mesh.added",
+			Key:     []byte("0x1"),
+			Value:   []byte(`{"n":1}`),
+			Headers: []kafka.Header{{Key: kafkaMsgIDHeader, Value: []byte("id1")}},
+		},
+		{
+			Topic:   "mesh.filled",
+			Key:     []byte("0x2"),
+			Value:   []byte(`{"n":2}`),
+			Headers: []kafka.Header{{Key: kafkaMsgIDHeader, Value: []byte("id2")}},
+		},
+	}
+	assert.Equal(t, expected, newKafkaMessages(messages))
+}
@@ -0,0 +1,145 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATSMessage is a message received by fakeNATSServer.
+type fakeNATSMessage struct {
+	subject string
+	headers string
+	payload string
+}
+
+// fakeNATSServer is a minimal NATS server which accepts a single connection
+// and acknowledges each published message with the reply returned by ack.
+type fakeNATSServer struct {
+	listener net.Listener
+	ack      func(message *fakeNATSMessage) string
+	received chan *fakeNATSMessage
+}
+
+func newFakeNATSServer(t *testing.T, ack func(message *fakeNATSMessage) string) *fakeNATSServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeNATSServer{
+		listener: listener,
+		ack:      ack,
+		received: make(chan *fakeNATSMessage, 100),
+	}
+	go server.serve(t)
+	return server
+}
+
+func (s *fakeNATSServer) url() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+func (s *fakeNATSServer) serve(t *testing.T) {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	_, _ = conn.Write([]byte(`INFO {"server_id":"fake","headers":true,"max_payload":1048576,"proto":1}` + "\r\n"))
+	// replySID is the ID of the subscription that acknowledgements are sent
+	// to.
+	replySID := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT", "PONG", "UNSUB":
+		case "SUB":
+			// SUB <subject> <sid>
+			replySID = fields[len(fields)-1]
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		case "HPUB":
+			// HPUB <subject> <reply-to> <#header bytes> <#total bytes>
+			headerSize, _ := strconv.Atoi(fields[3])
+			totalSize, _ := strconv.Atoi(fields[4])
+			data := make([]byte, totalSize+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			message := &fakeNATSMessage{
+				subject: fields[1],
+				headers: string(data[:headerSize]),
+				payload: string(data[headerSize:totalSize]),
+			}
+			s.received <- message
+			reply := s.ack(message)
+			_, _ = fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", fields[2], replySID, len(reply), reply)
+		default:
+			t.Errorf("unexpected operation: %q", line)
+			return
+		}
+	}
+}
+
+func (s *fakeNATSServer) close() {
+	_ = s.listener.Close()
+}
+
+func TestNATSSink(t *testing.T) {
+	server := newFakeNATSServer(t, func(*fakeNATSMessage) string {
+		return `{"stream":"ORDER_EVENTS","seq":1}`
+	})
+	defer server.close()
+
+	sink, err := NewNATSSink(server.url())
+	require.NoError(t, err)
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	messages := []*Message{
+		{Topic: "mesh.added", ID: "id1", Value: []byte(`{"n":1}`)},
+		{Topic: "mesh.filled", ID: "id2", Value: []byte(`{"n":2}`)},
+	}
+	require.NoError(t, sink.Publish(ctx, messages))
+
+	for _, message := range messages {
+		received := <-server.received
+		assert.Equal(t, message.Topic, received.subject)
+		assert.Contains(t, received.headers, nats.MsgIdHdr+": "+message.ID+"\r\n")
+		assert.Equal(t, string(message.Value), received.payload)
+	}
+}
+
+func TestNATSSinkError(t *testing.T) {
+	server := newFakeNATSServer(t, func(*fakeNATSMessage) string {
+		return `{"error":{"code":503,"description":"insufficient resources"}}`
+	})
+	defer server.close()
+
+	sink, err := NewNATSSink(server.url())
+	require.NoError(t, err)
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = sink.Publish(ctx, []*Message{{Topic: "mesh.added", ID: "id1", Value: []byte(`{}`)}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient resources")
+}
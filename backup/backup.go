@@ -0,0 +1,235 @@
+// Package backup periodically uploads checkpoints of the Mesh database to
+// object storage (e.g. S3 or GCS) and restores them, so that operators can
+// quickly recover a node after losing its disk.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// BackupFileExtension is the extension of the gzip-compressed checkpoints
+	// uploaded by Backuper.
+	BackupFileExtension = ".meshdb.gz"
+	// backupKeyPrefix is the prefix of the key of each backup.
+	backupKeyPrefix = "mesh-db-"
+	// latestKey is the key of the object which contains the key of the most
+	// recent backup. It is used to find the latest backup without listing the
+	// contents of the store.
+	latestKey = "latest.json"
+	// backupKeyTimeFormat is the format of the timestamp in the key of each
+	// backup.
+	backupKeyTimeFormat = "20060102T150405Z"
+)
+
+// CheckpointFunc writes a checkpoint of the database to w.
+type CheckpointFunc func(w io.Writer) error
+
+// RestoreFunc restores the database from the checkpoint in r.
+type RestoreFunc func(r io.Reader) error
+
+// Config is the configuration for a Backuper.
+type Config struct {
+	// Store is where backups are uploaded to.
+	Store Store
+	// Checkpoint writes the checkpoint that is backed up.
+	Checkpoint CheckpointFunc
+	// Interval is how often a backup is made.
+	Interval time.Duration
+	// Retain is the number of most recent backups to keep. Older backups are
+	// deleted after each successful backup. If 0, backups are never deleted.
+	Retain int
+	// TempDir is the directory where checkpoints are written to before being
+	// uploaded. If empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// Backuper periodically uploads a checkpoint of the database to a Store.
+type Backuper struct {
+	config Config
+}
+
+// latestBackup is the content of the object with latestKey.
+type latestBackup struct {
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// New creates a new Backuper with the given config.
+func New(config Config) (*Backuper, error) {
+	if config.Store == nil {
+		return nil, errors.New("backup store is required")
+	}
+	if config.Checkpoint == nil {
+		return nil, errors.New("checkpoint function is required")
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("backup interval must be positive")
+	}
+	if config.Retain < 0 {
+		return nil, errors.New("number of backups to retain must not be negative")
+	}
+	return &Backuper{
+		config: config,
+	}, nil
+}
+
+// Run makes a backup every Interval until ctx is canceled. Failed backups are
+// logged and retried at the next interval.
+func (b *Backuper) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			start := time.Now()
+			key, err := b.Backup(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.WithError(err).Error("could not back up database")
+				continue
+			}
+			log.WithFields(log.Fields{
+				"key":      key,
+				"duration": time.Since(start).String(),
+			}).Info("backed up database")
+			if err := b.deleteOldBackups(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.WithError(err).Error("could not delete old database backups")
+			}
+		}
+	}
+}
+
+// Backup writes a checkpoint of the database, uploads it, and then marks it as
+// the latest backup. It returns the key of the new backup.
+func (b *Backuper) Backup(ctx context.Context) (string, error) {
+	// The checkpoint is written to a temporary file first so that a checkpoint
+	// which fails halfway is never uploaded.
+	file, err := ioutil.TempFile(b.config.TempDir, "mesh-backup-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+	gzipWriter := gzip.NewWriter(file)
+	if err := b.config.Checkpoint(gzipWriter); err != nil {
+		return "", err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	createdAt := time.Now().UTC()
+	key := backupKeyPrefix + createdAt.Format(backupKeyTimeFormat) + BackupFileExtension
+	if err := b.config.Store.Put(ctx, key, file); err != nil {
+		return "", err
+	}
+	latest, err := json.Marshal(latestBackup{
+		Key:       key,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := b.config.Store.Put(ctx, latestKey, strings.NewReader(string(latest))); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// deleteOldBackups deletes all but the Retain most recent backups from the
+// store. It does nothing if Retain is 0.
+func (b *Backuper) deleteOldBackups(ctx context.Context) error {
+	if b.config.Retain == 0 {
+		return nil
+	}
+	keys, err := b.config.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+	backupKeys := []string{}
+	for _, key := range keys {
+		if strings.HasPrefix(key, backupKeyPrefix) && strings.HasSuffix(key, BackupFileExtension) {
+			backupKeys = append(backupKeys, key)
+		}
+	}
+	if len(backupKeys) <= b.config.Retain {
+		return nil
+	}
+	// The timestamps in the keys sort in the same order as the time the
+	// backups were created.
+	sort.Strings(backupKeys)
+	for _, key := range backupKeys[:len(backupKeys)-b.config.Retain] {
+		if err := b.config.Store.Delete(ctx, key); err != nil {
+			return err
+		}
+		log.WithField("key", key).Debug("deleted old database backup")
+	}
+	return nil
+}
+
+// Restore downloads the backup with the given key from the store and passes
+// the checkpoint it contains to restore. If key is empty, the latest backup is
+// restored. It returns the key of the backup that was restored.
+func Restore(ctx context.Context, store Store, key string, restore RestoreFunc) (string, error) {
+	if key == "" {
+		var err error
+		key, err = findLatestBackup(ctx, store)
+		if err != nil {
+			return "", err
+		}
+	}
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	gzipReader, err := gzip.NewReader(body)
+	if err != nil {
+		return "", fmt.Errorf("could not decompress backup %s: %s", key, err.Error())
+	}
+	if err := restore(gzipReader); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func findLatestBackup(ctx context.Context, store Store) (string, error) {
+	body, err := store.Get(ctx, latestKey)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	var latest latestBackup
+	if err := json.NewDecoder(io.LimitReader(body, 4096)).Decode(&latest); err != nil {
+		return "", fmt.Errorf("could not decode %s: %s", latestKey, err.Error())
+	}
+	if latest.Key == "" {
+		return "", ErrNotFound
+	}
+	return latest.Key, nil
+}
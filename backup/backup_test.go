@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mesh-backup-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewStore("file://"+dir, StoreConfig{})
+	require.NoError(t, err)
+
+	checkpoint := []byte("first checkpoint")
+	backuper, err := New(Config{
+		Store: store,
+		Checkpoint: func(w io.Writer) error {
+			_, err := w.Write(checkpoint)
+			return err
+		},
+		Interval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	firstKey, err := backuper.Backup(ctx)
+	require.NoError(t, err)
+	// Make sure that the second backup has a different key.
+	time.Sleep(time.Second)
+	checkpoint = []byte("second checkpoint")
+	secondKey, err := backuper.Backup(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, firstKey, secondKey)
+
+	// Restoring without a key should restore the latest backup.
+	restored := &bytes.Buffer{}
+	restoreFunc := func(r io.Reader) error {
+		restored.Reset()
+		_, err := io.Copy(restored, r)
+		return err
+	}
+	key, err := Restore(ctx, store, "", restoreFunc)
+	require.NoError(t, err)
+	assert.Equal(t, secondKey, key)
+	assert.Equal(t, "second checkpoint", restored.String())
+
+	key, err = Restore(ctx, store, firstKey, restoreFunc)
+	require.NoError(t, err)
+	assert.Equal(t, firstKey, key)
+	assert.Equal(t, "first checkpoint", restored.String())
+
+	_, err = Restore(ctx, store, "mesh-db-19700101T000000Z"+BackupFileExtension, restoreFunc)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestDeleteOldBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mesh-backup-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewStore("file://"+dir, StoreConfig{})
+	require.NoError(t, err)
+	backuper, err := New(Config{
+		Store: store,
+		Checkpoint: func(w io.Writer) error {
+			return nil
+		},
+		Interval: time.Hour,
+		Retain:   2,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, key := range []string{
+		"mesh-db-20200103T000000Z" + BackupFileExtension,
+		"mesh-db-20200101T000000Z" + BackupFileExtension,
+		"mesh-db-20200102T000000Z" + BackupFileExtension,
+		"unrelated",
+	} {
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("")))
+	}
+	require.NoError(t, backuper.deleteOldBackups(ctx))
+
+	keys, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"mesh-db-20200102T000000Z" + BackupFileExtension,
+		"mesh-db-20200103T000000Z" + BackupFileExtension,
+		"unrelated",
+	}, keys)
+}
+
+func TestRestoreWithoutBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mesh-backup-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := NewStore("file://"+dir, StoreConfig{})
+	require.NoError(t, err)
+	_, err = Restore(context.Background(), store, "", func(r io.Reader) error {
+		return nil
+	})
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestSplitObjectURL(t *testing.T) {
+	testCases := []struct {
+		url              string
+		expectedStoreURL string
+		expectedKey      string
+	}{
+		{
+			url:              "s3://bucket/prefix",
+			expectedStoreURL: "s3://bucket/prefix",
+			expectedKey:      "",
+		},
+		{
+			url:              "s3://bucket/prefix/mesh-db-20200101T000000Z.meshdb.gz",
+			expectedStoreURL: "s3://bucket/prefix",
+			expectedKey:      "mesh-db-20200101T000000Z.meshdb.gz",
+		},
+		{
+			url:              "gs://bucket/mesh-db-20200101T000000Z.meshdb.gz",
+			expectedStoreURL: "gs://bucket",
+			expectedKey:      "mesh-db-20200101T000000Z.meshdb.gz",
+		},
+		{
+			url:              "file:///backups/mesh-db-20200101T000000Z.meshdb.gz",
+			expectedStoreURL: "file:///backups",
+			expectedKey:      "mesh-db-20200101T000000Z.meshdb.gz",
+		},
+	}
+	for i, tc := range testCases {
+		storeURL, key := SplitObjectURL(tc.url)
+		assert.Equal(t, tc.expectedStoreURL, storeURL, "test case %d", i)
+		assert.Equal(t, tc.expectedKey, key, "test case %d", i)
+	}
+}
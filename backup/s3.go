@@ -0,0 +1,174 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+const (
+	// gcsEndpoint is the endpoint of the S3-compatible XML API of Google Cloud
+	// Storage.
+	gcsEndpoint = "https://storage.googleapis.com"
+	// gcsRegion is the region used to sign requests to GCS.
+	gcsRegion = "auto"
+	// defaultS3Region is used if no region was configured.
+	defaultS3Region = "us-east-1"
+	// uploadPartSize is the size of each part of a multipart upload. Backups
+	// which are smaller than this are uploaded with a single request.
+	uploadPartSize = 16 * 1024 * 1024
+	// uploadConcurrency is the number of parts of a multipart upload which are
+	// uploaded in parallel.
+	uploadConcurrency = 4
+)
+
+// s3Store is a Store which keeps backups in an S3 bucket or any other object
+// storage service which supports the S3 API (including GCS). Large backups are
+// uploaded in multiple parts.
+type s3Store struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// Ensure that s3Store implements Store.
+var _ Store = &s3Store{}
+
+func newS3Store(scheme string, bucket string, prefix string, config StoreConfig) (*s3Store, error) {
+	if config.Credentials.AccessKeyID == "" || config.Credentials.SecretAccessKey == "" {
+		return nil, errors.New("credentials are required for S3 and GCS backups")
+	}
+	region := config.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" && scheme == "gs" {
+		endpoint = gcsEndpoint
+		region = gcsRegion
+	}
+	awsConfig := aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials(
+			config.Credentials.AccessKeyID,
+			config.Credentials.SecretAccessKey,
+			config.Credentials.SessionToken,
+		)).
+		WithRegion(region).
+		WithHTTPClient(&http.Client{})
+	if endpoint != "" {
+		endpointURL, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported scheme for object storage endpoint: %q", endpointURL.Scheme)
+		}
+		// Buckets of S3-compatible services are addressed using path-style
+		// URLs, since they don't necessarily support virtual hosts.
+		awsConfig = awsConfig.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	client := s3.New(sess)
+	return &s3Store{
+		client: client,
+		uploader: s3manager.NewUploaderWithClient(client, func(uploader *s3manager.Uploader) {
+			uploader.PartSize = uploadPartSize
+			uploader.Concurrency = uploadConcurrency
+		}),
+		bucket: bucket,
+		prefix: prefix,
+	}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.objectKey(key)),
+		Body:        body,
+		ContentType: aws.String("application/octet-stream"),
+	})
+	return err
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	output, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (s *s3Store) List(ctx context.Context) ([]string, error) {
+	listPrefix := ""
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/"
+	}
+	keys := []string{}
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(listPrefix),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(aws.StringValue(object.Key), listPrefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	// Objects are deleted one at a time because the XML API of GCS doesn't
+	// support deleting multiple objects with a single request.
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// objectKey returns the key of the object in the bucket for the given key.
+func (s *s3Store) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+// isNotFound returns true if err means that an object does not exist.
+func isNotFound(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchKey {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3Server implements the subset of the S3 API which is used by s3Store.
+type fakeS3Server struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	// uploads maps an upload ID to the parts uploaded so far.
+	uploads map[string]map[int][]byte
+	// multipartUploads is the number of completed multipart uploads.
+	multipartUploads int
+}
+
+func newFakeS3Server(t *testing.T) *fakeS3Server {
+	return &fakeS3Server{
+		t:       t,
+		objects: map[string][]byte{},
+		uploads: map[string]map[int][]byte{},
+	}
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	switch {
+	case r.Method == "GET" && query.Get("list-type") == "2":
+		s.listObjects(w, r)
+	case r.Method == "POST" && query["uploads"] != nil:
+		uploadID := strconv.Itoa(len(s.uploads) + 1)
+		s.uploads[uploadID] = map[int][]byte{}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == "PUT" && query.Get("uploadId") != "":
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		require.NoError(s.t, err)
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(s.t, err)
+		s.uploads[query.Get("uploadId")][partNumber] = body
+		w.Header().Set("ETag", fmt.Sprintf("\"%d\"", partNumber))
+	case r.Method == "POST" && query.Get("uploadId") != "":
+		parts := s.uploads[query.Get("uploadId")]
+		partNumbers := []int{}
+		for partNumber := range parts {
+			partNumbers = append(partNumbers, partNumber)
+		}
+		sort.Ints(partNumbers)
+		object := []byte{}
+		for _, partNumber := range partNumbers {
+			object = append(object, parts[partNumber]...)
+		}
+		s.objects[r.URL.Path] = object
+		s.multipartUploads++
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == "PUT":
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(s.t, err)
+		s.objects[r.URL.Path] = body
+	case r.Method == "GET":
+		body, found := s.objects[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		_, _ = w.Write(body)
+	case r.Method == "DELETE":
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *fakeS3Server) listObjects(w http.ResponseWriter, r *http.Request) {
+	type object struct {
+		Key string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		IsTruncated bool
+		Contents    []object
+	}{}
+	bucketPath := r.URL.Path
+	if !strings.HasSuffix(bucketPath, "/") {
+		bucketPath += "/"
+	}
+	prefix := bucketPath + r.URL.Query().Get("prefix")
+	for path := range s.objects {
+		if strings.HasPrefix(path, prefix) {
+			result.Contents = append(result.Contents, object{Key: strings.TrimPrefix(path, bucketPath)})
+		}
+	}
+	require.NoError(s.t, xml.NewEncoder(w).Encode(result))
+}
+
+func (s *fakeS3Server) object(path string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.objects[path]
+}
+
+func TestS3Store(t *testing.T) {
+	fakeServer := newFakeS3Server(t)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	store, err := NewStore("s3://bucket/prefix", StoreConfig{
+		Credentials: Credentials{
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+		},
+		Endpoint: server.URL,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	expected := "checkpoint"
+	require.NoError(t, store.Put(ctx, "object", strings.NewReader(expected)))
+	assert.Equal(t, []byte(expected), fakeServer.object("/bucket/prefix/object"))
+
+	body, err := store.Get(ctx, "object")
+	require.NoError(t, err)
+	actual, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, expected, string(actual))
+
+	_, err = store.Get(ctx, "missing")
+	assert.Equal(t, ErrNotFound, err)
+
+	keys, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"object"}, keys)
+
+	require.NoError(t, store.Delete(ctx, "object"))
+	_, err = store.Get(ctx, "object")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestS3StoreMultipartUpload(t *testing.T) {
+	fakeServer := newFakeS3Server(t)
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	store, err := NewStore("s3://bucket", StoreConfig{
+		Credentials: Credentials{
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+		},
+		Endpoint: server.URL,
+	})
+	require.NoError(t, err)
+
+	expected := bytes.Repeat([]byte("checkpoint"), (2*uploadPartSize)/10+1)
+	require.NoError(t, store.Put(context.Background(), "object", bytes.NewReader(expected)))
+	assert.Equal(t, 1, fakeServer.multipartUploads)
+	assert.Equal(t, expected, fakeServer.object("/bucket/object"))
+}
@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by Store.Get if the object does not exist.
+var ErrNotFound = errors.New("backup not found")
+
+// Store is a place where backups can be uploaded to and downloaded from.
+// Objects are identified by keys relative to the URL the store was created
+// with.
+type Store interface {
+	// Put uploads body to the object with the given key, replacing it if it
+	// already exists.
+	Put(ctx context.Context, key string, body io.Reader) error
+	// Get downloads the object with the given key. The caller must close the
+	// returned ReadCloser. ErrNotFound is returned if the object does not
+	// exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns the keys of all of the objects in the store.
+	List(ctx context.Context) ([]string, error)
+	// Delete deletes the object with the given key. It does nothing if the
+	// object does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// Credentials are used to authenticate with S3 and GCS.
+type Credentials struct {
+	// AccessKeyID and SecretAccessKey are the AWS access keys for S3 or the
+	// HMAC keys of a service account for GCS.
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is an optional token for temporary AWS credentials.
+	SessionToken string
+}
+
+// StoreConfig determines how NewStore connects to object storage.
+type StoreConfig struct {
+	Credentials Credentials
+	// Region is the AWS region of the S3 bucket. It is ignored for GCS.
+	Region string
+	// Endpoint, if not empty, is the URL of an S3-compatible object storage
+	// service (e.g. MinIO) to use instead of AWS. Buckets are addressed using
+	// path-style URLs.
+	Endpoint string
+}
+
+// NewStore returns a Store for the given URL, which is of the form
+// "s3://bucket/prefix" (Amazon S3 or an S3-compatible service),
+// "gs://bucket/prefix" (Google Cloud Storage) or "file:///path/to/dir" (a
+// local or mounted directory). GCS is accessed through its S3-compatible XML
+// API, so HMAC keys must be used as credentials.
+func NewStore(rawURL string, config StoreConfig) (Store, error) {
+	storeURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch storeURL.Scheme {
+	case "s3", "gs":
+		if storeURL.Host == "" {
+			return nil, fmt.Errorf("backup URL is missing a bucket: %q", rawURL)
+		}
+		return newS3Store(storeURL.Scheme, storeURL.Host, strings.Trim(storeURL.Path, "/"), config)
+	case "file":
+		if storeURL.Path == "" {
+			return nil, fmt.Errorf("backup URL is missing a path: %q", rawURL)
+		}
+		return &fileStore{dir: filepath.FromSlash(storeURL.Path)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme for backup URL: %q", storeURL.Scheme)
+	}
+}
+
+// SplitObjectURL splits a URL which refers to a single backup (i.e. whose last
+// path segment ends with BackupFileExtension) into the URL of its store and
+// its key. If the URL does not refer to a single backup, it is returned
+// unchanged along with an empty key.
+func SplitObjectURL(rawURL string) (storeURL string, key string) {
+	if !strings.HasSuffix(rawURL, BackupFileExtension) {
+		return rawURL, ""
+	}
+	i := strings.LastIndex(rawURL, "/")
+	if i == -1 || strings.HasSuffix(rawURL[:i+1], "://") {
+		return rawURL, ""
+	}
+	return rawURL[:i], rawURL[i+1:]
+}
+
+// fileStore is a Store which keeps backups in a directory.
+type fileStore struct {
+	dir string
+}
+
+// Ensure that fileStore implements Store.
+var _ Store = &fileStore{}
+
+func (s *fileStore) Put(ctx context.Context, key string, body io.Reader) error {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a partially written backup is
+	// never mistaken for a complete one.
+	tempFile, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err := io.Copy(tempFile, body); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), s.path(key))
+}
+
+func (s *fileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *fileStore) List(ctx context.Context) ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	keys := []string{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".tmp-") {
+			continue
+		}
+		keys = append(keys, file.Name())
+	}
+	return keys, nil
+}
+
+func (s *fileStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore) path(key string) string {
+	// path.Clean prevents keys from escaping the directory.
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
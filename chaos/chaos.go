@@ -0,0 +1,63 @@
+// Package chaos injects faults into Mesh for resilience testing. Faults are
+// only injected when Mesh is built with the "chaos" build tag, e.g.:
+//
+//	go build -tags chaos ./cmd/mesh
+//
+// Without the tag, every function in this package is a no-op which the
+// compiler can inline away, so production builds are unaffected. A node built
+// with the tag behaves normally until fault injection is configured.
+package chaos
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config contains the probabilities with which each kind of fault is
+// injected. Each probability must be between 0 and 1. A probability of 0
+// disables the corresponding fault.
+type Config struct {
+	// EthRPCFailureProbability is the probability that an Ethereum JSON-RPC
+	// request fails without being sent.
+	EthRPCFailureProbability float64 `envvar:"CHAOS_ETH_RPC_FAILURE_PROBABILITY" default:"0"`
+	// BlockDelayProbability is the probability that processing the next block
+	// is delayed by BlockDelay.
+	BlockDelayProbability float64 `envvar:"CHAOS_BLOCK_DELAY_PROBABILITY" default:"0"`
+	// BlockDelay is how long processing a block is delayed for.
+	BlockDelay time.Duration `envvar:"CHAOS_BLOCK_DELAY" default:"5s"`
+	// PubSubDropProbability is the probability that a message received through
+	// GossipSub is dropped before it is handled.
+	PubSubDropProbability float64 `envvar:"CHAOS_PUBSUB_DROP_PROBABILITY" default:"0"`
+	// DBWriteFailureProbability is the probability that writing a model to
+	// the database fails.
+	DBWriteFailureProbability float64 `envvar:"CHAOS_DB_WRITE_FAILURE_PROBABILITY" default:"0"`
+}
+
+func (config Config) validate() error {
+	probabilities := map[string]float64{
+		"EthRPCFailureProbability":  config.EthRPCFailureProbability,
+		"BlockDelayProbability":     config.BlockDelayProbability,
+		"PubSubDropProbability":     config.PubSubDropProbability,
+		"DBWriteFailureProbability": config.DBWriteFailureProbability,
+	}
+	for name, probability := range probabilities {
+		if probability < 0 || probability > 1 {
+			return fmt.Errorf("chaos: %s must be between 0 and 1 but got %f", name, probability)
+		}
+	}
+	if config.BlockDelay < 0 {
+		return fmt.Errorf("chaos: BlockDelay cannot be negative but got %s", config.BlockDelay)
+	}
+	return nil
+}
+
+// InjectedFaultError is returned by operations which failed because of an
+// injected fault.
+type InjectedFaultError struct {
+	// Fault describes the fault, e.g. "Ethereum RPC failure".
+	Fault string
+}
+
+func (e InjectedFaultError) Error() string {
+	return fmt.Sprintf("chaos: injected %s", e.Fault)
+}
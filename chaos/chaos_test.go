@@ -0,0 +1,59 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureValidatesConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  Config
+		isValid bool
+	}{
+		{
+			name:    "empty config",
+			config:  Config{},
+			isValid: true,
+		},
+		{
+			name: "valid probabilities",
+			config: Config{
+				EthRPCFailureProbability:  0.1,
+				BlockDelayProbability:     1,
+				BlockDelay:                time.Second,
+				PubSubDropProbability:     0.5,
+				DBWriteFailureProbability: 0.01,
+			},
+			isValid: true,
+		},
+		{
+			name:    "negative probability",
+			config:  Config{PubSubDropProbability: -0.1},
+			isValid: false,
+		},
+		{
+			name:    "probability greater than 1",
+			config:  Config{DBWriteFailureProbability: 1.5},
+			isValid: false,
+		},
+		{
+			name:    "negative block delay",
+			config:  Config{BlockDelay: -time.Second},
+			isValid: false,
+		},
+	}
+	defer func() {
+		_ = Configure(Config{})
+	}()
+	for _, tc := range testCases {
+		err := Configure(tc.config)
+		if tc.isValid {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.Error(t, err, tc.name)
+		}
+	}
+}
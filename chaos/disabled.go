@@ -0,0 +1,38 @@
+// +build !chaos
+
+package chaos
+
+// Enabled is true if Mesh was built with the "chaos" build tag.
+const Enabled = false
+
+// Configure does nothing unless Mesh was built with the "chaos" build tag.
+func Configure(newConfig Config) error {
+	return newConfig.validate()
+}
+
+// ConfigureFromEnv does nothing unless Mesh was built with the "chaos" build
+// tag.
+func ConfigureFromEnv() error {
+	return nil
+}
+
+// FailEthRPCRequest always returns nil unless Mesh was built with the "chaos"
+// build tag.
+func FailEthRPCRequest() error {
+	return nil
+}
+
+// DelayBlock does nothing unless Mesh was built with the "chaos" build tag.
+func DelayBlock() {}
+
+// DropPubSubMessage always returns false unless Mesh was built with the
+// "chaos" build tag.
+func DropPubSubMessage() bool {
+	return false
+}
+
+// FailDBWrite always returns nil unless Mesh was built with the "chaos" build
+// tag.
+func FailDBWrite() error {
+	return nil
+}
@@ -0,0 +1,88 @@
+// +build chaos
+
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/plaid/go-envvar/envvar"
+)
+
+// Enabled is true if Mesh was built with the "chaos" build tag.
+const Enabled = true
+
+var (
+	mu     sync.Mutex
+	config Config
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Configure replaces the current fault injection config.
+func Configure(newConfig Config) error {
+	if err := newConfig.validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	config = newConfig
+	return nil
+}
+
+// ConfigureFromEnv configures fault injection from environment variables (see
+// Config for the variable names).
+func ConfigureFromEnv() error {
+	var newConfig Config
+	if err := envvar.Parse(&newConfig); err != nil {
+		return err
+	}
+	return Configure(newConfig)
+}
+
+// FailEthRPCRequest returns an InjectedFaultError if an Ethereum JSON-RPC
+// request should fail.
+func FailEthRPCRequest() error {
+	if shouldInject(func(c Config) float64 { return c.EthRPCFailureProbability }) {
+		return InjectedFaultError{Fault: "Ethereum RPC failure"}
+	}
+	return nil
+}
+
+// DelayBlock sleeps for the configured BlockDelay if processing a block should
+// be delayed.
+func DelayBlock() {
+	if shouldInject(func(c Config) float64 { return c.BlockDelayProbability }) {
+		mu.Lock()
+		delay := config.BlockDelay
+		mu.Unlock()
+		time.Sleep(delay)
+	}
+}
+
+// DropPubSubMessage returns true if a message received through GossipSub
+// should be dropped.
+func DropPubSubMessage() bool {
+	return shouldInject(func(c Config) float64 { return c.PubSubDropProbability })
+}
+
+// FailDBWrite returns an InjectedFaultError if writing to the database should
+// fail.
+func FailDBWrite() error {
+	if shouldInject(func(c Config) float64 { return c.DBWriteFailureProbability }) {
+		return InjectedFaultError{Fault: "database write failure"}
+	}
+	return nil
+}
+
+// shouldInject returns true with the probability returned by getProbability
+// for the current config.
+func shouldInject(getProbability func(Config) float64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	probability := getProbability(config)
+	if probability <= 0 {
+		return false
+	}
+	return random.Float64() < probability
+}
@@ -0,0 +1,36 @@
+// +build chaos
+
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjection(t *testing.T) {
+	defer func() {
+		_ = Configure(Config{})
+	}()
+
+	require.NoError(t, Configure(Config{}))
+	assert.NoError(t, FailEthRPCRequest())
+	assert.NoError(t, FailDBWrite())
+	assert.False(t, DropPubSubMessage())
+
+	require.NoError(t, Configure(Config{
+		EthRPCFailureProbability:  1,
+		BlockDelayProbability:     1,
+		BlockDelay:                10 * time.Millisecond,
+		PubSubDropProbability:     1,
+		DBWriteFailureProbability: 1,
+	}))
+	assert.IsType(t, InjectedFaultError{}, FailEthRPCRequest())
+	assert.IsType(t, InjectedFaultError{}, FailDBWrite())
+	assert.True(t, DropPubSubMessage())
+	start := time.Now()
+	DelayBlock()
+	assert.True(t, time.Since(start) >= 10*time.Millisecond, "expected DelayBlock to sleep")
+}
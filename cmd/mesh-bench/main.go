@@ -0,0 +1,412 @@
+// +build !js
+
+// mesh-bench is a separate executable which measures how quickly the current
+// machine can process orders at each stage of ingestion: JSON schema
+// validation, signature recovery, database inserts and gossip message
+// serialization. It uses a deterministic order book (see
+// scenario.GenerateOrderBook), so reports from different machines or
+// different releases of Mesh are comparable. A previous report in JSON format
+// can be passed in as a baseline to print the change for each benchmark.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/scenario"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// Config contains configuration options for the benchmarks.
+type Config struct {
+	// Seed is the seed of the generated order book. Reports are only
+	// comparable if they were created with the same SEED, NUM_PAIRS and DEPTH.
+	Seed int64 `envvar:"SEED" default:"1"`
+	// NumPairs is the number of pairs in the generated order book.
+	NumPairs int `envvar:"NUM_PAIRS" default:"50"`
+	// Depth is the number of bids and the number of asks for each pair. The
+	// benchmarks use NUM_PAIRS * DEPTH * 2 orders.
+	Depth int `envvar:"DEPTH" default:"100"`
+	// Rounds is the number of times each benchmark is run. The fastest round
+	// is reported, which makes the results less sensitive to noise.
+	Rounds int `envvar:"ROUNDS" default:"3"`
+	// DBBatchSize is the number of orders which are inserted into the database
+	// in one transaction.
+	DBBatchSize int `envvar:"DB_BATCH_SIZE" default:"100"`
+	// OutputFormat is the format of the report. It is either "text" or "json".
+	OutputFormat string `envvar:"OUTPUT_FORMAT" default:"text"`
+	// OutputPath is the path of the file that the report is written to. If
+	// empty, the report is written to stdout.
+	OutputPath string `envvar:"OUTPUT_PATH" default:""`
+	// BaselinePath is the path of a previous report in JSON format. If set, the
+	// change relative to the baseline is included for each benchmark.
+	BaselinePath string `envvar:"BASELINE_PATH" default:""`
+}
+
+// Report contains the results of all benchmarks and a description of the
+// machine they were run on.
+type Report struct {
+	Time                         time.Time `json:"time"`
+	GoVersion                    string    `json:"goVersion"`
+	GOOS                         string    `json:"goos"`
+	GOARCH                       string    `json:"goarch"`
+	NumCPU                       int       `json:"numCPU"`
+	SignerRecoveryImplementation string    `json:"signerRecoveryImplementation"`
+	Seed                         int64     `json:"seed"`
+	NumOrders                    int       `json:"numOrders"`
+	Results                      []*Result `json:"results"`
+}
+
+// Result is the result of one benchmark.
+type Result struct {
+	Name string `json:"name"`
+	// Duration is the duration of the fastest round.
+	Duration time.Duration `json:"duration"`
+	// OrdersPerSecond is the number of orders which were processed per second
+	// in the fastest round.
+	OrdersPerSecond float64 `json:"ordersPerSecond"`
+	// BytesPerSecond is the number of bytes which were processed per second in
+	// the fastest round. It is only set for benchmarks which process encoded
+	// orders.
+	BytesPerSecond float64 `json:"bytesPerSecond,omitempty"`
+	// BaselineOrdersPerSecond is OrdersPerSecond of the same benchmark in the
+	// baseline report (if any).
+	BaselineOrdersPerSecond float64 `json:"baselineOrdersPerSecond,omitempty"`
+}
+
+// benchmark is a single benchmark. run processes all orders once and returns
+// the number of bytes that were processed (or 0).
+type benchmark struct {
+	name  string
+	setup func() error
+	run   func() (int, error)
+}
+
+func main() {
+	config := Config{}
+	if err := envvar.Parse(&config); err != nil {
+		log.Fatal(err)
+	}
+	if config.OutputFormat != outputFormatText && config.OutputFormat != outputFormatJSON {
+		log.Fatalf("invalid OUTPUT_FORMAT: %q (expected %q or %q)", config.OutputFormat, outputFormatText, outputFormatJSON)
+	}
+	if config.NumPairs <= 0 || config.Depth <= 0 {
+		log.Fatal("NUM_PAIRS and DEPTH must be positive")
+	}
+	if config.Rounds <= 0 {
+		log.Fatal("ROUNDS must be positive")
+	}
+	if config.DBBatchSize <= 0 {
+		log.Fatal("DB_BATCH_SIZE must be positive")
+	}
+	log.SetOutput(os.Stderr)
+
+	orderBook, err := scenario.GenerateOrderBook(config.Seed, config.NumPairs, config.Depth)
+	if err != nil {
+		log.WithError(err).Fatal("could not generate order book")
+	}
+	orders := orderBook.Orders()
+	report, err := runBenchmarks(config, orders)
+	if err != nil {
+		log.WithError(err).Fatal("benchmark failed")
+	}
+	if config.BaselinePath != "" {
+		if err := addBaseline(report, config.BaselinePath); err != nil {
+			log.WithError(err).Fatal("could not read baseline report")
+		}
+	}
+
+	output := io.Writer(os.Stdout)
+	if config.OutputPath != "" {
+		file, err := os.Create(config.OutputPath)
+		if err != nil {
+			log.WithError(err).Fatal("could not create output file")
+		}
+		defer file.Close()
+		output = file
+	}
+	if err := writeReport(output, config.OutputFormat, report); err != nil {
+		log.WithError(err).Fatal("could not write report")
+	}
+}
+
+func runBenchmarks(config Config, orders []*zeroex.SignedOrder) (*Report, error) {
+	report := &Report{
+		Time:                         time.Now().UTC(),
+		GoVersion:                    runtime.Version(),
+		GOOS:                         runtime.GOOS,
+		GOARCH:                       runtime.GOARCH,
+		NumCPU:                       runtime.NumCPU(),
+		SignerRecoveryImplementation: zeroex.SignerRecoveryImplementation,
+		Seed:                         config.Seed,
+		NumOrders:                    len(orders),
+	}
+	benchmarks, cleanup, err := newBenchmarks(config, orders)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range benchmarks {
+		log.WithField("benchmark", b.name).Info("running benchmark")
+		result := &Result{
+			Name:     b.name,
+			Duration: time.Duration(math.MaxInt64),
+		}
+		for round := 0; round < config.Rounds; round++ {
+			if b.setup != nil {
+				if err := b.setup(); err != nil {
+					return nil, fmt.Errorf("%s: %s", b.name, err)
+				}
+			}
+			start := time.Now()
+			numBytes, err := b.run()
+			duration := time.Since(start)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", b.name, err)
+			}
+			if duration < result.Duration {
+				result.Duration = duration
+				result.OrdersPerSecond = float64(len(orders)) / duration.Seconds()
+				result.BytesPerSecond = float64(numBytes) / duration.Seconds()
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// newBenchmarks returns the benchmarks in the order in which they should be
+// run. cleanup must be called once the benchmarks are done, even if err is not
+// nil.
+func newBenchmarks(config Config, orders []*zeroex.SignedOrder) (benchmarks []*benchmark, cleanup func(), err error) {
+	tempDir, err := ioutil.TempDir("", "mesh-bench")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	var database *meshdb.MeshDB
+	cleanup = func() {
+		if database != nil {
+			database.Close()
+		}
+		_ = os.RemoveAll(tempDir)
+	}
+
+	filter, err := orderfilter.GetDefaultFilter(constants.TestChainID, ethereum.GanacheAddresses)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	orderJSONs := make([][]byte, len(orders))
+	for i, order := range orders {
+		orderJSONs[i], err = json.Marshal(order)
+		if err != nil {
+			return nil, cleanup, err
+		}
+	}
+	// Computing the order hash caches it on the order, which means the
+	// remaining benchmarks don't include the time it takes to hash orders.
+	orderHashes := make([]common.Hash, len(orders))
+	for i, order := range orders {
+		orderHashes[i], err = order.ComputeOrderHash()
+		if err != nil {
+			return nil, cleanup, err
+		}
+	}
+	messages := make([][]byte, len(orders))
+	for i, order := range orders {
+		messages[i], err = encoding.OrderToRawMessage(filter.Topic(), order)
+		if err != nil {
+			return nil, cleanup, err
+		}
+	}
+	dbRound := 0
+
+	benchmarks = []*benchmark{
+		{
+			name: "schema validation",
+			run: func() (int, error) {
+				numBytes := 0
+				for _, orderJSON := range orderJSONs {
+					result, err := filter.ValidateOrderJSON(orderJSON)
+					if err != nil {
+						return 0, err
+					}
+					if !result.Valid() {
+						return 0, fmt.Errorf("order does not match the schema: %v", result.Errors())
+					}
+					numBytes += len(orderJSON)
+				}
+				return numBytes, nil
+			},
+		},
+		{
+			name: "signature recovery",
+			run: func() (int, error) {
+				for i, order := range orders {
+					signer, err := zeroex.RecoverSigner(orderHashes[i], order.Signature)
+					if err != nil {
+						return 0, err
+					}
+					if signer != order.MakerAddress {
+						return 0, fmt.Errorf("unexpected signer: %s (expected %s)", signer.Hex(), order.MakerAddress.Hex())
+					}
+				}
+				return 0, nil
+			},
+		},
+		{
+			name: "db insert",
+			// Every round inserts the orders into a new database.
+			setup: func() error {
+				if database != nil {
+					database.Close()
+				}
+				dbRound++
+				newDatabase, err := meshdb.New(filepath.Join(tempDir, fmt.Sprintf("db%d", dbRound)), ethereum.GanacheAddresses)
+				if err != nil {
+					return err
+				}
+				database = newDatabase
+				return nil
+			},
+			run: func() (int, error) {
+				now := time.Now().UTC()
+				for start := 0; start < len(orders); start += config.DBBatchSize {
+					end := start + config.DBBatchSize
+					if end > len(orders) {
+						end = len(orders)
+					}
+					if err := insertOrders(database, orders[start:end], orderHashes[start:end], now); err != nil {
+						return 0, err
+					}
+				}
+				return 0, nil
+			},
+		},
+		{
+			name: "gossip encoding",
+			run: func() (int, error) {
+				numBytes := 0
+				for _, order := range orders {
+					message, err := encoding.OrderToRawMessage(filter.Topic(), order)
+					if err != nil {
+						return 0, err
+					}
+					numBytes += len(message)
+				}
+				return numBytes, nil
+			},
+		},
+		{
+			name: "gossip decoding",
+			run: func() (int, error) {
+				numBytes := 0
+				for _, message := range messages {
+					if _, err := encoding.RawMessageToOrder(message); err != nil {
+						return 0, err
+					}
+					numBytes += len(message)
+				}
+				return numBytes, nil
+			},
+		},
+	}
+	return benchmarks, cleanup, nil
+}
+
+// insertOrders inserts the given orders into the database in one transaction,
+// in the same way that the order watcher does for new orders.
+func insertOrders(database *meshdb.MeshDB, orders []*zeroex.SignedOrder, orderHashes []common.Hash, now time.Time) error {
+	txn := database.Orders.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	for i, order := range orders {
+		dbOrder := &meshdb.Order{
+			Hash:                     orderHashes[i],
+			SignedOrder:              order,
+			LastUpdated:              now,
+			FillableTakerAssetAmount: order.TakerAssetAmount,
+			LastValidated:            now,
+		}
+		if err := txn.Insert(dbOrder); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+// addBaseline sets BaselineOrdersPerSecond for each result in report which
+// has a result with the same name in the baseline report at the given path.
+func addBaseline(report *Report, baselinePath string) error {
+	data, err := ioutil.ReadFile(baselinePath)
+	if err != nil {
+		return err
+	}
+	var baseline Report
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return err
+	}
+	if baseline.Seed != report.Seed || baseline.NumOrders != report.NumOrders {
+		log.WithFields(log.Fields{
+			"baselineSeed":      baseline.Seed,
+			"baselineNumOrders": baseline.NumOrders,
+		}).Warn("baseline report was created with a different order book; the results are not directly comparable")
+	}
+	baselineResults := map[string]*Result{}
+	for _, result := range baseline.Results {
+		baselineResults[result.Name] = result
+	}
+	for _, result := range report.Results {
+		if baselineResult, found := baselineResults[result.Name]; found {
+			result.BaselineOrdersPerSecond = baselineResult.OrdersPerSecond
+		}
+	}
+	return nil
+}
+
+func writeReport(w io.Writer, format string, report *Report) error {
+	if format == outputFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "%s %s/%s, %d CPUs, signer recovery: %s\n", report.GoVersion, report.GOOS, report.GOARCH, report.NumCPU, report.SignerRecoveryImplementation)
+	fmt.Fprintf(w, "%d orders (seed %d)\n\n", report.NumOrders, report.Seed)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "benchmark\tduration\torders/s\tMB/s\tbaseline orders/s\tchange\t")
+	for _, result := range report.Results {
+		megabytesPerSecond := "-"
+		if result.BytesPerSecond > 0 {
+			megabytesPerSecond = fmt.Sprintf("%.2f", result.BytesPerSecond/1e6)
+		}
+		baseline, change := "-", "-"
+		if result.BaselineOrdersPerSecond > 0 {
+			baseline = fmt.Sprintf("%.0f", result.BaselineOrdersPerSecond)
+			change = fmt.Sprintf("%+.1f%%", 100*(result.OrdersPerSecond/result.BaselineOrdersPerSecond-1))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%.0f\t%s\t%s\t%s\t\n", result.Name, result.Duration.Round(time.Microsecond), result.OrdersPerSecond, megabytesPerSecond, baseline, change)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,271 @@
+// +build !js
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	p2pnet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// churnWindow is the period of time over which recent connection churn is
+	// reported.
+	churnWindow = 1 * time.Hour
+	// churnBucketDuration is the granularity with which connection churn is
+	// recorded within churnWindow.
+	churnBucketDuration = 1 * time.Minute
+	// numChurnBuckets is the number of buckets needed to cover churnWindow.
+	numChurnBuckets = int(churnWindow / churnBucketDuration)
+)
+
+// DiagnosticsResponse is the response of the diagnostics endpoint. It
+// summarizes the health of the bootstrap node.
+type DiagnosticsResponse struct {
+	PeerID        string   `json:"peerID"`
+	Addrs         []string `json:"addrs"`
+	UptimeSeconds int64    `json:"uptimeSeconds"`
+	// NumPeers is the number of peers the node is connected to.
+	NumPeers int `json:"numPeers"`
+	// NumConnections is the total number of open connections, which may be
+	// higher than NumPeers if there are multiple connections to a peer.
+	NumConnections int `json:"numConnections"`
+	// ConnectionsByDirection maps "inbound" and "outbound" to the number of
+	// open connections in that direction.
+	ConnectionsByDirection map[string]int `json:"connectionsByDirection"`
+	// PeersByProtocol maps each protocol to the number of connected peers
+	// which support it.
+	PeersByProtocol  map[string]int `json:"peersByProtocol"`
+	RoutingTableSize int            `json:"routingTableSize"`
+	Churn            ChurnStats     `json:"churn"`
+}
+
+// ChurnStats contains statistics about how many connections are opened and
+// closed.
+type ChurnStats struct {
+	// ConnectionsOpened is the number of connections opened since startup.
+	ConnectionsOpened uint64 `json:"connectionsOpened"`
+	// ConnectionsClosed is the number of connections closed since startup.
+	ConnectionsClosed uint64 `json:"connectionsClosed"`
+	// RecentConnectionsOpened is the number of connections opened within the
+	// last RecentWindowSeconds.
+	RecentConnectionsOpened uint64 `json:"recentConnectionsOpened"`
+	// RecentConnectionsClosed is the number of connections closed within the
+	// last RecentWindowSeconds.
+	RecentConnectionsClosed uint64 `json:"recentConnectionsClosed"`
+	RecentWindowSeconds     int64  `json:"recentWindowSeconds"`
+}
+
+// RoutingTableResponse is the response of the routing table endpoint.
+type RoutingTableResponse struct {
+	Size  int                `json:"size"`
+	Peers []RoutingTablePeer `json:"peers"`
+}
+
+// RoutingTablePeer is a peer in the DHT routing table.
+type RoutingTablePeer struct {
+	PeerID string   `json:"peerID"`
+	Addrs  []string `json:"addrs"`
+	// Connected is true if the node currently has a connection to the peer.
+	Connected bool `json:"connected"`
+	// LatencyMillis is the exponentially weighted moving average of the
+	// latency to the peer, or 0 if unknown.
+	LatencyMillis int64 `json:"latencyMillis"`
+}
+
+// churnBucket counts the connections opened and closed during one
+// churnBucketDuration.
+type churnBucket struct {
+	start  time.Time
+	opened uint64
+	closed uint64
+}
+
+// churnTracker counts the connections opened and closed, both in total and
+// within the last churnWindow. It is safe for concurrent use.
+type churnTracker struct {
+	mu      sync.Mutex
+	opened  uint64
+	closed  uint64
+	buckets [numChurnBuckets]churnBucket
+}
+
+// currentBucket returns the bucket for the current time, resetting it if it
+// was last used more than churnWindow ago. The caller must hold t.mu.
+func (t *churnTracker) currentBucket() *churnBucket {
+	start := time.Now().Truncate(churnBucketDuration)
+	bucket := &t.buckets[(start.UnixNano()/int64(churnBucketDuration))%int64(numChurnBuckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = churnBucket{start: start}
+	}
+	return bucket
+}
+
+func (t *churnTracker) recordOpened() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.opened++
+	t.currentBucket().opened++
+}
+
+func (t *churnTracker) recordClosed() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed++
+	t.currentBucket().closed++
+}
+
+func (t *churnTracker) stats() ChurnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := ChurnStats{
+		ConnectionsOpened:   t.opened,
+		ConnectionsClosed:   t.closed,
+		RecentWindowSeconds: int64(churnWindow / time.Second),
+	}
+	oldestStart := time.Now().Truncate(churnBucketDuration).Add(-churnWindow)
+	for _, bucket := range t.buckets {
+		if bucket.start.After(oldestStart) {
+			stats.RecentConnectionsOpened += bucket.opened
+			stats.RecentConnectionsClosed += bucket.closed
+		}
+	}
+	return stats
+}
+
+// diagnostics serves information about the connections and DHT routing table
+// of the bootstrap node over HTTP so that the health of the bootstrap
+// infrastructure can be monitored.
+type diagnostics struct {
+	host      host.Host
+	dht       *dht.IpfsDHT
+	churn     *churnTracker
+	startTime time.Time
+}
+
+func newDiagnostics(h host.Host, kadDHT *dht.IpfsDHT) *diagnostics {
+	return &diagnostics{
+		host:      h,
+		dht:       kadDHT,
+		churn:     &churnTracker{},
+		startTime: time.Now(),
+	}
+}
+
+// notifee returns a Notifiee which records connection churn.
+func (d *diagnostics) notifee() p2pnet.Notifiee {
+	return &p2pnet.NotifyBundle{
+		ConnectedF: func(p2pnet.Network, p2pnet.Conn) {
+			d.churn.recordOpened()
+		},
+		DisconnectedF: func(p2pnet.Network, p2pnet.Conn) {
+			d.churn.recordClosed()
+		},
+	}
+}
+
+func (d *diagnostics) getDiagnostics() *DiagnosticsResponse {
+	response := &DiagnosticsResponse{
+		PeerID:        d.host.ID().String(),
+		Addrs:         []string{},
+		UptimeSeconds: int64(time.Since(d.startTime) / time.Second),
+		ConnectionsByDirection: map[string]int{
+			"inbound":  0,
+			"outbound": 0,
+		},
+		PeersByProtocol:  map[string]int{},
+		RoutingTableSize: d.dht.RoutingTable().Size(),
+		Churn:            d.churn.stats(),
+	}
+	for _, addr := range d.host.Addrs() {
+		response.Addrs = append(response.Addrs, addr.String())
+	}
+	for _, conn := range d.host.Network().Conns() {
+		response.NumConnections++
+		switch conn.Stat().Direction {
+		case p2pnet.DirInbound:
+			response.ConnectionsByDirection["inbound"]++
+		case p2pnet.DirOutbound:
+			response.ConnectionsByDirection["outbound"]++
+		}
+	}
+	peers := d.host.Network().Peers()
+	response.NumPeers = len(peers)
+	for _, peerID := range peers {
+		protocols, err := d.host.Peerstore().GetProtocols(peerID)
+		if err != nil {
+			continue
+		}
+		for _, protocol := range protocols {
+			response.PeersByProtocol[protocol]++
+		}
+	}
+	return response
+}
+
+func (d *diagnostics) getRoutingTable() *RoutingTableResponse {
+	peerIDs := d.dht.RoutingTable().ListPeers()
+	sort.Slice(peerIDs, func(i, j int) bool {
+		return peerIDs[i] < peerIDs[j]
+	})
+	response := &RoutingTableResponse{
+		Size:  len(peerIDs),
+		Peers: make([]RoutingTablePeer, 0, len(peerIDs)),
+	}
+	for _, peerID := range peerIDs {
+		response.Peers = append(response.Peers, d.getRoutingTablePeer(peerID))
+	}
+	return response
+}
+
+func (d *diagnostics) getRoutingTablePeer(peerID peer.ID) RoutingTablePeer {
+	routingTablePeer := RoutingTablePeer{
+		PeerID:        peerID.String(),
+		Addrs:         []string{},
+		Connected:     d.host.Network().Connectedness(peerID) == p2pnet.Connected,
+		LatencyMillis: int64(d.host.Peerstore().LatencyEWMA(peerID) / time.Millisecond),
+	}
+	for _, addr := range d.host.Peerstore().Addrs(peerID) {
+		routingTablePeer.Addrs = append(routingTablePeer.Addrs, addr.String())
+	}
+	return routingTablePeer
+}
+
+func (d *diagnostics) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/diagnostics", func(w http.ResponseWriter, r *http.Request) {
+		writeDiagnosticsJSON(w, r, d.getDiagnostics())
+	})
+	mux.HandleFunc("/diagnostics/routing_table", func(w http.ResponseWriter, r *http.Request) {
+		writeDiagnosticsJSON(w, r, d.getRoutingTable())
+	})
+	return mux
+}
+
+func writeDiagnosticsJSON(w http.ResponseWriter, r *http.Request, response interface{}) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.WithError(err).Warn("could not write diagnostics response")
+	}
+}
+
+// serveDiagnostics serves the diagnostics endpoints on addr until the server
+// encounters an error.
+func serveDiagnostics(addr string, d *diagnostics) {
+	log.WithField("addr", addr).Info("serving diagnostics")
+	if err := http.ListenAndServe(addr, d.handler()); err != nil {
+		log.WithError(err).Error("diagnostics server exited with error")
+	}
+}
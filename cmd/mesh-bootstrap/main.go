@@ -22,6 +22,7 @@ import (
 	autonat "github.com/libp2p/go-libp2p-autonat-svc"
 	circuit "github.com/libp2p/go-libp2p-circuit"
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/metrics"
 	p2pnet "github.com/libp2p/go-libp2p-core/network"
@@ -122,6 +123,43 @@ type Config struct {
 	// allowed to send before failing the bandwidth check. Defaults to 1 MiB, which
 	// is roughly 100x expected usage based on real world measurements.
 	MaxBytesPerSecond float64 `envvar:"MAX_BYTES_PER_SECOND" default:"1048576"`
+	// PrivateKeyShareThreshold is the number of private key shares (created by
+	// mesh-keygen with KEY_SHARES and KEY_SHARE_THRESHOLD) required to unlock
+	// the private key. If set, the private key is not read from disk. Instead,
+	// the bootstrap node waits on startup until enough operators have POSTed
+	// their share to PRIVATE_KEY_UNLOCK_ADDR, so that compromising a single
+	// operator's machine does not leak the node identity. Defaults to 0, which
+	// disables threshold unlocking.
+	PrivateKeyShareThreshold int `envvar:"PRIVATE_KEY_SHARE_THRESHOLD" default:"0"`
+	// PrivateKeyUnlockAddr is the address of the HTTP endpoint which private
+	// key shares are submitted to, e.g. with
+	// `curl --data-binary @privkey.share1 http://localhost:60560`. Only used
+	// if PRIVATE_KEY_SHARE_THRESHOLD is set.
+	PrivateKeyUnlockAddr string `envvar:"PRIVATE_KEY_UNLOCK_ADDR" default:"localhost:60560"`
+	// PrivateKeyPeerID is the expected peer ID of the private key unlocked
+	// from shares. If set, a recovered key with any other peer ID is rejected
+	// (e.g. because one of the shares was wrong) and the shares have to be
+	// submitted again.
+	PrivateKeyPeerID string `envvar:"PRIVATE_KEY_PEER_ID" default:""`
+	// ServeNetworkManifest is whether or not the bootstrap node should serve a
+	// network manifest, signed with its private key, to regular nodes which
+	// request it on startup.
+	ServeNetworkManifest bool `envvar:"SERVE_NETWORK_MANIFEST" default:"false"`
+	// NetworkManifestPeers is a comma separated list of multiaddresses of
+	// recommended peers to include in the network manifest.
+	NetworkManifestPeers string `envvar:"NETWORK_MANIFEST_PEERS" default:""`
+	// NetworkManifestTopics is a comma separated list of the pubsub topics
+	// currently in use to include in the network manifest.
+	NetworkManifestTopics string `envvar:"NETWORK_MANIFEST_TOPICS" default:""`
+	// NetworkManifestMinClientVersion is the minimum recommended version of
+	// Mesh to include in the network manifest (e.g. "9.4.0").
+	NetworkManifestMinClientVersion string `envvar:"NETWORK_MANIFEST_MIN_CLIENT_VERSION" default:""`
+	// DiagnosticsAddr is the address on which to serve diagnostics about the
+	// connections and DHT routing table of the bootstrap node as JSON (e.g.
+	// `curl http://localhost:60561/diagnostics` or
+	// `curl http://localhost:60561/diagnostics/routing_table`). If empty,
+	// diagnostics are not served.
+	DiagnosticsAddr string `envvar:"DIAGNOSTICS_ADDR" default:""`
 }
 
 func init() {
@@ -147,8 +185,15 @@ func main() {
 	log.SetLevel(log.Level(config.Verbosity))
 	log.AddHook(loghooks.NewKeySuffixHook())
 
-	// Parse private key file and add peer ID log hook
-	privKey, err := initPrivateKey(getPrivateKeyPath(config))
+	// Parse private key file (or unlock it from shares) and add peer ID log
+	// hook
+	var privKey p2pcrypto.PrivKey
+	var err error
+	if config.PrivateKeyShareThreshold > 0 {
+		privKey, err = unlockPrivateKey(ctx, config)
+	} else {
+		privKey, err = initPrivateKey(getPrivateKeyPath(config))
+	}
 	if err != nil {
 		log.WithField("error", err).Fatal("could not initialize private key")
 	}
@@ -266,6 +311,18 @@ func main() {
 	// Set up the notifee.
 	basicHost.Network().Notify(&notifee{})
 
+	// Set up diagnostics. Connection churn is recorded from the start even if
+	// diagnostics are only served later.
+	diag := newDiagnostics(basicHost, kadDHT)
+	basicHost.Network().Notify(diag.notifee())
+
+	// Serve the network manifest if needed.
+	if config.ServeNetworkManifest {
+		if err := serveNetworkManifest(basicHost, privKey, config); err != nil {
+			log.WithField("error", err).Fatal("could not serve network manifest")
+		}
+	}
+
 	// Enable AutoNAT service.
 	if _, err := autonat.NewAutoNATService(ctx, basicHost); err != nil {
 		log.WithField("error", err).Fatal("could not enable AutoNAT service")
@@ -311,6 +368,10 @@ func main() {
 
 	}
 
+	if config.DiagnosticsAddr != "" {
+		go serveDiagnostics(config.DiagnosticsAddr, diag)
+	}
+
 	log.WithFields(map[string]interface{}{
 		"addrs":  basicHost.Addrs(),
 		"config": config,
@@ -353,6 +414,37 @@ func (n *notifee) OpenedStream(network p2pnet.Network, stream p2pnet.Stream) {}
 // ClosedStream is called when a stream closed
 func (n *notifee) ClosedStream(network p2pnet.Network, stream p2pnet.Stream) {}
 
+func serveNetworkManifest(h host.Host, privKey p2pcrypto.PrivKey, config Config) error {
+	manifest := &p2p.NetworkManifest{
+		Peers:            splitNonEmpty(config.NetworkManifestPeers),
+		Topics:           splitNonEmpty(config.NetworkManifestTopics),
+		MinClientVersion: config.NetworkManifestMinClientVersion,
+		Timestamp:        time.Now().UTC(),
+	}
+	// Make sure the peers are valid before publishing them.
+	if _, err := p2p.BootstrapListToAddrInfos(manifest.Peers); err != nil {
+		return err
+	}
+	signedManifest, err := p2p.SignNetworkManifest(privKey, manifest)
+	if err != nil {
+		return err
+	}
+	if err := p2p.ServeNetworkManifest(h, signedManifest); err != nil {
+		return err
+	}
+	log.WithField("manifest", manifest).Info("serving network manifest")
+	return nil
+}
+
+// splitNonEmpty splits a comma separated list, returning an empty list for an
+// empty string.
+func splitNonEmpty(commaSeparated string) []string {
+	if commaSeparated == "" {
+		return []string{}
+	}
+	return strings.Split(commaSeparated, ",")
+}
+
 func newAddrsFactory(advertiseAddrs []ma.Multiaddr) func([]ma.Multiaddr) []ma.Multiaddr {
 	return func([]ma.Multiaddr) []ma.Multiaddr {
 		return advertiseAddrs
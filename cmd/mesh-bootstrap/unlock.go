@@ -0,0 +1,166 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/keys"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxShareSize is the maximum size of a request body containing a key
+	// share.
+	maxShareSize = 4096
+	// unlockServerShutdownTimeout is how long to wait for the unlock server to
+	// shut down once the private key has been unlocked.
+	unlockServerShutdownTimeout = 5 * time.Second
+)
+
+// keyUnlocker collects hex-encoded private key shares submitted by operators
+// until enough of them have been received to recover the private key.
+type keyUnlocker struct {
+	mu        sync.Mutex
+	threshold int
+	// expectedPeerID is the peer ID the recovered private key must correspond
+	// to. If empty, the recovered key is not verified.
+	expectedPeerID peer.ID
+	// shares maps the x-coordinate of each submitted share to the share.
+	shares   map[byte]string
+	unlocked chan p2pcrypto.PrivKey
+}
+
+func newKeyUnlocker(threshold int, expectedPeerID peer.ID) *keyUnlocker {
+	return &keyUnlocker{
+		threshold:      threshold,
+		expectedPeerID: expectedPeerID,
+		shares:         map[byte]string{},
+		unlocked:       make(chan p2pcrypto.PrivKey, 1),
+	}
+}
+
+// addShare adds the given share. It returns the number of distinct shares
+// received so far. Once the threshold is reached, the private key is recovered
+// and sent on u.unlocked. If the recovered key is invalid, all shares are
+// discarded and an error is returned.
+func (u *keyUnlocker) addShare(encodedShare string) (int, error) {
+	share, err := keys.DecodeShare(encodedShare)
+	if err != nil {
+		return 0, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.shares) >= u.threshold {
+		return len(u.shares), errors.New("private key has already been unlocked")
+	}
+	u.shares[share[len(share)-1]] = encodedShare
+	if len(u.shares) < u.threshold {
+		return len(u.shares), nil
+	}
+
+	encodedShares := make([]string, 0, len(u.shares))
+	for _, encodedShare := range u.shares {
+		encodedShares = append(encodedShares, encodedShare)
+	}
+	privKey, err := keys.PrivateKeyFromShares(encodedShares)
+	if err == nil && u.expectedPeerID != "" {
+		var peerID peer.ID
+		peerID, err = peer.IDFromPrivateKey(privKey)
+		if err == nil && peerID != u.expectedPeerID {
+			err = fmt.Errorf("recovered private key has peer ID %s but expected %s", peerID, u.expectedPeerID)
+		}
+	}
+	if err != nil {
+		// At least one of the shares was wrong and there is no way to tell
+		// which, so start over.
+		u.shares = map[byte]string{}
+		return 0, fmt.Errorf("could not recover private key; all shares have been discarded: %s", err.Error())
+	}
+	u.unlocked <- privKey
+	return len(u.shares), nil
+}
+
+// ServeHTTP accepts a single key share as the body of a POST request.
+func (u *keyUnlocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxShareSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	numShares, err := u.addShare(strings.TrimSpace(string(body)))
+	if err != nil {
+		log.WithError(err).Warn("rejected private key share")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.WithFields(log.Fields{
+		"numShares": numShares,
+		"threshold": u.threshold,
+	}).Info("received private key share")
+	if numShares >= u.threshold {
+		fmt.Fprintln(w, "private key unlocked")
+	} else {
+		fmt.Fprintf(w, "share accepted (%d of %d)\n", numShares, u.threshold)
+	}
+}
+
+// unlockPrivateKey serves an HTTP endpoint on config.PrivateKeyUnlockAddr
+// which operators POST their private key shares to, and blocks until
+// config.PrivateKeyShareThreshold valid shares have been received. The
+// private key is only ever held in memory.
+func unlockPrivateKey(ctx context.Context, config Config) (p2pcrypto.PrivKey, error) {
+	if config.PrivateKeyShareThreshold < 2 {
+		return nil, errors.New("PRIVATE_KEY_SHARE_THRESHOLD must be at least 2")
+	}
+	var expectedPeerID peer.ID
+	if config.PrivateKeyPeerID != "" {
+		var err error
+		expectedPeerID, err = peer.IDB58Decode(config.PrivateKeyPeerID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PRIVATE_KEY_PEER_ID: %s", err.Error())
+		}
+	}
+	unlocker := newKeyUnlocker(config.PrivateKeyShareThreshold, expectedPeerID)
+	server := &http.Server{
+		Addr:    config.PrivateKeyUnlockAddr,
+		Handler: unlocker,
+	}
+	serverErrChan := make(chan error, 1)
+	go func() {
+		serverErrChan <- server.ListenAndServe()
+	}()
+	defer func() {
+		// Give the server a chance to finish responding to the operator who
+		// submitted the final share.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), unlockServerShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.WithFields(log.Fields{
+		"addr":      config.PrivateKeyUnlockAddr,
+		"threshold": config.PrivateKeyShareThreshold,
+	}).Info("waiting for private key shares")
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-serverErrChan:
+		return nil, err
+	case privKey := <-unlocker.unlocked:
+		return privKey, nil
+	}
+}
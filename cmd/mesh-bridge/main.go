@@ -2,7 +2,7 @@
 
 // mesh-bridge is a short program that bridges two Mesh nodes. This is useful in cases where
 // we introduce a network-level breaking change but still want the liquidity from one network
-//to flow to another
+// to flow to another
 package main
 
 import (
@@ -11,6 +11,7 @@ import (
 
 	"github.com/0xProject/0x-mesh/rpc"
 	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/plaid/go-envvar/envvar"
 	log "github.com/sirupsen/logrus"
@@ -64,6 +65,22 @@ func main() {
 	pipeOrders(firstClient, secondClient, firstWSRPCAddressLabel, secondWSRPCAddressLabel)
 }
 
+// bridgeBatch is a batch of order events received from one node which need to
+// be forwarded to the other.
+type bridgeBatch struct {
+	// addedOrders are orders which became fillable on the first node and are
+	// added to the other node.
+	addedOrders []*zeroex.SignedOrder
+	// removedOrderHashes are the hashes of orders which were removed by the
+	// first node. The other node re-validates them so that it removes them as
+	// well.
+	removedOrderHashes []common.Hash
+}
+
+func (b *bridgeBatch) size() int {
+	return len(b.addedOrders) + len(b.removedOrderHashes)
+}
+
 func pipeOrders(inClient, outClient *rpc.Client, inLabel, outLabel string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -74,48 +91,74 @@ func pipeOrders(inClient, outClient *rpc.Client, inLabel, outLabel string) {
 	}
 	defer clientSubscription.Unsubscribe()
 	for {
-		incomingSignedOrders, err := receiveBatch(orderEventsChan, clientSubscription, inLabel, outLabel)
+		batch, err := receiveBatch(orderEventsChan, clientSubscription, inLabel, outLabel)
 		if err != nil {
 			log.Fatal(err)
 		}
-		if len(incomingSignedOrders) == 0 {
-			continue
+		if len(batch.addedOrders) > 0 {
+			validationResults, err := outClient.AddOrders(batch.addedOrders)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.WithFields(log.Fields{
+				"from":        inLabel,
+				"to":          outLabel,
+				"numSent":     len(batch.addedOrders),
+				"numAccepted": len(validationResults.Accepted),
+				"numRejected": len(validationResults.Rejected),
+			}).Info("Finished bridging orders")
 		}
-		validationResults, err := outClient.AddOrders(incomingSignedOrders)
-		if err != nil {
-			log.Fatal(err)
+		if len(batch.removedOrderHashes) > 0 {
+			validationResults, err := outClient.RevalidateOrders(batch.removedOrderHashes)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.WithFields(log.Fields{
+				"from":        inLabel,
+				"to":          outLabel,
+				"numSent":     len(batch.removedOrderHashes),
+				"numAccepted": len(validationResults.Accepted),
+				"numRejected": len(validationResults.Rejected),
+			}).Info("Finished bridging order removals")
 		}
-		log.WithFields(log.Fields{
-			"from":        inLabel,
-			"to":          outLabel,
-			"numSent":     len(incomingSignedOrders),
-			"numAccepted": len(validationResults.Accepted),
-			"numRejected": len(validationResults.Rejected),
-		}).Info("Finished bridging orders")
 	}
 }
 
-func receiveBatch(inChan chan []*zeroex.OrderEvent, subscription *ethrpc.ClientSubscription, inLabel, outLabel string) ([]*zeroex.SignedOrder, error) {
-	signedOrders := []*zeroex.SignedOrder{}
+func receiveBatch(inChan chan []*zeroex.OrderEvent, subscription *ethrpc.ClientSubscription, inLabel, outLabel string) (*bridgeBatch, error) {
+	batch := &bridgeBatch{
+		addedOrders:        []*zeroex.SignedOrder{},
+		removedOrderHashes: []common.Hash{},
+	}
 	timeoutChan := time.After(receiveTimeout)
 	for {
-		if len(signedOrders) >= maxReceiveBatch {
-			return signedOrders, nil
+		if batch.size() >= maxReceiveBatch {
+			return batch, nil
 		}
 		select {
 		case <-timeoutChan:
-			return signedOrders, nil
+			return batch, nil
 		case orderEvents := <-inChan:
 			for _, orderEvent := range orderEvents {
-				if orderEvent.EndState != zeroex.ESOrderAdded {
-					continue
+				switch orderEvent.EndState {
+				case zeroex.ESOrderAdded, zeroex.ESOrderUnexpired, zeroex.ESOrderFillReverted, zeroex.ESOrderCancelReverted:
+					// The other node might have removed an order which became
+					// fillable again, so it is added again.
+					log.WithFields(log.Fields{
+						"from":      inLabel,
+						"to":        outLabel,
+						"orderHash": orderEvent.OrderHash.Hex(),
+						"endState":  orderEvent.EndState,
+					}).Info("Found new order over bridge")
+					batch.addedOrders = append(batch.addedOrders, orderEvent.SignedOrder)
+				case zeroex.ESOrderFullyFilled, zeroex.ESOrderCancelled, zeroex.ESOrderExpired, zeroex.ESOrderBecameUnfunded:
+					log.WithFields(log.Fields{
+						"from":      inLabel,
+						"to":        outLabel,
+						"orderHash": orderEvent.OrderHash.Hex(),
+						"endState":  orderEvent.EndState,
+					}).Info("Found removed order over bridge")
+					batch.removedOrderHashes = append(batch.removedOrderHashes, orderEvent.OrderHash)
 				}
-				log.WithFields(log.Fields{
-					"from":      inLabel,
-					"to":        outLabel,
-					"orderHash": orderEvent.OrderHash.Hex(),
-				}).Info("Found new order over bridge")
-				signedOrders = append(signedOrders, orderEvent.SignedOrder)
 			}
 		case err := <-subscription.Err():
 			log.Fatal(err)
@@ -0,0 +1,387 @@
+// +build !js
+
+// mesh-crawler is a separate executable which measures the size and health of
+// the 0x Mesh network. It walks the DHT to discover peers, connects to each of
+// them and requests a signed stats attestation (version, latest block and
+// order count per topic). The results are written as JSON or CSV.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/statsattestation"
+	"github.com/0xProject/0x-mesh/p2p"
+	libp2p "github.com/libp2p/go-libp2p"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/routing"
+	discovery "github.com/libp2p/go-libp2p-discovery"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	dhtopts "github.com/libp2p/go-libp2p-kad-dht/opts"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	outputFormatJSON = "json"
+	outputFormatCSV  = "csv"
+)
+
+// Config contains configuration options for the crawler.
+type Config struct {
+	// Verbosity is the logging verbosity: 0=panic, 1=fatal, 2=error, 3=warn, 4=info, 5=debug 6=trace
+	Verbosity int `envvar:"VERBOSITY" default:"4"`
+	// EthereumChainID is the chain ID of the network to crawl. It determines
+	// the rendezvous point which is used to find Mesh nodes.
+	EthereumChainID int `envvar:"ETHEREUM_CHAIN_ID" default:"1"`
+	// RendezvousPoints is an optional comma separated list of additional
+	// rendezvous points to find Mesh nodes with (e.g. for nodes which use a
+	// custom order filter).
+	RendezvousPoints string `envvar:"RENDEZVOUS_POINTS" default:""`
+	// BootstrapList is a comma-separated list of multiaddresses to use for
+	// bootstrapping the DHT. If empty, the default bootstrap list will be used.
+	BootstrapList string `envvar:"BOOTSTRAP_LIST" default:""`
+	// RandomWalks is the number of lookups of random keys in the DHT which are
+	// used to discover peers in addition to the rendezvous points. More walks
+	// discover more of the network but take longer.
+	RandomWalks int `envvar:"RANDOM_WALKS" default:"20"`
+	// DiscoveryTimeout is the maximum amount of time spent discovering peers.
+	DiscoveryTimeout time.Duration `envvar:"DISCOVERY_TIMEOUT" default:"2m"`
+	// PeerTimeout is the maximum amount of time spent connecting to and
+	// requesting stats from a single peer.
+	PeerTimeout time.Duration `envvar:"PEER_TIMEOUT" default:"20s"`
+	// Concurrency is the number of peers which are crawled at once.
+	Concurrency int `envvar:"CONCURRENCY" default:"16"`
+	// OutputFormat is the format of the results. It is either "json" or "csv".
+	OutputFormat string `envvar:"OUTPUT_FORMAT" default:"json"`
+	// OutputPath is the path of the file that the results are written to. If
+	// empty, the results are written to stdout.
+	OutputPath string `envvar:"OUTPUT_PATH" default:""`
+}
+
+// PeerResult contains the results of crawling a single peer.
+type PeerResult struct {
+	PeerID string   `json:"peerID"`
+	Addrs  []string `json:"addrs"`
+	// Reachable is whether or not the crawler could connect to the peer.
+	Reachable bool `json:"reachable"`
+	// AgentVersion is the libp2p agent version reported by the peer.
+	AgentVersion string `json:"agentVersion"`
+	// IsMeshNode is whether or not the peer returned a valid stats
+	// attestation. The fields below are only set if it did.
+	IsMeshNode        bool                           `json:"isMeshNode"`
+	Version           string                         `json:"version,omitempty"`
+	ChainID           int                            `json:"chainID,omitempty"`
+	LatestBlockNumber uint64                         `json:"latestBlockNumber,omitempty"`
+	Topics            []*statsattestation.TopicStats `json:"topics,omitempty"`
+	// Attestation is the signed attestation returned by the peer, which can
+	// be verified independently of the crawler.
+	Attestation *statsattestation.SignedAttestation `json:"attestation,omitempty"`
+	// Error describes why the peer could not be crawled.
+	Error string `json:"error,omitempty"`
+}
+
+func main() {
+	var config Config
+	if err := envvar.Parse(&config); err != nil {
+		panic(fmt.Sprintf("could not parse environment variables: %s", err.Error()))
+	}
+	if config.OutputFormat != outputFormatJSON && config.OutputFormat != outputFormatCSV {
+		log.Fatalf("invalid OUTPUT_FORMAT: %q (expected %q or %q)", config.OutputFormat, outputFormatJSON, outputFormatCSV)
+	}
+	if config.Concurrency <= 0 {
+		log.Fatal("CONCURRENCY must be positive")
+	}
+	log.SetOutput(os.Stderr)
+	log.SetLevel(log.Level(config.Verbosity))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, kadDHT, err := newCrawlerHost(ctx)
+	if err != nil {
+		log.WithError(err).Fatal("could not create libp2p host")
+	}
+	if err := connectToBootstrapList(ctx, h, config); err != nil {
+		log.WithError(err).Fatal("could not connect to bootstrap list")
+	}
+
+	peers := discoverPeers(ctx, h, kadDHT, config)
+	log.WithField("numPeers", len(peers)).Info("discovered peers")
+	results := crawlPeers(ctx, h, peers, config)
+
+	output := io.Writer(os.Stdout)
+	if config.OutputPath != "" {
+		file, err := os.Create(config.OutputPath)
+		if err != nil {
+			log.WithError(err).Fatal("could not create output file")
+		}
+		defer file.Close()
+		output = file
+	}
+	if err := writeResults(output, config.OutputFormat, results); err != nil {
+		log.WithError(err).Fatal("could not write results")
+	}
+	logSummary(results)
+}
+
+// newCrawlerHost creates a libp2p host with a new random identity and a DHT in
+// client mode, so that the crawler doesn't store any records for other peers.
+func newCrawlerHost(ctx context.Context) (host.Host, *dht.IpfsDHT, error) {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	var kadDHT *dht.IpfsDHT
+	newDHT := func(h host.Host) (routing.PeerRouting, error) {
+		var err error
+		kadDHT, err = dht.New(ctx, h, dhtopts.Client(true), dhtopts.Protocols(p2p.DHTProtocolID))
+		return kadDHT, err
+	}
+	h, err := libp2p.New(
+		ctx,
+		libp2p.Identity(privKey),
+		libp2p.Routing(newDHT),
+		libp2p.EnableRelay(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, kadDHT, nil
+}
+
+func connectToBootstrapList(ctx context.Context, h host.Host, config Config) error {
+	bootstrapList := p2p.DefaultBootstrapList
+	if config.BootstrapList != "" {
+		bootstrapList = strings.Split(config.BootstrapList, ",")
+	}
+	peerInfos, err := p2p.BootstrapListToAddrInfos(bootstrapList)
+	if err != nil {
+		return err
+	}
+	connected := 0
+	for _, peerInfo := range peerInfos {
+		connectCtx, cancel := context.WithTimeout(ctx, config.PeerTimeout)
+		err := h.Connect(connectCtx, peerInfo)
+		cancel()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":    err.Error(),
+				"peerInfo": peerInfo,
+			}).Debug("could not connect to bootstrap peer")
+			continue
+		}
+		connected++
+	}
+	if connected == 0 {
+		return fmt.Errorf("could not connect to any of the %d bootstrap peers", len(peerInfos))
+	}
+	return nil
+}
+
+// discoverPeers finds peers via the rendezvous points used by Mesh nodes and
+// via lookups of random keys in the DHT, which add the peers they encounter to
+// the peerstore. It returns every peer in the peerstore except for the crawler
+// itself.
+func discoverPeers(ctx context.Context, h host.Host, kadDHT *dht.IpfsDHT, config Config) []peer.ID {
+	ctx, cancel := context.WithTimeout(ctx, config.DiscoveryTimeout)
+	defer cancel()
+
+	rendezvousPoints := []string{fmt.Sprintf("/0x-mesh/network/%d/version/2", config.EthereumChainID)}
+	if config.RendezvousPoints != "" {
+		rendezvousPoints = append(rendezvousPoints, strings.Split(config.RendezvousPoints, ",")...)
+	}
+	routingDiscovery := discovery.NewRoutingDiscovery(kadDHT)
+	for _, rendezvousPoint := range rendezvousPoints {
+		peerChan, err := routingDiscovery.FindPeers(ctx, rendezvousPoint)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":           err.Error(),
+				"rendezvousPoint": rendezvousPoint,
+			}).Warn("could not find peers via rendezvous point")
+			continue
+		}
+		for peerInfo := range peerChan {
+			h.Peerstore().AddAddrs(peerInfo.ID, peerInfo.Addrs, time.Hour)
+		}
+	}
+
+	for i := 0; i < config.RandomWalks; i++ {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.WithError(err).Fatal("could not generate random key")
+		}
+		peerChan, err := kadDHT.GetClosestPeers(ctx, hex.EncodeToString(key))
+		if err != nil {
+			log.WithError(err).Debug("random walk failed")
+			continue
+		}
+		for range peerChan {
+			// The peers encountered during the lookup are added to the
+			// peerstore by the DHT.
+		}
+		log.WithFields(log.Fields{
+			"walk":     i + 1,
+			"numPeers": len(h.Peerstore().Peers()),
+		}).Debug("finished random walk")
+	}
+
+	peers := []peer.ID{}
+	for _, peerID := range h.Peerstore().Peers() {
+		if peerID != h.ID() {
+			peers = append(peers, peerID)
+		}
+	}
+	return peers
+}
+
+// crawlPeers connects to each of the given peers and requests a stats
+// attestation. The results are sorted by peer ID.
+func crawlPeers(ctx context.Context, h host.Host, peers []peer.ID, config Config) []*PeerResult {
+	peerChan := make(chan peer.ID)
+	resultsChan := make(chan *PeerResult)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for peerID := range peerChan {
+				resultsChan <- crawlPeer(ctx, h, peerID, config.PeerTimeout)
+			}
+		}()
+	}
+	go func() {
+		for _, peerID := range peers {
+			peerChan <- peerID
+		}
+		close(peerChan)
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	results := []*PeerResult{}
+	for result := range resultsChan {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].PeerID < results[j].PeerID
+	})
+	return results
+}
+
+func crawlPeer(ctx context.Context, h host.Host, peerID peer.ID, timeout time.Duration) *PeerResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result := &PeerResult{
+		PeerID: peerID.Pretty(),
+		Addrs:  []string{},
+	}
+	for _, addr := range h.Peerstore().Addrs(peerID) {
+		result.Addrs = append(result.Addrs, addr.String())
+	}
+	if err := h.Connect(ctx, peer.AddrInfo{ID: peerID}); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Reachable = true
+	if agentVersion, err := h.Peerstore().Get(peerID, "AgentVersion"); err == nil {
+		result.AgentVersion, _ = agentVersion.(string)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	signedAttestation, attestation, err := statsattestation.RequestAttestation(ctx, h, peerID, hex.EncodeToString(nonce))
+	if err != nil {
+		// Most peers in the DHT (e.g. bootstrap and relay nodes) are not Mesh
+		// nodes or run a version of Mesh which doesn't support attestations.
+		result.Error = err.Error()
+		return result
+	}
+	result.IsMeshNode = true
+	result.Version = attestation.Version
+	result.ChainID = attestation.ChainID
+	result.LatestBlockNumber = attestation.LatestBlockNumber
+	result.Topics = attestation.Topics
+	result.Attestation = signedAttestation
+	return result
+}
+
+// writeResults writes the results in the given format. CSV output contains one
+// row per peer and the order count of each topic in a single column of the
+// form "topic=count;topic=count".
+func writeResults(w io.Writer, format string, results []*PeerResult) error {
+	switch format {
+	case outputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case outputFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		header := []string{"peerID", "addrs", "reachable", "agentVersion", "isMeshNode", "version", "chainID", "latestBlockNumber", "topics", "error"}
+		if err := csvWriter.Write(header); err != nil {
+			return err
+		}
+		for _, result := range results {
+			topics := make([]string, len(result.Topics))
+			for i, topic := range result.Topics {
+				topics[i] = fmt.Sprintf("%s=%d", topic.Topic, topic.NumOrders)
+			}
+			row := []string{
+				result.PeerID,
+				strings.Join(result.Addrs, " "),
+				strconv.FormatBool(result.Reachable),
+				result.AgentVersion,
+				strconv.FormatBool(result.IsMeshNode),
+				result.Version,
+				strconv.Itoa(result.ChainID),
+				strconv.FormatUint(result.LatestBlockNumber, 10),
+				strings.Join(topics, ";"),
+				result.Error,
+			}
+			if err := csvWriter.Write(row); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	default:
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+}
+
+func logSummary(results []*PeerResult) {
+	numReachable := 0
+	numMeshNodes := 0
+	versions := map[string]int{}
+	for _, result := range results {
+		if result.Reachable {
+			numReachable++
+		}
+		if result.IsMeshNode {
+			numMeshNodes++
+			versions[result.Version]++
+		}
+	}
+	log.WithFields(log.Fields{
+		"numPeers":     len(results),
+		"numReachable": numReachable,
+		"numMeshNodes": numMeshNodes,
+		"versions":     versions,
+	}).Info("finished crawling")
+}
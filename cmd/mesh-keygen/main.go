@@ -4,16 +4,31 @@
 package main
 
 import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/0xProject/0x-mesh/keys"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/plaid/go-envvar/envvar"
 )
 
 type envVars struct {
 	// PrivateKeyPath is the path where the private key will be written.
 	PrivateKeyPath string `envvar:"PRIVATE_KEY_PATH" default:"0x_mesh/keys/privkey"`
+	// KeyShares is the number of shares to split the private key into using
+	// Shamir's Secret Sharing. If set, the private key itself is never written
+	// to disk. Instead, each share is written to PRIVATE_KEY_PATH with a
+	// ".share<N>" suffix and should be distributed to a different operator.
+	// Defaults to 0, which means the key is not split.
+	KeyShares int `envvar:"KEY_SHARES" default:"0"`
+	// KeyShareThreshold is the number of shares which are required to recover
+	// the private key. Required if KEY_SHARES is set.
+	KeyShareThreshold int `envvar:"KEY_SHARE_THRESHOLD" default:"0"`
 }
 
 func main() {
@@ -21,6 +36,12 @@ func main() {
 	if err := envvar.Parse(&env); err != nil {
 		log.Fatal(err)
 	}
+	if env.KeyShares > 0 {
+		if err := generateAndSaveKeyShares(env); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if _, err := os.Stat(env.PrivateKeyPath); !os.IsNotExist(err) {
 		log.Fatalf("Key file: %s already exists. If you really want to overwrite it, delete the file and try again.", env.PrivateKeyPath)
 	}
@@ -28,3 +49,35 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+func generateAndSaveKeyShares(env envVars) error {
+	sharePaths := make([]string, env.KeyShares)
+	for i := range sharePaths {
+		sharePaths[i] = fmt.Sprintf("%s.share%d", env.PrivateKeyPath, i+1)
+		if _, err := os.Stat(sharePaths[i]); !os.IsNotExist(err) {
+			return fmt.Errorf("key share file: %s already exists. If you really want to overwrite it, delete the file and try again", sharePaths[i])
+		}
+	}
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return err
+	}
+	shares, err := keys.SplitPrivateKey(privKey, env.KeyShares, env.KeyShareThreshold)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(env.PrivateKeyPath), os.ModePerm); err != nil {
+		return err
+	}
+	for i, share := range shares {
+		if err := ioutil.WriteFile(sharePaths[i], []byte(share), 0600); err != nil {
+			return err
+		}
+	}
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return err
+	}
+	log.Printf("Wrote %d key shares (threshold %d) for peer ID %s", len(shares), env.KeyShareThreshold, peerID)
+	return nil
+}
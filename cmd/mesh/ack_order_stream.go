@@ -0,0 +1,86 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/0xProject/0x-mesh/rpc/ackstream"
+	"github.com/0xProject/0x-mesh/zeroex"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+)
+
+// newAckStreamRegistry creates the registry for order event subscriptions in
+// ack mode. Their order events are buffered in the "subscriptions" directory
+// inside dataDir. Buffers which were left behind by a previous run (e.g.
+// because Mesh crashed) are removed.
+func newAckStreamRegistry(dataDir string, maxBufferBytes int64) (*ackstream.Registry, error) {
+	dir := filepath.Join(dataDir, "subscriptions")
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return ackstream.NewRegistry(dir, maxBufferBytes), nil
+}
+
+// setupAckOrderStream sets up the order stream for a subscription in ack mode.
+// Instead of lists of order events, the subscriber receives
+// OrderEventsNotifications and has to acknowledge each of them via
+// AckOrderEvents before it receives the next one.
+func setupAckOrderStream(ctx context.Context, app *core.App, ackStreams *ackstream.Registry, opts types.SubscribeToOrdersOpts) (*ethrpc.Subscription, error) {
+	notifier, supported := ethrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &ethrpc.Subscription{}, ethrpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	stream, err := ackStreams.NewStream(string(rpcSub.ID), opts, func(notification *types.OrderEventsNotification) bool {
+		return notifyOrderSubscriber(notifier, rpcSub.ID, notification, len(notification.OrderEvents))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		orderEventsChan := make(chan []*zeroex.OrderEvent, orderEventsBufferSize)
+		orderWatcherSub := app.SubscribeToOrderEvents(orderEventsChan)
+		defer orderWatcherSub.Unsubscribe()
+
+		streamCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case err := <-rpcSub.Err():
+				if err != nil {
+					log.WithField("err", err).Error("rpcSub returned an error")
+				} else {
+					log.Debug("rpcSub was closed without error")
+				}
+			case <-notifier.Closed():
+			case <-streamCtx.Done():
+			}
+			cancel()
+		}()
+		stream.Run(streamCtx, orderEventsChan)
+	}()
+
+	return rpcSub, nil
+}
+
+// AckOrderEvents is called when an RPC client calls AckOrderEvents.
+func (handler *rpcHandler) AckOrderEvents(subscriptionID string, ackID uint64) error {
+	log.WithFields(log.Fields{
+		"subscriptionID": subscriptionID,
+		"ackID":          ackID,
+	}).Trace("received AckOrderEvents request via RPC")
+	// Ack only returns ErrUnknownSubscription, which is safe to return to the
+	// client.
+	return handler.ackStreams.Ack(subscriptionID, ackID)
+}
@@ -0,0 +1,52 @@
+// +build !js
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/archive"
+	"github.com/0xProject/0x-mesh/core"
+	log "github.com/sirupsen/logrus"
+)
+
+// newArchiver returns an archiver which publishes order events to the message
+// queue enabled in config. It returns nil if no message queue is enabled.
+func newArchiver(config standaloneConfig, coreConfig core.Config) (*archive.Archiver, error) {
+	var sink archive.Sink
+	switch {
+	case config.ArchiveKafkaBrokers != "" && config.ArchiveNATSURL != "":
+		return nil, errors.New("only one of ARCHIVE_KAFKA_BROKERS and ARCHIVE_NATS_URL can be set")
+	case config.ArchiveKafkaBrokers != "":
+		kafkaSink, err := archive.NewKafkaSink(archive.KafkaConfig{
+			Brokers:  strings.Split(config.ArchiveKafkaBrokers, ","),
+			TLS:      config.ArchiveKafkaTLS,
+			Username: config.ArchiveKafkaUsername,
+			Password: config.ArchiveKafkaPassword,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sink = kafkaSink
+	case config.ArchiveNATSURL != "":
+		natsSink, err := archive.NewNATSSink(config.ArchiveNATSURL)
+		if err != nil {
+			return nil, err
+		}
+		sink = natsSink
+	default:
+		return nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"archiveTopic":             config.ArchiveTopic,
+		"archiveMaxBufferedEvents": config.ArchiveMaxBufferedEvents,
+	}).Info("configured order event archive")
+	return archive.New(archive.Config{
+		Sink:              sink,
+		TopicTemplate:     config.ArchiveTopic,
+		ChainID:           coreConfig.EthereumChainID,
+		MaxBufferedEvents: config.ArchiveMaxBufferedEvents,
+	})
+}
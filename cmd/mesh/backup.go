@@ -0,0 +1,103 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/0xProject/0x-mesh/backup"
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+// newBackuper returns a backuper which periodically uploads a checkpoint of
+// the database of app to BACKUP_URL. It returns nil if backups are disabled.
+func newBackuper(config standaloneConfig, app *core.App) (*backup.Backuper, error) {
+	if config.BackupURL == "" {
+		return nil, nil
+	}
+	store, err := newBackupStore(config.BackupURL, config)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"backupURL":      config.BackupURL,
+		"backupInterval": config.BackupInterval.String(),
+		"backupRetain":   config.BackupRetain,
+	}).Info("configured database backups")
+	return backup.New(backup.Config{
+		Store:      store,
+		Checkpoint: app.WriteDBCheckpoint,
+		Interval:   config.BackupInterval,
+		Retain:     config.BackupRetain,
+	})
+}
+
+func newBackupStore(url string, config standaloneConfig) (backup.Store, error) {
+	return backup.NewStore(url, backup.StoreConfig{
+		Credentials: backup.Credentials{
+			AccessKeyID:     config.BackupAccessKeyID,
+			SecretAccessKey: config.BackupSecretAccessKey,
+			SessionToken:    config.BackupSessionToken,
+		},
+		Region:   config.BackupRegion,
+		Endpoint: config.BackupEndpoint,
+	})
+}
+
+// restoreConfig contains the environment variables used by the "mesh restore"
+// subcommand in addition to the ones in standaloneConfig.
+type restoreConfig struct {
+	// DataDir is the directory that the database is restored to. It must be
+	// the same as the DATA_DIR used to run Mesh.
+	DataDir string `envvar:"DATA_DIR" default:"0x_mesh"`
+}
+
+// runRestoreCommand handles the "mesh restore --from <url>" subcommand, which
+// restores the database from a backup and then exits. The URL is either the
+// BACKUP_URL that backups were uploaded to, in which case the latest backup is
+// restored, or the URL of a specific backup. It returns the exit code for the
+// process.
+func runRestoreCommand(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	from := flags.String("from", "", "the backup URL to restore from (e.g. s3://bucket/prefix)")
+	if err := flags.Parse(args); err != nil || *from == "" || flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: mesh restore --from <backup URL>")
+		return 2
+	}
+	var config standaloneConfig
+	if err := envvar.Parse(&config); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	var dirConfig restoreConfig
+	if err := envvar.Parse(&dirConfig); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+
+	storeURL, key := backup.SplitObjectURL(*from)
+	store, err := newBackupStore(storeURL, config)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not restore database")
+		return 1
+	}
+	restoredKey, err := backup.Restore(context.Background(), store, key, func(checkpoint io.Reader) error {
+		return core.RestoreDBCheckpoint(dirConfig.DataDir, checkpoint)
+	})
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not restore database")
+		return 1
+	}
+	log.WithFields(log.Fields{
+		"backupURL": storeURL,
+		"key":       restoredKey,
+		"dataDir":   dirConfig.DataDir,
+	}).Info("restored database")
+	return 0
+}
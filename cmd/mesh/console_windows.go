@@ -0,0 +1,58 @@
+// +build windows
+
+package main
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Console control event types. See
+	// https://docs.microsoft.com/en-us/windows/console/handlerroutine
+	ctrlCloseEvent    = 2
+	ctrlLogoffEvent   = 5
+	ctrlShutdownEvent = 6
+	// consoleCloseGracePeriod is the maximum amount of time to block in the
+	// console control handler while waiting for Mesh to shut down. Windows
+	// forcibly terminates the process shortly after a CTRL_CLOSE_EVENT (5
+	// seconds by default) regardless of what the handler does.
+	consoleCloseGracePeriod = 5 * time.Second
+)
+
+var (
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleCtrlHandler = kernel32.NewProc("SetConsoleCtrlHandler")
+)
+
+// handleConsoleCloseEvents registers a console control handler which cancels
+// the given context when the console window is closed or the user logs off or
+// shuts down the machine. Windows terminates the process as soon as the
+// handler returns, so the handler blocks until done is closed (or the grace
+// period elapses) to give core.App a chance to close the database cleanly.
+// CTRL_C_EVENT and CTRL_BREAK_EVENT are left to the Go runtime, which delivers
+// them as os.Interrupt.
+func handleConsoleCloseEvents(cancel context.CancelFunc, done <-chan struct{}) {
+	handler := syscall.NewCallback(func(ctrlType uintptr) uintptr {
+		switch ctrlType {
+		case ctrlCloseEvent, ctrlLogoffEvent, ctrlShutdownEvent:
+			log.WithField("ctrlType", ctrlType).Info("received console control event; shutting down")
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(consoleCloseGracePeriod):
+				log.Warn("timed out waiting for Mesh to shut down")
+			}
+			return 1
+		default:
+			// Let the next handler in the chain handle the event.
+			return 0
+		}
+	})
+	if ret, _, err := procSetConsoleCtrlHandler.Call(handler, 1); ret == 0 {
+		log.WithError(err).Warn("could not set console control handler")
+	}
+}
@@ -0,0 +1,49 @@
+// +build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+// dbCommandConfig contains the environment variables used by the "mesh db"
+// subcommands.
+type dbCommandConfig struct {
+	// DataDir is the directory which contains the database. It must be the
+	// same as the DATA_DIR used to run Mesh.
+	DataDir string `envvar:"DATA_DIR" default:"0x_mesh"`
+}
+
+// runDBCommand handles the "mesh db compact" subcommand, which compacts the
+// database in order to reclaim the disk space used by removed orders and then
+// exits. Mesh must not be running. It returns the exit code for the process.
+func runDBCommand(args []string) int {
+	if len(args) != 1 || args[0] != "compact" {
+		fmt.Fprintln(os.Stderr, "usage: mesh db compact")
+		return 2
+	}
+	var config dbCommandConfig
+	if err := envvar.Parse(&config); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	result, err := core.CompactDBInDataDir(config.DataDir)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not compact database (make sure that Mesh is not running)")
+		return 1
+	}
+	log.WithFields(log.Fields{
+		"dataDir":              config.DataDir,
+		"sizeBefore":           result.SizeBefore,
+		"sizeAfter":            result.SizeAfter,
+		"reclaimedBytes":       result.ReclaimedBytes(),
+		"fragmentationPercent": result.FragmentationPercent(),
+		"duration":             result.Duration.String(),
+	}).Info("compacted database")
+	return 0
+}
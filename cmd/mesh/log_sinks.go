@@ -0,0 +1,70 @@
+// +build !js
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/0xProject/0x-mesh/loghooks"
+	log "github.com/sirupsen/logrus"
+)
+
+// syslogTag is the tag used for logs sent to syslog.
+const syslogTag = "mesh"
+
+// setupLogSinks adds logger hooks for each of the log sinks enabled in config
+// and returns a function which flushes and closes them.
+func setupLogSinks(config standaloneConfig) (func(), error) {
+	closers := []io.Closer{}
+	closeAll := func() {
+		for _, closer := range closers {
+			_ = closer.Close()
+		}
+	}
+	for name, verbosity := range map[string]int{
+		"LOG_FILE_VERBOSITY":    config.LogFileVerbosity,
+		"LOG_SYSLOG_VERBOSITY":  config.LogSyslogVerbosity,
+		"LOG_FLUENTD_VERBOSITY": config.LogFluentdVerbosity,
+	} {
+		if verbosity < int(log.PanicLevel) || verbosity > int(log.TraceLevel) {
+			return nil, fmt.Errorf("%s must be between 0 and 6", name)
+		}
+	}
+
+	if config.LogFilePath != "" {
+		file, err := loghooks.NewRotatingFile(config.LogFilePath, int64(config.LogFileMaxSizeMB)*1024*1024, config.LogFileMaxBackups)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, file)
+		log.AddHook(loghooks.NewMinLevelHook(loghooks.NewWriterHook(file, nil), log.Level(config.LogFileVerbosity)))
+	}
+	if config.LogSyslogAddr != "" {
+		hook, err := loghooks.NewSyslogHook(config.LogSyslogAddr, syslogTag)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, hook)
+		log.AddHook(loghooks.NewMinLevelHook(hook, log.Level(config.LogSyslogVerbosity)))
+	}
+	if config.LogFluentdAddr != "" {
+		hook := loghooks.NewFluentdHook(config.LogFluentdAddr, config.LogFluentdTag)
+		closers = append(closers, hook)
+		log.AddHook(loghooks.NewMinLevelHook(hook, log.Level(config.LogFluentdVerbosity)))
+	}
+	if !config.LogStdout {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	log.WithFields(log.Fields{
+		"logStdout":      config.LogStdout,
+		"logFilePath":    config.LogFilePath,
+		"logSyslogAddr":  config.LogSyslogAddr,
+		"logFluentdAddr": config.LogFluentdAddr,
+	}).Info("configured log sinks")
+	return closeAll, nil
+}
@@ -9,9 +9,12 @@ import (
 	"context"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/0xProject/0x-mesh/core"
 	"github.com/0xProject/0x-mesh/rpc"
+	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/plaid/go-envvar/envvar"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,9 +28,164 @@ type standaloneConfig struct {
 	// HTTPRPCAddr is the interface and port to use for the JSON-RPC API over
 	// HTTP. By default, 0x Mesh will listen on localhost and port 60556.
 	HTTPRPCAddr string `envvar:"HTTP_RPC_ADDR" default:"localhost:60556"`
+	// RPCRequireReady determines whether the JSON-RPC API rejects requests
+	// with HTTP status 503 and a NOT_READY error until the block watcher has
+	// caught up to the latest block and the first round of ordersync has
+	// completed. This keeps load balancers from routing traffic to a node which
+	// has not synced any orders yet. Individual requests can bypass the check by
+	// setting the "X-Mesh-Ignore-Readiness: true" header.
+	RPCRequireReady bool `envvar:"RPC_REQUIRE_READY" default:"false"`
+	// RPCAckBufferMaxBytes is the maximum number of bytes of order events
+	// which are buffered on disk for each order event subscription in ack
+	// mode (see the ackMode option of mesh_subscribe) while the subscriber
+	// hasn't acknowledged the previous notification. If the limit is reached,
+	// the buffered order events are dropped and the subscriber receives an
+	// overflow notification. Buffers are stored in the "subscriptions"
+	// directory inside DATA_DIR.
+	RPCAckBufferMaxBytes int64 `envvar:"RPC_ACK_BUFFER_MAX_BYTES" default:"67108864"`
+	// LogStdout is whether to write logs to stdout. It can be set to false if
+	// one of the log sinks below is used instead.
+	LogStdout bool `envvar:"LOG_STDOUT" default:"true"`
+	// LogFilePath is the path of a file that logs should be written to. The file
+	// is rotated once it reaches LogFileMaxSizeMB megabytes. If empty, logs are
+	// not written to a file.
+	LogFilePath string `envvar:"LOG_FILE_PATH" default:""`
+	// LogFileMaxSizeMB is the size in megabytes at which the log file is
+	// rotated.
+	LogFileMaxSizeMB int `envvar:"LOG_FILE_MAX_SIZE_MB" default:"100"`
+	// LogFileMaxBackups is the number of rotated log files to keep.
+	LogFileMaxBackups int `envvar:"LOG_FILE_MAX_BACKUPS" default:"5"`
+	// LogFileVerbosity is the most verbose level of the logs written to
+	// LogFilePath, using the same values as VERBOSITY. Logs which are more
+	// verbose than VERBOSITY are never written.
+	LogFileVerbosity int `envvar:"LOG_FILE_VERBOSITY" default:"4"`
+	// LogSyslogAddr is the address of a syslog server that logs should be sent
+	// to. It is either "local" (to use the local syslog daemon) or a URL of the
+	// form "udp://host:port" or "tcp://host:port". If empty, logs are not sent
+	// to syslog. Syslog is not supported on Windows.
+	LogSyslogAddr string `envvar:"LOG_SYSLOG_ADDR" default:""`
+	// LogSyslogVerbosity is the most verbose level of the logs sent to
+	// syslog, using the same values as VERBOSITY.
+	LogSyslogVerbosity int `envvar:"LOG_SYSLOG_VERBOSITY" default:"4"`
+	// LogFluentdAddr is the TCP address (e.g. "localhost:24224") of a Fluentd
+	// or Fluent Bit forward input that logs should be sent to. If empty, logs
+	// are not sent to Fluentd.
+	LogFluentdAddr string `envvar:"LOG_FLUENTD_ADDR" default:""`
+	// LogFluentdTag is the tag used for logs sent to Fluentd.
+	LogFluentdTag string `envvar:"LOG_FLUENTD_TAG" default:"mesh"`
+	// LogFluentdVerbosity is the most verbose level of the logs sent to
+	// Fluentd, using the same values as VERBOSITY.
+	LogFluentdVerbosity int `envvar:"LOG_FLUENTD_VERBOSITY" default:"4"`
+	// MetricsStatsDAddr is the UDP address (e.g. "localhost:8125") of a StatsD
+	// server or Datadog agent that metrics should be pushed to. If empty,
+	// metrics are not sent to StatsD.
+	MetricsStatsDAddr string `envvar:"METRICS_STATSD_ADDR" default:""`
+	// MetricsStatsDFormat determines how tags are sent to StatsD. It is either
+	// "datadog" (tags are sent using the DogStatsD extension) or "plain" (tags
+	// are dropped).
+	MetricsStatsDFormat string `envvar:"METRICS_STATSD_FORMAT" default:"datadog"`
+	// MetricsOTLPEndpoint is the URL of an OpenTelemetry collector (or any
+	// other backend which supports OTLP/HTTP with JSON encoding) that metrics
+	// should be pushed to (e.g. "http://localhost:4318/v1/metrics"). If empty,
+	// metrics are not sent via OTLP.
+	MetricsOTLPEndpoint string `envvar:"METRICS_OTLP_ENDPOINT" default:""`
+	// MetricsOTLPHeaders is a comma-separated list of headers of the form
+	// "key=value" which are added to each request sent to MetricsOTLPEndpoint
+	// (e.g. for authentication).
+	MetricsOTLPHeaders string `envvar:"METRICS_OTLP_HEADERS" default:""`
+	// MetricsExportInterval is how often metrics are pushed to each of the
+	// metrics backends above.
+	MetricsExportInterval time.Duration `envvar:"METRICS_EXPORT_INTERVAL" default:"10s"`
+	// ArchiveKafkaBrokers is a comma-separated list of the addresses of the
+	// Kafka brokers (e.g. "localhost:9092") that every order event should be
+	// published to. If empty, order events are not published to Kafka.
+	ArchiveKafkaBrokers string `envvar:"ARCHIVE_KAFKA_BROKERS" default:""`
+	// ArchiveKafkaTLS enables TLS for connections to the Kafka brokers.
+	ArchiveKafkaTLS bool `envvar:"ARCHIVE_KAFKA_TLS" default:"false"`
+	// ArchiveKafkaUsername and ArchiveKafkaPassword, if set, are used to
+	// authenticate with the Kafka brokers using SASL/PLAIN.
+	ArchiveKafkaUsername string `envvar:"ARCHIVE_KAFKA_USERNAME" default:""`
+	ArchiveKafkaPassword string `envvar:"ARCHIVE_KAFKA_PASSWORD" default:""`
+	// ArchiveNATSURL is the URL of a NATS server with JetStream enabled (e.g.
+	// "nats://localhost:4222") that every order event should be published to.
+	// Use the "tls" scheme to require TLS. If empty, order events are not
+	// published to NATS. Only one of ArchiveKafkaBrokers and ArchiveNATSURL
+	// can be set.
+	ArchiveNATSURL string `envvar:"ARCHIVE_NATS_URL" default:""`
+	// ArchiveTopic is the Kafka topic or NATS subject that order events are
+	// published to. It may contain the placeholders "{chainId}" and
+	// "{endState}" (e.g. "mesh.{chainId}.{endState}") to split order events
+	// across several topics.
+	ArchiveTopic string `envvar:"ARCHIVE_TOPIC" default:"mesh.order_events"`
+	// ArchiveMaxBufferedEvents is the maximum number of order events that can
+	// be waiting to be published. If the message queue is unavailable for long
+	// enough that this limit is reached, the oldest order events are dropped.
+	ArchiveMaxBufferedEvents int `envvar:"ARCHIVE_MAX_BUFFERED_EVENTS" default:"100000"`
+	// BackupURL is the location that checkpoints of the database are
+	// periodically uploaded to. It is of the form "s3://bucket/prefix",
+	// "gs://bucket/prefix" or "file:///path/to/dir". If empty, the database is
+	// not backed up.
+	BackupURL string `envvar:"BACKUP_URL" default:""`
+	// BackupInterval is how often the database is backed up.
+	BackupInterval time.Duration `envvar:"BACKUP_INTERVAL" default:"6h"`
+	// BackupRetain is the number of most recent backups to keep. Older backups
+	// are deleted after each successful backup. If 0, backups are never
+	// deleted.
+	BackupRetain int `envvar:"BACKUP_RETAIN" default:"28"`
+	// BackupAccessKeyID and BackupSecretAccessKey are the AWS access keys used
+	// for S3 backups or the HMAC keys of a service account used for GCS
+	// backups.
+	BackupAccessKeyID     string `envvar:"BACKUP_ACCESS_KEY_ID" default:""`
+	BackupSecretAccessKey string `envvar:"BACKUP_SECRET_ACCESS_KEY" default:""`
+	// BackupSessionToken is an optional session token for temporary AWS
+	// credentials.
+	BackupSessionToken string `envvar:"BACKUP_SESSION_TOKEN" default:""`
+	// BackupRegion is the AWS region of the S3 bucket that backups are
+	// uploaded to. It is ignored for GCS.
+	BackupRegion string `envvar:"BACKUP_REGION" default:"us-east-1"`
+	// BackupEndpoint is the URL of an S3-compatible object storage service
+	// (e.g. MinIO) to use instead of AWS for "s3://" backup URLs.
+	BackupEndpoint string `envvar:"BACKUP_ENDPOINT" default:""`
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		os.Exit(runServiceCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplayCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-key" {
+		os.Exit(runRotateKeyCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		os.Exit(runRestoreCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "print-config" {
+		os.Exit(runPrintConfigCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "db" {
+		os.Exit(runDBCommand(os.Args[2:]))
+	}
+	if isWindowsService() {
+		os.Exit(runAsService())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	// Cancel the context upon receiving an interrupt or termination signal (or
+	// a console close event on Windows) so that core.App can close the database
+	// cleanly before the process exits.
+	handleShutdownSignals(cancel, done)
+	exitCode := run(ctx)
+	close(done)
+	os.Exit(exitCode)
+}
+
+// run starts core.App and the RPC servers and blocks until ctx is canceled or
+// one of them returns an error. It returns the exit code for the process.
+func run(ctx context.Context) int {
 	// Parse env vars
 	var coreConfig core.Config
 	if err := envvar.Parse(&coreConfig); err != nil {
@@ -37,13 +195,44 @@ func main() {
 	if err := envvar.Parse(&config); err != nil {
 		log.WithField("error", err.Error()).Fatal("could not parse environment variables")
 	}
+	// Note: This is a no-op unless Mesh was built with the "chaos" build tag.
+	if err := chaos.ConfigureFromEnv(); err != nil {
+		log.WithField("error", err.Error()).Fatal("could not configure fault injection")
+	}
 
 	// Start core.App.
 	app, err := core.New(coreConfig)
 	if err != nil {
 		log.WithField("error", err.Error()).Fatal("could not initialize app")
 	}
-	ctx, cancel := context.WithCancel(context.Background())
+
+	// Set up log sinks. This happens after core.New so that logs sent to the
+	// sinks include the fields added by the hooks that core.New installs.
+	closeLogSinks, err := setupLogSinks(config)
+	if err != nil {
+		log.WithField("error", err.Error()).Fatal("could not set up log sinks")
+	}
+	defer closeLogSinks()
+	metricsReporter, err := newMetricsReporter(config, app)
+	if err != nil {
+		log.WithField("error", err.Error()).Fatal("could not set up metrics exporters")
+	}
+	archiver, err := newArchiver(config, coreConfig)
+	if err != nil {
+		log.WithField("error", err.Error()).Fatal("could not set up order event archive")
+	}
+	backuper, err := newBackuper(config, app)
+	if err != nil {
+		log.WithField("error", err.Error()).Fatal("could not set up database backups")
+	}
+	if config.RPCAckBufferMaxBytes <= 0 {
+		log.Fatal("RPC_ACK_BUFFER_MAX_BYTES must be positive")
+	}
+	ackStreams, err := newAckStreamRegistry(coreConfig.DataDir, config.RPCAckBufferMaxBytes)
+	if err != nil {
+		log.WithField("error", err.Error()).Fatal("could not set up order event buffers for subscriptions")
+	}
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// Below, we will start several independent goroutines. We use separate
@@ -51,6 +240,19 @@ func main() {
 	// to exit.
 	wg := &sync.WaitGroup{}
 
+	// Start publishing order events if an archive is enabled. This happens
+	// before core.App is started so that no order events are missed.
+	if archiver != nil {
+		orderEvents := make(chan []*zeroex.OrderEvent)
+		subscription := app.SubscribeToOrderEvents(orderEvents)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer subscription.Unsubscribe()
+			archiver.Run(ctx, orderEvents)
+		}()
+	}
+
 	coreErrChan := make(chan error, 1)
 	wg.Add(1)
 	go func() {
@@ -60,13 +262,31 @@ func main() {
 		}
 	}()
 
+	// Start pushing metrics if any metrics exporters are enabled.
+	if metricsReporter != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metricsReporter.Run(ctx)
+		}()
+	}
+
+	// Start backing up the database if backups are enabled.
+	if backuper != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			backuper.Run(ctx)
+		}()
+	}
+
 	// Start WS RPC server.
 	wsRPCErrChan := make(chan error, 1)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		log.WithField("ws_rpc_addr", config.WSRPCAddr).Info("starting WS RPC server")
-		rpcServer := instantiateServer(ctx, app, config.WSRPCAddr)
+		rpcServer := instantiateServer(ctx, app, ackStreams, config.WSRPCAddr, config.RPCRequireReady)
 		go func() {
 			selectedRPCAddr, err := waitForSelectedAddress(ctx, rpcServer)
 			if err != nil {
@@ -85,7 +305,7 @@ func main() {
 	go func() {
 		defer wg.Done()
 		log.WithField("http_rpc_addr", config.HTTPRPCAddr).Info("starting HTTP RPC server")
-		rpcServer := instantiateServer(ctx, app, config.HTTPRPCAddr)
+		rpcServer := instantiateServer(ctx, app, ackStreams, config.HTTPRPCAddr, config.RPCRequireReady)
 		go func() {
 			selectedRPCAddr, err := waitForSelectedAddress(ctx, rpcServer)
 			if err != nil {
@@ -104,7 +324,7 @@ func main() {
 		// We exited without error. Wait for all goroutines to finish and then
 		// exit the process with a status code of 0.
 		wg.Wait()
-		os.Exit(0)
+		return 0
 	case err := <-coreErrChan:
 		cancel()
 		log.WithField("error", err.Error()).Error("core app exited with error")
@@ -119,5 +339,5 @@ func main() {
 	// If we reached here it means there was an error. Wait for all goroutines
 	// to finish and then exit with non-zero status code.
 	wg.Wait()
-	os.Exit(1)
+	return 1
 }
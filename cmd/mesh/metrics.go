@@ -0,0 +1,122 @@
+// +build !js
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/0xProject/0x-mesh/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// newMetricsReporter returns a reporter which pushes the stats of app to each
+// of the metrics exporters enabled in config. It returns nil if no exporters
+// are enabled.
+func newMetricsReporter(config standaloneConfig, app *core.App) (*metrics.Reporter, error) {
+	exporters := []metrics.Exporter{}
+	if config.MetricsStatsDAddr != "" {
+		format, err := metrics.ParseStatsDFormat(config.MetricsStatsDFormat)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := metrics.NewStatsDExporter(config.MetricsStatsDAddr, format)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+	if config.MetricsOTLPEndpoint != "" {
+		headers, err := metrics.ParseOTLPHeaders(config.MetricsOTLPHeaders)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, metrics.NewOTLPExporter(config.MetricsOTLPEndpoint, headers))
+	}
+	if len(exporters) == 0 {
+		return nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"metricsStatsDAddr":     config.MetricsStatsDAddr,
+		"metricsOTLPEndpoint":   config.MetricsOTLPEndpoint,
+		"metricsExportInterval": config.MetricsExportInterval,
+	}).Info("configured metrics exporters")
+	return metrics.NewReporter(metrics.Config{
+		Collect: func() ([]metrics.Measurement, error) {
+			return collectMetrics(app)
+		},
+		Exporters: exporters,
+		Interval:  config.MetricsExportInterval,
+	})
+}
+
+// collectMetrics returns measurements for the stats of app.
+func collectMetrics(app *core.App) ([]metrics.Measurement, error) {
+	stats, err := app.GetStats()
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{
+		"chain_id": strconv.Itoa(stats.EthereumChainID),
+		"peer_id":  stats.PeerID,
+	}
+	measurements := []metrics.Measurement{
+		{Name: "mesh.latest_block_number", Kind: metrics.Gauge, Value: float64(stats.LatestBlock.Number)},
+		{Name: "mesh.num_peers", Kind: metrics.Gauge, Value: float64(stats.NumPeers)},
+		{Name: "mesh.num_orders", Kind: metrics.Gauge, Value: float64(stats.NumOrders)},
+		{Name: "mesh.num_orders_including_removed", Kind: metrics.Gauge, Value: float64(stats.NumOrdersIncludingRemoved)},
+		{Name: "mesh.num_pinned_orders", Kind: metrics.Gauge, Value: float64(stats.NumPinnedOrders)},
+		{Name: "mesh.num_cold_orders", Kind: metrics.Gauge, Value: float64(stats.NumColdOrders)},
+		{Name: "mesh.max_orders", Kind: metrics.Gauge, Value: float64(stats.MaxOrders)},
+		{Name: "mesh.evicted_orders_last_24h", Kind: metrics.Gauge, Value: float64(stats.EvictedOrdersLast24h)},
+		{Name: "mesh.storage_used_bytes", Kind: metrics.Gauge, Value: float64(stats.StorageUsedBytes)},
+		{Name: "mesh.storage_utilization_percent", Kind: metrics.Gauge, Value: stats.StorageUtilizationPercent},
+		{Name: "mesh.eth_rpc_requests_sent_in_current_utc_day", Kind: metrics.Gauge, Value: float64(stats.EthRPCRequestsSentInCurrentUTCDay)},
+		{Name: "mesh.eth_rpc_rate_limit_expired_requests", Kind: metrics.Counter, Value: float64(stats.EthRPCRateLimitExpiredRequests)},
+		{Name: "mesh.eth_rpc_max_requests_per_24hr_utc", Kind: metrics.Gauge, Value: float64(stats.EthRPCMaxRequestsPer24HrUTC)},
+		{Name: "mesh.eth_rpc_remaining_requests_in_current_utc_day", Kind: metrics.Gauge, Value: float64(stats.EthRPCRemainingRequestsInCurrentUTCDay)},
+		{Name: "mesh.eth_rpc_budget_exhaustion_eta_seconds", Kind: metrics.Gauge, Value: float64(stats.EthRPCBudgetExhaustionETASeconds)},
+		{Name: "mesh.eth_rpc_cache_hits", Kind: metrics.Counter, Value: float64(stats.EthRPCCacheHits)},
+		{Name: "mesh.eth_rpc_cache_misses", Kind: metrics.Counter, Value: float64(stats.EthRPCCacheMisses)},
+		{Name: "mesh.eth_rpc_cache_entries", Kind: metrics.Gauge, Value: float64(stats.EthRPCCacheEntries)},
+		{Name: "mesh.inbound_queue_length", Kind: metrics.Gauge, Value: float64(stats.InboundQueueLength)},
+		{Name: "mesh.inbound_queue_dropped_messages", Kind: metrics.Counter, Value: float64(stats.InboundQueueDroppedMessages)},
+		{Name: "mesh.validation_memory_bytes", Kind: metrics.Gauge, Value: float64(stats.ValidationMemoryBytes)},
+		{Name: "mesh.validation_memory_shed_orders", Kind: metrics.Counter, Value: float64(stats.ValidationMemoryShedOrders)},
+		{Name: "mesh.deduped_order_submissions", Kind: metrics.Counter, Value: float64(stats.DedupedOrderSubmissions)},
+		{Name: "mesh.mismatched_domain_orders", Kind: metrics.Counter, Value: float64(stats.MismatchedDomainOrders)},
+		{Name: "mesh.dropped_order_events", Kind: metrics.Counter, Value: float64(stats.DroppedOrderEvents)},
+		{Name: "mesh.ingestion_paused", Kind: metrics.Gauge, Value: boolToFloat64(stats.IngestionPaused)},
+		{Name: "mesh.ordersync_bytes_saved", Kind: metrics.Counter, Value: float64(stats.OrderSyncBytesSaved)},
+		{Name: "mesh.db_slow_queries", Kind: metrics.Counter, Value: float64(stats.SlowDBQueries)},
+		{Name: "mesh.db_fragmentation_percent", Kind: metrics.Gauge, Value: stats.DBFragmentationPercent},
+		{Name: "mesh.db_reclaimed_bytes", Kind: metrics.Counter, Value: float64(stats.DBReclaimedBytes)},
+		{Name: "mesh.bootstrap_dial_failures", Kind: metrics.Counter, Value: float64(stats.BootstrapDialFailures)},
+		{Name: "mesh.unhealthy_bootstrap_peers", Kind: metrics.Gauge, Value: float64(stats.UnhealthyBootstrapPeers)},
+		{Name: "mesh.uptime_seconds", Kind: metrics.Gauge, Value: float64(stats.UptimeSeconds)},
+	}
+	for i := range measurements {
+		measurements[i].Tags = tags
+	}
+	for _, topicStats := range stats.Topics {
+		topicTags := map[string]string{
+			"chain_id": tags["chain_id"],
+			"peer_id":  tags["peer_id"],
+			"topic":    topicStats.Topic,
+		}
+		measurements = append(measurements,
+			metrics.Measurement{Name: "mesh.topic.num_orders", Kind: metrics.Gauge, Value: float64(topicStats.NumOrders), Tags: topicTags},
+			metrics.Measurement{Name: "mesh.topic.quota_dropped_messages", Kind: metrics.Counter, Value: float64(topicStats.QuotaDroppedMessages), Tags: topicTags},
+		)
+	}
+	return measurements, nil
+}
+
+// boolToFloat64 converts b to a metric value of 1 if b is true and 0 otherwise.
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
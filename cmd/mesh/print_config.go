@@ -0,0 +1,165 @@
+// +build !js
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+// maskedValue replaces the values of secrets in the output of "mesh
+// print-config".
+const maskedValue = "****"
+
+// secretEnvVars are the environment variables whose values are masked
+// entirely by "mesh print-config".
+var secretEnvVars = map[string]bool{
+	"BACKUP_SECRET_ACCESS_KEY": true,
+	"BACKUP_SESSION_TOKEN":     true,
+	"ARCHIVE_KAFKA_PASSWORD":   true,
+	"METRICS_OTLP_HEADERS":     true,
+}
+
+// secretURLEnvVars are the environment variables containing URLs which may
+// include credentials (e.g. an API key in the path of an Ethereum RPC URL).
+// Only their scheme and host are printed by "mesh print-config".
+var secretURLEnvVars = map[string]bool{
+	"ETHEREUM_RPC_URL":            true,
+	"ETHEREUM_VALIDATION_RPC_URL": true,
+	"ARCHIVE_NATS_URL":            true,
+}
+
+// runPrintConfigCommand handles the "mesh print-config [--format json|yaml]"
+// subcommand, which prints the configuration that Mesh would run with (i.e.
+// the values of all environment variables, including defaults) with secrets
+// masked, and then exits. It returns the exit code for the process.
+func runPrintConfigCommand(args []string) int {
+	flags := flag.NewFlagSet("print-config", flag.ContinueOnError)
+	format := flags.String("format", "json", "the output format (json or yaml)")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || (*format != "json" && *format != "yaml") {
+		fmt.Fprintln(os.Stderr, "usage: mesh print-config [--format json|yaml]")
+		return 2
+	}
+	var coreConfig core.Config
+	if err := envvar.Parse(&coreConfig); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	var config standaloneConfig
+	if err := envvar.Parse(&config); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	effectiveConfig := effectiveConfig(coreConfig, config)
+	var err error
+	if *format == "yaml" {
+		err = writeConfigYAML(os.Stdout, effectiveConfig)
+	} else {
+		err = writeConfigJSON(os.Stdout, effectiveConfig)
+	}
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not print configuration")
+		return 1
+	}
+	return 0
+}
+
+// effectiveConfig returns the values of the fields of the given config structs
+// keyed by environment variable, with secrets masked. Durations are formatted
+// as strings (e.g. "10s"). Fields which can't be set via environment variable
+// are omitted.
+func effectiveConfig(configs ...interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, config := range configs {
+		configVal := reflect.ValueOf(config)
+		configType := configVal.Type()
+		for i := 0; i < configType.NumField(); i++ {
+			name := configType.Field(i).Tag.Get("envvar")
+			if name == "" || name == "-" {
+				continue
+			}
+			var value interface{}
+			switch fieldVal := configVal.Field(i).Interface().(type) {
+			case time.Duration:
+				value = fieldVal.String()
+			case string:
+				value = maskSecret(name, fieldVal)
+			default:
+				value = fieldVal
+			}
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// maskSecret returns the value that "mesh print-config" prints for the given
+// environment variable.
+func maskSecret(name string, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretEnvVars[name] {
+		return maskedValue
+	}
+	if secretURLEnvVars[name] {
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" {
+			return maskedValue
+		}
+		masked := u.Scheme + "://" + u.Host
+		if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			masked += "/" + maskedValue
+		}
+		return masked
+	}
+	return value
+}
+
+func writeConfigJSON(w io.Writer, values map[string]interface{}) error {
+	encoded, err := json.MarshalIndent(values, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(encoded))
+	return err
+}
+
+// writeConfigYAML writes the given values as a YAML mapping sorted by key.
+// Note: All values are scalars, so a YAML library is not needed. Strings are
+// written as double-quoted scalars, for which the escape sequences of
+// strconv.Quote are valid.
+func writeConfigYAML(w io.Writer, values map[string]interface{}) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var formatted string
+		switch value := values[name].(type) {
+		case string:
+			formatted = strconv.Quote(value)
+		case float64:
+			formatted = strconv.FormatFloat(value, 'g', -1, 64)
+		default:
+			formatted = fmt.Sprint(value)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", name, formatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+// runReplayCommand handles the "mesh replay <path>" subcommand, which replays a
+// recording created with REPLAY_RECORD_PATH into a fresh node and then exits.
+// The node is configured with the same environment variables as usual, but
+// DATA_DIR should point to an empty directory. It returns the exit code for
+// the process.
+func runReplayCommand(args []string) int {
+	if len(args) != 1 {
+		log.Error("usage: mesh replay <path>")
+		return 1
+	}
+	var coreConfig core.Config
+	if err := envvar.Parse(&coreConfig); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	app, err := core.New(coreConfig)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not initialize app")
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	handleShutdownSignals(cancel, done)
+
+	if _, err := app.Replay(ctx, args[0]); err != nil {
+		log.WithField("error", err.Error()).Error("could not replay recording")
+		return 1
+	}
+	return 0
+}
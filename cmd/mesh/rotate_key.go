@@ -0,0 +1,67 @@
+// +build !js
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core"
+	"github.com/plaid/go-envvar/envvar"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultKeyRotationGracePeriod is the grace period used by "mesh rotate-key"
+// if none is given.
+const defaultKeyRotationGracePeriod = 7 * 24 * time.Hour
+
+// rotateKeyConfig contains the environment variables used by the "mesh
+// rotate-key" subcommand.
+type rotateKeyConfig struct {
+	// DataDir is the directory which contains the private key to rotate. It
+	// must be the same as the DATA_DIR used to run Mesh.
+	DataDir string `envvar:"DATA_DIR" default:"0x_mesh"`
+}
+
+// runRotateKeyCommand handles the "mesh rotate-key [grace period]" subcommand,
+// which replaces the private key of the node with a new one and then exits.
+// The grace period (e.g. "72h") determines how long the node announces the
+// rotation to its peers and defaults to 7 days. It returns the exit code for
+// the process.
+func runRotateKeyCommand(args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: mesh rotate-key [grace period]")
+		return 2
+	}
+	gracePeriod := defaultKeyRotationGracePeriod
+	if len(args) == 1 {
+		var err error
+		gracePeriod, err = time.ParseDuration(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "usage: mesh rotate-key [grace period]")
+			return 2
+		}
+	}
+	var config rotateKeyConfig
+	if err := envvar.Parse(&config); err != nil {
+		log.WithField("error", err.Error()).Error("could not parse environment variables")
+		return 1
+	}
+	rotation, err := core.RotatePrivateKey(config.DataDir, gracePeriod)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not rotate private key")
+		return 1
+	}
+	previousPeerID, err := rotation.PreviousPeerID()
+	if err != nil {
+		log.WithField("error", err.Error()).Error("could not rotate private key")
+		return 1
+	}
+	log.WithFields(log.Fields{
+		"previousPeerID": previousPeerID.String(),
+		"newPeerID":      rotation.NewPeerID.String(),
+		"expirationTime": rotation.ExpirationTime,
+	}).Info("rotated private key (restart Mesh to start using it)")
+	return 0
+}
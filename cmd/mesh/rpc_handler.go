@@ -15,10 +15,15 @@ import (
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/core"
+	"github.com/0xProject/0x-mesh/core/softcancel"
 	"github.com/0xProject/0x-mesh/rpc"
+	"github.com/0xProject/0x-mesh/rpc/ackstream"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/0xProject/0x-mesh/zeroex/orderwatch"
+	"github.com/ethereum/go-ethereum/common"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	log "github.com/sirupsen/logrus"
 )
@@ -30,6 +35,9 @@ const orderEventsBufferSize = 8000
 type rpcHandler struct {
 	app *core.App
 	ctx context.Context
+	// ackStreams is shared by the WS and HTTP servers so that subscriptions
+	// in ack mode can be acknowledged via either of them.
+	ackStreams *ackstream.Registry
 }
 
 // waitForSelectedAddress wait for the server to start listening and select an address.
@@ -45,26 +53,35 @@ func waitForSelectedAddress(ctx context.Context, rpcServer *rpc.Server) (string,
 	return rpcServer.Addr().String(), nil
 }
 
-// instantiateServer instantiates a new RPC server with the rpcHandler.
-func instantiateServer(ctx context.Context, app *core.App, rpcAddr string) *rpc.Server {
+// instantiateServer instantiates a new RPC server with the rpcHandler. If
+// requireReady is true, the server rejects requests until app is ready.
+func instantiateServer(ctx context.Context, app *core.App, ackStreams *ackstream.Registry, rpcAddr string, requireReady bool) *rpc.Server {
 	// Initialize the JSON RPC WebSocket server (but don't start it yet).
 	rpcHandler := &rpcHandler{
-		app: app,
-		ctx: ctx,
+		app:        app,
+		ctx:        ctx,
+		ackStreams: ackStreams,
 	}
 	rpcServer, err := rpc.NewServer(rpcAddr, rpcHandler)
 	if err != nil {
 		return nil
 	}
+	if requireReady {
+		rpcServer.SetReadinessCheck(app.IsReady)
+	}
 	return rpcServer
 }
 
 // GetOrders is called when an RPC client calls GetOrders.
-func (handler *rpcHandler) GetOrders(page, perPage int, snapshotID string) (result *types.GetOrdersResponse, err error) {
+func (handler *rpcHandler) GetOrders(page, perPage int, snapshotID string, opts types.GetOrdersOpts) (result *types.GetOrdersResponse, err error) {
 	log.WithFields(map[string]interface{}{
-		"page":       page,
-		"perPage":    perPage,
-		"snapshotID": snapshotID,
+		"page":                page,
+		"perPage":             perPage,
+		"snapshotID":          snapshotID,
+		"maxStalenessSeconds": opts.MaxStalenessSeconds,
+		"takerAddress":        opts.TakerAddress,
+		"sort":                opts.Sort,
+		"nft":                 opts.NFT,
 	}).Debug("received GetOrders request via RPC")
 	// Catch panics, log stack trace and return RPC error message
 	defer func() {
@@ -82,7 +99,7 @@ func (handler *rpcHandler) GetOrders(page, perPage int, snapshotID string) (resu
 			err = errors.New("method handler crashed in GetOrders RPC call (check logs for stack trace)")
 		}
 	}()
-	getOrdersResponse, err := handler.app.GetOrders(page, perPage, snapshotID)
+	getOrdersResponse, err := handler.app.GetOrders(page, perPage, snapshotID, opts)
 	if err != nil {
 		if _, ok := err.(core.ErrSnapshotNotFound); ok {
 			return nil, err
@@ -90,6 +107,12 @@ func (handler *rpcHandler) GetOrders(page, perPage int, snapshotID string) (resu
 		if _, ok := err.(core.ErrPerPageZero); ok {
 			return nil, err
 		}
+		if _, ok := err.(core.ErrInvalidSort); ok {
+			return nil, err
+		}
+		if _, ok := err.(core.ErrInvalidNFTFilter); ok {
+			return nil, err
+		}
 		// We don't want to leak internal error details to the RPC client.
 		log.WithField("error", err.Error()).Error("internal error in GetOrders RPC call")
 		return nil, constants.ErrInternal
@@ -119,7 +142,11 @@ func (handler *rpcHandler) AddOrders(signedOrdersRaw []*json.RawMessage, opts ty
 			err = errors.New("method handler crashed in AddOrders RPC call (check logs for stack trace)")
 		}
 	}()
-	validationResults, err := handler.app.AddOrders(handler.ctx, signedOrdersRaw, opts.Pinned)
+	ctx := handler.ctx
+	if opts.Trace {
+		ctx = ordervalidator.WithTracing(ctx)
+	}
+	validationResults, err := handler.app.AddOrders(ctx, signedOrdersRaw, opts.Pinned)
 	if err != nil {
 		// We don't want to leak internal error details to the RPC client.
 		log.WithField("error", err.Error()).Error("internal error in AddOrders RPC call")
@@ -128,6 +155,37 @@ func (handler *rpcHandler) AddOrders(signedOrdersRaw []*json.RawMessage, opts ty
 	return validationResults, nil
 }
 
+// RevalidateOrders is called when an RPC client calls RevalidateOrders.
+func (handler *rpcHandler) RevalidateOrders(orderHashes []common.Hash) (results *ordervalidator.ValidationResults, err error) {
+	log.WithField("count", len(orderHashes)).Info("received RevalidateOrders request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "RevalidateOrders",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in RevalidateOrders RPC call (check logs for stack trace)")
+		}
+	}()
+	validationResults, err := handler.app.RevalidateOrders(handler.ctx, orderHashes)
+	if err != nil {
+		if _, ok := err.(core.ErrTooManyOrderHashes); ok {
+			return nil, err
+		}
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in RevalidateOrders RPC call")
+		return nil, constants.ErrInternal
+	}
+	return validationResults, nil
+}
+
 // AddPeer is called when an RPC client calls AddPeer,
 func (handler *rpcHandler) AddPeer(peerInfo peerstore.PeerInfo) (err error) {
 	log.Debug("received AddPeer request via RPC")
@@ -181,9 +239,334 @@ func (handler *rpcHandler) GetStats() (result *types.Stats, err error) {
 	return getStatsResponse, nil
 }
 
+// GetMakers is called when an RPC client calls GetMakers.
+func (handler *rpcHandler) GetMakers(opts types.GetMakersOpts) (result []*types.MakerInfo, err error) {
+	log.WithField("numMakerAddresses", len(opts.MakerAddresses)).Debug("received GetMakers request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetMakers",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetMakers RPC call (check logs for stack trace)")
+		}
+	}()
+	makerInfos, err := handler.app.GetMakers(opts)
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetMakers RPC call")
+		return nil, constants.ErrInternal
+	}
+	return makerInfos, nil
+}
+
+// GetMakerEpoch is called when an RPC client calls GetMakerEpoch.
+func (handler *rpcHandler) GetMakerEpoch(makerAddress, senderAddress common.Address) (result *types.MakerEpoch, err error) {
+	log.WithFields(log.Fields{
+		"makerAddress":  makerAddress.Hex(),
+		"senderAddress": senderAddress.Hex(),
+	}).Debug("received GetMakerEpoch request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetMakerEpoch",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetMakerEpoch RPC call (check logs for stack trace)")
+		}
+	}()
+	makerEpoch, err := handler.app.GetMakerEpoch(handler.ctx, makerAddress, senderAddress)
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetMakerEpoch RPC call")
+		return nil, constants.ErrInternal
+	}
+	return makerEpoch, nil
+}
+
+// GetBlocks is called when an RPC client calls GetBlocks.
+func (handler *rpcHandler) GetBlocks(fromBlock, toBlock int) (result []*types.Block, err error) {
+	log.WithFields(log.Fields{
+		"fromBlock": fromBlock,
+		"toBlock":   toBlock,
+	}).Debug("received GetBlocks request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetBlocks",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetBlocks RPC call (check logs for stack trace)")
+		}
+	}()
+	blocks, err := handler.app.GetBlocks(fromBlock, toBlock)
+	if err != nil {
+		if err == core.ErrBlockHistoryDisabled || err == core.ErrInvalidBlockRange {
+			return nil, err
+		}
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetBlocks RPC call")
+		return nil, constants.ErrInternal
+	}
+	return blocks, nil
+}
+
+// GetOrderEventsSince is called when an RPC client calls GetOrderEventsSince.
+func (handler *rpcHandler) GetOrderEventsSince(epoch string, sequenceNumber uint64) (result []*zeroex.OrderEvent, err error) {
+	log.WithFields(log.Fields{
+		"epoch":          epoch,
+		"sequenceNumber": sequenceNumber,
+	}).Debug("received GetOrderEventsSince request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetOrderEventsSince",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetOrderEventsSince RPC call (check logs for stack trace)")
+		}
+	}()
+	orderEvents, err := handler.app.GetOrderEventsSince(epoch, sequenceNumber)
+	if err != nil {
+		if err == orderwatch.ErrOrderEventsUnavailable || err == orderwatch.ErrOrderEventsEpochMismatch {
+			return nil, err
+		}
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetOrderEventsSince RPC call")
+		return nil, constants.ErrInternal
+	}
+	return orderEvents, nil
+}
+
+// GetOrdersAffectedByTransaction is called when an RPC client calls
+// GetOrdersAffectedByTransaction.
+func (handler *rpcHandler) GetOrdersAffectedByTransaction(txHash common.Hash) (result []*zeroex.OrderEvent, err error) {
+	log.WithField("txHash", txHash.Hex()).Debug("received GetOrdersAffectedByTransaction request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetOrdersAffectedByTransaction",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetOrdersAffectedByTransaction RPC call (check logs for stack trace)")
+		}
+	}()
+	orderEvents, err := handler.app.GetOrdersAffectedByTransaction(txHash)
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetOrdersAffectedByTransaction RPC call")
+		return nil, constants.ErrInternal
+	}
+	return orderEvents, nil
+}
+
+// GetMarkets is called when an RPC client calls GetMarkets.
+func (handler *rpcHandler) GetMarkets() (result []*types.MarketInfo, err error) {
+	log.Debug("received GetMarkets request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetMarkets",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetMarkets RPC call (check logs for stack trace)")
+		}
+	}()
+	marketInfos, err := handler.app.GetMarkets()
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in GetMarkets RPC call")
+		return nil, constants.ErrInternal
+	}
+	return marketInfos, nil
+}
+
+// SetLogLevel is called when an RPC client calls SetLogLevel.
+func (handler *rpcHandler) SetLogLevel(verbosity int, debugSubsystems []string) (err error) {
+	log.WithFields(log.Fields{
+		"verbosity":       verbosity,
+		"debugSubsystems": debugSubsystems,
+	}).Debug("received SetLogLevel request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "SetLogLevel",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in SetLogLevel RPC call (check logs for stack trace)")
+		}
+	}()
+	// SetLogLevel only returns validation errors, which are safe to return to
+	// the client.
+	return handler.app.SetLogLevel(verbosity, debugSubsystems)
+}
+
+// Resync is called when an RPC client calls Resync.
+func (handler *rpcHandler) Resync(peerIDs []peer.ID) (result *types.ResyncResponse, err error) {
+	log.WithField("peers", peerIDs).Info("received Resync request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "Resync",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in Resync RPC call (check logs for stack trace)")
+		}
+	}()
+	resyncResponse, err := handler.app.Resync(handler.ctx, peerIDs)
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in Resync RPC call")
+		return nil, constants.ErrInternal
+	}
+	return resyncResponse, nil
+}
+
+// SoftCancelOrder is called when an RPC client calls SoftCancelOrder.
+func (handler *rpcHandler) SoftCancelOrder(softCancel *softcancel.SoftCancel) (err error) {
+	log.WithFields(log.Fields{
+		"orderHash":    softCancel.OrderHash.Hex(),
+		"makerAddress": softCancel.MakerAddress.Hex(),
+	}).Debug("received SoftCancelOrder request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "SoftCancelOrder",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in SoftCancelOrder RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.SoftCancelOrder(handler.ctx, softCancel); err != nil {
+		switch err {
+		case core.ErrSoftCancelsDisabled, softcancel.ErrInvalidSignature, zeroex.ErrSignatureNotRecoverable, zeroex.ErrInvalidSignatureLength, zeroex.ErrInvalidSignatureV:
+			return err
+		}
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in SoftCancelOrder RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// PauseIngestion is called when an RPC client calls PauseIngestion.
+func (handler *rpcHandler) PauseIngestion() (err error) {
+	log.Info("received PauseIngestion request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "PauseIngestion",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in PauseIngestion RPC call (check logs for stack trace)")
+		}
+	}()
+	handler.app.PauseIngestion()
+	return nil
+}
+
+// ResumeIngestion is called when an RPC client calls ResumeIngestion.
+func (handler *rpcHandler) ResumeIngestion() (err error) {
+	log.Info("received ResumeIngestion request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "ResumeIngestion",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in ResumeIngestion RPC call (check logs for stack trace)")
+		}
+	}()
+	handler.app.ResumeIngestion()
+	return nil
+}
+
 // SubscribeToOrders is called when an RPC client sends a `mesh_subscribe` request with the `orders` topic parameter
-func (handler *rpcHandler) SubscribeToOrders(ctx context.Context) (result *ethrpc.Subscription, err error) {
-	log.Debug("received order event subscription request via RPC")
+func (handler *rpcHandler) SubscribeToOrders(ctx context.Context, opts types.SubscribeToOrdersOpts) (result *ethrpc.Subscription, err error) {
+	log.WithFields(log.Fields{
+		"coalesceIntervalMs": opts.CoalesceIntervalMs,
+		"makerAddress":       opts.MakerAddress,
+		"assetDataPairs":     opts.AssetDataPairs,
+		"endStates":          opts.EndStates,
+		"ackMode":            opts.AckMode,
+	}).Debug("received order event subscription request via RPC")
 	// Catch panics, log stack trace and return RPC error message
 	defer func() {
 		if r := recover(); r != nil {
@@ -200,7 +583,15 @@ func (handler *rpcHandler) SubscribeToOrders(ctx context.Context) (result *ethrp
 			err = errors.New("method handler crashed in SubscribeToOrders RPC call (check logs for stack trace)")
 		}
 	}()
-	subscription, err := SetupOrderStream(ctx, handler.app)
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	var subscription *ethrpc.Subscription
+	if opts.AckMode {
+		subscription, err = setupAckOrderStream(ctx, handler.app, handler.ackStreams, opts)
+	} else {
+		subscription, err = SetupOrderStream(ctx, handler.app, opts)
+	}
 	if err != nil {
 		log.WithField("error", err.Error()).Error("internal error in `mesh_subscribe` to `orders` RPC call")
 		return nil, constants.ErrInternal
@@ -208,8 +599,12 @@ func (handler *rpcHandler) SubscribeToOrders(ctx context.Context) (result *ethrp
 	return subscription, nil
 }
 
-// SetupOrderStream sets up the order stream for a subscription
-func SetupOrderStream(ctx context.Context, app *core.App) (*ethrpc.Subscription, error) {
+// SetupOrderStream sets up the order stream for a subscription. Order events
+// which don't match the filters in opts are not sent. If
+// opts.CoalesceIntervalMs is greater than 0, order events are buffered and sent
+// at most once per interval, and successive fill updates for the same order
+// are coalesced into a single event.
+func SetupOrderStream(ctx context.Context, app *core.App, opts types.SubscribeToOrdersOpts) (*ethrpc.Subscription, error) {
 	notifier, supported := ethrpc.NotifierFromContext(ctx)
 	if !supported {
 		return &ethrpc.Subscription{}, ethrpc.ErrNotificationsUnsupported
@@ -217,43 +612,47 @@ func SetupOrderStream(ctx context.Context, app *core.App) (*ethrpc.Subscription,
 
 	rpcSub := notifier.CreateSubscription()
 
+	// notify sends the given order events to the subscriber. It returns false if
+	// the subscription should be closed.
+	notify := func(orderEvents []*zeroex.OrderEvent) bool {
+		return notifyOrderSubscriber(notifier, rpcSub.ID, orderEvents, len(orderEvents))
+	}
+
 	go func() {
 		orderEventsChan := make(chan []*zeroex.OrderEvent, orderEventsBufferSize)
 		orderWatcherSub := app.SubscribeToOrderEvents(orderEventsChan)
 		defer orderWatcherSub.Unsubscribe()
 
+		// If coalescing is disabled, flushChan is nil and is never selected.
+		var coalescer *zeroex.OrderEventCoalescer
+		var flushChan <-chan time.Time
+		if opts.CoalesceIntervalMs > 0 {
+			coalescer = zeroex.NewOrderEventCoalescer()
+			ticker := time.NewTicker(time.Duration(opts.CoalesceIntervalMs) * time.Millisecond)
+			defer ticker.Stop()
+			flushChan = ticker.C
+		}
+
 		for {
 			select {
 			case orderEvents := <-orderEventsChan:
-				err := notifier.Notify(rpcSub.ID, orderEvents)
-				if err != nil {
-					// TODO(fabio): The current implementation of `notifier.Notify` returns a
-					// `write: broken pipe` error when it is called _after_ the client has
-					// disconnected but before the corresponding error is received on the
-					// `rpcSub.Err()` channel. This race-condition is not problematic beyond
-					// the unnecessary computation and log spam resulting from it. Once this is
-					// fixed upstream, give all logs an `Error` severity.
-					logEntry := log.WithFields(map[string]interface{}{
-						"error":            err.Error(),
-						"subscriptionType": "orders",
-						"orderEvents":      len(orderEvents),
-					})
-					message := "error while calling notifier.Notify"
-					// If the network connection disconnects for longer then ~2mins and then comes
-					// back up, we've noticed the call to `notifier.Notify` return `i/o timeout`
-					// `net.OpError` errors everytime it's called and no values are sent over
-					// `rpcSub.Err()` nor `notifier.Closed()`. In order to stop the error from
-					// endlessly re-occuring, we unsubscribe and return for encountering this type of
-					// error.
-					if _, ok := err.(*net.OpError); ok {
-						logEntry.Trace(message)
-						return
-					}
-					if strings.Contains(err.Error(), "write: broken pipe") {
-						logEntry.Trace(message)
-					} else {
-						logEntry.Error(message)
-					}
+				orderEvents = opts.FilterOrderEvents(orderEvents)
+				if len(orderEvents) == 0 {
+					continue
+				}
+				if coalescer != nil {
+					coalescer.Add(orderEvents)
+					continue
+				}
+				if !notify(orderEvents) {
+					return
+				}
+			case <-flushChan:
+				if coalescer.Len() == 0 {
+					continue
+				}
+				if !notify(coalescer.Flush()) {
+					return
 				}
 			case err := <-rpcSub.Err():
 				if err != nil {
@@ -270,3 +669,40 @@ func SetupOrderStream(ctx context.Context, app *core.App) (*ethrpc.Subscription,
 
 	return rpcSub, nil
 }
+
+// notifyOrderSubscriber sends data, which contains numOrderEvents order
+// events, to the order event subscription with the given ID. It returns false
+// if the subscription should be closed.
+func notifyOrderSubscriber(notifier *ethrpc.Notifier, id ethrpc.ID, data interface{}, numOrderEvents int) bool {
+	err := notifier.Notify(id, data)
+	if err != nil {
+		// TODO(fabio): The current implementation of `notifier.Notify` returns a
+		// `write: broken pipe` error when it is called _after_ the client has
+		// disconnected but before the corresponding error is received on the
+		// `rpcSub.Err()` channel. This race-condition is not problematic beyond
+		// the unnecessary computation and log spam resulting from it. Once this is
+		// fixed upstream, give all logs an `Error` severity.
+		logEntry := log.WithFields(map[string]interface{}{
+			"error":            err.Error(),
+			"subscriptionType": "orders",
+			"orderEvents":      numOrderEvents,
+		})
+		message := "error while calling notifier.Notify"
+		// If the network connection disconnects for longer then ~2mins and then comes
+		// back up, we've noticed the call to `notifier.Notify` return `i/o timeout`
+		// `net.OpError` errors everytime it's called and no values are sent over
+		// `rpcSub.Err()` nor `notifier.Closed()`. In order to stop the error from
+		// endlessly re-occuring, we unsubscribe and return for encountering this type of
+		// error.
+		if _, ok := err.(*net.OpError); ok {
+			logEntry.Trace(message)
+			return false
+		}
+		if strings.Contains(err.Error(), "write: broken pipe") {
+			logEntry.Trace(message)
+		} else {
+			logEntry.Error(message)
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+// +build !js,!windows
+
+package main
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// runServiceCommand handles the `mesh service` subcommand. Services are only
+// supported on Windows.
+func runServiceCommand(args []string) int {
+	log.Error("the service subcommand is only supported on Windows")
+	return 1
+}
+
+// isWindowsService returns true if the process was started by the Windows
+// service control manager. It always returns false on other platforms.
+func isWindowsService() bool {
+	return false
+}
+
+// runAsService is never called on platforms other than Windows.
+func runAsService() int {
+	return 1
+}
+
+// handleConsoleCloseEvents is a no-op on platforms other than Windows.
+func handleConsoleCloseEvents(cancel context.CancelFunc, done <-chan struct{}) {}
@@ -0,0 +1,301 @@
+// +build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const (
+	serviceName        = "0xMesh"
+	serviceDisplayName = "0x Mesh"
+	serviceDescription = "0x Mesh node for sharing 0x orders"
+	// serviceLogFileName is the name of the file (relative to DATA_DIR) that
+	// logs are written to when Mesh is running as a service.
+	serviceLogFileName = "mesh.log"
+	// serviceStopTimeout is how long `mesh service stop` waits for the service
+	// to report that it has stopped.
+	serviceStopTimeout = 30 * time.Second
+	// serviceStopWaitHint is the amount of time we tell the service control
+	// manager that a pending stop operation is expected to take.
+	serviceStopWaitHint = 10 * time.Second
+	serviceUsage        = "usage: mesh service <install|uninstall|start|stop>"
+)
+
+// runServiceCommand handles the `mesh service` subcommand and returns the exit
+// code for the process.
+func runServiceCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, serviceUsage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "install":
+		err = installService()
+	case "uninstall":
+		err = uninstallService()
+	case "start":
+		err = startService()
+	case "stop":
+		err = stopService()
+	default:
+		fmt.Fprintln(os.Stderr, serviceUsage)
+		return 2
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":   err.Error(),
+			"command": args[0],
+		}).Error("service command failed")
+		return 1
+	}
+	return 0
+}
+
+// isWindowsService returns true if the process was started by the Windows
+// service control manager.
+func isWindowsService() bool {
+	isInteractive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		log.WithError(err).Fatal("could not determine if running in an interactive session")
+	}
+	return !isInteractive
+}
+
+// runAsService runs Mesh under the Windows service control manager and returns
+// the exit code for the process.
+func runAsService() int {
+	// Services do not have a console, so we write logs to a file in the data
+	// directory instead. DATA_DIR is always an absolute path here because it is
+	// set by installService.
+	if dataDir := os.Getenv("DATA_DIR"); dataDir != "" {
+		if err := os.MkdirAll(dataDir, os.ModePerm); err != nil {
+			return 1
+		}
+		logFile, err := os.OpenFile(filepath.Join(dataDir, serviceLogFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return 1
+		}
+		defer logFile.Close()
+		log.SetOutput(logFile)
+	}
+	if err := svc.Run(serviceName, &meshService{}); err != nil {
+		log.WithError(err).Error("could not run service")
+		return 1
+	}
+	return 0
+}
+
+// meshService implements svc.Handler.
+type meshService struct{}
+
+var _ svc.Handler = &meshService{}
+
+// Execute runs Mesh and translates service control requests into context
+// cancellation so that the database is closed cleanly when the service is
+// stopped or the machine shuts down.
+func (m *meshService) Execute(args []string, requests <-chan svc.ChangeRequest, statuses chan<- svc.Status) (bool, uint32) {
+	statuses <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exitCodeChan := make(chan int, 1)
+	go func() {
+		exitCodeChan <- run(ctx)
+	}()
+	statuses <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case exitCode := <-exitCodeChan:
+			// Mesh exited on its own, most likely due to an error.
+			return exitCode != 0, uint32(exitCode)
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				statuses <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				log.WithField("cmd", req.Cmd).Info("received service stop request; shutting down")
+				statuses <- svc.Status{State: svc.StopPending, WaitHint: uint32(serviceStopWaitHint / time.Millisecond)}
+				cancel()
+				exitCode := <-exitCodeChan
+				return exitCode != 0, uint32(exitCode)
+			default:
+				log.WithField("cmd", req.Cmd).Warn("received unexpected service control request")
+			}
+		}
+	}
+}
+
+func installService() error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	exePath, err = filepath.Abs(exePath)
+	if err != nil {
+		return err
+	}
+	environment, err := serviceEnvironment()
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+	s, err = m.CreateService(serviceName, exePath, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	if err := setServiceEnvironment(environment); err != nil {
+		_ = s.Delete()
+		return err
+	}
+	log.WithFields(log.Fields{
+		"name":        serviceName,
+		"path":        exePath,
+		"environment": environment,
+	}).Info("installed service")
+	return nil
+}
+
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	log.WithField("name", serviceName).Info("uninstalled service")
+	return nil
+}
+
+func startService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+	return s.Start()
+}
+
+func stopService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(serviceStopTimeout)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		status, err = s.Query()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceEnvironment returns the environment variables (in KEY=value form)
+// that Mesh should be started with when running as a service. Services do not
+// inherit the environment of the user that installed them, so we capture every
+// config environment variable that is currently set. DATA_DIR is always
+// included and converted to an absolute path, since services are started with
+// a working directory of %SystemRoot%\System32.
+func serviceEnvironment() ([]string, error) {
+	environment := []string{}
+	dataDirSet := false
+	for _, configType := range []reflect.Type{
+		reflect.TypeOf(core.Config{}),
+		reflect.TypeOf(standaloneConfig{}),
+	} {
+		for i := 0; i < configType.NumField(); i++ {
+			field := configType.Field(i)
+			name := field.Tag.Get("envvar")
+			if name == "" {
+				continue
+			}
+			value, found := os.LookupEnv(name)
+			if name == "DATA_DIR" {
+				if !found {
+					value = field.Tag.Get("default")
+				}
+				absDataDir, err := filepath.Abs(value)
+				if err != nil {
+					return nil, err
+				}
+				value = absDataDir
+				found = true
+				dataDirSet = true
+			}
+			if found {
+				environment = append(environment, name+"="+value)
+			}
+		}
+	}
+	if !dataDirSet {
+		return nil, errors.New("could not determine DATA_DIR")
+	}
+	return environment, nil
+}
+
+// setServiceEnvironment stores the given environment variables in the
+// service's registry key. The service control manager passes them to the
+// service process when it is started.
+func setServiceEnvironment(environment []string) error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+serviceName, registry.SET_VALUE)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	return key.SetStringsValue("Environment", environment)
+}
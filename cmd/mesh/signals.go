@@ -0,0 +1,33 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// handleShutdownSignals cancels the given context when the process receives an
+// interrupt or termination signal. On Windows it additionally handles console
+// close, logoff, and shutdown events. The done channel should be closed once
+// the app has finished shutting down.
+func handleShutdownSignals(cancel context.CancelFunc, done <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			log.WithField("signal", sig.String()).Info("received signal; shutting down")
+			cancel()
+		case <-done:
+		}
+		// Stop relaying signals so that a second interrupt kills the process
+		// immediately if shutting down takes too long.
+		signal.Stop(signals)
+	}()
+	handleConsoleCloseEvents(cancel, done)
+}
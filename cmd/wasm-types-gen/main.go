@@ -0,0 +1,247 @@
+// wasm-types-gen generates the TypeScript definitions for the types which are
+// passed from the Go Wasm code to the TypeScript bindings in
+// packages/browser-lite. The definitions are derived from the Go structs so
+// that they can't drift apart.
+//
+// Usage (from the root of the repository):
+//
+//	go run ./cmd/wasm-types-gen          # regenerate the definitions
+//	go run ./cmd/wasm-types-gen -check   # fail if the definitions are stale
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const defaultOutput = "packages/browser-lite/src/wasm_types.ts"
+
+const header = `// Code generated by wasm-types-gen. DO NOT EDIT.
+//
+// To regenerate, run 'go run ./cmd/wasm-types-gen' from the root of the
+// repository.
+`
+
+// rootTypes are the types which are passed across the Wasm boundary. Any
+// struct types they reference which aren't in externalTypes are generated as
+// well.
+var rootTypes = []reflect.Type{
+	reflect.TypeOf(zeroex.OrderEvent{}),
+	reflect.TypeOf(ordervalidator.ValidationResults{}),
+	reflect.TypeOf(types.Stats{}),
+}
+
+// externalTypes maps Go types to the TypeScript types they are converted to.
+// Named TypeScript types are defined by hand in types.ts, either because their
+// JSValue representation differs from the Go struct (e.g. signed orders and
+// contract events) or because they are part of the public API.
+var externalTypes = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}):                          "string",
+	reflect.TypeOf(common.Hash{}):                        "string",
+	reflect.TypeOf(common.Address{}):                     "string",
+	reflect.TypeOf(big.Int{}):                            "string",
+	reflect.TypeOf(zeroex.SignedOrder{}):                 "WrapperSignedOrder",
+	reflect.TypeOf(zeroex.ContractEvent{}):               "WrapperContractEvent",
+	reflect.TypeOf(zeroex.OrderEventEndState("")):        "OrderEventEndState",
+	reflect.TypeOf(ordervalidator.RejectedOrderKind("")): "RejectedOrderKind",
+	reflect.TypeOf(ordervalidator.RejectedOrderStatus{}): "RejectedOrderStatus",
+	reflect.TypeOf(types.LatestBlock{}):                  "LatestBlock",
+	reflect.TypeOf(types.TopicStats{}):                   "TopicStats",
+}
+
+// excludedFields contains fields which are not included in the JSValue
+// representation of their struct.
+var excludedFields = map[reflect.Type][]string{
+	reflect.TypeOf(ordervalidator.AcceptedOrderInfo{}): {"Trace"},
+	reflect.TypeOf(ordervalidator.RejectedOrderInfo{}): {"Trace"},
+}
+
+func main() {
+	output := flag.String("output", defaultOutput, "the file to write the definitions to")
+	check := flag.Bool("check", false, "check that the existing definitions are up to date instead of writing them")
+	flag.Parse()
+
+	generated, err := generate(rootTypes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		existing, err := ioutil.ReadFile(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !bytes.Equal(existing, generated) {
+			log.Fatalf("%s is out of date; run 'go run ./cmd/wasm-types-gen' to regenerate it", *output)
+		}
+		return
+	}
+	if err := ioutil.WriteFile(*output, generated, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	buf bytes.Buffer
+	// imports is the set of external named types which are referenced.
+	imports map[string]struct{}
+	// queue contains the struct types which still need to be generated.
+	queue []reflect.Type
+	// seen contains the struct types which have been generated or queued.
+	seen map[reflect.Type]struct{}
+}
+
+// generate returns the TypeScript definitions for the given struct types and
+// the struct types they reference.
+func generate(roots []reflect.Type) ([]byte, error) {
+	g := &generator{
+		imports: map[string]struct{}{},
+		seen:    map[reflect.Type]struct{}{},
+	}
+	for _, typ := range roots {
+		g.enqueue(typ)
+	}
+	for len(g.queue) > 0 {
+		typ := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.writeInterface(typ); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	if len(g.imports) > 0 {
+		imports := sortedKeys(g.imports)
+		fmt.Fprintf(&out, "\nimport {\n    %s,\n} from './types';\n", strings.Join(imports, ",\n    "))
+	}
+	out.Write(g.buf.Bytes())
+	return out.Bytes(), nil
+}
+
+func (g *generator) enqueue(typ reflect.Type) {
+	if _, found := g.seen[typ]; found {
+		return
+	}
+	g.seen[typ] = struct{}{}
+	g.queue = append(g.queue, typ)
+}
+
+func (g *generator) writeInterface(typ reflect.Type) error {
+	fmt.Fprintf(&g.buf, "\n/** @ignore */\nexport interface %s {\n", wrapperName(typ))
+	excluded := map[string]struct{}{}
+	for _, name := range excludedFields[typ] {
+		excluded[name] = struct{}{}
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+		if _, found := excluded[field.Name]; found {
+			continue
+		}
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		tsType, err := g.tsType(field.Type)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %s", typ.Name(), field.Name, err.Error())
+		}
+		optional := ""
+		if omitEmpty {
+			optional = "?"
+		}
+		fmt.Fprintf(&g.buf, "    %s%s: %s;\n", name, optional, tsType)
+	}
+	g.buf.WriteString("}\n")
+	return nil
+}
+
+// tsType returns the TypeScript type for the given Go type, queueing any
+// struct types which need to be generated.
+func (g *generator) tsType(typ reflect.Type) (string, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if name, found := externalTypes[typ]; found {
+		if name != "string" {
+			g.imports[name] = struct{}{}
+		}
+		return name, nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// Note that this includes time.Duration, which is passed in
+		// nanoseconds.
+		return "number", nil
+	case reflect.Slice, reflect.Array:
+		elemType, err := g.tsType(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		return elemType + "[]", nil
+	case reflect.Struct:
+		g.enqueue(typ)
+		return wrapperName(typ), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", typ.String())
+	}
+}
+
+// wrapperName returns the name of the generated TypeScript interface for the
+// given struct type. This follows the convention in types.ts of prefixing the
+// types exposed by the Wasm code with "Wrapper".
+func wrapperName(typ reflect.Type) string {
+	return "Wrapper" + typ.Name()
+}
+
+// jsonName returns the JSON field name of the given struct field and whether
+// it has the omitempty option.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	omitEmpty := false
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
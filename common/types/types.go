@@ -5,32 +5,85 @@ package types
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 )
 
 // Stats is the return value for core.GetStats. Also used in the browser and RPC
 // interface.
 type Stats struct {
-	Version                           string      `json:"version"`
-	PubSubTopic                       string      `json:"pubSubTopic"`
-	Rendezvous                        string      `json:"rendezvous"`
-	SecondaryRendezvous               []string    `json:"secondaryRendezvous"`
-	PeerID                            string      `json:"peerID"`
-	EthereumChainID                   int         `json:"ethereumChainID"`
-	LatestBlock                       LatestBlock `json:"latestBlock"`
-	NumPeers                          int         `json:"numPeers"`
-	NumOrders                         int         `json:"numOrders"`
-	NumOrdersIncludingRemoved         int         `json:"numOrdersIncludingRemoved"`
-	NumPinnedOrders                   int         `json:"numPinnedOrders"`
-	MaxExpirationTime                 string      `json:"maxExpirationTime"`
-	StartOfCurrentUTCDay              time.Time   `json:"startOfCurrentUTCDay"`
-	EthRPCRequestsSentInCurrentUTCDay int         `json:"ethRPCRequestsSentInCurrentUTCDay"`
-	EthRPCRateLimitExpiredRequests    int64       `json:"ethRPCRateLimitExpiredRequests"`
+	Version                                string       `json:"version"`
+	PubSubTopic                            string       `json:"pubSubTopic"`
+	Rendezvous                             string       `json:"rendezvous"`
+	SecondaryRendezvous                    []string     `json:"secondaryRendezvous"`
+	PeerID                                 string       `json:"peerID"`
+	EthereumChainID                        int          `json:"ethereumChainID"`
+	LatestBlock                            LatestBlock  `json:"latestBlock"`
+	NumPeers                               int          `json:"numPeers"`
+	NumOrders                              int          `json:"numOrders"`
+	NumOrdersIncludingRemoved              int          `json:"numOrdersIncludingRemoved"`
+	NumPinnedOrders                        int          `json:"numPinnedOrders"`
+	NumColdOrders                          int          `json:"numColdOrders"`
+	MaxExpirationTime                      string       `json:"maxExpirationTime"`
+	StartOfCurrentUTCDay                   time.Time    `json:"startOfCurrentUTCDay"`
+	EthRPCRequestsSentInCurrentUTCDay      int          `json:"ethRPCRequestsSentInCurrentUTCDay"`
+	EthRPCRateLimitExpiredRequests         int64        `json:"ethRPCRateLimitExpiredRequests"`
+	EthRPCMaxRequestsPer24HrUTC            int          `json:"ethRPCMaxRequestsPer24HrUTC"`
+	EthRPCRemainingRequestsInCurrentUTCDay int          `json:"ethRPCRemainingRequestsInCurrentUTCDay"`
+	EthRPCBudgetExhaustionETASeconds       int64        `json:"ethRPCBudgetExhaustionETASeconds"`
+	EthRPCCacheHits                        int64        `json:"ethRPCCacheHits"`
+	EthRPCCacheMisses                      int64        `json:"ethRPCCacheMisses"`
+	EthRPCCacheEntries                     int          `json:"ethRPCCacheEntries"`
+	StorageUsedBytes                       int64        `json:"storageUsedBytes"`
+	MaxOrders                              int          `json:"maxOrders"`
+	CurrentOrders                          int          `json:"currentOrders"`
+	EvictedOrdersLast24h                   int          `json:"evictedOrdersLast24h"`
+	StorageUtilizationPercent              float64      `json:"storageUtilizationPercent"`
+	InboundQueueLength                     int          `json:"inboundQueueLength"`
+	InboundQueueDroppedMessages            uint64       `json:"inboundQueueDroppedMessages"`
+	ValidationMemoryBytes                  int          `json:"validationMemoryBytes"`
+	ValidationMemoryShedOrders             uint64       `json:"validationMemoryShedOrders"`
+	DedupedOrderSubmissions                uint64       `json:"dedupedOrderSubmissions"`
+	MismatchedDomainOrders                 uint64       `json:"mismatchedDomainOrders"`
+	DroppedOrderEvents                     uint64       `json:"droppedOrderEvents"`
+	IngestionPaused                        bool         `json:"ingestionPaused"`
+	OrderSyncBytesSaved                    uint64       `json:"orderSyncBytesSaved"`
+	SlowDBQueries                          uint64       `json:"slowDBQueries"`
+	DBFragmentationPercent                 float64      `json:"dbFragmentationPercent"`
+	DBReclaimedBytes                       int64        `json:"dbReclaimedBytes"`
+	BootstrapDialFailures                  uint64       `json:"bootstrapDialFailures"`
+	UnhealthyBootstrapPeers                int          `json:"unhealthyBootstrapPeers"`
+	StartTime                              time.Time    `json:"startTime"`
+	UptimeSeconds                          int64        `json:"uptimeSeconds"`
+	GitCommit                              string       `json:"gitCommit"`
+	BuildDate                              string       `json:"buildDate"`
+	GoVersion                              string       `json:"goVersion"`
+	EnabledFeatures                        []string     `json:"enabledFeatures"`
+	ConfigHash                             string       `json:"configHash"`
+	Topics                                 []TopicStats `json:"topics"`
+}
+
+// TopicStats contains stats about one of the pubsub topics that the Mesh node
+// has joined. The first topic is always the one for the custom order filter,
+// followed by the topics for any additional order filters.
+type TopicStats struct {
+	Topic string `json:"topic"`
+	// NumOrders is the number of stored orders that have been received on the
+	// topic.
+	NumOrders int `json:"numOrders"`
+	// MaxOrders is the storage quota for the topic or zero if there is none.
+	MaxOrders int `json:"maxOrders"`
+	// QuotaDroppedMessages is the number of messages received on the topic
+	// which were dropped because the quota was reached.
+	QuotaDroppedMessages uint64 `json:"quotaDroppedMessages"`
 }
 
 // LatestBlock is the latest block processed by the Mesh node.
@@ -39,6 +92,17 @@ type LatestBlock struct {
 	Hash   common.Hash `json:"hash"`
 }
 
+// Block is a block that was processed by the Mesh node, along with the logs
+// in the block that were relevant to Mesh. It is the return value for
+// core.GetBlocks. Also used in the RPC interface.
+type Block struct {
+	Number     int            `json:"number"`
+	Hash       common.Hash    `json:"hash"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Logs       []ethtypes.Log `json:"logs"`
+}
+
 // GetOrdersResponse is the return value for core.GetOrders. Also used in the
 // browser and RPC interface.
 type GetOrdersResponse struct {
@@ -55,6 +119,227 @@ type AddOrdersOpts struct {
 	// and will always stay in storage until they are no longer fillable. Defaults
 	// to true.
 	Pinned bool `json:"pinned"`
+	// Trace determines whether or not a validation trace (schema and signature
+	// validation times and the number and duration of eth_calls) should be
+	// included with each result. Defaults to false.
+	Trace bool `json:"trace"`
+}
+
+// SubscribeToOrdersOpts is a set of options for order event subscriptions via
+// the RPC interface.
+type SubscribeToOrdersOpts struct {
+	// CoalesceIntervalMs is the interval (in milliseconds) at which buffered
+	// order events are sent to the subscriber. If it is greater than 0,
+	// successive fill updates for the same order within an interval (e.g.
+	// multiple fills in one block) are coalesced into a single event with the
+	// latest fillableTakerAssetAmount. Defaults to 0, which sends every event
+	// as soon as it is generated. Note that the sequence numbers of coalesced
+	// events are not consecutive.
+	CoalesceIntervalMs int `json:"coalesceIntervalMs"`
+	// MakerAddress, if not nil, only sends order events for orders with the
+	// given makerAddress. Defaults to nil, which doesn't filter order events by
+	// makerAddress.
+	MakerAddress *common.Address `json:"makerAddress"`
+	// AssetDataPairs, if not empty, only sends order events for orders which
+	// trade one of the given pairs of assets, in either direction. Defaults to
+	// nil, which doesn't filter order events by the assets they trade.
+	AssetDataPairs []AssetDataPair `json:"assetDataPairs"`
+	// EndStates, if not empty, only sends order events with one of the given
+	// end states (e.g. "ADDED" or "CANCELLED"). Defaults to nil, which sends
+	// order events with any end state.
+	EndStates []zeroex.OrderEventEndState `json:"endStates"`
+	// AckMode, if true, sends OrderEventsNotifications instead of plain lists
+	// of order events, and only sends the next notification once the
+	// subscriber has acknowledged the previous one via mesh_ackOrderEvents.
+	// Order events which are generated in the meantime are buffered on the
+	// disk of the Mesh node up to a limit. If the limit is reached, the
+	// buffered order events are dropped and the subscriber receives an
+	// overflow notification instead. It can't be combined with
+	// CoalesceIntervalMs. Defaults to false, which sends order events as soon
+	// as they are generated, regardless of whether the subscriber keeps up.
+	AckMode bool `json:"ackMode"`
+}
+
+const (
+	// OrderEventsNotificationType is the type of OrderEventsNotifications
+	// which contain order events.
+	OrderEventsNotificationType = "orderEvents"
+	// OverflowNotificationType is the type of OrderEventsNotifications which
+	// are sent instead of order events that were dropped because the
+	// subscriber didn't acknowledge notifications quickly enough.
+	OverflowNotificationType = "overflow"
+)
+
+// OrderEventsNotification is sent to subscribers of order events which set
+// SubscribeToOrdersOpts.AckMode. Each notification must be acknowledged by
+// calling mesh_ackOrderEvents with SubscriptionID and AckID before the next
+// one is sent.
+type OrderEventsNotification struct {
+	// Type is either OrderEventsNotificationType or OverflowNotificationType.
+	Type           string `json:"type"`
+	SubscriptionID string `json:"subscriptionID"`
+	AckID          uint64 `json:"ackID"`
+	// OrderEvents are the order events for notifications of type
+	// OrderEventsNotificationType.
+	OrderEvents []*zeroex.OrderEvent `json:"orderEvents"`
+	// DroppedOrderEvents is the number of order events that were dropped for
+	// notifications of type OverflowNotificationType. The subscriber should
+	// resync its state with mesh_getOrders.
+	DroppedOrderEvents int `json:"droppedOrderEvents"`
+}
+
+// AssetDataPair is a pair of assets in SubscribeToOrdersOpts. Orders which
+// sell either asset for the other match the pair.
+type AssetDataPair struct {
+	// BaseAssetData and QuoteAssetData are 0x-prefixed, hex-encoded assetData.
+	BaseAssetData  string `json:"baseAssetData"`
+	QuoteAssetData string `json:"quoteAssetData"`
+}
+
+// Validate returns an error if opts contains an invalid option.
+func (opts SubscribeToOrdersOpts) Validate() error {
+	if opts.CoalesceIntervalMs < 0 {
+		return errors.New("coalesceIntervalMs cannot be negative")
+	}
+	if opts.AckMode && opts.CoalesceIntervalMs > 0 {
+		return errors.New("ackMode cannot be combined with coalesceIntervalMs")
+	}
+	for _, pair := range opts.AssetDataPairs {
+		for _, assetData := range []string{pair.BaseAssetData, pair.QuoteAssetData} {
+			if _, err := hexutil.Decode(assetData); err != nil || assetData == "0x" {
+				return fmt.Errorf("invalid assetData in assetDataPairs: %q", assetData)
+			}
+		}
+	}
+	for _, endState := range opts.EndStates {
+		if !endState.IsValid() {
+			return fmt.Errorf("invalid end state in endStates: %q", endState)
+		}
+	}
+	return nil
+}
+
+// IsFiltered returns true if opts only matches some order events.
+func (opts SubscribeToOrdersOpts) IsFiltered() bool {
+	return opts.MakerAddress != nil || len(opts.AssetDataPairs) > 0 || len(opts.EndStates) > 0
+}
+
+// MatchOrderEvent returns true if the given order event should be sent to a
+// subscriber with the given options.
+func (opts SubscribeToOrdersOpts) MatchOrderEvent(orderEvent *zeroex.OrderEvent) bool {
+	if len(opts.EndStates) > 0 {
+		found := false
+		for _, endState := range opts.EndStates {
+			if orderEvent.EndState == endState {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if opts.MakerAddress == nil && len(opts.AssetDataPairs) == 0 {
+		return true
+	}
+	signedOrder := orderEvent.SignedOrder
+	if signedOrder == nil {
+		return false
+	}
+	if opts.MakerAddress != nil && signedOrder.MakerAddress != *opts.MakerAddress {
+		return false
+	}
+	if len(opts.AssetDataPairs) > 0 {
+		makerAssetData := hexutil.Encode(signedOrder.MakerAssetData)
+		takerAssetData := hexutil.Encode(signedOrder.TakerAssetData)
+		found := false
+		for _, pair := range opts.AssetDataPairs {
+			baseAssetData := strings.ToLower(pair.BaseAssetData)
+			quoteAssetData := strings.ToLower(pair.QuoteAssetData)
+			if (makerAssetData == baseAssetData && takerAssetData == quoteAssetData) ||
+				(makerAssetData == quoteAssetData && takerAssetData == baseAssetData) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterOrderEvents returns the order events which match opts (see
+// MatchOrderEvent).
+func (opts SubscribeToOrdersOpts) FilterOrderEvents(orderEvents []*zeroex.OrderEvent) []*zeroex.OrderEvent {
+	if !opts.IsFiltered() {
+		return orderEvents
+	}
+	filteredOrderEvents := make([]*zeroex.OrderEvent, 0, len(orderEvents))
+	for _, orderEvent := range orderEvents {
+		if opts.MatchOrderEvent(orderEvent) {
+			filteredOrderEvents = append(filteredOrderEvents, orderEvent)
+		}
+	}
+	return filteredOrderEvents
+}
+
+// GetOrdersOpts is a set of options for core.GetOrders. Also used in the RPC
+// interface.
+type GetOrdersOpts struct {
+	// MaxStalenessSeconds excludes orders which were last validated more than
+	// the given number of seconds ago. Excluded orders still count towards the
+	// page size, so a page may contain fewer than perPage orders even if it is
+	// not the last page. Defaults to 0, which doesn't exclude any orders.
+	MaxStalenessSeconds int `json:"maxStalenessSeconds"`
+	// TakerAddress, if not nil, restricts the results to orders with the given
+	// takerAddress. Use the null address to only get orders which can be filled
+	// by anyone. The same value must be used for every page of a snapshot.
+	// Defaults to nil, which doesn't filter orders by takerAddress.
+	TakerAddress *common.Address `json:"takerAddress"`
+	// Sort is an ordered list of fields to sort orders by. Orders are sorted by
+	// the first field, then by the second field, and so on. Orders which are
+	// equal in all of the fields are sorted by hash. The same value must be used
+	// for every page of a snapshot. Defaults to nil, which sorts orders by hash.
+	Sort []OrderSortField `json:"sort"`
+	// NFT, if not nil, restricts the results to orders which buy or sell ERC721
+	// or ERC1155 tokens matching the filter. The same value must be used for
+	// every page of a snapshot. Defaults to nil, which doesn't filter orders by
+	// the tokens they buy or sell.
+	NFT *NFTFilter `json:"nft"`
+}
+
+// NFTFilter matches orders which buy or sell ERC721 or ERC1155 tokens in
+// GetOrdersOpts.
+type NFTFilter struct {
+	// Side is either "ask" (orders which sell a matching token), "bid" (orders
+	// which buy a matching token) or "any". Defaults to "any".
+	Side string `json:"side"`
+	// ContractAddress, if not nil, only matches tokens of the given contract
+	// (i.e. collection).
+	ContractAddress *common.Address `json:"contractAddress"`
+	// TokenID, if not empty, only matches the token with the given ID. It is a
+	// decimal or 0x-prefixed hexadecimal string. ContractAddress must be set if
+	// TokenID is set.
+	TokenID string `json:"tokenId"`
+}
+
+// OrderSortField is a field to sort orders by in GetOrdersOpts.
+type OrderSortField struct {
+	// Field is either "price" (takerAssetAmount / makerAssetAmount) or
+	// "expirationTime".
+	Field string `json:"field"`
+	// Direction is either "asc" or "desc". Defaults to "asc".
+	Direction string `json:"direction"`
+}
+
+// GetMakersOpts is a set of options for core.GetMakers. Also used in the RPC
+// interface.
+type GetMakersOpts struct {
+	// MakerAddresses restricts the results to the given makers. Makers without
+	// any open orders are included in the results if they are requested
+	// explicitly. Defaults to all makers with at least one open order.
+	MakerAddresses []common.Address `json:"makerAddresses"`
 }
 
 // OrderInfo represents an fillable order and how much it could be filled for.
@@ -62,21 +347,66 @@ type OrderInfo struct {
 	OrderHash                common.Hash         `json:"orderHash"`
 	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
 	FillableTakerAssetAmount *big.Int            `json:"fillableTakerAssetAmount"`
+	// LastValidatedBlockNumber is the number of the block at which
+	// FillableTakerAssetAmount was last computed. It is nil if the order has not
+	// been validated since this field was introduced.
+	LastValidatedBlockNumber *big.Int `json:"lastValidatedBlockNumber"`
+	// LastValidatedBlockHash is the hash of the block at which
+	// FillableTakerAssetAmount was last computed.
+	LastValidatedBlockHash common.Hash `json:"lastValidatedBlockHash"`
+	// NextRevalidationTime is the latest time at which the order will be
+	// re-validated. Orders are also re-validated whenever a relevant contract
+	// event is detected, so they may be re-validated sooner.
+	NextRevalidationTime time.Time `json:"nextRevalidationTime"`
+	// FillabilityScore is an estimate between 0 and 1 of how likely the order
+	// is to be fillable, based on the past behavior of its maker and its age.
+	// It is nil unless fillability scores are enabled.
+	FillabilityScore *float64 `json:"fillabilityScore,omitempty"`
+	// Annotations are the key-value pairs that the operator-defined order
+	// policy attached to the order when it was added, if any.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Staleness is how long ago the order was last validated, i.e. how old
+	// FillableTakerAssetAmount is. It is encoded as a whole number of seconds.
+	Staleness time.Duration `json:"staleness"`
+	// Signer is the peer ID of the node which published and signed the
+	// GossipSub message in which the order was first received, if any.
+	Signer string `json:"signer,omitempty"`
 }
 
 type orderInfoJSON struct {
 	OrderHash                string              `json:"orderHash"`
 	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
 	FillableTakerAssetAmount string              `json:"fillableTakerAssetAmount"`
+	LastValidatedBlockNumber *string             `json:"lastValidatedBlockNumber"`
+	LastValidatedBlockHash   string              `json:"lastValidatedBlockHash"`
+	NextRevalidationTime     time.Time           `json:"nextRevalidationTime"`
+	FillabilityScore         *float64            `json:"fillabilityScore,omitempty"`
+	Annotations              map[string]string   `json:"annotations,omitempty"`
+	Staleness                int64               `json:"staleness"`
 }
 
 // MarshalJSON is a custom Marshaler for OrderInfo
 func (o OrderInfo) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	var lastValidatedBlockNumber interface{}
+	if o.LastValidatedBlockNumber != nil {
+		lastValidatedBlockNumber = o.LastValidatedBlockNumber.String()
+	}
+	orderInfo := map[string]interface{}{
 		"orderHash":                o.OrderHash.Hex(),
 		"signedOrder":              o.SignedOrder,
 		"fillableTakerAssetAmount": o.FillableTakerAssetAmount.String(),
-	})
+		"lastValidatedBlockNumber": lastValidatedBlockNumber,
+		"lastValidatedBlockHash":   o.LastValidatedBlockHash.Hex(),
+		"nextRevalidationTime":     o.NextRevalidationTime,
+		"staleness":                int64(o.Staleness / time.Second),
+	}
+	if o.FillabilityScore != nil {
+		orderInfo["fillabilityScore"] = *o.FillabilityScore
+	}
+	if len(o.Annotations) > 0 {
+		orderInfo["annotations"] = o.Annotations
+	}
+	return json.Marshal(orderInfo)
 }
 
 // UnmarshalJSON implements a custom JSON unmarshaller for the OrderEvent type
@@ -94,5 +424,239 @@ func (o *OrderInfo) UnmarshalJSON(data []byte) error {
 	if !ok {
 		return errors.New("Invalid uint256 number encountered for FillableTakerAssetAmount")
 	}
+	if orderInfoJSON.LastValidatedBlockNumber != nil {
+		o.LastValidatedBlockNumber, ok = math.ParseBig256(*orderInfoJSON.LastValidatedBlockNumber)
+		if !ok {
+			return errors.New("Invalid uint256 number encountered for LastValidatedBlockNumber")
+		}
+	}
+	o.LastValidatedBlockHash = common.HexToHash(orderInfoJSON.LastValidatedBlockHash)
+	o.NextRevalidationTime = orderInfoJSON.NextRevalidationTime
+	o.FillabilityScore = orderInfoJSON.FillabilityScore
+	o.Annotations = orderInfoJSON.Annotations
+	o.Staleness = time.Duration(orderInfoJSON.Staleness) * time.Second
+	return nil
+}
+
+// MakerInfo contains aggregate information about the open orders of a single
+// maker. It is the return value for core.GetMakers. Also used in the RPC
+// interface.
+type MakerInfo struct {
+	MakerAddress common.Address `json:"makerAddress"`
+	// NumOrders is the number of open orders.
+	NumOrders int `json:"numOrders"`
+	// MakerAssetAmounts contains the sum of the makerAssetAmount of the open
+	// orders for each maker asset, sorted by asset data.
+	MakerAssetAmounts []*MakerAssetAmount `json:"makerAssetAmounts"`
+	// LastActivity is the most recent time at which any of the open orders was
+	// updated.
+	LastActivity time.Time `json:"lastActivity"`
+}
+
+// MakerAssetAmount is the total amount of a single asset offered by a maker.
+type MakerAssetAmount struct {
+	AssetData []byte   `json:"assetData"`
+	Amount    *big.Int `json:"amount"`
+}
+
+type makerAssetAmountJSON struct {
+	AssetData string `json:"assetData"`
+	Amount    string `json:"amount"`
+}
+
+// MarshalJSON is a custom Marshaler for MakerAssetAmount
+func (m MakerAssetAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(makerAssetAmountJSON{
+		AssetData: common.ToHex(m.AssetData),
+		Amount:    m.Amount.String(),
+	})
+}
+
+// UnmarshalJSON implements a custom JSON unmarshaller for the MakerAssetAmount
+// type
+func (m *MakerAssetAmount) UnmarshalJSON(data []byte) error {
+	var makerAssetAmountJSON makerAssetAmountJSON
+	if err := json.Unmarshal(data, &makerAssetAmountJSON); err != nil {
+		return err
+	}
+	m.AssetData = common.FromHex(makerAssetAmountJSON.AssetData)
+	var ok bool
+	m.Amount, ok = math.ParseBig256(makerAssetAmountJSON.Amount)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for Amount")
+	}
+	return nil
+}
+
+// MakerEpoch is the order epoch of a maker for a single sender address, which
+// is set by calling cancelOrdersUpTo on the Exchange contract. All orders of
+// the maker with that sender address and a salt lower than OrderEpoch are
+// cancelled. It is the return value for core.GetMakerEpoch. Also used in the
+// RPC interface.
+type MakerEpoch struct {
+	MakerAddress  common.Address
+	SenderAddress common.Address
+	OrderEpoch    *big.Int
+}
+
+type makerEpochJSON struct {
+	MakerAddress  common.Address `json:"makerAddress"`
+	SenderAddress common.Address `json:"senderAddress"`
+	OrderEpoch    string         `json:"orderEpoch"`
+}
+
+// MarshalJSON is a custom Marshaler for MakerEpoch
+func (m MakerEpoch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(makerEpochJSON{
+		MakerAddress:  m.MakerAddress,
+		SenderAddress: m.SenderAddress,
+		OrderEpoch:    m.OrderEpoch.String(),
+	})
+}
+
+// UnmarshalJSON implements a custom JSON unmarshaller for the MakerEpoch type
+func (m *MakerEpoch) UnmarshalJSON(data []byte) error {
+	var makerEpochJSON makerEpochJSON
+	if err := json.Unmarshal(data, &makerEpochJSON); err != nil {
+		return err
+	}
+	m.MakerAddress = makerEpochJSON.MakerAddress
+	m.SenderAddress = makerEpochJSON.SenderAddress
+	var ok bool
+	m.OrderEpoch, ok = math.ParseBig256(makerEpochJSON.OrderEpoch)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for OrderEpoch")
+	}
 	return nil
 }
+
+// MarketInfo contains aggregate information about the open orders for a
+// single trading pair. It is the return value for core.GetMarkets. Also used
+// in the RPC interface. Orders which sell the base asset are asks and orders
+// which sell the quote asset are bids. Prices are the amount of the quote
+// asset per unit of the base asset, both in base units, and sizes are amounts
+// of the base asset in base units.
+type MarketInfo struct {
+	BaseAssetData  []byte
+	QuoteAssetData []byte
+	// NumOrders is the number of open orders (i.e. NumBids + NumAsks).
+	NumOrders int
+	NumBids   int
+	NumAsks   int
+	// BestBid is the highest price of any bid. It is nil if there are no bids.
+	BestBid *big.Rat
+	// BestAsk is the lowest price of any ask. It is nil if there are no asks.
+	BestAsk *big.Rat
+	// MidPrice is the average of BestBid and BestAsk. It is nil unless there
+	// are both bids and asks.
+	MidPrice *big.Rat
+	// Spread is BestAsk minus BestBid. It is nil unless there are both bids
+	// and asks. It is negative if the best bid and ask cross.
+	Spread *big.Rat
+	// BidSize is the remaining fillable amount of the base asset of all bids.
+	BidSize *big.Int
+	// AskSize is the remaining fillable amount of the base asset of all asks.
+	AskSize *big.Int
+}
+
+// priceDigits is the number of significant digits used when encoding prices
+// as JSON.
+const priceDigits = 20
+
+type marketInfoJSON struct {
+	BaseAssetData  string  `json:"baseAssetData"`
+	QuoteAssetData string  `json:"quoteAssetData"`
+	NumOrders      int     `json:"numOrders"`
+	NumBids        int     `json:"numBids"`
+	NumAsks        int     `json:"numAsks"`
+	BestBid        *string `json:"bestBid"`
+	BestAsk        *string `json:"bestAsk"`
+	MidPrice       *string `json:"midPrice"`
+	Spread         *string `json:"spread"`
+	BidSize        string  `json:"bidSize"`
+	AskSize        string  `json:"askSize"`
+}
+
+// MarshalJSON is a custom Marshaler for MarketInfo. Prices are encoded as
+// decimal strings (which may use scientific notation) or null.
+func (m MarketInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marketInfoJSON{
+		BaseAssetData:  common.ToHex(m.BaseAssetData),
+		QuoteAssetData: common.ToHex(m.QuoteAssetData),
+		NumOrders:      m.NumOrders,
+		NumBids:        m.NumBids,
+		NumAsks:        m.NumAsks,
+		BestBid:        formatPrice(m.BestBid),
+		BestAsk:        formatPrice(m.BestAsk),
+		MidPrice:       formatPrice(m.MidPrice),
+		Spread:         formatPrice(m.Spread),
+		BidSize:        m.BidSize.String(),
+		AskSize:        m.AskSize.String(),
+	})
+}
+
+// UnmarshalJSON implements a custom JSON unmarshaller for the MarketInfo type
+func (m *MarketInfo) UnmarshalJSON(data []byte) error {
+	var marketInfoJSON marketInfoJSON
+	if err := json.Unmarshal(data, &marketInfoJSON); err != nil {
+		return err
+	}
+	m.BaseAssetData = common.FromHex(marketInfoJSON.BaseAssetData)
+	m.QuoteAssetData = common.FromHex(marketInfoJSON.QuoteAssetData)
+	m.NumOrders = marketInfoJSON.NumOrders
+	m.NumBids = marketInfoJSON.NumBids
+	m.NumAsks = marketInfoJSON.NumAsks
+	var err error
+	if m.BestBid, err = parsePrice(marketInfoJSON.BestBid); err != nil {
+		return err
+	}
+	if m.BestAsk, err = parsePrice(marketInfoJSON.BestAsk); err != nil {
+		return err
+	}
+	if m.MidPrice, err = parsePrice(marketInfoJSON.MidPrice); err != nil {
+		return err
+	}
+	if m.Spread, err = parsePrice(marketInfoJSON.Spread); err != nil {
+		return err
+	}
+	var ok bool
+	m.BidSize, ok = math.ParseBig256(marketInfoJSON.BidSize)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for BidSize")
+	}
+	m.AskSize, ok = math.ParseBig256(marketInfoJSON.AskSize)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for AskSize")
+	}
+	return nil
+}
+
+func formatPrice(price *big.Rat) *string {
+	if price == nil {
+		return nil
+	}
+	formatted := new(big.Float).SetPrec(128).SetRat(price).Text('g', priceDigits)
+	return &formatted
+}
+
+func parsePrice(price *string) (*big.Rat, error) {
+	if price == nil {
+		return nil, nil
+	}
+	parsed, ok := new(big.Rat).SetString(*price)
+	if !ok {
+		return nil, fmt.Errorf("invalid price: %q", *price)
+	}
+	return parsed, nil
+}
+
+// ResyncResponse is the return value for core.Resync. Also used in the RPC
+// interface.
+type ResyncResponse struct {
+	// LatestBlock is the block that Mesh started again from.
+	LatestBlock LatestBlock `json:"latestBlock"`
+	// SyncedPeers are the peers from which orders were received via ordersync.
+	SyncedPeers []string `json:"syncedPeers"`
+	// FailedPeers are the peers for which ordersync failed.
+	FailedPeers []string `json:"failedPeers"`
+}
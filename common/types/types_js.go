@@ -5,6 +5,7 @@ package types
 import (
 	"encoding/json"
 	"syscall/js"
+	"time"
 )
 
 func (r GetOrdersResponse) JSValue() js.Value {
@@ -30,21 +31,72 @@ func (s Stats) JSValue() js.Value {
 	for i, rendezvousPoint := range s.SecondaryRendezvous {
 		secondaryRendezvous[i] = rendezvousPoint
 	}
+	enabledFeatures := make([]interface{}, len(s.EnabledFeatures))
+	for i, feature := range s.EnabledFeatures {
+		enabledFeatures[i] = feature
+	}
+	topics := make([]interface{}, len(s.Topics))
+	for i, topicStats := range s.Topics {
+		topics[i] = topicStats.JSValue()
+	}
+	return js.ValueOf(map[string]interface{}{
+		"version":                                s.Version,
+		"pubSubTopic":                            s.PubSubTopic,
+		"rendezvous":                             s.Rendezvous,
+		"secondaryRendezvous":                    secondaryRendezvous,
+		"peerID":                                 s.PeerID,
+		"ethereumChainID":                        s.EthereumChainID,
+		"latestBlock":                            s.LatestBlock.JSValue(),
+		"numPeers":                               s.NumPeers,
+		"numOrders":                              s.NumOrders,
+		"numOrdersIncludingRemoved":              s.NumOrdersIncludingRemoved,
+		"numPinnedOrders":                        s.NumPinnedOrders,
+		"numColdOrders":                          s.NumColdOrders,
+		"maxExpirationTime":                      s.MaxExpirationTime,
+		"startOfCurrentUTCDay":                   s.StartOfCurrentUTCDay.String(),
+		"ethRPCRequestsSentInCurrentUTCDay":      s.EthRPCRequestsSentInCurrentUTCDay,
+		"ethRPCRateLimitExpiredRequests":         s.EthRPCRateLimitExpiredRequests,
+		"ethRPCMaxRequestsPer24HrUTC":            s.EthRPCMaxRequestsPer24HrUTC,
+		"ethRPCRemainingRequestsInCurrentUTCDay": s.EthRPCRemainingRequestsInCurrentUTCDay,
+		"ethRPCBudgetExhaustionETASeconds":       s.EthRPCBudgetExhaustionETASeconds,
+		"ethRPCCacheHits":                        s.EthRPCCacheHits,
+		"ethRPCCacheMisses":                      s.EthRPCCacheMisses,
+		"ethRPCCacheEntries":                     s.EthRPCCacheEntries,
+		"storageUsedBytes":                       s.StorageUsedBytes,
+		"maxOrders":                              s.MaxOrders,
+		"currentOrders":                          s.CurrentOrders,
+		"evictedOrdersLast24h":                   s.EvictedOrdersLast24h,
+		"storageUtilizationPercent":              s.StorageUtilizationPercent,
+		"inboundQueueLength":                     s.InboundQueueLength,
+		"inboundQueueDroppedMessages":            s.InboundQueueDroppedMessages,
+		"validationMemoryBytes":                  s.ValidationMemoryBytes,
+		"validationMemoryShedOrders":             s.ValidationMemoryShedOrders,
+		"dedupedOrderSubmissions":                s.DedupedOrderSubmissions,
+		"mismatchedDomainOrders":                 s.MismatchedDomainOrders,
+		"droppedOrderEvents":                     s.DroppedOrderEvents,
+		"ingestionPaused":                        s.IngestionPaused,
+		"orderSyncBytesSaved":                    s.OrderSyncBytesSaved,
+		"slowDBQueries":                          s.SlowDBQueries,
+		"dbFragmentationPercent":                 s.DBFragmentationPercent,
+		"dbReclaimedBytes":                       s.DBReclaimedBytes,
+		"bootstrapDialFailures":                  s.BootstrapDialFailures,
+		"unhealthyBootstrapPeers":                s.UnhealthyBootstrapPeers,
+		"startTime":                              s.StartTime.Format(time.RFC3339),
+		"uptimeSeconds":                          s.UptimeSeconds,
+		"gitCommit":                              s.GitCommit,
+		"buildDate":                              s.BuildDate,
+		"goVersion":                              s.GoVersion,
+		"enabledFeatures":                        enabledFeatures,
+		"configHash":                             s.ConfigHash,
+		"topics":                                 topics,
+	})
+}
+
+func (t TopicStats) JSValue() js.Value {
 	return js.ValueOf(map[string]interface{}{
-		"version":                           s.Version,
-		"pubSubTopic":                       s.PubSubTopic,
-		"rendezvous":                        s.Rendezvous,
-		"secondaryRendezvous":               secondaryRendezvous,
-		"peerID":                            s.PeerID,
-		"ethereumChainID":                   s.EthereumChainID,
-		"latestBlock":                       s.LatestBlock.JSValue(),
-		"numPeers":                          s.NumPeers,
-		"numOrders":                         s.NumOrders,
-		"numOrdersIncludingRemoved":         s.NumOrdersIncludingRemoved,
-		"numPinnedOrders":                   s.NumPinnedOrders,
-		"maxExpirationTime":                 s.MaxExpirationTime,
-		"startOfCurrentUTCDay":              s.StartOfCurrentUTCDay.String(),
-		"ethRPCRequestsSentInCurrentUTCDay": s.EthRPCRequestsSentInCurrentUTCDay,
-		"ethRPCRateLimitExpiredRequests":    s.EthRPCRateLimitExpiredRequests,
+		"topic":                t.Topic,
+		"numOrders":            t.NumOrders,
+		"maxOrders":            t.MaxOrders,
+		"quotaDroppedMessages": t.QuotaDroppedMessages,
 	})
 }
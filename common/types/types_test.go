@@ -0,0 +1,109 @@
+// +build !js
+
+package types
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeToOrdersOptsValidate(t *testing.T) {
+	validAssetData := "0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	testCases := []struct {
+		opts    SubscribeToOrdersOpts
+		isValid bool
+	}{
+		{SubscribeToOrdersOpts{}, true},
+		{SubscribeToOrdersOpts{CoalesceIntervalMs: -1}, false},
+		{SubscribeToOrdersOpts{AssetDataPairs: []AssetDataPair{{BaseAssetData: validAssetData, QuoteAssetData: validAssetData}}}, true},
+		{SubscribeToOrdersOpts{AssetDataPairs: []AssetDataPair{{BaseAssetData: validAssetData, QuoteAssetData: "0x"}}}, false},
+		{SubscribeToOrdersOpts{AssetDataPairs: []AssetDataPair{{BaseAssetData: "not hex", QuoteAssetData: validAssetData}}}, false},
+		{SubscribeToOrdersOpts{EndStates: []zeroex.OrderEventEndState{zeroex.ESOrderAdded, zeroex.ESOrderCancelled}}, true},
+		{SubscribeToOrdersOpts{EndStates: []zeroex.OrderEventEndState{"NOT_AN_END_STATE"}}, false},
+		{SubscribeToOrdersOpts{EndStates: []zeroex.OrderEventEndState{zeroex.ESInvalid}}, false},
+		{SubscribeToOrdersOpts{AckMode: true}, true},
+		{SubscribeToOrdersOpts{AckMode: true, CoalesceIntervalMs: 100}, false},
+	}
+	for i, testCase := range testCases {
+		err := testCase.opts.Validate()
+		if testCase.isValid {
+			assert.NoError(t, err, "test case %d", i)
+		} else {
+			assert.Error(t, err, "test case %d", i)
+		}
+	}
+}
+
+func TestSubscribeToOrdersOptsFilterOrderEvents(t *testing.T) {
+	maker := common.HexToAddress("0x6440b8c5f5a3c725eb394c7c40994afaf50a0d39")
+	otherMaker := common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124")
+	weth := common.FromHex("0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	dai := common.FromHex("0xf47261b00000000000000000000000006b175474e89094c44da98b954eedeac495271d0f")
+	zrx := common.FromHex("0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498")
+	newOrderEvent := func(makerAddress common.Address, makerAssetData, takerAssetData []byte, endState zeroex.OrderEventEndState) *zeroex.OrderEvent {
+		return &zeroex.OrderEvent{
+			SignedOrder: &zeroex.SignedOrder{
+				Order: zeroex.Order{
+					MakerAddress:   makerAddress,
+					MakerAssetData: makerAssetData,
+					TakerAssetData: takerAssetData,
+				},
+			},
+			EndState: endState,
+		}
+	}
+	ask := newOrderEvent(maker, weth, dai, zeroex.ESOrderAdded)
+	bid := newOrderEvent(maker, dai, weth, zeroex.ESOrderFilled)
+	otherMarket := newOrderEvent(maker, zrx, dai, zeroex.ESOrderAdded)
+	otherMakersOrder := newOrderEvent(otherMaker, weth, dai, zeroex.ESOrderCancelled)
+	orderEvents := []*zeroex.OrderEvent{ask, bid, otherMarket, otherMakersOrder}
+
+	testCases := []struct {
+		description string
+		opts        SubscribeToOrdersOpts
+		expected    []*zeroex.OrderEvent
+	}{
+		{
+			description: "no filters",
+			opts:        SubscribeToOrdersOpts{},
+			expected:    orderEvents,
+		},
+		{
+			description: "makerAddress",
+			opts:        SubscribeToOrdersOpts{MakerAddress: &otherMaker},
+			expected:    []*zeroex.OrderEvent{otherMakersOrder},
+		},
+		{
+			description: "assetDataPairs",
+			opts: SubscribeToOrdersOpts{AssetDataPairs: []AssetDataPair{{
+				BaseAssetData:  common.ToHex(weth),
+				QuoteAssetData: common.ToHex(dai),
+			}}},
+			expected: []*zeroex.OrderEvent{ask, bid, otherMakersOrder},
+		},
+		{
+			description: "endStates",
+			opts:        SubscribeToOrdersOpts{EndStates: []zeroex.OrderEventEndState{zeroex.ESOrderAdded}},
+			expected:    []*zeroex.OrderEvent{ask, otherMarket},
+		},
+		{
+			description: "all filters",
+			opts: SubscribeToOrdersOpts{
+				MakerAddress: &maker,
+				AssetDataPairs: []AssetDataPair{{
+					BaseAssetData:  common.ToHex(weth),
+					QuoteAssetData: common.ToHex(dai),
+				}},
+				EndStates: []zeroex.OrderEventEndState{zeroex.ESOrderFilled, zeroex.ESOrderCancelled},
+			},
+			expected: []*zeroex.OrderEvent{bid},
+		},
+	}
+	for _, testCase := range testCases {
+		actual := testCase.opts.FilterOrderEvents(orderEvents)
+		assert.Equal(t, testCase.expected, actual, testCase.description)
+	}
+}
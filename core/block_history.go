@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxBlocksPerRequest is the maximum number of blocks that can be requested
+// via GetBlocks at once.
+const maxBlocksPerRequest = 1000
+
+var (
+	// ErrBlockHistoryDisabled is returned by GetBlocks if BlockHistoryRetention
+	// is 0.
+	ErrBlockHistoryDisabled = errors.New("block history is disabled (BLOCK_HISTORY_RETENTION is 0)")
+	// ErrInvalidBlockRange is returned by GetBlocks if the requested range of
+	// blocks is empty or too large.
+	ErrInvalidBlockRange = fmt.Errorf("invalid block range (toBlock must not be less than fromBlock and at most %d blocks can be requested at once)", maxBlocksPerRequest)
+)
+
+// startBlockHistory starts storing the blocks processed by the block watcher
+// until ctx is canceled. Blocks older than app.config.BlockHistoryRetention
+// blocks are pruned.
+func (app *App) startBlockHistory(ctx context.Context, wg *sync.WaitGroup) {
+	blockEvents := make(chan []*blockwatch.Event, 100)
+	blockSubscription := app.blockWatcher.Subscribe(blockEvents)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing block history")
+		}()
+		defer blockSubscription.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-blockSubscription.Err():
+				log.WithError(err).Error("block subscription error encountered while storing block history")
+				return
+			case events := <-blockEvents:
+				if err := app.storeBlockHistory(events); err != nil {
+					log.WithError(err).Error("could not store block history")
+				}
+			}
+		}
+	}()
+}
+
+func (app *App) storeBlockHistory(events []*blockwatch.Event) error {
+	var latest *big.Int
+	for _, event := range events {
+		switch event.Type {
+		case blockwatch.Added:
+			if err := app.db.AddHistoricalBlocks([]*miniheader.MiniHeader{event.BlockHeader}); err != nil {
+				return err
+			}
+			if latest == nil || event.BlockHeader.Number.Cmp(latest) > 0 {
+				latest = event.BlockHeader.Number
+			}
+		case blockwatch.Removed:
+			if err := app.db.RemoveHistoricalBlock(event.BlockHeader.Number, event.BlockHeader.Hash); err != nil {
+				return err
+			}
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	minBlockNumber := big.NewInt(0).Sub(latest, big.NewInt(int64(app.config.BlockHistoryRetention)-1))
+	numPruned, err := app.db.PruneBlockHistory(minBlockNumber)
+	if err != nil {
+		return err
+	}
+	if numPruned > 0 {
+		log.WithField("numPruned", numPruned).Trace("pruned block history")
+	}
+	return nil
+}
+
+// GetBlocks returns the stored blocks with a block number in the range
+// [fromBlock, toBlock] in ascending order. Blocks which are older than
+// BlockHistoryRetention blocks are not included. Neither are blocks which were
+// mined while Mesh was offline and did not contain any logs relevant to Mesh,
+// since those are skipped when Mesh catches up.
+func (app *App) GetBlocks(fromBlock, toBlock int) ([]*types.Block, error) {
+	<-app.started
+
+	if app.config.BlockHistoryRetention == 0 {
+		return nil, ErrBlockHistoryDisabled
+	}
+	if fromBlock < 0 || toBlock < fromBlock || toBlock-fromBlock >= maxBlocksPerRequest {
+		return nil, ErrInvalidBlockRange
+	}
+	historicalBlocks, err := app.db.FindHistoricalBlocks(big.NewInt(int64(fromBlock)), big.NewInt(int64(toBlock)), maxBlocksPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*types.Block, len(historicalBlocks))
+	for i, historicalBlock := range historicalBlocks {
+		blocks[i] = &types.Block{
+			Number:     int(historicalBlock.Number.Int64()),
+			Hash:       historicalBlock.Hash,
+			ParentHash: historicalBlock.Parent,
+			Timestamp:  historicalBlock.Timestamp,
+			Logs:       historicalBlock.Logs,
+		}
+	}
+	return blocks, nil
+}
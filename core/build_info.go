@@ -0,0 +1,58 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// gitCommit and buildDate describe the build of Mesh. They are set at build
+// time with:
+//
+//	go build -ldflags "-X github.com/0xProject/0x-mesh/core.gitCommit=$(git rev-parse HEAD) -X github.com/0xProject/0x-mesh/core.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	gitCommit = "unknown"
+	buildDate = "unknown"
+)
+
+// enabledFeatures returns the environment variables of the boolean options in
+// the given config which are enabled, sorted alphabetically.
+func enabledFeatures(config Config) []string {
+	features := []string{}
+	configVal := reflect.ValueOf(config)
+	configType := configVal.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		name := configType.Field(i).Tag.Get("envvar")
+		if name == "" || name == "-" || configType.Field(i).Type.Kind() != reflect.Bool {
+			continue
+		}
+		if configVal.Field(i).Bool() {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// configHash returns a hex-encoded SHA-256 hash of the options in the given
+// config which can be set via environment variable. Secrets (i.e. fields which
+// are excluded from JSON) are not included, so the hash can be shared freely
+// and compared across nodes to verify that they are configured identically.
+func configHash(config Config) string {
+	var encoded strings.Builder
+	configVal := reflect.ValueOf(config)
+	configType := configVal.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		name := field.Tag.Get("envvar")
+		if name == "" || name == "-" || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fmt.Fprintf(&encoded, "%s=%v\n", name, configVal.Field(i).Interface())
+	}
+	hash := sha256.Sum256([]byte(encoded.String()))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,35 @@
+// +build !js
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabledFeatures(t *testing.T) {
+	config := Config{
+		UseBootstrapList:            true,
+		EthereumRPCNarrowLogFilters: true,
+		RequireMessageSignatures:    false,
+	}
+	assert.Equal(t, []string{"ETHEREUM_RPC_NARROW_LOG_FILTERS", "USE_BOOTSTRAP_LIST"}, enabledFeatures(config))
+	assert.Equal(t, []string{}, enabledFeatures(Config{}))
+}
+
+func TestConfigHash(t *testing.T) {
+	config := Config{
+		EthereumChainID: 1337,
+		EthereumRPCURL:  "https://mainnet.infura.io/v3/some-api-key",
+	}
+	hash := configHash(config)
+	assert.Len(t, hash, 64)
+
+	// Secrets don't affect the hash.
+	config.EthereumRPCURL = "http://localhost:8545"
+	assert.Equal(t, hash, configHash(config))
+
+	config.EthereumChainID = 1
+	assert.NotEqual(t, hash, configHash(config))
+}
@@ -2,20 +2,33 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/core/filterattestation"
+	"github.com/0xProject/0x-mesh/core/orderchecksum"
+	"github.com/0xProject/0x-mesh/core/ordersubmission"
 	"github.com/0xProject/0x-mesh/core/ordersync"
+	"github.com/0xProject/0x-mesh/core/softcancel"
+	"github.com/0xProject/0x-mesh/core/statsattestation"
 	"github.com/0xProject/0x-mesh/db"
 	"github.com/0xProject/0x-mesh/encoding"
 	"github.com/0xProject/0x-mesh/ethereum"
@@ -23,18 +36,24 @@ import (
 	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
 	"github.com/0xProject/0x-mesh/ethereum/ratelimit"
 	"github.com/0xProject/0x-mesh/ethereum/simplestack"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
 	"github.com/0xProject/0x-mesh/expirationwatch"
+	"github.com/0xProject/0x-mesh/fillscore"
 	"github.com/0xProject/0x-mesh/keys"
 	"github.com/0xProject/0x-mesh/loghooks"
 	"github.com/0xProject/0x-mesh/meshdb"
 	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/orderpolicy"
 	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/replay"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch"
 	"github.com/albrow/stringset"
 	"github.com/benbjohnson/clock"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -68,6 +87,12 @@ const (
 	// run of the ordersync protocol (as a requester). We always request orders
 	// immediately on startup. This delay only applies to subsequent runs.
 	ordersyncApproxDelay = 1 * time.Hour
+	// maxRevalidateOrderHashes is the maximum number of order hashes that can
+	// be passed to RevalidateOrders at once.
+	maxRevalidateOrderHashes = 1000
+	// defaultOrderEventsBufferSize is the default value for
+	// Config.OrderEventsBufferSize.
+	defaultOrderEventsBufferSize = 1000
 )
 
 // privateConfig contains some configuration options that can only be changed from
@@ -102,6 +127,13 @@ type Config struct {
 	// EthereumRPCURL is the URL of an Etheruem node which supports the JSON RPC
 	// API.
 	EthereumRPCURL string `envvar:"ETHEREUM_RPC_URL" json:"-"`
+	// EthereumValidationRPCURL is the URL of an Ethereum node which is used for
+	// the eth_calls made to validate orders (i.e. calls to the DevUtils
+	// contract), which are much heavier than the requests made to watch for new
+	// blocks. This allows the block watcher to use a cheap (e.g. WebSocket)
+	// endpoint while validation uses an archive or batching provider. If empty,
+	// EthereumRPCURL is used for everything.
+	EthereumValidationRPCURL string `envvar:"ETHEREUM_VALIDATION_RPC_URL" json:"-" default:""`
 	// EthereumChainID is the chain ID specifying which Ethereum chain you wish to
 	// run your Mesh node for
 	EthereumChainID int `envvar:"ETHEREUM_CHAIN_ID"`
@@ -113,11 +145,95 @@ type Config struct {
 	// "/ip4/3.214.190.67/tcp/60558/ipfs/16Uiu2HAmGx8Z6gdq5T5AQE54GMtqDhDFhizywTy1o28NJbAMMumF").
 	// If empty, the default bootstrap list will be used.
 	BootstrapList string `envvar:"BOOTSTRAP_LIST" default:""`
+	// UseNetworkManifest is whether to fetch a signed network manifest from the
+	// bootstrap peers on startup. The manifest lists recommended peers to
+	// connect to, the pubsub topics in use and the minimum recommended version
+	// of Mesh. Only used if UseBootstrapList is true.
+	UseNetworkManifest bool `envvar:"USE_NETWORK_MANIFEST" default:"false"`
+	// MinPeerProtocolVersion is the minimum p2p protocol version (e.g. "1.2.0")
+	// accepted from peers. Peers which report an older protocol version when
+	// connecting are disconnected with an explanation. Peers with a different
+	// major protocol version are always disconnected. It cannot be newer than
+	// the protocol version of this node. If empty, any protocol version with
+	// the same major version is accepted.
+	MinPeerProtocolVersion string `envvar:"MIN_PEER_PROTOCOL_VERSION" default:""`
+	// P2PDenyPrivateIPs determines whether or not to deny p2p connections to
+	// and from private, shared, loopback and link-local IP addresses (e.g.
+	// 10.0.0.0/8 or 127.0.0.1). This can be used to comply with network
+	// policies which forbid connecting to internal hosts.
+	P2PDenyPrivateIPs bool `envvar:"P2P_DENY_PRIVATE_IPS" default:"false"`
+	// P2PDeniedSubnets is a comma-separated list of subnets in CIDR notation
+	// (e.g. "192.0.2.0/24,2001:db8::/32") to and from which p2p connections are
+	// denied. It takes precedence over P2PAllowedSubnets.
+	P2PDeniedSubnets string `envvar:"P2P_DENIED_SUBNETS" default:""`
+	// P2PAllowedSubnets is a comma-separated list of subnets in CIDR notation.
+	// If it is set, p2p connections to and from any IP address outside of these
+	// subnets are denied. Note that the bootstrap peers must be reachable
+	// within the allowed subnets in order for peer discovery to work.
+	P2PAllowedSubnets string `envvar:"P2P_ALLOWED_SUBNETS" default:""`
+	// P2PReservedPeerSlotsFraction is the fraction of the maximum number of p2p
+	// connections which is reserved for the peers that contribute the most
+	// valid orders. Connections to these peers are never pruned when Mesh is at
+	// its connection limit. Must be between 0 and 1. Set to 0 to disable.
+	P2PReservedPeerSlotsFraction float64 `envvar:"P2P_RESERVED_PEER_SLOTS_FRACTION" default:"0.2"`
+	// P2PIdlePeerTimeout is the amount of time a peer may stay connected without
+	// contributing a valid order before it is considered idle. When Mesh is at
+	// its target number of connections, idle peers are gradually disconnected
+	// to make room for new peers. If 0, idle peers are not disconnected.
+	P2PIdlePeerTimeout time.Duration `envvar:"P2P_IDLE_PEER_TIMEOUT" default:"0s"`
+	// RequireMessageSignatures determines whether or not to drop orders received
+	// through GossipSub in messages which were not signed by the node which
+	// published them. Mesh always signs the messages it publishes and verifies
+	// the signatures of the messages it receives if present, and the signer is
+	// recorded as the origin of each order. Requiring signatures ensures that
+	// every order can be attributed to the node which published it.
+	RequireMessageSignatures bool `envvar:"REQUIRE_MESSAGE_SIGNATURES" default:"false"`
+	// GossipSubD is the target number of peers to which GossipSub forwards the
+	// messages of each topic. Larger values propagate orders faster at the
+	// cost of more bandwidth. If 0, the default of 6 is used.
+	GossipSubD int `envvar:"GOSSIPSUB_D" default:"0"`
+	// GossipSubDLow is the number of peers below which GossipSub looks for
+	// more peers to forward messages to. If 0, the default of 4 is used.
+	GossipSubDLow int `envvar:"GOSSIPSUB_D_LOW" default:"0"`
+	// GossipSubDHigh is the number of peers above which GossipSub stops
+	// forwarding messages to some of its peers. If 0, the default of 12 is
+	// used.
+	GossipSubDHigh int `envvar:"GOSSIPSUB_D_HIGH" default:"0"`
+	// GossipSubHeartbeatInterval is how often GossipSub updates the set of
+	// peers it forwards messages to and gossips about recent messages to other
+	// peers. If 0, the default of 1s is used.
+	GossipSubHeartbeatInterval time.Duration `envvar:"GOSSIPSUB_HEARTBEAT_INTERVAL" default:"0s"`
+	// TrustedOrderSubmitters is a comma-separated list of peer IDs which are
+	// allowed to add orders directly over libp2p using the order submission
+	// protocol (see the core/ordersubmission package). This is intended for
+	// trusted, co-located services such as market maker engines, for which it
+	// has lower latency than the JSON-RPC API. If empty, the protocol is
+	// disabled.
+	TrustedOrderSubmitters string `envvar:"TRUSTED_ORDER_SUBMITTERS" default:""`
+	// EnableFillabilityScores determines whether or not to compute a
+	// fillability score between 0 and 1 for each order returned by
+	// mesh_getOrders. The score combines the maker's past fill rate, how often
+	// the maker's orders became unfunded and the age of the order, and can be
+	// used to rank orders beyond their price. Scores are computed from the
+	// order events observed since the node started.
+	EnableFillabilityScores bool `envvar:"ENABLE_FILLABILITY_SCORES" default:"false"`
 	// BlockPollingInterval is the polling interval to wait before checking for a new Ethereum block
 	// that might contain transactions that impact the fillability of orders stored by Mesh. Different
 	// chains have different block producing intervals: POW chains are typically slower (e.g., Mainnet)
-	// and POA chains faster (e.g., Kovan) so one should adjust the polling interval accordingly.
-	BlockPollingInterval time.Duration `envvar:"BLOCK_POLLING_INTERVAL" default:"5s"`
+	// and POA chains faster (e.g., Kovan) so one should adjust the polling interval accordingly. If
+	// zero, a default that is tuned for the chain is used (5s for Mainnet).
+	BlockPollingInterval time.Duration `envvar:"BLOCK_POLLING_INTERVAL" default:"0s"`
+	// EthereumMaxReorgDepth is the depth of the deepest block re-org that Mesh
+	// should be able to handle. Mesh stores this many recent block headers. If
+	// zero, a default that is tuned for the chain is used (20 for Mainnet).
+	EthereumMaxReorgDepth int `envvar:"ETHEREUM_MAX_REORG_DEPTH" default:"0"`
+	// BlockHistoryRetention is the number of most recent processed blocks
+	// (including the logs that are relevant to Mesh) to keep in the database.
+	// Unlike the block headers which are stored for handling re-orgs, the
+	// block history is not limited to EthereumMaxReorgDepth blocks and can be
+	// queried via mesh_getBlocks in order to correlate order events with the
+	// blocks in which they happened. If 0, no block history is kept.
+	BlockHistoryRetention int `envvar:"BLOCK_HISTORY_RETENTION" default:"0"`
 	// EthereumRPCMaxContentLength is the maximum request Content-Length accepted by the backing Ethereum RPC
 	// endpoint used by Mesh. Geth & Infura both limit a request's content length to 1024 * 512 Bytes. Parity
 	// and Alchemy have much higher limits. When batch validating 0x orders, we will fit as many orders into a
@@ -142,6 +258,59 @@ type Config struct {
 	// It defaults to the recommended 30 rps for Infura's free tier, and can be increased to 100 rpc for pro users,
 	// and potentially higher on alternative infrastructure.
 	EthereumRPCMaxRequestsPerSecond float64 `envvar:"ETHEREUM_RPC_MAX_REQUESTS_PER_SECOND" default:"30"`
+	// EthereumValidationRPCMaxRequestsPerSecond caps the number of requests per
+	// second sent to EthereumValidationRPCURL. Requests sent to it are limited
+	// independently of (and don't count towards) the limits for EthereumRPCURL.
+	// It has no effect if EthereumValidationRPCURL is empty or if
+	// EnableEthereumRPCRateLimiting is false.
+	EthereumValidationRPCMaxRequestsPerSecond float64 `envvar:"ETHEREUM_VALIDATION_RPC_MAX_REQUESTS_PER_SECOND" default:"30"`
+	// EthereumRPCCallCacheSize is the maximum number of eth_call results (e.g.
+	// the order states returned by the DevUtils contract) that Mesh caches.
+	// Results are keyed by block hash and evicted if the block is re-orged
+	// out, so only identical calls made at the same block are served from the
+	// cache. This can reduce the number of requests sent to the Ethereum RPC
+	// provider, e.g. when the same order is received from many peers. If 0,
+	// eth_call results are not cached.
+	EthereumRPCCallCacheSize int `envvar:"ETHEREUM_RPC_CALL_CACHE_SIZE" default:"0"`
+	// EthereumRPCCodeCacheSize is the maximum number of eth_getCode results
+	// that Mesh caches. Like eth_call results, they are keyed by block hash. If
+	// 0, eth_getCode results are not cached.
+	EthereumRPCCodeCacheSize int `envvar:"ETHEREUM_RPC_CODE_CACHE_SIZE" default:"0"`
+	// EthereumRPCDegradedMode determines whether the block watcher should
+	// avoid the Ethereum RPC requests which light clients and log-only
+	// providers often don't support. In degraded mode, blocks are fetched by
+	// number instead of by hash and logs are filtered by block number instead
+	// of block hash. Degraded mode is also enabled automatically if the
+	// Ethereum RPC endpoint reports that it doesn't support these requests.
+	EthereumRPCDegradedMode bool `envvar:"ETHEREUM_RPC_DEGRADED_MODE" default:"false"`
+	// EthereumRPCMaxGetLogsBlockRange is the maximum number of blocks that Mesh
+	// fetches logs for in a single eth_getLogs request. Mesh reduces it
+	// automatically if the Ethereum RPC endpoint rejects requests for spanning
+	// too many blocks.
+	EthereumRPCMaxGetLogsBlockRange int `envvar:"ETHEREUM_RPC_MAX_GET_LOGS_BLOCK_RANGE" default:"60"`
+	// EthereumRPCGetLogsBatchSize is the number of eth_getLogs requests that
+	// Mesh sends concurrently while catching up on missed blocks.
+	EthereumRPCGetLogsBatchSize int `envvar:"ETHEREUM_RPC_GET_LOGS_BATCH_SIZE" default:"3"`
+	// EthereumRPCMaxOrdersPerBatch is the maximum number of orders that Mesh
+	// validates in a single eth_call. If zero, the number of orders per call
+	// is only limited by EthereumRPCMaxContentLength. Either way, Mesh splits
+	// calls in half automatically if the Ethereum RPC endpoint rejects them
+	// for exceeding its gas or response size limits.
+	EthereumRPCMaxOrdersPerBatch int `envvar:"ETHEREUM_RPC_MAX_ORDERS_PER_BATCH" default:"0"`
+	// EthereumRPCMaxBlockHistory is the number of recent blocks for which the
+	// Ethereum RPC endpoint can serve logs. If Mesh falls further behind the
+	// latest block than this (e.g. while offline), it re-validates all orders
+	// instead of catching up on the missed blocks. Since block headers older
+	// than this cannot be used to recover from re-orgs, EthereumMaxReorgDepth
+	// is reduced to this value if it is larger.
+	EthereumRPCMaxBlockHistory int `envvar:"ETHEREUM_RPC_MAX_BLOCK_HISTORY" default:"128"`
+	// EthereumRPCNarrowLogFilters determines whether Mesh should only fetch
+	// the logs which can affect the orders it stores, i.e. the events of the
+	// tokens and makers in those orders. This can reduce the number of logs
+	// fetched substantially for nodes which only store orders for a few tokens
+	// or makers, at the cost of sending a few eth_getLogs requests instead of
+	// one.
+	EthereumRPCNarrowLogFilters bool `envvar:"ETHEREUM_RPC_NARROW_LOG_FILTERS" default:"false"`
 	// CustomContractAddresses is a JSON-encoded string representing a set of
 	// custom addresses to use for the configured chain ID. The contract
 	// addresses for most common chains/networks are already included by default, so this
@@ -165,6 +334,54 @@ type Config struct {
 	// enforcing a limit on maximum expiration time for incoming orders and remove
 	// any orders with an expiration time too far in the future.
 	MaxOrdersInStorage int `envvar:"MAX_ORDERS_IN_STORAGE" default:"100000"`
+	// DBSlowQueryThreshold is the duration after which a database query is
+	// logged as slow, along with the collection, index, and filter that were
+	// used. Slow queries are also counted in the slowDBQueries stat. A threshold
+	// of 0 disables slow query logging.
+	DBSlowQueryThreshold time.Duration `envvar:"DB_SLOW_QUERY_THRESHOLD" default:"1s"`
+	// ColdOrderMinTimeToExpiry is the minimum amount of time until an order
+	// expires for it to be moved to the cold tier of the database. Cold orders
+	// are stored in a separate table with only a few indexes, which keeps
+	// queries on the remaining (hot) orders fast for very large stores. Cold
+	// orders are still watched and are moved back to the hot tier as soon as
+	// something might change their fillability. They are returned by
+	// mesh_getOrders after all hot orders, but not by mesh_queryOrders. If 0,
+	// orders are not moved to the cold tier based on their expiration time.
+	ColdOrderMinTimeToExpiry time.Duration `envvar:"COLD_ORDER_MIN_TIME_TO_EXPIRY" default:"0s"`
+	// ColdOrderMinPriceDistance is the minimum relative distance between the
+	// price of an order and the best price on the same side of its market for
+	// the order to be moved to the cold tier of the database (e.g. 0.5 means
+	// asks priced at least 50% above the best ask and bids priced at least 50%
+	// below the best bid). If 0, orders are not moved to the cold tier based on
+	// their price.
+	ColdOrderMinPriceDistance float64 `envvar:"COLD_ORDER_MIN_PRICE_DISTANCE" default:"0"`
+	// ColdOrderTieringInterval is how often orders are moved between the hot
+	// and cold tiers of the database if ColdOrderMinTimeToExpiry or
+	// ColdOrderMinPriceDistance is set. Orders which expire within two
+	// intervals are always kept in the hot tier.
+	ColdOrderTieringInterval time.Duration `envvar:"COLD_ORDER_TIERING_INTERVAL" default:"10m"`
+	// DBCompactionInterval is how often the database is compacted. The disk
+	// space used by orders and other data which were removed (e.g. because
+	// orders were evicted, filled or expired) is only reclaimed gradually
+	// otherwise, so compacting the database keeps its size on disk close to the
+	// size of the data it holds. Compacting a large database takes a while and
+	// uses a lot of disk I/O, but the node keeps running normally while it
+	// happens. The fragmentation of the database is measured by each compaction
+	// and reported in the dbFragmentationPercent stat. If 0, the database is
+	// only compacted automatically by LevelDB.
+	DBCompactionInterval time.Duration `envvar:"DB_COMPACTION_INTERVAL" default:"24h"`
+	// SchemaValidationTimeout, EthValidationTimeout and DBWriteTimeout bound
+	// how long each stage of validating and storing new orders may take: JSON
+	// schema validation (only for orders received via AddOrders), on-chain
+	// validation (including any time spent waiting for the ETH RPC rate
+	// limiter) and writing the valid orders to the database. Orders which could
+	// not be validated and stored in time are rejected with the
+	// ValidationTimedOut status. If 0, the stage is only bounded by the context
+	// of the request. Embedders can override these timeouts for individual calls
+	// to App.AddOrders by using ordervalidator.WithStageTimeouts.
+	SchemaValidationTimeout time.Duration `envvar:"SCHEMA_VALIDATION_TIMEOUT" default:"0s"`
+	EthValidationTimeout    time.Duration `envvar:"ETH_VALIDATION_TIMEOUT" default:"1m"`
+	DBWriteTimeout          time.Duration `envvar:"DB_WRITE_TIMEOUT" default:"0s"`
 	// CustomOrderFilter is a stringified JSON Schema which will be used for
 	// validating incoming orders. If provided, Mesh will only receive orders from
 	// other peers in the network with the same filter.
@@ -185,14 +402,201 @@ type Config struct {
 	// all the required fields) are automatically included. For more information
 	// on JSON Schemas, see https://json-schema.org/
 	CustomOrderFilter string `envvar:"CUSTOM_ORDER_FILTER" default:"{}"`
+	// AdditionalOrderFilters is an optional JSON array of order filters whose
+	// topics Mesh will join in addition to the topic for CustomOrderFilter. This
+	// allows a single node to serve several relayers with different filters.
+	// Each entry must contain exactly one of "filter" (a JSON Schema in the same
+	// format as CustomOrderFilter), "filterFile" (the path to a file containing
+	// such a schema) or "topic" (the pubsub topic of an existing filter). An
+	// entry may also contain "maxOrders", which is the maximum number of orders
+	// received on its topic that will be stored. Each topic with "maxOrders"
+	// has its own quota: its orders don't count toward MaxOrdersInStorage and
+	// are only evicted to make space for orders on the same topic, so a busy
+	// topic can't evict the orders of a quieter one. Since topics include the
+	// chain ID, quotas are also separate per chain. For example:
+	//
+	//    [
+	//        {"filter": {"properties": {"makerAddress": {"const": "0xa3ece5d5b6319fa785efc10d3112769a46c6e149"}}}, "maxOrders": 10000},
+	//        {"filterFile": "/filters/relayer.json"}
+	//    ]
+	//
+	// Orders from the additional topics are only received through GossipSub.
+	// Ordersync always uses CustomOrderFilter.
+	AdditionalOrderFilters string `envvar:"ADDITIONAL_ORDER_FILTERS" default:""`
+	// NetworkID is an optional identifier for a private Mesh network. If it is
+	// set, Mesh uses separate pubsub topics, rendezvous points, and ordersync
+	// subprotocols which include the network ID, so that organizations can run an
+	// isolated network on a public Ethereum chain without sharing orders with
+	// the main 0x Mesh network. All nodes in the private network must use the
+	// same network ID, which may only contain letters, digits, hyphens, and
+	// underscores. Note that the default bootstrap nodes are part of the main
+	// network, so BOOTSTRAP_LIST should usually be set as well.
+	NetworkID string `envvar:"MESH_NETWORK_ID" default:""`
+	// FeeRecipientAllowlist is an optional comma-separated list of fee
+	// recipient addresses. If it is set, Mesh will reject any new orders whose
+	// feeRecipientAddress is not in the list, which allows relayers to run nodes
+	// that only store and propagate orders that pay fees to them.
+	FeeRecipientAllowlist string `envvar:"FEE_RECIPIENT_ALLOWLIST" default:""`
+	// MaxMakerFee is the maximum makerFee (in base units of the maker fee
+	// asset) that new orders can have. If empty, there is no maximum.
+	MaxMakerFee string `envvar:"MAX_MAKER_FEE" default:""`
+	// MaxTakerFee is the maximum takerFee (in base units of the taker fee
+	// asset) that new orders can have. If empty, there is no maximum.
+	MaxTakerFee string `envvar:"MAX_TAKER_FEE" default:""`
+	// MinMakerAssetAmount is the minimum makerAssetAmount (in base units of the
+	// maker asset) that new orders can have. It can be used to reject dust
+	// orders. If empty, there is no minimum.
+	MinMakerAssetAmount string `envvar:"MIN_MAKER_ASSET_AMOUNT" default:""`
+	// MinTakerAssetAmount is the minimum takerAssetAmount (in base units of the
+	// taker asset) that new orders can have. If empty, there is no minimum.
+	MinTakerAssetAmount string `envvar:"MIN_TAKER_ASSET_AMOUNT" default:""`
+	// MinOrderValueUSD is the minimum value in USD (e.g. "5.00") that new
+	// orders can have, as determined by PriceOracle. Orders whose value cannot
+	// be determined are accepted. If empty, there is no minimum. PriceOracle
+	// must be set if MinOrderValueUSD is set.
+	MinOrderValueUSD string `envvar:"MIN_ORDER_VALUE_USD" default:""`
+	// DropTakerRestrictedOrders is whether to drop orders with a non-zero
+	// takerAddress (which can only be filled by a single taker) from GossipSub
+	// and ordersync. Such orders are not stored or relayed to peers. Orders
+	// added via AddOrders are still stored but are not shared with peers. It
+	// can be used by general-purpose nodes to reduce noise.
+	DropTakerRestrictedOrders bool `envvar:"DROP_TAKER_RESTRICTED_ORDERS" default:"false"`
+	// EnableSoftCancels determines whether or not to support soft
+	// cancellations, which are messages signed by the maker of an order that
+	// ask nodes to stop sharing the order. They give makers a way to cancel
+	// orders without paying gas, but are only advisory since anyone who
+	// already has the order can still fill it. If enabled, Mesh subscribes to
+	// a separate topic on which soft cancellations are gossiped and no longer
+	// shares soft cancelled orders via GossipSub or ordersync. Soft cancelled
+	// orders are still stored. Soft cancellations are only kept in memory.
+	EnableSoftCancels bool `envvar:"ENABLE_SOFT_CANCELS" default:"false"`
+	// OrderPolicyPluginPath is the path to a Go plugin (built with `go build
+	// -buildmode=plugin`) which exports an order policy named "OrderPolicy".
+	// The policy is consulted for every new order and can reject it or attach
+	// annotations to it, which allows operators to enforce custom listing
+	// rules without forking Mesh. See the orderpolicy package for details.
+	// Plugins are not supported in browsers. If empty, no plugin is loaded.
+	OrderPolicyPluginPath string `envvar:"ORDER_POLICY_PLUGIN_PATH" default:""`
+	// InboundQueueSize is the maximum number of order messages received from
+	// peers which can be waiting to be validated. Bounding the queue ensures
+	// that a burst of messages can't cause memory usage to grow without limit.
+	// If 0, a default of 10,000 (2,000 in browsers) is used.
+	InboundQueueSize int `envvar:"INBOUND_QUEUE_SIZE" default:"0"`
+	// InboundQueueOverflowPolicy determines which messages are dropped when the
+	// inbound queue is full. It is either "drop-oldest" (the oldest queued
+	// message is dropped to make room for the new one) or "drop-new" (the new
+	// message is dropped).
+	InboundQueueOverflowPolicy string `envvar:"INBOUND_QUEUE_OVERFLOW_POLICY" default:"drop-oldest"`
+	// MaxValidationMemoryBytes is the maximum approximate number of bytes of
+	// memory that decoded orders waiting to be validated can use. It applies
+	// to orders received through both GossipSub and ordersync, and prevents
+	// memory usage from growing without limit when many orders are received
+	// at once (e.g. during the initial ordersync on a small instance). If 0,
+	// there is no maximum.
+	MaxValidationMemoryBytes int `envvar:"MAX_VALIDATION_MEMORY_BYTES" default:"0"`
+	// ValidationMemoryPolicy determines what happens when orders would exceed
+	// MaxValidationMemoryBytes. It is either "wait" (validation waits until
+	// other orders have been validated, which slows down ordersync and lets
+	// the inbound queue fill up) or "shed" (the orders are dropped).
+	ValidationMemoryPolicy string `envvar:"VALIDATION_MEMORY_POLICY" default:"wait"`
+	// OrderEventsBufferSize is the number of batches of order events which
+	// are buffered for each order event subscriber (e.g. each subscription via
+	// JSON-RPC or SubscribeToOrderEvents) that can't keep up. If 0, a default
+	// of 1,000 is used.
+	OrderEventsBufferSize int `envvar:"ORDER_EVENTS_BUFFER_SIZE" default:"0"`
+	// OrderEventsOverflowPolicy determines what happens when the order event
+	// buffer of a subscriber is full. It is either "block" (order events are
+	// not delivered to any subscriber until the slow subscriber catches up,
+	// which also delays order validation and block processing),
+	// "drop-oldest" (the oldest buffered order events of the slow subscriber
+	// are dropped to make room for the new ones) or "drop-new" (the new order
+	// events are dropped for the slow subscriber). Subscribers can detect
+	// dropped order events by gaps in their sequence numbers.
+	OrderEventsOverflowPolicy string `envvar:"ORDER_EVENTS_OVERFLOW_POLICY" default:"block"`
+	// LogRedactFields is a comma-separated list of log fields which should be
+	// redacted for privacy reasons. Each entry is either "key" (the field is
+	// dropped) or "key:hash" (the value is replaced with its SHA-256 hash).
+	// Fields are matched at the top level of each log and inside of nested
+	// objects. For example, "signedOrder:drop,makerAddress:hash" drops raw
+	// signed orders and hashes maker addresses.
+	LogRedactFields string `envvar:"LOG_REDACT_FIELDS" default:""`
 	// EthereumRPCClient is the client to use for all Ethereum RPC reuqests. It is only
 	// settable in browsers and cannot be set via environment variable. If
 	// provided, EthereumRPCURL will be ignored.
 	EthereumRPCClient ethclient.RPCClient `envvar:"-"`
+	// EthereumClient is a custom implementation of ethrpcclient.Client which
+	// will be used by the block watcher and order validator for all Ethereum RPC
+	// requests. It allows embedders to route requests through their own
+	// provider managers or caches (or to use a mock in tests). It can only be
+	// set programmatically. If provided, EthereumRPCURL and EthereumRPCClient
+	// will be ignored and the custom client is responsible for enforcing its
+	// own request timeouts and rate limits.
+	EthereumClient ethrpcclient.Client `envvar:"-"`
+	// CustomAssetValidators is a list of validators which add support for asset
+	// types that Mesh does not support natively. They can only be set
+	// programmatically (or via the browser config) and cannot be set via
+	// environment variable. See ordervalidator.AssetValidator for details.
+	CustomAssetValidators []ordervalidator.AssetValidator `envvar:"-"`
+	// OrderPolicy is an operator-defined policy which can reject or annotate
+	// new orders. It can only be set programmatically and cannot be combined
+	// with OrderPolicyPluginPath.
+	OrderPolicy orderpolicy.Policy `envvar:"-"`
+	// PriceOracle returns the value of assets in USD and is used to enforce
+	// MinOrderValueUSD. It can only be set programmatically.
+	PriceOracle orderwatch.PriceOracle `envvar:"-"`
+	// ReplayRecordPath is the path of a file to which all pubsub messages and
+	// block events received by Mesh are recorded. The recording can later be
+	// replayed into a fresh node (e.g. with `mesh replay <path>`) in order to
+	// reproduce bugs in the order pipeline offline. If empty, nothing is
+	// recorded.
+	ReplayRecordPath string `envvar:"REPLAY_RECORD_PATH" default:""`
+	// OrderSyncSnapshotInterval is how often to materialize an immutable
+	// snapshot of all orders which is used to serve ordersync requests from
+	// other peers. This avoids scanning the database for every request, which
+	// can be expensive for nodes that serve many syncing peers at once, at the
+	// cost of keeping up to two copies of the order book in memory and serving
+	// orders that may be slightly out of date. If 0, snapshots are not
+	// materialized and each ordersync request is served from the database.
+	OrderSyncSnapshotInterval time.Duration `envvar:"ORDERSYNC_SNAPSHOT_INTERVAL" default:"0s"`
+	// OrderSyncMaxConcurrentSessions is the maximum number of peers which are
+	// served via ordersync at once. Peers which request orders while the limit
+	// is reached wait in a queue (see OrderSyncMaxQueuedSessions) or sync with
+	// other nodes instead. This keeps a large number of peers which start at
+	// the same time from saturating the uplink of the node. If 0, the number of
+	// peers is not limited.
+	OrderSyncMaxConcurrentSessions int `envvar:"ORDERSYNC_MAX_CONCURRENT_SESSIONS" default:"0"`
+	// OrderSyncMaxQueuedSessions is the maximum number of peers which can wait
+	// up to 15 seconds to be served via ordersync while
+	// OrderSyncMaxConcurrentSessions peers are being served.
+	OrderSyncMaxQueuedSessions int `envvar:"ORDERSYNC_MAX_QUEUED_SESSIONS" default:"10"`
+	// OrderSyncMaxBytesPerSecond is the maximum rate in bytes per second at
+	// which orders are sent to peers via ordersync, shared between all peers.
+	// Peers give up if a page of orders takes longer than 30 seconds to
+	// arrive, so it shouldn't be set much lower than 100000 (100 KB/s). If 0,
+	// the rate is not limited.
+	OrderSyncMaxBytesPerSecond int `envvar:"ORDERSYNC_MAX_BYTES_PER_SECOND" default:"0"`
+	// OrderChecksumInterval is how often to compare a checksum of the stored
+	// orders which match the filter of each topic with the checksum of a
+	// random peer. If the checksums differ in two consecutive comparisons
+	// with the same peer, Mesh resyncs with that peer via ordersync. This
+	// detects orders which were missed (e.g. while Mesh was offline) long before the next regular
+	// ordersync. If 0, Mesh doesn't compare checksums, but it still responds
+	// to requests from its peers.
+	OrderChecksumInterval time.Duration `envvar:"ORDER_CHECKSUM_INTERVAL" default:"10m"`
+	// ValidationTraceSampleRate is the fraction (between 0 and 1) of batches of
+	// orders received from peers for which a validation trace (signature
+	// validation time and the number and duration of eth_calls) is logged for
+	// each order at the debug level. This can help pinpoint the causes of slow
+	// order validation. Defaults to 0, which disables tracing.
+	ValidationTraceSampleRate float64 `envvar:"VALIDATION_TRACE_SAMPLE_RATE" default:"0"`
 }
 
 type snapshotInfo struct {
-	Snapshot            *db.Snapshot
+	Snapshot *db.Snapshot
+	// ColdSnapshot is a snapshot of the cold orders collection at the same
+	// point in time as Snapshot. It shares the underlying DB snapshot with
+	// Snapshot, so it doesn't need to be released separately.
+	ColdSnapshot        *db.Snapshot
 	CreatedAt           time.Time
 	ExpirationTimestamp time.Time
 }
@@ -208,6 +612,7 @@ type App struct {
 	orderWatcher              *orderwatch.Watcher
 	orderValidator            *ordervalidator.OrderValidator
 	orderFilter               *orderfilter.Filter
+	additionalOrderFilters    []*additionalOrderFilter
 	snapshotExpirationWatcher *expirationwatch.Watcher
 	muIdToSnapshotInfo        sync.Mutex
 	idToSnapshotInfo          map[string]snapshotInfo
@@ -216,10 +621,79 @@ type App struct {
 	db                        *meshdb.MeshDB
 	ordersyncService          *ordersync.Service
 	contractAddresses         *ethereum.ContractAddresses
+	// exchange is used to read the order epochs of makers which are not known
+	// from recent CancelUpTo events.
+	exchange *wrappers.ExchangeCaller
+	// recorder is used to record messages and block events if
+	// config.ReplayRecordPath is set. Otherwise it is nil.
+	recorder *replay.Recorder
+	// orderSyncSnapshots holds the materialized order book snapshots which are
+	// used to serve ordersync requests if config.OrderSyncSnapshotInterval is
+	// set. Otherwise it is always empty.
+	orderSyncSnapshots orderSyncSnapshots
+	// trustedOrderSubmitters are the peers which are allowed to add orders via
+	// the order submission protocol (see config.TrustedOrderSubmitters).
+	trustedOrderSubmitters []peer.ID
+	// keyRotation is announced to peers if the private key of the node was
+	// rotated and the grace period hasn't ended yet. Otherwise it is nil.
+	keyRotation *p2p.KeyRotation
+	// fillScorer computes fillability scores if config.EnableFillabilityScores
+	// is true. Otherwise it is nil.
+	fillScorer *fillscore.Scorer
+	// ethRPCCache caches the results of Ethereum RPC requests if
+	// config.EthereumRPCCallCacheSize or config.EthereumRPCCodeCacheSize is
+	// set. Otherwise it is nil.
+	ethRPCCache *ethrpcclient.CachingClient
+	// validationEthRPCClient is the client used to validate orders. It is the
+	// same as ethRPCClient unless config.EthereumValidationRPCURL is set.
+	validationEthRPCClient ethrpcclient.Client
+	// allowedSubnets and deniedSubnets are the parsed values of
+	// config.P2PAllowedSubnets and config.P2PDeniedSubnets.
+	allowedSubnets []net.IPNet
+	deniedSubnets  []net.IPNet
+	// resyncMu ensures that only one resync (see Resync) runs at a time.
+	resyncMu sync.Mutex
+	// validationMemory accounts for the memory used by orders awaiting
+	// validation and enforces config.MaxValidationMemoryBytes.
+	validationMemory *validationMemory
+	// orderIngestion deduplicates orders which are validated concurrently via
+	// AddOrders, GossipSub and ordersync.
+	orderIngestion *orderIngestion
+	// dbCompactionStats keeps track of the results of database compactions.
+	dbCompactionStats dbCompactionStats
+	// mismatchedDomainOrders is the number of orders which were rejected since
+	// startup because they are for a different chain or exchange. It must only
+	// be accessed atomically.
+	mismatchedDomainOrders uint64
+	// orderEventsOverflowPolicy is the parsed config.OrderEventsOverflowPolicy.
+	orderEventsOverflowPolicy OrderEventsOverflowPolicy
+	// droppedOrderEvents is the number of order events which were dropped
+	// since startup because a subscriber couldn't keep up. It must only be
+	// accessed atomically.
+	droppedOrderEvents uint64
+	// softCancels holds the soft cancellations received from makers and peers
+	// if config.EnableSoftCancels is true. Otherwise it is nil.
+	softCancels *softcancel.Store
+	// hidden is 1 while Mesh is running in a browser page which is hidden and
+	// 0 otherwise. It must be accessed atomically.
+	hidden int32
+	// ingestionPaused is 1 while ingestion of orders from the network is paused
+	// (see PauseIngestion) and 0 otherwise. It must be accessed atomically.
+	ingestionPaused int32
+
+	// orderRetryQueue holds the orders received from peers which were
+	// rejected for a retriable reason.
+	orderRetryQueue *orderRetryQueue
+	// peerFilterVerifications keeps track of the peers whose order filter was
+	// verified for the custom topics they send messages on.
+	peerFilterVerifications *peerFilterVerifications
 
 	// started is closed to signal that the App has been started. Some methods
 	// will block until after the App is started.
 	started chan struct{}
+	// startTime is the time at which the App was created. It is used to
+	// compute the uptime of the node.
+	startTime time.Time
 }
 
 var setupLoggerOnce = &sync.Once{}
@@ -231,15 +705,22 @@ func New(config Config) (*App, error) {
 func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	// Configure logger
 	// TODO(albrow): Don't use global variables for log settings.
+	redactHook, err := loghooks.NewRedactHook(config.LogRedactFields)
+	if err != nil {
+		return nil, err
+	}
 	setupLoggerOnce.Do(func() {
-		log.SetFormatter(&log.JSONFormatter{})
+		logFilterFormatter.SetLevels(log.Level(config.Verbosity), nil)
+		log.SetFormatter(logFilterFormatter)
 		log.SetLevel(log.Level(config.Verbosity))
+		// The redact hook must be added before the key suffix hook so that
+		// fields are matched by their original keys.
+		log.AddHook(redactHook)
 		log.AddHook(loghooks.NewKeySuffixHook())
 	})
 
 	// Add custom contract addresses if needed.
 	var contractAddresses ethereum.ContractAddresses
-	var err error
 	if config.CustomContractAddresses != "" {
 		contractAddresses, err = parseAndValidateCustomContractAddresses(config.EthereumChainID, config.CustomContractAddresses)
 	} else {
@@ -249,6 +730,116 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		return nil, err
 	}
 
+	// Use the default parameters for the chain if they were not specified.
+	chainParams := ethereum.ChainParamsForChainID(config.EthereumChainID)
+	if config.BlockPollingInterval == 0 {
+		config.BlockPollingInterval = chainParams.BlockPollingInterval
+	}
+	if config.EthereumMaxReorgDepth == 0 {
+		config.EthereumMaxReorgDepth = chainParams.MaxReorgDepth
+	}
+	if config.EthereumRPCMaxGetLogsBlockRange == 0 {
+		config.EthereumRPCMaxGetLogsBlockRange = 60
+	}
+	if config.EthereumRPCMaxBlockHistory == 0 {
+		config.EthereumRPCMaxBlockHistory = constants.MaxBlocksStoredInNonArchiveNode
+	}
+	if config.EthereumRPCMaxGetLogsBlockRange < 0 {
+		return nil, errors.New("ETHEREUM_RPC_MAX_GET_LOGS_BLOCK_RANGE cannot be negative")
+	}
+	if config.EthereumRPCMaxBlockHistory < 0 {
+		return nil, errors.New("ETHEREUM_RPC_MAX_BLOCK_HISTORY cannot be negative")
+	}
+	if config.EthereumRPCGetLogsBatchSize < 0 {
+		return nil, errors.New("ETHEREUM_RPC_GET_LOGS_BATCH_SIZE cannot be negative")
+	}
+	if config.EthereumRPCMaxOrdersPerBatch < 0 {
+		return nil, errors.New("ETHEREUM_RPC_MAX_ORDERS_PER_BATCH cannot be negative")
+	}
+	if config.P2PReservedPeerSlotsFraction < 0 || config.P2PReservedPeerSlotsFraction > 1 {
+		return nil, errors.New("P2P_RESERVED_PEER_SLOTS_FRACTION must be between 0 and 1")
+	}
+	if config.P2PIdlePeerTimeout < 0 {
+		return nil, errors.New("P2P_IDLE_PEER_TIMEOUT cannot be negative")
+	}
+	if config.EthereumMaxReorgDepth > config.EthereumRPCMaxBlockHistory {
+		log.WithFields(log.Fields{
+			"ethereumMaxReorgDepth":      config.EthereumMaxReorgDepth,
+			"ethereumRPCMaxBlockHistory": config.EthereumRPCMaxBlockHistory,
+		}).Warn("max re-org depth exceeds the block history of the Ethereum RPC endpoint; reducing it")
+		config.EthereumMaxReorgDepth = config.EthereumRPCMaxBlockHistory
+	}
+
+	if config.InboundQueueSize < 0 {
+		return nil, errors.New("INBOUND_QUEUE_SIZE cannot be negative")
+	}
+	if _, err := p2p.ParseOverflowPolicy(config.InboundQueueOverflowPolicy); err != nil {
+		return nil, err
+	}
+	if err := gossipSubParams(config).Validate(); err != nil {
+		return nil, err
+	}
+	if config.MinPeerProtocolVersion != "" {
+		if err := p2p.ValidateMinPeerProtocolVersion(config.MinPeerProtocolVersion); err != nil {
+			return nil, fmt.Errorf("invalid MIN_PEER_PROTOCOL_VERSION: %s", err.Error())
+		}
+	}
+	if config.OrderChecksumInterval < 0 {
+		return nil, errors.New("ORDER_CHECKSUM_INTERVAL cannot be negative")
+	}
+	if config.OrderSyncMaxConcurrentSessions < 0 || config.OrderSyncMaxQueuedSessions < 0 || config.OrderSyncMaxBytesPerSecond < 0 {
+		return nil, errors.New("ORDERSYNC_MAX_CONCURRENT_SESSIONS, ORDERSYNC_MAX_QUEUED_SESSIONS and ORDERSYNC_MAX_BYTES_PER_SECOND cannot be negative")
+	}
+	if config.EthereumValidationRPCURL != "" && config.EnableEthereumRPCRateLimiting && config.EthereumValidationRPCMaxRequestsPerSecond <= 0 {
+		return nil, errors.New("ETHEREUM_VALIDATION_RPC_MAX_REQUESTS_PER_SECOND must be positive")
+	}
+	if config.BlockHistoryRetention < 0 {
+		return nil, errors.New("BLOCK_HISTORY_RETENTION cannot be negative")
+	}
+	if config.ColdOrderMinTimeToExpiry < 0 || config.ColdOrderMinPriceDistance < 0 {
+		return nil, errors.New("COLD_ORDER_MIN_TIME_TO_EXPIRY and COLD_ORDER_MIN_PRICE_DISTANCE cannot be negative")
+	}
+	if coldOrderCriteria(config).Enabled() && config.ColdOrderTieringInterval <= 0 {
+		return nil, errors.New("COLD_ORDER_TIERING_INTERVAL must be positive")
+	}
+	if config.DBCompactionInterval < 0 {
+		return nil, errors.New("DB_COMPACTION_INTERVAL cannot be negative")
+	}
+	if config.SchemaValidationTimeout < 0 || config.EthValidationTimeout < 0 || config.DBWriteTimeout < 0 {
+		return nil, errors.New("SCHEMA_VALIDATION_TIMEOUT, ETH_VALIDATION_TIMEOUT and DB_WRITE_TIMEOUT cannot be negative")
+	}
+	if config.MaxValidationMemoryBytes < 0 {
+		return nil, errors.New("MAX_VALIDATION_MEMORY_BYTES cannot be negative")
+	}
+	validationMemoryPolicy, err := ParseValidationMemoryPolicy(config.ValidationMemoryPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if config.OrderEventsBufferSize == 0 {
+		config.OrderEventsBufferSize = defaultOrderEventsBufferSize
+	} else if config.OrderEventsBufferSize < 0 {
+		return nil, errors.New("ORDER_EVENTS_BUFFER_SIZE cannot be negative")
+	}
+	orderEventsOverflowPolicy, err := ParseOrderEventsOverflowPolicy(config.OrderEventsOverflowPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if config.ValidationTraceSampleRate < 0 || config.ValidationTraceSampleRate > 1 {
+		return nil, errors.New("VALIDATION_TRACE_SAMPLE_RATE must be between 0 and 1")
+	}
+	allowedSubnets, err := p2p.ParseSubnets(config.P2PAllowedSubnets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet in P2P_ALLOWED_SUBNETS: %s", err.Error())
+	}
+	deniedSubnets, err := p2p.ParseSubnets(config.P2PDeniedSubnets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet in P2P_DENIED_SUBNETS: %s", err.Error())
+	}
+	trustedOrderSubmitters, err := parseTrustedOrderSubmitters(config.TrustedOrderSubmitters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load private key and add peer ID hook.
 	privKeyPath := filepath.Join(config.DataDir, "keys", "privkey")
 	privKey, err := initPrivateKey(privKeyPath)
@@ -260,6 +851,10 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		return nil, err
 	}
 	log.AddHook(loghooks.NewPeerIDHook(peerID))
+	keyRotation, err := loadKeyRotation(config.DataDir, peerID)
+	if err != nil {
+		return nil, err
+	}
 
 	if config.EthereumRPCMaxContentLength < constants.MaxOrderSizeInBytes {
 		return nil, fmt.Errorf("Cannot set `EthereumRPCMaxContentLength` to be less then MaxOrderSizeInBytes: %d", constants.MaxOrderSizeInBytes)
@@ -286,6 +881,22 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := meshDB.UpdateMiniHeaderRetentionLimit(config.EthereumMaxReorgDepth); err != nil {
+		return nil, err
+	}
+	meshDB.SetSlowQueryThreshold(config.DBSlowQueryThreshold)
+
+	// Move all cold orders back to the hot tier if tiering was disabled since
+	// the last time Mesh was started.
+	if !coldOrderCriteria(config).Enabled() {
+		numThawed, err := meshDB.ThawAllOrders()
+		if err != nil {
+			return nil, err
+		}
+		if numThawed > 0 {
+			log.WithField("numThawed", numThawed).Info("moved all cold orders back to the hot tier")
+		}
+	}
 
 	// Initialize metadata and check stored chain id (if any).
 	metadata, err := initMetadata(config.EthereumChainID, meshDB)
@@ -307,24 +918,42 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	}
 
 	// Initialize the ETH client, which will be used by various watchers.
-	var ethRPCClient ethclient.RPCClient
-	if config.EthereumRPCClient != nil {
-		if config.EthereumRPCURL != "" {
-			log.Warn("Ignoring EthereumRPCURL and using the provided EthereumRPCClient")
+	ethClient, err := newEthClient(config, ethRPCRateLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize a separate ETH client for validating orders if needed.
+	// Otherwise the same client is used for everything.
+	validationEthClient := ethClient
+	if config.EthereumValidationRPCURL != "" {
+		var validationRateLimiter ratelimit.RateLimiter
+		if config.EnableEthereumRPCRateLimiting == false {
+			validationRateLimiter = ratelimit.NewUnlimited()
+		} else {
+			validationRateLimiter = ratelimit.NewPerSecond(config.EthereumValidationRPCMaxRequestsPerSecond, clock.New())
 		}
-		ethRPCClient = config.EthereumRPCClient
-	} else if config.EthereumRPCURL != "" {
-		ethRPCClient, err = rpc.Dial(config.EthereumRPCURL)
+		validationEthClient, err = newValidationEthClient(config, validationRateLimiter)
 		if err != nil {
-			log.WithError(err).Error("Could not dial EthereumRPCURL")
 			return nil, err
 		}
-	} else {
-		return nil, errors.New("cannot initialize core.App: neither EthereumRPCURL or EthereumRPCClient were provided")
 	}
-	ethClient, err := ethrpcclient.New(ethRPCClient, ethereumRPCRequestTimeout, ethRPCRateLimiter)
-	if err != nil {
-		return nil, err
+
+	// The cache only stores the results of eth_call and eth_getCode requests,
+	// which are made by the order validator.
+	var ethRPCCache *ethrpcclient.CachingClient
+	if config.EthereumRPCCallCacheSize != 0 || config.EthereumRPCCodeCacheSize != 0 {
+		ethRPCCache, err = ethrpcclient.NewCachingClient(validationEthClient, ethrpcclient.CacheConfig{
+			CallCacheSize: config.EthereumRPCCallCacheSize,
+			CodeCacheSize: config.EthereumRPCCodeCacheSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ethereum RPC cache config: %s", err.Error())
+		}
+		if config.EthereumValidationRPCURL == "" {
+			ethClient = ethRPCCache
+		}
+		validationEthClient = ethRPCCache
 	}
 
 	// Initialize block watcher (but don't start it yet).
@@ -364,17 +993,34 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	}
 	stack := simplestack.New(meshDB.MiniHeaderRetentionLimit, miniHeaders)
 	blockWatcherConfig := blockwatch.Config{
-		Stack:           stack,
-		PollingInterval: config.BlockPollingInterval,
-		WithLogs:        true,
-		Topics:          topics,
-		Client:          blockWatcherClient,
+		Stack:                   stack,
+		PollingInterval:         config.BlockPollingInterval,
+		WithLogs:                true,
+		Topics:                  topics,
+		Client:                  blockWatcherClient,
+		DegradedMode:            config.EthereumRPCDegradedMode,
+		MaxBlocksInGetLogsQuery: config.EthereumRPCMaxGetLogsBlockRange,
+		GetLogsRequestChunkSize: config.EthereumRPCGetLogsBatchSize,
+		MaxBlockHistory:         config.EthereumRPCMaxBlockHistory,
+	}
+	// orderWatcher is initialized below, before the block watcher is started.
+	var orderWatcher *orderwatch.Watcher
+	if recoverFromBlockGaps {
+		blockWatcherConfig.OnTooManyBlocksBehind = func(ctx context.Context) error {
+			log.Info("Too many blocks have elapsed since the last block was processed. Re-validating all orders stored (this can take a while)...")
+			return orderWatcher.Cleanup(ctx, 0*time.Minute)
+		}
+	}
+	if config.EthereumRPCNarrowLogFilters {
+		blockWatcherConfig.LogFilters = func() []blockwatch.LogFilter {
+			return orderWatcher.LogFilters()
+		}
 	}
 	blockWatcher := blockwatch.New(blockWatcherConfig)
 
 	// Initialize the order validator
 	orderValidator, err := ordervalidator.New(
-		ethClient,
+		validationEthClient,
 		config.EthereumChainID,
 		config.EthereumRPCMaxContentLength,
 		contractAddresses,
@@ -382,9 +1028,50 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	orderValidator.SetMaxOrdersPerBatch(config.EthereumRPCMaxOrdersPerBatch)
+	for _, assetValidator := range config.CustomAssetValidators {
+		orderValidator.RegisterAssetValidator(assetValidator)
+	}
+	exchange, err := wrappers.NewExchangeCaller(contractAddresses.Exchange, validationEthClient)
+	if err != nil {
+		return nil, err
+	}
+
+	feePolicy, err := parseFeePolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	sizePolicy, err := parseSizePolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	orderPolicy, err := loadOrderPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the order filter
+	orderFilter, err := orderfilter.New(config.EthereumChainID, config.CustomOrderFilter, contractAddresses)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom order filter: %s", err.Error())
+	}
+	if config.NetworkID != "" {
+		if err := orderfilter.ValidateNetworkID(config.NetworkID); err != nil {
+			return nil, fmt.Errorf("invalid MESH_NETWORK_ID: %s", err.Error())
+		}
+		orderFilter = orderFilter.WithNetworkID(config.NetworkID)
+	}
+	publishTopics, err := getPublishTopics(config.EthereumChainID, contractAddresses, orderFilter)
+	if err != nil {
+		return nil, err
+	}
+	additionalOrderFilters, err := parseAdditionalOrderFilters(config.AdditionalOrderFilters, config.EthereumChainID, contractAddresses, config.NetworkID, append(publishTopics, orderFilter.Topic()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADDITIONAL_ORDER_FILTERS: %s", err.Error())
+	}
 
 	// Initialize order watcher (but don't start it yet).
-	orderWatcher, err := orderwatch.New(orderwatch.Config{
+	orderWatcher, err = orderwatch.New(orderwatch.Config{
 		MeshDB:            meshDB,
 		BlockWatcher:      blockWatcher,
 		OrderValidator:    orderValidator,
@@ -392,22 +1079,21 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		ContractAddresses: contractAddresses,
 		MaxOrders:         config.MaxOrdersInStorage,
 		MaxExpirationTime: metadata.MaxExpirationTime,
+		FeePolicy:         feePolicy,
+		SizePolicy:        sizePolicy,
+		OrderPolicy:       orderPolicy,
+		TopicQuotas:       topicQuotaLimits(additionalOrderFilters),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Initialize the order filter
-	orderFilter, err := orderfilter.New(config.EthereumChainID, config.CustomOrderFilter, contractAddresses)
-	if err != nil {
-		return nil, fmt.Errorf("invalid custom order filter: %s", err.Error())
-	}
-
 	// Initialize remaining fields.
 	snapshotExpirationWatcher := expirationwatch.New()
 
 	app := &App{
 		started:                   make(chan struct{}),
+		startTime:                 time.Now(),
 		config:                    config,
 		privateConfig:             pConfig,
 		privKey:                   privKey,
@@ -417,27 +1103,80 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		orderWatcher:              orderWatcher,
 		orderValidator:            orderValidator,
 		orderFilter:               orderFilter,
+		additionalOrderFilters:    additionalOrderFilters,
 		snapshotExpirationWatcher: snapshotExpirationWatcher,
 		idToSnapshotInfo:          map[string]snapshotInfo{},
 		ethRPCRateLimiter:         ethRPCRateLimiter,
 		ethRPCClient:              ethClient,
+		ethRPCCache:               ethRPCCache,
+		validationEthRPCClient:    validationEthClient,
 		db:                        meshDB,
 		contractAddresses:         &contractAddresses,
+		exchange:                  exchange,
+		trustedOrderSubmitters:    trustedOrderSubmitters,
+		keyRotation:               keyRotation,
+		allowedSubnets:            allowedSubnets,
+		deniedSubnets:             deniedSubnets,
+		validationMemory:          newValidationMemory(config.MaxValidationMemoryBytes, validationMemoryPolicy),
+		orderEventsOverflowPolicy: orderEventsOverflowPolicy,
+		orderIngestion:            newOrderIngestion(),
+		orderRetryQueue:           newOrderRetryQueue(),
+	}
+	// The first publish topic is always the default topic.
+	app.peerFilterVerifications = newPeerFilterVerifications(publishTopics[0], app.VerifyPeerFilter)
+	if config.EnableFillabilityScores {
+		app.fillScorer = fillscore.New()
+	}
+	if config.EnableSoftCancels {
+		app.softCancels = softcancel.NewStore(maxSoftCancels)
 	}
 
 	log.WithFields(map[string]interface{}{
 		"config":  config,
 		"version": version,
 	}).Info("finished initializing core.App")
+	if chaos.Enabled {
+		log.Warn("Mesh was built with fault injection enabled; it should only be used for testing")
+	}
 
 	return app, nil
 }
 
+// gossipSubParams returns the GossipSub parameters set in the given config.
+func gossipSubParams(config Config) p2p.GossipSubParams {
+	return p2p.GossipSubParams{
+		D:                 config.GossipSubD,
+		DLow:              config.GossipSubDLow,
+		DHigh:             config.GossipSubDHigh,
+		HeartbeatInterval: config.GossipSubHeartbeatInterval,
+	}
+}
+
+// parseTrustedOrderSubmitters parses a comma-separated list of peer IDs.
+func parseTrustedOrderSubmitters(commaSeparatedPeerIDs string) ([]peer.ID, error) {
+	if commaSeparatedPeerIDs == "" {
+		return nil, nil
+	}
+	peerIDStrings := strings.Split(commaSeparatedPeerIDs, ",")
+	peerIDs := make([]peer.ID, len(peerIDStrings))
+	for i, peerIDString := range peerIDStrings {
+		peerID, err := peer.IDB58Decode(strings.TrimSpace(peerIDString))
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer ID in TRUSTED_ORDER_SUBMITTERS: %s", err.Error())
+		}
+		peerIDs[i] = peerID
+	}
+	return peerIDs, nil
+}
+
 // unquoteConfig removes quotes (if needed) from each string field in config.
 func unquoteConfig(config Config) Config {
 	if unquotedEthereumRPCURL, err := strconv.Unquote(config.EthereumRPCURL); err == nil {
 		config.EthereumRPCURL = unquotedEthereumRPCURL
 	}
+	if unquotedValidationRPCURL, err := strconv.Unquote(config.EthereumValidationRPCURL); err == nil {
+		config.EthereumValidationRPCURL = unquotedValidationRPCURL
+	}
 	if unquotedDataDir, err := strconv.Unquote(config.DataDir); err == nil {
 		config.DataDir = unquotedDataDir
 	}
@@ -445,7 +1184,7 @@ func unquoteConfig(config Config) Config {
 }
 
 func getPublishTopics(chainID int, contractAddresses ethereum.ContractAddresses, customFilter *orderfilter.Filter) ([]string, error) {
-	defaultTopic, err := orderfilter.GetDefaultTopic(chainID, contractAddresses)
+	defaultTopic, err := getDefaultTopic(chainID, contractAddresses, customFilter.NetworkID())
 	if err != nil {
 		return nil, err
 	}
@@ -464,23 +1203,79 @@ func getPublishTopics(chainID int, contractAddresses ethereum.ContractAddresses,
 	}
 }
 
+// getDefaultTopic returns the topic for the default order filter within the
+// private network with the given ID (or the main network if networkID is
+// empty).
+func getDefaultTopic(chainID int, contractAddresses ethereum.ContractAddresses, networkID string) (string, error) {
+	defaultFilter, err := orderfilter.GetDefaultFilter(chainID, contractAddresses)
+	if err != nil {
+		return "", err
+	}
+	return defaultFilter.WithNetworkID(networkID).Topic(), nil
+}
+
+// newEthClient returns the ethrpcclient.Client to use for all Ethereum RPC
+// requests based on the given config.
+func newEthClient(config Config, rateLimiter ratelimit.RateLimiter) (ethrpcclient.Client, error) {
+	if config.EthereumClient != nil {
+		if config.EthereumRPCURL != "" || config.EthereumRPCClient != nil {
+			log.Warn("Ignoring EthereumRPCURL and EthereumRPCClient and using the provided EthereumClient")
+		}
+		return config.EthereumClient, nil
+	}
+	var ethRPCClient ethclient.RPCClient
+	if config.EthereumRPCClient != nil {
+		if config.EthereumRPCURL != "" {
+			log.Warn("Ignoring EthereumRPCURL and using the provided EthereumRPCClient")
+		}
+		ethRPCClient = config.EthereumRPCClient
+	} else if config.EthereumRPCURL != "" {
+		var err error
+		ethRPCClient, err = rpc.Dial(config.EthereumRPCURL)
+		if err != nil {
+			log.WithError(err).Error("Could not dial EthereumRPCURL")
+			return nil, err
+		}
+	} else {
+		return nil, errors.New("cannot initialize core.App: neither EthereumRPCURL, EthereumRPCClient, or EthereumClient were provided")
+	}
+	return ethrpcclient.New(ethRPCClient, ethereumRPCRequestTimeout, rateLimiter)
+}
+
+func newValidationEthClient(config Config, rateLimiter ratelimit.RateLimiter) (ethrpcclient.Client, error) {
+	ethRPCClient, err := rpc.Dial(config.EthereumValidationRPCURL)
+	if err != nil {
+		log.WithError(err).Error("Could not dial EthereumValidationRPCURL")
+		return nil, err
+	}
+	return ethrpcclient.New(ethRPCClient, ethereumRPCRequestTimeout, rateLimiter)
+}
+
 func (app *App) getRendezvousPoints() ([]string, error) {
 	defaultRendezvousPoint := fmt.Sprintf("/0x-mesh/network/%d/version/2", app.config.EthereumChainID)
-	defaultTopic, err := orderfilter.GetDefaultTopic(app.chainID, *app.contractAddresses)
+	if app.config.NetworkID != "" {
+		defaultRendezvousPoint = fmt.Sprintf("/0x-mesh/private-network/%s/chain/%d/version/2", app.config.NetworkID, app.config.EthereumChainID)
+	}
+	defaultTopic, err := getDefaultTopic(app.chainID, *app.contractAddresses, app.config.NetworkID)
 	if err != nil {
 		return nil, err
 	}
 	customTopic := app.orderFilter.Topic()
-	if defaultTopic == customTopic {
-		// If we're just using the default order filter, we don't need to use multiple
-		// rendezvous points.
-		return []string{defaultRendezvousPoint}, nil
-	} else {
+	// If we're just using the default order filter, we don't need to use
+	// multiple rendezvous points.
+	rendezvousPoints := []string{defaultRendezvousPoint}
+	if defaultTopic != customTopic {
 		// If we are using a custom order filter, use *both* the default
 		// rendezvous point and a separate one specific to the filter. The
 		// filter-specific rendezvous point takes priority.
-		return []string{app.orderFilter.Rendezvous(), defaultRendezvousPoint}, nil
+		rendezvousPoints = []string{app.orderFilter.Rendezvous(), defaultRendezvousPoint}
 	}
+	// The rendezvous points for the additional order filters have the lowest
+	// priority.
+	for _, additionalFilter := range app.additionalOrderFilters {
+		rendezvousPoints = append(rendezvousPoints, additionalFilter.filter.Rendezvous())
+	}
+	return rendezvousPoints, nil
 }
 
 func initPrivateKey(path string) (p2pcrypto.PrivKey, error) {
@@ -530,6 +1325,13 @@ func (app *App) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Evict orders from topics which have more orders than their quota (e.g.
+	// because the quota was lowered since Mesh was last started). Afterwards,
+	// the quotas are enforced whenever messages are received.
+	if err := app.orderWatcher.TrimOrdersOverTopicQuotas(); err != nil {
+		return err
+	}
+
 	// Create a child context so that we can preemptively cancel if there is an
 	// error.
 	innerCtx, cancel := context.WithCancel(ctx)
@@ -540,6 +1342,24 @@ func (app *App) Start(ctx context.Context) error {
 	// to exit.
 	wg := &sync.WaitGroup{}
 
+	// Start recording messages and block events if needed. This must happen
+	// before the block watcher and p2p node are started so that no inputs are
+	// missed.
+	if app.config.ReplayRecordPath != "" {
+		if err := app.startRecording(innerCtx, wg); err != nil {
+			return err
+		}
+	}
+
+	// Start storing the block history if needed. Like recording, this must
+	// happen before the block watcher is started so that no blocks are missed.
+	if app.config.BlockHistoryRetention > 0 {
+		app.startBlockHistory(innerCtx, wg)
+	}
+	if app.ethRPCCache != nil {
+		app.startEthRPCCacheUpdates(innerCtx, wg)
+	}
+
 	// Close the database when the context is canceled.
 	wg.Add(1)
 	go func() {
@@ -606,7 +1426,7 @@ func (app *App) Start(ctx context.Context) error {
 			log.Debug("closing chainID checker")
 		}()
 
-		chainID, err := app.getEthRPCChainID(innerCtx)
+		chainID, err := getEthRPCChainID(innerCtx, app.ethRPCClient)
 		if err != nil {
 			chainIDMismatchErrChan <- err
 			return
@@ -615,6 +1435,18 @@ func (app *App) Start(ctx context.Context) error {
 		configChainID := app.config.EthereumChainID
 		if int64(configChainID) != chainID.Int64() {
 			chainIDMismatchErrChan <- fmt.Errorf("ChainID mismatch between RPC client (chainID: %d) and configured environment variable ETHEREUM_CHAIN_ID: %d", chainID, configChainID)
+			return
+		}
+
+		if app.config.EthereumValidationRPCURL != "" {
+			validationChainID, err := getEthRPCChainID(innerCtx, app.validationEthRPCClient)
+			if err != nil {
+				chainIDMismatchErrChan <- err
+				return
+			}
+			if int64(configChainID) != validationChainID.Int64() {
+				chainIDMismatchErrChan <- fmt.Errorf("ChainID mismatch between validation RPC client (chainID: %d) and configured environment variable ETHEREUM_CHAIN_ID: %d", validationChainID, configChainID)
+			}
 		}
 	}()
 
@@ -646,8 +1478,11 @@ func (app *App) Start(ctx context.Context) error {
 		}
 	}
 
-	if blocksElapsed >= constants.MaxBlocksStoredInNonArchiveNode {
-		log.WithField("blocksElapsed", blocksElapsed).Info("More than 128 blocks have elapsed since last boot. Re-validating all orders stored (this can take a while)...")
+	if blocksElapsed >= app.blockWatcher.MaxBlockHistory() {
+		log.WithFields(log.Fields{
+			"blocksElapsed":   blocksElapsed,
+			"maxBlockHistory": app.blockWatcher.MaxBlockHistory(),
+		}).Info("Too many blocks have elapsed since last boot. Re-validating all orders stored (this can take a while)...")
 		// Re-validate all orders since too many blocks have elapsed to fast-sync events
 		if err := app.orderWatcher.Cleanup(innerCtx, 0*time.Minute); err != nil {
 			return err
@@ -670,29 +1505,100 @@ func (app *App) Start(ctx context.Context) error {
 		return err
 	}
 	nodeConfig := p2p.Config{
-		SubscribeTopic:         app.orderFilter.Topic(),
-		PublishTopics:          publishTopics,
-		TCPPort:                app.config.P2PTCPPort,
-		WebSocketsPort:         app.config.P2PWebSocketsPort,
-		Insecure:               false,
-		PrivateKey:             app.privKey,
-		MessageHandler:         app,
-		RendezvousPoints:       rendezvousPoints,
-		UseBootstrapList:       app.config.UseBootstrapList,
-		BootstrapList:          bootstrapList,
-		DataDir:                filepath.Join(app.config.DataDir, "p2p"),
-		CustomMessageValidator: app.orderFilter.ValidatePubSubMessage,
+		SubscribeTopic:            app.orderFilter.Topic(),
+		PublishTopics:             publishTopics,
+		AdditionalSubscribeTopics: app.additionalSubscribeTopics(),
+		TCPPort:                   app.config.P2PTCPPort,
+		WebSocketsPort:            app.config.P2PWebSocketsPort,
+		Insecure:                  false,
+		PrivateKey:                app.privKey,
+		MessageHandler:            app,
+		RendezvousPoints:          rendezvousPoints,
+		UseBootstrapList:          app.config.UseBootstrapList,
+		BootstrapList:             bootstrapList,
+		DataDir:                   filepath.Join(app.config.DataDir, "p2p"),
+		CustomMessageValidator:    app.dropSoftCancelledMessages(app.dropTakerRestrictedMessages(app.detectMismatchedDomainMessages(app.orderFilter.ValidatePubSubMessage))),
+		InboundQueueSize:          app.config.InboundQueueSize,
+		// The overflow policy was already validated in newWithPrivateConfig.
+		InboundQueueOverflowPolicy: p2p.OverflowPolicy(app.config.InboundQueueOverflowPolicy),
+		UseNetworkManifest:         app.config.UseNetworkManifest,
+		ClientVersion:              version,
+		MinPeerProtocolVersion:     app.config.MinPeerProtocolVersion,
+		ReputationStore:            app.db,
+		DenyPrivateIPs:             app.config.P2PDenyPrivateIPs,
+		DeniedSubnets:              app.deniedSubnets,
+		AllowedSubnets:             app.allowedSubnets,
+		KeyRotation:                app.keyRotation,
+		RequireMessageSignatures:   app.config.RequireMessageSignatures,
+		GossipSubParams:            gossipSubParams(app.config),
+		ReservedPeerSlotsFraction:  app.config.P2PReservedPeerSlotsFraction,
+		IdlePeerTimeout:            app.config.P2PIdlePeerTimeout,
 	}
 	app.node, err = p2p.New(innerCtx, nodeConfig)
 	if err != nil {
 		return err
 	}
 
+	// Start moving orders between the hot and cold tiers if needed.
+	if coldOrderCriteria(app.config).Enabled() {
+		app.startOrderTiering(innerCtx, wg)
+	}
+
+	// Start compacting the database periodically if needed.
+	if app.config.DBCompactionInterval > 0 {
+		app.startDBCompaction(innerCtx, wg)
+	}
+
+	// Start materializing snapshots for ordersync if needed.
+	if app.config.OrderSyncSnapshotInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				log.Debug("closing ordersync snapshot loop")
+			}()
+			app.periodicallyMaterializeOrderSyncSnapshots(innerCtx)
+		}()
+	}
+
+	// Start retrying the validation of orders which were rejected for a
+	// retriable reason.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing order retry loop")
+		}()
+		app.retryRejectedOrders(innerCtx)
+	}()
+
+	// Start computing fillability scores if needed.
+	if app.fillScorer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				log.Debug("closing fillability scoring loop")
+			}()
+			app.updateFillScoresFromOrderEvents(innerCtx)
+		}()
+	}
+
+	// Register the order submission service for trusted peers if needed.
+	if len(app.trustedOrderSubmitters) > 0 {
+		_ = ordersubmission.New(innerCtx, app.node, app, app.trustedOrderSubmitters)
+	}
+
 	// Register and start ordersync service.
 	ordersyncSubprotocols := []ordersync.Subprotocol{
 		NewFilteredPaginationSubprotocol(app, app.privateConfig.paginationSubprotocolPerPage),
 	}
-	app.ordersyncService = ordersync.New(innerCtx, app.node, ordersyncSubprotocols)
+	app.ordersyncService = ordersync.New(innerCtx, app.node, ordersyncSubprotocols, ordersync.ProviderLimits{
+		MaxConcurrentSessions: app.config.OrderSyncMaxConcurrentSessions,
+		MaxQueuedSessions:     app.config.OrderSyncMaxQueuedSessions,
+		MaxBytesPerSecond:     app.config.OrderSyncMaxBytesPerSecond,
+	})
+
 	orderSyncErrChan := make(chan error, 1)
 	wg.Add(1)
 	go func() {
@@ -711,6 +1617,29 @@ func (app *App) Start(ctx context.Context) error {
 		}
 	}()
 
+	// Register the order checksum service and start comparing checksums with
+	// peers if needed.
+	checksumCache := &orderChecksumCache{app: app}
+	orderChecksumService := orderchecksum.New(innerCtx, app.node, checksumCache, app.resyncWithPeer)
+	if app.config.OrderChecksumInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				log.Debug("closing order checksum loop")
+			}()
+			orderChecksumService.PeriodicallyCheckPeers(innerCtx, app.orderChecksumTopics(), app.config.OrderChecksumInterval)
+		}()
+	}
+
+	// Register the stats attestation service, which serves signed stats to
+	// network crawlers.
+	_ = statsattestation.New(innerCtx, app.node, app.privKey, &statsAttestationSource{app: app, checksums: checksumCache})
+
+	// Register the filter attestation service, which lets peers verify which
+	// order filter each of our topics stands for.
+	_ = filterattestation.New(innerCtx, app.node, &filterAttestationSource{app: app})
+
 	// Start the p2p node.
 	p2pErrChan := make(chan error, 1)
 	wg.Add(1)
@@ -737,6 +1666,16 @@ func (app *App) Start(ctx context.Context) error {
 		p2pErrChan <- app.node.Start()
 	}()
 
+	// Start watching for Mesh waking up after being suspended.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing wake watcher")
+		}()
+		app.watchForWake(innerCtx)
+	}()
+
 	// Start loop for periodically logging stats.
 	wg.Add(1)
 	go func() {
@@ -750,6 +1689,8 @@ func (app *App) Start(ctx context.Context) error {
 	// Signal that the app has been started.
 	log.Info("core.App was started")
 	close(app.started)
+	// Ingestion may have been paused before the ordersync service existed.
+	app.syncOrdersyncPause()
 
 	// Wait for all other goroutines to close.
 	appClosed := make(chan struct{})
@@ -845,6 +1786,23 @@ func (e ErrSnapshotNotFound) Error() string {
 	return fmt.Sprintf("No snapshot found with id: %s. To create a new snapshot, send a request with an empty snapshotID", e.id)
 }
 
+// addSchemaValidationDurations adds the given schema validation durations to
+// the traces for the corresponding orders in results.
+func addSchemaValidationDurations(results *ordervalidator.ValidationResults, durations map[common.Hash]time.Duration) {
+	for _, acceptedOrderInfo := range results.Accepted {
+		if acceptedOrderInfo.Trace == nil {
+			acceptedOrderInfo.Trace = &ordervalidator.ValidationTrace{}
+		}
+		acceptedOrderInfo.Trace.SchemaValidationDuration = durations[acceptedOrderInfo.OrderHash]
+	}
+	for _, rejectedOrderInfo := range results.Rejected {
+		if rejectedOrderInfo.Trace == nil {
+			rejectedOrderInfo.Trace = &ordervalidator.ValidationTrace{}
+		}
+		rejectedOrderInfo.Trace.SchemaValidationDuration = durations[rejectedOrderInfo.OrderHash]
+	}
+}
+
 // ErrPerPageZero is the error returned when a GetOrders request specifies perPage to 0
 type ErrPerPageZero struct{}
 
@@ -852,19 +1810,74 @@ func (e ErrPerPageZero) Error() string {
 	return "perPage cannot be zero"
 }
 
+// ErrInvalidSort is the error returned when a GetOrders request specifies a
+// sort order which is not supported
+type ErrInvalidSort struct {
+	reason string
+}
+
+func (e ErrInvalidSort) Error() string {
+	return fmt.Sprintf("invalid sort: %s", e.reason)
+}
+
+// ErrInvalidNFTFilter is the error returned when a GetOrders request specifies
+// an invalid NFT filter
+type ErrInvalidNFTFilter struct {
+	reason string
+}
+
+func (e ErrInvalidNFTFilter) Error() string {
+	return fmt.Sprintf("invalid nft filter: %s", e.reason)
+}
+
+// ErrTooManyOrderHashes is the error returned when a RevalidateOrders request
+// contains more than maxRevalidateOrderHashes order hashes
+type ErrTooManyOrderHashes struct{}
+
+func (e ErrTooManyOrderHashes) Error() string {
+	return fmt.Sprintf("cannot revalidate more than %d orders at once", maxRevalidateOrderHashes)
+}
+
 // GetOrders retrieves paginated orders from the Mesh DB at a specific snapshot in time. Passing an empty
 // string as `snapshotID` creates a new snapshot and returns the first set of results. To fetch all orders,
 // continue to make requests supplying the `snapshotID` returned from the first request. After 1 minute of not
 // received further requests referencing a specific snapshot, the snapshot expires and can no longer be used.
-func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error) {
+// If opts.MaxStalenessSeconds is greater than 0, orders which were last validated longer ago are excluded.
+// If opts.TakerAddress is not nil, only orders with the given takerAddress are returned.
+// Cold orders (see Config.ColdOrderMinTimeToExpiry) are returned after all hot orders, regardless
+// of opts.Sort.
+func (app *App) GetOrders(page, perPage int, snapshotID string, opts types.GetOrdersOpts) (*types.GetOrdersResponse, error) {
 	<-app.started
 
 	if perPage <= 0 {
 		return nil, ErrPerPageZero{}
 	}
+	var sortIndex *db.Index
+	var reverse bool
+	if len(opts.Sort) > 0 {
+		var err error
+		sortIndex, reverse, err = app.findOrderSortIndex(opts.Sort)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var nftFilter *meshdb.NFTFilter
+	var nftAssetFilter *db.Filter
+	if opts.NFT != nil {
+		var err error
+		nftFilter, err = parseNFTFilter(opts.NFT)
+		if err != nil {
+			return nil, err
+		}
+		nftAssetFilter, err = app.db.NFTAssetFilter(*nftFilter)
+		if err != nil {
+			return nil, ErrInvalidNFTFilter{reason: err.Error()}
+		}
+	}
 
 	ordersInfos := []*types.OrderInfo{}
 	var snapshot *db.Snapshot
+	var coldSnapshot *db.Snapshot
 	var createdAt time.Time
 	if snapshotID == "" {
 		// Create a new snapshot
@@ -874,12 +1887,14 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 		if err != nil {
 			return nil, err
 		}
+		coldSnapshot = snapshot.WithCollection(app.db.ColdOrders.Collection)
 		createdAt = time.Now().UTC()
 		expirationTimestamp := time.Now().Add(1 * time.Minute)
 		app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
 		app.muIdToSnapshotInfo.Lock()
 		app.idToSnapshotInfo[snapshotID] = snapshotInfo{
 			Snapshot:            snapshot,
+			ColdSnapshot:        coldSnapshot,
 			CreatedAt:           createdAt,
 			ExpirationTimestamp: expirationTimestamp,
 		}
@@ -893,6 +1908,7 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 			return nil, ErrSnapshotNotFound{id: snapshotID}
 		}
 		snapshot = info.Snapshot
+		coldSnapshot = info.ColdSnapshot
 		createdAt = info.CreatedAt
 		// Reset the snapshot's expiry
 		app.snapshotExpirationWatcher.Remove(info.ExpirationTimestamp, snapshotID)
@@ -900,23 +1916,82 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 		app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
 		app.idToSnapshotInfo[snapshotID] = snapshotInfo{
 			Snapshot:            snapshot,
+			ColdSnapshot:        coldSnapshot,
 			CreatedAt:           createdAt,
 			ExpirationTimestamp: expirationTimestamp,
 		}
 		app.muIdToSnapshotInfo.Unlock()
 	}
 
-	notRemovedFilter := app.db.Orders.IsRemovedIndex.ValueFilter([]byte{0})
+	// Note: Only one index can be used by the query. The sort index is used if
+	// a sort order is given, then the nftAsset index if an NFT filter is given,
+	// then the takerAddress index. Removed orders and orders which don't match
+	// the other options are skipped below and still count towards perPage.
+	filter := app.db.Orders.IsRemovedIndex.ValueFilter([]byte{0})
+	checkNFTFilter := nftFilter != nil
+	checkTakerAddress := opts.TakerAddress != nil
+	switch {
+	case sortIndex != nil:
+		filter = sortIndex.All()
+	case nftAssetFilter != nil:
+		filter = nftAssetFilter
+		checkNFTFilter = false
+	case opts.TakerAddress != nil:
+		filter = app.db.Orders.TakerAddressIndex.ValueFilter([]byte(opts.TakerAddress.Hex()))
+		checkTakerAddress = false
+	}
+	query := snapshot.NewQuery(filter).Offset(page * perPage).Max(perPage)
+	if reverse {
+		query = query.Reverse()
+	}
 	var selectedOrders []*meshdb.Order
-	err := snapshot.NewQuery(notRemovedFilter).Offset(page * perPage).Max(perPage).Run(&selectedOrders)
-	if err != nil {
+	if err := query.Run(&selectedOrders); err != nil {
 		return nil, err
 	}
-	for _, order := range selectedOrders {
+	numHotOrders := len(selectedOrders)
+	if numHotOrders < perPage {
+		coldOrders, err := app.findColdOrdersPage(snapshot, coldSnapshot, filter, page, perPage, numHotOrders)
+		if err != nil {
+			return nil, err
+		}
+		selectedOrders = append(selectedOrders, coldOrders...)
+	}
+	now := time.Now()
+	maxStaleness := time.Duration(opts.MaxStalenessSeconds) * time.Second
+	for i, order := range selectedOrders {
+		if order.IsRemoved {
+			continue
+		}
+		// Cold orders are not selected by the index used for hot orders, so
+		// all of the options need to be checked for them.
+		isCold := i >= numHotOrders
+		if (checkTakerAddress || isCold && opts.TakerAddress != nil) && order.SignedOrder.TakerAddress != *opts.TakerAddress {
+			continue
+		}
+		if checkNFTFilter || isCold && nftFilter != nil {
+			matches, err := app.db.OrderMatchesNFTFilter(order, *nftFilter)
+			if err != nil {
+				return nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+		staleness := orderStaleness(order, now)
+		if maxStaleness > 0 && staleness > maxStaleness {
+			continue
+		}
 		ordersInfos = append(ordersInfos, &types.OrderInfo{
 			OrderHash:                order.Hash,
 			SignedOrder:              order.SignedOrder,
 			FillableTakerAssetAmount: order.FillableTakerAssetAmount,
+			LastValidatedBlockNumber: order.LastValidatedBlockNumber,
+			LastValidatedBlockHash:   order.LastValidatedBlockHash,
+			NextRevalidationTime:     app.orderWatcher.NextRevalidationTime(order),
+			FillabilityScore:         app.fillabilityScore(order),
+			Annotations:              order.Annotations,
+			Staleness:                staleness,
+			Signer:                   order.Signer,
 		})
 	}
 
@@ -929,13 +2004,223 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 	return getOrdersResponse, nil
 }
 
+// findColdOrdersPage returns the cold orders which fill up the given page of
+// mesh_getOrders results after the numHotOrders hot orders which matched
+// hotFilter. Cold orders are paged as if they came after all of the entries in
+// hotFilter.
+func (app *App) findColdOrdersPage(snapshot, coldSnapshot *db.Snapshot, hotFilter *db.Filter, page, perPage, numHotOrders int) ([]*meshdb.Order, error) {
+	numColdOrders, err := coldSnapshot.Count()
+	if err != nil {
+		return nil, err
+	}
+	if numColdOrders == 0 {
+		return nil, nil
+	}
+	numHotEntries, err := snapshot.NewQuery(hotFilter).Count()
+	if err != nil {
+		return nil, err
+	}
+	offset := page*perPage - numHotEntries
+	if offset < 0 {
+		offset = 0
+	}
+	var coldOrders []*meshdb.Order
+	query := coldSnapshot.NewQuery(app.db.ColdOrders.LastUpdatedIndex.All()).Offset(offset).Max(perPage - numHotOrders)
+	if err := query.Run(&coldOrders); err != nil {
+		return nil, err
+	}
+	return coldOrders, nil
+}
+
+// findOrderSortIndex returns the index to use for sorting orders by the given
+// fields and whether it needs to be iterated in reverse.
+func (app *App) findOrderSortIndex(sort []types.OrderSortField) (*db.Index, bool, error) {
+	fields := make([]meshdb.OrderSortField, len(sort))
+	for i, field := range sort {
+		fields[i].Field = field.Field
+		switch field.Direction {
+		case "", "asc":
+		case "desc":
+			fields[i].Descending = true
+		default:
+			return nil, false, ErrInvalidSort{reason: fmt.Sprintf("unsupported direction %q (must be \"asc\" or \"desc\")", field.Direction)}
+		}
+	}
+	index, reverse, err := app.db.FindOrderSortIndex(fields)
+	if err != nil {
+		return nil, false, ErrInvalidSort{reason: err.Error()}
+	}
+	return index, reverse, nil
+}
+
+// parseNFTFilter converts the NFT filter of a GetOrders request into a
+// meshdb.NFTFilter.
+func parseNFTFilter(filter *types.NFTFilter) (*meshdb.NFTFilter, error) {
+	nftFilter := &meshdb.NFTFilter{
+		Side:            filter.Side,
+		ContractAddress: filter.ContractAddress,
+	}
+	if nftFilter.Side == "" {
+		nftFilter.Side = meshdb.NFTSideAny
+	}
+	if filter.TokenID != "" {
+		tokenID, ok := math.ParseBig256(filter.TokenID)
+		if !ok {
+			return nil, ErrInvalidNFTFilter{reason: fmt.Sprintf("invalid token ID: %q", filter.TokenID)}
+		}
+		nftFilter.TokenID = tokenID
+	}
+	return nftFilter, nil
+}
+
+// QueryOrders runs a read-only query against one of the indexes of the orders
+// stored in the database. It is an escape hatch for advanced use cases which
+// are not covered by GetOrders. See meshdb.OrderQuery for the supported
+// indexes and the format of their values. Unlike GetOrders, QueryOrders does
+// not use a snapshot and doesn't return cold orders.
+func (app *App) QueryOrders(query *meshdb.OrderQuery) ([]*types.OrderInfo, error) {
+	<-app.started
+
+	orders, err := app.db.QueryOrders(query)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ordersInfos := make([]*types.OrderInfo, len(orders))
+	for i, order := range orders {
+		ordersInfos[i] = &types.OrderInfo{
+			OrderHash:                order.Hash,
+			SignedOrder:              order.SignedOrder,
+			FillableTakerAssetAmount: order.FillableTakerAssetAmount,
+			LastValidatedBlockNumber: order.LastValidatedBlockNumber,
+			LastValidatedBlockHash:   order.LastValidatedBlockHash,
+			NextRevalidationTime:     app.orderWatcher.NextRevalidationTime(order),
+			FillabilityScore:         app.fillabilityScore(order),
+			Annotations:              order.Annotations,
+			Staleness:                orderStaleness(order, now),
+			Signer:                   order.Signer,
+		}
+	}
+	return ordersInfos, nil
+}
+
+// orderStaleness returns how long ago the order was last validated.
+func orderStaleness(order *meshdb.Order, now time.Time) time.Duration {
+	lastValidated := order.LastValidated
+	if lastValidated.IsZero() {
+		// The order was stored before LastValidated was introduced. LastUpdated is
+		// the best approximation we have.
+		lastValidated = order.LastUpdated
+	}
+	staleness := now.Sub(lastValidated)
+	if staleness < 0 {
+		return 0
+	}
+	return staleness
+}
+
+// GetMakers returns aggregate information about the open orders of each maker.
+// It is intended for monitoring the exposure of individual makers.
+func (app *App) GetMakers(opts types.GetMakersOpts) ([]*types.MakerInfo, error) {
+	<-app.started
+
+	aggregates, err := app.db.FindMakerAggregates(opts.MakerAddresses)
+	if err != nil {
+		return nil, err
+	}
+	makerInfos := make([]*types.MakerInfo, len(aggregates))
+	for i, aggregate := range aggregates {
+		makerAssetAmounts := make([]*types.MakerAssetAmount, 0, len(aggregate.MakerAssetAmounts))
+		for assetData, amount := range aggregate.MakerAssetAmounts {
+			makerAssetAmounts = append(makerAssetAmounts, &types.MakerAssetAmount{
+				AssetData: common.FromHex(assetData),
+				Amount:    amount,
+			})
+		}
+		sort.Slice(makerAssetAmounts, func(i, j int) bool {
+			return bytes.Compare(makerAssetAmounts[i].AssetData, makerAssetAmounts[j].AssetData) == -1
+		})
+		makerInfos[i] = &types.MakerInfo{
+			MakerAddress:      aggregate.MakerAddress,
+			NumOrders:         aggregate.NumOrders,
+			MakerAssetAmounts: makerAssetAmounts,
+			LastActivity:      aggregate.LastActivity,
+		}
+	}
+	return makerInfos, nil
+}
+
+// GetMakerEpoch returns the order epoch of the given maker and sender address.
+// All orders of the maker with that sender address and a salt lower than the
+// epoch are cancelled. The epoch is taken from the latest CancelUpTo event
+// processed by Mesh if there is one and is read from the Exchange contract
+// otherwise. Stored orders affected by a CancelUpTo event are re-validated
+// automatically.
+func (app *App) GetMakerEpoch(ctx context.Context, makerAddress, senderAddress common.Address) (*types.MakerEpoch, error) {
+	<-app.started
+
+	orderEpoch, found := app.orderWatcher.MakerEpoch(makerAddress, senderAddress)
+	if !found {
+		var err error
+		orderEpoch, err = app.exchange.OrderEpoch(&bind.CallOpts{Context: ctx}, makerAddress, senderAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &types.MakerEpoch{
+		MakerAddress:  makerAddress,
+		SenderAddress: senderAddress,
+		OrderEpoch:    orderEpoch,
+	}, nil
+}
+
+// GetMarkets returns aggregate information about the open orders for each
+// trading pair, such as the best bid and ask and the total open size. It is
+// intended for showing an overview of every market without fetching the
+// orders themselves.
+func (app *App) GetMarkets() ([]*types.MarketInfo, error) {
+	<-app.started
+
+	aggregates, err := app.db.FindMarketAggregates()
+	if err != nil {
+		return nil, err
+	}
+	marketInfos := make([]*types.MarketInfo, len(aggregates))
+	for i, aggregate := range aggregates {
+		marketInfo := &types.MarketInfo{
+			BaseAssetData:  aggregate.BaseAssetData,
+			QuoteAssetData: aggregate.QuoteAssetData,
+			NumOrders:      aggregate.NumBids + aggregate.NumAsks,
+			NumBids:        aggregate.NumBids,
+			NumAsks:        aggregate.NumAsks,
+			BestBid:        aggregate.BestBid,
+			BestAsk:        aggregate.BestAsk,
+			BidSize:        aggregate.BidSize,
+			AskSize:        aggregate.AskSize,
+		}
+		if aggregate.BestBid != nil && aggregate.BestAsk != nil {
+			marketInfo.Spread = new(big.Rat).Sub(aggregate.BestAsk, aggregate.BestBid)
+			marketInfo.MidPrice = new(big.Rat).Add(aggregate.BestAsk, aggregate.BestBid)
+			marketInfo.MidPrice.Quo(marketInfo.MidPrice, big.NewRat(2, 1))
+		}
+		marketInfos[i] = marketInfo
+	}
+	return marketInfos, nil
+}
+
 // AddOrders can be used to add orders to Mesh. It validates the given orders
 // and if they are valid, will store and eventually broadcast the orders to
 // peers. If pinned is true, the orders will be marked as pinned, which means
 // they will only be removed if they become unfillable and will not be removed
-// due to having a high expiration time or any incentive mechanisms.
+// due to having a high expiration time or any incentive mechanisms. If ctx was
+// created with ordervalidator.WithTracing, a ValidationTrace is attached to
+// each result.
 func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessage, pinned bool) (*ordervalidator.ValidationResults, error) {
-	<-app.started
+	select {
+	case <-app.started:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 
 	allValidationResults := &ordervalidator.ValidationResults{
 		Accepted: []*ordervalidator.AcceptedOrderInfo{},
@@ -943,9 +2228,59 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 	}
 	orderHashesSeen := map[common.Hash]struct{}{}
 	schemaValidOrders := []*zeroex.SignedOrder{}
+	// If tracing is enabled, we keep track of how long schema validation took
+	// for each order.
+	isTracingEnabled := ordervalidator.IsTracingEnabled(ctx)
+	schemaValidationDurations := map[common.Hash]time.Duration{}
+	ctx = app.withStageTimeouts(ctx)
+	schemaValidationCtx, cancelSchemaValidation := ordervalidator.WithStageTimeout(ctx, ordervalidator.StageTimeoutsFromContext(ctx).SchemaValidation)
+	defer cancelSchemaValidation()
 	for _, signedOrderRaw := range signedOrdersRaw {
-		signedOrderBytes := []byte(*signedOrderRaw)
+		if schemaValidationCtx.Err() != nil {
+			// Orders which were not schema validated before the deadline are
+			// rejected without being validated.
+			rejectedOrderInfo := &ordervalidator.RejectedOrderInfo{
+				Kind:   ordervalidator.MeshError,
+				Status: ordervalidator.ROValidationTimedOut,
+			}
+			signedOrder := &zeroex.SignedOrder{}
+			if err := signedOrder.UnmarshalJSON([]byte(*signedOrderRaw)); err == nil {
+				rejectedOrderInfo.SignedOrder = signedOrder
+				if orderHash, err := signedOrder.ComputeOrderHash(); err == nil {
+					rejectedOrderInfo.OrderHash = orderHash
+				}
+			}
+			allValidationResults.Rejected = append(allValidationResults.Rejected, rejectedOrderInfo)
+			continue
+		}
+		// Many client libraries emit EIP-55 checksummed addresses. Convert them
+		// to lowercase so that they match the order filter.
+		signedOrderBytes, err := zeroex.NormalizeSignedOrderJSON([]byte(*signedOrderRaw))
+		if err != nil {
+			log.WithError(err).WithField("signedOrderRaw", string(*signedOrderRaw)).Info("Order has an invalid address checksum")
+			allValidationResults.Rejected = append(allValidationResults.Rejected, &ordervalidator.RejectedOrderInfo{
+				Kind: ordervalidator.MeshValidation,
+				Status: ordervalidator.RejectedOrderStatus{
+					Code:    ordervalidator.ROInvalidSchemaCode,
+					Message: fmt.Sprintf("order did not pass JSON-schema validation: %s", err),
+				},
+			})
+			continue
+		}
+		// Orders for a different chain or exchange would fail schema validation
+		// anyway, but are rejected with a specific status so that it's clear
+		// what is wrong with them.
+		var domain orderDomain
+		if err := json.Unmarshal(signedOrderBytes, &domain); err == nil {
+			if status, mismatched := app.checkOrderDomain(domain); mismatched {
+				app.recordMismatchedDomainOrder(domain, status, "AddOrders")
+				allValidationResults.Rejected = append(allValidationResults.Rejected, newMismatchedDomainRejection(signedOrderBytes, status))
+				continue
+			}
+		}
+		schemaValidationStart := time.Now()
 		result, err := app.orderFilter.ValidateOrderJSON(signedOrderBytes)
+		schemaValidationDuration := time.Since(schemaValidationStart)
 		if err != nil {
 			signedOrder := &zeroex.SignedOrder{}
 			if err := signedOrder.UnmarshalJSON(signedOrderBytes); err != nil {
@@ -972,11 +2307,17 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 			if err := signedOrder.UnmarshalJSON(signedOrderBytes); err != nil {
 				signedOrder = nil
 			}
-			allValidationResults.Rejected = append(allValidationResults.Rejected, &ordervalidator.RejectedOrderInfo{
+			rejectedOrderInfo := &ordervalidator.RejectedOrderInfo{
 				SignedOrder: signedOrder,
 				Kind:        ordervalidator.MeshValidation,
 				Status:      status,
-			})
+			}
+			if isTracingEnabled {
+				rejectedOrderInfo.Trace = &ordervalidator.ValidationTrace{
+					SchemaValidationDuration: schemaValidationDuration,
+				}
+			}
+			allValidationResults.Rejected = append(allValidationResults.Rejected, rejectedOrderInfo)
 			continue
 		}
 
@@ -997,12 +2338,18 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 
 		schemaValidOrders = append(schemaValidOrders, signedOrder)
 		orderHashesSeen[orderHash] = struct{}{}
+		if isTracingEnabled {
+			schemaValidationDurations[orderHash] = schemaValidationDuration
+		}
 	}
 
-	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrders(ctx, schemaValidOrders, pinned, app.chainID)
+	validationResults, err := app.validateAndStoreOrders(ctx, schemaValidOrders, pinned)
 	if err != nil {
 		return nil, err
 	}
+	if isTracingEnabled {
+		addSchemaValidationDurations(validationResults, schemaValidationDurations)
+	}
 
 	for _, orderInfo := range validationResults.Accepted {
 		allValidationResults.Accepted = append(allValidationResults.Accepted, orderInfo)
@@ -1022,6 +2369,10 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 			"orderHash": acceptedOrderInfo.OrderHash.String(),
 		}).Debug("added new valid order via RPC or browser callback")
 
+		if app.config.DropTakerRestrictedOrders && isTakerRestricted(acceptedOrderInfo.SignedOrder) {
+			continue
+		}
+
 		// Share the order with our peers.
 		if err := app.shareOrder(acceptedOrderInfo.SignedOrder); err != nil {
 			return nil, err
@@ -1035,11 +2386,33 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 func (app *App) shareOrder(order *zeroex.SignedOrder) error {
 	<-app.started
 
+	if app.isSoftCancelled(order) {
+		return nil
+	}
+
 	encoded, err := encoding.OrderToRawMessage(app.orderFilter.Topic(), order)
 	if err != nil {
 		return err
 	}
-	return app.node.Send(encoded)
+	if err := app.node.Send(encoded); err != nil {
+		return err
+	}
+	return app.shareOrderOnAdditionalTopics(order)
+}
+
+// RevalidateOrders immediately re-validates the stored orders with the given
+// hashes instead of waiting for the next scheduled re-validation. This is
+// useful when e.g. a maker has increased their allowance and wants the new
+// fillable amounts to be reflected right away. Order events are emitted for
+// any orders whose state has changed. Orders which are not stored are
+// rejected with ordervalidator.ROOrderNotStored.
+func (app *App) RevalidateOrders(ctx context.Context, orderHashes []common.Hash) (*ordervalidator.ValidationResults, error) {
+	<-app.started
+
+	if len(orderHashes) > maxRevalidateOrderHashes {
+		return nil, ErrTooManyOrderHashes{}
+	}
+	return app.orderWatcher.RevalidateOrders(ctx, orderHashes)
 }
 
 // AddPeer can be used to manually connect to a new peer.
@@ -1062,11 +2435,17 @@ func (app *App) GetStats() (*types.Stats, error) {
 		Hash:   latestBlockHeader.Hash,
 	}
 	notRemovedFilter := app.db.Orders.IsRemovedIndex.ValueFilter([]byte{0})
-	numOrders, err := app.db.Orders.NewQuery(notRemovedFilter).Count()
+	numHotOrders, err := app.db.Orders.NewQuery(notRemovedFilter).Count()
 	if err != nil {
 		return nil, err
 	}
-	numOrdersIncludingRemoved, err := app.db.Orders.Count()
+	// Cold orders are never flagged for removal.
+	numColdOrders, err := app.db.CountColdOrders()
+	if err != nil {
+		return nil, err
+	}
+	numOrders := numHotOrders + numColdOrders
+	numOrdersIncludingRemoved, err := app.db.CountStoredOrders()
 	if err != nil {
 		return nil, err
 	}
@@ -1082,23 +2461,92 @@ func (app *App) GetStats() (*types.Stats, error) {
 	if err != nil {
 		return nil, err
 	}
+	storageUsedBytes, err := app.db.ApproximateSize()
+	if err != nil {
+		return nil, err
+	}
+	dbFragmentationPercent, dbReclaimedBytes := app.dbCompactionStats.get()
+	// Removed orders count towards the storage limit until they are
+	// permanently deleted.
+	storageUtilizationPercent := 0.0
+	if app.config.MaxOrdersInStorage > 0 {
+		storageUtilizationPercent = 100 * float64(numOrdersIncludingRemoved) / float64(app.config.MaxOrdersInStorage)
+	}
+
+	inboundQueueStats := app.node.InboundQueueStats()
+	dialStats := app.node.DialStats()
+	topicStats, err := app.getTopicStats()
+	if err != nil {
+		return nil, err
+	}
+
+	validationMemoryStats := app.validationMemory.stats()
+
+	var ethRPCCacheStats ethrpcclient.CacheStats
+	if app.ethRPCCache != nil {
+		ethRPCCacheStats = app.ethRPCCache.Stats()
+	}
+	ethRPCRateLimitExpiredRequests := app.ethRPCClient.GetRateLimitDroppedRequests()
+	if app.config.EthereumValidationRPCURL != "" {
+		ethRPCRateLimitExpiredRequests += app.validationEthRPCClient.GetRateLimitDroppedRequests()
+	}
+
+	maxEthRPCRequests := 0
+	if app.config.EnableEthereumRPCRateLimiting {
+		maxEthRPCRequests = app.config.EthereumRPCMaxRequestsPer24HrUTC
+	}
+	ethRPCBudget := computeEthRPCBudget(maxEthRPCRequests, metadata.EthRPCRequestsSentInCurrentUTCDay, metadata.StartOfCurrentUTCDay, time.Now())
 
 	response := &types.Stats{
-		Version:                           version,
-		PubSubTopic:                       app.orderFilter.Topic(),
-		Rendezvous:                        rendezvousPoints[0],
-		SecondaryRendezvous:               rendezvousPoints[1:],
-		PeerID:                            app.peerID.String(),
-		EthereumChainID:                   app.config.EthereumChainID,
-		LatestBlock:                       latestBlock,
-		NumOrders:                         numOrders,
-		NumPeers:                          app.node.GetNumPeers(),
-		NumOrdersIncludingRemoved:         numOrdersIncludingRemoved,
-		NumPinnedOrders:                   numPinnedOrders,
-		MaxExpirationTime:                 app.orderWatcher.MaxExpirationTime().String(),
-		StartOfCurrentUTCDay:              metadata.StartOfCurrentUTCDay,
-		EthRPCRequestsSentInCurrentUTCDay: metadata.EthRPCRequestsSentInCurrentUTCDay,
-		EthRPCRateLimitExpiredRequests:    app.ethRPCClient.GetRateLimitDroppedRequests(),
+		Version:                                version,
+		PubSubTopic:                            app.orderFilter.Topic(),
+		Rendezvous:                             rendezvousPoints[0],
+		SecondaryRendezvous:                    rendezvousPoints[1:],
+		PeerID:                                 app.peerID.String(),
+		EthereumChainID:                        app.config.EthereumChainID,
+		LatestBlock:                            latestBlock,
+		NumOrders:                              numOrders,
+		NumPeers:                               app.node.GetNumPeers(),
+		NumOrdersIncludingRemoved:              numOrdersIncludingRemoved,
+		NumPinnedOrders:                        numPinnedOrders,
+		NumColdOrders:                          numColdOrders,
+		MaxExpirationTime:                      app.orderWatcher.MaxExpirationTime().String(),
+		StartOfCurrentUTCDay:                   metadata.StartOfCurrentUTCDay,
+		EthRPCRequestsSentInCurrentUTCDay:      metadata.EthRPCRequestsSentInCurrentUTCDay,
+		EthRPCRateLimitExpiredRequests:         ethRPCRateLimitExpiredRequests,
+		EthRPCMaxRequestsPer24HrUTC:            ethRPCBudget.max,
+		EthRPCRemainingRequestsInCurrentUTCDay: ethRPCBudget.remaining,
+		EthRPCBudgetExhaustionETASeconds:       int64(ethRPCBudget.exhaustionETA.Seconds()),
+		EthRPCCacheHits:                        ethRPCCacheStats.Hits,
+		EthRPCCacheMisses:                      ethRPCCacheStats.Misses,
+		EthRPCCacheEntries:                     ethRPCCacheStats.Entries,
+		StorageUsedBytes:                       storageUsedBytes,
+		MaxOrders:                              app.config.MaxOrdersInStorage,
+		CurrentOrders:                          numOrdersIncludingRemoved,
+		EvictedOrdersLast24h:                   app.orderWatcher.EvictedOrdersLast24h(),
+		StorageUtilizationPercent:              storageUtilizationPercent,
+		InboundQueueLength:                     inboundQueueStats.Length,
+		InboundQueueDroppedMessages:            inboundQueueStats.Dropped,
+		ValidationMemoryBytes:                  validationMemoryStats.Bytes,
+		ValidationMemoryShedOrders:             validationMemoryStats.ShedOrders,
+		DedupedOrderSubmissions:                app.orderIngestion.dedupedSubmissions(),
+		MismatchedDomainOrders:                 atomic.LoadUint64(&app.mismatchedDomainOrders),
+		DroppedOrderEvents:                     atomic.LoadUint64(&app.droppedOrderEvents),
+		IngestionPaused:                        app.isIngestionPaused(),
+		OrderSyncBytesSaved:                    app.ordersyncService.BytesSavedByCompression(),
+		SlowDBQueries:                          app.db.SlowQueryCount(),
+		DBFragmentationPercent:                 dbFragmentationPercent,
+		DBReclaimedBytes:                       dbReclaimedBytes,
+		BootstrapDialFailures:                  dialStats.Failures,
+		UnhealthyBootstrapPeers:                dialStats.UnhealthyPeers,
+		StartTime:                              app.startTime,
+		UptimeSeconds:                          int64(time.Since(app.startTime).Seconds()),
+		GitCommit:                              gitCommit,
+		BuildDate:                              buildDate,
+		GoVersion:                              runtime.Version(),
+		EnabledFeatures:                        enabledFeatures(app.config),
+		ConfigHash:                             configHash(app.config),
+		Topics:                                 topicStats,
 	}
 	return response, nil
 }
@@ -1114,6 +2562,9 @@ func (app *App) periodicallyLogStats(ctx context.Context) {
 			return
 		case <-ticker.C:
 		}
+		if app.isHidden() {
+			continue
+		}
 
 		stats, err := app.GetStats()
 		if err != nil {
@@ -1121,28 +2572,115 @@ func (app *App) periodicallyLogStats(ctx context.Context) {
 			continue
 		}
 		log.WithFields(log.Fields{
-			"version":                           stats.Version,
-			"pubSubTopic":                       stats.PubSubTopic,
-			"rendezvous":                        stats.Rendezvous,
-			"ethereumChainID":                   stats.EthereumChainID,
-			"latestBlock":                       stats.LatestBlock,
-			"numOrders":                         stats.NumOrders,
-			"numOrdersIncludingRemoved":         stats.NumOrdersIncludingRemoved,
-			"numPinnedOrders":                   stats.NumPinnedOrders,
-			"numPeers":                          stats.NumPeers,
-			"maxExpirationTime":                 stats.MaxExpirationTime,
-			"startOfCurrentUTCDay":              stats.StartOfCurrentUTCDay,
-			"ethRPCRequestsSentInCurrentUTCDay": stats.EthRPCRequestsSentInCurrentUTCDay,
-			"ethRPCRateLimitExpiredRequests":    stats.EthRPCRateLimitExpiredRequests,
+			"version":                                stats.Version,
+			"pubSubTopic":                            stats.PubSubTopic,
+			"rendezvous":                             stats.Rendezvous,
+			"ethereumChainID":                        stats.EthereumChainID,
+			"latestBlock":                            stats.LatestBlock,
+			"numOrders":                              stats.NumOrders,
+			"numOrdersIncludingRemoved":              stats.NumOrdersIncludingRemoved,
+			"numPinnedOrders":                        stats.NumPinnedOrders,
+			"numColdOrders":                          stats.NumColdOrders,
+			"numPeers":                               stats.NumPeers,
+			"maxExpirationTime":                      stats.MaxExpirationTime,
+			"startOfCurrentUTCDay":                   stats.StartOfCurrentUTCDay,
+			"ethRPCRequestsSentInCurrentUTCDay":      stats.EthRPCRequestsSentInCurrentUTCDay,
+			"ethRPCRateLimitExpiredRequests":         stats.EthRPCRateLimitExpiredRequests,
+			"ethRPCRemainingRequestsInCurrentUTCDay": stats.EthRPCRemainingRequestsInCurrentUTCDay,
+			"ethRPCBudgetExhaustionETASeconds":       stats.EthRPCBudgetExhaustionETASeconds,
+			"storageUsedBytes":                       stats.StorageUsedBytes,
+			"maxOrders":                              stats.MaxOrders,
+			"currentOrders":                          stats.CurrentOrders,
+			"evictedOrdersLast24h":                   stats.EvictedOrdersLast24h,
+			"storageUtilizationPercent":              stats.StorageUtilizationPercent,
+			"inboundQueueLength":                     stats.InboundQueueLength,
+			"inboundQueueDroppedMessages":            stats.InboundQueueDroppedMessages,
+			"validationMemoryBytes":                  stats.ValidationMemoryBytes,
+			"validationMemoryShedOrders":             stats.ValidationMemoryShedOrders,
+			"dedupedOrderSubmissions":                stats.DedupedOrderSubmissions,
+			"mismatchedDomainOrders":                 stats.MismatchedDomainOrders,
+			"droppedOrderEvents":                     stats.DroppedOrderEvents,
+			"ingestionPaused":                        stats.IngestionPaused,
+			"orderSyncBytesSaved":                    stats.OrderSyncBytesSaved,
+			"slowDBQueries":                          stats.SlowDBQueries,
+			"dbFragmentationPercent":                 stats.DBFragmentationPercent,
+			"dbReclaimedBytes":                       stats.DBReclaimedBytes,
+			"bootstrapDialFailures":                  stats.BootstrapDialFailures,
+			"unhealthyBootstrapPeers":                stats.UnhealthyBootstrapPeers,
+			"uptimeSeconds":                          stats.UptimeSeconds,
+			"gitCommit":                              stats.GitCommit,
+			"configHash":                             stats.ConfigHash,
 		}).Info("current stats")
 	}
 }
 
-// SubscribeToOrderEvents let's one subscribe to order events emitted by the OrderWatcher
+// updateFillScoresFromOrderEvents feeds all order events to app.fillScorer
+// until the context is canceled.
+func (app *App) updateFillScoresFromOrderEvents(ctx context.Context) {
+	orderEventsChan := make(chan []*zeroex.OrderEvent)
+	subscription := app.SubscribeToOrderEvents(orderEventsChan)
+	defer subscription.Unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-subscription.Err():
+			if err != nil {
+				log.WithError(err).Error("fillability scoring order event subscription error")
+			}
+			return
+		case orderEvents := <-orderEventsChan:
+			app.fillScorer.HandleOrderEvents(orderEvents)
+		}
+	}
+}
+
+// fillabilityScore returns the fillability score for the given order or nil if
+// fillability scores are disabled.
+func (app *App) fillabilityScore(order *meshdb.Order) *float64 {
+	if app.fillScorer == nil {
+		return nil
+	}
+	score := app.fillScorer.Score(order.Hash, order.SignedOrder)
+	return &score
+}
+
+// WriteDBCheckpoint writes a consistent copy of the database to w, e.g. in
+// order to back it up. It is safe to call while the app is running. The
+// checkpoint can be restored with db.RestoreCheckpoint before the app is
+// started.
+func (app *App) WriteDBCheckpoint(w io.Writer) error {
+	return app.db.WriteCheckpoint(w)
+}
+
+// SubscribeToOrderEvents let's one subscribe to order events emitted by the OrderWatcher.
+// Up to config.OrderEventsBufferSize batches of order events are buffered for
+// sink in addition to its capacity. If the buffer is full, order events are
+// handled according to config.OrderEventsOverflowPolicy.
 func (app *App) SubscribeToOrderEvents(sink chan<- []*zeroex.OrderEvent) event.Subscription {
 	// app.orderWatcher is guaranteed to be initialized. No need to wait.
-	subscription := app.orderWatcher.Subscribe(sink)
-	return subscription
+	return subscribeBufferedOrderEvents(app.orderWatcher, sink, app.config.OrderEventsBufferSize, app.orderEventsOverflowPolicy, &app.droppedOrderEvents)
+}
+
+// GetOrderEventsSince returns the recent order events with a sequence number
+// greater than sequenceNumber, which must belong to the given epoch. It returns
+// orderwatch.ErrOrderEventsEpochMismatch if Mesh was restarted since the epoch
+// and orderwatch.ErrOrderEventsUnavailable if some of the events are no longer
+// retained. In both cases the caller should resync with GetOrders.
+func (app *App) GetOrderEventsSince(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error) {
+	<-app.started
+
+	return app.orderWatcher.OrderEventsSince(epoch, sequenceNumber)
+}
+
+// GetOrdersAffectedByTransaction returns the recent order events which were
+// caused by the transaction with the given hash, along with the contract events
+// that caused them. Only the most recent order events are retained, so the
+// result is empty if the transaction is too old or didn't affect any orders.
+func (app *App) GetOrdersAffectedByTransaction(txHash common.Hash) ([]*zeroex.OrderEvent, error) {
+	<-app.started
+
+	return app.orderWatcher.OrderEventsByTransaction(txHash), nil
 }
 
 // IsCaughtUpToLatestBlock returns whether or not the latest block stored by Mesh corresponds
@@ -1168,6 +2706,19 @@ func (app *App) IsCaughtUpToLatestBlock(ctx context.Context) bool {
 	return latestBlock.Number.Cmp(latestBlockStored.Number) == 0
 }
 
+// IsReady returns whether or not Mesh has finished starting up and is ready to
+// serve requests, i.e. the block watcher has caught up to the latest block and
+// the first round of ordersync has completed. Until then, Mesh may be missing
+// most of the orders on the network.
+func (app *App) IsReady() bool {
+	select {
+	case <-app.started:
+	default:
+		return false
+	}
+	return app.ordersyncService.HasCompletedFirstRound()
+}
+
 func parseAndValidateCustomContractAddresses(chainID int, encodedContractAddresses string) (ethereum.ContractAddresses, error) {
 	customAddresses := ethereum.ContractAddresses{}
 	if err := json.Unmarshal([]byte(encodedContractAddresses), &customAddresses); err != nil {
@@ -1178,3 +2729,91 @@ func parseAndValidateCustomContractAddresses(chainID int, encodedContractAddress
 	}
 	return customAddresses, nil
 }
+
+// parseFeePolicy returns the fee policy specified by the given config or nil
+// if no fee policy options are set.
+func parseFeePolicy(config Config) (*orderwatch.FeePolicy, error) {
+	if config.FeeRecipientAllowlist == "" && config.MaxMakerFee == "" && config.MaxTakerFee == "" {
+		return nil, nil
+	}
+	feePolicy := &orderwatch.FeePolicy{
+		AllowedFeeRecipients: map[common.Address]struct{}{},
+	}
+	for _, feeRecipient := range strings.Split(config.FeeRecipientAllowlist, ",") {
+		feeRecipient = strings.TrimSpace(feeRecipient)
+		if feeRecipient == "" {
+			continue
+		}
+		if !common.IsHexAddress(feeRecipient) {
+			return nil, fmt.Errorf("config.FeeRecipientAllowlist is invalid: %q is not an address", feeRecipient)
+		}
+		feePolicy.AllowedFeeRecipients[common.HexToAddress(feeRecipient)] = struct{}{}
+	}
+	if config.MaxMakerFee != "" {
+		maxMakerFee, ok := new(big.Int).SetString(config.MaxMakerFee, 10)
+		if !ok || maxMakerFee.Sign() == -1 {
+			return nil, fmt.Errorf("config.MaxMakerFee is invalid: %q is not a non-negative integer", config.MaxMakerFee)
+		}
+		feePolicy.MaxMakerFee = maxMakerFee
+	}
+	if config.MaxTakerFee != "" {
+		maxTakerFee, ok := new(big.Int).SetString(config.MaxTakerFee, 10)
+		if !ok || maxTakerFee.Sign() == -1 {
+			return nil, fmt.Errorf("config.MaxTakerFee is invalid: %q is not a non-negative integer", config.MaxTakerFee)
+		}
+		feePolicy.MaxTakerFee = maxTakerFee
+	}
+	return feePolicy, nil
+}
+
+// parseSizePolicy returns the size policy specified by the given config or nil
+// if no size policy options are set.
+func parseSizePolicy(config Config) (*orderwatch.SizePolicy, error) {
+	if config.MinMakerAssetAmount == "" && config.MinTakerAssetAmount == "" && config.MinOrderValueUSD == "" {
+		return nil, nil
+	}
+	sizePolicy := &orderwatch.SizePolicy{
+		PriceOracle: config.PriceOracle,
+	}
+	if config.MinMakerAssetAmount != "" {
+		minMakerAssetAmount, ok := new(big.Int).SetString(config.MinMakerAssetAmount, 10)
+		if !ok || minMakerAssetAmount.Sign() == -1 {
+			return nil, fmt.Errorf("config.MinMakerAssetAmount is invalid: %q is not a non-negative integer", config.MinMakerAssetAmount)
+		}
+		sizePolicy.MinMakerAssetAmount = minMakerAssetAmount
+	}
+	if config.MinTakerAssetAmount != "" {
+		minTakerAssetAmount, ok := new(big.Int).SetString(config.MinTakerAssetAmount, 10)
+		if !ok || minTakerAssetAmount.Sign() == -1 {
+			return nil, fmt.Errorf("config.MinTakerAssetAmount is invalid: %q is not a non-negative integer", config.MinTakerAssetAmount)
+		}
+		sizePolicy.MinTakerAssetAmount = minTakerAssetAmount
+	}
+	if config.MinOrderValueUSD != "" {
+		if config.PriceOracle == nil {
+			return nil, errors.New("config.MinOrderValueUSD requires config.PriceOracle to be set")
+		}
+		minUSDValue, ok := new(big.Float).SetString(config.MinOrderValueUSD)
+		if !ok || minUSDValue.Sign() == -1 {
+			return nil, fmt.Errorf("config.MinOrderValueUSD is invalid: %q is not a non-negative number", config.MinOrderValueUSD)
+		}
+		sizePolicy.MinUSDValue = minUSDValue
+	}
+	return sizePolicy, nil
+}
+
+// loadOrderPolicy returns the order policy specified by the given config or nil
+// if there is none.
+func loadOrderPolicy(config Config) (orderpolicy.Policy, error) {
+	if config.OrderPolicyPluginPath == "" {
+		return config.OrderPolicy, nil
+	}
+	if config.OrderPolicy != nil {
+		return nil, errors.New("config.OrderPolicy and config.OrderPolicyPluginPath cannot both be set")
+	}
+	orderPolicy, err := orderpolicy.Load(config.OrderPolicyPluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load order policy plugin: %s", err.Error())
+	}
+	return orderPolicy, nil
+}
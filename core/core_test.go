@@ -11,8 +11,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
+	"github.com/0xProject/0x-mesh/ethereum/ratelimit"
 	"github.com/0xProject/0x-mesh/meshdb"
 	"github.com/0xProject/0x-mesh/scenario"
 	"github.com/0xProject/0x-mesh/scenario/orderopts"
@@ -53,6 +56,26 @@ func TestEthereumChainDetection(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// customEthClient is a custom implementation of ethrpcclient.Client. Only the
+// identity of the client matters for testing, so all methods are left
+// unimplemented.
+type customEthClient struct {
+	ethrpcclient.Client
+}
+
+func TestNewEthClientWithCustomClient(t *testing.T) {
+	customClient := &customEthClient{}
+	ethClient, err := newEthClient(Config{
+		EthereumRPCURL: constants.GanacheEndpoint,
+		EthereumClient: customClient,
+	}, ratelimit.NewUnlimited())
+	require.NoError(t, err)
+	assert.Equal(t, customClient, ethClient)
+
+	_, err = newEthClient(Config{}, ratelimit.NewUnlimited())
+	assert.Error(t, err, "expected an error when no Ethereum client is provided")
+}
+
 func TestConfigChainIDAndRPCMatchDetection(t *testing.T) {
 	if !serialTestsEnabled {
 		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
@@ -150,6 +173,41 @@ func init() {
 	}
 }
 
+func TestOrderStaleness(t *testing.T) {
+	now := time.Date(2020, 4, 8, 10, 32, 11, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		order    *meshdb.Order
+		expected time.Duration
+	}{
+		{
+			name: "validated recently",
+			order: &meshdb.Order{
+				LastUpdated:   now.Add(-time.Hour),
+				LastValidated: now.Add(-10 * time.Second),
+			},
+			expected: 10 * time.Second,
+		},
+		{
+			name: "stored before LastValidated was introduced",
+			order: &meshdb.Order{
+				LastUpdated: now.Add(-time.Hour),
+			},
+			expected: time.Hour,
+		},
+		{
+			name: "validated in the future",
+			order: &meshdb.Order{
+				LastValidated: now.Add(time.Second),
+			},
+			expected: 0,
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, orderStaleness(tc.order, now), tc.name)
+	}
+}
+
 func TestRepeatedAppInitialization(t *testing.T) {
 	dataDir := "/tmp/test_node/" + uuid.New().String()
 	config := Config{
@@ -336,7 +394,7 @@ func runOrdersyncTestCase(t *testing.T, testCase ordersyncTestCase) func(t *test
 
 		// Test that the orders are actually in the database and are returned by
 		// GetOrders.
-		newNodeOrdersResp, err := newNode.GetOrders(0, len(filteredOrders), "")
+		newNodeOrdersResp, err := newNode.GetOrders(0, len(filteredOrders), "", types.GetOrdersOpts{})
 		require.NoError(t, err)
 		assert.Len(t, newNodeOrdersResp.OrdersInfos, len(filteredOrders), "new node should have %d orders", len(originalOrders))
 		for _, expectedOrder := range filteredOrders {
@@ -0,0 +1,49 @@
+// +build !js
+
+package core
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/0xProject/0x-mesh/db"
+)
+
+// RestoreDBCheckpoint restores the database of the node with the given data
+// directory from a checkpoint written by App.WriteDBCheckpoint. It must be
+// called while Mesh is not running. To avoid accidentally overwriting orders,
+// it returns an error if a database already exists in the data directory. The
+// checkpoint is first restored into a temporary directory, so an interrupted
+// restore never leaves behind a partial database.
+func RestoreDBCheckpoint(dataDir string, r io.Reader) error {
+	databasePath := filepath.Join(dataDir, "db")
+	if entries, err := ioutil.ReadDir(databasePath); err == nil && len(entries) > 0 {
+		return fmt.Errorf("a database already exists at %s (move or delete it before restoring)", databasePath)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	tempPath := databasePath + ".restore"
+	if err := os.RemoveAll(tempPath); err != nil {
+		return err
+	}
+	database, err := db.Open(tempPath)
+	if err != nil {
+		return err
+	}
+	if err := database.RestoreCheckpoint(r); err != nil {
+		database.Close()
+		_ = os.RemoveAll(tempPath)
+		return err
+	}
+	if err := database.Close(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(databasePath); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, databasePath)
+}
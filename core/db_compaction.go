@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// dbCompactionStats keeps track of the results of database compactions for
+// GetStats.
+type dbCompactionStats struct {
+	mu sync.Mutex
+	// fragmentationPercent is the fragmentation of the database which was
+	// measured by the latest compaction.
+	fragmentationPercent float64
+	// reclaimedBytes is the total number of bytes reclaimed by compactions
+	// since startup.
+	reclaimedBytes int64
+}
+
+func (s *dbCompactionStats) add(result *db.CompactionResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fragmentationPercent = result.FragmentationPercent()
+	s.reclaimedBytes += result.ReclaimedBytes()
+}
+
+func (s *dbCompactionStats) get() (fragmentationPercent float64, reclaimedBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fragmentationPercent, s.reclaimedBytes
+}
+
+// CompactDB compacts the database in order to reclaim the disk space used by
+// orders and other data which were removed (e.g. after a large number of
+// orders was evicted). It is safe to call while the app is running. The
+// results are reported in the dbFragmentationPercent and dbReclaimedBytes
+// stats.
+func (app *App) CompactDB() (*db.CompactionResult, error) {
+	result, err := app.db.Compact()
+	if err != nil {
+		return nil, err
+	}
+	app.dbCompactionStats.add(result)
+	log.WithFields(log.Fields{
+		"sizeBefore":           result.SizeBefore,
+		"sizeAfter":            result.SizeAfter,
+		"fragmentationPercent": result.FragmentationPercent(),
+		"duration":             result.Duration.String(),
+	}).Info("compacted database")
+	return result, nil
+}
+
+// CompactDBInDataDir compacts the database of the node with the given data
+// directory. It must be called while Mesh is not running. Use App.CompactDB to
+// compact the database of a running node.
+func CompactDBInDataDir(dataDir string) (*db.CompactionResult, error) {
+	databasePath := filepath.Join(dataDir, "db")
+	if _, err := os.Stat(databasePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("no database found at %s", databasePath)
+	} else if err != nil {
+		return nil, err
+	}
+	database, err := db.Open(databasePath)
+	if err != nil {
+		return nil, err
+	}
+	result, err := database.Compact()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	if err := database.Close(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// startDBCompaction compacts the database every app.config.DBCompactionInterval
+// until ctx is canceled.
+func (app *App) startDBCompaction(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing database compaction loop")
+		}()
+		ticker := time.NewTicker(app.config.DBCompactionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := app.CompactDB(); err != nil {
+				log.WithError(err).Error("could not compact database")
+			}
+		}
+	}()
+}
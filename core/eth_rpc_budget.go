@@ -0,0 +1,46 @@
+package core
+
+import (
+	"time"
+)
+
+// ethRPCBudget describes how much of the daily Ethereum RPC request budget
+// has been used up.
+type ethRPCBudget struct {
+	// max is the number of requests allowed per UTC day or zero if Ethereum
+	// RPC rate limiting is disabled.
+	max int
+	// remaining is the number of requests which can still be sent in the
+	// current UTC day.
+	remaining int
+	// exhaustionETA is the time until the budget is used up if requests keep
+	// being sent at the average rate of the current UTC day. It is zero if the
+	// budget is not projected to run out before the UTC day ends.
+	exhaustionETA time.Duration
+}
+
+// computeEthRPCBudget returns the state of the daily Ethereum RPC request
+// budget at now, given the number of requests sent since startOfDay.
+func computeEthRPCBudget(maxRequests int, sent int, startOfDay time.Time, now time.Time) ethRPCBudget {
+	if maxRequests <= 0 {
+		return ethRPCBudget{}
+	}
+	budget := ethRPCBudget{
+		max:       maxRequests,
+		remaining: maxRequests - sent,
+	}
+	if budget.remaining <= 0 {
+		budget.remaining = 0
+		return budget
+	}
+	elapsed := now.Sub(startOfDay)
+	if sent == 0 || elapsed <= 0 {
+		return budget
+	}
+	timePerRequest := elapsed / time.Duration(sent)
+	eta := timePerRequest * time.Duration(budget.remaining)
+	if now.Add(eta).Before(startOfDay.Add(24 * time.Hour)) {
+		budget.exhaustionETA = eta
+	}
+	return budget
+}
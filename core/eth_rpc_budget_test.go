@@ -0,0 +1,61 @@
+// +build !js
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeEthRPCBudget(t *testing.T) {
+	startOfDay := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		description string
+		maxRequests int
+		sent        int
+		now         time.Time
+		expected    ethRPCBudget
+	}{
+		{
+			description: "rate limiting disabled",
+			maxRequests: 0,
+			sent:        1000,
+			now:         startOfDay.Add(time.Hour),
+			expected:    ethRPCBudget{},
+		},
+		{
+			description: "no requests sent",
+			maxRequests: 1000,
+			sent:        0,
+			now:         startOfDay.Add(time.Hour),
+			expected:    ethRPCBudget{max: 1000, remaining: 1000},
+		},
+		{
+			description: "projected to run out before the day ends",
+			maxRequests: 1000,
+			sent:        300,
+			now:         startOfDay.Add(6 * time.Hour),
+			expected:    ethRPCBudget{max: 1000, remaining: 700, exhaustionETA: 14 * time.Hour},
+		},
+		{
+			description: "not projected to run out before the day ends",
+			maxRequests: 1000,
+			sent:        100,
+			now:         startOfDay.Add(6 * time.Hour),
+			expected:    ethRPCBudget{max: 1000, remaining: 900},
+		},
+		{
+			description: "budget used up",
+			maxRequests: 1000,
+			sent:        1200,
+			now:         startOfDay.Add(6 * time.Hour),
+			expected:    ethRPCBudget{max: 1000, remaining: 0},
+		},
+	}
+	for _, testCase := range testCases {
+		actual := computeEthRPCBudget(testCase.maxRequests, testCase.sent, startOfDay, testCase.now)
+		assert.Equal(t, testCase.expected, actual, testCase.description)
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	log "github.com/sirupsen/logrus"
+)
+
+// startEthRPCCacheUpdates passes the blocks processed by the block watcher to
+// the Ethereum RPC cache until ctx is canceled. This lets the cache learn the
+// hashes of new blocks without fetching their headers itself and evict the
+// results for blocks which were removed by a re-org.
+func (app *App) startEthRPCCacheUpdates(ctx context.Context, wg *sync.WaitGroup) {
+	blockEvents := make(chan []*blockwatch.Event, 100)
+	blockSubscription := app.blockWatcher.Subscribe(blockEvents)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing Ethereum RPC cache updates")
+		}()
+		defer blockSubscription.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-blockSubscription.Err():
+				log.WithError(err).Error("block subscription error encountered while updating the Ethereum RPC cache")
+				return
+			case events := <-blockEvents:
+				for _, event := range events {
+					switch event.Type {
+					case blockwatch.Added:
+						app.ethRPCCache.AddBlock(event.BlockHeader)
+					case blockwatch.Removed:
+						app.ethRPCCache.RemoveBlock(event.BlockHeader)
+					}
+				}
+			}
+		}
+	}()
+}
@@ -5,15 +5,16 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
 	"github.com/ethereum/go-ethereum/common/math"
 )
 
-func (app *App) getEthRPCChainID(ctx context.Context) (*big.Int, error) {
+func getEthRPCChainID(ctx context.Context, ethRPCClient ethrpcclient.Client) (*big.Int, error) {
 	ctx, cancel := context.WithTimeout(ctx, ethereumRPCRequestTimeout)
 	defer cancel()
 
 	var chainIDRaw string
-	err := app.ethRPCClient.CallContext(ctx, &chainIDRaw, "eth_chainId")
+	err := ethRPCClient.CallContext(ctx, &chainIDRaw, "eth_chainId")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/filterattestation"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// filterAttestationSource provides the order filters which are sent to peers
+// that request them. It implements filterattestation.FilterSource.
+type filterAttestationSource struct {
+	app *App
+}
+
+// Ensure that filterAttestationSource implements filterattestation.FilterSource.
+var _ filterattestation.FilterSource = &filterAttestationSource{}
+
+// FilterForTopic implements filterattestation.FilterSource.
+func (s *filterAttestationSource) FilterForTopic(topic string) (*orderfilter.Filter, bool) {
+	return s.app.filterForTopic(topic)
+}
+
+// filterForTopic returns the order filter used for the given topic, which is
+// either the topic for CustomOrderFilter or one of the topics for
+// AdditionalOrderFilters.
+func (app *App) filterForTopic(topic string) (*orderfilter.Filter, bool) {
+	if app.orderFilter.Topic() == topic {
+		return app.orderFilter, true
+	}
+	for _, additionalFilter := range app.additionalOrderFilters {
+		if additionalFilter.filter.Topic() == topic {
+			return additionalFilter.filter, true
+		}
+	}
+	return nil, false
+}
+
+// FilterMismatchError is returned by VerifyPeerFilter if the peer uses a
+// different order filter for the topic than this node.
+type FilterMismatchError struct {
+	Topic                   string
+	PeerExchangeAddress     string
+	ExpectedExchangeAddress string
+}
+
+func (e FilterMismatchError) Error() string {
+	return fmt.Sprintf("peer uses a filter for exchange %s instead of %s for topic %s", e.PeerExchangeAddress, e.ExpectedExchangeAddress, e.Topic)
+}
+
+// VerifyPeerFilter requests the order filter that the given peer uses for
+// topic and checks that it is semantically identical to the filter this node
+// uses for the topic. It returns a FilterMismatchError if the filters differ,
+// and filterattestation.ErrTopicNotUsed if the peer doesn't use the topic. If
+// the filters differ, the score of the peer is lowered, since any orders it
+// sends on the topic would be rejected.
+func (app *App) VerifyPeerFilter(ctx context.Context, peerID peer.ID, topic string) error {
+	<-app.started
+
+	ownFilter, found := app.filterForTopic(topic)
+	if !found {
+		return fmt.Errorf("topic is not used by this node: %s", topic)
+	}
+	peerFilter, err := filterattestation.RequestFilter(ctx, app.node, peerID, topic)
+	if err != nil {
+		return err
+	}
+	if peerFilter.Equal(ownFilter) {
+		return nil
+	}
+	log.WithFields(log.Fields{
+		"peerID":                  peerID.Pretty(),
+		"topic":                   topic,
+		"peerExchangeAddress":     peerFilter.ExchangeAddress().Hex(),
+		"expectedExchangeAddress": ownFilter.ExchangeAddress().Hex(),
+	}).Warn("peer uses a different order filter for a shared topic")
+	app.handlePeerScoreEvent(peerID, psReceivedOrderDoesNotMatchFilter)
+	return FilterMismatchError{
+		Topic:                   topic,
+		PeerExchangeAddress:     peerFilter.ExchangeAddress().Hex(),
+		ExpectedExchangeAddress: ownFilter.ExchangeAddress().Hex(),
+	}
+}
+
+const (
+	// maxPeerFilterVerifications is the maximum number of (peer, topic) pairs
+	// for which the result of the filter verification is remembered. When the
+	// limit is reached, all results are forgotten and peers are verified again
+	// the next time they send a message on a custom topic.
+	maxPeerFilterVerifications = 10000
+	// peerFilterVerificationTimeout is how long to wait for a peer to respond
+	// with its filter.
+	peerFilterVerificationTimeout = 30 * time.Second
+)
+
+type peerTopic struct {
+	peerID peer.ID
+	topic  string
+}
+
+// peerFilterVerifications keeps track of the peers whose order filter was
+// verified (or is being verified) for each custom topic, so that every peer is
+// only asked for its filter once per topic.
+type peerFilterVerifications struct {
+	// defaultTopic is the topic of the default order filter. Filters don't
+	// need to be verified for it since every node uses the same filter.
+	defaultTopic string
+	// verify is called to verify the filter of a peer. It is App.VerifyPeerFilter
+	// except in tests.
+	verify   func(ctx context.Context, peerID peer.ID, topic string) error
+	mu       sync.Mutex
+	verified map[peerTopic]struct{}
+}
+
+func newPeerFilterVerifications(defaultTopic string, verify func(ctx context.Context, peerID peer.ID, topic string) error) *peerFilterVerifications {
+	return &peerFilterVerifications{
+		defaultTopic: defaultTopic,
+		verify:       verify,
+		verified:     map[peerTopic]struct{}{},
+	}
+}
+
+// markStarted records that the filter of the peer is being verified for the
+// topic. It returns false if the filter was already verified.
+func (v *peerFilterVerifications) markStarted(peerID peer.ID, topic string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := peerTopic{peerID: peerID, topic: topic}
+	if _, found := v.verified[key]; found {
+		return false
+	}
+	if len(v.verified) >= maxPeerFilterVerifications {
+		v.verified = map[peerTopic]struct{}{}
+	}
+	v.verified[key] = struct{}{}
+	return true
+}
+
+// forget removes the record for the peer and topic so that the filter of the
+// peer is verified again the next time it sends a message on the topic.
+func (v *peerFilterVerifications) forget(peerID peer.ID, topic string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.verified, peerTopic{peerID: peerID, topic: topic})
+}
+
+// verifyPeerFilterInBackground verifies the order filter that the peer uses
+// for the topic if the topic belongs to a custom order filter and the filter
+// of the peer wasn't verified for it yet. It is called whenever a message is
+// received from a peer so that mismatched filters are detected as soon as a
+// peer starts sending on a shared custom topic. The verification runs in a
+// separate goroutine and doesn't block message handling.
+func (app *App) verifyPeerFilterInBackground(ctx context.Context, peerID peer.ID, topic string) {
+	verifications := app.peerFilterVerifications
+	if verifications == nil || peerID == app.peerID || topic == verifications.defaultTopic {
+		return
+	}
+	if _, found := app.filterForTopic(topic); !found {
+		return
+	}
+	if !verifications.markStarted(peerID, topic) {
+		return
+	}
+	go func() {
+		verifyCtx, cancel := context.WithTimeout(ctx, peerFilterVerificationTimeout)
+		defer cancel()
+		err := verifications.verify(verifyCtx, peerID, topic)
+		switch err.(type) {
+		case nil, FilterMismatchError:
+			// The result is final. Mismatches are already logged and penalized by
+			// VerifyPeerFilter.
+			return
+		}
+		logger := log.WithError(err).WithFields(log.Fields{
+			"peerID": peerID.Pretty(),
+			"topic":  topic,
+		})
+		if err == filterattestation.ErrTopicNotUsed {
+			// The peer sent a message on a topic it claims not to use. This is
+			// also final.
+			logger.Warn("peer sent a message on a topic it does not use")
+			return
+		}
+		logger.Debug("could not verify order filter of peer")
+		// Try again the next time the peer sends a message on the topic.
+		verifications.forget(peerID, topic)
+	}()
+}
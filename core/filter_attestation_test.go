@@ -0,0 +1,114 @@
+// +build !js
+
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyPeerFilterInBackground(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	defaultFilter, err := orderfilter.GetDefaultFilter(constants.TestChainID, contractAddresses)
+	require.NoError(t, err)
+	customFilter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149"}}}`, contractAddresses)
+	require.NoError(t, err)
+	additionalFilter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"}}}`, contractAddresses)
+	require.NoError(t, err)
+	unusedFilter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0xe36ea790bc9d7ab70c55260c66d52b1eca985f84"}}}`, contractAddresses)
+	require.NoError(t, err)
+
+	type verification struct {
+		peerID peer.ID
+		topic  string
+	}
+	verifications := make(chan verification, 10)
+	verifyErr := make(chan error, 10)
+	app := &App{
+		peerID:                 newTestPeerID(t),
+		orderFilter:            customFilter,
+		additionalOrderFilters: []*additionalOrderFilter{{filter: additionalFilter}},
+	}
+	app.peerFilterVerifications = newPeerFilterVerifications(defaultFilter.Topic(), func(ctx context.Context, peerID peer.ID, topic string) error {
+		verifications <- verification{peerID: peerID, topic: topic}
+		return <-verifyErr
+	})
+	expectVerification := func(expected verification) {
+		select {
+		case actual := <-verifications:
+			assert.Equal(t, expected, actual)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("filter of peer %s was not verified for topic %s", expected.peerID.Pretty(), expected.topic)
+		}
+	}
+	expectNoVerification := func() {
+		select {
+		case actual := <-verifications:
+			t.Errorf("unexpected verification of filter of peer %s for topic %s", actual.peerID.Pretty(), actual.topic)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	peerID := newTestPeerID(t)
+
+	// Filters are not verified for the default topic, topics this node doesn't
+	// use or messages sent by this node.
+	app.verifyPeerFilterInBackground(ctx, peerID, defaultFilter.Topic())
+	app.verifyPeerFilterInBackground(ctx, peerID, unusedFilter.Topic())
+	app.verifyPeerFilterInBackground(ctx, app.peerID, customFilter.Topic())
+	expectNoVerification()
+
+	// The filter is verified the first time the peer sends a message on a
+	// custom topic, but not again after it was verified.
+	verifyErr <- nil
+	app.verifyPeerFilterInBackground(ctx, peerID, customFilter.Topic())
+	expectVerification(verification{peerID: peerID, topic: customFilter.Topic()})
+	app.verifyPeerFilterInBackground(ctx, peerID, customFilter.Topic())
+	expectNoVerification()
+
+	// Mismatched filters are not verified again either.
+	verifyErr <- FilterMismatchError{}
+	app.verifyPeerFilterInBackground(ctx, peerID, additionalFilter.Topic())
+	expectVerification(verification{peerID: peerID, topic: additionalFilter.Topic()})
+	app.verifyPeerFilterInBackground(ctx, peerID, additionalFilter.Topic())
+	expectNoVerification()
+
+	// If the verification fails for another reason, it is retried when the
+	// peer sends the next message on the topic.
+	otherPeerID := newTestPeerID(t)
+	verifyErr <- errors.New("stream reset")
+	app.verifyPeerFilterInBackground(ctx, otherPeerID, customFilter.Topic())
+	expectVerification(verification{peerID: otherPeerID, topic: customFilter.Topic()})
+	// Wait for the failed verification to be forgotten.
+	require.Eventually(t, func() bool {
+		app.peerFilterVerifications.mu.Lock()
+		defer app.peerFilterVerifications.mu.Unlock()
+		_, found := app.peerFilterVerifications.verified[peerTopic{peerID: otherPeerID, topic: customFilter.Topic()}]
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+	verifyErr <- nil
+	app.verifyPeerFilterInBackground(ctx, otherPeerID, customFilter.Topic())
+	expectVerification(verification{peerID: otherPeerID, topic: customFilter.Topic()})
+}
+
+func newTestPeerID(t *testing.T) peer.ID {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	require.NoError(t, err)
+	return peerID
+}
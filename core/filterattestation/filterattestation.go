@@ -0,0 +1,198 @@
+// Package filterattestation contains the filter attestation protocol, which
+// peers use to prove which order filter a pubsub topic they advertise stands
+// for. The topic of a custom order filter only contains an encoding of its
+// JSON Schema, chain ID and network ID, but not the exchange address, so two
+// nodes which joined the same topic can still disagree about which orders
+// belong in it. Such nodes waste bandwidth gossiping orders the other node
+// rejects. By requesting the filter for a topic and comparing it to its own, a
+// node can verify that it shares semantically identical filters with a peer.
+//
+// A requester opens a stream and sends a single JSON-encoded Request which
+// contains the topic. The provider responds with a single JSON-encoded
+// Response which contains its filter for the topic (if it uses the topic) and
+// closes the stream.
+package filterattestation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// ID is the ID for the filter attestation protocol.
+const ID = protocol.ID("/0x-mesh/filter-attestation/version/0")
+
+const (
+	// maxTopicLength is the maximum length of the topic in a request.
+	maxTopicLength = 8 * 1024
+	// maxMessageSize is the maximum size of a single request or response.
+	maxMessageSize = 64 * 1024
+	// requestResponseTimeout is how long to wait for a request or response.
+	requestResponseTimeout = 10 * time.Second
+	// maxRequestsPerSecond is the maximum number of requests per second that
+	// will be handled for all peers combined.
+	maxRequestsPerSecond = 5
+	// requestsBurst is the maximum number of requests that can be handled at
+	// once.
+	requestsBurst = 10
+)
+
+// ErrTopicNotUsed is returned by RequestFilter if the peer doesn't use the
+// requested topic.
+var ErrTopicNotUsed = errors.New("peer does not use the requested topic")
+
+// TopicMismatchError is returned by RequestFilter if the filter returned by the
+// peer doesn't belong to the requested topic.
+type TopicMismatchError struct {
+	RequestedTopic string
+	FilterTopic    string
+}
+
+func (e TopicMismatchError) Error() string {
+	return fmt.Sprintf("peer responded with a filter for topic %s instead of %s", e.FilterTopic, e.RequestedTopic)
+}
+
+// Request is a request for the filter of a topic.
+type Request struct {
+	Topic string `json:"topic"`
+}
+
+// Response contains the filter that the provider uses for the requested
+// topic. Filter is nil if the provider doesn't use the topic.
+type Response struct {
+	Topic  string              `json:"topic"`
+	Filter *orderfilter.Filter `json:"filter,omitempty"`
+}
+
+// Verify checks that the response contains a filter which belongs to the given
+// topic and returns the filter.
+func (res *Response) Verify(topic string) (*orderfilter.Filter, error) {
+	if res.Filter == nil {
+		return nil, ErrTopicNotUsed
+	}
+	if filterTopic := res.Filter.Topic(); filterTopic != topic {
+		return nil, TopicMismatchError{
+			RequestedTopic: topic,
+			FilterTopic:    filterTopic,
+		}
+	}
+	return res.Filter, nil
+}
+
+// FilterSource provides the order filters used by a Mesh node.
+type FilterSource interface {
+	// FilterForTopic returns the filter which the node uses for the given
+	// topic. The second return value is false if the node doesn't use the
+	// topic.
+	FilterForTopic(topic string) (*orderfilter.Filter, bool)
+}
+
+// Service is the provider side of the filter attestation protocol.
+type Service struct {
+	ctx                context.Context
+	node               *p2p.Node
+	filters            FilterSource
+	requestRateLimiter *rate.Limiter
+}
+
+// New creates and returns a new Service which responds with the filters
+// provided by filters.
+func New(ctx context.Context, node *p2p.Node, filters FilterSource) *Service {
+	s := &Service{
+		ctx:                ctx,
+		node:               node,
+		filters:            filters,
+		requestRateLimiter: rate.NewLimiter(maxRequestsPerSecond, requestsBurst),
+	}
+	s.node.SetStreamHandler(ID, s.HandleStream)
+	return s
+}
+
+// HandleStream is a stream handler that is used to handle incoming filter
+// attestation requests.
+func (s *Service) HandleStream(stream network.Stream) {
+	requesterID := stream.Conn().RemotePeer()
+	if !s.requestRateLimiter.Allow() {
+		log.WithField("requester", requesterID.Pretty()).Debug("resetting filter attestation stream because rate limiter is backed up")
+		_ = stream.Reset()
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	_ = stream.SetReadDeadline(time.Now().Add(requestResponseTimeout))
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&req); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not decode filter attestation request")
+		return
+	}
+	if len(req.Topic) > maxTopicLength {
+		log.WithField("requester", requesterID.Pretty()).Debug("received filter attestation request with a topic that is too long")
+		return
+	}
+	res := s.Attest(req.Topic)
+	_ = stream.SetWriteDeadline(time.Now().Add(requestResponseTimeout))
+	if err := json.NewEncoder(stream).Encode(res); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not send filter attestation response")
+	}
+}
+
+// Attest returns the response to a request for the filter of the given topic.
+func (s *Service) Attest(topic string) *Response {
+	res := &Response{Topic: topic}
+	if filter, found := s.filters.FilterForTopic(topic); found {
+		res.Filter = filter
+	}
+	return res
+}
+
+// StreamOpener opens streams to other peers. It is implemented by both
+// *p2p.Node and host.Host.
+type StreamOpener interface {
+	NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error)
+}
+
+// RequestFilter requests the filter that the given peer uses for topic and
+// verifies that it belongs to the topic, i.e. that its JSON Schema, chain ID
+// and network ID are the ones encoded in the topic. It returns ErrTopicNotUsed
+// if the peer doesn't use the topic.
+func RequestFilter(ctx context.Context, opener StreamOpener, peerID peer.ID, topic string) (*orderfilter.Filter, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestResponseTimeout)
+	defer cancel()
+	stream, err := opener.NewStream(ctx, peerID, ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+	if err := json.NewEncoder(stream).Encode(Request{Topic: topic}); err != nil {
+		return nil, err
+	}
+	var res Response
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&res); err != nil {
+		return nil, err
+	}
+	return res.Verify(topic)
+}
@@ -0,0 +1,50 @@
+package filterattestation
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFilterSource map[string]*orderfilter.Filter
+
+func (s testFilterSource) FilterForTopic(topic string) (*orderfilter.Filter, bool) {
+	filter, found := s[topic]
+	return filter, found
+}
+
+func TestAttestAndVerify(t *testing.T) {
+	filter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149"}}}`, ethereum.GanacheAddresses)
+	require.NoError(t, err)
+	defaultFilter, err := orderfilter.GetDefaultFilter(constants.TestChainID, ethereum.GanacheAddresses)
+	require.NoError(t, err)
+	service := &Service{
+		filters: testFilterSource{filter.Topic(): filter},
+	}
+
+	// The response is sent over the wire as JSON.
+	encoded, err := json.Marshal(service.Attest(filter.Topic()))
+	require.NoError(t, err)
+	var res Response
+	require.NoError(t, json.Unmarshal(encoded, &res))
+	actual, err := res.Verify(filter.Topic())
+	require.NoError(t, err)
+	assert.True(t, filter.Equal(actual), "filter should survive the round trip")
+
+	encoded, err = json.Marshal(service.Attest(defaultFilter.Topic()))
+	require.NoError(t, err)
+	res = Response{}
+	require.NoError(t, json.Unmarshal(encoded, &res))
+	_, err = res.Verify(defaultFilter.Topic())
+	assert.Equal(t, ErrTopicNotUsed, err)
+
+	// A filter which doesn't belong to the requested topic must be rejected.
+	res = Response{Topic: defaultFilter.Topic(), Filter: filter}
+	_, err = res.Verify(defaultFilter.Topic())
+	assert.Equal(t, TopicMismatchError{RequestedTopic: defaultFilter.Topic(), FilterTopic: filter.Topic()}, err)
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PauseIngestion stops accepting new orders from the network, i.e. orders
+// received via GossipSub and ordersync are dropped and orders rejected for a
+// retriable reason are not retried until ResumeIngestion is called. Mesh still
+// serves queries, accepts orders via AddOrders, shares its orders with peers
+// and tracks the state of the chain while ingestion is paused. This is useful
+// during maintenance, migrations or incident response. Pausing ingestion
+// which is already paused has no effect. No ordersync requests are sent to
+// other peers while ingestion is paused.
+func (app *App) PauseIngestion() {
+	if atomic.CompareAndSwapInt32(&app.ingestionPaused, 0, 1) {
+		log.Info("paused ingestion of orders from the network")
+	}
+	app.syncOrdersyncPause()
+}
+
+// ResumeIngestion resumes accepting new orders from the network after
+// PauseIngestion was called. Orders which were missed in the meantime are
+// received with the next periodic run of ordersync. Resuming ingestion which
+// isn't paused has no effect.
+func (app *App) ResumeIngestion() {
+	if atomic.CompareAndSwapInt32(&app.ingestionPaused, 1, 0) {
+		log.Info("resumed ingestion of orders from the network")
+	}
+	app.syncOrdersyncPause()
+}
+
+// isIngestionPaused returns true if ingestion of orders from the network was
+// paused with PauseIngestion.
+func (app *App) isIngestionPaused() bool {
+	return atomic.LoadInt32(&app.ingestionPaused) == 1
+}
+
+// syncOrdersyncPause pauses or resumes requests of the ordersync service
+// according to whether ingestion is paused. It does nothing if the app hasn't
+// been started yet; Start calls it again once the ordersync service exists.
+func (app *App) syncOrdersyncPause() {
+	select {
+	case <-app.started:
+	default:
+		return
+	}
+	if app.isIngestionPaused() {
+		app.ordersyncService.PauseRequests()
+	} else {
+		app.ordersyncService.ResumeRequests()
+	}
+}
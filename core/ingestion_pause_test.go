@@ -0,0 +1,42 @@
+// +build !js
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/core/ordersync"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseAndResumeIngestion(t *testing.T) {
+	t.Parallel()
+
+	app := &App{}
+	assert.False(t, app.isIngestionPaused())
+	app.PauseIngestion()
+	assert.True(t, app.isIngestionPaused())
+	// Pausing twice has no effect.
+	app.PauseIngestion()
+	assert.True(t, app.isIngestionPaused())
+
+	// Orders received via ordersync are not validated while ingestion is
+	// paused.
+	subprotocol := &FilteredPaginationSubProtocol{app: app}
+	res := &ordersync.Response{
+		Orders:   []*zeroex.SignedOrder{{}},
+		Metadata: &FilteredPaginationResponseMetadata{Page: 0},
+	}
+	nextReq, err := subprotocol.HandleOrderSyncResponse(context.Background(), res)
+	assert.Nil(t, nextReq)
+	require.Equal(t, ordersync.ErrRequestsPaused, err)
+
+	app.ResumeIngestion()
+	assert.False(t, app.isIngestionPaused())
+	// Resuming twice has no effect.
+	app.ResumeIngestion()
+	assert.False(t, app.isIngestionPaused())
+}
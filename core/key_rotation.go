@@ -0,0 +1,131 @@
+// +build !js
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/0xProject/0x-mesh/keys"
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// privateKeyFile is the name of the file in DATA_DIR/keys which contains
+	// the private key of the node.
+	privateKeyFile = "privkey"
+	// previousPrivateKeyFile is the name of the file in DATA_DIR/keys which
+	// contains the private key that was replaced by the last key rotation.
+	previousPrivateKeyFile = "privkey.previous"
+	// keyRotationFile is the name of the file in DATA_DIR/keys which contains
+	// the announcement for the last key rotation.
+	keyRotationFile = "key_rotation.json"
+)
+
+// RotatePrivateKey replaces the private key (and therefore the peer ID) of the
+// node with the given data directory by a newly generated one. The previous
+// key is kept in DATA_DIR/keys/privkey.previous. Until the grace period ends,
+// the node announces the rotation to every peer it connects to, so that they
+// can carry over their relationship with the previous identity (see
+// p2p.KeyRotation). The new key is used the next time the node is started.
+func RotatePrivateKey(dataDir string, gracePeriod time.Duration) (*p2p.KeyRotation, error) {
+	if gracePeriod <= 0 || gracePeriod > p2p.MaxKeyRotationGracePeriod {
+		return nil, fmt.Errorf("grace period must be positive and at most %s", p2p.MaxKeyRotationGracePeriod)
+	}
+	keysDir := filepath.Join(dataDir, "keys")
+	privKeyPath := filepath.Join(keysDir, privateKeyFile)
+	previousPrivKeyPath := filepath.Join(keysDir, previousPrivateKeyFile)
+	keyRotationPath := filepath.Join(keysDir, keyRotationFile)
+
+	previousKey, err := keys.GetPrivateKeyFromPath(privKeyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no private key found at %s", privKeyPath)
+		}
+		return nil, err
+	}
+	// Rotating the key again during the grace period would overwrite the
+	// previous key and cut off peers which only know about it.
+	if rotation, err := readKeyRotation(keyRotationPath); err != nil {
+		return nil, err
+	} else if rotation != nil && time.Now().Before(rotation.ExpirationTime) {
+		return nil, fmt.Errorf("the grace period of the last key rotation has not ended yet (it ends at %s)", rotation.ExpirationTime)
+	}
+
+	newPrivKeyPath := privKeyPath + ".new"
+	newKey, err := keys.GenerateAndSavePrivateKey(newPrivKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	newPeerID, err := peer.IDFromPrivateKey(newKey)
+	if err != nil {
+		return nil, err
+	}
+	rotation, err := p2p.NewKeyRotation(previousKey, newPeerID, time.Now().Add(gracePeriod))
+	if err != nil {
+		return nil, err
+	}
+	encodedRotation, err := json.Marshal(rotation)
+	if err != nil {
+		return nil, err
+	}
+	// Note: If we crash between any of the following steps, the key rotation
+	// file will not match the private key and will be ignored on startup.
+	if err := ioutil.WriteFile(keyRotationPath, encodedRotation, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(privKeyPath, previousPrivKeyPath); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(newPrivKeyPath, privKeyPath); err != nil {
+		return nil, err
+	}
+	return rotation, nil
+}
+
+// loadKeyRotation returns the announcement for the last key rotation of the
+// node with the given data directory and peer ID, or nil if there is none or
+// its grace period has ended.
+func loadKeyRotation(dataDir string, peerID peer.ID) (*p2p.KeyRotation, error) {
+	rotation, err := readKeyRotation(filepath.Join(dataDir, "keys", keyRotationFile))
+	if err != nil || rotation == nil {
+		return nil, err
+	}
+	previousPeerID, err := rotation.Verify(peerID, time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"newPeerID": rotation.NewPeerID.String(),
+		}).Info("not announcing key rotation")
+		return nil, nil
+	}
+	log.WithFields(log.Fields{
+		"previousPeerID": previousPeerID.String(),
+		"expirationTime": rotation.ExpirationTime,
+	}).Info("announcing key rotation to peers until the grace period ends")
+	return rotation, nil
+}
+
+// readKeyRotation reads the key rotation at the given path. It returns nil if
+// the file doesn't exist.
+func readKeyRotation(path string) (*p2p.KeyRotation, error) {
+	encodedRotation, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rotation p2p.KeyRotation
+	if err := json.Unmarshal(encodedRotation, &rotation); err != nil {
+		return nil, errors.New("could not decode key rotation: " + err.Error())
+	}
+	return &rotation, nil
+}
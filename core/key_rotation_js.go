@@ -0,0 +1,14 @@
+// +build js,wasm
+
+package core
+
+import (
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// loadKeyRotation always returns nil since key rotation is not supported in
+// the browser.
+func loadKeyRotation(dataDir string, peerID peer.ID) (*p2p.KeyRotation, error) {
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+// +build !js
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/keys"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatePrivateKey(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "mesh-key-rotation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+	privKeyPath := filepath.Join(dataDir, "keys", privateKeyFile)
+
+	// Rotating fails if there is no key yet.
+	_, err = RotatePrivateKey(dataDir, time.Hour)
+	require.Error(t, err)
+
+	previousKey, err := keys.GenerateAndSavePrivateKey(privKeyPath)
+	require.NoError(t, err)
+	previousPeerID, err := peer.IDFromPrivateKey(previousKey)
+	require.NoError(t, err)
+
+	rotation, err := RotatePrivateKey(dataDir, time.Hour)
+	require.NoError(t, err)
+	newKey, err := keys.GetPrivateKeyFromPath(privKeyPath)
+	require.NoError(t, err)
+	newPeerID, err := peer.IDFromPrivateKey(newKey)
+	require.NoError(t, err)
+	assert.Equal(t, newPeerID, rotation.NewPeerID)
+	assert.NotEqual(t, previousPeerID, newPeerID)
+
+	// The previous key is kept.
+	storedPreviousKey, err := keys.GetPrivateKeyFromPath(filepath.Join(dataDir, "keys", previousPrivateKeyFile))
+	require.NoError(t, err)
+	assert.True(t, storedPreviousKey.Equals(previousKey))
+
+	// The rotation is announced by the node with the new key.
+	loadedRotation, err := loadKeyRotation(dataDir, newPeerID)
+	require.NoError(t, err)
+	require.NotNil(t, loadedRotation)
+	loadedPreviousPeerID, err := loadedRotation.PreviousPeerID()
+	require.NoError(t, err)
+	assert.Equal(t, previousPeerID, loadedPreviousPeerID)
+
+	// The rotation is ignored by any other node.
+	loadedRotation, err = loadKeyRotation(dataDir, previousPeerID)
+	require.NoError(t, err)
+	assert.Nil(t, loadedRotation)
+
+	// The key can't be rotated again during the grace period.
+	_, err = RotatePrivateKey(dataDir, time.Hour)
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xProject/0x-mesh/loghooks"
+	log "github.com/sirupsen/logrus"
+)
+
+// logSubsystemPackages maps the names of the subsystems for which debug logging
+// can be enabled separately to their package paths.
+var logSubsystemPackages = map[string]string{
+	"p2p":        "github.com/0xProject/0x-mesh/p2p",
+	"blockwatch": "github.com/0xProject/0x-mesh/ethereum/blockwatch",
+	"ordersync":  "github.com/0xProject/0x-mesh/core/ordersync",
+	"orderwatch": "github.com/0xProject/0x-mesh/zeroex/orderwatch",
+}
+
+// logFilterFormatter is the formatter used by the global logger. It is used to
+// enable debug logging for specific subsystems.
+var logFilterFormatter = loghooks.NewLevelFilterFormatter(&log.JSONFormatter{}, log.InfoLevel)
+
+// SetLogLevel changes the logging verbosity at runtime (see Config.Verbosity)
+// and enables debug logging for the given subsystems regardless of the
+// verbosity. Valid subsystems are "p2p", "blockwatch", "ordersync" and
+// "orderwatch". Passing an empty list of subsystems disables any previously
+// enabled subsystem debug logging.
+func (app *App) SetLogLevel(verbosity int, debugSubsystems []string) error {
+	if verbosity < int(log.PanicLevel) || verbosity > int(log.TraceLevel) {
+		return fmt.Errorf("verbosity must be between %d and %d", log.PanicLevel, log.TraceLevel)
+	}
+	baseLevel := log.Level(verbosity)
+	packageLevels := map[string]log.Level{}
+	for _, subsystem := range debugSubsystems {
+		pkg, found := logSubsystemPackages[subsystem]
+		if !found {
+			return errors.New("unknown log subsystem: " + subsystem)
+		}
+		packageLevels[pkg] = log.DebugLevel
+	}
+
+	logFilterFormatter.SetLevels(baseLevel, packageLevels)
+	// The caller is needed to determine which package an entry was logged
+	// from, but it is somewhat expensive to compute so we only report it when
+	// needed.
+	log.SetReportCaller(logFilterFormatter.MaxLevel() > baseLevel)
+	log.SetLevel(logFilterFormatter.MaxLevel())
+	log.WithFields(log.Fields{
+		"verbosity":       verbosity,
+		"debugSubsystems": debugSubsystems,
+	}).Info("changed log level")
+	return nil
+}
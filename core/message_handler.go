@@ -2,10 +2,12 @@ package core
 
 import (
 	"context"
+	"math/rand"
 
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/encoding"
 	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/replay"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,9 +18,20 @@ import (
 var _ p2p.MessageHandler = &App{}
 
 func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) error {
+	if app.recorder != nil {
+		if err := app.recorder.RecordMessages(messages); err != nil && err != replay.ErrRecorderClosed {
+			log.WithError(err).Error("could not record messages")
+		}
+	}
+
 	// First we validate the messages and decode them into orders.
 	orders := []*zeroex.SignedOrder{}
 	orderHashToMessage := map[common.Hash]*p2p.Message{}
+	// The same order may be received on more than one topic. It is only stored
+	// once, but we keep track of every topic it was received on.
+	orderHashToTopics := map[common.Hash][]string{}
+	quotas := app.newTopicQuotas()
+	softCancelMessages := []*p2p.Message{}
 
 	for _, msg := range messages {
 		if err := validateMessageSize(msg); err != nil {
@@ -31,6 +44,27 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			app.handlePeerScoreEvent(msg.From, psInvalidMessage)
 			continue
 		}
+		if app.softCancels != nil && msg.Topic == app.softCancelTopic() {
+			softCancelMessages = append(softCancelMessages, msg)
+			continue
+		}
+		if app.isIngestionPaused() {
+			// Don't incur a negative score since pausing ingestion is our own
+			// decision.
+			log.WithFields(map[string]interface{}{
+				"from":  msg.From,
+				"topic": msg.Topic,
+			}).Trace("dropped message because ingestion is paused")
+			continue
+		}
+		if !quotas.allow(msg.Topic) {
+			// Don't incur a negative score since the quota is our own policy.
+			log.WithFields(map[string]interface{}{
+				"from":  msg.From,
+				"topic": msg.Topic,
+			}).Trace("dropped message because the storage quota for its topic was reached")
+			continue
+		}
 
 		order, err := encoding.RawMessageToOrder(msg.Data)
 		if err != nil {
@@ -45,6 +79,12 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 		if err != nil {
 			return err
 		}
+		if msg.Topic != "" {
+			orderHashToTopics[orderHash] = append(orderHashToTopics[orderHash], msg.Topic)
+		}
+		// Make sure that the peer uses the same filter for a custom topic as we
+		// do before it wastes more bandwidth on orders we would reject.
+		app.verifyPeerFilterInBackground(ctx, msg.From, msg.Topic)
 		// Validate doesn't guarantee there are no duplicates so we keep track of
 		// which orders we've already seen.
 		if _, alreadySeen := orderHashToMessage[orderHash]; alreadySeen {
@@ -54,26 +94,79 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 		orderHashToMessage[orderHash] = msg
 		app.handlePeerScoreEvent(msg.From, psValidMessage)
 	}
+	if len(softCancelMessages) > 0 {
+		app.handleSoftCancelMessages(softCancelMessages)
+	}
+
+	return app.validateAndStoreReceivedOrders(ctx, orders, orderHashToMessage, orderHashToTopics, nil)
+}
 
-	// Next, we validate the orders.
-	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrders(ctx, orders, false, app.chainID)
+// validateAndStoreReceivedOrders validates and stores orders received from
+// peers and updates the peer scores accordingly. orderHashToMessage maps each
+// order to the message it was received in and orderHashToTopics to the topics
+// it was received on. retryAttempts contains the number of times that
+// validation has been retried before for orders from app.orderRetryQueue. It
+// is nil for orders which were just received.
+func (app *App) validateAndStoreReceivedOrders(ctx context.Context, orders []*zeroex.SignedOrder, orderHashToMessage map[common.Hash]*p2p.Message, orderHashToTopics map[common.Hash][]string, retryAttempts map[common.Hash]int) error {
+	// Reserve memory for the decoded orders while they are being validated.
+	// Depending on the policy, this either waits for other batches to finish
+	// or drops the orders that don't fit.
+	orders, reservedBytes, err := app.validationMemory.reserve(ctx, orders)
 	if err != nil {
 		return err
 	}
+	defer app.validationMemory.release(reservedBytes)
+
+	// Next, we validate the orders. Validation is traced for a sample of
+	// batches if configured.
+	isTraced := app.config.ValidationTraceSampleRate > 0 && rand.Float64() < app.config.ValidationTraceSampleRate
+	if isTraced {
+		ctx = ordervalidator.WithTracing(ctx)
+	}
+	validationResults, err := app.validateAndStoreOrders(ctx, orders, false)
+	if err != nil {
+		return err
+	}
+	if isTraced {
+		logValidationTraces(validationResults)
+	}
 
 	// Store any valid orders and update the peer scores.
 	for _, acceptedOrderInfo := range validationResults.Accepted {
+		if topics := orderHashToTopics[acceptedOrderInfo.OrderHash]; len(topics) > 0 {
+			if err := app.orderWatcher.AddOrderTopics(acceptedOrderInfo.OrderHash, topics); err != nil {
+				// This is not a critical error. It only means that we might not know
+				// about every topic the order was received on.
+				log.WithFields(map[string]interface{}{
+					"error":     err.Error(),
+					"orderHash": acceptedOrderInfo.OrderHash.Hex(),
+					"topics":    topics,
+				}).Debug("could not associate topics with order")
+			}
+		}
 		// If the order isn't new, we don't log it's receipt or adjust peer scores
 		if !acceptedOrderInfo.IsNew {
 			continue
 		}
 		msg := orderHashToMessage[acceptedOrderInfo.OrderHash]
+		if msg.Signer != "" {
+			if err := app.db.SetOrderSigner(acceptedOrderInfo.OrderHash, msg.Signer); err != nil {
+				// This is not a critical error. It only means that the order
+				// can't be attributed to the node which published it.
+				log.WithFields(map[string]interface{}{
+					"error":     err.Error(),
+					"orderHash": acceptedOrderInfo.OrderHash.Hex(),
+					"signer":    msg.Signer.String(),
+				}).Debug("could not record signer of order")
+			}
+		}
 		// If we've reached this point, the message is valid, we were able to
 		// decode it into an order and check that this order is valid. Update
 		// peer scores accordingly.
 		log.WithFields(map[string]interface{}{
 			"orderHash": acceptedOrderInfo.OrderHash.Hex(),
 			"from":      msg.From.String(),
+			"signer":    msg.Signer.String(),
 			"protocol":  "GossipSub",
 		}).Info("received new valid order from peer")
 		log.WithFields(map[string]interface{}{
@@ -83,6 +176,7 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			"protocol":  "GossipSub",
 		}).Trace("all fields for new valid order received from peer")
 		app.handlePeerScoreEvent(msg.From, psOrderStored)
+		app.recordOrderContribution(msg.ReceivedFrom)
 	}
 
 	// We don't store invalid orders, but in some cases still need to update peer
@@ -92,11 +186,35 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 		log.WithFields(map[string]interface{}{
 			"rejectedOrderInfo": rejectedOrderInfo,
 			"from":              msg.From.String(),
+			"signer":            msg.Signer.String(),
 		}).Trace("not storing rejected order received from peer")
+		if rejectedOrderInfo.Status.Code == ordervalidator.ROOrderPolicyRejected.Code {
+			// Don't incur a negative score for orders which are rejected by our own
+			// order policy since they may be valid for the rest of the network.
+			continue
+		}
+		if isRetriableRejection(rejectedOrderInfo.Status) {
+			retry := &orderRetry{
+				order:    rejectedOrderInfo.SignedOrder,
+				msg:      msg,
+				topics:   orderHashToTopics[rejectedOrderInfo.OrderHash],
+				attempts: retryAttempts[rejectedOrderInfo.OrderHash],
+			}
+			if !app.orderRetryQueue.add(rejectedOrderInfo.OrderHash, retry) {
+				log.WithFields(map[string]interface{}{
+					"orderHash": rejectedOrderInfo.OrderHash.Hex(),
+					"status":    rejectedOrderInfo.Status.Code,
+					"attempts":  retry.attempts,
+				}).Debug("dropped order rejected for a retriable reason")
+			}
+		}
 		switch rejectedOrderInfo.Status {
-		case ordervalidator.ROInternalError, ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROCoordinatorRequestFailed, ordervalidator.RODatabaseFullOfOrders:
+		case ordervalidator.ROInternalError, ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROValidationTimedOut, ordervalidator.ROCoordinatorRequestFailed, ordervalidator.RODatabaseFullOfOrders:
 			// Don't incur a negative score for these status types (it might not be
 			// their fault).
+		case ordervalidator.ROFeeRecipientNotAllowed, ordervalidator.ROMaxFeeExceeded, ordervalidator.ROOrderTooSmall:
+			// Don't incur a negative score for orders which are rejected by our own
+			// fee or size policy since they are valid for the rest of the network.
 		default:
 			// For other status types, we need to update the peer's score
 			app.handlePeerScoreEvent(msg.From, psInvalidMessage)
@@ -105,6 +223,25 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 	return nil
 }
 
+// logValidationTraces logs the validation trace for each order in results.
+func logValidationTraces(results *ordervalidator.ValidationResults) {
+	for _, acceptedOrderInfo := range results.Accepted {
+		log.WithFields(map[string]interface{}{
+			"orderHash": acceptedOrderInfo.OrderHash.Hex(),
+			"accepted":  true,
+			"trace":     acceptedOrderInfo.Trace,
+		}).Debug("validation trace for order received from peer")
+	}
+	for _, rejectedOrderInfo := range results.Rejected {
+		log.WithFields(map[string]interface{}{
+			"orderHash": rejectedOrderInfo.OrderHash.Hex(),
+			"accepted":  false,
+			"status":    rejectedOrderInfo.Status.Code,
+			"trace":     rejectedOrderInfo.Trace,
+		}).Debug("validation trace for order received from peer")
+	}
+}
+
 func validateMessageSize(message *p2p.Message) error {
 	if len(message.Data) > constants.MaxMessageSizeInBytes {
 		return constants.ErrMaxMessageSize
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/orderchecksum"
+	"github.com/0xProject/0x-mesh/core/ordersync"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// orderChecksumCacheTTL is how long the checksums of the stored orders are
+// cached. Computing them requires reading every order from the database, so
+// they are not recomputed for every request.
+const orderChecksumCacheTTL = 1 * time.Minute
+
+// orderChecksumCache computes and caches the checksums of the open orders for
+// each topic we use. It implements orderchecksum.OrderSet.
+type orderChecksumCache struct {
+	app        *App
+	mu         sync.Mutex
+	checksums  map[string]*orderchecksum.Checksum
+	computedAt time.Time
+}
+
+// Ensure that orderChecksumCache implements orderchecksum.OrderSet.
+var _ orderchecksum.OrderSet = &orderChecksumCache{}
+
+// Checksums implements orderchecksum.OrderSet. The checksum for each topic
+// only includes the open orders that match the order filter of the topic.
+func (c *orderChecksumCache) Checksums(topics []string) ([]*orderchecksum.Checksum, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.checksums == nil || time.Since(c.computedAt) > orderChecksumCacheTTL {
+		checksums, err := c.app.computeOrderChecksums()
+		if err != nil {
+			return nil, err
+		}
+		c.checksums = checksums
+		c.computedAt = time.Now()
+	}
+	results := []*orderchecksum.Checksum{}
+	for _, topic := range topics {
+		if checksum, found := c.checksums[topic]; found {
+			results = append(results, checksum)
+		}
+	}
+	return results, nil
+}
+
+// orderChecksumTopics returns the topics for which we compare order checksums
+// with our peers.
+func (app *App) orderChecksumTopics() []string {
+	topics := []string{app.orderFilter.Topic()}
+	for _, additionalFilter := range app.additionalOrderFilters {
+		topics = append(topics, additionalFilter.filter.Topic())
+	}
+	return topics
+}
+
+// computeOrderChecksums returns the checksums of the open orders for each of
+// the topics returned by orderChecksumTopics.
+func (app *App) computeOrderChecksums() (map[string]*orderchecksum.Checksum, error) {
+	orders, err := app.db.FindNotRemovedOrders()
+	if err != nil {
+		return nil, err
+	}
+	filters := []*orderfilter.Filter{app.orderFilter}
+	for _, additionalFilter := range app.additionalOrderFilters {
+		filters = append(filters, additionalFilter.filter)
+	}
+	return computeOrderChecksumsForFilters(orders, filters)
+}
+
+// computeOrderChecksumsForFilters returns the checksums of the given orders
+// for the topic of each filter. The checksum for a topic only includes the
+// orders that match its filter. Orders received on the topic of an additional
+// filter don't necessarily match the primary filter, and peers which only use
+// the primary filter never store them, so including them would make the
+// checksums of otherwise identical order sets diverge.
+func computeOrderChecksumsForFilters(orders []*meshdb.Order, filters []*orderfilter.Filter) (map[string]*orderchecksum.Checksum, error) {
+	checksums := make(map[string]*orderchecksum.Checksum, len(filters))
+	for _, filter := range filters {
+		orderHashes := []common.Hash{}
+		for _, order := range orders {
+			matches, err := filter.MatchOrder(order.SignedOrder)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				orderHashes = append(orderHashes, order.Hash)
+			}
+		}
+		topic := filter.Topic()
+		checksums[topic] = &orderchecksum.Checksum{
+			Topic:     topic,
+			NumOrders: len(orderHashes),
+			Checksum:  orderchecksum.Sum(orderHashes),
+		}
+	}
+	return checksums, nil
+}
+
+// resyncWithPeer is called when the orders for the given topics have diverged
+// from the orders stored by the given peer. Ordersync always uses our own order
+// filter, so we can only resync the primary topic.
+func (app *App) resyncWithPeer(ctx context.Context, peerID peer.ID, topics []string) {
+	primaryTopic := app.orderFilter.Topic()
+	for _, topic := range topics {
+		if topic != primaryTopic {
+			log.WithFields(log.Fields{
+				"peer":  peerID.Pretty(),
+				"topic": topic,
+			}).Debug("cannot resync orders for additional topic via ordersync")
+			continue
+		}
+		if err := app.ordersyncService.GetOrdersFromPeer(ctx, peerID); err == ordersync.ErrRequestsPaused {
+			log.WithField("peer", peerID.Pretty()).Debug("not resyncing orders with peer because ingestion is paused")
+		} else if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"peer":  peerID.Pretty(),
+			}).Warn("could not resync orders with peer via ordersync")
+		}
+	}
+}
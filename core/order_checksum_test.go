@@ -0,0 +1,60 @@
+// +build !js
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/core/orderchecksum"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeOrderChecksumsForFilters(t *testing.T) {
+	t.Parallel()
+
+	primaryMaker := common.HexToAddress("0xa3ece5d5b6319fa785efc10d3112769a46c6e149")
+	additionalMaker := common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb")
+	primaryFilter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149"}}}`, contractAddresses)
+	require.NoError(t, err)
+	additionalFilter, err := orderfilter.New(constants.TestChainID, `{"properties":{"makerAddress":{"const":"0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"}}}`, contractAddresses)
+	require.NoError(t, err)
+
+	primaryOrder := newChecksumTestOrder(t, primaryMaker, common.Hash{0x01})
+	additionalOrder := newChecksumTestOrder(t, additionalMaker, common.Hash{0x02})
+	// The order was received on the primary topic, but it only matches the
+	// additional filter.
+	additionalOrder.Topics = []string{primaryFilter.Topic()}
+
+	checksums, err := computeOrderChecksumsForFilters([]*meshdb.Order{primaryOrder, additionalOrder}, []*orderfilter.Filter{primaryFilter, additionalFilter})
+	require.NoError(t, err)
+	expected := map[string]*orderchecksum.Checksum{
+		primaryFilter.Topic(): {
+			Topic:     primaryFilter.Topic(),
+			NumOrders: 1,
+			Checksum:  orderchecksum.Sum([]common.Hash{primaryOrder.Hash}),
+		},
+		additionalFilter.Topic(): {
+			Topic:     additionalFilter.Topic(),
+			NumOrders: 1,
+			Checksum:  orderchecksum.Sum([]common.Hash{additionalOrder.Hash}),
+		},
+	}
+	assert.Equal(t, expected, checksums)
+}
+
+func newChecksumTestOrder(t *testing.T, makerAddress common.Address, hash common.Hash) *meshdb.Order {
+	signedOrder := &zeroex.SignedOrder{}
+	require.NoError(t, json.Unmarshal([]byte(`{"makerAddress":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149","takerAddress":"0x0000000000000000000000000000000000000000","makerAssetAmount":"100000000000000000000","takerAssetAmount":"100000000000000000000000","expirationTimeSeconds":"1559856615025","makerFee":"0","takerFee":"0","feeRecipientAddress":"0x0000000000000000000000000000000000000000","senderAddress":"0x0000000000000000000000000000000000000000","salt":"46108882540880341679561755865076495033942060608820537332859096815711589201849","makerAssetData":"0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498","takerAssetData":"0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2","makerFeeAssetData":"0x","takerFeeAssetData":"0x","exchangeAddress":"0x48bacb9266a570d521063ef5dd96e61686dbe788","chainId":1337,"signature":"0x1c52f75daa4bd2ad9e6e8a7c35adbd089d709e48ae86463f2abfafa3578747fafc264a04d02fa26227e90476d57bca94e24af32f1cc8da444bba21092ca56cd85603"}`), signedOrder))
+	signedOrder.MakerAddress = makerAddress
+	return &meshdb.Order{
+		Hash:        hash,
+		SignedOrder: signedOrder,
+	}
+}
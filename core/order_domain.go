@@ -0,0 +1,116 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	log "github.com/sirupsen/logrus"
+)
+
+// orderDomain contains the fields of an order which make up its EIP-712
+// domain. The signature of an order is only valid for the chain and exchange
+// contract in its domain.
+type orderDomain struct {
+	ChainID         *big.Int       `json:"chainId"`
+	ExchangeAddress common.Address `json:"exchangeAddress"`
+}
+
+func newOrderDomain(order *zeroex.SignedOrder) orderDomain {
+	return orderDomain{
+		ChainID:         order.ChainID,
+		ExchangeAddress: order.ExchangeAddress,
+	}
+}
+
+// checkOrderDomain returns ROIncorrectChain or ROIncorrectExchangeAddress if
+// the domain of an order doesn't match the chain the node is configured for.
+// Such orders can never be valid, but would otherwise only fail JSON Schema
+// validation or signature validation, which doesn't tell the maker (or the
+// operator of the node) what is wrong. The second return value is false if the
+// domain matches.
+func (app *App) checkOrderDomain(domain orderDomain) (ordervalidator.RejectedOrderStatus, bool) {
+	if domain.ChainID == nil || domain.ChainID.Cmp(big.NewInt(int64(app.config.EthereumChainID))) != 0 {
+		return ordervalidator.ROIncorrectChain, true
+	}
+	if domain.ExchangeAddress != app.contractAddresses.Exchange {
+		return ordervalidator.ROIncorrectExchangeAddress, true
+	}
+	return ordervalidator.RejectedOrderStatus{}, false
+}
+
+// recordMismatchedDomainOrder counts and logs an order which was rejected
+// because of its domain. The count is reported in the mismatchedDomainOrders
+// stat.
+func (app *App) recordMismatchedDomainOrder(domain orderDomain, status ordervalidator.RejectedOrderStatus, source string) {
+	atomic.AddUint64(&app.mismatchedDomainOrders, 1)
+	log.WithFields(log.Fields{
+		"chainId":                 domain.ChainID,
+		"exchangeAddress":         domain.ExchangeAddress.Hex(),
+		"expectedChainId":         app.config.EthereumChainID,
+		"expectedExchangeAddress": app.contractAddresses.Exchange.Hex(),
+		"status":                  status.Code,
+		"source":                  source,
+	}).Trace("rejected order for a different chain or exchange")
+}
+
+// newMismatchedDomainRejection returns the RejectedOrderInfo for an order
+// submitted via AddOrders which was rejected because of its domain. The order
+// hash is computed using the domain of the order itself, which is the hash
+// that the maker would have computed.
+func newMismatchedDomainRejection(signedOrderBytes []byte, status ordervalidator.RejectedOrderStatus) *ordervalidator.RejectedOrderInfo {
+	rejectedOrderInfo := &ordervalidator.RejectedOrderInfo{
+		Kind:   ordervalidator.MeshValidation,
+		Status: status,
+	}
+	signedOrder := &zeroex.SignedOrder{}
+	if err := signedOrder.UnmarshalJSON(signedOrderBytes); err != nil {
+		return rejectedOrderInfo
+	}
+	rejectedOrderInfo.SignedOrder = signedOrder
+	// The order hash can't be computed without a chain ID.
+	if signedOrder.ChainID != nil {
+		if orderHash, err := signedOrder.ComputeOrderHash(); err == nil {
+			rejectedOrderInfo.OrderHash = orderHash
+		}
+	}
+	return rejectedOrderInfo
+}
+
+// countMismatchedDomainOrders counts the orders in results which were rejected
+// by the OrderWatcher because of their domain.
+func (app *App) countMismatchedDomainOrders(results *ordervalidator.ValidationResults) {
+	for _, rejectedOrderInfo := range results.Rejected {
+		switch rejectedOrderInfo.Status.Code {
+		case ordervalidator.ROIncorrectChain.Code, ordervalidator.ROIncorrectExchangeAddress.Code:
+			atomic.AddUint64(&app.mismatchedDomainOrders, 1)
+		}
+	}
+}
+
+// detectMismatchedDomainMessages wraps the given GossipSub validator so that
+// messages which it rejects because they contain an order for a different
+// chain or exchange are counted and logged. Such messages are typically sent by
+// peers which are misconfigured, so they are rejected the same way as before.
+func (app *App) detectMismatchedDomainMessages(validator pubsub.Validator) pubsub.Validator {
+	return func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+		if validator(ctx, sender, msg) {
+			return true
+		}
+		order, err := encoding.RawMessageToOrder(msg.Data)
+		if err != nil {
+			return false
+		}
+		domain := newOrderDomain(order)
+		if status, mismatched := app.checkOrderDomain(domain); mismatched {
+			app.recordMismatchedDomainOrder(domain, status, "GossipSub")
+		}
+		return false
+	}
+}
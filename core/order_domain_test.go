@@ -0,0 +1,116 @@
+// +build !js
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOrderDomain(t *testing.T) {
+	t.Parallel()
+
+	addresses := contractAddresses
+	app := &App{
+		config:            Config{EthereumChainID: constants.TestChainID},
+		contractAddresses: &addresses,
+	}
+	testCases := []struct {
+		note               string
+		domain             orderDomain
+		expectedMismatched bool
+		expectedStatus     ordervalidator.RejectedOrderStatus
+	}{
+		{
+			note: "matching domain",
+			domain: orderDomain{
+				ChainID:         big.NewInt(constants.TestChainID),
+				ExchangeAddress: contractAddresses.Exchange,
+			},
+		},
+		{
+			note: "wrong chain ID",
+			domain: orderDomain{
+				ChainID:         big.NewInt(1),
+				ExchangeAddress: contractAddresses.Exchange,
+			},
+			expectedMismatched: true,
+			expectedStatus:     ordervalidator.ROIncorrectChain,
+		},
+		{
+			note: "missing chain ID",
+			domain: orderDomain{
+				ExchangeAddress: contractAddresses.Exchange,
+			},
+			expectedMismatched: true,
+			expectedStatus:     ordervalidator.ROIncorrectChain,
+		},
+		{
+			note: "wrong exchange address",
+			domain: orderDomain{
+				ChainID:         big.NewInt(constants.TestChainID),
+				ExchangeAddress: common.HexToAddress("0x61935cbdd02287b511119ddb11aeb42f1593b7ef"),
+			},
+			expectedMismatched: true,
+			expectedStatus:     ordervalidator.ROIncorrectExchangeAddress,
+		},
+	}
+	for _, testCase := range testCases {
+		status, mismatched := app.checkOrderDomain(testCase.domain)
+		assert.Equal(t, testCase.expectedMismatched, mismatched, testCase.note)
+		assert.Equal(t, testCase.expectedStatus, status, testCase.note)
+	}
+
+	results := &ordervalidator.ValidationResults{
+		Rejected: []*ordervalidator.RejectedOrderInfo{
+			{Status: ordervalidator.ROIncorrectChain},
+			{Status: ordervalidator.ROIncorrectExchangeAddress},
+			{Status: ordervalidator.ROExpired},
+		},
+	}
+	app.countMismatchedDomainOrders(results)
+	assert.Equal(t, uint64(2), app.mismatchedDomainOrders)
+}
+
+func TestNewMismatchedDomainRejection(t *testing.T) {
+	t.Parallel()
+
+	signedOrder, err := zeroex.SignTestOrder(&zeroex.Order{
+		ChainID:               big.NewInt(1),
+		MakerAddress:          constants.GanacheAccount0,
+		MakerAssetData:        common.FromHex("0xf47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		MakerAssetAmount:      math.MustParseBig256("1000"),
+		MakerFee:              math.MustParseBig256("0"),
+		TakerAssetData:        common.FromHex("0xf47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082"),
+		TakerAssetAmount:      math.MustParseBig256("2000"),
+		TakerFee:              math.MustParseBig256("0"),
+		ExchangeAddress:       contractAddresses.Exchange,
+		ExpirationTimeSeconds: math.MustParseBig256("1574532801"),
+		Salt:                  math.MustParseBig256("1548619145450"),
+	})
+	require.NoError(t, err)
+	expectedOrderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	signedOrderBytes, err := signedOrder.MarshalJSON()
+	require.NoError(t, err)
+
+	rejectedOrderInfo := newMismatchedDomainRejection(signedOrderBytes, ordervalidator.ROIncorrectChain)
+	assert.Equal(t, expectedOrderHash, rejectedOrderInfo.OrderHash)
+	assert.Equal(t, ordervalidator.MeshValidation, rejectedOrderInfo.Kind)
+	assert.Equal(t, ordervalidator.ROIncorrectChain, rejectedOrderInfo.Status)
+	require.NotNil(t, rejectedOrderInfo.SignedOrder)
+	assert.Equal(t, signedOrder.Salt, rejectedOrderInfo.SignedOrder.Salt)
+
+	// Orders which can't be decoded are still rejected, but without a hash.
+	rejectedOrderInfo = newMismatchedDomainRejection([]byte(`{"chainId":1}`), ordervalidator.ROIncorrectChain)
+	assert.Equal(t, common.Hash{}, rejectedOrderInfo.OrderHash)
+	assert.Equal(t, ordervalidator.ROIncorrectChain, rejectedOrderInfo.Status)
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/event"
+	log "github.com/sirupsen/logrus"
+)
+
+// OrderEventsOverflowPolicy determines what happens when the buffer of an
+// order event subscription is full because the subscriber can't keep up.
+type OrderEventsOverflowPolicy string
+
+const (
+	// OrderEventsBlock stops delivering order events to every subscriber until
+	// the slow subscriber catches up. No order events are dropped, but a slow
+	// subscriber delays order events for all other subscribers and blocks the
+	// OrderWatcher, which delays order validation and block processing.
+	OrderEventsBlock OrderEventsOverflowPolicy = "block"
+	// OrderEventsDropOldest drops the oldest buffered order events of the slow
+	// subscriber to make room for the new ones.
+	OrderEventsDropOldest OrderEventsOverflowPolicy = "drop-oldest"
+	// OrderEventsDropNew drops the new order events for the slow subscriber
+	// and leaves its buffer unchanged.
+	OrderEventsDropNew OrderEventsOverflowPolicy = "drop-new"
+)
+
+// ParseOrderEventsOverflowPolicy parses the given string as an
+// OrderEventsOverflowPolicy. An empty string is parsed as OrderEventsBlock.
+func ParseOrderEventsOverflowPolicy(s string) (OrderEventsOverflowPolicy, error) {
+	switch OrderEventsOverflowPolicy(s) {
+	case "", OrderEventsBlock:
+		return OrderEventsBlock, nil
+	case OrderEventsDropOldest:
+		return OrderEventsDropOldest, nil
+	case OrderEventsDropNew:
+		return OrderEventsDropNew, nil
+	default:
+		return "", fmt.Errorf("invalid order events overflow policy %q (expected %q, %q or %q)", s, OrderEventsBlock, OrderEventsDropOldest, OrderEventsDropNew)
+	}
+}
+
+// orderEventSource is implemented by *orderwatch.Watcher.
+type orderEventSource interface {
+	Subscribe(sink chan<- []*zeroex.OrderEvent) event.Subscription
+}
+
+// subscribeBufferedOrderEvents subscribes sink to the order events emitted by
+// source. Up to bufferSize batches of order events are buffered for sink in
+// addition to the capacity of sink. What happens when the buffer is full
+// depends on policy. The number of dropped order events is added to dropped,
+// which must only be accessed atomically.
+func subscribeBufferedOrderEvents(source orderEventSource, sink chan<- []*zeroex.OrderEvent, bufferSize int, policy OrderEventsOverflowPolicy, dropped *uint64) event.Subscription {
+	// The relay channel is unbuffered. Order events are buffered in buffer
+	// instead, so that they can be dropped according to policy.
+	relay := make(chan []*zeroex.OrderEvent)
+	sourceSub := source.Subscribe(relay)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sourceSub.Unsubscribe()
+
+		buffer := [][]*zeroex.OrderEvent{}
+		// droppedInBurst is the number of order events dropped since the
+		// buffer was last empty. It is used to only log once per burst.
+		droppedInBurst := 0
+		for {
+			in := relay
+			if len(buffer) >= bufferSize && policy == OrderEventsBlock {
+				// Stop receiving until the subscriber catches up, which
+				// applies backpressure to the OrderWatcher.
+				in = nil
+			}
+			var out chan<- []*zeroex.OrderEvent
+			var next []*zeroex.OrderEvent
+			if len(buffer) > 0 {
+				out = sink
+				next = buffer[0]
+			}
+			select {
+			case <-quit:
+				return nil
+			case err := <-sourceSub.Err():
+				return err
+			case orderEvents := <-in:
+				if len(buffer) < bufferSize {
+					buffer = append(buffer, orderEvents)
+					continue
+				}
+				droppedEvents := orderEvents
+				if policy == OrderEventsDropOldest {
+					droppedEvents = buffer[0]
+					buffer[0] = nil
+					buffer = append(buffer[1:], orderEvents)
+				}
+				atomic.AddUint64(dropped, uint64(len(droppedEvents)))
+				if droppedInBurst == 0 {
+					log.WithFields(log.Fields{
+						"bufferSize": bufferSize,
+						"policy":     policy,
+					}).Warn("dropping order events because a subscriber can't keep up")
+				}
+				droppedInBurst += len(droppedEvents)
+			case out <- next:
+				buffer[0] = nil
+				buffer = buffer[1:]
+				if len(buffer) == 0 && droppedInBurst > 0 {
+					log.WithField("droppedOrderEvents", droppedInBurst).Info("order event subscriber caught up after order events were dropped")
+					droppedInBurst = 0
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,119 @@
+// +build !js
+
+package core
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedOrderEventSource is an orderEventSource backed by an event.Feed.
+type feedOrderEventSource struct {
+	feed event.Feed
+}
+
+func (s *feedOrderEventSource) Subscribe(sink chan<- []*zeroex.OrderEvent) event.Subscription {
+	return s.feed.Subscribe(sink)
+}
+
+func TestParseOrderEventsOverflowPolicy(t *testing.T) {
+	t.Parallel()
+
+	for s, expected := range map[string]OrderEventsOverflowPolicy{
+		"":            OrderEventsBlock,
+		"block":       OrderEventsBlock,
+		"drop-oldest": OrderEventsDropOldest,
+		"drop-new":    OrderEventsDropNew,
+	} {
+		actual, err := ParseOrderEventsOverflowPolicy(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	_, err := ParseOrderEventsOverflowPolicy("drop")
+	assert.Error(t, err)
+}
+
+func TestSubscribeBufferedOrderEvents(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		policy                     OrderEventsOverflowPolicy
+		expectedSequenceNumbers    []uint64
+		expectedDroppedOrderEvents uint64
+	}{
+		{
+			policy:                     OrderEventsDropOldest,
+			expectedSequenceNumbers:    []uint64{3, 4},
+			expectedDroppedOrderEvents: 2,
+		},
+		{
+			policy:                     OrderEventsDropNew,
+			expectedSequenceNumbers:    []uint64{1, 2},
+			expectedDroppedOrderEvents: 2,
+		},
+	}
+	for _, testCase := range testCases {
+		source := &feedOrderEventSource{}
+		sink := make(chan []*zeroex.OrderEvent)
+		var dropped uint64
+		subscription := subscribeBufferedOrderEvents(source, sink, 2, testCase.policy, &dropped)
+
+		// Nothing is received from sink, so only two batches fit in the
+		// buffer and the others are dropped. Sending must not block.
+		for sequenceNumber := uint64(1); sequenceNumber <= 4; sequenceNumber++ {
+			source.feed.Send([]*zeroex.OrderEvent{{SequenceNumber: sequenceNumber}})
+		}
+		for _, expectedSequenceNumber := range testCase.expectedSequenceNumbers {
+			select {
+			case orderEvents := <-sink:
+				require.Len(t, orderEvents, 1)
+				assert.Equal(t, expectedSequenceNumber, orderEvents[0].SequenceNumber, string(testCase.policy))
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for order events")
+			}
+		}
+		assert.Equal(t, testCase.expectedDroppedOrderEvents, atomic.LoadUint64(&dropped), string(testCase.policy))
+		subscription.Unsubscribe()
+	}
+}
+
+func TestSubscribeBufferedOrderEventsBlock(t *testing.T) {
+	t.Parallel()
+
+	source := &feedOrderEventSource{}
+	sink := make(chan []*zeroex.OrderEvent)
+	var dropped uint64
+	subscription := subscribeBufferedOrderEvents(source, sink, 1, OrderEventsBlock, &dropped)
+	defer subscription.Unsubscribe()
+
+	// The first batch is buffered, so sending the second one blocks until
+	// the subscriber receives from sink.
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for sequenceNumber := uint64(1); sequenceNumber <= 3; sequenceNumber++ {
+			source.feed.Send([]*zeroex.OrderEvent{{SequenceNumber: sequenceNumber}})
+		}
+	}()
+	select {
+	case <-sent:
+		t.Fatal("sending order events did not block")
+	case <-time.After(100 * time.Millisecond):
+	}
+	for sequenceNumber := uint64(1); sequenceNumber <= 3; sequenceNumber++ {
+		select {
+		case orderEvents := <-sink:
+			assert.Equal(t, sequenceNumber, orderEvents[0].SequenceNumber)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for order events")
+		}
+	}
+	<-sent
+	assert.Equal(t, uint64(0), atomic.LoadUint64(&dropped))
+}
@@ -0,0 +1,212 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// validateAndStoreFunc validates the given orders and stores the valid ones.
+type validateAndStoreFunc func(ctx context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error)
+
+// pinOrdersFunc pins the given orders which were already stored.
+type pinOrdersFunc func(orderHashes []common.Hash) error
+
+// inFlightOrder is an order which is currently being validated. done is closed
+// once validation is finished, after which exactly one of accepted and
+// rejected is set unless validation failed with an error. accepted and
+// rejected are copies of the results which may be shared by several callers
+// and must not be modified. pinned is whether the order is stored as a pinned
+// order if it is valid.
+type inFlightOrder struct {
+	done     chan struct{}
+	pinned   bool
+	accepted *ordervalidator.AcceptedOrderInfo
+	rejected *ordervalidator.RejectedOrderInfo
+}
+
+// duplicateOrder is an order which was submitted while an identical order was
+// already in flight.
+type duplicateOrder struct {
+	order    *zeroex.SignedOrder
+	inFlight *inFlightOrder
+}
+
+// orderIngestion is the single entry point through which orders received via
+// AddOrders, GossipSub and ordersync are validated and stored. It deduplicates
+// identical orders which arrive via several of these paths at the same time so
+// that each order is only validated once at a time. Duplicate submissions wait
+// for the in-flight validation and share its result.
+type orderIngestion struct {
+	mu       sync.Mutex
+	inFlight map[common.Hash]*inFlightOrder
+	// deduped is the total number of order submissions which were not
+	// validated because the same order was already being validated. It is
+	// accessed atomically.
+	deduped uint64
+}
+
+func newOrderIngestion() *orderIngestion {
+	return &orderIngestion{
+		inFlight: map[common.Hash]*inFlightOrder{},
+	}
+}
+
+// validateAndStore validates and stores the given orders using validate,
+// except for orders which are already being validated by another caller. For
+// those, it waits for the other validation to finish and returns a copy of its
+// result. Since the order was not stored as a result of this call, copies of
+// accepted orders always have IsNew set to false and copies don't include a
+// validation trace. If pinned is true and an order was stored by another caller
+// which didn't pin it, the order is pinned with pin afterwards.
+func (oi *orderIngestion) validateAndStore(ctx context.Context, orders []*zeroex.SignedOrder, pinned bool, validate validateAndStoreFunc, pin pinOrdersFunc) (*ordervalidator.ValidationResults, error) {
+	owned := map[common.Hash]*inFlightOrder{}
+	ordersToValidate := make([]*zeroex.SignedOrder, 0, len(orders))
+	duplicates := []duplicateOrder{}
+	oi.mu.Lock()
+	for _, order := range orders {
+		orderHash, err := order.ComputeOrderHash()
+		if err != nil {
+			// Let validate reject the order.
+			ordersToValidate = append(ordersToValidate, order)
+			continue
+		}
+		if existing, found := oi.inFlight[orderHash]; found {
+			duplicates = append(duplicates, duplicateOrder{order: order, inFlight: existing})
+			continue
+		}
+		inFlight := &inFlightOrder{done: make(chan struct{}), pinned: pinned}
+		oi.inFlight[orderHash] = inFlight
+		owned[orderHash] = inFlight
+		ordersToValidate = append(ordersToValidate, order)
+	}
+	oi.mu.Unlock()
+
+	results, err := validate(ctx, ordersToValidate)
+	// Note: The results for the orders we own must be published before
+	// waiting for the duplicates. Otherwise two callers which each wait for an
+	// order owned by the other could deadlock.
+	oi.finish(owned, results)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) == 0 {
+		return results, nil
+	}
+
+	retry := []*zeroex.SignedOrder{}
+	orderHashesToPin := []common.Hash{}
+	for _, duplicate := range duplicates {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-duplicate.inFlight.done:
+		}
+		switch {
+		case duplicate.inFlight.accepted != nil:
+			accepted := *duplicate.inFlight.accepted
+			results.Accepted = append(results.Accepted, &accepted)
+			if pinned && !duplicate.inFlight.pinned {
+				orderHashesToPin = append(orderHashesToPin, accepted.OrderHash)
+			}
+		case duplicate.inFlight.rejected != nil:
+			rejected := *duplicate.inFlight.rejected
+			results.Rejected = append(results.Rejected, &rejected)
+		default:
+			// The other validation failed with an error (e.g. because its
+			// context was canceled) so we have to validate the order ourselves.
+			retry = append(retry, duplicate.order)
+			continue
+		}
+		atomic.AddUint64(&oi.deduped, 1)
+	}
+	if len(orderHashesToPin) > 0 {
+		if err := pin(orderHashesToPin); err != nil {
+			return nil, err
+		}
+	}
+	if len(retry) > 0 {
+		retryResults, err := oi.validateAndStore(ctx, retry, pinned, validate, pin)
+		if err != nil {
+			return nil, err
+		}
+		results.Accepted = append(results.Accepted, retryResults.Accepted...)
+		results.Rejected = append(results.Rejected, retryResults.Rejected...)
+	}
+	return results, nil
+}
+
+// finish records copies of the results for the given in-flight orders, removes
+// them from oi.inFlight and wakes up any callers waiting for them. results may
+// be nil if validation failed.
+func (oi *orderIngestion) finish(owned map[common.Hash]*inFlightOrder, results *ordervalidator.ValidationResults) {
+	if len(owned) == 0 {
+		return
+	}
+	oi.mu.Lock()
+	defer oi.mu.Unlock()
+	if results != nil {
+		for _, acceptedOrderInfo := range results.Accepted {
+			if inFlight, found := owned[acceptedOrderInfo.OrderHash]; found {
+				accepted := *acceptedOrderInfo
+				accepted.IsNew = false
+				accepted.Trace = nil
+				inFlight.accepted = &accepted
+			}
+		}
+		for _, rejectedOrderInfo := range results.Rejected {
+			if inFlight, found := owned[rejectedOrderInfo.OrderHash]; found {
+				rejected := *rejectedOrderInfo
+				rejected.Trace = nil
+				inFlight.rejected = &rejected
+			}
+		}
+	}
+	for orderHash, inFlight := range owned {
+		delete(oi.inFlight, orderHash)
+		close(inFlight.done)
+	}
+}
+
+// dedupedSubmissions returns the total number of order submissions which were
+// deduplicated.
+func (oi *orderIngestion) dedupedSubmissions() uint64 {
+	return atomic.LoadUint64(&oi.deduped)
+}
+
+// validateAndStoreOrders validates the given orders and stores the valid ones
+// in the OrderWatcher. Orders which are already being validated (e.g. because
+// they were received via GossipSub while also being added via AddOrders) are
+// not validated again. See orderIngestion.validateAndStore.
+func (app *App) validateAndStoreOrders(ctx context.Context, orders []*zeroex.SignedOrder, pinned bool) (*ordervalidator.ValidationResults, error) {
+	ctx = app.withStageTimeouts(ctx)
+	return app.orderIngestion.validateAndStore(ctx, orders, pinned, func(ctx context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+		results, err := app.orderWatcher.ValidateAndStoreValidOrders(ctx, orders, pinned, app.chainID)
+		if err != nil {
+			return nil, err
+		}
+		app.countMismatchedDomainOrders(results)
+		return results, nil
+	}, app.orderWatcher.PinOrders)
+}
+
+// withStageTimeouts returns a copy of ctx which carries the stage timeouts from
+// the config. Stage timeouts which were already set on ctx (see
+// ordervalidator.WithStageTimeouts) take precedence.
+func (app *App) withStageTimeouts(ctx context.Context) context.Context {
+	timeouts := ordervalidator.StageTimeoutsFromContext(ctx)
+	if timeouts.SchemaValidation == 0 {
+		timeouts.SchemaValidation = app.config.SchemaValidationTimeout
+	}
+	if timeouts.EthValidation == 0 {
+		timeouts.EthValidation = app.config.EthValidationTimeout
+	}
+	if timeouts.DBWrite == 0 {
+		timeouts.DBWrite = app.config.DBWriteTimeout
+	}
+	return ordervalidator.WithStageTimeouts(ctx, timeouts)
+}
@@ -0,0 +1,195 @@
+// +build !js
+
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderIngestionDedupsInFlightOrders(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithDistinctHashes(t, 2)
+	ingestion := newOrderIngestion()
+
+	// The first validation blocks until it is released so that the same order
+	// is in flight when it is submitted again.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	firstDone := make(chan *ordervalidator.ValidationResults)
+	go func() {
+		results, err := ingestion.validateAndStore(context.Background(), orders[:1], false, func(_ context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			close(started)
+			<-release
+			return acceptAll(t, orders), nil
+		}, nil)
+		require.NoError(t, err)
+		firstDone <- results
+	}()
+	<-started
+
+	secondDone := make(chan *ordervalidator.ValidationResults)
+	validated := make(chan []*zeroex.SignedOrder, 1)
+	go func() {
+		results, err := ingestion.validateAndStore(context.Background(), orders, false, func(_ context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			validated <- orders
+			return acceptAll(t, orders), nil
+		}, nil)
+		require.NoError(t, err)
+		secondDone <- results
+	}()
+
+	// Only the order which isn't already in flight should be validated again.
+	assert.Equal(t, orders[1:], <-validated)
+	select {
+	case <-secondDone:
+		t.Fatal("validateAndStore returned before the in-flight order was validated")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	firstResults := <-firstDone
+	require.Len(t, firstResults.Accepted, 1)
+	assert.True(t, firstResults.Accepted[0].IsNew)
+	secondResults := <-secondDone
+	require.Len(t, secondResults.Accepted, 2)
+	assert.Equal(t, orders[1], secondResults.Accepted[0].SignedOrder)
+	assert.True(t, secondResults.Accepted[0].IsNew)
+	assert.Equal(t, orders[0], secondResults.Accepted[1].SignedOrder)
+	assert.False(t, secondResults.Accepted[1].IsNew, "deduped order should not be new")
+	assert.Equal(t, uint64(1), ingestion.dedupedSubmissions())
+	assert.Empty(t, ingestion.inFlight)
+}
+
+func TestOrderIngestionPinsDedupedOrders(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithDistinctHashes(t, 1)
+	ingestion := newOrderIngestion()
+
+	// An unpinned validation of the order is in flight when the order is
+	// submitted again with pinned set to true.
+	started := make(chan struct{})
+	release := make(chan struct{})
+	firstDone := make(chan struct{})
+	go func() {
+		_, err := ingestion.validateAndStore(context.Background(), orders, false, func(_ context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			close(started)
+			<-release
+			return acceptAll(t, orders), nil
+		}, nil)
+		require.NoError(t, err)
+		close(firstDone)
+	}()
+	<-started
+
+	secondDone := make(chan *ordervalidator.ValidationResults)
+	validated := make(chan []*zeroex.SignedOrder, 1)
+	pinned := make(chan []common.Hash, 1)
+	go func() {
+		results, err := ingestion.validateAndStore(context.Background(), orders, true, func(_ context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			validated <- orders
+			return acceptAll(t, orders), nil
+		}, func(orderHashes []common.Hash) error {
+			pinned <- orderHashes
+			return nil
+		})
+		require.NoError(t, err)
+		secondDone <- results
+	}()
+	// The order is deduped, so there is nothing left to validate.
+	assert.Empty(t, <-validated)
+	close(release)
+	<-firstDone
+
+	// The shared result must not lose the pin of the second submission.
+	results := <-secondDone
+	require.Len(t, results.Accepted, 1)
+	assert.Equal(t, []common.Hash{results.Accepted[0].OrderHash}, <-pinned)
+	assert.Equal(t, uint64(1), ingestion.dedupedSubmissions())
+}
+
+func TestOrderIngestionRevalidatesAfterError(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithDistinctHashes(t, 1)
+	ingestion := newOrderIngestion()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	expectedErr := errors.New("validation failed")
+	firstDone := make(chan error)
+	go func() {
+		_, err := ingestion.validateAndStore(context.Background(), orders, false, func(context.Context, []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			close(started)
+			<-release
+			return nil, expectedErr
+		}, nil)
+		firstDone <- err
+	}()
+	<-started
+
+	secondDone := make(chan *ordervalidator.ValidationResults)
+	go func() {
+		results, err := ingestion.validateAndStore(context.Background(), orders, false, func(_ context.Context, orders []*zeroex.SignedOrder) (*ordervalidator.ValidationResults, error) {
+			return acceptAll(t, orders), nil
+		}, nil)
+		require.NoError(t, err)
+		secondDone <- results
+	}()
+	close(release)
+	assert.Equal(t, expectedErr, <-firstDone)
+
+	// Since the in-flight validation failed, the duplicate should have been
+	// validated on its own.
+	results := <-secondDone
+	require.Len(t, results.Accepted, 1)
+	assert.True(t, results.Accepted[0].IsNew)
+	assert.Equal(t, uint64(0), ingestion.dedupedSubmissions())
+}
+
+func newOrdersWithDistinctHashes(t *testing.T, count int) []*zeroex.SignedOrder {
+	orders := make([]*zeroex.SignedOrder, count)
+	for i := range orders {
+		orders[i] = &zeroex.SignedOrder{
+			Order: zeroex.Order{
+				ChainID:               big.NewInt(1337),
+				MakerAssetAmount:      big.NewInt(1),
+				MakerFee:              big.NewInt(0),
+				TakerAssetAmount:      big.NewInt(1),
+				TakerFee:              big.NewInt(0),
+				ExpirationTimeSeconds: big.NewInt(0),
+				Salt:                  big.NewInt(int64(i)),
+			},
+		}
+		// Compute the hashes up front so that they are not computed
+		// concurrently by the tests.
+		_, err := orders[i].ComputeOrderHash()
+		require.NoError(t, err)
+	}
+	return orders
+}
+
+func acceptAll(t *testing.T, orders []*zeroex.SignedOrder) *ordervalidator.ValidationResults {
+	results := &ordervalidator.ValidationResults{}
+	for _, order := range orders {
+		orderHash, err := order.ComputeOrderHash()
+		require.NoError(t, err)
+		results.Accepted = append(results.Accepted, &ordervalidator.AcceptedOrderInfo{
+			OrderHash:   orderHash,
+			SignedOrder: order,
+			IsNew:       true,
+		})
+	}
+	return results
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxOrderRetryAttempts is the maximum number of times that validation is
+	// retried for an order which was rejected for a retriable reason.
+	maxOrderRetryAttempts = 5
+	// minOrderRetryBackoff is how long to wait before retrying the validation
+	// of an order for the first time. It doubles with each attempt.
+	minOrderRetryBackoff = 5 * time.Second
+	// maxOrderRetryBackoff is the maximum amount of time to wait before
+	// retrying the validation of an order.
+	maxOrderRetryBackoff = 2 * time.Minute
+	// maxOrderRetryQueueSize is the maximum number of orders waiting to be
+	// retried. Orders rejected while the queue is full are dropped.
+	maxOrderRetryQueueSize = 1000
+	// orderRetryCheckInterval is how often to check for orders which are due
+	// to be retried.
+	orderRetryCheckInterval = 1 * time.Second
+)
+
+// isRetriableRejection returns true if an order which was rejected with the
+// given status might be accepted if it is validated again later, i.e. if it
+// was rejected because of a transient issue such as an Ethereum RPC request
+// which timed out or was rate limited.
+func isRetriableRejection(status ordervalidator.RejectedOrderStatus) bool {
+	switch status {
+	case ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROValidationTimedOut, ordervalidator.ROCoordinatorRequestFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// orderRetry is an order received from a peer which is waiting to be
+// validated again.
+type orderRetry struct {
+	order  *zeroex.SignedOrder
+	msg    *p2p.Message
+	topics []string
+	// attempts is the number of times that validation has been retried
+	// before.
+	attempts  int
+	nextRetry time.Time
+}
+
+// orderRetryQueue holds the orders received from peers which were rejected
+// for a retriable reason, so that transient issues with the Ethereum RPC
+// provider don't cause those orders to be lost. Orders are retried with
+// exponential backoff up to maxOrderRetryAttempts times. It is safe for
+// concurrent use.
+type orderRetryQueue struct {
+	mu      sync.Mutex
+	retries map[common.Hash]*orderRetry
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+func newOrderRetryQueue() *orderRetryQueue {
+	return &orderRetryQueue{
+		retries: map[common.Hash]*orderRetry{},
+		now:     time.Now,
+	}
+}
+
+// add schedules validation of the given order to be retried after it was
+// rejected for the attempts-th time. It returns false if the order was dropped
+// instead, either because it has been retried too many times or because the
+// queue is full.
+func (q *orderRetryQueue) add(orderHash common.Hash, retry *orderRetry) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, found := q.retries[orderHash]; found {
+		// The order was received again while waiting to be retried.
+		return true
+	}
+	if retry.attempts >= maxOrderRetryAttempts || len(q.retries) >= maxOrderRetryQueueSize {
+		return false
+	}
+	retry.nextRetry = q.now().Add(orderRetryBackoff(retry.attempts))
+	q.retries[orderHash] = retry
+	return true
+}
+
+// popDue removes and returns the orders which are due to be retried.
+func (q *orderRetryQueue) popDue() map[common.Hash]*orderRetry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	due := map[common.Hash]*orderRetry{}
+	now := q.now()
+	for orderHash, retry := range q.retries {
+		if !now.Before(retry.nextRetry) {
+			due[orderHash] = retry
+			delete(q.retries, orderHash)
+		}
+	}
+	return due
+}
+
+// orderRetryBackoff returns the amount of time to wait before retrying the
+// validation of an order which has already been retried the given number of
+// times.
+func orderRetryBackoff(attempts int) time.Duration {
+	backoff := minOrderRetryBackoff
+	for i := 0; i < attempts && backoff < maxOrderRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxOrderRetryBackoff {
+		backoff = maxOrderRetryBackoff
+	}
+	return backoff
+}
+
+// retryRejectedOrders periodically validates the orders in app.orderRetryQueue
+// which are due to be retried until the context is canceled.
+func (app *App) retryRejectedOrders(ctx context.Context) {
+	ticker := time.NewTicker(orderRetryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if app.isIngestionPaused() {
+			// The orders are retried once ingestion is resumed.
+			continue
+		}
+		due := app.orderRetryQueue.popDue()
+		if len(due) == 0 {
+			continue
+		}
+		orders := make([]*zeroex.SignedOrder, 0, len(due))
+		orderHashToMessage := make(map[common.Hash]*p2p.Message, len(due))
+		orderHashToTopics := make(map[common.Hash][]string, len(due))
+		retryAttempts := make(map[common.Hash]int, len(due))
+		for orderHash, retry := range due {
+			orders = append(orders, retry.order)
+			orderHashToMessage[orderHash] = retry.msg
+			orderHashToTopics[orderHash] = retry.topics
+			retryAttempts[orderHash] = retry.attempts + 1
+		}
+		log.WithField("numOrders", len(orders)).Debug("retrying validation of orders rejected for a retriable reason")
+		if err := app.validateAndStoreReceivedOrders(ctx, orders, orderHashToMessage, orderHashToTopics, retryAttempts); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithError(err).Error("could not retry validation of orders")
+			for orderHash, retry := range due {
+				retry.attempts++
+				app.orderRetryQueue.add(orderHash, retry)
+			}
+		}
+	}
+}
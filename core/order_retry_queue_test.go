@@ -0,0 +1,57 @@
+// +build !js
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRetryQueue(t *testing.T) {
+	now := time.Now()
+	q := newOrderRetryQueue()
+	q.now = func() time.Time { return now }
+	orderHash := common.HexToHash("0x1")
+
+	require.True(t, q.add(orderHash, &orderRetry{attempts: 0}))
+	// Adding the same order again doesn't reset its backoff.
+	require.True(t, q.add(orderHash, &orderRetry{attempts: 0}))
+	assert.Empty(t, q.popDue())
+
+	now = now.Add(minOrderRetryBackoff)
+	due := q.popDue()
+	require.Len(t, due, 1)
+	assert.Equal(t, 0, due[orderHash].attempts)
+	assert.Empty(t, q.popDue())
+
+	// The backoff doubles with each attempt.
+	require.True(t, q.add(orderHash, &orderRetry{attempts: 1}))
+	now = now.Add(minOrderRetryBackoff)
+	assert.Empty(t, q.popDue())
+	now = now.Add(minOrderRetryBackoff)
+	assert.Len(t, q.popDue(), 1)
+
+	// Orders are dropped after maxOrderRetryAttempts retries.
+	assert.False(t, q.add(orderHash, &orderRetry{attempts: maxOrderRetryAttempts}))
+	assert.Empty(t, q.retries)
+}
+
+func TestOrderRetryQueueFull(t *testing.T) {
+	q := newOrderRetryQueue()
+	for i := 0; i < maxOrderRetryQueueSize; i++ {
+		require.True(t, q.add(common.BigToHash(big.NewInt(int64(i))), &orderRetry{}))
+	}
+	assert.False(t, q.add(common.HexToHash("0xffffffff"), &orderRetry{}))
+}
+
+func TestOrderRetryBackoff(t *testing.T) {
+	assert.Equal(t, minOrderRetryBackoff, orderRetryBackoff(0))
+	assert.Equal(t, 2*minOrderRetryBackoff, orderRetryBackoff(1))
+	assert.Equal(t, 4*minOrderRetryBackoff, orderRetryBackoff(2))
+	assert.Equal(t, maxOrderRetryBackoff, orderRetryBackoff(10))
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/meshdb"
+	log "github.com/sirupsen/logrus"
+)
+
+// coldOrderCriteria returns the criteria for moving orders to the cold tier
+// of the database which are specified by the given config.
+func coldOrderCriteria(config Config) meshdb.ColdOrderCriteria {
+	return meshdb.ColdOrderCriteria{
+		MinTimeToExpiry:  config.ColdOrderMinTimeToExpiry,
+		MinPriceDistance: config.ColdOrderMinPriceDistance,
+		// Orders which could expire before the next tiering pass are kept hot
+		// so that they rarely need to be thawed when they expire.
+		ExpirationBuffer: 2 * config.ColdOrderTieringInterval,
+	}
+}
+
+// startOrderTiering moves orders between the hot and cold tiers of the
+// database every app.config.ColdOrderTieringInterval until ctx is canceled.
+func (app *App) startOrderTiering(ctx context.Context, wg *sync.WaitGroup) {
+	criteria := coldOrderCriteria(app.config)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing order tiering loop")
+		}()
+		ticker := time.NewTicker(app.config.ColdOrderTieringInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := app.orderWatcher.UpdateOrderTiers(criteria); err != nil {
+				log.WithError(err).Error("could not update order tiers")
+			}
+		}
+	}()
+}
@@ -0,0 +1,298 @@
+// Package orderchecksum contains the order checksum protocol, which peers use
+// to detect whether their sets of open orders have diverged. Each node
+// periodically asks one of its peers for a checksum of the orders it has
+// stored for each of the pubsub topics they share. If the checksums for a
+// topic differ in consecutive checks (i.e. the difference is not just caused
+// by orders which were still propagating through the network), the node
+// resyncs with that peer.
+//
+// A checksum is the XOR of the hashes of the orders in the set, which doesn't
+// depend on the order in which the orders were received and can be updated
+// with a single XOR whenever an order is added or removed.
+//
+// A requester opens a stream and sends a single JSON-encoded Request. The
+// provider responds with a single JSON-encoded Response and closes the
+// stream.
+package orderchecksum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// ID is the ID for the order checksum protocol.
+const ID = protocol.ID("/0x-mesh/order-checksum/version/0")
+
+const (
+	// maxTopicsPerRequest is the maximum number of topics in a single request.
+	maxTopicsPerRequest = 100
+	// maxMessageSize is the maximum size of a single request or response.
+	maxMessageSize = 64 * 1024
+	// requestResponseTimeout is how long to wait for a request or response.
+	requestResponseTimeout = 30 * time.Second
+	// maxRequestsPerSecond is the maximum number of requests per second that
+	// will be handled for all peers combined. Computing checksums requires
+	// reading every order from the database, so this must be low.
+	maxRequestsPerSecond = 0.5
+	// requestsBurst is the maximum number of requests that can be handled at
+	// once.
+	requestsBurst = 5
+	// divergenceThreshold is the number of consecutive checks in which the
+	// checksums for a topic must differ before the node resyncs with the peer.
+	divergenceThreshold = 2
+)
+
+// Checksum is the checksum of the open orders stored for a single topic.
+type Checksum struct {
+	Topic     string      `json:"topic"`
+	NumOrders int         `json:"numOrders"`
+	Checksum  common.Hash `json:"checksum"`
+}
+
+// Request is a request for the checksums of the given topics.
+type Request struct {
+	Topics []string `json:"topics"`
+}
+
+// Response is the response to a Request. It contains a checksum for each of
+// the requested topics that the provider uses. Topics which the provider
+// doesn't use are omitted.
+type Response struct {
+	Checksums []*Checksum `json:"checksums"`
+}
+
+// Sum returns the checksum of the given order hashes, which is the XOR of all
+// of them.
+func Sum(orderHashes []common.Hash) common.Hash {
+	var sum common.Hash
+	for _, orderHash := range orderHashes {
+		for i := range sum {
+			sum[i] ^= orderHash[i]
+		}
+	}
+	return sum
+}
+
+// OrderSet computes the checksums of the open orders stored by a Mesh node.
+type OrderSet interface {
+	// Checksums returns the checksum for each of the given topics. Topics
+	// which the node doesn't use must be omitted.
+	Checksums(topics []string) ([]*Checksum, error)
+}
+
+// ResyncFunc is called when the orders stored for the given topics have
+// diverged from those stored by the given peer.
+type ResyncFunc func(ctx context.Context, peerID peer.ID, topics []string)
+
+// Service is both the requester and provider side of the order checksum
+// protocol.
+type Service struct {
+	ctx                context.Context
+	node               *p2p.Node
+	orderSet           OrderSet
+	resync             ResyncFunc
+	requestRateLimiter *rate.Limiter
+	// mu protects divergences.
+	mu sync.Mutex
+	// divergences maps each peer to the number of consecutive checks in which
+	// the checksums for each topic differed.
+	divergences map[peer.ID]map[string]int
+}
+
+// New creates and returns a new Service which computes checksums with
+// orderSet and calls resync when the orders for a topic have diverged.
+func New(ctx context.Context, node *p2p.Node, orderSet OrderSet, resync ResyncFunc) *Service {
+	s := &Service{
+		ctx:                ctx,
+		node:               node,
+		orderSet:           orderSet,
+		resync:             resync,
+		requestRateLimiter: rate.NewLimiter(maxRequestsPerSecond, requestsBurst),
+		divergences:        map[peer.ID]map[string]int{},
+	}
+	s.node.SetStreamHandler(ID, s.HandleStream)
+	return s
+}
+
+// HandleStream is a stream handler that is used to handle incoming order
+// checksum requests.
+func (s *Service) HandleStream(stream network.Stream) {
+	requesterID := stream.Conn().RemotePeer()
+	if !s.requestRateLimiter.Allow() {
+		log.WithField("requester", requesterID.Pretty()).Debug("resetting order checksum stream because rate limiter is backed up")
+		_ = stream.Reset()
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	_ = stream.SetReadDeadline(time.Now().Add(requestResponseTimeout))
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&req); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not decode order checksum request")
+		return
+	}
+	res, err := s.handleRequest(&req)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Warn("could not handle order checksum request")
+		return
+	}
+	_ = stream.SetWriteDeadline(time.Now().Add(requestResponseTimeout))
+	if err := json.NewEncoder(stream).Encode(res); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not send order checksum response")
+	}
+}
+
+func (s *Service) handleRequest(req *Request) (*Response, error) {
+	if len(req.Topics) > maxTopicsPerRequest {
+		return nil, errors.New("too many topics in request")
+	}
+	checksums, err := s.orderSet.Checksums(req.Topics)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Checksums: checksums}, nil
+}
+
+// GetChecksums requests the checksums for the given topics from the given
+// peer.
+func (s *Service) GetChecksums(ctx context.Context, peerID peer.ID, topics []string) ([]*Checksum, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestResponseTimeout)
+	defer cancel()
+	stream, err := s.node.NewStream(ctx, peerID, ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+	if err := json.NewEncoder(stream).Encode(Request{Topics: topics}); err != nil {
+		return nil, err
+	}
+	var res Response
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&res); err != nil {
+		return nil, err
+	}
+	return res.Checksums, nil
+}
+
+// CheckPeer compares the checksums for the given topics with those of the
+// given peer and returns the topics for which they differ. Topics which the
+// peer doesn't use are ignored. If the checksums for a topic have differed
+// in divergenceThreshold consecutive checks, CheckPeer calls the ResyncFunc.
+func (s *Service) CheckPeer(ctx context.Context, peerID peer.ID, topics []string) ([]string, error) {
+	remoteChecksums, err := s.GetChecksums(ctx, peerID, topics)
+	if err != nil {
+		return nil, err
+	}
+	localChecksums, err := s.orderSet.Checksums(topics)
+	if err != nil {
+		return nil, err
+	}
+	divergedTopics := compareChecksums(localChecksums, remoteChecksums)
+	if topicsToResync := s.recordDivergences(peerID, divergedTopics); len(topicsToResync) > 0 {
+		log.WithFields(log.Fields{
+			"peer":   peerID.Pretty(),
+			"topics": topicsToResync,
+		}).Info("order checksums diverged from peer; resyncing")
+		s.resync(ctx, peerID, topicsToResync)
+	}
+	return divergedTopics, nil
+}
+
+// PeriodicallyCheckPeers calls CheckPeer for a random peer once per interval
+// until ctx is canceled. Errors are logged but do not stop the checks.
+func (s *Service) PeriodicallyCheckPeers(ctx context.Context, topics []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		neighbors := s.node.Neighbors()
+		if len(neighbors) == 0 {
+			continue
+		}
+		peerID := neighbors[rand.Intn(len(neighbors))]
+		if _, err := s.CheckPeer(ctx, peerID, topics); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"peer":  peerID.Pretty(),
+			}).Debug("could not compare order checksums with peer")
+		}
+	}
+}
+
+// recordDivergences records the topics whose checksums differed in the most
+// recent check with peerID and returns the topics for which the
+// divergenceThreshold has been reached. Their counts are reset so that the
+// next resync only happens if the checksums still differ afterwards.
+func (s *Service) recordDivergences(peerID peer.ID, divergedTopics []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.divergences[peerID]
+	current := map[string]int{}
+	topicsToResync := []string{}
+	for _, topic := range divergedTopics {
+		count := previous[topic] + 1
+		if count >= divergenceThreshold {
+			topicsToResync = append(topicsToResync, topic)
+			continue
+		}
+		current[topic] = count
+	}
+	if len(current) == 0 {
+		delete(s.divergences, peerID)
+	} else {
+		s.divergences[peerID] = current
+	}
+	return topicsToResync
+}
+
+// compareChecksums returns the topics which are in both local and remote but
+// whose checksums differ.
+func compareChecksums(local, remote []*Checksum) []string {
+	remoteByTopic := map[string]*Checksum{}
+	for _, checksum := range remote {
+		remoteByTopic[checksum.Topic] = checksum
+	}
+	divergedTopics := []string{}
+	for _, localChecksum := range local {
+		remoteChecksum, found := remoteByTopic[localChecksum.Topic]
+		if !found {
+			continue
+		}
+		if localChecksum.NumOrders != remoteChecksum.NumOrders || localChecksum.Checksum != remoteChecksum.Checksum {
+			divergedTopics = append(divergedTopics, localChecksum.Topic)
+		}
+	}
+	return divergedTopics
+}
@@ -0,0 +1,85 @@
+package orderchecksum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOrderSet struct {
+	checksums map[string]*Checksum
+}
+
+func (s *fakeOrderSet) Checksums(topics []string) ([]*Checksum, error) {
+	checksums := []*Checksum{}
+	for _, topic := range topics {
+		if checksum, found := s.checksums[topic]; found {
+			checksums = append(checksums, checksum)
+		}
+	}
+	return checksums, nil
+}
+
+func TestSum(t *testing.T) {
+	hashA := common.HexToHash("0x01")
+	hashB := common.HexToHash("0x1234")
+	hashC := common.HexToHash("0xff00")
+
+	assert.Equal(t, common.Hash{}, Sum(nil))
+	assert.Equal(t, Sum([]common.Hash{hashA, hashB, hashC}), Sum([]common.Hash{hashC, hashA, hashB}), "checksum should not depend on the order of hashes")
+	assert.NotEqual(t, Sum([]common.Hash{hashA, hashB}), Sum([]common.Hash{hashA, hashC}))
+
+	// Removing a hash is the same as XORing it again.
+	sum := Sum([]common.Hash{hashA, hashB, hashC})
+	assert.Equal(t, Sum([]common.Hash{hashA, hashC}), Sum([]common.Hash{sum, hashB}))
+}
+
+func TestCompareChecksums(t *testing.T) {
+	local := []*Checksum{
+		{Topic: "same", NumOrders: 2, Checksum: common.HexToHash("0x01")},
+		{Topic: "differentChecksum", NumOrders: 2, Checksum: common.HexToHash("0x02")},
+		{Topic: "differentNumOrders", NumOrders: 3, Checksum: common.HexToHash("0x03")},
+		{Topic: "localOnly", NumOrders: 1, Checksum: common.HexToHash("0x04")},
+	}
+	remote := []*Checksum{
+		{Topic: "differentNumOrders", NumOrders: 4, Checksum: common.HexToHash("0x03")},
+		{Topic: "same", NumOrders: 2, Checksum: common.HexToHash("0x01")},
+		{Topic: "differentChecksum", NumOrders: 2, Checksum: common.HexToHash("0x05")},
+		{Topic: "remoteOnly", NumOrders: 1, Checksum: common.HexToHash("0x06")},
+	}
+	assert.Equal(t, []string{"differentChecksum", "differentNumOrders"}, compareChecksums(local, remote))
+}
+
+func TestRecordDivergences(t *testing.T) {
+	s := &Service{divergences: map[peer.ID]map[string]int{}}
+	peerA := peer.ID("peerA")
+	peerB := peer.ID("peerB")
+
+	// A single divergence may be caused by orders that are still propagating.
+	assert.Empty(t, s.recordDivergences(peerA, []string{"topic1", "topic2"}))
+	assert.Empty(t, s.recordDivergences(peerB, []string{"topic1"}))
+	// Divergences must be consecutive.
+	assert.Equal(t, []string{"topic1"}, s.recordDivergences(peerA, []string{"topic1"}))
+	assert.Empty(t, s.recordDivergences(peerA, []string{"topic2"}))
+	// The count is reset after a resync.
+	assert.Empty(t, s.recordDivergences(peerA, []string{"topic1"}))
+	// Peers are tracked independently.
+	assert.Equal(t, []string{"topic1"}, s.recordDivergences(peerB, []string{"topic1"}))
+	assert.Empty(t, s.recordDivergences(peerB, nil))
+	assert.NotContains(t, s.divergences, peerB)
+}
+
+func TestHandleRequest(t *testing.T) {
+	checksum := &Checksum{Topic: "topic", NumOrders: 1, Checksum: common.HexToHash("0x01")}
+	s := &Service{orderSet: &fakeOrderSet{checksums: map[string]*Checksum{"topic": checksum}}}
+
+	res, err := s.handleRequest(&Request{Topics: []string{"topic", "unknownTopic"}})
+	require.NoError(t, err)
+	assert.Equal(t, []*Checksum{checksum}, res.Checksums)
+
+	_, err = s.handleRequest(&Request{Topics: make([]string, maxTopicsPerRequest+1)})
+	assert.Error(t, err)
+}
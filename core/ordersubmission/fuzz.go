@@ -0,0 +1,42 @@
+// +build gofuzz
+
+package ordersubmission
+
+import (
+	"encoding/json"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// This file contains harnesses for go-fuzz (https://github.com/dvyukov/go-fuzz)
+// and libFuzzer. They are run with `make fuzz`.
+
+// FuzzRequestEncoding unmarshals data as a list of signed orders (which may
+// contain any additional fields) and checks that the order submission request
+// that would be sent for them only contains the canonical fields of the orders.
+func FuzzRequestEncoding(data []byte) int {
+	var signedOrders []*zeroex.SignedOrder
+	if err := json.Unmarshal(data, &signedOrders); err != nil {
+		return 0
+	}
+	req, err := newRequest(signedOrders, false)
+	if err != nil {
+		panic(err)
+	}
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		panic(err)
+	}
+	var message struct {
+		Orders []json.RawMessage `json:"orders"`
+	}
+	if err := json.Unmarshal(encoded, &message); err != nil {
+		panic(err)
+	}
+	for _, order := range message.Orders {
+		if err := zeroex.CheckCanonicalJSON(order); err != nil {
+			panic(err)
+		}
+	}
+	return 1
+}
@@ -0,0 +1,241 @@
+// Package ordersubmission contains the order submission protocol, which is
+// used by trusted, typically co-located, services (e.g. market maker engines)
+// to add orders directly to a Mesh node over libp2p. Compared to the JSON-RPC
+// API it avoids the overhead of a separate HTTP/WebSocket connection and the
+// JSON-RPC envelope. Requesters are authenticated by their peer ID, which is
+// verified by the libp2p secure transport.
+//
+// A requester opens a single stream and sends any number of newline-delimited
+// JSON requests over it. The Mesh node responds to each request in order.
+package ordersubmission
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// ID is the ID for the order submission protocol.
+const ID = protocol.ID("/0x-mesh/order-submission/version/0")
+
+const (
+	// maxOrdersPerRequest is the maximum number of orders which can be added in
+	// a single request.
+	maxOrdersPerRequest = 1000
+	// maxMessageSize is the maximum size of a single request or response.
+	maxMessageSize = maxOrdersPerRequest * constants.MaxOrderSizeInBytes * 2
+	// idleTimeout is how long a stream may stay open without any requests.
+	idleTimeout = 5 * time.Minute
+	// trustedPeerTag is the tag used to protect connections to trusted peers.
+	trustedPeerTag = "trusted-order-submitter"
+)
+
+// ErrTooManyOrders is returned when a request contains more than
+// maxOrdersPerRequest orders.
+var ErrTooManyOrders = errors.New("too many orders in request")
+
+// Request is a request to add orders.
+type Request struct {
+	Orders []*json.RawMessage `json:"orders"`
+	Pinned bool               `json:"pinned"`
+}
+
+// Response is the response to a Request. Exactly one of Results and Error is
+// set.
+type Response struct {
+	Results *ordervalidator.ValidationResults `json:"results,omitempty"`
+	Error   string                            `json:"error,omitempty"`
+}
+
+// OrderAdder adds orders to a Mesh node.
+type OrderAdder interface {
+	AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessage, pinned bool) (*ordervalidator.ValidationResults, error)
+}
+
+// Service is the provider side of the order submission protocol.
+type Service struct {
+	ctx          context.Context
+	node         *p2p.Node
+	orderAdder   OrderAdder
+	trustedPeers map[peer.ID]struct{}
+}
+
+// New creates and returns a new Service which accepts orders from the given
+// trusted peers and adds them with orderAdder. Streams from any other peer are
+// reset.
+func New(ctx context.Context, node *p2p.Node, orderAdder OrderAdder, trustedPeers []peer.ID) *Service {
+	trustedPeerSet := map[peer.ID]struct{}{}
+	for _, peerID := range trustedPeers {
+		trustedPeerSet[peerID] = struct{}{}
+	}
+	s := &Service{
+		ctx:          ctx,
+		node:         node,
+		orderAdder:   orderAdder,
+		trustedPeers: trustedPeerSet,
+	}
+	s.node.SetStreamHandler(ID, s.HandleStream)
+	return s
+}
+
+// HandleStream is a stream handler that is used to handle incoming order
+// submission requests.
+func (s *Service) HandleStream(stream network.Stream) {
+	requesterID := stream.Conn().RemotePeer()
+	if !s.isTrusted(requesterID) {
+		log.WithField("requester", requesterID.Pretty()).Warn("resetting order submission stream from untrusted peer")
+		_ = stream.Reset()
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	// Trusted peers often send many orders at once. Make sure they don't get
+	// disconnected or banned for it.
+	s.node.ProtectPeer(requesterID, trustedPeerTag)
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxMessageSize)
+	encoder := json.NewEncoder(stream)
+	for {
+		_ = stream.SetReadDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.WithFields(log.Fields{
+					"error":     err.Error(),
+					"requester": requesterID.Pretty(),
+				}).Debug("closing order submission stream")
+			}
+			return
+		}
+		res := s.handleRequest(scanner.Bytes())
+		if err := encoder.Encode(res); err != nil {
+			log.WithFields(log.Fields{
+				"error":     err.Error(),
+				"requester": requesterID.Pretty(),
+			}).Warn("could not send order submission response")
+			return
+		}
+	}
+}
+
+// isTrusted returns true if the given peer is trusted. A peer which has
+// rotated its identity key is trusted if its previous identity was trusted
+// and the grace period hasn't ended yet.
+func (s *Service) isTrusted(id peer.ID) bool {
+	if _, found := s.trustedPeers[id]; found {
+		return true
+	}
+	previousID, found := s.node.PreviousPeerID(id)
+	if !found {
+		return false
+	}
+	_, found = s.trustedPeers[previousID]
+	return found
+}
+
+func (s *Service) handleRequest(rawReq []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(rawReq, &req); err != nil {
+		return &Response{Error: "invalid request: " + err.Error()}
+	}
+	if len(req.Orders) > maxOrdersPerRequest {
+		return &Response{Error: ErrTooManyOrders.Error()}
+	}
+	results, err := s.orderAdder.AddOrders(s.ctx, req.Orders, req.Pinned)
+	if err != nil {
+		// We don't want to leak internal error details, even to trusted
+		// peers. This mirrors the JSON-RPC API.
+		log.WithError(err).Error("internal error in order submission request")
+		return &Response{Error: constants.ErrInternal.Error()}
+	}
+	return &Response{Results: results}
+}
+
+// Client is the requester side of the order submission protocol. It is not
+// safe for concurrent use.
+type Client struct {
+	stream  network.Stream
+	scanner *bufio.Scanner
+	encoder *json.Encoder
+}
+
+// NewClient opens a new order submission stream to the Mesh node with the given
+// peer ID. h must already be able to connect to the Mesh node (e.g. because
+// its address was added to the peerstore) and its peer ID must be trusted by
+// the Mesh node.
+func NewClient(ctx context.Context, h host.Host, meshPeerID peer.ID) (*Client, error) {
+	stream, err := h.NewStream(ctx, meshPeerID, ID)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxMessageSize)
+	return &Client{
+		stream:  stream,
+		scanner: scanner,
+		encoder: json.NewEncoder(stream),
+	}, nil
+}
+
+// AddOrders adds the given orders to the Mesh node and returns the validation
+// results.
+func (c *Client) AddOrders(signedOrders []*zeroex.SignedOrder, pinned bool) (*ordervalidator.ValidationResults, error) {
+	if len(signedOrders) > maxOrdersPerRequest {
+		return nil, ErrTooManyOrders
+	}
+	req, err := newRequest(signedOrders, pinned)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.encoder.Encode(req); err != nil {
+		return nil, err
+	}
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("order submission stream closed by Mesh node")
+	}
+	var res Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &res); err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	return res.Results, nil
+}
+
+// newRequest returns a Request for the given orders. The orders are encoded
+// with zeroex.MarshalCanonicalJSON, so only their canonical fields are sent to
+// the Mesh node.
+func newRequest(signedOrders []*zeroex.SignedOrder, pinned bool) (*Request, error) {
+	rawOrders := make([]*json.RawMessage, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		encoded, err := zeroex.MarshalCanonicalJSON(signedOrder)
+		if err != nil {
+			return nil, err
+		}
+		rawOrder := json.RawMessage(encoded)
+		rawOrders[i] = &rawOrder
+	}
+	return &Request{Orders: rawOrders, Pinned: pinned}, nil
+}
+
+// Close closes the underlying stream.
+func (c *Client) Close() error {
+	return c.stream.Close()
+}
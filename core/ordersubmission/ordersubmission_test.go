@@ -0,0 +1,48 @@
+package ordersubmission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOrderAdder struct {
+	numOrders int
+	pinned    bool
+	err       error
+}
+
+func (a *fakeOrderAdder) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessage, pinned bool) (*ordervalidator.ValidationResults, error) {
+	a.numOrders = len(signedOrdersRaw)
+	a.pinned = pinned
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &ordervalidator.ValidationResults{}, nil
+}
+
+func TestHandleRequest(t *testing.T) {
+	adder := &fakeOrderAdder{}
+	s := &Service{ctx: context.Background(), orderAdder: adder}
+
+	res := s.handleRequest([]byte(`{"orders":[{},{}],"pinned":true}`))
+	require.Empty(t, res.Error)
+	assert.NotNil(t, res.Results)
+	assert.Equal(t, 2, adder.numOrders)
+	assert.True(t, adder.pinned)
+
+	res = s.handleRequest([]byte(`not json`))
+	assert.Contains(t, res.Error, "invalid request")
+	assert.Nil(t, res.Results)
+
+	// Internal errors should not be leaked.
+	adder.err = errors.New("database is on fire")
+	res = s.handleRequest([]byte(`{"orders":[]}`))
+	assert.Equal(t, constants.ErrInternal.Error(), res.Error)
+}
@@ -0,0 +1,202 @@
+package ordersync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxDecompressedOrdersSize is the maximum size in bytes of the orders in
+	// a single compressed ordersync response after decompression. It protects
+	// requesters from decompression bombs.
+	maxDecompressedOrdersSize = 64 * 1024 * 1024
+	// maxCompressedOrdersSize is the maximum size in bytes of the compressed
+	// orders in a single ordersync response.
+	maxCompressedOrdersSize = 16 * 1024 * 1024
+)
+
+var errDecompressedOrdersTooLarge = fmt.Errorf("decompressed orders exceed the maximum size of %d bytes", maxDecompressedOrdersSize)
+
+// compressionCodec compresses and decompresses the orders in ordersync
+// responses.
+type compressionCodec interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// supportedCompression is the list of compression encodings supported for
+// ordersync responses in order of preference. Requesters send this list with
+// each request and providers use the first encoding that they also support.
+// Older peers don't send or understand the list, in which case responses are
+// not compressed.
+var supportedCompression = []string{"zstd"}
+
+var compressionCodecs = map[string]compressionCodec{
+	"zstd": &zstdCodec{},
+}
+
+// zstdCodec compresses orders with Zstandard. The encoder is shared between
+// all responses since creating one is relatively expensive.
+type zstdCodec struct {
+	encoderOnce sync.Once
+	encoder     *zstd.Encoder
+	encoderErr  error
+}
+
+func (c *zstdCodec) Compress(data []byte) ([]byte, error) {
+	c.encoderOnce.Do(func() {
+		c.encoder, c.encoderErr = zstd.NewWriter(nil)
+	})
+	if c.encoderErr != nil {
+		return nil, c.encoderErr
+	}
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) Decompress(data []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	decompressed, err := ioutil.ReadAll(io.LimitReader(decoder, maxDecompressedOrdersSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedOrdersSize {
+		return nil, errDecompressedOrdersTooLarge
+	}
+	return decompressed, nil
+}
+
+// BytesSavedByCompression returns the total number of bytes that were saved
+// by compressing ordersync responses, both sent and received, since the
+// service was created.
+func (s *Service) BytesSavedByCompression() uint64 {
+	return atomic.LoadUint64(&s.bytesSavedByCompression)
+}
+
+// compressOrders compresses the orders in rawRes with the first of the
+// accepted encodings that is supported. The orders are left uncompressed if
+// none of the encodings are supported or compression wouldn't make the
+// response smaller.
+func (s *Service) compressOrders(rawRes *rawResponse, acceptedEncodings []string) {
+	if len(rawRes.Orders) == 0 {
+		return
+	}
+	for _, encoding := range acceptedEncodings {
+		codec, found := compressionCodecs[encoding]
+		if !found {
+			continue
+		}
+		encodedOrders, err := zeroex.MarshalCanonicalOrdersJSON(rawRes.Orders)
+		if err != nil {
+			log.WithError(err).Error("could not encode ordersync orders for compression")
+			return
+		}
+		compressedOrders, err := codec.Compress(encodedOrders)
+		if err != nil {
+			log.WithError(err).Error("could not compress ordersync orders")
+			return
+		}
+		if len(compressedOrders) >= len(encodedOrders) || len(compressedOrders) > maxCompressedOrdersSize {
+			return
+		}
+		atomic.AddUint64(&s.bytesSavedByCompression, uint64(len(encodedOrders)-len(compressedOrders)))
+		rawRes.Compression = encoding
+		rawRes.CompressedOrdersLength = len(compressedOrders)
+		rawRes.compressedOrders = compressedOrders
+		rawRes.Orders = nil
+		return
+	}
+}
+
+// decompressOrders decompresses the orders in rawRes if they are compressed.
+func (s *Service) decompressOrders(rawRes *rawResponse) error {
+	if rawRes.Compression == "" {
+		return nil
+	}
+	codec, found := compressionCodecs[rawRes.Compression]
+	if !found {
+		return fmt.Errorf("unsupported ordersync compression: %s", rawRes.Compression)
+	}
+	if len(rawRes.Orders) != 0 {
+		return errors.New("ordersync response contains both compressed and uncompressed orders")
+	}
+	encodedOrders, err := codec.Decompress(rawRes.compressedOrders)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(encodedOrders, &rawRes.Orders); err != nil {
+		return err
+	}
+	if len(rawRes.compressedOrders) < len(encodedOrders) {
+		atomic.AddUint64(&s.bytesSavedByCompression, uint64(len(encodedOrders)-len(rawRes.compressedOrders)))
+	}
+	rawRes.Compression = ""
+	rawRes.CompressedOrdersLength = 0
+	rawRes.compressedOrders = nil
+	return nil
+}
+
+// writeResponse writes the JSON-encoded response, which is terminated by a
+// newline, followed by the compressed orders (if any) as raw bytes.
+func writeResponse(writer io.Writer, rawRes *rawResponse) error {
+	encodedOrders, err := zeroex.MarshalCanonicalOrdersJSON(rawRes.Orders)
+	if err != nil {
+		return err
+	}
+	outgoingRes := outgoingRawResponse{
+		rawResponse: *rawRes,
+		Orders:      encodedOrders,
+	}
+	if err := json.NewEncoder(writer).Encode(outgoingRes); err != nil {
+		return err
+	}
+	if rawRes.CompressedOrdersLength == 0 {
+		return nil
+	}
+	_, err = writer.Write(rawRes.compressedOrders)
+	return err
+}
+
+// readResponse reads a response written by writeResponse, including the
+// compressed orders (if any).
+func readResponse(reader io.Reader) (*rawResponse, error) {
+	decoder := json.NewDecoder(reader)
+	var rawRes rawResponse
+	if err := decoder.Decode(&rawRes); err != nil {
+		return nil, err
+	}
+	if rawRes.CompressedOrdersLength == 0 {
+		return &rawRes, nil
+	}
+	if rawRes.CompressedOrdersLength < 0 || rawRes.CompressedOrdersLength > maxCompressedOrdersSize {
+		return nil, fmt.Errorf("compressed orders length %d is out of range", rawRes.CompressedOrdersLength)
+	}
+	// The decoder may have read the newline which terminates the response and
+	// some of the compressed orders into its buffer already.
+	remaining := io.MultiReader(decoder.Buffered(), reader)
+	separator := make([]byte, 1)
+	if _, err := io.ReadFull(remaining, separator); err != nil {
+		return nil, err
+	}
+	if separator[0] != '\n' {
+		return nil, errors.New("compressed orders are not preceded by a newline")
+	}
+	rawRes.compressedOrders = make([]byte, rawRes.CompressedOrdersLength)
+	if _, err := io.ReadFull(remaining, rawRes.compressedOrders); err != nil {
+		return nil, err
+	}
+	return &rawRes, nil
+}
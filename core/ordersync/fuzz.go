@@ -0,0 +1,66 @@
+// +build gofuzz
+
+package ordersync
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// This file contains harnesses for go-fuzz (https://github.com/dvyukov/go-fuzz)
+// and libFuzzer. They are run with `make fuzz`.
+
+// FuzzResponseEncoding unmarshals data as a list of signed orders (which may
+// contain any additional fields) and checks that the ordersync responses that
+// would be sent for them, both with and without compression, only contain the
+// canonical fields of the orders.
+func FuzzResponseEncoding(data []byte) int {
+	var orders []*zeroex.SignedOrder
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return 0
+	}
+	provider := &Service{}
+	for _, acceptedEncodings := range [][]string{nil, supportedCompression} {
+		rawRes := &rawResponse{
+			Type:   TypeResponse,
+			Orders: orders,
+		}
+		provider.compressOrders(rawRes, acceptedEncodings)
+		var wire bytes.Buffer
+		if err := writeResponse(&wire, rawRes); err != nil {
+			panic(err)
+		}
+		// Any compressed orders follow the JSON-encoded response, so only the
+		// first JSON value is decoded.
+		var message map[string]json.RawMessage
+		if err := json.NewDecoder(&wire).Decode(&message); err != nil {
+			panic(err)
+		}
+		encodedOrders := message["orders"]
+		if rawRes.Compression != "" {
+			decompressed, err := compressionCodecs[rawRes.Compression].Decompress(rawRes.compressedOrders)
+			if err != nil {
+				panic(err)
+			}
+			encodedOrders = decompressed
+		}
+		checkCanonicalOrders(encodedOrders)
+	}
+	return 1
+}
+
+// checkCanonicalOrders panics if encodedOrders is not a JSON array of orders
+// which only contain canonical fields.
+func checkCanonicalOrders(encodedOrders []byte) {
+	var orders []json.RawMessage
+	if err := json.Unmarshal(encodedOrders, &orders); err != nil {
+		panic(err)
+	}
+	for _, order := range orders {
+		if err := zeroex.CheckCanonicalJSON(order); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xProject/0x-mesh/p2p"
@@ -53,6 +56,9 @@ var (
 	// the entire network, or that there are peers that have the orders we're
 	// looking for, but they are refusing to give them to us.
 	ErrNoOrders = errors.New("no orders where received from any known peers")
+	// ErrRequestsPaused is returned by GetOrders and GetOrdersFromPeer while
+	// requesting orders has been paused with PauseRequests.
+	ErrRequestsPaused = errors.New("ordersync requests are paused")
 )
 
 // NoMatchingSubprotocolsError is returned whenever two peers attempting to use
@@ -84,6 +90,9 @@ type rawRequest struct {
 	Type         string          `json:"type"`
 	Subprotocols []string        `json:"subprotocols"`
 	Metadata     json.RawMessage `json:"metadata"`
+	// Compression is the list of compression encodings that the requester
+	// accepts for the orders in the response, in order of preference.
+	Compression []string `json:"compression,omitempty"`
 }
 
 // Response represents a high-level ordersync response. It abstracts away some
@@ -103,19 +112,54 @@ type rawResponse struct {
 	Orders      []*zeroex.SignedOrder `json:"orders"`
 	Complete    bool                  `json:"complete"`
 	Metadata    json.RawMessage       `json:"metadata"`
+	// Compression is the compression encoding of the orders. If it is empty,
+	// the orders are not compressed and are contained in Orders. Otherwise the
+	// compressed orders are sent as CompressedOrdersLength raw bytes directly
+	// after the JSON-encoded response (see writeResponse).
+	Compression            string `json:"compression,omitempty"`
+	CompressedOrdersLength int    `json:"compressedOrdersLength,omitempty"`
+	// compressedOrders holds the compressed orders while the response is
+	// written or read. It is not part of the JSON encoding.
+	compressedOrders []byte
+}
+
+// outgoingRawResponse is the encoding of a rawResponse which is sent over the
+// wire. The orders are encoded with zeroex.MarshalCanonicalOrdersJSON, so only
+// the canonical fields of the orders can ever be sent to peers. Orders shadows
+// the field of the same name in rawResponse.
+type outgoingRawResponse struct {
+	rawResponse
+	Orders json.RawMessage `json:"orders"`
 }
 
 // Service is the main entrypoint for running the ordersync protocol. It handles
 // responding to and sending ordersync requests.
 type Service struct {
-	ctx  context.Context
-	node *p2p.Node
+	// bytesSavedByCompression is the total number of bytes saved by
+	// compressing ordersync responses. It is accessed atomically and is the
+	// first field to guarantee 64-bit alignment.
+	bytesSavedByCompression uint64
+	// requestsPaused is set to 1 while requesting orders from other peers is
+	// paused. It is accessed atomically.
+	requestsPaused int32
+	ctx            context.Context
+	node           *p2p.Node
 	// preferredSubprotocols is the list of supported subprotocol IDs in order of preference.
 	preferredSubprotocols []string
 	subprotocolSet        map[string]Subprotocol
 	// requestRateLimiter is a rate limiter for incoming ordersync requests. It's
 	// shared between all peers.
 	requestRateLimiter *rate.Limiter
+	// sessionLimiter limits the number of peers which are served at once. It
+	// is nil if the number of sessions is not limited.
+	sessionLimiter *sessionLimiter
+	// bandwidthLimiter limits the rate at which responses are sent. It is nil
+	// if bandwidth is not limited.
+	bandwidthLimiter *rate.Limiter
+	// firstRoundCompleted is closed once the first round of ordersync has
+	// finished. See HasCompletedFirstRound.
+	firstRoundCompleted     chan struct{}
+	firstRoundCompletedOnce sync.Once
 }
 
 // Subprotocol is a lower-level protocol which defines the details for the
@@ -150,8 +194,9 @@ type Subprotocol interface {
 // requesting orders from other peers and providing orders to peers who request
 // them. New expects an array of subprotocols which the service will support, in the
 // order of preference. The service will automatically pick the most preferred protocol
-// that is supported by both peers for each request/response.
-func New(ctx context.Context, node *p2p.Node, subprotocols []Subprotocol) *Service {
+// that is supported by both peers for each request/response. limits restricts
+// the resources used to serve requests from other peers.
+func New(ctx context.Context, node *p2p.Node, subprotocols []Subprotocol, limits ProviderLimits) *Service {
 	sids := []string{}
 	supportedSubprotocols := map[string]Subprotocol{}
 	for _, subp := range subprotocols {
@@ -166,6 +211,9 @@ func New(ctx context.Context, node *p2p.Node, subprotocols []Subprotocol) *Servi
 		subprotocolSet:        supportedSubprotocols,
 		preferredSubprotocols: sids,
 		requestRateLimiter:    rate.NewLimiter(maxRequestsPerSecond, requestsBurst),
+		sessionLimiter:        newSessionLimiter(limits),
+		bandwidthLimiter:      newBandwidthLimiter(limits),
+		firstRoundCompleted:   make(chan struct{}),
 	}
 	s.node.SetStreamHandler(ID, s.HandleStream)
 	return s
@@ -198,6 +246,16 @@ func (s *Service) HandleStream(stream network.Stream) {
 		_ = stream.Reset()
 		return
 	}
+	if !s.sessionLimiter.acquire(s.ctx, maxSessionQueueTime) {
+		// Close the stream so that the requester can sync with another peer
+		// instead.
+		log.WithFields(log.Fields{
+			"requester": stream.Conn().RemotePeer().Pretty(),
+		}).Warn("closing ordersync stream because too many peers are being served")
+		_ = stream.Reset()
+		return
+	}
+	defer s.sessionLimiter.release()
 	log.WithFields(log.Fields{
 		"requester": stream.Conn().RemotePeer().Pretty(),
 	}).Trace("handling ordersync stream")
@@ -205,6 +263,10 @@ func (s *Service) HandleStream(stream network.Stream) {
 		_ = stream.Close()
 	}()
 	requesterID := stream.Conn().RemotePeer()
+	var writer io.Writer = stream
+	if s.bandwidthLimiter != nil {
+		writer = &throttledWriter{ctx: s.ctx, writer: stream, limiter: s.bandwidthLimiter}
+	}
 
 	for {
 		if err := s.requestRateLimiter.Wait(s.ctx); err != nil {
@@ -225,7 +287,7 @@ func (s *Service) HandleStream(stream network.Stream) {
 		if rawRes == nil {
 			return
 		}
-		if err := json.NewEncoder(stream).Encode(rawRes); err != nil {
+		if err := writeResponse(writer, rawRes); err != nil {
 			log.WithFields(log.Fields{
 				"error":     err.Error(),
 				"requester": requesterID.Pretty(),
@@ -260,6 +322,9 @@ func (s *Service) GetOrders(ctx context.Context, minPeers int) error {
 			return ctx.Err()
 		default:
 		}
+		if s.RequestsPaused() {
+			return ErrRequestsPaused
+		}
 
 		// TODO(albrow): As a performance optimization, do this for loop
 		// partly in parallel.
@@ -281,8 +346,14 @@ func (s *Service) GetOrders(ctx context.Context, minPeers int) error {
 				return ctx.Err()
 			default:
 			}
+			if s.RequestsPaused() {
+				return ErrRequestsPaused
+			}
 
 			if err := s.getOrdersFromPeer(ctx, peerID); err != nil {
+				if err == ErrRequestsPaused {
+					return err
+				}
 				log.WithFields(log.Fields{
 					"error":    err.Error(),
 					"provider": peerID.Pretty(),
@@ -299,6 +370,12 @@ func (s *Service) GetOrders(ctx context.Context, minPeers int) error {
 				successfullySyncedPeers.Add(peerID.Pretty())
 			}
 		}
+		// Nodes with fewer than minPeers neighbors would otherwise never
+		// complete the first round, so a full pass over all of the current
+		// neighbors counts as well.
+		if len(currentNeighbors) > 0 {
+			s.markFirstRoundCompleted()
+		}
 
 		delayBeforeNextRetry := retryBackoff.Duration()
 		log.WithFields(log.Fields{
@@ -328,8 +405,14 @@ func (s *Service) PeriodicallyGetOrders(ctx context.Context, minPeers int, appro
 		default:
 		}
 
-		if err := s.GetOrders(ctx, minPeers); err != nil {
+		// While requests are paused, rounds are skipped entirely and do not
+		// count towards completing the first round.
+		if err := s.GetOrders(ctx, minPeers); err == ErrRequestsPaused {
+			log.Debug("skipping ordersync round because requests are paused")
+		} else if err != nil {
 			return err
+		} else {
+			s.markFirstRoundCompleted()
 		}
 
 		// Note(albrow): The random jitter here helps smooth out the frequency of ordersync
@@ -344,6 +427,41 @@ func (s *Service) PeriodicallyGetOrders(ctx context.Context, minPeers int, appro
 	}
 }
 
+// HasCompletedFirstRound returns true if GetOrders has either received orders
+// from minPeers peers or tried every neighbor at least once.
+func (s *Service) HasCompletedFirstRound() bool {
+	select {
+	case <-s.firstRoundCompleted:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Service) markFirstRoundCompleted() {
+	s.firstRoundCompletedOnce.Do(func() {
+		close(s.firstRoundCompleted)
+	})
+}
+
+// PauseRequests stops the service from requesting orders from other peers
+// until ResumeRequests is called. Rounds that are in progress stop before
+// contacting the next peer. Serving requests from other peers is unaffected.
+func (s *Service) PauseRequests() {
+	atomic.StoreInt32(&s.requestsPaused, 1)
+}
+
+// ResumeRequests undoes PauseRequests.
+func (s *Service) ResumeRequests() {
+	atomic.StoreInt32(&s.requestsPaused, 0)
+}
+
+// RequestsPaused returns true if requesting orders from other peers is
+// currently paused.
+func (s *Service) RequestsPaused() bool {
+	return atomic.LoadInt32(&s.requestsPaused) == 1
+}
+
 func calculateDelayWithJitter(approxDelay time.Duration, jitterAmount float64) time.Duration {
 	jitterBounds := int(float64(approxDelay) * jitterAmount * 2)
 	delta := rand.Intn(jitterBounds) - jitterBounds/2
@@ -389,13 +507,15 @@ func (s *Service) handleRawRequest(rawReq *rawRequest, requesterID peer.ID) *raw
 		return nil
 	}
 	s.handlePeerScoreEvent(requesterID, psValidMessage)
-	return &rawResponse{
+	rawRes := &rawResponse{
 		Type:        TypeResponse,
 		Subprotocol: subprotocol.Name(),
 		Orders:      res.Orders,
 		Complete:    res.Complete,
 		Metadata:    encodedMetadata,
 	}
+	s.compressOrders(rawRes, rawReq.Compression)
+	return rawRes
 }
 
 func handleRequestWithSubprotocol(ctx context.Context, subprotocol Subprotocol, requesterID peer.ID, rawReq *rawRequest) (*Response, error) {
@@ -459,7 +579,17 @@ func (s *Service) createFirstRequestForAllSubprotocols() (*rawRequest, error) {
 	}, nil
 }
 
+// GetOrdersFromPeer performs the ordersync protocol with the given peer only.
+// It is used to resync with a peer whose orders are known to have diverged
+// from ours.
+func (s *Service) GetOrdersFromPeer(ctx context.Context, providerID peer.ID) error {
+	return s.getOrdersFromPeer(ctx, providerID)
+}
+
 func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) error {
+	if s.RequestsPaused() {
+		return ErrRequestsPaused
+	}
 	stream, err := s.node.NewStream(ctx, providerID, ID)
 	if err != nil {
 		s.handlePeerScoreEvent(providerID, psUnexpectedDisconnect)
@@ -496,6 +626,7 @@ func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) err
 				Metadata:     encodedMetadata,
 			}
 		}
+		rawReq.Compression = supportedCompression
 
 		if err := json.NewEncoder(stream).Encode(rawReq); err != nil {
 			s.handlePeerScoreEvent(providerID, psUnexpectedDisconnect)
@@ -506,6 +637,10 @@ func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) err
 		if err != nil {
 			return err
 		}
+		if err := s.decompressOrders(rawRes); err != nil {
+			s.handlePeerScoreEvent(providerID, psInvalidMessage)
+			return err
+		}
 		s.handlePeerScoreEvent(providerID, psValidMessage)
 
 		subprotocol, found := s.subprotocolSet[rawRes.Subprotocol]
@@ -576,8 +711,8 @@ func waitForResponse(parentCtx context.Context, stream network.Stream) (*rawResp
 	resChan := make(chan *rawResponse, 1)
 	errChan := make(chan error, 1)
 	go func() {
-		var rawRes rawResponse
-		if err := json.NewDecoder(stream).Decode(&rawRes); err != nil {
+		rawRes, err := readResponse(stream)
+		if err != nil {
 			log.WithFields(log.Fields{
 				"error":    err.Error(),
 				"provider": stream.Conn().RemotePeer().Pretty(),
@@ -585,7 +720,7 @@ func waitForResponse(parentCtx context.Context, stream network.Stream) (*rawResp
 			errChan <- err
 			return
 		}
-		resChan <- &rawRes
+		resChan <- rawRes
 	}()
 
 	select {
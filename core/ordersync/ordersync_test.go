@@ -1,6 +1,7 @@
 package ordersync
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"math/big"
@@ -29,6 +30,22 @@ func TestCalculateDelayWithJitters(t *testing.T) {
 	}
 }
 
+func TestGetOrdersWhileRequestsPaused(t *testing.T) {
+	s := &Service{}
+	s.PauseRequests()
+	assert.True(t, s.RequestsPaused())
+
+	// GetOrders returns immediately instead of retrying until enough peers
+	// were synced.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Equal(t, ErrRequestsPaused, s.GetOrders(ctx, 5))
+	assert.Equal(t, ErrRequestsPaused, s.GetOrdersFromPeer(ctx, peer.ID("")))
+
+	s.ResumeRequests()
+	assert.False(t, s.RequestsPaused())
+}
+
 func TestHandleRawRequest(t *testing.T) {
 	n, err := p2p.New(
 		context.Background(),
@@ -46,7 +63,7 @@ func TestHandleRawRequest(t *testing.T) {
 		myPeerID: n.ID(),
 		hostSubp: subp0,
 	}
-	s := New(context.Background(), n, []Subprotocol{subp0, subp1})
+	s := New(context.Background(), n, []Subprotocol{subp0, subp1}, ProviderLimits{})
 
 	rawReq := &rawRequest{
 		Type:         TypeRequest,
@@ -80,6 +97,58 @@ func TestHandleRawRequest(t *testing.T) {
 	assert.Equal(t, res.Metadata, rawReq.Metadata)
 }
 
+func TestOrdersCompression(t *testing.T) {
+	subp := &oneOrderSubprotocol{}
+	orders := []*zeroex.SignedOrder{}
+	for i := 0; i < 20; i++ {
+		res, err := subp.HandleOrderSyncRequest(context.Background(), &Request{})
+		require.NoError(t, err)
+		orders = append(orders, res.Orders...)
+	}
+	provider := &Service{}
+	requester := &Service{}
+
+	// Responses to older peers, which don't accept any compression encodings,
+	// and to peers which don't accept any supported encodings should not be
+	// compressed.
+	for _, acceptedEncodings := range [][]string{nil, []string{"unknown"}} {
+		rawRes := &rawResponse{Orders: orders}
+		provider.compressOrders(rawRes, acceptedEncodings)
+		assert.Equal(t, "", rawRes.Compression)
+		assert.Nil(t, rawRes.compressedOrders)
+		assert.Equal(t, orders, rawRes.Orders)
+	}
+	assert.Equal(t, uint64(0), provider.BytesSavedByCompression())
+
+	rawRes := &rawResponse{Orders: orders}
+	provider.compressOrders(rawRes, []string{"unknown", "zstd"})
+	assert.Equal(t, "zstd", rawRes.Compression)
+	assert.Nil(t, rawRes.Orders)
+	assert.NotEqual(t, uint64(0), provider.BytesSavedByCompression())
+
+	// Send the response over the "wire" and decompress it. The compressed
+	// orders are sent as raw bytes after the JSON-encoded response.
+	var wire bytes.Buffer
+	require.NoError(t, writeResponse(&wire, rawRes))
+	receivedRes, err := readResponse(&wire)
+	require.NoError(t, err)
+	assert.Equal(t, 0, wire.Len(), "the whole response should be read")
+	assert.Equal(t, rawRes.compressedOrders, receivedRes.compressedOrders)
+	require.NoError(t, requester.decompressOrders(receivedRes))
+	assert.Equal(t, provider.BytesSavedByCompression(), requester.BytesSavedByCompression())
+	require.Len(t, receivedRes.Orders, len(orders))
+	for i, order := range receivedRes.Orders {
+		expectedHash, err := orders[i].ComputeOrderHash()
+		require.NoError(t, err)
+		actualHash, err := order.ComputeOrderHash()
+		require.NoError(t, err)
+		assert.Equal(t, expectedHash, actualHash)
+	}
+
+	unsupportedRes := &rawResponse{Compression: "unknown", CompressedOrdersLength: 3, compressedOrders: []byte{1, 2, 3}}
+	assert.Error(t, requester.decompressOrders(unsupportedRes))
+}
+
 var _ p2p.MessageHandler = &noopMessageHandler{}
 
 // noopMessageHandler is a dummy message handler that allows a p2p node to be
@@ -24,15 +24,19 @@ func (s *Service) handlePeerScoreEvent(id peer.ID, event peerScoreEvent) {
 	switch event {
 	case psInvalidMessage:
 		s.node.AddPeerScore(id, "ordersync/invalid-message", -5)
+		s.node.Reputation().RecordOrderSyncFailure(id)
 	case psValidMessage:
 		s.node.SetPeerScore(id, "ordersync/valid-message", 5)
 	case psSubprotocolNegotiationFailed:
 		s.node.SetPeerScore(id, "ordersync/subprotocol-negotiation-failed", -5)
+		s.node.Reputation().RecordOrderSyncFailure(id)
 	case psUnexpectedDisconnect:
 		s.node.AddPeerScore(id, "ordersync/unexpected-disconnect", -1)
+		s.node.Reputation().RecordOrderSyncFailure(id)
 	case receivedOrders:
 		s.node.UnsetPeerScore(id, "ordersync/unexpected-disconnect")
 		s.node.SetPeerScore(id, "ordersync/received-orders", 10)
+		s.node.Reputation().RecordOrderSyncSuccess(id)
 	default:
 		log.WithField("event", event).Error("unknown ordersync peerScoreEvent")
 	}
@@ -0,0 +1,133 @@
+package ordersync
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxSessionQueueTime is the maximum amount of time that an incoming ordersync
+// stream waits for a free session before it is closed. The requester starts
+// waiting for the first response when it opens the stream, so only half of
+// requestResponseTimeout is spent in the queue and the other half is left for
+// preparing and sending the first response.
+const maxSessionQueueTime = requestResponseTimeout / 2
+
+// ProviderLimits restricts the resources used to serve ordersync requests from
+// other peers, so that a large number of peers syncing at once (e.g. after
+// many nodes restart at the same time) can't saturate the uplink of the node.
+// Zero values mean that there is no limit.
+type ProviderLimits struct {
+	// MaxConcurrentSessions is the maximum number of peers which are served at
+	// once. A session lasts until all pages of orders have been sent to the
+	// peer.
+	MaxConcurrentSessions int
+	// MaxQueuedSessions is the maximum number of peers which can wait for a
+	// session while MaxConcurrentSessions peers are being served. Streams from
+	// additional peers are closed immediately and the peers sync with other
+	// nodes instead.
+	MaxQueuedSessions int
+	// MaxBytesPerSecond is the maximum rate at which ordersync responses are
+	// sent, shared between all sessions.
+	MaxBytesPerSecond int
+}
+
+// sessionLimiter limits the number of concurrent ordersync sessions.
+type sessionLimiter struct {
+	sessions  chan struct{}
+	maxQueued int32
+	// queued is the number of streams waiting for a session. It must be
+	// accessed atomically.
+	queued int32
+}
+
+// newSessionLimiter returns a sessionLimiter for the given limits or nil if
+// the number of concurrent sessions is not limited.
+func newSessionLimiter(limits ProviderLimits) *sessionLimiter {
+	if limits.MaxConcurrentSessions <= 0 {
+		return nil
+	}
+	return &sessionLimiter{
+		sessions:  make(chan struct{}, limits.MaxConcurrentSessions),
+		maxQueued: int32(limits.MaxQueuedSessions),
+	}
+}
+
+// acquire waits for a free session. It returns false if the queue is full or
+// if no session became available in time, in which case the stream should be
+// closed. Otherwise, release must be called once the session has ended. A nil
+// sessionLimiter always returns true.
+func (l *sessionLimiter) acquire(ctx context.Context, maxWait time.Duration) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.sessions <- struct{}{}:
+		return true
+	default:
+	}
+	if atomic.AddInt32(&l.queued, 1) > l.maxQueued {
+		atomic.AddInt32(&l.queued, -1)
+		return false
+	}
+	defer atomic.AddInt32(&l.queued, -1)
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+	select {
+	case l.sessions <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return false
+	}
+}
+
+// release ends a session which was started with acquire.
+func (l *sessionLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.sessions
+}
+
+// newBandwidthLimiter returns a rate limiter for the given limits or nil if
+// bandwidth is not limited. The burst size allows up to one second worth of
+// bytes to be written at once.
+func newBandwidthLimiter(limits ProviderLimits) *rate.Limiter {
+	if limits.MaxBytesPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(limits.MaxBytesPerSecond), limits.MaxBytesPerSecond)
+}
+
+// throttledWriter is an io.Writer which waits for the given rate limiter
+// before each write. Large writes are split into chunks of at most the burst
+// size of the limiter.
+type throttledWriter struct {
+	ctx     context.Context
+	writer  io.Writer
+	limiter *rate.Limiter
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunkSize := len(p) - written
+		if chunkSize > w.limiter.Burst() {
+			chunkSize = w.limiter.Burst()
+		}
+		if err := w.limiter.WaitN(w.ctx, chunkSize); err != nil {
+			return written, err
+		}
+		n, err := w.writer.Write(p[written : written+chunkSize])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,62 @@
+package ordersync
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestSessionLimiter(t *testing.T) {
+	ctx := context.Background()
+	// A nil sessionLimiter doesn't limit the number of sessions.
+	var unlimited *sessionLimiter
+	assert.Nil(t, newSessionLimiter(ProviderLimits{}))
+	assert.True(t, unlimited.acquire(ctx, 0))
+	unlimited.release()
+
+	limiter := newSessionLimiter(ProviderLimits{MaxConcurrentSessions: 1, MaxQueuedSessions: 1})
+	require.True(t, limiter.acquire(ctx, time.Second))
+
+	// The second session waits in the queue until the first one is released.
+	acquired := make(chan bool)
+	go func() {
+		acquired <- limiter.acquire(ctx, 5*time.Second)
+	}()
+	for atomic.LoadInt32(&limiter.queued) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The queue is full, so the third session is rejected right away.
+	assert.False(t, limiter.acquire(ctx, 5*time.Second))
+
+	limiter.release()
+	assert.True(t, <-acquired)
+
+	// Sessions give up after waiting for maxWait.
+	assert.False(t, limiter.acquire(ctx, 10*time.Millisecond))
+	limiter.release()
+}
+
+func TestThrottledWriter(t *testing.T) {
+	var buf bytes.Buffer
+	limiter := rate.NewLimiter(rate.Limit(1000), 10)
+	writer := &throttledWriter{ctx: context.Background(), writer: &buf, limiter: limiter}
+	data := bytes.Repeat([]byte{1}, 35)
+	n, err := writer.Write(data)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, data, buf.Bytes())
+
+	// Writes fail once the context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	writer = &throttledWriter{ctx: ctx, writer: &buf, limiter: rate.NewLimiter(rate.Limit(1), 1)}
+	_, err = writer.Write(data)
+	assert.Error(t, err)
+}
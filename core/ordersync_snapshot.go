@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// orderSyncSnapshot is an immutable view of all the orders in the database
+// (not including removed orders) which is used to serve ordersync requests.
+type orderSyncSnapshot struct {
+	id        string
+	createdAt time.Time
+	orders    []*zeroex.SignedOrder
+}
+
+// page returns the orders for the given page.
+func (s *orderSyncSnapshot) page(page, perPage int) []*zeroex.SignedOrder {
+	start := page * perPage
+	if start >= len(s.orders) {
+		return nil
+	}
+	end := start + perPage
+	if end > len(s.orders) {
+		end = len(s.orders)
+	}
+	return s.orders[start:end]
+}
+
+// orderSyncSnapshots holds the most recently materialized orderSyncSnapshots.
+// The previous snapshot is retained so that peers which started syncing just
+// before a new snapshot was materialized can finish paginating through it.
+type orderSyncSnapshots struct {
+	mu       sync.RWMutex
+	current  *orderSyncSnapshot
+	previous *orderSyncSnapshot
+}
+
+// get returns the snapshot with the given ID or the current snapshot if id is
+// empty. It returns nil if there is no such snapshot.
+func (s *orderSyncSnapshots) get(id string) *orderSyncSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch {
+	case id == "":
+		return s.current
+	case s.current != nil && s.current.id == id:
+		return s.current
+	case s.previous != nil && s.previous.id == id:
+		return s.previous
+	default:
+		return nil
+	}
+}
+
+func (s *orderSyncSnapshots) add(snapshot *orderSyncSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.previous = s.current
+	s.current = snapshot
+}
+
+// materializeOrderSyncSnapshot loads all the orders which have not been
+// removed from the database into a new orderSyncSnapshot.
+func (app *App) materializeOrderSyncSnapshot() (*orderSyncSnapshot, error) {
+	createdAt := time.Now().UTC()
+	orders, err := app.db.FindNotRemovedOrders()
+	if err != nil {
+		return nil, err
+	}
+	signedOrders := make([]*zeroex.SignedOrder, len(orders))
+	for i, order := range orders {
+		signedOrders[i] = order.SignedOrder
+	}
+	return &orderSyncSnapshot{
+		id:        uuid.New().String(),
+		createdAt: createdAt,
+		orders:    signedOrders,
+	}, nil
+}
+
+// periodicallyMaterializeOrderSyncSnapshots materializes a new
+// orderSyncSnapshot every config.OrderSyncSnapshotInterval until the context is
+// canceled.
+func (app *App) periodicallyMaterializeOrderSyncSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(app.config.OrderSyncSnapshotInterval)
+	defer ticker.Stop()
+	for {
+		// Snapshots are not materialized while the page is hidden in the
+		// browser. The previous snapshot is still used to serve requests.
+		if !app.isHidden() {
+			app.materializeAndAddOrderSyncSnapshot()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// materializeAndAddOrderSyncSnapshot materializes a new ordersync snapshot and
+// adds it to app.orderSyncSnapshots.
+func (app *App) materializeAndAddOrderSyncSnapshot() {
+	start := time.Now()
+	snapshot, err := app.materializeOrderSyncSnapshot()
+	if err != nil {
+		log.WithError(err).Error("could not materialize ordersync snapshot")
+		return
+	}
+	app.orderSyncSnapshots.add(snapshot)
+	log.WithFields(log.Fields{
+		"snapshotID": snapshot.id,
+		"numOrders":  len(snapshot.orders),
+		"duration":   time.Since(start).String(),
+	}).Debug("materialized ordersync snapshot")
+}
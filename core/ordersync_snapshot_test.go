@@ -0,0 +1,46 @@
+// +build !js
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderSyncSnapshotPage(t *testing.T) {
+	t.Parallel()
+	orders := make([]*zeroex.SignedOrder, 5)
+	for i := range orders {
+		orders[i] = &zeroex.SignedOrder{Order: zeroex.Order{Salt: big.NewInt(int64(i))}}
+	}
+	snapshot := &orderSyncSnapshot{id: "a", orders: orders}
+	assert.Equal(t, orders[0:2], snapshot.page(0, 2))
+	assert.Equal(t, orders[2:4], snapshot.page(1, 2))
+	assert.Equal(t, orders[4:5], snapshot.page(2, 2))
+	assert.Empty(t, snapshot.page(3, 2))
+}
+
+func TestOrderSyncSnapshotsGet(t *testing.T) {
+	t.Parallel()
+	snapshots := orderSyncSnapshots{}
+	assert.Nil(t, snapshots.get(""))
+	assert.Nil(t, snapshots.get("a"))
+
+	a := &orderSyncSnapshot{id: "a"}
+	b := &orderSyncSnapshot{id: "b"}
+	c := &orderSyncSnapshot{id: "c"}
+	snapshots.add(a)
+	snapshots.add(b)
+	assert.Equal(t, b, snapshots.get(""))
+	assert.Equal(t, a, snapshots.get("a"))
+	assert.Equal(t, b, snapshots.get("b"))
+
+	// Only the two most recent snapshots are retained.
+	snapshots.add(c)
+	assert.Equal(t, c, snapshots.get(""))
+	assert.Nil(t, snapshots.get("a"))
+	assert.Equal(t, b, snapshots.get("b"))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/core/ordersync"
 	"github.com/0xProject/0x-mesh/orderfilter"
 	"github.com/0xProject/0x-mesh/zeroex"
@@ -50,8 +51,13 @@ type FilteredPaginationResponseMetadata struct {
 	SnapshotID string `json:"snapshotID"`
 }
 
-// Name returns the name of the FilteredPaginationSubProtocol
+// Name returns the name of the FilteredPaginationSubProtocol. Nodes in a
+// private network use a different name so that they only sync orders with
+// other nodes in the same network.
 func (p *FilteredPaginationSubProtocol) Name() string {
+	if networkID := p.orderFilter.NetworkID(); networkID != "" {
+		return fmt.Sprintf("/pagination-with-filter/network/%s/version/0", networkID)
+	}
 	return "/pagination-with-filter/version/0"
 }
 
@@ -87,28 +93,29 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncRequest(ctx context.Conte
 		default:
 		}
 		// Get the orders for this page.
-		ordersResp, err := p.app.GetOrders(currentPage, p.perPage, metadata.SnapshotID)
+		var orders []*zeroex.SignedOrder
+		var err error
+		orders, snapshotID, err = p.getOrders(currentPage, metadata.SnapshotID)
 		if err != nil {
 			return nil, err
 		}
-		snapshotID = ordersResp.SnapshotID
-		if len(ordersResp.OrdersInfos) == 0 {
+		if len(orders) == 0 {
 			// No more orders left.
 			break
 		}
+		// Soft cancelled orders are not shared with peers.
+		orders = p.app.dropSoftCancelledOrders(orders)
 		// Filter the orders for this page.
 		if metadata.OrderFilter != nil {
-			for _, orderInfo := range ordersResp.OrdersInfos {
-				if matches, err := metadata.OrderFilter.MatchOrder(orderInfo.SignedOrder); err != nil {
+			for _, order := range orders {
+				if matches, err := metadata.OrderFilter.MatchOrder(order); err != nil {
 					return nil, err
 				} else if matches {
-					filteredOrders = append(filteredOrders, orderInfo.SignedOrder)
+					filteredOrders = append(filteredOrders, order)
 				}
 			}
 		} else {
-			for _, orderInfo := range ordersResp.OrdersInfos {
-				filteredOrders = append(filteredOrders, orderInfo.SignedOrder)
-			}
+			filteredOrders = append(filteredOrders, orders...)
 		}
 		if len(filteredOrders) == 0 {
 			// If none of the orders for this page match the filter, we continue
@@ -130,6 +137,26 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncRequest(ctx context.Conte
 	}, nil
 }
 
+// getOrders returns the orders for the given page along with the ID of the
+// snapshot they were taken from. If ordersync snapshots are enabled, orders are
+// served from the materialized snapshot with the given ID (or the latest one if
+// snapshotID is empty). Otherwise, or if no such snapshot exists, orders are
+// read from a database snapshot via GetOrders.
+func (p *FilteredPaginationSubProtocol) getOrders(page int, snapshotID string) ([]*zeroex.SignedOrder, string, error) {
+	if snapshot := p.app.orderSyncSnapshots.get(snapshotID); snapshot != nil {
+		return snapshot.page(page, p.perPage), snapshot.id, nil
+	}
+	ordersResp, err := p.app.GetOrders(page, p.perPage, snapshotID, types.GetOrdersOpts{})
+	if err != nil {
+		return nil, "", err
+	}
+	orders := make([]*zeroex.SignedOrder, len(ordersResp.OrdersInfos))
+	for i, orderInfo := range ordersResp.OrdersInfos {
+		orders[i] = orderInfo.SignedOrder
+	}
+	return orders, ordersResp.SnapshotID, nil
+}
+
 // HandleOrderSyncResponse handles the orders for one page by validating them, storing them
 // in the database, and firing the appropriate events. It also returns the next request to
 // be sent. This is the implementation for the "requester" side of the subprotocol.
@@ -141,8 +168,19 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncResponse(ctx context.Cont
 	if !ok {
 		return nil, fmt.Errorf("FilteredPaginationSubProtocol received response with wrong metadata type (got %T)", res.Metadata)
 	}
+	if p.app.isIngestionPaused() {
+		// Stop requesting pages from this peer. The orders are received with
+		// the next run of ordersync after ingestion is resumed.
+		return nil, ordersync.ErrRequestsPaused
+	}
 	filteredOrders := []*zeroex.SignedOrder{}
 	for _, order := range res.Orders {
+		domain := newOrderDomain(order)
+		if status, mismatched := p.app.checkOrderDomain(domain); mismatched {
+			p.app.recordMismatchedDomainOrder(domain, status, "ordersync")
+			p.app.handlePeerScoreEvent(res.ProviderID, psReceivedOrderDoesNotMatchFilter)
+			continue
+		}
 		if matches, err := p.orderFilter.MatchOrder(order); err != nil {
 			return nil, err
 		} else if matches {
@@ -151,7 +189,15 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncResponse(ctx context.Cont
 			p.app.handlePeerScoreEvent(res.ProviderID, psReceivedOrderDoesNotMatchFilter)
 		}
 	}
-	validationResults, err := p.app.orderWatcher.ValidateAndStoreValidOrders(ctx, filteredOrders, false, p.app.chainID)
+	// Note: Taker-restricted orders are dropped because of our own policy, so
+	// the peer's score isn't affected.
+	filteredOrders = p.app.dropTakerRestrictedOrders(filteredOrders)
+	filteredOrders, reservedBytes, err := p.app.validationMemory.reserve(ctx, filteredOrders)
+	if err != nil {
+		return nil, err
+	}
+	defer p.app.validationMemory.release(reservedBytes)
+	validationResults, err := p.app.validateAndStoreOrders(ctx, filteredOrders, false)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +214,7 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncResponse(ctx context.Cont
 				"from":      res.ProviderID.Pretty(),
 				"protocol":  "ordersync",
 			}).Trace("all fields for new valid order received from peer")
+			p.app.recordOrderContribution(res.ProviderID)
 		}
 	}
 
@@ -15,6 +15,11 @@ const (
 )
 
 func (app *App) handlePeerScoreEvent(id peer.ID, event peerScoreEvent) {
+	if app.node == nil {
+		// There is no p2p node when replaying a recording, so there are no peer
+		// scores to update.
+		return
+	}
 	// Note: for some events, we use `SetPeerScore` instead of `AddPeerScore` in
 	// order to limit the maximum positive score associated with that event.
 	// Without this, peers could be incentivized to artificially increase their
@@ -23,13 +28,29 @@ func (app *App) handlePeerScoreEvent(id peer.ID, event peerScoreEvent) {
 	switch event {
 	case psInvalidMessage:
 		app.node.AddPeerScore(id, "invalid-message", -5)
+		app.node.Reputation().RecordInvalidMessage(id)
 	case psValidMessage:
 		app.node.SetPeerScore(id, "valid-message", 5)
+		app.node.Reputation().RecordValidMessage(id)
 	case psOrderStored:
 		app.node.SetPeerScore(id, "order-stored", 10)
 	case psReceivedOrderDoesNotMatchFilter:
 		app.node.SetPeerScore(id, "received-order-does-not-match-filter", -10)
+		app.node.Reputation().RecordInvalidMessage(id)
 	default:
 		log.WithField("event", event).Error("unknown peerScoreEvent")
 	}
 }
+
+// recordOrderContribution records that the given neighbor sent us a new valid
+// order, either by relaying it via GossipSub or via ordersync. Contributions
+// must be credited to the neighbor rather than to the original publisher of
+// the order, since only connections to neighbors can be protected or pruned.
+func (app *App) recordOrderContribution(neighbor peer.ID) {
+	if app.node == nil || neighbor == "" {
+		// There is no p2p node when replaying a recording, and recorded
+		// messages may not contain the neighbor they were received from.
+		return
+	}
+	app.node.RecordOrderContribution(neighbor)
+}
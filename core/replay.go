@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/0xProject/0x-mesh/replay"
+	log "github.com/sirupsen/logrus"
+)
+
+// Ensure that App implements replay.Handler.
+var _ replay.Handler = &App{}
+
+// startRecording starts recording all pubsub messages and block events to
+// app.config.ReplayRecordPath. The recording is closed when ctx is canceled.
+func (app *App) startRecording(ctx context.Context, wg *sync.WaitGroup) error {
+	recorder, err := replay.NewRecorder(app.config.ReplayRecordPath)
+	if err != nil {
+		return err
+	}
+	app.recorder = recorder
+	log.WithField("path", app.config.ReplayRecordPath).Info("recording messages and block events for replay")
+
+	blockEvents := make(chan []*blockwatch.Event, 100)
+	blockSubscription := app.blockWatcher.Subscribe(blockEvents)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing replay recorder")
+		}()
+		defer blockSubscription.Unsubscribe()
+		defer recorder.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-blockSubscription.Err():
+				log.WithError(err).Error("block subscription error encountered while recording")
+				return
+			case events := <-blockEvents:
+				app.recordBlockEvents(events)
+			}
+		}
+	}()
+	return nil
+}
+
+func (app *App) recordBlockEvents(events []*blockwatch.Event) {
+	if err := app.recorder.RecordBlockEvents(events); err != nil && err != replay.ErrRecorderClosed {
+		log.WithError(err).Error("could not record block events")
+	}
+}
+
+// HandleBlockEvents passes the given block events to the order watcher. It is
+// used when replaying a recording.
+func (app *App) HandleBlockEvents(ctx context.Context, events []*blockwatch.Event) error {
+	return app.orderWatcher.HandleBlockEvents(ctx, events)
+}
+
+// Replay replays the recording at the given path (created by setting
+// ReplayRecordPath) into the App. It should be called on a fresh App (i.e.
+// one with an empty DataDir) instead of Start. The App does not connect to any
+// peers or watch for new blocks while replaying, and each recorded message or
+// block event is fully processed before the next one so that replays are
+// deterministic. Note that orders are still validated by making eth_calls
+// against the configured Ethereum RPC endpoint, which should be an archive
+// node if the recorded blocks are old.
+func (app *App) Replay(ctx context.Context, path string) (int, error) {
+	if app.config.ReplayRecordPath != "" {
+		return 0, errors.New("cannot record while replaying (unset ReplayRecordPath)")
+	}
+	defer app.db.Close()
+	log.WithField("path", path).Info("replaying recorded messages and block events")
+	numReplayed, err := replay.Replay(ctx, path, app)
+	log.WithFields(log.Fields{
+		"path":        path,
+		"numReplayed": numReplayed,
+	}).Info("finished replaying recording")
+	return numReplayed, err
+}
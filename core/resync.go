@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	mathrand "math/rand"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// resyncOrderSyncTimeout is the maximum amount of time to spend performing
+// ordersync during a resync.
+const resyncOrderSyncTimeout = 1 * time.Minute
+
+// Resync recovers a node which is stuck (e.g. because it is following a chain
+// which is no longer canonical or it missed orders) without restarting it or
+// wiping its state. It drops all stored block headers and starts again from
+// the latest block, re-validates all stored orders at that block, and then
+// performs ordersync with the given peers. If no peers are given, it performs
+// ordersync with randomly selected neighbors until orders were received from
+// ordersyncMinPeers of them. Only one resync runs at a time.
+func (app *App) Resync(ctx context.Context, peerIDs []peer.ID) (*types.ResyncResponse, error) {
+	<-app.started
+
+	app.resyncMu.Lock()
+	defer app.resyncMu.Unlock()
+
+	start := time.Now()
+	log.WithField("peers", peerIDs).Info("resyncing blocks and orders")
+	latestHeader, err := app.blockWatcher.Reset(func(latestHeader *miniheader.MiniHeader) error {
+		return app.orderWatcher.ResetMiniHeaders(latestHeader)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := app.orderWatcher.Cleanup(ctx, 0*time.Minute); err != nil {
+		return nil, err
+	}
+
+	orderSyncCtx, cancel := context.WithTimeout(ctx, resyncOrderSyncTimeout)
+	defer cancel()
+	response := &types.ResyncResponse{
+		LatestBlock: types.LatestBlock{
+			Number: int(latestHeader.Number.Int64()),
+			Hash:   latestHeader.Hash,
+		},
+		SyncedPeers: []string{},
+		FailedPeers: []string{},
+	}
+	minPeers := len(peerIDs)
+	if len(peerIDs) == 0 {
+		peerIDs = app.node.Neighbors()
+		mathrand.Shuffle(len(peerIDs), func(i, j int) {
+			peerIDs[i], peerIDs[j] = peerIDs[j], peerIDs[i]
+		})
+		minPeers = ordersyncMinPeers
+	}
+	for _, peerID := range peerIDs {
+		if len(response.SyncedPeers) >= minPeers || orderSyncCtx.Err() != nil {
+			break
+		}
+		if err := app.ordersyncService.GetOrdersFromPeer(orderSyncCtx, peerID); err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"peer":  peerID.Pretty(),
+			}).Warn("could not get orders from peer via ordersync during resync")
+			response.FailedPeers = append(response.FailedPeers, peerID.Pretty())
+			continue
+		}
+		response.SyncedPeers = append(response.SyncedPeers, peerID.Pretty())
+	}
+
+	log.WithFields(log.Fields{
+		"latestBlockNumber": response.LatestBlock.Number,
+		"syncedPeers":       len(response.SyncedPeers),
+		"failedPeers":       len(response.FailedPeers),
+		"duration":          time.Since(start).String(),
+	}).Info("finished resyncing blocks and orders")
+	return response, nil
+}
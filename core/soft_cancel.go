@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0xProject/0x-mesh/core/softcancel"
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/zeroex"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxSoftCancels is the maximum number of soft cancellations which are kept in
+// memory. Once it is reached, the oldest soft cancellations are forgotten.
+const maxSoftCancels = 100000
+
+// ErrSoftCancelsDisabled is returned by SoftCancelOrder if EnableSoftCancels is
+// false.
+var ErrSoftCancelsDisabled = errors.New("soft cancellations are disabled (ENABLE_SOFT_CANCELS is false)")
+
+// softCancelTopic returns the pubsub topic on which soft cancellations are
+// gossiped.
+func (app *App) softCancelTopic() string {
+	return softcancel.Topic(app.chainID, app.config.NetworkID)
+}
+
+// isSoftCancelled returns true if soft cancellations are enabled and the given
+// order was soft cancelled by its maker.
+func (app *App) isSoftCancelled(order *zeroex.SignedOrder) bool {
+	return app.softCancels != nil && app.softCancels.IsCancelled(order)
+}
+
+// dropSoftCancelledMessages wraps the given GossipSub validator so that
+// messages which contain soft cancelled orders are rejected if soft
+// cancellations are enabled. Rejected messages are neither handled nor relayed
+// to other peers.
+func (app *App) dropSoftCancelledMessages(validator pubsub.Validator) pubsub.Validator {
+	if app.softCancels == nil {
+		return validator
+	}
+	return func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+		if !validator(ctx, sender, msg) {
+			return false
+		}
+		order, err := encoding.RawMessageToOrder(msg.Data)
+		if err != nil {
+			return false
+		}
+		return !app.softCancels.IsCancelled(order)
+	}
+}
+
+// dropSoftCancelledOrders returns the given orders without the soft cancelled
+// ones if soft cancellations are enabled.
+func (app *App) dropSoftCancelledOrders(orders []*zeroex.SignedOrder) []*zeroex.SignedOrder {
+	if app.softCancels == nil {
+		return orders
+	}
+	filteredOrders := make([]*zeroex.SignedOrder, 0, len(orders))
+	for _, order := range orders {
+		if !app.softCancels.IsCancelled(order) {
+			filteredOrders = append(filteredOrders, order)
+		}
+	}
+	return filteredOrders
+}
+
+// handleSoftCancelMessages stores the soft cancellations contained in the given
+// messages, which were received on the soft cancel topic.
+func (app *App) handleSoftCancelMessages(messages []*p2p.Message) {
+	for _, msg := range messages {
+		softCancel, err := softcancel.Decode(msg.Data)
+		if err == nil {
+			err = softCancel.Verify()
+		}
+		if err != nil {
+			log.WithFields(map[string]interface{}{
+				"error": err,
+				"from":  msg.From,
+			}).Trace("received invalid soft cancellation")
+			app.handlePeerScoreEvent(msg.From, psInvalidMessage)
+			continue
+		}
+		if !app.softCancels.Add(softCancel) {
+			continue
+		}
+		log.WithFields(map[string]interface{}{
+			"orderHash":    softCancel.OrderHash.Hex(),
+			"makerAddress": softCancel.MakerAddress.Hex(),
+			"from":         msg.From.String(),
+		}).Debug("received soft cancellation from peer")
+	}
+}
+
+// SoftCancelOrder stores the given soft cancellation and shares it with peers.
+// Afterwards, this node and all peers which support soft cancellations stop
+// sharing the order via GossipSub and ordersync. The order itself stays in the
+// database and can still be filled until it expires or is cancelled on-chain.
+// The soft cancellation must be signed by the maker of the order. It is not
+// checked whether the order is known, since the maker might want to soft
+// cancel an order before it reaches this node.
+func (app *App) SoftCancelOrder(ctx context.Context, softCancel *softcancel.SoftCancel) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-app.started:
+	}
+
+	if app.softCancels == nil {
+		return ErrSoftCancelsDisabled
+	}
+	if err := softCancel.Verify(); err != nil {
+		return err
+	}
+	app.softCancels.Add(softCancel)
+	encoded, err := softcancel.Encode(softCancel)
+	if err != nil {
+		return err
+	}
+	log.WithFields(map[string]interface{}{
+		"orderHash":    softCancel.OrderHash.Hex(),
+		"makerAddress": softCancel.MakerAddress.Hex(),
+	}).Info("sharing soft cancellation")
+	return app.node.SendToTopic(app.softCancelTopic(), encoded)
+}
@@ -0,0 +1,221 @@
+// Package softcancel contains soft cancellations, which are messages signed by
+// the maker of an order that ask Mesh nodes to stop sharing the order with
+// their peers. Unlike cancelling an order on-chain, a soft cancellation doesn't
+// cost any gas. It is only advisory though: anyone who already has the order
+// can still fill it until it expires or is cancelled on-chain.
+//
+// Soft cancellations are gossiped on a separate pubsub topic for each chain
+// (see Topic). Nodes which support them keep the orders in their database, but
+// no longer share them via GossipSub or ordersync.
+package softcancel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/ethereum/signer"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+const (
+	topicVersion       = 0
+	topicFormat        = "/0x-soft-cancels/version/%d/chain/%d"
+	networkTopicFormat = "/0x-soft-cancels/network/%s/version/%d/chain/%d"
+	// messageType is the message type of encoded soft cancellations. It
+	// distinguishes them from order messages.
+	messageType = "softCancel"
+	// hashPrefix is prepended to the order hash before it is hashed and
+	// signed, so that the signature of a soft cancellation can't be mistaken
+	// for the signature of the order itself (which is over the bare order
+	// hash).
+	hashPrefix = "0x-mesh-soft-cancel:"
+)
+
+// ErrInvalidSignature is returned by Verify if the soft cancellation was not
+// signed by the maker it names.
+var ErrInvalidSignature = errors.New("soft cancellation was not signed by the maker")
+
+// Topic returns the pubsub topic on which soft cancellations are gossiped for
+// the given chain. networkID is the ID of the private network the node belongs
+// to or empty for the main network.
+func Topic(chainID int, networkID string) string {
+	if networkID != "" {
+		return fmt.Sprintf(networkTopicFormat, networkID, topicVersion, chainID)
+	}
+	return fmt.Sprintf(topicFormat, topicVersion, chainID)
+}
+
+// SoftCancel is a request by the maker of the order with the given hash to stop
+// sharing it.
+type SoftCancel struct {
+	OrderHash    common.Hash    `json:"orderHash"`
+	MakerAddress common.Address `json:"makerAddress"`
+	// Signature is a 0x EthSign or EIP712 signature of Hash(OrderHash) by
+	// MakerAddress.
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Hash returns the hash which the maker signs in order to soft cancel the
+// order with the given hash.
+func Hash(orderHash common.Hash) common.Hash {
+	return common.BytesToHash(crypto.Keccak256([]byte(hashPrefix), orderHash.Bytes()))
+}
+
+// Sign creates a soft cancellation for the order with the given hash which is
+// signed by makerAddress using s.
+func Sign(s signer.Signer, orderHash common.Hash, makerAddress common.Address) (*SoftCancel, error) {
+	ecSignature, err := s.EthSign(Hash(orderHash).Bytes(), makerAddress)
+	if err != nil {
+		return nil, err
+	}
+	signature := make([]byte, 66)
+	signature[0] = ecSignature.V
+	copy(signature[1:33], ecSignature.R[:])
+	copy(signature[33:65], ecSignature.S[:])
+	signature[65] = byte(zeroex.EthSignSignature)
+	return &SoftCancel{
+		OrderHash:    orderHash,
+		MakerAddress: makerAddress,
+		Signature:    signature,
+	}, nil
+}
+
+// Verify returns ErrInvalidSignature if the soft cancellation was not signed by
+// its maker. Only signatures whose signer can be recovered off-chain (i.e.
+// EthSign and EIP712 signatures) are supported.
+func (c *SoftCancel) Verify() error {
+	recoveredSigner, err := zeroex.RecoverSigner(Hash(c.OrderHash), c.Signature)
+	if err != nil {
+		return err
+	}
+	if recoveredSigner != c.MakerAddress {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+type softCancelMessage struct {
+	MessageType string      `json:"messageType"`
+	SoftCancel  *SoftCancel `json:"softCancel"`
+}
+
+// Encode encodes the soft cancellation into a message to be sent over the
+// wire.
+func Encode(softCancel *SoftCancel) ([]byte, error) {
+	return json.Marshal(softCancelMessage{
+		MessageType: messageType,
+		SoftCancel:  softCancel,
+	})
+}
+
+// Decode decodes a soft cancellation message sent over the wire. It doesn't
+// verify the signature.
+func Decode(data []byte) (*SoftCancel, error) {
+	var message softCancelMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+	if message.MessageType != messageType {
+		return nil, fmt.Errorf("unexpected message type: %q", message.MessageType)
+	}
+	if message.SoftCancel == nil {
+		return nil, errors.New("message does not contain a soft cancellation")
+	}
+	return message.SoftCancel, nil
+}
+
+// Dummy declaration to ensure that ValidatePubSubMessage matches the expected
+// signature for pubsub.Validator.
+var _ pubsub.Validator = ValidatePubSubMessage
+
+// ValidatePubSubMessage is an implementation of pubsub.Validator which returns
+// true if the message contains a soft cancellation that was signed by its
+// maker. Invalid soft cancellations are neither handled nor relayed to peers.
+func ValidatePubSubMessage(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+	softCancel, err := Decode(msg.Data)
+	if err != nil {
+		return false
+	}
+	return softCancel.Verify() == nil
+}
+
+// storeKey identifies a soft cancellation in a Store. It includes the maker so
+// that a soft cancellation signed by someone other than the maker of the order
+// can't prevent the actual maker from soft cancelling it.
+type storeKey struct {
+	orderHash    common.Hash
+	makerAddress common.Address
+}
+
+// Store holds up to a fixed number of verified soft cancellations in memory.
+// When it is full, the oldest soft cancellations are forgotten first. It is
+// safe for concurrent use.
+type Store struct {
+	mu      sync.RWMutex
+	maxSize int
+	cancels map[storeKey]struct{}
+	// keys contains the keys of the soft cancellations in the order in which
+	// they were added. It is used as a ring buffer and next is the index of
+	// the oldest entry once the store is full.
+	keys []storeKey
+	next int
+}
+
+// NewStore returns a store which holds up to maxSize soft cancellations.
+// maxSize must be positive.
+func NewStore(maxSize int) *Store {
+	return &Store{
+		maxSize: maxSize,
+		cancels: map[storeKey]struct{}{},
+		keys:    make([]storeKey, 0, maxSize),
+	}
+}
+
+// Add adds the given soft cancellation, which must already be verified, to the
+// store. It returns false if the store already contains it.
+func (s *Store) Add(softCancel *SoftCancel) bool {
+	key := storeKey{orderHash: softCancel.OrderHash, makerAddress: softCancel.MakerAddress}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.cancels[key]; found {
+		return false
+	}
+	if len(s.keys) < s.maxSize {
+		s.keys = append(s.keys, key)
+	} else {
+		delete(s.cancels, s.keys[s.next])
+		s.keys[s.next] = key
+		s.next = (s.next + 1) % s.maxSize
+	}
+	s.cancels[key] = struct{}{}
+	return true
+}
+
+// IsCancelled returns true if the given order was soft cancelled by its
+// maker.
+func (s *Store) IsCancelled(order *zeroex.SignedOrder) bool {
+	orderHash, err := order.ComputeOrderHash()
+	if err != nil {
+		return false
+	}
+	key := storeKey{orderHash: orderHash, makerAddress: order.MakerAddress}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, found := s.cancels[key]
+	return found
+}
+
+// Len returns the number of soft cancellations in the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.cancels)
+}
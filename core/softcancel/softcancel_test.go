@@ -0,0 +1,94 @@
+package softcancel
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum/signer"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerifySoftCancel(t *testing.T) {
+	orderHash := common.HexToHash("0x1")
+	softCancel, err := Sign(signer.NewTestSigner(), orderHash, constants.GanacheAccount0)
+	require.NoError(t, err)
+	require.NoError(t, softCancel.Verify())
+
+	// A soft cancellation which claims to be from a different maker should be
+	// rejected.
+	forged := *softCancel
+	forged.MakerAddress = constants.GanacheAccount1
+	assert.Equal(t, ErrInvalidSignature, forged.Verify())
+
+	// The signature must not be valid for a different order.
+	forged = *softCancel
+	forged.OrderHash = common.HexToHash("0x2")
+	assert.Equal(t, ErrInvalidSignature, forged.Verify())
+}
+
+func TestEncodeAndDecodeSoftCancel(t *testing.T) {
+	softCancel, err := Sign(signer.NewTestSigner(), common.HexToHash("0x1"), constants.GanacheAccount0)
+	require.NoError(t, err)
+	encoded, err := Encode(softCancel)
+	require.NoError(t, err)
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, softCancel, decoded)
+
+	_, err = Decode([]byte(`{"messageType":"order","order":{}}`))
+	assert.Error(t, err, "order messages should not be decoded as soft cancellations")
+	_, err = Decode([]byte(`{"messageType":"softCancel"}`))
+	assert.Error(t, err)
+}
+
+func TestTopic(t *testing.T) {
+	assert.Equal(t, "/0x-soft-cancels/version/0/chain/1337", Topic(1337, ""))
+	assert.Equal(t, "/0x-soft-cancels/network/my-network/version/0/chain/1337", Topic(1337, "my-network"))
+}
+
+func TestStore(t *testing.T) {
+	orders := make([]*zeroex.SignedOrder, 3)
+	for i := range orders {
+		orders[i] = &zeroex.SignedOrder{
+			Order: zeroex.Order{
+				ChainID:               big.NewInt(constants.TestChainID),
+				MakerAddress:          constants.GanacheAccount0,
+				MakerAssetAmount:      big.NewInt(1),
+				MakerFee:              big.NewInt(0),
+				TakerAssetAmount:      big.NewInt(1),
+				TakerFee:              big.NewInt(0),
+				ExpirationTimeSeconds: big.NewInt(0),
+				Salt:                  big.NewInt(int64(i)),
+			},
+		}
+	}
+	softCancelFor := func(order *zeroex.SignedOrder, makerAddress common.Address) *SoftCancel {
+		orderHash, err := order.ComputeOrderHash()
+		require.NoError(t, err)
+		return &SoftCancel{OrderHash: orderHash, MakerAddress: makerAddress}
+	}
+
+	store := NewStore(2)
+	// A soft cancellation by someone other than the maker doesn't apply to the
+	// order and doesn't prevent the maker from soft cancelling it.
+	assert.True(t, store.Add(softCancelFor(orders[0], constants.GanacheAccount1)))
+	assert.False(t, store.IsCancelled(orders[0]))
+	assert.True(t, store.Add(softCancelFor(orders[0], constants.GanacheAccount0)))
+	assert.True(t, store.IsCancelled(orders[0]))
+	assert.False(t, store.Add(softCancelFor(orders[0], constants.GanacheAccount0)))
+	assert.Equal(t, 2, store.Len())
+
+	// The oldest soft cancellation is forgotten once the store is full.
+	assert.True(t, store.Add(softCancelFor(orders[1], constants.GanacheAccount0)))
+	assert.Equal(t, 2, store.Len())
+	assert.True(t, store.IsCancelled(orders[0]))
+	assert.True(t, store.IsCancelled(orders[1]))
+	assert.True(t, store.Add(softCancelFor(orders[2], constants.GanacheAccount0)))
+	assert.False(t, store.IsCancelled(orders[0]))
+	assert.True(t, store.IsCancelled(orders[1]))
+	assert.True(t, store.IsCancelled(orders[2]))
+}
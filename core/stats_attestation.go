@@ -0,0 +1,47 @@
+package core
+
+import (
+	"github.com/0xProject/0x-mesh/core/statsattestation"
+	"github.com/0xProject/0x-mesh/meshdb"
+)
+
+// statsAttestationSource provides the stats which are signed and sent to
+// network crawlers. It implements statsattestation.StatsSource.
+type statsAttestationSource struct {
+	app *App
+	// checksums is shared with the order checksum service, so that the orders
+	// are counted at most once per orderChecksumCacheTTL.
+	checksums *orderChecksumCache
+}
+
+// Ensure that statsAttestationSource implements statsattestation.StatsSource.
+var _ statsattestation.StatsSource = &statsAttestationSource{}
+
+// AttestationStats implements statsattestation.StatsSource.
+func (s *statsAttestationSource) AttestationStats() (*statsattestation.Attestation, error) {
+	attestation := &statsattestation.Attestation{
+		Version: version,
+		ChainID: s.app.chainID,
+		Topics:  []*statsattestation.TopicStats{},
+	}
+	latestBlockHeader, err := s.app.db.FindLatestMiniHeader()
+	if err != nil {
+		if _, ok := err.(meshdb.MiniHeaderCollectionEmptyError); !ok {
+			return nil, err
+		}
+	} else {
+		attestation.LatestBlockNumber = latestBlockHeader.Number.Uint64()
+		attestation.LatestBlockHash = latestBlockHeader.Hash
+	}
+	checksums, err := s.checksums.Checksums(s.app.orderChecksumTopics())
+	if err != nil {
+		return nil, err
+	}
+	for _, checksum := range checksums {
+		attestation.Topics = append(attestation.Topics, &statsattestation.TopicStats{
+			Topic:     checksum.Topic,
+			NumOrders: checksum.NumOrders,
+		})
+	}
+	return attestation, nil
+}
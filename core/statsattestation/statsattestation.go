@@ -0,0 +1,256 @@
+// Package statsattestation contains the stats attestation protocol, which
+// network crawlers use to collect stats about Mesh nodes (e.g. for health
+// dashboards) that are signed with the private key of each node. Since the
+// peer ID of a node is derived from its public key, anyone can verify that a
+// signed attestation was created by the node it claims to describe.
+//
+// A requester opens a stream and sends a single JSON-encoded Request which
+// contains a random nonce. The provider responds with a single JSON-encoded
+// SignedAttestation which includes the nonce, so that old attestations can't be
+// replayed, and closes the stream.
+package statsattestation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/ethereum/go-ethereum/common"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// ID is the ID for the stats attestation protocol.
+const ID = protocol.ID("/0x-mesh/stats-attestation/version/0")
+
+const (
+	// maxNonceLength is the maximum length of the nonce in a request.
+	maxNonceLength = 128
+	// maxMessageSize is the maximum size of a single request or response.
+	maxMessageSize = 64 * 1024
+	// requestResponseTimeout is how long to wait for a request or response.
+	requestResponseTimeout = 10 * time.Second
+	// maxRequestsPerSecond is the maximum number of requests per second that
+	// will be handled for all peers combined.
+	maxRequestsPerSecond = 1
+	// requestsBurst is the maximum number of requests that can be handled at
+	// once.
+	requestsBurst = 5
+	// signaturePrefix is prepended to the encoded attestation before it is
+	// signed, so that a signed attestation can't be mistaken for any other
+	// message signed with the key of the node.
+	signaturePrefix = "0x-mesh-stats-attestation:"
+)
+
+// Request is a request for a signed attestation.
+type Request struct {
+	// Nonce is an arbitrary string chosen by the requester which is included
+	// in the attestation.
+	Nonce string `json:"nonce"`
+}
+
+// TopicStats contains the stats for a single pubsub topic used by the node.
+type TopicStats struct {
+	Topic     string `json:"topic"`
+	NumOrders int    `json:"numOrders"`
+}
+
+// Attestation is a summary of the state of a Mesh node.
+type Attestation struct {
+	PeerID            string        `json:"peerID"`
+	Version           string        `json:"version"`
+	ChainID           int           `json:"chainID"`
+	LatestBlockNumber uint64        `json:"latestBlockNumber"`
+	LatestBlockHash   common.Hash   `json:"latestBlockHash"`
+	Topics            []*TopicStats `json:"topics"`
+	// Timestamp is the time at which the attestation was signed.
+	Timestamp time.Time `json:"timestamp"`
+	// Nonce is the nonce from the request.
+	Nonce string `json:"nonce"`
+}
+
+// SignedAttestation is an Attestation signed with the private key of the node
+// it describes. Attestation contains the JSON encoding of the Attestation,
+// which is exactly the data that was signed (after signaturePrefix).
+type SignedAttestation struct {
+	Attestation json.RawMessage `json:"attestation"`
+	Signature   []byte          `json:"signature"`
+}
+
+// Sign signs the given attestation with privKey.
+func Sign(privKey p2pcrypto.PrivKey, attestation *Attestation) (*SignedAttestation, error) {
+	encodedAttestation, err := json.Marshal(attestation)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := privKey.Sign(append([]byte(signaturePrefix), encodedAttestation...))
+	if err != nil {
+		return nil, err
+	}
+	return &SignedAttestation{
+		Attestation: encodedAttestation,
+		Signature:   signature,
+	}, nil
+}
+
+// Verify checks that the attestation was signed by the node whose peer ID it
+// contains and returns the decoded attestation. It only works for peer IDs
+// which include the public key of the node, which is the case for the
+// secp256k1 keys used by Mesh.
+func (s *SignedAttestation) Verify() (*Attestation, error) {
+	var attestation Attestation
+	if err := json.Unmarshal(s.Attestation, &attestation); err != nil {
+		return nil, err
+	}
+	peerID, err := peer.IDB58Decode(attestation.PeerID)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := peerID.ExtractPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("could not extract public key from peer ID %s: %s", attestation.PeerID, err.Error())
+	}
+	valid, err := pubKey.Verify(append([]byte(signaturePrefix), s.Attestation...), s.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("invalid stats attestation signature")
+	}
+	return &attestation, nil
+}
+
+// StatsSource provides the stats of a Mesh node.
+type StatsSource interface {
+	// AttestationStats returns the current stats of the node. PeerID,
+	// Timestamp and Nonce are filled in by the Service.
+	AttestationStats() (*Attestation, error)
+}
+
+// Service is the provider side of the stats attestation protocol.
+type Service struct {
+	ctx                context.Context
+	node               *p2p.Node
+	privKey            p2pcrypto.PrivKey
+	stats              StatsSource
+	requestRateLimiter *rate.Limiter
+}
+
+// New creates and returns a new Service which signs the stats provided by
+// stats with privKey, which must be the private key of node.
+func New(ctx context.Context, node *p2p.Node, privKey p2pcrypto.PrivKey, stats StatsSource) *Service {
+	s := &Service{
+		ctx:                ctx,
+		node:               node,
+		privKey:            privKey,
+		stats:              stats,
+		requestRateLimiter: rate.NewLimiter(maxRequestsPerSecond, requestsBurst),
+	}
+	s.node.SetStreamHandler(ID, s.HandleStream)
+	return s
+}
+
+// HandleStream is a stream handler that is used to handle incoming stats
+// attestation requests.
+func (s *Service) HandleStream(stream network.Stream) {
+	requesterID := stream.Conn().RemotePeer()
+	if !s.requestRateLimiter.Allow() {
+		log.WithField("requester", requesterID.Pretty()).Debug("resetting stats attestation stream because rate limiter is backed up")
+		_ = stream.Reset()
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	_ = stream.SetReadDeadline(time.Now().Add(requestResponseTimeout))
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&req); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not decode stats attestation request")
+		return
+	}
+	res, err := s.Attest(req.Nonce)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Warn("could not handle stats attestation request")
+		return
+	}
+	_ = stream.SetWriteDeadline(time.Now().Add(requestResponseTimeout))
+	if err := json.NewEncoder(stream).Encode(res); err != nil {
+		log.WithFields(log.Fields{
+			"error":     err.Error(),
+			"requester": requesterID.Pretty(),
+		}).Debug("could not send stats attestation response")
+	}
+}
+
+// Attest returns a signed attestation of the current stats of the node which
+// includes the given nonce.
+func (s *Service) Attest(nonce string) (*SignedAttestation, error) {
+	if len(nonce) > maxNonceLength {
+		return nil, fmt.Errorf("nonce cannot be longer than %d characters", maxNonceLength)
+	}
+	attestation, err := s.stats.AttestationStats()
+	if err != nil {
+		return nil, err
+	}
+	attestation.PeerID = s.node.ID().Pretty()
+	attestation.Timestamp = time.Now().UTC()
+	attestation.Nonce = nonce
+	return Sign(s.privKey, attestation)
+}
+
+// StreamOpener opens streams to other peers. It is implemented by both
+// *p2p.Node and host.Host, so that attestations can be requested by programs
+// which don't run a full Mesh node (e.g. network crawlers).
+type StreamOpener interface {
+	NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error)
+}
+
+// RequestAttestation requests a signed attestation from the given peer and
+// verifies that it was signed by that peer and includes the given nonce.
+func RequestAttestation(ctx context.Context, opener StreamOpener, peerID peer.ID, nonce string) (*SignedAttestation, *Attestation, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestResponseTimeout)
+	defer cancel()
+	stream, err := opener.NewStream(ctx, peerID, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+	if err := json.NewEncoder(stream).Encode(Request{Nonce: nonce}); err != nil {
+		return nil, nil, err
+	}
+	var signedAttestation SignedAttestation
+	if err := json.NewDecoder(io.LimitReader(stream, maxMessageSize)).Decode(&signedAttestation); err != nil {
+		return nil, nil, err
+	}
+	attestation, err := signedAttestation.Verify()
+	if err != nil {
+		return nil, nil, err
+	}
+	if attestation.PeerID != peerID.Pretty() {
+		return nil, nil, fmt.Errorf("attestation is for peer %s instead of %s", attestation.PeerID, peerID.Pretty())
+	}
+	if attestation.Nonce != nonce {
+		return nil, nil, errors.New("attestation does not include the nonce from the request")
+	}
+	return &signedAttestation, attestation, nil
+}
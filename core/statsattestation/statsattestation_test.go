@@ -0,0 +1,51 @@
+package statsattestation
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerifyAttestation(t *testing.T) {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	require.NoError(t, err)
+	attestation := &Attestation{
+		PeerID:            peerID.Pretty(),
+		Version:           "9.4.2",
+		ChainID:           1337,
+		LatestBlockNumber: 42,
+		LatestBlockHash:   common.HexToHash("0x1"),
+		Topics: []*TopicStats{
+			{Topic: "/0x-orders/version/3/chain/1337/schema/e30=", NumOrders: 10},
+		},
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Nonce:     "nonce",
+	}
+	signedAttestation, err := Sign(privKey, attestation)
+	require.NoError(t, err)
+	actual, err := signedAttestation.Verify()
+	require.NoError(t, err)
+	assert.Equal(t, attestation, actual)
+
+	// An attestation which claims to be from a different peer should be
+	// rejected.
+	otherPrivKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	otherPeerID, err := peer.IDFromPrivateKey(otherPrivKey)
+	require.NoError(t, err)
+	forged := *attestation
+	forged.PeerID = otherPeerID.Pretty()
+	encodedForged, err := json.Marshal(forged)
+	require.NoError(t, err)
+	_, err = (&SignedAttestation{Attestation: encodedForged, Signature: signedAttestation.Signature}).Verify()
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/zeroex"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// isTakerRestricted returns true if the given order can only be filled by a
+// specific taker.
+func isTakerRestricted(order *zeroex.SignedOrder) bool {
+	return order.TakerAddress != constants.NullAddress
+}
+
+// dropTakerRestrictedMessages wraps the given GossipSub validator so that
+// messages which contain taker-restricted orders are rejected if
+// DropTakerRestrictedOrders is set. Rejected messages are neither handled nor
+// relayed to other peers.
+func (app *App) dropTakerRestrictedMessages(validator pubsub.Validator) pubsub.Validator {
+	if !app.config.DropTakerRestrictedOrders {
+		return validator
+	}
+	return func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+		if !validator(ctx, sender, msg) {
+			return false
+		}
+		order, err := encoding.RawMessageToOrder(msg.Data)
+		if err != nil {
+			return false
+		}
+		return !isTakerRestricted(order)
+	}
+}
+
+// dropTakerRestrictedOrders returns the given orders without the
+// taker-restricted ones if DropTakerRestrictedOrders is set.
+func (app *App) dropTakerRestrictedOrders(orders []*zeroex.SignedOrder) []*zeroex.SignedOrder {
+	if !app.config.DropTakerRestrictedOrders {
+		return orders
+	}
+	filteredOrders := make([]*zeroex.SignedOrder, 0, len(orders))
+	for _, order := range orders {
+		if !isTakerRestricted(order) {
+			filteredOrders = append(filteredOrders, order)
+		}
+	}
+	return filteredOrders
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/core/softcancel"
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/albrow/stringset"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	log "github.com/sirupsen/logrus"
+)
+
+// additionalOrderFilterConfig is a single entry in
+// Config.AdditionalOrderFilters. Exactly one of Filter, FilterFile and Topic
+// must be set.
+type additionalOrderFilterConfig struct {
+	// Filter is a JSON Schema in the same format as Config.CustomOrderFilter.
+	Filter json.RawMessage `json:"filter,omitempty"`
+	// FilterFile is the path to a file which contains a JSON Schema in the same
+	// format as Config.CustomOrderFilter.
+	FilterFile string `json:"filterFile,omitempty"`
+	// Topic is the pubsub topic for the filter (e.g. as reported by the
+	// pubSubTopic field of mesh_getStats on another node).
+	Topic string `json:"topic,omitempty"`
+	// MaxOrders is the maximum number of orders received on the topic that will
+	// be stored. Orders received on a topic with a quota don't count toward
+	// Config.MaxOrdersInStorage and are never evicted to make space for orders
+	// on other topics. If it is zero, orders received on the topic are only
+	// limited by Config.MaxOrdersInStorage.
+	MaxOrders int `json:"maxOrders,omitempty"`
+}
+
+// additionalOrderFilter is a custom order filter whose topic we subscribe to in
+// addition to the topic for Config.CustomOrderFilter.
+type additionalOrderFilter struct {
+	filter    *orderfilter.Filter
+	maxOrders int
+	// quotaDroppedMessages is the number of messages received on the topic which
+	// were dropped because the quota was reached. It must be accessed
+	// atomically.
+	quotaDroppedMessages uint64
+}
+
+// parseAdditionalOrderFilters parses the value of Config.AdditionalOrderFilters.
+// usedTopics are the topics that we already publish or subscribe to. They may
+// not be used by any of the additional filters.
+func parseAdditionalOrderFilters(rawFilters string, chainID int, contractAddresses ethereum.ContractAddresses, networkID string, usedTopics []string) ([]*additionalOrderFilter, error) {
+	if rawFilters == "" {
+		return nil, nil
+	}
+	var filterConfigs []additionalOrderFilterConfig
+	if err := json.Unmarshal([]byte(rawFilters), &filterConfigs); err != nil {
+		return nil, err
+	}
+	seenTopics := stringset.NewFromSlice(usedTopics)
+	filters := make([]*additionalOrderFilter, len(filterConfigs))
+	for i, filterConfig := range filterConfigs {
+		filter, err := filterConfig.newFilter(chainID, contractAddresses, networkID)
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %s", i, err.Error())
+		}
+		if filterConfig.MaxOrders < 0 {
+			return nil, fmt.Errorf("filter %d: maxOrders cannot be negative", i)
+		}
+		topic := filter.Topic()
+		if seenTopics.Contains(topic) {
+			return nil, fmt.Errorf("filter %d: topic is already used: %s", i, topic)
+		}
+		seenTopics.Add(topic)
+		filters[i] = &additionalOrderFilter{
+			filter:    filter,
+			maxOrders: filterConfig.MaxOrders,
+		}
+	}
+	return filters, nil
+}
+
+// topicQuotaLimits returns the topics of the given additional order filters
+// which have a quota mapped to the maximum number of orders received on them
+// that will be stored.
+func topicQuotaLimits(additionalFilters []*additionalOrderFilter) map[string]int {
+	quotas := map[string]int{}
+	for _, additionalFilter := range additionalFilters {
+		if additionalFilter.maxOrders > 0 {
+			quotas[additionalFilter.filter.Topic()] = additionalFilter.maxOrders
+		}
+	}
+	return quotas
+}
+
+func (c additionalOrderFilterConfig) newFilter(chainID int, contractAddresses ethereum.ContractAddresses, networkID string) (*orderfilter.Filter, error) {
+	numSources := 0
+	for _, isSet := range []bool{len(c.Filter) > 0, c.FilterFile != "", c.Topic != ""} {
+		if isSet {
+			numSources++
+		}
+	}
+	if numSources != 1 {
+		return nil, errors.New(`exactly one of "filter", "filterFile" and "topic" must be set`)
+	}
+
+	if c.Topic != "" {
+		filter, err := orderfilter.NewFromTopic(c.Topic, contractAddresses)
+		if err != nil {
+			return nil, err
+		}
+		if filter.NetworkID() != networkID {
+			return nil, fmt.Errorf("topic belongs to a different network: %s", c.Topic)
+		}
+		return filter, nil
+	}
+	schema := string(c.Filter)
+	if c.FilterFile != "" {
+		data, err := ioutil.ReadFile(c.FilterFile)
+		if err != nil {
+			return nil, err
+		}
+		schema = string(data)
+	}
+	filter, err := orderfilter.New(chainID, schema, contractAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return filter.WithNetworkID(networkID), nil
+}
+
+// additionalSubscribeTopics returns the topics for the additional order
+// filters and the soft cancel topic (if soft cancellations are enabled) mapped
+// to the validators for messages on those topics.
+func (app *App) additionalSubscribeTopics() map[string]pubsub.Validator {
+	topics := make(map[string]pubsub.Validator, len(app.additionalOrderFilters))
+	for _, additionalFilter := range app.additionalOrderFilters {
+		topics[additionalFilter.filter.Topic()] = app.dropSoftCancelledMessages(app.dropTakerRestrictedMessages(additionalFilter.filter.ValidatePubSubMessage))
+	}
+	if app.softCancels != nil {
+		topics[app.softCancelTopic()] = softcancel.ValidatePubSubMessage
+	}
+	return topics
+}
+
+// additionalOrderFilterForTopic returns the additional order filter for the
+// given topic or nil if there is none.
+func (app *App) additionalOrderFilterForTopic(topic string) *additionalOrderFilter {
+	for _, additionalFilter := range app.additionalOrderFilters {
+		if additionalFilter.filter.Topic() == topic {
+			return additionalFilter
+		}
+	}
+	return nil
+}
+
+// shareOrderOnAdditionalTopics shares the given order on the topic of each
+// additional order filter that it matches.
+func (app *App) shareOrderOnAdditionalTopics(order *zeroex.SignedOrder) error {
+	for _, additionalFilter := range app.additionalOrderFilters {
+		matches, err := additionalFilter.filter.MatchOrder(order)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			continue
+		}
+		topic := additionalFilter.filter.Topic()
+		encoded, err := encoding.OrderToRawMessage(topic, order)
+		if err != nil {
+			return err
+		}
+		if err := app.node.SendToTopic(topic, encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topicQuotas keeps track of the number of orders that may still be stored
+// for each topic with a quota while a batch of messages is handled.
+type topicQuotas struct {
+	app       *App
+	remaining map[string]int
+}
+
+func (app *App) newTopicQuotas() *topicQuotas {
+	return &topicQuotas{
+		app:       app,
+		remaining: map[string]int{},
+	}
+}
+
+// allow returns true if a message received on the given topic should be
+// handled. Each allowed message uses up one unit of the topic's quota.
+func (q *topicQuotas) allow(topic string) bool {
+	additionalFilter := q.app.additionalOrderFilterForTopic(topic)
+	if additionalFilter == nil || additionalFilter.maxOrders == 0 {
+		return true
+	}
+	remaining, found := q.remaining[topic]
+	if !found {
+		numOrders, err := q.app.db.CountOrdersByTopic(topic)
+		if err != nil {
+			// If we can't check the quota, err on the side of handling the
+			// message. MaxOrdersInStorage still applies.
+			log.WithFields(map[string]interface{}{
+				"error": err.Error(),
+				"topic": topic,
+			}).Error("could not count orders for topic")
+			return true
+		}
+		remaining = additionalFilter.maxOrders - numOrders
+	}
+	if remaining <= 0 {
+		q.remaining[topic] = 0
+		atomic.AddUint64(&additionalFilter.quotaDroppedMessages, 1)
+		return false
+	}
+	q.remaining[topic] = remaining - 1
+	return true
+}
+
+// getTopicStats returns stats for the primary topic followed by the topics for
+// each of the additional order filters.
+func (app *App) getTopicStats() ([]types.TopicStats, error) {
+	primaryTopic := app.orderFilter.Topic()
+	numOrders, err := app.db.CountOrdersByTopic(primaryTopic)
+	if err != nil {
+		return nil, err
+	}
+	topicStats := []types.TopicStats{
+		{
+			Topic:     primaryTopic,
+			NumOrders: numOrders,
+		},
+	}
+	for _, additionalFilter := range app.additionalOrderFilters {
+		topic := additionalFilter.filter.Topic()
+		numOrders, err := app.db.CountOrdersByTopic(topic)
+		if err != nil {
+			return nil, err
+		}
+		topicStats = append(topicStats, types.TopicStats{
+			Topic:                topic,
+			NumOrders:            numOrders,
+			MaxOrders:            additionalFilter.maxOrders,
+			QuotaDroppedMessages: atomic.LoadUint64(&additionalFilter.quotaDroppedMessages),
+		})
+	}
+	return topicStats, nil
+}
@@ -0,0 +1,107 @@
+// +build !js
+
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAdditionalOrderFilters(t *testing.T) {
+	t.Parallel()
+
+	makerAddressFilter := `{"properties":{"makerAddress":{"const":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149"}}}`
+	expectedFilter, err := orderfilter.New(constants.TestChainID, makerAddressFilter, contractAddresses)
+	require.NoError(t, err)
+	expectedTopic := expectedFilter.Topic()
+	defaultTopic, err := getDefaultTopic(constants.TestChainID, contractAddresses, "")
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "additional_order_filters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filterFile := filepath.Join(dir, "filter.json")
+	require.NoError(t, ioutil.WriteFile(filterFile, []byte(makerAddressFilter), 0644))
+
+	filters, err := parseAdditionalOrderFilters("", constants.TestChainID, contractAddresses, "", []string{defaultTopic})
+	require.NoError(t, err)
+	assert.Len(t, filters, 0)
+
+	validTestCases := []struct {
+		name              string
+		rawFilters        string
+		expectedMaxOrders int
+	}{
+		{
+			name:              "inline filter",
+			rawFilters:        fmt.Sprintf(`[{"filter":%s,"maxOrders":10}]`, makerAddressFilter),
+			expectedMaxOrders: 10,
+		},
+		{
+			name:       "filter file",
+			rawFilters: fmt.Sprintf(`[{"filterFile":%q}]`, filterFile),
+		},
+		{
+			name:       "topic",
+			rawFilters: fmt.Sprintf(`[{"topic":%q}]`, expectedTopic),
+		},
+	}
+	for _, tc := range validTestCases {
+		filters, err := parseAdditionalOrderFilters(tc.rawFilters, constants.TestChainID, contractAddresses, "", []string{defaultTopic})
+		require.NoError(t, err, tc.name)
+		require.Len(t, filters, 1, tc.name)
+		assert.Equal(t, expectedTopic, filters[0].filter.Topic(), tc.name)
+		assert.Equal(t, tc.expectedMaxOrders, filters[0].maxOrders, tc.name)
+		if tc.expectedMaxOrders > 0 {
+			assert.Equal(t, map[string]int{expectedTopic: tc.expectedMaxOrders}, topicQuotaLimits(filters), tc.name)
+		} else {
+			assert.Empty(t, topicQuotaLimits(filters), tc.name)
+		}
+	}
+
+	invalidTestCases := []struct {
+		name       string
+		rawFilters string
+	}{
+		{
+			name:       "not an array",
+			rawFilters: makerAddressFilter,
+		},
+		{
+			name:       "no filter",
+			rawFilters: `[{"maxOrders":10}]`,
+		},
+		{
+			name:       "more than one filter",
+			rawFilters: fmt.Sprintf(`[{"filter":%s,"topic":%q}]`, makerAddressFilter, expectedTopic),
+		},
+		{
+			name:       "negative maxOrders",
+			rawFilters: fmt.Sprintf(`[{"filter":%s,"maxOrders":-1}]`, makerAddressFilter),
+		},
+		{
+			name:       "duplicate topic",
+			rawFilters: fmt.Sprintf(`[{"filter":%s},{"topic":%q}]`, makerAddressFilter, expectedTopic),
+		},
+		{
+			name:       "topic which is already used",
+			rawFilters: `[{"filter":{}}]`,
+		},
+		{
+			name:       "topic for a different network",
+			rawFilters: fmt.Sprintf(`[{"topic":%q}]`, expectedFilter.WithNetworkID("private").Topic()),
+		},
+	}
+	for _, tc := range invalidTestCases {
+		_, err := parseAdditionalOrderFilters(tc.rawFilters, constants.TestChainID, contractAddresses, "", []string{defaultTopic})
+		assert.Error(t, err, tc.name)
+	}
+}
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// orderOverheadBytes is the approximate size of a decoded order excluding its
+// variable-length fields (i.e. the struct itself and the headers of its
+// slices and big.Ints).
+const orderOverheadBytes = 512
+
+// ValidationMemoryPolicy determines what happens to decoded orders when the
+// memory reserved for orders awaiting validation would exceed the configured
+// maximum.
+type ValidationMemoryPolicy string
+
+const (
+	// ValidationMemoryWait waits until enough memory is released by other
+	// batches of orders before validating. This applies backpressure to
+	// ordersync and to the inbound message queue instead of dropping orders.
+	ValidationMemoryWait ValidationMemoryPolicy = "wait"
+	// ValidationMemoryShed drops the orders that don't fit. Orders dropped
+	// during ordersync may be received again the next time ordersync runs.
+	ValidationMemoryShed ValidationMemoryPolicy = "shed"
+)
+
+// ParseValidationMemoryPolicy parses the given string as a
+// ValidationMemoryPolicy. An empty string is parsed as ValidationMemoryWait.
+func ParseValidationMemoryPolicy(s string) (ValidationMemoryPolicy, error) {
+	switch ValidationMemoryPolicy(s) {
+	case "", ValidationMemoryWait:
+		return ValidationMemoryWait, nil
+	case ValidationMemoryShed:
+		return ValidationMemoryShed, nil
+	default:
+		return "", fmt.Errorf("invalid validation memory policy %q (expected %q or %q)", s, ValidationMemoryWait, ValidationMemoryShed)
+	}
+}
+
+// validationMemoryStats contains metrics about the memory used by orders
+// awaiting validation.
+type validationMemoryStats struct {
+	// Bytes is the approximate number of bytes currently reserved.
+	Bytes int
+	// ShedOrders is the total number of orders that were dropped because
+	// there was not enough memory.
+	ShedOrders uint64
+}
+
+// validationMemory accounts for the approximate memory used by decoded orders
+// which are waiting to be validated (from both GossipSub and ordersync) and
+// caps it at a maximum number of bytes. This prevents memory usage from
+// growing without limit when many orders are received at once, e.g. during
+// the initial ordersync on a node with little memory.
+type validationMemory struct {
+	mu       sync.Mutex
+	maxBytes int
+	policy   ValidationMemoryPolicy
+	bytes    int
+	shed     uint64
+	// released is closed and replaced whenever memory is released, which
+	// wakes up every waiting reservation.
+	released chan struct{}
+}
+
+// newValidationMemory returns a new validationMemory with the given maximum
+// number of bytes. If maxBytes is 0, memory is accounted for but there is no
+// maximum.
+func newValidationMemory(maxBytes int, policy ValidationMemoryPolicy) *validationMemory {
+	return &validationMemory{
+		maxBytes: maxBytes,
+		policy:   policy,
+		released: make(chan struct{}),
+	}
+}
+
+// reserve reserves memory for the given orders and returns the orders which
+// may be validated along with the number of bytes reserved for them, which
+// must be passed to release once validation is done. If there is not enough
+// memory, reserve either waits until there is (returning an error if ctx is
+// canceled first) or drops the orders that don't fit, depending on the
+// policy. A batch which is larger than the maximum on its own is allowed
+// when no other memory is reserved so that it can't wait forever.
+func (m *validationMemory) reserve(ctx context.Context, orders []*zeroex.SignedOrder) ([]*zeroex.SignedOrder, int, error) {
+	sizes := make([]int, len(orders))
+	total := 0
+	for i, order := range orders {
+		sizes[i] = estimateOrderSize(order)
+		total += sizes[i]
+	}
+
+	m.mu.Lock()
+	if m.policy == ValidationMemoryShed {
+		defer m.mu.Unlock()
+		kept := make([]*zeroex.SignedOrder, 0, len(orders))
+		reserved := 0
+		for i, order := range orders {
+			if !m.fits(sizes[i]) {
+				m.shed++
+				continue
+			}
+			m.bytes += sizes[i]
+			reserved += sizes[i]
+			kept = append(kept, order)
+		}
+		return kept, reserved, nil
+	}
+	for !m.fits(total) {
+		released := m.released
+		m.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-released:
+		}
+		m.mu.Lock()
+	}
+	m.bytes += total
+	m.mu.Unlock()
+	return orders, total, nil
+}
+
+// fits returns true if size bytes can be reserved. m.mu must be held.
+func (m *validationMemory) fits(size int) bool {
+	return m.maxBytes == 0 || m.bytes == 0 || m.bytes+size <= m.maxBytes
+}
+
+// release releases memory that was reserved by reserve.
+func (m *validationMemory) release(size int) {
+	if size == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes -= size
+	close(m.released)
+	m.released = make(chan struct{})
+}
+
+func (m *validationMemory) stats() validationMemoryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return validationMemoryStats{
+		Bytes:      m.bytes,
+		ShedOrders: m.shed,
+	}
+}
+
+// estimateOrderSize returns the approximate number of bytes of memory used by
+// the given decoded order.
+func estimateOrderSize(order *zeroex.SignedOrder) int {
+	size := orderOverheadBytes
+	for _, data := range [][]byte{
+		order.MakerAssetData,
+		order.MakerFeeAssetData,
+		order.TakerAssetData,
+		order.TakerFeeAssetData,
+		order.Signature,
+	} {
+		size += cap(data)
+	}
+	for _, i := range []*big.Int{
+		order.ChainID,
+		order.MakerAssetAmount,
+		order.MakerFee,
+		order.TakerAssetAmount,
+		order.TakerFee,
+		order.ExpirationTimeSeconds,
+		order.Salt,
+	} {
+		if i != nil {
+			size += len(i.Bits()) * bits.UintSize / 8
+		}
+	}
+	return size
+}
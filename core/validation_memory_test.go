@@ -0,0 +1,118 @@
+// +build !js
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValidationMemoryPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseValidationMemoryPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, ValidationMemoryWait, policy)
+	policy, err = ParseValidationMemoryPolicy("shed")
+	require.NoError(t, err)
+	assert.Equal(t, ValidationMemoryShed, policy)
+	_, err = ParseValidationMemoryPolicy("spill")
+	assert.Error(t, err)
+}
+
+func TestValidationMemoryShed(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithSignatureSize(3, 100)
+	orderSize := estimateOrderSize(orders[0])
+	memory := newValidationMemory(2*orderSize, ValidationMemoryShed)
+
+	kept, reserved, err := memory.reserve(context.Background(), orders)
+	require.NoError(t, err)
+	assert.Equal(t, orders[:2], kept)
+	assert.Equal(t, 2*orderSize, reserved)
+	assert.Equal(t, validationMemoryStats{Bytes: 2 * orderSize, ShedOrders: 1}, memory.stats())
+
+	memory.release(reserved)
+	assert.Equal(t, validationMemoryStats{Bytes: 0, ShedOrders: 1}, memory.stats())
+}
+
+func TestValidationMemoryWait(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithSignatureSize(2, 100)
+	orderSize := estimateOrderSize(orders[0])
+	memory := newValidationMemory(orderSize, ValidationMemoryWait)
+
+	_, firstReserved, err := memory.reserve(context.Background(), orders[:1])
+	require.NoError(t, err)
+
+	// The second reservation should wait until the first is released.
+	done := make(chan int)
+	go func() {
+		_, reserved, err := memory.reserve(context.Background(), orders[1:])
+		require.NoError(t, err)
+		done <- reserved
+	}()
+	select {
+	case <-done:
+		t.Fatal("reserve returned before memory was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+	memory.release(firstReserved)
+	select {
+	case reserved := <-done:
+		assert.Equal(t, orderSize, reserved)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reserve to return")
+	}
+	assert.Equal(t, validationMemoryStats{Bytes: orderSize}, memory.stats())
+}
+
+func TestValidationMemoryWaitContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithSignatureSize(2, 100)
+	memory := newValidationMemory(estimateOrderSize(orders[0]), ValidationMemoryWait)
+	_, _, err := memory.reserve(context.Background(), orders[:1])
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = memory.reserve(ctx, orders[1:])
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestValidationMemoryAllowsLargeBatchWhenEmpty(t *testing.T) {
+	t.Parallel()
+
+	orders := newOrdersWithSignatureSize(3, 100)
+	for _, policy := range []ValidationMemoryPolicy{ValidationMemoryWait, ValidationMemoryShed} {
+		memory := newValidationMemory(1, policy)
+		kept, _, err := memory.reserve(context.Background(), orders[:1])
+		require.NoError(t, err)
+		assert.Len(t, kept, 1, "policy: %s", policy)
+	}
+
+	// With no maximum, everything is reserved.
+	memory := newValidationMemory(0, ValidationMemoryShed)
+	kept, reserved, err := memory.reserve(context.Background(), orders)
+	require.NoError(t, err)
+	assert.Equal(t, orders, kept)
+	assert.Equal(t, 3*estimateOrderSize(orders[0]), reserved)
+}
+
+func newOrdersWithSignatureSize(count int, signatureSize int) []*zeroex.SignedOrder {
+	orders := make([]*zeroex.SignedOrder, count)
+	for i := range orders {
+		orders[i] = &zeroex.SignedOrder{
+			Signature: make([]byte, signatureSize),
+		}
+	}
+	return orders
+}
@@ -0,0 +1,16 @@
+// +build !js
+
+package core
+
+import "context"
+
+// recoverFromBlockGaps is false outside of the browser. Mesh exits when the
+// block watcher falls too many blocks behind and re-validates all orders when
+// it is restarted.
+const recoverFromBlockGaps = false
+
+// watchVisibility returns nil since there is no page whose visibility could
+// change outside of the browser.
+func watchVisibility(ctx context.Context) <-chan bool {
+	return nil
+}
@@ -0,0 +1,48 @@
+// +build js,wasm
+
+package core
+
+import (
+	"context"
+	"syscall/js"
+)
+
+// recoverFromBlockGaps is true in the browser, where tabs are routinely frozen
+// in the background for long periods of time. Instead of exiting when the
+// block watcher falls too many blocks behind, Mesh starts again from the latest
+// block and re-validates all orders.
+const recoverFromBlockGaps = true
+
+// watchVisibility returns a channel which receives true whenever the page
+// becomes visible and false whenever it is hidden. It returns nil if Mesh is
+// not running in a page (e.g. in a web worker).
+func watchVisibility(ctx context.Context) <-chan bool {
+	document := js.Global().Get("document")
+	if document == js.Undefined() || document == js.Null() {
+		return nil
+	}
+	visibilityChanges := make(chan bool, 1)
+	onVisibilityChange := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		visible := document.Get("visibilityState").String() != "hidden"
+		// Drop a previous change which hasn't been received yet, since only the
+		// latest visibility state matters.
+		select {
+		case <-visibilityChanges:
+		default:
+		}
+		select {
+		case visibilityChanges <- visible:
+		default:
+		}
+		return nil
+	})
+	// Send the initial visibility state, since the page may already be hidden.
+	visibilityChanges <- document.Get("visibilityState").String() != "hidden"
+	document.Call("addEventListener", "visibilitychange", onVisibilityChange)
+	go func() {
+		<-ctx.Done()
+		document.Call("removeEventListener", "visibilitychange", onVisibilityChange)
+		onVisibilityChange.Release()
+	}()
+	return visibilityChanges
+}
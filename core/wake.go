@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/ordersync"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// heartbeatInterval is how often the wake watcher checks whether Mesh was
+	// suspended.
+	heartbeatInterval = 5 * time.Second
+	// minSleepDuration is the minimum amount of time that Mesh must have been
+	// suspended (or hidden, in the browser) for a resync to be performed when
+	// it wakes up.
+	minSleepDuration = 1 * time.Minute
+	// wakeOrderSyncTimeout is the maximum amount of time to spend performing
+	// ordersync after waking up.
+	wakeOrderSyncTimeout = 1 * time.Minute
+)
+
+// watchForWake detects when Mesh wakes up after being suspended (e.g. when the
+// computer went to sleep or a browser tab was frozen in the background) and
+// resyncs with the Ethereum blockchain and the network, so that Mesh becomes
+// consistent again quickly instead of serving stale orders until the next
+// periodic sync. Mesh is considered to have been suspended if the time between
+// two heartbeats is much longer than heartbeatInterval. In the browser, it
+// also pauses noncritical work while the page is hidden and resyncs when it
+// becomes visible again.
+func (app *App) watchForWake(ctx context.Context) {
+	<-app.started
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	visibilityChanges := watchVisibility(ctx)
+	// Note: The monotonic clock reading is stripped from the heartbeat times
+	// since the monotonic clock doesn't advance while the computer is asleep on
+	// some platforms.
+	lastHeartbeat := time.Now().Round(0)
+	var hiddenAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			now = now.Round(0)
+			sleepDuration := now.Sub(lastHeartbeat) - heartbeatInterval
+			lastHeartbeat = now
+			if sleepDuration >= minSleepDuration && !app.isHidden() {
+				log.WithField("sleepDuration", sleepDuration.String()).Info("detected that Mesh was suspended; resyncing")
+				app.resyncAfterWake(ctx)
+			}
+		case visible := <-visibilityChanges:
+			if !visible {
+				if hiddenAt.IsZero() {
+					log.Debug("page was hidden; pausing noncritical work")
+					atomic.StoreInt32(&app.hidden, 1)
+					hiddenAt = time.Now().Round(0)
+				}
+				continue
+			}
+			if hiddenAt.IsZero() {
+				// The page was already visible (e.g. this is the initial
+				// visibility state).
+				continue
+			}
+			atomic.StoreInt32(&app.hidden, 0)
+			hiddenDuration := time.Now().Round(0).Sub(hiddenAt)
+			hiddenAt = time.Time{}
+			log.WithField("hiddenDuration", hiddenDuration.String()).Debug("page became visible; resuming noncritical work")
+			if hiddenDuration >= minSleepDuration {
+				app.resyncAfterWake(ctx)
+			}
+			// The heartbeat gap caused by the page being hidden was handled
+			// above.
+			lastHeartbeat = time.Now().Round(0)
+		}
+	}
+}
+
+// isHidden returns true if Mesh is running in a browser page which is
+// currently hidden. Noncritical work (e.g. periodically logging stats) is
+// skipped while the page is hidden.
+func (app *App) isHidden() bool {
+	return atomic.LoadInt32(&app.hidden) == 1
+}
+
+// resyncAfterWake catches up with the blocks that were mined while Mesh was
+// suspended and then receives any orders that were missed via ordersync.
+func (app *App) resyncAfterWake(ctx context.Context) {
+	start := time.Now()
+	blocksElapsed, err := app.blockWatcher.Resync(ctx)
+	if err != nil {
+		log.WithError(err).Error("could not resync blocks after waking up")
+	} else if blocksElapsed >= app.blockWatcher.MaxBlockHistory() && !recoverFromBlockGaps {
+		// The block watcher dropped its block headers and started again from
+		// the latest block, so the events of the missed blocks were never
+		// emitted. Re-validate all orders so that orders which were filled,
+		// cancelled or expired in the meantime are removed. When
+		// recoverFromBlockGaps is true, OnTooManyBlocksBehind already did this.
+		log.WithField("blocksElapsed", blocksElapsed).Info("Too many blocks have elapsed while Mesh was suspended. Re-validating all orders stored (this can take a while)...")
+		if err := app.orderWatcher.Cleanup(ctx, 0*time.Minute); err != nil {
+			log.WithError(err).Error("could not re-validate orders after waking up")
+		}
+	}
+
+	orderSyncCtx, cancel := context.WithTimeout(ctx, wakeOrderSyncTimeout)
+	defer cancel()
+	if err := app.ordersyncService.GetOrders(orderSyncCtx, ordersyncMinPeers); err != nil && err != context.DeadlineExceeded && err != ordersync.ErrRequestsPaused {
+		log.WithError(err).Warn("could not complete ordersync after waking up")
+	}
+	log.WithFields(log.Fields{
+		"blocksElapsed": blocksElapsed,
+		"duration":      time.Since(start).String(),
+	}).Info("finished resyncing after waking up")
+}
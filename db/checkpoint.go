@@ -0,0 +1,144 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// checkpointHeader is written at the start of every checkpoint so that other
+// files are not mistaken for checkpoints.
+var checkpointHeader = []byte("0x-mesh db checkpoint v1\n")
+
+// maxCheckpointEntrySize is the maximum size of a key or value in a
+// checkpoint. It prevents corrupted checkpoints from causing huge
+// allocations.
+const maxCheckpointEntrySize = 64 * 1024 * 1024
+
+// restoreBatchSize is the approximate number of bytes written to the database
+// at a time when restoring a checkpoint.
+const restoreBatchSize = 4 * 1024 * 1024
+
+// ErrDBNotEmpty is returned by RestoreCheckpoint if the database already
+// contains data.
+var ErrDBNotEmpty = errors.New("cannot restore checkpoint into a database which is not empty")
+
+// ErrInvalidCheckpoint is returned by RestoreCheckpoint if the checkpoint is
+// malformed or truncated.
+var ErrInvalidCheckpoint = errors.New("invalid or truncated checkpoint")
+
+// WriteCheckpoint writes a consistent copy of the entire database to w. It
+// uses a snapshot of the database, so it is safe to call while the database
+// is being read from and written to. The checkpoint can be loaded into an
+// empty database with RestoreCheckpoint.
+//
+// A checkpoint consists of checkpointHeader followed by each key and value
+// prefixed by its length as a uvarint, and ends with a zero length key.
+func (db *DB) WriteCheckpoint(w io.Writer) error {
+	snapshot, err := db.ldb.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Release()
+	iter := snapshot.NewIterator(nil, nil)
+	defer iter.Release()
+
+	bufWriter := bufio.NewWriter(w)
+	if _, err := bufWriter.Write(checkpointHeader); err != nil {
+		return err
+	}
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	writeEntry := func(data []byte) error {
+		n := binary.PutUvarint(lenBuf, uint64(len(data)))
+		if _, err := bufWriter.Write(lenBuf[:n]); err != nil {
+			return err
+		}
+		_, err := bufWriter.Write(data)
+		return err
+	}
+	for iter.Next() {
+		if err := writeEntry(iter.Key()); err != nil {
+			return err
+		}
+		if err := writeEntry(iter.Value()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := writeEntry(nil); err != nil {
+		return err
+	}
+	return bufWriter.Flush()
+}
+
+// RestoreCheckpoint loads a checkpoint which was written by WriteCheckpoint
+// into the database. The database must be empty and should not be used by any
+// collections until RestoreCheckpoint returns. If the checkpoint is invalid or
+// truncated, ErrInvalidCheckpoint is returned and the database may contain
+// part of the checkpoint.
+func (db *DB) RestoreCheckpoint(r io.Reader) error {
+	iter := db.ldb.NewIterator(nil, nil)
+	notEmpty := iter.First()
+	iter.Release()
+	if notEmpty {
+		return ErrDBNotEmpty
+	}
+
+	bufReader := bufio.NewReader(r)
+	header := make([]byte, len(checkpointHeader))
+	if _, err := io.ReadFull(bufReader, header); err != nil || !bytes.Equal(header, checkpointHeader) {
+		return ErrInvalidCheckpoint
+	}
+	readEntry := func() ([]byte, error) {
+		length, err := binary.ReadUvarint(bufReader)
+		if err != nil {
+			return nil, ErrInvalidCheckpoint
+		}
+		if length > maxCheckpointEntrySize {
+			return nil, ErrInvalidCheckpoint
+		}
+		data := make([]byte, length)
+		if _, err := io.ReadFull(bufReader, data); err != nil {
+			return nil, ErrInvalidCheckpoint
+		}
+		return data, nil
+	}
+
+	batch := new(leveldb.Batch)
+	batchSize := 0
+	for {
+		key, err := readEntry()
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			break
+		}
+		value, err := readEntry()
+		if err != nil {
+			return err
+		}
+		batch.Put(key, value)
+		batchSize += len(key) + len(value)
+		if batchSize >= restoreBatchSize {
+			if err := db.ldb.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+			batchSize = 0
+		}
+	}
+	if err := db.ldb.Write(batch, nil); err != nil {
+		return err
+	}
+	if _, err := bufReader.ReadByte(); err != io.EOF {
+		return ErrInvalidCheckpoint
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	expected := []*testModel{}
+	for i := 0; i < 10; i++ {
+		model := &testModel{
+			Name: fmt.Sprintf("person_%d", i),
+			Age:  i,
+		}
+		require.NoError(t, col.Insert(model))
+		expected = append(expected, model)
+	}
+
+	checkpoint := &bytes.Buffer{}
+	require.NoError(t, db.WriteCheckpoint(checkpoint))
+
+	restoredDB := newTestDB(t)
+	defer restoredDB.Close()
+	require.NoError(t, restoredDB.RestoreCheckpoint(bytes.NewReader(checkpoint.Bytes())))
+	restoredCol, err := restoredDB.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	actual := []*testModel{}
+	require.NoError(t, restoredCol.FindAll(&actual))
+	assert.Equal(t, expected, actual)
+	count, err := restoredCol.Count()
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), count)
+
+	// Restoring into a database which is not empty should fail.
+	err = restoredDB.RestoreCheckpoint(bytes.NewReader(checkpoint.Bytes()))
+	assert.Equal(t, ErrDBNotEmpty, err)
+}
+
+func TestRestoreTruncatedCheckpoint(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	require.NoError(t, col.Insert(&testModel{Name: "foo", Age: 42}))
+	checkpoint := &bytes.Buffer{}
+	require.NoError(t, db.WriteCheckpoint(checkpoint))
+
+	truncated := checkpoint.Bytes()[:checkpoint.Len()-1]
+	restoredDB := newTestDB(t)
+	defer restoredDB.Close()
+	err = restoredDB.RestoreCheckpoint(bytes.NewReader(truncated))
+	assert.Equal(t, ErrInvalidCheckpoint, err)
+}
@@ -18,6 +18,9 @@ type colInfo struct {
 	// until the transaction is committed or discarded. Needs to be a pointer so
 	// that copies of this colInfo retain the same writeLock.
 	writeMut *sync.Mutex
+	// compressor is used to compress model data. It is nil if compression is
+	// not enabled for the collection.
+	compressor *compressor
 }
 
 // copy returns a copy of the colInfo. Any changes made to the original (e.g.
@@ -27,13 +30,15 @@ func (info *colInfo) copy() *colInfo {
 	info.indexMut.RLock()
 	indexes := make([]*Index, len(info.indexes))
 	copy(indexes, info.indexes)
+	compressor := info.compressor
 	info.indexMut.RUnlock()
 	return &colInfo{
-		db:        info.db,
-		name:      info.name,
-		modelType: info.modelType,
-		indexes:   indexes,
-		writeMut:  info.writeMut,
+		db:         info.db,
+		name:       info.name,
+		modelType:  info.modelType,
+		indexes:    indexes,
+		writeMut:   info.writeMut,
+		compressor: compressor,
 	}
 }
 
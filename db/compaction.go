@@ -0,0 +1,65 @@
+package db
+
+import (
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// CompactionResult describes the effect of a call to Compact.
+type CompactionResult struct {
+	// SizeBefore and SizeAfter are the approximate sizes of the database in
+	// bytes (see ApproximateSize) before and after the compaction.
+	SizeBefore int64
+	SizeAfter  int64
+	// Duration is how long the compaction took.
+	Duration time.Duration
+}
+
+// ReclaimedBytes returns the number of bytes that were freed by the
+// compaction. It is 0 if the database grew during the compaction, e.g.
+// because recent writes were flushed to disk.
+func (r *CompactionResult) ReclaimedBytes() int64 {
+	if r.SizeAfter >= r.SizeBefore {
+		return 0
+	}
+	return r.SizeBefore - r.SizeAfter
+}
+
+// FragmentationPercent returns the percentage of the size of the database
+// before the compaction which was used by deleted or overwritten entries, i.e.
+// the reclaimed bytes as a percentage of SizeBefore.
+func (r *CompactionResult) FragmentationPercent() float64 {
+	if r.SizeBefore <= 0 {
+		return 0
+	}
+	return 100 * float64(r.ReclaimedBytes()) / float64(r.SizeBefore)
+}
+
+// Compact compacts the entire database. LevelDB only removes deleted and
+// overwritten entries from disk when the tables which contain them are
+// compacted, which happens gradually as new data is written. Compacting
+// explicitly reclaims the disk space used by those entries right away (e.g.
+// after many orders were removed). It is safe to call while the database is
+// being read from and written to, but it can take a while and uses a lot of
+// disk I/O for large databases.
+func (db *DB) Compact() (*CompactionResult, error) {
+	start := time.Now()
+	sizeBefore, err := db.ApproximateSize()
+	if err != nil {
+		return nil, err
+	}
+	// An empty range covers the entire database.
+	if err := db.ldb.CompactRange(util.Range{}); err != nil {
+		return nil, err
+	}
+	sizeAfter, err := db.ApproximateSize()
+	if err != nil {
+		return nil, err
+	}
+	return &CompactionResult{
+		SizeBefore: sizeBefore,
+		SizeAfter:  sizeAfter,
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	// Random nicknames make sure that the data can't be compressed much.
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		nickname := make([]byte, 1000)
+		_, _ = random.Read(nickname)
+		model := &testModel{
+			Name:      fmt.Sprintf("person_%d", i),
+			Age:       i,
+			Nicknames: []string{hex.EncodeToString(nickname)},
+		}
+		require.NoError(t, col.Insert(model))
+	}
+	// The first compaction makes sure that all of the models are written to
+	// disk.
+	_, err = db.Compact()
+	require.NoError(t, err)
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, col.Delete([]byte(fmt.Sprintf("person_%d", i))))
+	}
+	result, err := db.Compact()
+	require.NoError(t, err)
+	assert.True(t, result.SizeBefore > 0)
+	assert.True(t, result.SizeAfter < result.SizeBefore, "compaction should reclaim the space used by deleted models")
+	assert.Equal(t, result.SizeBefore-result.SizeAfter, result.ReclaimedBytes())
+	assert.True(t, result.FragmentationPercent() > 50)
+	assert.True(t, result.FragmentationPercent() <= 100)
+	count, err := col.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestCompactionResult(t *testing.T) {
+	t.Parallel()
+	result := &CompactionResult{SizeBefore: 200, SizeAfter: 50}
+	assert.Equal(t, int64(150), result.ReclaimedBytes())
+	assert.Equal(t, 75.0, result.FragmentationPercent())
+
+	// The database can grow if recent writes are flushed to disk.
+	result = &CompactionResult{SizeBefore: 100, SizeAfter: 120}
+	assert.Equal(t, int64(0), result.ReclaimedBytes())
+	assert.Equal(t, 0.0, result.FragmentationPercent())
+
+	result = &CompactionResult{}
+	assert.Equal(t, 0.0, result.FragmentationPercent())
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// compressedMarker is the first byte of model data that has been compressed.
+// Models are encoded as JSON, which never starts with a zero byte, so data
+// that was stored before compression was enabled can still be read.
+const compressedMarker byte = 0x00
+
+// errNoCompressionDictionary is returned when we encounter compressed model
+// data for a collection that doesn't have a compression dictionary.
+var errNoCompressionDictionary = errors.New("model data is compressed but the collection has no compression dictionary")
+
+// compressor compresses model data with DEFLATE using a preset dictionary.
+// Models in the same collection share most of their structure (field names,
+// common prefixes, etc.), so a dictionary which contains that structure allows
+// even small models to be compressed well. It is safe for concurrent use.
+type compressor struct {
+	dict    []byte
+	writers sync.Pool
+	readers sync.Pool
+}
+
+func newCompressor(dict []byte) *compressor {
+	c := &compressor{
+		dict: dict,
+	}
+	c.writers.New = func() interface{} {
+		// flate.NewWriterDict only returns an error for an invalid level.
+		w, _ := flate.NewWriterDict(nil, flate.BestCompression, c.dict)
+		return w
+	}
+	c.readers.New = func() interface{} {
+		return flate.NewReaderDict(nil, c.dict)
+	}
+	return c
+}
+
+// compress returns the compressed data prefixed with compressedMarker. If
+// compressing doesn't make the data any smaller, data is returned unchanged.
+func (c *compressor) compress(data []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)/2+1))
+	buf.WriteByte(compressedMarker)
+	w := c.writers.Get().(*flate.Writer)
+	defer c.writers.Put(w)
+	w.Reset(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(data) {
+		return data, nil
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress. Data which doesn't start with compressedMarker
+// is returned unchanged.
+func (c *compressor) decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != compressedMarker {
+		return data, nil
+	}
+	r := c.readers.Get().(io.ReadCloser)
+	defer c.readers.Put(r)
+	if err := r.(flate.Resetter).Reset(bytes.NewReader(data[1:]), c.dict); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// SetCompressionDictionary enables compression of the model data stored in the
+// collection. dict should contain byte sequences that are likely to appear in
+// the JSON encoding of the model, with the most common ones at the end. Like
+// AddIndex, it should be called right after the collection is created. Models
+// which were stored without compression can still be read, but the dictionary
+// must never change once models have been stored with it.
+func (c *Collection) SetCompressionDictionary(dict []byte) {
+	c.info.indexMut.Lock()
+	defer c.info.indexMut.Unlock()
+	c.info.compressor = newCompressor(dict)
+}
+
+// encodeModel encodes the model so that it can be stored in the database.
+func (info *colInfo) encodeModel(model Model) ([]byte, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	if info.compressor == nil {
+		return data, nil
+	}
+	return info.compressor.compress(data)
+}
+
+// decodeModel decodes data returned by encodeModel into model.
+func (info *colInfo) decodeModel(data []byte, model interface{}) error {
+	if len(data) > 0 && data[0] == compressedMarker {
+		if info.compressor == nil {
+			return errNoCompressionDictionary
+		}
+		decompressed, err := info.compressor.decompress(data)
+		if err != nil {
+			return err
+		}
+		data = decompressed
+	}
+	return json.Unmarshal(data, model)
+}
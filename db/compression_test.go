@@ -0,0 +1,101 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCompressionDictionary = `{"Name":"","Age":0,"Nicknames":["Bobby","Bob"]}`
+
+func TestCompressorRoundTrip(t *testing.T) {
+	t.Parallel()
+	c := newCompressor([]byte(testCompressionDictionary))
+	data := []byte(`{"Name":"Robert","Age":42,"Nicknames":["Bobby","Bob","Rob","Robbie"]}`)
+	compressed, err := c.compress(data)
+	require.NoError(t, err)
+	assert.Equal(t, compressedMarker, compressed[0])
+	assert.True(t, len(compressed) < len(data), "expected compressed data to be smaller than %d bytes but got %d", len(data), len(compressed))
+	decompressed, err := c.decompress(compressed)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	// Data which doesn't get any smaller should be stored as is.
+	incompressible := []byte(`{"A":1}`)
+	actual, err := c.compress(incompressible)
+	require.NoError(t, err)
+	assert.Equal(t, incompressible, actual)
+}
+
+func TestCompressedCollection(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	col.SetCompressionDictionary([]byte(testCompressionDictionary))
+	ageIndex := col.AddIndex("age", func(m Model) []byte {
+		return []byte{byte(m.(*testModel).Age)}
+	})
+
+	// Store a model without compression, as if it was stored before compression
+	// was enabled.
+	legacy := &testModel{
+		Name: "legacy",
+		Age:  42,
+	}
+	legacyData, err := json.Marshal(legacy)
+	require.NoError(t, err)
+	require.NoError(t, db.ldb.Put(col.info.primaryKeyForModel(legacy), legacyData, nil))
+
+	expected := &testModel{
+		Name:      "Robert",
+		Age:       42,
+		Nicknames: []string{"Bobby", "Bob", "Rob", "Robbie"},
+	}
+	require.NoError(t, col.Insert(expected))
+	data, err := db.ldb.Get(col.info.primaryKeyForModel(expected), nil)
+	require.NoError(t, err)
+	assert.Equal(t, compressedMarker, data[0], "model data was not compressed")
+
+	actual := &testModel{}
+	require.NoError(t, col.FindByID(expected.ID(), actual))
+	assert.Equal(t, expected, actual)
+	actualLegacy := &testModel{}
+	require.NoError(t, col.FindByID(legacy.ID(), actualLegacy))
+	assert.Equal(t, legacy, actualLegacy)
+
+	var all []*testModel
+	require.NoError(t, col.FindAll(&all))
+	assert.Len(t, all, 2)
+	var found []*testModel
+	require.NoError(t, col.NewQuery(ageIndex.ValueFilter([]byte{42})).Run(&found))
+	require.Len(t, found, 1)
+	assert.Equal(t, expected, found[0])
+
+	expected.Age = 43
+	require.NoError(t, col.Update(expected))
+	require.NoError(t, col.FindByID(expected.ID(), actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestCompressedDataWithoutDictionary(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	col.SetCompressionDictionary([]byte(testCompressionDictionary))
+	model := &testModel{
+		Name:      "Robert",
+		Nicknames: []string{"Bobby", "Bob", "Rob", "Robbie"},
+	}
+	require.NoError(t, col.Insert(model))
+
+	// Simulate opening the same database without enabling compression.
+	col.info.compressor = nil
+	err = col.FindByID(model.ID(), &testModel{})
+	assert.Equal(t, errNoCompressionDictionary, err)
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 // Note about the implementation:
@@ -30,10 +31,16 @@ type Model interface {
 
 // DB is the top-level Database.
 type DB struct {
-	ldb             *leveldb.DB
-	globalWriteLock sync.RWMutex
-	collections     []*Collection
-	colLock         sync.Mutex
+	// slowQueryThreshold is the threshold (as a time.Duration) for logging slow
+	// queries and slowQueryCount is the number of slow queries. They must be
+	// accessed atomically and are at the start of the struct to ensure 64-bit
+	// alignment.
+	slowQueryThreshold int64
+	slowQueryCount     uint64
+	ldb                *leveldb.DB
+	globalWriteLock    sync.RWMutex
+	collections        []*Collection
+	colLock            sync.Mutex
 }
 
 // Close closes the database. It is not safe to call Close if there are any
@@ -42,3 +49,16 @@ type DB struct {
 func (db *DB) Close() error {
 	return db.ldb.Close()
 }
+
+// ApproximateSize returns the approximate amount of storage space used by the
+// database in bytes. Recent writes which have not yet been compacted to disk
+// are not included.
+func (db *DB) ApproximateSize() (int64, error) {
+	// All keys start with a printable ASCII prefix (e.g. "model:" or "index:"),
+	// so this range covers the entire database.
+	sizes, err := db.ldb.SizeOf([]util.Range{{Start: []byte{0x00}, Limit: []byte{0xff}}})
+	if err != nil {
+		return 0, err
+	}
+	return sizes.Sum(), nil
+}
@@ -1,12 +1,12 @@
 package db
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/util"
@@ -24,7 +24,7 @@ func findByID(info *colInfo, reader dbReader, id []byte, model Model) error {
 		}
 		return err
 	}
-	return json.Unmarshal(data, model)
+	return info.decodeModel(data, model)
 }
 
 func findAll(info *colInfo, reader dbReader, models interface{}) error {
@@ -44,7 +44,7 @@ func findWithIterator(info *colInfo, iter iterator.Iterator, models interface{})
 		// model.
 		data := iter.Value()
 		model := reflect.New(info.modelType)
-		if err := json.Unmarshal(data, model.Interface()); err != nil {
+		if err := info.decodeModel(data, model.Interface()); err != nil {
 			return err
 		}
 		modelsVal.Set(reflect.Append(modelsVal, model.Elem()))
@@ -63,7 +63,7 @@ func findExistingModelByPrimaryKeyWithTransaction(info *colInfo, readWriter dbRe
 	}
 	// Use reflect to create a new reference for the model type.
 	modelRef := reflect.New(info.modelType).Interface()
-	if err := json.Unmarshal(data, modelRef); err != nil {
+	if err := info.decodeModel(data, modelRef); err != nil {
 		return nil, err
 	}
 	model := reflect.ValueOf(modelRef).Elem().Interface().(Model)
@@ -71,13 +71,16 @@ func findExistingModelByPrimaryKeyWithTransaction(info *colInfo, readWriter dbRe
 }
 
 func insertWithTransaction(info *colInfo, readWriter dbReadWriter, model Model) error {
+	if err := chaos.FailDBWrite(); err != nil {
+		return err
+	}
 	if len(model.ID()) == 0 {
 		return errors.New("can't insert model with empty ID")
 	}
 	if err := info.checkModelType(model); err != nil {
 		return err
 	}
-	data, err := json.Marshal(model)
+	data, err := info.encodeModel(model)
 	if err != nil {
 		return err
 	}
@@ -97,6 +100,9 @@ func insertWithTransaction(info *colInfo, readWriter dbReadWriter, model Model)
 }
 
 func updateWithTransaction(info *colInfo, readWriter dbReadWriter, model Model) error {
+	if err := chaos.FailDBWrite(); err != nil {
+		return err
+	}
 	if len(model.ID()) == 0 {
 		return errors.New("can't update model with empty ID")
 	}
@@ -122,7 +128,7 @@ func updateWithTransaction(info *colInfo, readWriter dbReadWriter, model Model)
 	}
 
 	// Save the new data and add the new indexes.
-	newData, err := json.Marshal(model)
+	newData, err := info.encodeModel(model)
 	if err != nil {
 		return err
 	}
@@ -136,6 +142,9 @@ func updateWithTransaction(info *colInfo, readWriter dbReadWriter, model Model)
 }
 
 func deleteWithTransaction(info *colInfo, readWriter dbReadWriter, id []byte) error {
+	if err := chaos.FailDBWrite(); err != nil {
+		return err
+	}
 	if len(id) == 0 {
 		return errors.New("can't delete model with empty ID")
 	}
@@ -1,9 +1,9 @@
 package db
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 
@@ -112,12 +112,20 @@ func (q *Query) Run(models interface{}) error {
 		return err
 	}
 
+	start := time.Now()
 	iter := q.reader.NewIterator(q.filter.slice, nil)
 	defer iter.Release()
+	var err error
 	if q.reverse {
-		return q.getModelsWithIteratorReverse(iter, models)
+		err = q.getModelsWithIteratorReverse(iter, models)
+	} else {
+		err = q.getModelsWithIteratorForward(iter, models)
 	}
-	return q.getModelsWithIteratorForward(iter, models)
+	if err != nil {
+		return err
+	}
+	q.logIfSlow("Run", start, reflect.ValueOf(models).Elem().Len())
+	return nil
 }
 
 // Count returns the number of unique models that match the query. It does not
@@ -125,6 +133,7 @@ func (q *Query) Run(models interface{}) error {
 // respect q.Max. If the number of models that match the filter is greater than
 // q.Max, it will stop counting and return q.Max.
 func (q *Query) Count() (int, error) {
+	start := time.Now()
 	iter := q.reader.NewIterator(q.filter.slice, nil)
 	defer iter.Release()
 	pkSet := stringset.New()
@@ -141,6 +150,7 @@ func (q *Query) Count() (int, error) {
 	if iter.Error() != nil {
 		return 0, iter.Error()
 	}
+	q.logIfSlow("Count", start, len(pkSet))
 	return len(pkSet), nil
 }
 
@@ -206,7 +216,7 @@ func (q *Query) getAndAppendModelIfUnique(index *Index, pkSet stringset.Set, key
 		return err
 	}
 	model := reflect.New(q.colInfo.modelType)
-	if err := json.Unmarshal(data, model.Interface()); err != nil {
+	if err := q.colInfo.decodeModel(data, model.Interface()); err != nil {
 		return err
 	}
 	modelsVal.Set(reflect.Append(modelsVal, model.Elem()))
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"bytes"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SetSlowQueryThreshold sets the duration after which queries are considered
+// slow. Slow queries are logged along with their filter and options and
+// counted (see SlowQueryCount), which helps to diagnose pathological queries
+// on large databases. A threshold of 0 (the default) disables slow query
+// logging.
+func (db *DB) SetSlowQueryThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&db.slowQueryThreshold, int64(threshold))
+}
+
+// SlowQueryCount returns the number of queries which exceeded the slow query
+// threshold since the database was opened.
+func (db *DB) SlowQueryCount() uint64 {
+	return atomic.LoadUint64(&db.slowQueryCount)
+}
+
+// logIfSlow logs the query and increments the slow query count if the query
+// took longer than the slow query threshold. operation is the name of the
+// method that ran the query and numResults is the number of models it
+// returned.
+func (q *Query) logIfSlow(operation string, start time.Time, numResults int) {
+	db := q.colInfo.db
+	if db == nil {
+		return
+	}
+	threshold := time.Duration(atomic.LoadInt64(&db.slowQueryThreshold))
+	if threshold <= 0 {
+		return
+	}
+	duration := time.Since(start)
+	if duration < threshold {
+		return
+	}
+	atomic.AddUint64(&db.slowQueryCount, 1)
+	// Index keys start with the prefix of the index, which is trimmed to make
+	// the log easier to read.
+	indexPrefix := append(q.filter.index.prefix(), ':')
+	log.WithFields(log.Fields{
+		"collection":  q.colInfo.name,
+		"index":       q.filter.index.name,
+		"operation":   operation,
+		"filterStart": string(bytes.TrimPrefix(q.filter.slice.Start, indexPrefix)),
+		"filterLimit": string(bytes.TrimPrefix(q.filter.slice.Limit, indexPrefix)),
+		"max":         q.max,
+		"offset":      q.offset,
+		"reverse":     q.reverse,
+		"numResults":  numResults,
+		"duration":    duration.String(),
+		"threshold":   threshold.String(),
+	}).Warn("slow database query")
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowQueryCount(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+	ageIndex := col.AddIndex("age", func(m Model) []byte {
+		return []byte{byte(m.(*testModel).Age)}
+	})
+	require.NoError(t, col.Insert(&testModel{Name: "foo", Age: 42}))
+
+	// Slow query logging is disabled by default.
+	var models []*testModel
+	require.NoError(t, col.NewQuery(ageIndex.All()).Run(&models))
+	assert.Equal(t, uint64(0), db.SlowQueryCount())
+
+	// With a tiny threshold, every query is slow.
+	db.SetSlowQueryThreshold(time.Nanosecond)
+	models = []*testModel{}
+	require.NoError(t, col.NewQuery(ageIndex.All()).Run(&models))
+	assert.Equal(t, uint64(1), db.SlowQueryCount())
+	_, err = col.NewQuery(ageIndex.ValueFilter([]byte{42})).Count()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), db.SlowQueryCount())
+
+	db.SetSlowQueryThreshold(time.Hour)
+	models = []*testModel{}
+	require.NoError(t, col.NewQuery(ageIndex.All()).Run(&models))
+	assert.Equal(t, uint64(2), db.SlowQueryCount())
+}
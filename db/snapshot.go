@@ -25,6 +25,17 @@ func (c *Collection) GetSnapshot() (*Snapshot, error) {
 	}, nil
 }
 
+// WithCollection returns a snapshot of the given collection at the same point
+// in time as s. This can be used to read from several collections of the same
+// DB consistently. The returned snapshot shares the underlying DB snapshot with
+// s, so releasing either of them releases both.
+func (s *Snapshot) WithCollection(c *Collection) *Snapshot {
+	return &Snapshot{
+		colInfo:  c.info.copy(),
+		snapshot: s.snapshot,
+	}
+}
+
 // Release releases the snapshot. This will not release any ongoing queries,
 // which will still finish unless the database is closed. Other methods should
 // not be called after the snapshot has been released.
@@ -67,3 +67,39 @@ func TestSnapshot(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, len(expected), actualCount)
 }
+
+func TestSnapshotWithCollection(t *testing.T) {
+	t.Parallel()
+	db := newTestDB(t)
+	defer db.Close()
+	hotCol, err := db.NewCollection("hotPeople", &testModel{})
+	require.NoError(t, err)
+	coldCol, err := db.NewCollection("coldPeople", &testModel{})
+	require.NoError(t, err)
+
+	model := &testModel{
+		Name: "Person",
+		Age:  42,
+	}
+	require.NoError(t, hotCol.Insert(model))
+
+	// Take a snapshot of both collections.
+	hotSnapshot, err := hotCol.GetSnapshot()
+	require.NoError(t, err)
+	defer hotSnapshot.Release()
+	coldSnapshot := hotSnapshot.WithCollection(coldCol)
+
+	// Move the model from one collection to the other.
+	txn := db.OpenGlobalTransaction()
+	require.NoError(t, txn.Delete(hotCol, model.ID()))
+	require.NoError(t, txn.Insert(coldCol, model))
+	require.NoError(t, txn.Commit())
+
+	// The snapshots should both reflect the state before the model was moved.
+	var actual []*testModel
+	require.NoError(t, hotSnapshot.FindAll(&actual))
+	assert.Equal(t, []*testModel{model}, actual)
+	actualCount, err := coldSnapshot.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, actualCount)
+}
@@ -0,0 +1,405 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The binary encoding is used to pass large batches of orders between the Go
+// Wasm code and the TypeScript bindings without going through JSON. It is a
+// simple, fixed layout in which:
+//
+//   - uint256 values are encoded as 32 big-endian bytes,
+//   - addresses and hashes are encoded as-is (20 and 32 bytes),
+//   - byte slices and strings are prefixed with their length as a big-endian
+//     uint32,
+//   - lists are prefixed with their length as a big-endian uint32,
+//   - timestamps are encoded as milliseconds since the Unix epoch in a
+//     big-endian int64,
+//   - optional values are prefixed with a single byte which is 1 if the value
+//     is present and 0 otherwise.
+//
+// Signed orders are encoded field by field in the order in which the fields
+// are declared in zeroex.Order, followed by the signature. The TypeScript
+// implementation lives in packages/browser-lite/src/binary_encoding.ts and
+// must be kept in sync with this file.
+
+const (
+	uint256Size = 32
+	// maxBinaryListLength is the maximum length of a list in the binary
+	// encoding. It guards against allocating huge slices for malformed input.
+	maxBinaryListLength = 1 << 20
+)
+
+var (
+	// ErrBinaryTruncated is returned when decoding binary data which ends
+	// unexpectedly.
+	ErrBinaryTruncated = errors.New("binary data is truncated")
+	// ErrBinaryTrailingData is returned when decoding binary data which
+	// contains unexpected bytes at the end.
+	ErrBinaryTrailingData = errors.New("binary data contains trailing bytes")
+)
+
+// EncodeOrdersBinary encodes the given signed orders using the binary
+// encoding.
+func EncodeOrdersBinary(orders []*zeroex.SignedOrder) ([]byte, error) {
+	w := &binaryWriter{}
+	w.writeLength(len(orders))
+	for _, order := range orders {
+		w.writeSignedOrder(order)
+	}
+	return w.bytes()
+}
+
+// DecodeOrdersBinary decodes signed orders which were encoded with
+// EncodeOrdersBinary.
+func DecodeOrdersBinary(data []byte) ([]*zeroex.SignedOrder, error) {
+	r := &binaryReader{data: data}
+	orders := make([]*zeroex.SignedOrder, r.readLength())
+	for i := range orders {
+		orders[i] = r.readSignedOrder()
+	}
+	if err := r.finish(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// EncodeValidationResultsBinary encodes the given validation results using the
+// binary encoding. Validation traces are not included.
+func EncodeValidationResultsBinary(results *ordervalidator.ValidationResults) ([]byte, error) {
+	w := &binaryWriter{}
+	w.writeLength(len(results.Accepted))
+	for _, info := range results.Accepted {
+		w.writeHash(info.OrderHash)
+		w.writeSignedOrder(info.SignedOrder)
+		w.writeUint256(info.FillableTakerAssetAmount)
+		w.writeBool(info.IsNew)
+	}
+	w.writeLength(len(results.Rejected))
+	for _, info := range results.Rejected {
+		w.writeHash(info.OrderHash)
+		// The order of a rejected order is nil if it couldn't be parsed.
+		w.writeBool(info.SignedOrder != nil)
+		if info.SignedOrder != nil {
+			w.writeSignedOrder(info.SignedOrder)
+		}
+		w.writeString(string(info.Kind))
+		w.writeString(info.Status.Code)
+		w.writeString(info.Status.Message)
+	}
+	return w.bytes()
+}
+
+// DecodeValidationResultsBinary decodes validation results which were encoded
+// with EncodeValidationResultsBinary.
+func DecodeValidationResultsBinary(data []byte) (*ordervalidator.ValidationResults, error) {
+	r := &binaryReader{data: data}
+	results := &ordervalidator.ValidationResults{}
+	results.Accepted = make([]*ordervalidator.AcceptedOrderInfo, r.readLength())
+	for i := range results.Accepted {
+		results.Accepted[i] = &ordervalidator.AcceptedOrderInfo{
+			OrderHash:                r.readHash(),
+			SignedOrder:              r.readSignedOrder(),
+			FillableTakerAssetAmount: r.readUint256(),
+			IsNew:                    r.readBool(),
+		}
+	}
+	results.Rejected = make([]*ordervalidator.RejectedOrderInfo, r.readLength())
+	for i := range results.Rejected {
+		rejected := &ordervalidator.RejectedOrderInfo{
+			OrderHash: r.readHash(),
+		}
+		if r.readBool() {
+			rejected.SignedOrder = r.readSignedOrder()
+		}
+		rejected.Kind = ordervalidator.RejectedOrderKind(r.readString())
+		rejected.Status = ordervalidator.RejectedOrderStatus{
+			Code:    r.readString(),
+			Message: r.readString(),
+		}
+		results.Rejected[i] = rejected
+	}
+	if err := r.finish(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// EncodeGetOrdersResponseBinary encodes the given response using the binary
+// encoding. Fillability scores are not included.
+func EncodeGetOrdersResponseBinary(response *types.GetOrdersResponse) ([]byte, error) {
+	w := &binaryWriter{}
+	w.writeString(response.SnapshotID)
+	w.writeTime(response.SnapshotTimestamp)
+	w.writeLength(len(response.OrdersInfos))
+	for _, info := range response.OrdersInfos {
+		w.writeHash(info.OrderHash)
+		w.writeSignedOrder(info.SignedOrder)
+		w.writeUint256(info.FillableTakerAssetAmount)
+		w.writeBool(info.LastValidatedBlockNumber != nil)
+		if info.LastValidatedBlockNumber != nil {
+			w.writeUint256(info.LastValidatedBlockNumber)
+		}
+		w.writeHash(info.LastValidatedBlockHash)
+		w.writeTime(info.NextRevalidationTime)
+	}
+	return w.bytes()
+}
+
+// DecodeGetOrdersResponseBinary decodes a response which was encoded with
+// EncodeGetOrdersResponseBinary.
+func DecodeGetOrdersResponseBinary(data []byte) (*types.GetOrdersResponse, error) {
+	r := &binaryReader{data: data}
+	response := &types.GetOrdersResponse{
+		SnapshotID:        r.readString(),
+		SnapshotTimestamp: r.readTime(),
+	}
+	response.OrdersInfos = make([]*types.OrderInfo, r.readLength())
+	for i := range response.OrdersInfos {
+		info := &types.OrderInfo{
+			OrderHash:                r.readHash(),
+			SignedOrder:              r.readSignedOrder(),
+			FillableTakerAssetAmount: r.readUint256(),
+		}
+		if r.readBool() {
+			info.LastValidatedBlockNumber = r.readUint256()
+		}
+		info.LastValidatedBlockHash = r.readHash()
+		info.NextRevalidationTime = r.readTime()
+		response.OrdersInfos[i] = info
+	}
+	if err := r.finish(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// binaryWriter writes values using the binary encoding. Once an error occurs,
+// all subsequent writes are ignored and the error is returned by bytes.
+type binaryWriter struct {
+	buf []byte
+	err error
+}
+
+func (w *binaryWriter) bytes() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.buf, nil
+}
+
+func (w *binaryWriter) writeUint32(value uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], value)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *binaryWriter) writeLength(length int) {
+	if w.err == nil && uint64(length) > math.MaxUint32 {
+		w.err = fmt.Errorf("length %d is too large for the binary encoding", length)
+	}
+	w.writeUint32(uint32(length))
+}
+
+func (w *binaryWriter) writeBool(value bool) {
+	if value {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *binaryWriter) writeBytes(value []byte) {
+	w.writeLength(len(value))
+	w.buf = append(w.buf, value...)
+}
+
+func (w *binaryWriter) writeString(value string) {
+	w.writeLength(len(value))
+	w.buf = append(w.buf, value...)
+}
+
+func (w *binaryWriter) writeHash(value common.Hash) {
+	w.buf = append(w.buf, value.Bytes()...)
+}
+
+func (w *binaryWriter) writeAddress(value common.Address) {
+	w.buf = append(w.buf, value.Bytes()...)
+}
+
+func (w *binaryWriter) writeUint256(value *big.Int) {
+	var b [uint256Size]byte
+	if value == nil {
+		if w.err == nil {
+			w.err = errors.New("cannot encode nil uint256")
+		}
+	} else if value.Sign() < 0 || value.BitLen() > 256 {
+		if w.err == nil {
+			w.err = fmt.Errorf("%s is not a valid uint256", value.String())
+		}
+	} else {
+		valueBytes := value.Bytes()
+		copy(b[uint256Size-len(valueBytes):], valueBytes)
+	}
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *binaryWriter) writeTime(value time.Time) {
+	// Computed this way (instead of with UnixNano) so that times outside of
+	// the range of UnixNano, like the zero time, are encoded correctly.
+	millis := value.Unix()*1000 + int64(value.Nanosecond())/int64(time.Millisecond)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(millis))
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *binaryWriter) writeSignedOrder(order *zeroex.SignedOrder) {
+	if order == nil {
+		if w.err == nil {
+			w.err = errors.New("cannot encode nil order")
+		}
+		return
+	}
+	w.writeUint256(order.ChainID)
+	w.writeAddress(order.ExchangeAddress)
+	w.writeAddress(order.MakerAddress)
+	w.writeBytes(order.MakerAssetData)
+	w.writeBytes(order.MakerFeeAssetData)
+	w.writeUint256(order.MakerAssetAmount)
+	w.writeUint256(order.MakerFee)
+	w.writeAddress(order.TakerAddress)
+	w.writeBytes(order.TakerAssetData)
+	w.writeBytes(order.TakerFeeAssetData)
+	w.writeUint256(order.TakerAssetAmount)
+	w.writeUint256(order.TakerFee)
+	w.writeAddress(order.SenderAddress)
+	w.writeAddress(order.FeeRecipientAddress)
+	w.writeUint256(order.ExpirationTimeSeconds)
+	w.writeUint256(order.Salt)
+	w.writeBytes(order.Signature)
+}
+
+// binaryReader reads values using the binary encoding. Once an error occurs,
+// all subsequent reads return zero values and the error is returned by finish.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+// next returns the next n bytes, or nil if there are fewer than n bytes left.
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = ErrBinaryTruncated
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *binaryReader) finish() error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 0 {
+		return ErrBinaryTrailingData
+	}
+	return nil
+}
+
+func (r *binaryReader) readUint32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *binaryReader) readLength() int {
+	length := r.readUint32()
+	if length > maxBinaryListLength {
+		if r.err == nil {
+			r.err = fmt.Errorf("length %d exceeds the maximum of %d", length, maxBinaryListLength)
+		}
+		return 0
+	}
+	return int(length)
+}
+
+func (r *binaryReader) readBool() bool {
+	b := r.next(1)
+	if b == nil {
+		return false
+	}
+	return b[0] != 0
+}
+
+func (r *binaryReader) readBytes() []byte {
+	length := int(r.readUint32())
+	b := r.next(length)
+	if b == nil {
+		return nil
+	}
+	// Copy so that the result does not retain the underlying buffer.
+	return append([]byte{}, b...)
+}
+
+func (r *binaryReader) readString() string {
+	return string(r.next(int(r.readUint32())))
+}
+
+func (r *binaryReader) readHash() common.Hash {
+	return common.BytesToHash(r.next(common.HashLength))
+}
+
+func (r *binaryReader) readAddress() common.Address {
+	return common.BytesToAddress(r.next(common.AddressLength))
+}
+
+func (r *binaryReader) readUint256() *big.Int {
+	return new(big.Int).SetBytes(r.next(uint256Size))
+}
+
+func (r *binaryReader) readTime() time.Time {
+	b := r.next(8)
+	if b == nil {
+		return time.Time{}
+	}
+	millis := int64(binary.BigEndian.Uint64(b))
+	return time.Unix(millis/1000, (millis%1000)*int64(time.Millisecond)).UTC()
+}
+
+func (r *binaryReader) readSignedOrder() *zeroex.SignedOrder {
+	order := &zeroex.SignedOrder{}
+	order.ChainID = r.readUint256()
+	order.ExchangeAddress = r.readAddress()
+	order.MakerAddress = r.readAddress()
+	order.MakerAssetData = r.readBytes()
+	order.MakerFeeAssetData = r.readBytes()
+	order.MakerAssetAmount = r.readUint256()
+	order.MakerFee = r.readUint256()
+	order.TakerAddress = r.readAddress()
+	order.TakerAssetData = r.readBytes()
+	order.TakerFeeAssetData = r.readBytes()
+	order.TakerAssetAmount = r.readUint256()
+	order.TakerFee = r.readUint256()
+	order.SenderAddress = r.readAddress()
+	order.FeeRecipientAddress = r.readAddress()
+	order.ExpirationTimeSeconds = r.readUint256()
+	order.Salt = r.readUint256()
+	order.Signature = zeroex.NormalizeSignature(r.readBytes())
+	return order
+}
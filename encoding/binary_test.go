@@ -0,0 +1,232 @@
+package encoding
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSignedOrder(i int) *zeroex.SignedOrder {
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			ChainID:               big.NewInt(1337),
+			ExchangeAddress:       common.HexToAddress("0x48bacb9266a570d521063ef5dd96e61686dbe788"),
+			MakerAddress:          common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+			MakerAssetData:        common.FromHex("0xf47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			MakerFeeAssetData:     []byte{},
+			MakerAssetAmount:      big.NewInt(int64(i + 1)),
+			MakerFee:              big.NewInt(0),
+			TakerAddress:          common.Address{},
+			TakerAssetData:        common.FromHex("0xf47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082"),
+			TakerFeeAssetData:     []byte{},
+			TakerAssetAmount:      maxUint256,
+			TakerFee:              big.NewInt(0),
+			SenderAddress:         common.Address{},
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			ExpirationTimeSeconds: big.NewInt(1588000000),
+			Salt:                  big.NewInt(int64(i)),
+		},
+		Signature: common.FromHex("0x1c3582f06356a1314dbf1c0e534c4d8e92e59b056ee607a7ff5a825f5f2cc5e6151c5cc7fdd420f5608e4d5bef108e42ad90c7a4b408caef32e24374cf387b0d7603"),
+	}
+}
+
+func assertSignedOrdersEqual(t *testing.T, expected, actual *zeroex.SignedOrder) {
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+func TestOrdersBinaryRoundTrip(t *testing.T) {
+	orders := []*zeroex.SignedOrder{newTestSignedOrder(0), newTestSignedOrder(1)}
+	encoded, err := EncodeOrdersBinary(orders)
+	require.NoError(t, err)
+	decoded, err := DecodeOrdersBinary(encoded)
+	require.NoError(t, err)
+	require.Len(t, decoded, len(orders))
+	for i := range orders {
+		assertSignedOrdersEqual(t, orders[i], decoded[i])
+	}
+}
+
+func TestValidationResultsBinaryRoundTrip(t *testing.T) {
+	results := &ordervalidator.ValidationResults{
+		Accepted: []*ordervalidator.AcceptedOrderInfo{
+			{
+				OrderHash:                common.HexToHash("0x1"),
+				SignedOrder:              newTestSignedOrder(0),
+				FillableTakerAssetAmount: big.NewInt(42),
+				IsNew:                    true,
+			},
+		},
+		Rejected: []*ordervalidator.RejectedOrderInfo{
+			{
+				OrderHash:   common.HexToHash("0x2"),
+				SignedOrder: newTestSignedOrder(1),
+				Kind:        ordervalidator.MeshValidation,
+				Status:      ordervalidator.ROMaxExpirationExceeded,
+			},
+		},
+	}
+	encoded, err := EncodeValidationResultsBinary(results)
+	require.NoError(t, err)
+	decoded, err := DecodeValidationResultsBinary(encoded)
+	require.NoError(t, err)
+
+	require.Len(t, decoded.Accepted, 1)
+	assert.Equal(t, results.Accepted[0].OrderHash, decoded.Accepted[0].OrderHash)
+	assertSignedOrdersEqual(t, results.Accepted[0].SignedOrder, decoded.Accepted[0].SignedOrder)
+	assert.Equal(t, results.Accepted[0].FillableTakerAssetAmount, decoded.Accepted[0].FillableTakerAssetAmount)
+	assert.True(t, decoded.Accepted[0].IsNew)
+	require.Len(t, decoded.Rejected, 1)
+	assert.Equal(t, results.Rejected[0].OrderHash, decoded.Rejected[0].OrderHash)
+	assertSignedOrdersEqual(t, results.Rejected[0].SignedOrder, decoded.Rejected[0].SignedOrder)
+	assert.Equal(t, results.Rejected[0].Kind, decoded.Rejected[0].Kind)
+	assert.Equal(t, results.Rejected[0].Status, decoded.Rejected[0].Status)
+}
+
+func TestValidationResultsBinaryRoundTripWithoutOrder(t *testing.T) {
+	// Orders which can't be parsed are rejected without a SignedOrder.
+	results := &ordervalidator.ValidationResults{
+		Rejected: []*ordervalidator.RejectedOrderInfo{
+			{
+				Kind: ordervalidator.MeshValidation,
+				Status: ordervalidator.RejectedOrderStatus{
+					Code:    ordervalidator.ROInvalidSchemaCode,
+					Message: "order did not pass JSON-schema validation",
+				},
+			},
+		},
+	}
+	encoded, err := EncodeValidationResultsBinary(results)
+	require.NoError(t, err)
+	decoded, err := DecodeValidationResultsBinary(encoded)
+	require.NoError(t, err)
+
+	require.Len(t, decoded.Rejected, 1)
+	assert.Nil(t, decoded.Rejected[0].SignedOrder)
+	assert.Equal(t, results.Rejected[0].Kind, decoded.Rejected[0].Kind)
+	assert.Equal(t, results.Rejected[0].Status, decoded.Rejected[0].Status)
+}
+
+func TestGetOrdersResponseBinaryRoundTrip(t *testing.T) {
+	response := &types.GetOrdersResponse{
+		SnapshotID:        "f47ac10b-58cc-0372-8567-0e02b2c3d479",
+		SnapshotTimestamp: time.Date(2020, 4, 8, 10, 32, 11, 402000000, time.UTC),
+		OrdersInfos: []*types.OrderInfo{
+			{
+				OrderHash:                common.HexToHash("0x1"),
+				SignedOrder:              newTestSignedOrder(0),
+				FillableTakerAssetAmount: big.NewInt(42),
+				LastValidatedBlockNumber: big.NewInt(100),
+				LastValidatedBlockHash:   common.HexToHash("0x3"),
+				NextRevalidationTime:     time.Date(2020, 4, 8, 11, 0, 0, 0, time.UTC),
+			},
+			{
+				// Orders stored before LastValidatedBlockNumber was introduced.
+				OrderHash:                common.HexToHash("0x2"),
+				SignedOrder:              newTestSignedOrder(1),
+				FillableTakerAssetAmount: big.NewInt(0),
+			},
+		},
+	}
+	encoded, err := EncodeGetOrdersResponseBinary(response)
+	require.NoError(t, err)
+	decoded, err := DecodeGetOrdersResponseBinary(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, response.SnapshotID, decoded.SnapshotID)
+	assert.True(t, response.SnapshotTimestamp.Equal(decoded.SnapshotTimestamp))
+	require.Len(t, decoded.OrdersInfos, len(response.OrdersInfos))
+	for i, expected := range response.OrdersInfos {
+		actual := decoded.OrdersInfos[i]
+		assert.Equal(t, expected.OrderHash, actual.OrderHash)
+		assertSignedOrdersEqual(t, expected.SignedOrder, actual.SignedOrder)
+		// Note: big.Int values are compared with Cmp since zero values
+		// don't necessarily have the same internal representation.
+		assert.Equal(t, 0, expected.FillableTakerAssetAmount.Cmp(actual.FillableTakerAssetAmount), "expected %s but got %s", expected.FillableTakerAssetAmount, actual.FillableTakerAssetAmount)
+		if expected.LastValidatedBlockNumber == nil {
+			assert.Nil(t, actual.LastValidatedBlockNumber)
+		} else {
+			assert.Equal(t, 0, expected.LastValidatedBlockNumber.Cmp(actual.LastValidatedBlockNumber), "expected %s but got %s", expected.LastValidatedBlockNumber, actual.LastValidatedBlockNumber)
+		}
+		assert.Equal(t, expected.LastValidatedBlockHash, actual.LastValidatedBlockHash)
+		assert.True(t, expected.NextRevalidationTime.Equal(actual.NextRevalidationTime), "expected %s but got %s", expected.NextRevalidationTime, actual.NextRevalidationTime)
+	}
+}
+
+func TestDecodeOrdersBinaryInvalid(t *testing.T) {
+	encoded, err := EncodeOrdersBinary([]*zeroex.SignedOrder{newTestSignedOrder(0)})
+	require.NoError(t, err)
+
+	_, err = DecodeOrdersBinary(encoded[:len(encoded)-1])
+	assert.Equal(t, ErrBinaryTruncated, err)
+	_, err = DecodeOrdersBinary(append(encoded, 0))
+	assert.Equal(t, ErrBinaryTrailingData, err)
+}
+
+func TestEncodeOrdersBinaryInvalidUint256(t *testing.T) {
+	order := newTestSignedOrder(0)
+	order.Salt = new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err := EncodeOrdersBinary([]*zeroex.SignedOrder{order})
+	assert.Error(t, err)
+}
+
+// The following benchmarks compare the binary encoding to JSON for the batch
+// sizes which are typical when adding orders in the browser.
+
+func newBenchmarkOrders(count int) []*zeroex.SignedOrder {
+	orders := make([]*zeroex.SignedOrder, count)
+	for i := range orders {
+		orders[i] = newTestSignedOrder(i)
+	}
+	return orders
+}
+
+func BenchmarkOrdersBinaryRoundTrip(b *testing.B) {
+	for _, count := range []int{100, 10000} {
+		orders := newBenchmarkOrders(count)
+		b.Run(fmt.Sprintf("%d orders", count), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				encoded, err := EncodeOrdersBinary(orders)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := DecodeOrdersBinary(encoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkOrdersJSONRoundTrip(b *testing.B) {
+	for _, count := range []int{100, 10000} {
+		orders := newBenchmarkOrders(count)
+		b.Run(fmt.Sprintf("%d orders", count), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				encoded, err := json.Marshal(orders)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var decoded []*zeroex.SignedOrder
+				if err := json.Unmarshal(encoded, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
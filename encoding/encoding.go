@@ -13,11 +13,24 @@ type orderMessage struct {
 	Topics      []string            `json:"topics"`
 }
 
+// outgoingOrderMessage is the encoding of an orderMessage which is sent over
+// the wire. The order is encoded with zeroex.MarshalCanonicalJSON, so only the
+// canonical fields of the order can ever be sent to peers.
+type outgoingOrderMessage struct {
+	MessageType string          `json:"messageType"`
+	Order       json.RawMessage `json:"order"`
+	Topics      []string        `json:"topics"`
+}
+
 // OrderToRawMessage encodes an order into an order message to be sent over the wire
 func OrderToRawMessage(topic string, order *zeroex.SignedOrder) ([]byte, error) {
-	return json.Marshal(orderMessage{
+	encodedOrder, err := zeroex.MarshalCanonicalJSON(order)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(outgoingOrderMessage{
 		MessageType: "order",
-		Order:       order,
+		Order:       encodedOrder,
 		Topics:      []string{topic},
 	})
 }
@@ -0,0 +1,32 @@
+package encoding
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderRawMessageRoundTrip(t *testing.T) {
+	t.Parallel()
+	order := newTestSignedOrder(1)
+	encoded, err := OrderToRawMessage("/0x-orders/version/3/chain/1337/schema/e30=", order)
+	require.NoError(t, err)
+
+	var message map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(encoded, &message))
+	assert.Len(t, message, 3)
+	assert.JSONEq(t, `"order"`, string(message["messageType"]))
+	assert.JSONEq(t, `["/0x-orders/version/3/chain/1337/schema/e30="]`, string(message["topics"]))
+	assert.NoError(t, zeroex.CheckCanonicalJSON(message["order"]))
+
+	decoded, err := RawMessageToOrder(encoded)
+	require.NoError(t, err)
+	expectedHash, err := order.ComputeOrderHash()
+	require.NoError(t, err)
+	actualHash, err := decoded.ComputeOrderHash()
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, actualHash)
+}
@@ -0,0 +1,39 @@
+// +build gofuzz
+
+package encoding
+
+import (
+	"encoding/json"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// This file contains harnesses for go-fuzz (https://github.com/dvyukov/go-fuzz)
+// and libFuzzer. They are run with `make fuzz`.
+
+// FuzzOrderMessageEncoding unmarshals data as a signed order (which may
+// contain any additional fields) and checks that the order message that would
+// be gossiped for it only contains the canonical fields of the order.
+func FuzzOrderMessageEncoding(data []byte) int {
+	var signedOrder zeroex.SignedOrder
+	if err := json.Unmarshal(data, &signedOrder); err != nil {
+		return 0
+	}
+	encoded, err := OrderToRawMessage("/0x-orders/version/3/chain/1337/schema/e30=", &signedOrder)
+	if err != nil {
+		panic(err)
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &message); err != nil {
+		panic(err)
+	}
+	for field := range message {
+		if field != "messageType" && field != "order" && field != "topics" {
+			panic("order message contains unexpected field: " + field)
+		}
+	}
+	if err := zeroex.CheckCanonicalJSON(message["order"]); err != nil {
+		panic(err)
+	}
+	return 1
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
 	"github.com/ethereum/go-ethereum"
@@ -22,12 +24,37 @@ import (
 // go-ethereum client `ethereum.NotFound` error type message
 const rpcClientNotFoundError = "not found"
 
-// maxBlocksInGetLogsQuery is the max number of blocks to fetch logs for in a single query. There is
-// a hard limit of 10,000 logs returned by a single `eth_getLogs` query by Infura's Ethereum nodes so
-// we need to try and stay below it. Parity, Geth and Alchemy all have much higher limits (if any) on
-// the number of logs returned so Infura is by far the limiting factor.
+// maxBlocksInGetLogsQuery is the default max number of blocks to fetch logs for in a single query.
+// There is a hard limit of 10,000 logs returned by a single `eth_getLogs` query by Infura's Ethereum
+// nodes so we need to try and stay below it. Parity, Geth and Alchemy all have much higher limits (if
+// any) on the number of logs returned so Infura is by far the limiting factor.
 var maxBlocksInGetLogsQuery = 60
 
+// logRangeLimitErrorMessages are substrings of the errors returned by Ethereum RPC providers when an
+// `eth_getLogs` query spans too many blocks or returns too many logs. Queries which fail with one of
+// these errors are split into smaller queries.
+var logRangeLimitErrorMessages = []string{
+	infuraTooManyResultsErrMsg,
+	"block range",
+	"range is too large",
+	"range too large",
+	"too many blocks",
+	"response size",
+	"response too large",
+}
+
+// unsupportedRequestErrorMessages are substrings of the errors returned by Ethereum RPC providers
+// (typically light clients and log-only providers) when they don't support a JSON RPC method or one
+// of its parameters.
+var unsupportedRequestErrorMessages = []string{
+	"method not found",
+	"does not exist/is not available",
+	"not supported",
+	"unsupported",
+	"unknown field",
+	"couldn't parse parameters: blockhash",
+}
+
 // warningLevelErrorMessages are certain blockwatch.Watch errors that we want to report as warnings
 // because they do not represent a bug or issue with Mesh and are expected to happen from time to time
 var warningLevelErrorMessages = []string{
@@ -35,6 +62,8 @@ var warningLevelErrorMessages = []string{
 	rpcClientNotFoundError,
 	"context deadline exceeded",
 	constants.ParityFilterUnknownBlock,
+	"while it was being fetched",
+	"while its logs were being fetched",
 }
 
 // EventType describes the types of events emitted by blockwatch.Watcher. A block can be discovered
@@ -66,8 +95,8 @@ type Stack interface {
 }
 
 // TooMayBlocksBehindError is an error returned if the BlockWatcher has fallen too many blocks behind
-// the latest block (>128 blocks), and cannot catch back up when connect to a non-archive Ethereum
-// node.
+// the latest block (>128 blocks by default), and cannot catch back up when connect to a non-archive
+// Ethereum node.
 type TooMayBlocksBehindError struct {
 	blocksMissing int
 }
@@ -76,6 +105,14 @@ func (e TooMayBlocksBehindError) Error() string {
 	return fmt.Sprintf("too many blocks (%d) behind the latest block", e.blocksMissing)
 }
 
+// LogFilter narrows down the logs fetched with `eth_getLogs` to those emitted
+// by one of Addresses and matching Topics. A nil Addresses matches logs emitted
+// by any contract. Topics has the same semantics as in ethereum.FilterQuery.
+type LogFilter struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
 // Config holds some configuration options for an instance of BlockWatcher.
 type Config struct {
 	Stack           Stack
@@ -83,6 +120,41 @@ type Config struct {
 	WithLogs        bool
 	Topics          []common.Hash
 	Client          Client
+	// DegradedMode, if true, causes the Watcher to avoid the requests which
+	// light clients and log-only providers often don't support: it fetches
+	// parent blocks by number instead of by hash (`eth_getBlockByHash`) and
+	// fetches the logs of a block by number instead of with a `blockHash`
+	// filter. Degraded mode is also enabled automatically the first time the
+	// Ethereum RPC endpoint reports that it doesn't support one of these
+	// requests.
+	DegradedMode bool
+	// MaxBlocksInGetLogsQuery is the max number of blocks to fetch logs for in
+	// a single `eth_getLogs` query. It is reduced automatically if the Ethereum
+	// RPC endpoint rejects queries for spanning too many blocks. If zero, a
+	// default of 60 is used.
+	MaxBlocksInGetLogsQuery int
+	// GetLogsRequestChunkSize is the number of `eth_getLogs` requests sent
+	// concurrently in each batch while catching up on missed blocks. If zero, a
+	// default of 3 is used.
+	GetLogsRequestChunkSize int
+	// MaxBlockHistory is the number of recent blocks for which the Ethereum RPC
+	// endpoint can serve logs. If the Watcher falls further behind than this,
+	// it cannot catch up by fetching the missed logs. If zero, a default of 128
+	// (the number of blocks for which a non-archive node stores state) is used.
+	MaxBlockHistory int
+	// OnTooManyBlocksBehind, if not nil, is called instead of stopping the
+	// Watcher when it falls more than MaxBlockHistory blocks behind the latest
+	// block (e.g. because the process was suspended). The Watcher starts again
+	// from the latest block, so OnTooManyBlocksBehind should re-validate any
+	// state which depends on the missed blocks. If it returns an error, the
+	// Watcher stops with that error.
+	OnTooManyBlocksBehind func(ctx context.Context) error
+	// LogFilters, if not nil, is called before logs are fetched and returns
+	// the filters which the logs must match. The Watcher sends one
+	// `eth_getLogs` request per filter and merges the results, which can
+	// shrink the number of logs fetched substantially if the filters are
+	// narrow. If LogFilters returns nil, all logs matching Topics are fetched.
+	LogFilters func() []LogFilter
 }
 
 // Watcher maintains a consistent representation of the latest X blocks (where X is enforced by the
@@ -97,24 +169,57 @@ type Watcher struct {
 	pollingInterval     time.Duration
 	withLogs            bool
 	topics              []common.Hash
+	maxBlockHistory     int
+	getLogsChunkSize    int
 	mu                  sync.RWMutex
 	syncToLatestBlockMu sync.Mutex
+	// limitsMu protects degradedMode and maxBlocksInGetLogsQuery, which can
+	// change while the Watcher is running.
+	limitsMu                sync.Mutex
+	degradedMode            bool
+	maxBlocksInGetLogsQuery int
+	onTooManyBlocksBehind   func(ctx context.Context) error
+	logFilters              func() []LogFilter
 }
 
 // New creates a new Watcher instance.
 func New(config Config) *Watcher {
+	maxBlocks := config.MaxBlocksInGetLogsQuery
+	if maxBlocks <= 0 {
+		maxBlocks = maxBlocksInGetLogsQuery
+	}
+	getLogsChunkSize := config.GetLogsRequestChunkSize
+	if getLogsChunkSize <= 0 {
+		getLogsChunkSize = getLogsRequestChunkSize
+	}
+	maxBlockHistory := config.MaxBlockHistory
+	if maxBlockHistory <= 0 {
+		maxBlockHistory = constants.MaxBlocksStoredInNonArchiveNode
+	}
 	return &Watcher{
-		pollingInterval: config.PollingInterval,
-		stack:           config.Stack,
-		client:          config.Client,
-		withLogs:        config.WithLogs,
-		topics:          config.Topics,
+		pollingInterval:         config.PollingInterval,
+		stack:                   config.Stack,
+		client:                  config.Client,
+		withLogs:                config.WithLogs,
+		topics:                  config.Topics,
+		maxBlockHistory:         maxBlockHistory,
+		getLogsChunkSize:        getLogsChunkSize,
+		degradedMode:            config.DegradedMode,
+		maxBlocksInGetLogsQuery: maxBlocks,
+		onTooManyBlocksBehind:   config.OnTooManyBlocksBehind,
+		logFilters:              config.LogFilters,
 	}
 }
 
+// MaxBlockHistory returns the number of recent blocks the Watcher can catch
+// up on after falling behind the latest block.
+func (w *Watcher) MaxBlockHistory() int {
+	return w.maxBlockHistory
+}
+
 // FastSyncToLatestBlock checks if the BlockWatcher is behind the latest block, and if so,
-// catches it back up. If less than 128 blocks passed, we are able to fetch all missing
-// block events and process them. If more than 128 blocks passed, we cannot catch up
+// catches it back up. If less than MaxBlockHistory (128 by default) blocks passed, we are able
+// to fetch all missing block events and process them. If more blocks passed, we cannot catch up
 // without an archive Ethereum node (see: http://bit.ly/2D11Hr6) so we instead clear
 // previously tracked blocks so BlockWatcher starts again from the latest block. This
 // function blocks until complete or the context is  cancelled.
@@ -126,6 +231,64 @@ func (w *Watcher) FastSyncToLatestBlock(ctx context.Context) (blocksElapsed int,
 	}
 	w.mu.Unlock()
 
+	return w.backfillToLatestBlock(ctx)
+}
+
+// Resync is like FastSyncToLatestBlock, but it can be called while the Watcher
+// is running. It is meant to be called when the Watcher may have fallen far
+// behind the latest block (e.g. after the process was suspended), since the
+// events of the missed blocks are fetched with batched `eth_getLogs` requests
+// instead of block by block. If MaxBlockHistory or more blocks have elapsed,
+// the Watcher starts again from the latest block and OnTooManyBlocksBehind is
+// called if it is set.
+func (w *Watcher) Resync(ctx context.Context) (blocksElapsed int, err error) {
+	w.syncToLatestBlockMu.Lock()
+	blocksElapsed, err = w.backfillToLatestBlock(ctx)
+	w.syncToLatestBlockMu.Unlock()
+	if err != nil {
+		return blocksElapsed, err
+	}
+	if blocksElapsed >= w.maxBlockHistory && w.onTooManyBlocksBehind != nil {
+		if err := w.onTooManyBlocksBehind(ctx); err != nil {
+			return blocksElapsed, err
+		}
+	}
+	return blocksElapsed, nil
+}
+
+// Reset drops all retained block headers and starts again from the latest
+// block without emitting any events. It is meant to recover a Watcher that is
+// stuck on a chain which is no longer canonical (e.g. after the Ethereum RPC
+// endpoint was switched to a different node). onReset is called with the
+// latest block header before any other block is processed, so that the caller
+// can replace any state derived from the dropped headers.
+func (w *Watcher) Reset(onReset func(latestHeader *miniheader.MiniHeader) error) (*miniheader.MiniHeader, error) {
+	w.syncToLatestBlockMu.Lock()
+	defer w.syncToLatestBlockMu.Unlock()
+
+	latestHeader, err := w.client.HeaderByNumber(nil)
+	if err != nil {
+		return nil, err
+	}
+	if onReset != nil {
+		if err := onReset(latestHeader); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.stack.Clear(); err != nil {
+		return nil, err
+	}
+	if err := w.stack.Push(latestHeader); err != nil {
+		return nil, err
+	}
+	if _, err := w.stack.Checkpoint(); err != nil {
+		return nil, err
+	}
+	return latestHeader, nil
+}
+
+// backfillToLatestBlock implements FastSyncToLatestBlock and Resync.
+func (w *Watcher) backfillToLatestBlock(ctx context.Context) (blocksElapsed int, err error) {
 	latestBlockProcessed, err := w.stack.Peek()
 	if err != nil {
 		return 0, err
@@ -144,7 +307,7 @@ func (w *Watcher) FastSyncToLatestBlock(ctx context.Context) (blocksElapsed int,
 	blocksElapsed = int(latestBlock.Number.Int64()) - latestBlockProcessedNumber
 	if blocksElapsed == 0 {
 		return blocksElapsed, nil
-	} else if blocksElapsed < constants.MaxBlocksStoredInNonArchiveNode {
+	} else if blocksElapsed < w.maxBlockHistory {
 		log.WithField("blocksElapsed", blocksElapsed).Info("Some blocks have elapsed since last boot. Backfilling block events (this can take a while)...")
 		events, err := w.getMissedEventsToBackfill(ctx, blocksElapsed, latestBlockProcessedNumber)
 		if err != nil {
@@ -206,6 +369,15 @@ func (w *Watcher) Watch(ctx context.Context) error {
 					ticker.Stop()
 					return err
 				}
+				if _, ok := err.(TooMayBlocksBehindError); ok && w.onTooManyBlocksBehind != nil {
+					// Start again from the latest block instead of stopping.
+					log.WithError(err).Warn("blockwatch.Watcher fell too many blocks behind; resyncing from the latest block")
+					if _, err := w.Resync(ctx); err != nil {
+						ticker.Stop()
+						return err
+					}
+					continue
+				}
 				if _, ok := err.(TooMayBlocksBehindError); ok {
 					// We've fallen too many blocks behind to sync to the latest block.
 					// We'd need to start again from the latest block but also require
@@ -238,6 +410,8 @@ func (w *Watcher) Subscribe(sink chan<- []*Event) event.Subscription {
 // SyncToLatestBlock syncs our local state of the chain to the latest block found via
 // Ethereum RPC
 func (w *Watcher) SyncToLatestBlock() error {
+	chaos.DelayBlock()
+
 	w.syncToLatestBlockMu.Lock()
 	defer w.syncToLatestBlockMu.Unlock()
 
@@ -272,7 +446,7 @@ func (w *Watcher) SyncToLatestBlock() error {
 		numBlocksToFetch = int(latestBlockNumber - lastStoredBlockNumber)
 	}
 
-	if numBlocksToFetch >= constants.MaxBlocksStoredInNonArchiveNode {
+	if numBlocksToFetch >= w.maxBlockHistory {
 		return TooMayBlocksBehindError{
 			blocksMissing: numBlocksToFetch,
 		}
@@ -370,7 +544,7 @@ func (w *Watcher) buildCanonicalChain(nextHeader *miniheader.MiniHeader, events
 		BlockHeader: latestHeader,
 	})
 
-	nextParentHeader, err := w.client.HeaderByHash(nextHeader.Parent)
+	nextParentHeader, err := w.getParentHeader(nextHeader)
 	if err != nil {
 		return events, err
 	}
@@ -394,21 +568,119 @@ func (w *Watcher) buildCanonicalChain(nextHeader *miniheader.MiniHeader, events
 	return events, nil
 }
 
+// getParentHeader fetches the parent of the given block header. In degraded mode, the parent
+// is fetched by number and compared to the expected hash, since `eth_getBlockByHash` might not
+// be supported.
+func (w *Watcher) getParentHeader(header *miniheader.MiniHeader) (*miniheader.MiniHeader, error) {
+	if !w.isDegraded() {
+		parentHeader, err := w.client.HeaderByHash(header.Parent)
+		if err == nil || !isUnsupportedRequestError(err) {
+			return parentHeader, err
+		}
+		w.enableDegradedMode(err)
+	}
+	parentNumber := big.NewInt(0).Sub(header.Number, big.NewInt(1))
+	parentHeader, err := w.client.HeaderByNumber(parentNumber)
+	if err != nil {
+		return nil, err
+	}
+	if parentHeader.Hash != header.Parent {
+		// The chain was re-organized again since header was fetched. The next
+		// sync will start over from the new latest block.
+		return nil, fmt.Errorf("parent of block #%d changed while it was being fetched", header.Number)
+	}
+	return parentHeader, nil
+}
+
 func (w *Watcher) addLogs(header *miniheader.MiniHeader) (*miniheader.MiniHeader, error) {
 	if !w.withLogs {
 		return header, nil
 	}
-	logs, err := w.client.FilterLogs(ethereum.FilterQuery{
-		BlockHash: &header.Hash,
-		Topics:    [][]common.Hash{w.topics},
+	if !w.isDegraded() {
+		logs, err := w.filterLogs(ethereum.FilterQuery{
+			BlockHash: &header.Hash,
+		})
+		if err == nil {
+			header.Logs = logs
+			return header, nil
+		}
+		if !isUnsupportedRequestError(err) {
+			return header, err
+		}
+		w.enableDegradedMode(err)
+	}
+	return w.addLogsByNumber(header)
+}
+
+// addLogsByNumber is like addLogs but filters the logs by block number instead of block hash.
+// Since a different block with the same number might have been mined in the meantime, it checks
+// that the logs (or, if there are none, the block at that number) still belong to header.
+func (w *Watcher) addLogsByNumber(header *miniheader.MiniHeader) (*miniheader.MiniHeader, error) {
+	logs, err := w.filterLogs(ethereum.FilterQuery{
+		FromBlock: header.Number,
+		ToBlock:   header.Number,
 	})
 	if err != nil {
 		return header, err
 	}
+	if len(logs) == 0 {
+		canonicalHeader, err := w.client.HeaderByNumber(header.Number)
+		if err != nil {
+			return header, err
+		}
+		if canonicalHeader.Hash != header.Hash {
+			return header, fmt.Errorf("block #%d changed while its logs were being fetched", header.Number)
+		}
+	}
+	for _, log := range logs {
+		if log.BlockHash != header.Hash {
+			return header, fmt.Errorf("block #%d changed while its logs were being fetched", header.Number)
+		}
+	}
 	header.Logs = logs
 	return header, nil
 }
 
+func (w *Watcher) isDegraded() bool {
+	w.limitsMu.Lock()
+	defer w.limitsMu.Unlock()
+	return w.degradedMode
+}
+
+// enableDegradedMode switches the Watcher to degraded mode after the Ethereum RPC endpoint
+// returned the given error for a request it doesn't support.
+func (w *Watcher) enableDegradedMode(reason error) {
+	w.limitsMu.Lock()
+	defer w.limitsMu.Unlock()
+	if w.degradedMode {
+		return
+	}
+	w.degradedMode = true
+	log.WithError(reason).Warn("Ethereum RPC endpoint does not support fetching blocks or logs by block hash; switching block watcher to degraded mode")
+}
+
+func (w *Watcher) getMaxBlocksInGetLogsQuery() int {
+	w.limitsMu.Lock()
+	defer w.limitsMu.Unlock()
+	return w.maxBlocksInGetLogsQuery
+}
+
+// reduceMaxBlocksInGetLogsQuery lowers the max number of blocks per `eth_getLogs` query after the
+// Ethereum RPC endpoint rejected a query spanning numBlocks blocks for exceeding its limits.
+func (w *Watcher) reduceMaxBlocksInGetLogsQuery(numBlocks int) {
+	w.limitsMu.Lock()
+	defer w.limitsMu.Unlock()
+	newMax := numBlocks / 2
+	if newMax < 1 {
+		newMax = 1
+	}
+	if newMax >= w.maxBlocksInGetLogsQuery {
+		return
+	}
+	w.maxBlocksInGetLogsQuery = newMax
+	log.WithField("maxBlocksInGetLogsQuery", newMax).Warn("Ethereum RPC endpoint rejected eth_getLogs query; reducing the number of blocks per query")
+}
+
 // getMissedEventsToBackfill finds missed events that might have occured while the Mesh node was
 // offline. It does this by comparing the last block stored with the latest block discoverable via RPC.
 // If the stored block is older then the latest block, it batch fetches the events for missing blocks,
@@ -482,7 +754,8 @@ type logRequestResult struct {
 	Err  error
 }
 
-// getLogsRequestChunkSize is the number of `eth_getLogs` JSON RPC to send concurrently in each batch fetch
+// getLogsRequestChunkSize is the default number of `eth_getLogs` JSON RPC to send concurrently in each
+// batch fetch
 const getLogsRequestChunkSize = 3
 
 // getLogsInBlockRange attempts to fetch all logs in the block range supplied. It implements a
@@ -491,16 +764,16 @@ const getLogsRequestChunkSize = 3
 // batch requests are not sent. Instead, it returns all the logs it found up until the error was
 // encountered, along with the block number after which no further logs were retrieved.
 func (w *Watcher) getLogsInBlockRange(ctx context.Context, from, to int) ([]types.Log, int) {
-	blockRanges := w.getSubBlockRanges(from, to, maxBlocksInGetLogsQuery)
+	blockRanges := w.getSubBlockRanges(from, to, w.getMaxBlocksInGetLogsQuery())
 
 	numChunks := 0
 	chunkChan := make(chan []*blockRange, 1000000)
 	for len(blockRanges) != 0 {
 		var chunk []*blockRange
-		if len(blockRanges) < getLogsRequestChunkSize {
+		if len(blockRanges) < w.getLogsChunkSize {
 			chunk = blockRanges[:len(blockRanges)]
 		} else {
-			chunk = blockRanges[:getLogsRequestChunkSize]
+			chunk = blockRanges[:w.getLogsChunkSize]
 		}
 		chunkChan <- chunk
 		blockRanges = blockRanges[len(chunk):]
@@ -640,20 +913,20 @@ func (w *Watcher) filterLogsRecurisively(from, to int, allLogs []types.Log) ([]t
 		"to":   to,
 	}).Trace("Fetching block logs")
 	numBlocks := to - from
-	topics := [][]common.Hash{}
-	if len(w.topics) > 0 {
-		topics = append(topics, w.topics)
-	}
-	logs, err := w.client.FilterLogs(ethereum.FilterQuery{
+	logs, err := w.filterLogs(ethereum.FilterQuery{
 		FromBlock: big.NewInt(int64(from)),
 		ToBlock:   big.NewInt(int64(to)),
-		Topics:    topics,
 	})
 	if err != nil {
-		// Infura caps the logs returned to 10,000 per request, if our request exceeds this limit, split it
-		// into two requests. Parity, Geth and Alchemy all have much higher limits (if any at all), so no need
-		// to expect any similar errors of this nature from them.
-		if err.Error() == infuraTooManyResultsErrMsg {
+		// Infura caps the logs returned to 10,000 per request and some other providers cap the number
+		// of blocks or the size of the response. If our request exceeds one of these limits, split it
+		// into two requests.
+		if isLogRangeLimitError(err) {
+			// Block range limits don't depend on the number of logs, so avoid sending
+			// queries which are too large in the future.
+			if err.Error() != infuraTooManyResultsErrMsg {
+				w.reduceMaxBlocksInGetLogsQuery(numBlocks + 1)
+			}
 			// HACK(fabio): Infura limits the returned results to 10,000 logs, BUT some single
 			// blocks contain more then 10,000 logs. This has supposedly been fixed but we keep
 			// this logic here just in case. It helps us avoid infinite recursion.
@@ -688,11 +961,88 @@ func (w *Watcher) filterLogsRecurisively(from, to int, allLogs []types.Log) ([]t
 	return allLogs, nil
 }
 
+// filterLogs fetches the logs in the block range or block of the given query
+// which match either Topics or, if it returns any, the filters returned by
+// LogFilters. In the latter case, the logs are sorted by block number and log
+// index.
+func (w *Watcher) filterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	var filters []LogFilter
+	if w.logFilters != nil {
+		filters = w.logFilters()
+	}
+	if filters == nil {
+		if len(w.topics) > 0 {
+			query.Topics = [][]common.Hash{w.topics}
+		}
+		return w.client.FilterLogs(query)
+	}
+
+	// The index of a log is its position within the block, so a log is
+	// identified by its block and index.
+	type logID struct {
+		blockHash common.Hash
+		index     uint
+	}
+	seen := map[logID]struct{}{}
+	// Each filter is fetched with a separate request, so a block could be
+	// replaced by a re-org in between. Mixing the logs of both blocks would
+	// produce a block which never existed.
+	blockHashes := map[uint64]common.Hash{}
+	allLogs := []types.Log{}
+	for _, filter := range filters {
+		query.Addresses = filter.Addresses
+		query.Topics = filter.Topics
+		logs, err := w.client.FilterLogs(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if blockHash, found := blockHashes[log.BlockNumber]; !found {
+				blockHashes[log.BlockNumber] = log.BlockHash
+			} else if blockHash != log.BlockHash {
+				return nil, fmt.Errorf("block #%d changed while its logs were being fetched", log.BlockNumber)
+			}
+			// A log can match more than one filter.
+			id := logID{blockHash: log.BlockHash, index: log.Index}
+			if _, found := seen[id]; found {
+				continue
+			}
+			seen[id] = struct{}{}
+			allLogs = append(allLogs, log)
+		}
+	}
+	sort.SliceStable(allLogs, func(i, j int) bool {
+		if allLogs[i].BlockNumber != allLogs[j].BlockNumber {
+			return allLogs[i].BlockNumber < allLogs[j].BlockNumber
+		}
+		return allLogs[i].Index < allLogs[j].Index
+	})
+	return allLogs, nil
+}
+
 // getAllRetainedBlocks returns the blocks retained in-memory by the Watcher.
 func (w *Watcher) getAllRetainedBlocks() ([]*miniheader.MiniHeader, error) {
 	return w.stack.PeekAll()
 }
 
+func isLogRangeLimitError(err error) bool {
+	return containsAny(err.Error(), logRangeLimitErrorMessages)
+}
+
+func isUnsupportedRequestError(err error) bool {
+	return containsAny(err.Error(), unsupportedRequestErrorMessages)
+}
+
+func containsAny(message string, substrings []string) bool {
+	message = strings.ToLower(message)
+	for _, substring := range substrings {
+		if strings.Contains(message, strings.ToLower(substring)) {
+			return true
+		}
+	}
+	return false
+}
+
 func isWarning(err error) bool {
 	message := err.Error()
 	for _, warningLevelErrorMessage := range warningLevelErrorMessages {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
 	"github.com/0xProject/0x-mesh/ethereum/simplestack"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
@@ -264,6 +265,88 @@ func TestFastSyncToLatestBlockMoreThanOrExactly128Missed(t *testing.T) {
 	require.Len(t, headers, 0)
 }
 
+func TestResyncMoreThanOrExactly128Missed(t *testing.T) {
+	// Fixture will return block 133 as the tip of the chain (128 blocks from block 5)
+	fakeClient, err := newFakeClient("testdata/fake_client_reset_fixture.json")
+	require.NoError(t, err)
+
+	// Add block number 5 as the last block seen by BlockWatcher
+	lastBlockSeen := &miniheader.MiniHeader{
+		Number:    big.NewInt(5),
+		Hash:      common.HexToHash("0x293b9ea024055a3e9eddbf9b9383dc7731744111894af6aa038594dc1b61f87f"),
+		Parent:    common.HexToHash("0x26b13ac89500f7fcdd141b7d1b30f3a82178431eca325d1cf10998f9d68ff5ba"),
+		Timestamp: time.Now(),
+	}
+
+	resyncConfig := config
+	resyncConfig.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	err = resyncConfig.Stack.Push(lastBlockSeen)
+	require.NoError(t, err)
+	resyncConfig.Client = fakeClient
+	numCalls := 0
+	resyncConfig.OnTooManyBlocksBehind = func(ctx context.Context) error {
+		numCalls++
+		return nil
+	}
+	watcher := New(resyncConfig)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Unlike FastSyncToLatestBlock, Resync can be called after the Watcher was
+	// started.
+	watcher.wasStartedOnce = true
+	blocksElapsed, err := watcher.Resync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 128, blocksElapsed)
+	assert.Equal(t, 1, numCalls, "OnTooManyBlocksBehind should be called once")
+
+	// Check that all blocks have been removed from BlockWatcher
+	headers, err := resyncConfig.Stack.PeekAll()
+	require.NoError(t, err)
+	require.Len(t, headers, 0)
+}
+
+func TestReset(t *testing.T) {
+	// Fixture will return block 133 as the tip of the chain
+	fakeClient, err := newFakeClient("testdata/fake_client_reset_fixture.json")
+	require.NoError(t, err)
+
+	// Add block number 5 as the last block seen by BlockWatcher
+	lastBlockSeen := &miniheader.MiniHeader{
+		Number:    big.NewInt(5),
+		Hash:      common.HexToHash("0x293b9ea024055a3e9eddbf9b9383dc7731744111894af6aa038594dc1b61f87f"),
+		Parent:    common.HexToHash("0x26b13ac89500f7fcdd141b7d1b30f3a82178431eca325d1cf10998f9d68ff5ba"),
+		Timestamp: time.Now(),
+	}
+
+	resetConfig := config
+	resetConfig.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	err = resetConfig.Stack.Push(lastBlockSeen)
+	require.NoError(t, err)
+	resetConfig.Client = fakeClient
+	watcher := New(resetConfig)
+	events := make(chan []*Event, 10)
+	sub := watcher.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	var resetHeader *miniheader.MiniHeader
+	latestHeader, err := watcher.Reset(func(header *miniheader.MiniHeader) error {
+		resetHeader = header
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(133), latestHeader.Number)
+	assert.Equal(t, latestHeader, resetHeader)
+
+	// Check that only the latest block is retained and that no events were
+	// emitted.
+	headers, err := resetConfig.Stack.PeekAll()
+	require.NoError(t, err)
+	require.Len(t, headers, 1)
+	assert.Equal(t, latestHeader, headers[0])
+	assert.Len(t, events, 0)
+}
+
 func TestFastSyncToLatestBlockNoneMissed(t *testing.T) {
 	// Fixture will return block 5 as the tip of the chain
 	fakeClient, err := newFakeClient("testdata/fake_client_basic_fixture.json")
@@ -534,6 +617,203 @@ func TestGetLogsInBlockRange(t *testing.T) {
 	}
 }
 
+func TestFilterLogsRecursivelyBlockRangeLimit(t *testing.T) {
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		"10-20": filterLogsResponse{
+			Err: errors.New("exceed maximum block range: 10"),
+		},
+		"10-15": filterLogsResponse{
+			Logs: []types.Log{
+				logStub,
+			},
+		},
+		"16-20": filterLogsResponse{
+			Logs: []types.Log{
+				logStub,
+			},
+		},
+	})
+	require.NoError(t, err)
+	config.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	config.Client = fakeLogClient
+	watcher := New(config)
+
+	logs, err := watcher.filterLogsRecurisively(10, 20, []types.Log{})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Log{logStub, logStub}, logs)
+
+	// The block range that was rejected contained 11 blocks, so subsequent
+	// queries should contain at most half as many.
+	assert.Equal(t, 5, watcher.getMaxBlocksInGetLogsQuery())
+}
+
+func TestGetParentHeaderDegradedMode(t *testing.T) {
+	headers := newTestHeaderChain(10, 3)
+	fakeLightClient := newFakeLightClient(headers, nil)
+	config.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	config.Client = fakeLightClient
+	watcher := New(config)
+	require.False(t, watcher.isDegraded())
+
+	// HeaderByHash is not supported, so the watcher should switch to degraded
+	// mode and fetch the parent by number instead.
+	parentHeader, err := watcher.getParentHeader(headers[2])
+	require.NoError(t, err)
+	assert.Equal(t, headers[1], parentHeader)
+	assert.True(t, watcher.isDegraded())
+
+	// If the block at the parent's number has a different hash, the chain
+	// was re-organized in the meantime.
+	orphanedHeader := &miniheader.MiniHeader{
+		Hash:   common.HexToHash("0xff"),
+		Parent: common.HexToHash("0xfe"),
+		Number: headers[2].Number,
+	}
+	_, err = watcher.getParentHeader(orphanedHeader)
+	require.Error(t, err)
+	assert.True(t, isWarning(err))
+}
+
+func TestAddLogsDegradedMode(t *testing.T) {
+	headers := newTestHeaderChain(10, 3)
+	blockLog := types.Log{
+		BlockNumber: headers[1].Number.Uint64(),
+		BlockHash:   headers[1].Hash,
+	}
+	fakeLightClient := newFakeLightClient(headers, []types.Log{blockLog})
+	degradedConfig := config
+	degradedConfig.WithLogs = true
+	degradedConfig.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	degradedConfig.Client = fakeLightClient
+	watcher := New(degradedConfig)
+
+	header, err := watcher.addLogs(headers[1])
+	require.NoError(t, err)
+	assert.Equal(t, []types.Log{blockLog}, header.Logs)
+	assert.True(t, watcher.isDegraded())
+
+	// Logs for a block which is no longer part of the canonical chain should
+	// result in an error.
+	orphanedHeader := &miniheader.MiniHeader{
+		Hash:   common.HexToHash("0xff"),
+		Parent: headers[0].Hash,
+		Number: headers[1].Number,
+	}
+	_, err = watcher.addLogs(orphanedHeader)
+	require.Error(t, err)
+
+	// The same goes for blocks without logs.
+	orphanedHeader = &miniheader.MiniHeader{
+		Hash:   common.HexToHash("0xff"),
+		Parent: headers[1].Hash,
+		Number: headers[2].Number,
+	}
+	_, err = watcher.addLogs(orphanedHeader)
+	require.Error(t, err)
+}
+
+// addressLogClient is a fake Client which returns the logs emitted by the
+// addresses in each FilterLogs query and records the queries.
+type addressLogClient struct {
+	fakeLogClient
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+}
+
+func (c *addressLogClient) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries = append(c.queries, q)
+	logs := []types.Log{}
+	for _, log := range c.logs {
+		if len(q.Addresses) == 0 {
+			logs = append(logs, log)
+			continue
+		}
+		for _, address := range q.Addresses {
+			if log.Address == address {
+				logs = append(logs, log)
+				break
+			}
+		}
+	}
+	return logs, nil
+}
+
+func TestFilterLogsWithLogFilters(t *testing.T) {
+	addressA := common.HexToAddress("0x0a")
+	addressB := common.HexToAddress("0x0b")
+	blockHash5 := common.HexToHash("0x05")
+	blockHash6 := common.HexToHash("0x06")
+	logA0 := types.Log{Address: addressA, BlockNumber: 6, BlockHash: blockHash6, Index: 0}
+	logA1 := types.Log{Address: addressA, BlockNumber: 5, BlockHash: blockHash5, Index: 1}
+	logB0 := types.Log{Address: addressB, BlockNumber: 5, BlockHash: blockHash5, Index: 0}
+	client := &addressLogClient{logs: []types.Log{logA0, logA1, logB0}}
+	topics := []common.Hash{common.HexToHash("0x01")}
+	var filters []LogFilter
+
+	filterConfig := config
+	filterConfig.Stack = simplestack.New(blockRetentionLimit, startMiniHeaders)
+	filterConfig.Client = client
+	filterConfig.Topics = topics
+	filterConfig.LogFilters = func() []LogFilter { return filters }
+	watcher := New(filterConfig)
+
+	// If LogFilters returns nil, all logs matching Topics are fetched.
+	logs, err := watcher.filterLogsRecurisively(5, 6, []types.Log{})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Log{logA0, logA1, logB0}, logs)
+	require.Len(t, client.queries, 1)
+	assert.Equal(t, [][]common.Hash{topics}, client.queries[0].Topics)
+	assert.Nil(t, client.queries[0].Addresses)
+
+	// Otherwise one query is sent per filter and the results are merged,
+	// deduplicated and sorted.
+	client.queries = nil
+	filters = []LogFilter{
+		{Addresses: []common.Address{addressA}, Topics: [][]common.Hash{topics}},
+		{Addresses: []common.Address{addressA, addressB}},
+	}
+	logs, err = watcher.filterLogsRecurisively(5, 6, []types.Log{})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Log{logB0, logA1, logA0}, logs)
+	require.Len(t, client.queries, 2)
+	for i, filter := range filters {
+		assert.Equal(t, filter.Addresses, client.queries[i].Addresses)
+		assert.Equal(t, filter.Topics, client.queries[i].Topics)
+		assert.Equal(t, big.NewInt(5), client.queries[i].FromBlock)
+		assert.Equal(t, big.NewInt(6), client.queries[i].ToBlock)
+	}
+
+	// If block 5 is replaced between the queries for two filters, the logs
+	// of both blocks must not be mixed.
+	reorgedLogB0 := logB0
+	reorgedLogB0.BlockHash = common.HexToHash("0x55")
+	client.logs = []types.Log{logA0, logA1, reorgedLogB0}
+	filters = []LogFilter{
+		{Addresses: []common.Address{addressA}},
+		{Addresses: []common.Address{addressB}},
+	}
+	_, err = watcher.filterLogsRecurisively(5, 6, []types.Log{})
+	assert.EqualError(t, err, "block #5 changed while its logs were being fetched")
+}
+
+// newTestHeaderChain returns numHeaders block headers, starting at block
+// number from, where each header is the parent of the next.
+func newTestHeaderChain(from int64, numHeaders int) []*miniheader.MiniHeader {
+	headers := []*miniheader.MiniHeader{}
+	parent := common.HexToHash("0x01")
+	for i := int64(0); i < int64(numHeaders); i++ {
+		header := &miniheader.MiniHeader{
+			Hash:   common.BigToHash(big.NewInt(from + i + 1000)),
+			Parent: parent,
+			Number: big.NewInt(from + i),
+			Logs:   []types.Log{},
+		}
+		headers = append(headers, header)
+		parent = header.Hash
+	}
+	return headers
+}
+
 func TestIsWarning(t *testing.T) {
 	errs := map[error]bool{
 		errors.New("not found"):     true,
@@ -0,0 +1,66 @@
+package blockwatch
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errFakeMethodNotFound = errors.New("the method eth_getBlockByHash does not exist/is not available")
+
+// fakeLightClient is a fake Client for testing degraded mode. Like some light clients and
+// log-only providers, it doesn't support fetching blocks by hash or filtering logs by block hash.
+type fakeLightClient struct {
+	numberToHeader map[int64]*miniheader.MiniHeader
+	numberToLogs   map[int64][]types.Log
+}
+
+// newFakeLightClient instantiates a fakeLightClient which returns the given headers and logs
+func newFakeLightClient(headers []*miniheader.MiniHeader, logs []types.Log) *fakeLightClient {
+	fc := &fakeLightClient{
+		numberToHeader: map[int64]*miniheader.MiniHeader{},
+		numberToLogs:   map[int64][]types.Log{},
+	}
+	for _, header := range headers {
+		fc.numberToHeader[header.Number.Int64()] = header
+	}
+	for _, log := range logs {
+		number := int64(log.BlockNumber)
+		fc.numberToLogs[number] = append(fc.numberToLogs[number], log)
+	}
+	return fc
+}
+
+// HeaderByNumber fetches a block header by its number
+func (fc *fakeLightClient) HeaderByNumber(number *big.Int) (*miniheader.MiniHeader, error) {
+	header, ok := fc.numberToHeader[number.Int64()]
+	if !ok {
+		return nil, fmt.Errorf("no header for block #%s", number)
+	}
+	// Return a copy so that the Watcher can't modify the stored header.
+	headerCopy := *header
+	return &headerCopy, nil
+}
+
+// HeaderByHash always returns an error since it isn't supported
+func (fc *fakeLightClient) HeaderByHash(hash common.Hash) (*miniheader.MiniHeader, error) {
+	return nil, errFakeMethodNotFound
+}
+
+// FilterLogs returns the logs that satisfy the supplied filter query. Filtering by block hash
+// isn't supported and topics are ignored.
+func (fc *fakeLightClient) FilterLogs(q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.BlockHash != nil {
+		return nil, errors.New("couldn't parse parameters: blockHash")
+	}
+	logs := []types.Log{}
+	for number := q.FromBlock.Int64(); number <= q.ToBlock.Int64(); number++ {
+		logs = append(logs, fc.numberToLogs[number]...)
+	}
+	return logs, nil
+}
@@ -0,0 +1,80 @@
+package ethereum
+
+import "time"
+
+// ChainParams contains default parameters which are tuned for a specific
+// chain. They can be overridden in the Mesh config.
+type ChainParams struct {
+	// Name is a human-readable name for the chain.
+	Name string
+	// BlockPollingInterval is the default interval at which Mesh checks for new
+	// blocks. It should be close to the average block time of the chain.
+	BlockPollingInterval time.Duration
+	// MaxReorgDepth is the depth of the deepest block re-org that Mesh is
+	// expected to handle. Mesh retains this many block headers so that it can
+	// detect re-orgs and revert their effects on orders.
+	MaxReorgDepth int
+}
+
+// defaultChainParams are used for chains which are not in knownChainParams.
+var defaultChainParams = ChainParams{
+	Name:                 "unknown",
+	BlockPollingInterval: 5 * time.Second,
+	MaxReorgDepth:        20,
+}
+
+var knownChainParams = map[int]ChainParams{
+	1: {
+		Name:                 "mainnet",
+		BlockPollingInterval: 5 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	3: {
+		Name:                 "ropsten",
+		BlockPollingInterval: 5 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	4: {
+		Name:                 "rinkeby",
+		BlockPollingInterval: 5 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	42: {
+		Name:                 "kovan",
+		BlockPollingInterval: 4 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	100: {
+		Name:                 "gnosis",
+		BlockPollingInterval: 5 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	137: {
+		Name:                 "polygon",
+		BlockPollingInterval: 2 * time.Second,
+		// Polygon regularly has re-orgs that are much deeper than those on
+		// mainnet, and its blocks are small, so it is cheap to retain more of
+		// them.
+		MaxReorgDepth: 128,
+	},
+	1337: {
+		Name:                 "ganache",
+		BlockPollingInterval: 5 * time.Second,
+		MaxReorgDepth:        20,
+	},
+	42161: {
+		Name:                 "arbitrum",
+		BlockPollingInterval: 1 * time.Second,
+		MaxReorgDepth:        20,
+	},
+}
+
+// ChainParamsForChainID returns the default parameters for the given chain ID.
+// If the chain is not known, it returns conservative defaults that are
+// appropriate for mainnet-like chains.
+func ChainParamsForChainID(chainID int) ChainParams {
+	if params, found := knownChainParams[chainID]; found {
+		return params
+	}
+	return defaultChainParams
+}
@@ -92,6 +92,11 @@ func NewContractAddressesForChainID(chainID int) (ContractAddresses, error) {
 	case 1337:
 		return ganacheAddresses(), nil
 	default:
+		if params, found := knownChainParams[chainID]; found {
+			// We have tuned defaults for this chain, but there are no built-in
+			// addresses for the 0x v3 contracts that Mesh depends on.
+			return ContractAddresses{}, fmt.Errorf("Cannot create contract addresses for chainID %d (%s): no built-in addresses are available, use CUSTOM_CONTRACT_ADDRESSES instead", chainID, params.Name)
+		}
 		return ContractAddresses{}, fmt.Errorf("Cannot create contract addresses for non-standard chainID")
 	}
 }
@@ -0,0 +1,258 @@
+package ethrpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// maxCachedBlockHashes is the number of recent block numbers for which the
+// CachingClient remembers the block hash. Requests for older blocks are not
+// cached.
+const maxCachedBlockHashes = 256
+
+// CacheConfig contains configuration options for a CachingClient.
+type CacheConfig struct {
+	// CallCacheSize is the maximum number of eth_call results (e.g. DevUtils
+	// order states or token decimals) to cache. If 0, eth_call results are
+	// not cached.
+	CallCacheSize int
+	// CodeCacheSize is the maximum number of eth_getCode results to cache. If
+	// 0, eth_getCode results are not cached.
+	CodeCacheSize int
+}
+
+// CacheStats contains statistics about a CachingClient.
+type CacheStats struct {
+	// Hits is the number of requests that were served from the cache.
+	Hits int64
+	// Misses is the number of cacheable requests that were sent to the
+	// underlying Client.
+	Misses int64
+	// Entries is the number of results that are currently cached.
+	Entries int
+}
+
+// CachingClient is a Client which caches the results of idempotent requests
+// made at a specific block number (eth_call and eth_getCode). Results are
+// keyed by the hash of the block, which the CachingClient learns from the
+// headers returned by HeaderByNumber and from the blocks passed to AddBlock
+// (e.g. by the block watcher). Requests for the latest block and for blocks
+// whose hash is not known are never cached. If a block number is found to
+// have a different hash or the block is passed to RemoveBlock (i.e. there was
+// a block re-org), the results for the old block are evicted.
+type CachingClient struct {
+	Client
+	callCache *lru.Cache
+	codeCache *lru.Cache
+	// blockHashesMut protects blockHashes.
+	blockHashesMut sync.RWMutex
+	// blockHashes maps recent block numbers to the hash of the canonical block
+	// with that number.
+	blockHashes map[uint64]common.Hash
+	hits        int64
+	misses      int64
+}
+
+// Ensure that CachingClient implements the Client interface.
+var _ Client = &CachingClient{}
+
+// NewCachingClient returns a new CachingClient which sends requests that are
+// not cached to the given client.
+func NewCachingClient(client Client, config CacheConfig) (*CachingClient, error) {
+	if config.CallCacheSize < 0 || config.CodeCacheSize < 0 {
+		return nil, errors.New("cache sizes cannot be negative")
+	}
+	c := &CachingClient{
+		Client:      client,
+		blockHashes: map[uint64]common.Hash{},
+	}
+	if config.CallCacheSize > 0 {
+		callCache, err := lru.New(config.CallCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		c.callCache = callCache
+	}
+	if config.CodeCacheSize > 0 {
+		codeCache, err := lru.New(config.CodeCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		c.codeCache = codeCache
+	}
+	return c, nil
+}
+
+// HeaderByNumber fetches a block header by its number and remembers its hash
+// so that requests at that block can be cached.
+func (c *CachingClient) HeaderByNumber(ctx context.Context, number *big.Int) (*miniheader.MiniHeader, error) {
+	header, err := c.Client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	c.observeBlock(header.Number, header.Hash)
+	return header, nil
+}
+
+// AddBlock records the hash of a block which was added to the canonical chain
+// so that requests at that block can be cached.
+func (c *CachingClient) AddBlock(header *miniheader.MiniHeader) {
+	c.observeBlock(header.Number, header.Hash)
+}
+
+// RemoveBlock forgets about a block which was removed from the canonical chain
+// and evicts the cached results for it.
+func (c *CachingClient) RemoveBlock(header *miniheader.MiniHeader) {
+	if header.Number == nil || !header.Number.IsUint64() {
+		return
+	}
+	c.blockHashesMut.Lock()
+	if blockHash, found := c.blockHashes[header.Number.Uint64()]; found && blockHash == header.Hash {
+		delete(c.blockHashes, header.Number.Uint64())
+	}
+	c.blockHashesMut.Unlock()
+	c.evictBlock(header.Hash)
+}
+
+// CallContract executes an Ethereum contract call. The result is cached if
+// blockNumber is a recent block whose hash is known.
+func (c *CachingClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.callCache == nil {
+		return c.Client.CallContract(ctx, call, blockNumber)
+	}
+	blockHash, ok := c.blockHash(blockNumber)
+	if !ok {
+		return c.Client.CallContract(ctx, call, blockNumber)
+	}
+	key := callCacheKey(blockHash, call)
+	if result, found := c.callCache.Get(key); found {
+		atomic.AddInt64(&c.hits, 1)
+		return result.([]byte), nil
+	}
+	atomic.AddInt64(&c.misses, 1)
+	result, err := c.Client.CallContract(ctx, call, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	c.callCache.Add(key, result)
+	return result, nil
+}
+
+// CodeAt returns the code of the given account. The result is cached if
+// blockNumber is a recent block whose hash is known.
+func (c *CachingClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if c.codeCache == nil {
+		return c.Client.CodeAt(ctx, contract, blockNumber)
+	}
+	blockHash, ok := c.blockHash(blockNumber)
+	if !ok {
+		return c.Client.CodeAt(ctx, contract, blockNumber)
+	}
+	key := blockHash.Hex() + "|" + contract.Hex()
+	if code, found := c.codeCache.Get(key); found {
+		atomic.AddInt64(&c.hits, 1)
+		return code.([]byte), nil
+	}
+	atomic.AddInt64(&c.misses, 1)
+	code, err := c.Client.CodeAt(ctx, contract, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	c.codeCache.Add(key, code)
+	return code, nil
+}
+
+// Stats returns statistics about the cache.
+func (c *CachingClient) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:   atomic.LoadInt64(&c.hits),
+		Misses: atomic.LoadInt64(&c.misses),
+	}
+	if c.callCache != nil {
+		stats.Entries += c.callCache.Len()
+	}
+	if c.codeCache != nil {
+		stats.Entries += c.codeCache.Len()
+	}
+	return stats
+}
+
+// blockHash returns the hash of the block with the given number if it is
+// known.
+func (c *CachingClient) blockHash(blockNumber *big.Int) (common.Hash, bool) {
+	if blockNumber == nil || !blockNumber.IsUint64() {
+		return common.Hash{}, false
+	}
+	c.blockHashesMut.RLock()
+	defer c.blockHashesMut.RUnlock()
+	blockHash, found := c.blockHashes[blockNumber.Uint64()]
+	return blockHash, found
+}
+
+// observeBlock records the hash of the canonical block with the given number.
+// If a different hash was recorded for the same number, the cached results
+// for the old block are evicted.
+func (c *CachingClient) observeBlock(blockNumber *big.Int, blockHash common.Hash) {
+	if blockNumber == nil || !blockNumber.IsUint64() {
+		return
+	}
+	number := blockNumber.Uint64()
+	c.blockHashesMut.Lock()
+	oldHash, found := c.blockHashes[number]
+	c.blockHashes[number] = blockHash
+	// Forget about blocks that are too old to be requested.
+	if number >= maxCachedBlockHashes {
+		for cachedNumber := range c.blockHashes {
+			if cachedNumber <= number-maxCachedBlockHashes {
+				delete(c.blockHashes, cachedNumber)
+			}
+		}
+	}
+	c.blockHashesMut.Unlock()
+
+	if found && oldHash != blockHash {
+		c.evictBlock(oldHash)
+	}
+}
+
+// evictBlock removes all cached results for the block with the given hash.
+func (c *CachingClient) evictBlock(blockHash common.Hash) {
+	prefix := blockHash.Hex()
+	for _, cache := range []*lru.Cache{c.callCache, c.codeCache} {
+		if cache == nil {
+			continue
+		}
+		for _, key := range cache.Keys() {
+			if strings.HasPrefix(key.(string), prefix) {
+				cache.Remove(key)
+			}
+		}
+	}
+}
+
+// callCacheKey returns the cache key for an eth_call at the given block. It
+// includes every field of call since they can all affect the result.
+func callCacheKey(blockHash common.Hash, call ethereum.CallMsg) string {
+	to := ""
+	if call.To != nil {
+		to = call.To.Hex()
+	}
+	return fmt.Sprintf("%s|%s|%s|%d|%s|%s|%x", blockHash.Hex(), call.From.Hex(), to, call.Gas, bigString(call.GasPrice), bigString(call.Value), call.Data)
+}
+
+func bigString(i *big.Int) string {
+	if i == nil {
+		return ""
+	}
+	return i.String()
+}
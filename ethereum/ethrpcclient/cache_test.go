@@ -0,0 +1,150 @@
+package ethrpcclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient is a Client which counts the number of calls to CallContract
+// and CodeAt. Only the methods used by CachingClient are implemented.
+type countingClient struct {
+	Client
+	headers   map[uint64]common.Hash
+	calls     int
+	codeCalls int
+}
+
+func (c *countingClient) HeaderByNumber(ctx context.Context, number *big.Int) (*miniheader.MiniHeader, error) {
+	return &miniheader.MiniHeader{
+		Number: number,
+		Hash:   c.headers[number.Uint64()],
+	}, nil
+}
+
+func (c *countingClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.calls++
+	return []byte{byte(c.calls)}, nil
+}
+
+func (c *countingClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.codeCalls++
+	return []byte{byte(c.codeCalls)}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{
+		headers: map[uint64]common.Hash{
+			5: common.HexToHash("0x5"),
+		},
+	}
+	client, err := NewCachingClient(inner, CacheConfig{CallCacheSize: 10, CodeCacheSize: 10})
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x1")
+	call := ethereum.CallMsg{To: &to, Data: []byte{0x31, 0x3c, 0xe5, 0x67}}
+	otherCall := ethereum.CallMsg{To: &to, Data: []byte{0x95, 0xd8, 0x9b, 0x41}}
+	blockNumber := big.NewInt(5)
+
+	// Requests are not cached until the hash of the block is known.
+	_, err = client.CallContract(ctx, call, blockNumber)
+	require.NoError(t, err)
+	_, err = client.CallContract(ctx, call, blockNumber)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, CacheStats{}, client.Stats())
+
+	_, err = client.HeaderByNumber(ctx, blockNumber)
+	require.NoError(t, err)
+	first, err := client.CallContract(ctx, call, blockNumber)
+	require.NoError(t, err)
+	second, err := client.CallContract(ctx, call, blockNumber)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	_, err = client.CallContract(ctx, otherCall, blockNumber)
+	require.NoError(t, err)
+	assert.Equal(t, 4, inner.calls)
+
+	// Requests for the latest block are never cached.
+	_, err = client.CallContract(ctx, call, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, inner.calls)
+
+	_, err = client.CodeAt(ctx, to, blockNumber)
+	require.NoError(t, err)
+	_, err = client.CodeAt(ctx, to, blockNumber)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.codeCalls)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 3, Entries: 3}, client.Stats())
+
+	// If the block is re-orged out, the results for the old block should be
+	// evicted.
+	inner.headers[5] = common.HexToHash("0x55")
+	_, err = client.HeaderByNumber(ctx, blockNumber)
+	require.NoError(t, err)
+	assert.Equal(t, 0, client.Stats().Entries)
+	third, err := client.CallContract(ctx, call, blockNumber)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third)
+	assert.Equal(t, 6, inner.calls)
+}
+
+func TestCachingClientAddAndRemoveBlock(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewCachingClient(&countingClient{}, CacheConfig{CallCacheSize: 10})
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x1")
+	call := ethereum.CallMsg{To: &to, Data: []byte{0x31, 0x3c, 0xe5, 0x67}}
+	header := &miniheader.MiniHeader{
+		Number: big.NewInt(5),
+		Hash:   common.HexToHash("0x5"),
+	}
+
+	// Blocks passed to AddBlock can be cached without fetching their header.
+	client.AddBlock(header)
+	_, err = client.CallContract(ctx, call, header.Number)
+	require.NoError(t, err)
+	_, err = client.CallContract(ctx, call, header.Number)
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, Entries: 1}, client.Stats())
+
+	// Removing the block evicts its results and stops caching requests at
+	// that block number until a new block is added.
+	client.RemoveBlock(header)
+	assert.Equal(t, 0, client.Stats().Entries)
+	_, found := client.blockHash(header.Number)
+	assert.False(t, found)
+}
+
+func TestCachingClientForgetsOldBlocks(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingClient{
+		headers: map[uint64]common.Hash{},
+	}
+	client, err := NewCachingClient(inner, CacheConfig{CallCacheSize: 10})
+	require.NoError(t, err)
+
+	for i := uint64(0); i < maxCachedBlockHashes*2; i++ {
+		inner.headers[i] = common.BigToHash(new(big.Int).SetUint64(i + 1))
+		_, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(i))
+		require.NoError(t, err)
+	}
+	assert.Len(t, client.blockHashes, maxCachedBlockHashes)
+	_, found := client.blockHash(big.NewInt(maxCachedBlockHashes - 1))
+	assert.False(t, found)
+	_, found = client.blockHash(big.NewInt(maxCachedBlockHashes*2 - 1))
+	assert.True(t, found)
+}
+
+func TestNewCachingClientValidatesConfig(t *testing.T) {
+	_, err := NewCachingClient(&countingClient{}, CacheConfig{CallCacheSize: -1})
+	assert.Error(t, err)
+}
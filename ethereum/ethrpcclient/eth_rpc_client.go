@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
 	"github.com/0xProject/0x-mesh/ethereum/ratelimit"
 	"github.com/ethereum/go-ethereum"
@@ -15,7 +16,9 @@ import (
 )
 
 // Client defines the methods needed to satisfy the subsdet of ETH JSON-RPC client
-// methods used by Mesh
+// methods used by Mesh. Custom implementations can be provided via
+// core.Config.EthereumClient. Implementations which don't enforce rate limits
+// should return 0 from GetRateLimitDroppedRequests.
 type Client interface {
 	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
 	HeaderByNumber(ctx context.Context, number *big.Int) (*miniheader.MiniHeader, error)
@@ -26,6 +29,9 @@ type Client interface {
 	GetRateLimitDroppedRequests() int64
 }
 
+// Ensure that client implements the Client interface.
+var _ Client = &client{}
+
 // client is a Client through which _all_ Ethereum JSON-RPC requests should be routed through. It
 // enforces a max requestTimeout and also rate-limits requests
 type client struct {
@@ -64,6 +70,9 @@ func (ec *client) CallContext(ctx context.Context, result interface{}, method st
 		// Context cancelled or deadline exceeded
 		return err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, ec.requestTimeout)
 	defer cancel()
@@ -79,6 +88,9 @@ func (ec *client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.He
 		// Context cancelled or deadline exceeded
 		return nil, err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, ec.requestTimeout)
 	defer cancel()
@@ -96,6 +108,9 @@ func (ec *client) HeaderByNumber(ctx context.Context, number *big.Int) (*minihea
 		// Context cancelled or deadline exceeded
 		return nil, err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return nil, err
+	}
 
 	header, err := ec.client.HeaderByNumber(ctx, number)
 	if err != nil {
@@ -119,6 +134,9 @@ func (ec *client) CodeAt(ctx context.Context, contract common.Address, blockNumb
 		// Context cancelled or deadline exceeded
 		return []byte{}, err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return []byte{}, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, ec.requestTimeout)
 	defer cancel()
@@ -133,6 +151,9 @@ func (ec *client) CallContract(ctx context.Context, call ethereum.CallMsg, block
 		// Context cancelled or deadline exceeded
 		return []byte{}, err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return []byte{}, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, ec.requestTimeout)
 	defer cancel()
@@ -147,6 +168,9 @@ func (ec *client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]typ
 		// Context cancelled or deadline exceeded
 		return nil, err
 	}
+	if err := chaos.FailEthRPCRequest(); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, ec.requestTimeout)
 	defer cancel()
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"golang.org/x/time/rate"
+)
+
+// perSecondLimiter is a RateLimiter which only limits the number of requests
+// per second. Unlike rateLimiter, it doesn't cap the number of requests per
+// 24 hours and doesn't store any state in the database.
+type perSecondLimiter struct {
+	limiter               *rate.Limiter
+	currentUTCCheckpoint  time.Time // Start of current UTC 24hr period
+	grantedInLast24hrsUTC int       // Number of granted requests issued in last 24hr UTC
+	aClock                clock.Clock
+	mu                    sync.Mutex
+}
+
+// NewPerSecond returns a new RateLimiter which allows up to
+// maxRequestsPerSecond requests per second, with bursts of up to
+// maxRequestsPerSecond/2 requests. It is used for secondary Ethereum RPC
+// endpoints which are rate-limited independently of the main one.
+func NewPerSecond(maxRequestsPerSecond float64, aClock clock.Clock) RateLimiter {
+	return &perSecondLimiter{
+		limiter:              rate.NewLimiter(rate.Limit(maxRequestsPerSecond), int(math.Max(1, maxRequestsPerSecond/2))),
+		currentUTCCheckpoint: GetUTCMidnightOfDate(aClock.Now()),
+		aClock:               aClock,
+	}
+}
+
+// Start is a no-op since perSecondLimiter doesn't need any background
+// processes. The number of granted requests is reset when the first request of
+// a new UTC day is granted instead.
+func (p *perSecondLimiter) Start(ctx context.Context, checkpointInterval time.Duration) error {
+	return nil
+}
+
+// Wait blocks until the perSecondLimiter allows for another request to be
+// sent. It returns an error if the deadline of the given context is before the
+// request would be granted.
+func (p *perSecondLimiter) Wait(ctx context.Context) error {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.resetIfNewUTCDay()
+	p.grantedInLast24hrsUTC++
+	p.mu.Unlock()
+	return nil
+}
+
+// resetIfNewUTCDay resets the number of requests granted and sets the current
+// UTC checkpoint if the UTC day time window has elapsed. It must be called
+// while holding p.mu.
+func (p *perSecondLimiter) resetIfNewUTCDay() {
+	currentUTCCheckpoint := GetUTCMidnightOfDate(p.aClock.Now())
+	if currentUTCCheckpoint.After(p.currentUTCCheckpoint) {
+		p.currentUTCCheckpoint = currentUTCCheckpoint
+		p.grantedInLast24hrsUTC = 0
+	}
+}
+
+func (p *perSecondLimiter) getGrantedInLast24hrsUTC() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resetIfNewUTCDay()
+	return p.grantedInLast24hrsUTC
+}
+
+func (p *perSecondLimiter) getCurrentUTCCheckpoint() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resetIfNewUTCDay()
+	return p.currentUTCCheckpoint
+}
@@ -213,6 +213,48 @@ func expectRequestsGranted(t *testing.T, rateLimiter RateLimiter, numRequests in
 	}
 }
 
+// TestPerSecondLimiter checks that requests are granted based on the per
+// second limit and that there is no 24 hour limit.
+func TestPerSecondLimiter(t *testing.T) {
+	const maxRequestsPerSecond = 10
+
+	rateLimiter := NewPerSecond(maxRequestsPerSecond, clock.New())
+	require.NoError(t, rateLimiter.Start(context.Background(), defaultCheckpointInterval))
+
+	// First maxRequestsPerSecond/2 should be granted pretty much immediately.
+	expectRequestsGranted(t, rateLimiter, int(maxRequestsPerSecond/2), 0, grantTimingTolerance)
+
+	// Subsequent requests should be granted at a rate of 1 per (1 second /
+	// maxRequestsPerSecond).
+	expectedDelay := time.Second / maxRequestsPerSecond
+	expectRequestsGranted(t, rateLimiter, int(maxRequestsPerSecond), expectedDelay-grantTimingTolerance, expectedDelay+grantTimingTolerance)
+
+	assert.Equal(t, int(maxRequestsPerSecond/2)+maxRequestsPerSecond, rateLimiter.getGrantedInLast24hrsUTC())
+}
+
+// TestPerSecondLimiterResetsAtUTCMidnight checks that the number of granted
+// requests is reset when the UTC day time window elapses.
+func TestPerSecondLimiterResetsAtUTCMidnight(t *testing.T) {
+	aClock := clock.NewMock()
+	startOfCurrentUTCDay := GetUTCMidnightOfDate(time.Now())
+	aClock.Set(startOfCurrentUTCDay.Add(23 * time.Hour))
+
+	rateLimiter := NewPerSecond(math.MaxFloat64, aClock)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, rateLimiter.Wait(context.Background()))
+	}
+	assert.Equal(t, 3, rateLimiter.getGrantedInLast24hrsUTC())
+	assert.Equal(t, startOfCurrentUTCDay, rateLimiter.getCurrentUTCCheckpoint())
+
+	aClock.Add(1 * time.Hour)
+	startOfNextUTCDay := startOfCurrentUTCDay.AddDate(0, 0, 1)
+	assert.Equal(t, 0, rateLimiter.getGrantedInLast24hrsUTC())
+	assert.Equal(t, startOfNextUTCDay, rateLimiter.getCurrentUTCCheckpoint())
+
+	require.NoError(t, rateLimiter.Wait(context.Background()))
+	assert.Equal(t, 1, rateLimiter.getGrantedInLast24hrsUTC())
+}
+
 func TestGetUTCMidnightOfDate(t *testing.T) {
 	testCases := []struct {
 		input    time.Time
@@ -4,8 +4,7 @@
 package main
 
 import (
-	"context"
-
+	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/rpc"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/plaid/go-envvar/envvar"
@@ -30,13 +29,11 @@ func main() {
 		log.WithError(err).Fatal("could not create client")
 	}
 
-	ctx := context.Background()
+	// The subscription is automatically re-established if the connection to
+	// the Mesh node is lost.
 	orderEventsChan := make(chan []*zeroex.OrderEvent, 8000)
-	clientSubscription, err := client.SubscribeToOrders(ctx, orderEventsChan)
-	if err != nil {
-		log.WithError(err).Fatal("Couldn't set up OrderStream subscription")
-	}
-	defer clientSubscription.Unsubscribe()
+	subscription := client.SubscribeToOrdersWithReconnect(orderEventsChan, types.SubscribeToOrdersOpts{})
+	defer subscription.Unsubscribe()
 
 	for {
 		select {
@@ -46,8 +43,8 @@ func main() {
 					"event": orderEvent,
 				}).Printf("received order event")
 			}
-		case err := <-clientSubscription.Err():
-			log.Fatal(err)
+		case err := <-subscription.Missed():
+			log.WithError(err).Warn("missed some order events while reconnecting (use GetOrders to resync)")
 		}
 	}
 }
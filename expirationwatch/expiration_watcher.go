@@ -1,59 +1,87 @@
 package expirationwatch
 
 import (
+	"math/bits"
+	"sort"
 	"sync"
 	"time"
 
-	"github.com/albrow/stringset"
-	"github.com/ocdogan/rbt"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// slotBits is the number of bits of the expiration time (in seconds) which
+	// are covered by each level of the timer wheel.
+	slotBits = 6
+	// slotsPerLevel is the number of slots in each level of the timer wheel.
+	slotsPerLevel = 1 << slotBits
+	// numLevels is the number of levels of the timer wheel. Together, the levels
+	// cover 2^36 seconds (more than 2000 years). Items which expire even later
+	// are kept in an overflow list.
+	numLevels = 6
+)
+
 // ExpiredItem represents an expired item returned from the Watcher
 type ExpiredItem struct {
 	ExpirationTimestamp time.Time
 	ID                  string
 }
 
-// Watcher watches the expiration of items
+type item struct {
+	expiration int64
+	id         string
+}
+
+type slot map[item]struct{}
+
+// Watcher watches the expiration of items. It is implemented as a hierarchical
+// timer wheel with a resolution of one second, so the cost of Add, Remove and
+// Prune doesn't depend on the number of items being watched, and calling Prune
+// frequently is cheap when no items have expired.
+//
+// Level 0 of the wheel has one slot for each of the next 64 seconds, level 1
+// one slot for each of the next 64 ranges of 64 seconds, and so on. Items are
+// moved to lower levels as the wheel advances, and expire once they reach
+// level 0 and their slot is reached.
 type Watcher struct {
 	expiredItems chan []ExpiredItem
-	rbTreeMu     sync.RWMutex
-	rbTree       *rbt.RbTree
+	mu           sync.Mutex
+	// now is the time (in seconds) up to which the wheel has advanced. All
+	// items in the wheel expire after now.
+	now    int64
+	levels [numLevels][slotsPerLevel]slot
+	// occupied has one bit set for each non-empty slot of each level.
+	occupied [numLevels]uint64
+	overflow slot
+	// overdue contains the items which expire at or before now. This happens
+	// when an item is added with an expiration time in the past or when a
+	// block re-org moved the timestamp passed to Prune backwards.
+	overdue slot
 }
 
 // New instantiates a new expiration watcher
 func New() *Watcher {
-	rbTree := rbt.NewRbTree()
 	return &Watcher{
 		expiredItems: make(chan []ExpiredItem, 10),
-		rbTree:       rbTree,
+		overflow:     slot{},
+		overdue:      slot{},
 	}
 }
 
 // Add adds a new item identified by an ID to the expiration watcher
 func (w *Watcher) Add(expirationTimestamp time.Time, id string) {
-	key := rbt.Int64Key(expirationTimestamp.Unix())
-	w.rbTreeMu.Lock()
-	defer w.rbTreeMu.Unlock()
-	value, ok := w.rbTree.Get(&key)
-	var ids stringset.Set
-	if !ok {
-		ids = stringset.New()
-	} else {
-		ids = value.(stringset.Set)
-	}
-	ids.Add(id)
-	w.rbTree.Insert(&key, ids)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.insert(item{expiration: expirationTimestamp.Unix(), id: id})
 }
 
 // Remove removes the item with a specified id from the expiration watcher
 func (w *Watcher) Remove(expirationTimestamp time.Time, id string) {
-	key := rbt.Int64Key(expirationTimestamp.Unix())
-	w.rbTreeMu.Lock()
-	defer w.rbTreeMu.Unlock()
-	value, ok := w.rbTree.Get(&key)
-	if !ok {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	it := item{expiration: expirationTimestamp.Unix(), id: id}
+	s, level, index := w.slotFor(it.expiration)
+	if _, found := s[it]; !found {
 		// Due to the asynchronous nature of the Watcher and OrderWatcher, there are
 		// race-conditions where we try to remove an item from the Watcher after it
 		// has already been removed.
@@ -61,43 +89,133 @@ func (w *Watcher) Remove(expirationTimestamp time.Time, id string) {
 			"id": id,
 		}).Trace("Attempted to remove item from Watcher that no longer exists")
 		return // Noop
-	} else {
-		ids := value.(stringset.Set)
-		ids.Remove(id)
-		if len(ids) == 0 {
-			w.rbTree.Delete(&key)
-		} else {
-			w.rbTree.Insert(&key, ids)
-		}
+	}
+	delete(s, it)
+	if level >= 0 && len(s) == 0 {
+		w.clearSlot(level, index)
 	}
 }
 
 // Prune checks for any expired items given a timestamp and removes any expired
-// items from the expiration watcher and returns them to the caller
+// items from the expiration watcher and returns them to the caller. The
+// expired items are ordered by expiration time.
 func (w *Watcher) Prune(timestamp time.Time) []ExpiredItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	target := timestamp.Unix()
+
 	pruned := []ExpiredItem{}
+	for it := range w.overdue {
+		if it.expiration <= target {
+			pruned = append(pruned, newExpiredItem(it))
+			delete(w.overdue, it)
+		}
+	}
+	sort.Slice(pruned, func(i, j int) bool {
+		return pruned[i].ExpirationTimestamp.Before(pruned[j].ExpirationTimestamp)
+	})
+
 	for {
-		w.rbTreeMu.RLock()
-		key, value := w.rbTree.Min()
-		w.rbTreeMu.RUnlock()
-		if key == nil {
+		next, level, ok := w.nextEvent()
+		if !ok || next > target {
 			break
 		}
-		expirationTimeSeconds := int64(*key.(*rbt.Int64Key))
-		expirationTime := time.Unix(expirationTimeSeconds, 0)
-		if timestamp.Before(expirationTime) {
-			break
+		w.now = next
+		var s slot
+		if level == numLevels {
+			// A new range of the top level was reached, so some of the items in
+			// the overflow list might belong in the wheel now.
+			s = w.overflow
+			w.overflow = slot{}
+		} else {
+			index := slotIndex(next, level)
+			s = w.levels[level][index]
+			w.clearSlot(level, index)
 		}
-		ids := value.(stringset.Set)
-		for id := range ids {
-			pruned = append(pruned, ExpiredItem{
-				ExpirationTimestamp: expirationTime,
-				ID:                  id,
-			})
+		for it := range s {
+			if it.expiration <= w.now {
+				pruned = append(pruned, newExpiredItem(it))
+			} else {
+				// Move the item to a lower level.
+				w.insert(it)
+			}
 		}
-		w.rbTreeMu.Lock()
-		w.rbTree.Delete(key)
-		w.rbTreeMu.Unlock()
+	}
+	if target > w.now {
+		w.now = target
 	}
 	return pruned
 }
+
+// insert adds it to the slot it belongs in given the current time of the
+// wheel.
+func (w *Watcher) insert(it item) {
+	s, level, index := w.slotFor(it.expiration)
+	if s == nil {
+		s = slot{}
+		w.levels[level][index] = s
+		w.occupied[level] |= 1 << uint(index)
+	}
+	s[it] = struct{}{}
+}
+
+// slotFor returns the slot that items with the given expiration time belong
+// in, along with its level and index. The level is -1 for the overdue and
+// overflow lists. The returned slot is nil if it is empty.
+func (w *Watcher) slotFor(expiration int64) (s slot, level int, index int) {
+	if expiration <= w.now {
+		return w.overdue, -1, 0
+	}
+	// Items belong in the highest level in which the digits of their expiration
+	// time and the current time differ.
+	for level = numLevels - 1; level >= 0; level-- {
+		if expiration>>uint(slotBits*level) != w.now>>uint(slotBits*level) {
+			break
+		}
+	}
+	if expiration>>uint(slotBits*numLevels) != w.now>>uint(slotBits*numLevels) {
+		return w.overflow, -1, 0
+	}
+	index = slotIndex(expiration, level)
+	return w.levels[level][index], level, index
+}
+
+func (w *Watcher) clearSlot(level int, index int) {
+	w.levels[level][index] = nil
+	w.occupied[level] &^= 1 << uint(index)
+}
+
+// nextEvent returns the next time at which the wheel needs to do something
+// (i.e. expire items or move them to a lower level) along with the level of
+// the corresponding slot. The level is numLevels if items need to be moved
+// from the overflow list into the wheel.
+func (w *Watcher) nextEvent() (next int64, level int, ok bool) {
+	// Every item in a lower level expires before the items in higher levels
+	// need to be moved, so the first non-empty level determines the next
+	// event.
+	for level = 0; level < numLevels; level++ {
+		if w.occupied[level] == 0 {
+			continue
+		}
+		index := bits.TrailingZeros64(w.occupied[level])
+		shift := uint(slotBits * (level + 1))
+		rangeStart := w.now >> shift << shift
+		return rangeStart + int64(index)<<uint(slotBits*level), level, true
+	}
+	if len(w.overflow) > 0 {
+		shift := uint(slotBits * numLevels)
+		return (w.now>>shift + 1) << shift, numLevels, true
+	}
+	return 0, 0, false
+}
+
+func slotIndex(expiration int64, level int) int {
+	return int(expiration>>uint(slotBits*level)) & (slotsPerLevel - 1)
+}
+
+func newExpiredItem(it item) ExpiredItem {
+	return ExpiredItem{
+		ExpirationTimestamp: time.Unix(it.expiration, 0),
+		ID:                  it.id,
+	}
+}
@@ -1,10 +1,14 @@
 package expirationwatch
 
 import (
+	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrunesExpiredItems(t *testing.T) {
@@ -135,3 +139,89 @@ func TestRemoveItemWhichSharesExpirationTimeWithOtherItems(t *testing.T) {
 	assert.Len(t, pruned, 1, "two expired items should get pruned")
 	assert.Equal(t, expiryEntryOne, pruned[0])
 }
+
+func TestPrunesItemsInHigherLevels(t *testing.T) {
+	watcher := New()
+
+	current := time.Now().Truncate(time.Second)
+	watcher.Prune(current)
+	expirations := []time.Time{
+		current.Add(30 * time.Second),
+		current.Add(2 * time.Hour),
+		current.Add(90 * 24 * time.Hour),
+		current.Add(5 * 365 * 24 * time.Hour),
+	}
+	for i, expiration := range expirations {
+		watcher.Add(expiration, fmt.Sprintf("item_%d", i))
+	}
+
+	for i, expiration := range expirations {
+		pruned := watcher.Prune(expiration.Add(-1 * time.Second))
+		assert.Len(t, pruned, 0, "item should not be pruned before it expires")
+		pruned = watcher.Prune(expiration)
+		require.Len(t, pruned, 1, "item should be pruned once it expires")
+		assert.Equal(t, ExpiredItem{ExpirationTimestamp: expiration, ID: fmt.Sprintf("item_%d", i)}, pruned[0])
+	}
+}
+
+const secondsPerYear = 365 * 24 * 60 * 60
+
+func TestPrunesItemsInOrderAfterLargeJump(t *testing.T) {
+	watcher := New()
+
+	current := time.Now().Truncate(time.Second)
+	watcher.Prune(current)
+	// Add the items in random order, with expiration times ranging from a few
+	// seconds to thousands of years in the future.
+	random := rand.New(rand.NewSource(1))
+	expected := []ExpiredItem{}
+	for i := 0; i < 1000; i++ {
+		expiration := time.Unix(current.Unix()+random.Int63n(4000*secondsPerYear), 0)
+		id := fmt.Sprintf("item_%d", i)
+		watcher.Add(expiration, id)
+		expected = append(expected, ExpiredItem{ExpirationTimestamp: expiration, ID: id})
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		return expected[i].ExpirationTimestamp.Before(expected[j].ExpirationTimestamp)
+	})
+
+	pruned := watcher.Prune(time.Unix(current.Unix()+5000*secondsPerYear, 0))
+	assert.Equal(t, expected, pruned)
+}
+
+func TestPrunesItemsAddedAfterTimestampMovedBackwards(t *testing.T) {
+	watcher := New()
+
+	current := time.Now().Truncate(time.Second)
+	watcher.Prune(current)
+
+	// The timestamp passed to Prune can move backwards after a block re-org.
+	expiryEntry := ExpiredItem{
+		ExpirationTimestamp: current.Add(-5 * time.Second),
+		ID:                  "0x8e209dda7e515025d0c34aa61a0d1156a631248a4318576a2ce0fb408d97385e",
+	}
+	watcher.Add(expiryEntry.ExpirationTimestamp, expiryEntry.ID)
+	pruned := watcher.Prune(current.Add(-10 * time.Second))
+	assert.Len(t, pruned, 0, "Doesn't prune unexpired item")
+
+	pruned = watcher.Prune(current.Add(-5 * time.Second))
+	require.Len(t, pruned, 1, "one expired item should get pruned")
+	assert.Equal(t, expiryEntry, pruned[0])
+}
+
+func TestRemoveItemInHigherLevel(t *testing.T) {
+	watcher := New()
+
+	current := time.Now().Truncate(time.Second)
+	watcher.Prune(current)
+	expiration := current.Add(10 * 24 * time.Hour)
+	watcher.Add(expiration, "item_0")
+	watcher.Add(expiration, "item_1")
+	// Move the items to a lower level before removing one of them.
+	watcher.Prune(expiration.Add(-1 * time.Minute))
+	watcher.Remove(expiration, "item_0")
+
+	pruned := watcher.Prune(expiration)
+	require.Len(t, pruned, 1, "one expired item should get pruned")
+	assert.Equal(t, "item_1", pruned[0].ID)
+}
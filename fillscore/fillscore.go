@@ -0,0 +1,160 @@
+// Package fillscore estimates how likely it is that an order can actually be
+// filled, based on the past behavior of its maker and on the age of the order.
+// It is intended to help takers rank orders beyond their price. Scores are
+// heuristics computed from the order events observed by this node since it
+// started; they are not persisted.
+package fillscore
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+const (
+	// fillRateWeight, balanceWeight and ageWeight are the weights of each
+	// component of the score. They must add up to 1.
+	fillRateWeight = 0.5
+	balanceWeight  = 0.3
+	ageWeight      = 0.2
+	// ageHalfLife is the age at which the age component of the score is 0.5.
+	// Orders which have been around for a long time without being filled are
+	// more likely to be stale.
+	ageHalfLife = 24 * time.Hour
+	// maxTrackedMakers is the maximum number of makers for which stats are
+	// kept. When it is reached, the least recently active maker is forgotten.
+	maxTrackedMakers = 100000
+	// maxTrackedOrders is the maximum number of orders for which the time they
+	// were first seen is kept. When it is reached, the least recently added
+	// order is forgotten.
+	maxTrackedOrders = 500000
+)
+
+// makerStats contains the events observed for the orders of a single maker.
+type makerStats struct {
+	// added is the number of orders which were added.
+	added int
+	// fills is the number of FILLED and FULLY_FILLED events.
+	fills int
+	// unfilled is the number of orders which were cancelled or expired.
+	unfilled int
+	// unfunded is the number of orders which became unfunded, i.e. the maker's
+	// balance or allowance dropped below what the order requires.
+	unfunded int
+}
+
+// Scorer computes fillability scores. It is safe for concurrent use.
+type Scorer struct {
+	mu sync.RWMutex
+	// makers maps a maker address to its *makerStats.
+	makers *simplelru.LRU
+	// firstSeen maps an order hash to the time.Time it was added.
+	firstSeen *simplelru.LRU
+	now       func() time.Time
+}
+
+// New creates and returns a new Scorer.
+func New() *Scorer {
+	return newScorer(maxTrackedMakers, maxTrackedOrders)
+}
+
+func newScorer(maxMakers, maxOrders int) *Scorer {
+	// simplelru.NewLRU only returns an error if size is <= 0, so we can
+	// safely ignore it.
+	makers, _ := simplelru.NewLRU(maxMakers, nil)
+	firstSeen, _ := simplelru.NewLRU(maxOrders, nil)
+	return &Scorer{
+		makers:    makers,
+		firstSeen: firstSeen,
+		now:       time.Now,
+	}
+}
+
+// HandleOrderEvents updates the statistics used to compute scores.
+func (s *Scorer) HandleOrderEvents(events []*zeroex.OrderEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if event.SignedOrder == nil {
+			continue
+		}
+		stats := s.statsForMaker(event.SignedOrder.MakerAddress)
+		switch event.EndState {
+		case zeroex.ESOrderAdded:
+			stats.added++
+			if !s.firstSeen.Contains(event.OrderHash) {
+				s.firstSeen.Add(event.OrderHash, s.now())
+			}
+		case zeroex.ESOrderFilled:
+			stats.fills++
+		case zeroex.ESOrderFullyFilled:
+			stats.fills++
+			s.firstSeen.Remove(event.OrderHash)
+		case zeroex.ESOrderCancelled, zeroex.ESOrderExpired:
+			stats.unfilled++
+			s.firstSeen.Remove(event.OrderHash)
+		case zeroex.ESOrderFillReverted:
+			// The fill was undone by a block re-org.
+			if stats.fills > 0 {
+				stats.fills--
+			}
+		case zeroex.ESOrderCancelReverted:
+			if stats.unfilled > 0 {
+				stats.unfilled--
+			}
+		case zeroex.ESOrderBecameUnfunded:
+			stats.unfunded++
+			s.firstSeen.Remove(event.OrderHash)
+		case zeroex.ESStoppedWatching:
+			s.firstSeen.Remove(event.OrderHash)
+		}
+	}
+}
+
+// statsForMaker returns the stats for the given maker, creating them if needed
+// and marking the maker as recently active. s.mu must be held for writing.
+func (s *Scorer) statsForMaker(maker common.Address) *makerStats {
+	if stats, found := s.makers.Get(maker); found {
+		return stats.(*makerStats)
+	}
+	stats := &makerStats{}
+	s.makers.Add(maker, stats)
+	return stats
+}
+
+// Score returns a fillability score between 0 and 1 for the given order. The
+// score is a weighted average of:
+//
+//   - the maker's past fill rate (fill events relative to cancelled and
+//     expired orders),
+//   - the maker's balance history (how many of their orders became
+//     unfunded),
+//   - the age of the order, which decays with a half-life of 24 hours.
+//
+// Makers without any history get a neutral fill rate of 0.5. If the order has
+// not been seen being added (e.g. because it was stored before the node
+// started) it is treated as brand new. Score does not modify the Scorer.
+func (s *Scorer) Score(orderHash common.Hash, signedOrder *zeroex.SignedOrder) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats := &makerStats{}
+	// Peek does not update recency, so it is safe to call with a read lock.
+	if value, found := s.makers.Peek(signedOrder.MakerAddress); found {
+		stats = value.(*makerStats)
+	}
+	// Laplace smoothing gives makers without history a neutral fill rate.
+	fillRate := float64(stats.fills+1) / float64(stats.fills+stats.unfilled+2)
+	balanceScore := float64(stats.added+1) / float64(stats.added+stats.unfunded+1)
+
+	var age time.Duration
+	if firstSeen, found := s.firstSeen.Peek(orderHash); found {
+		age = s.now().Sub(firstSeen.(time.Time))
+	}
+	ageScore := math.Pow(0.5, age.Hours()/ageHalfLife.Hours())
+
+	return fillRateWeight*fillRate + balanceWeight*balanceScore + ageWeight*ageScore
+}
@@ -0,0 +1,77 @@
+package fillscore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	scorer := New()
+	scorer.now = func() time.Time { return now }
+
+	goodMaker := &zeroex.SignedOrder{Order: zeroex.Order{MakerAddress: common.HexToAddress("0x1")}}
+	badMaker := &zeroex.SignedOrder{Order: zeroex.Order{MakerAddress: common.HexToAddress("0x2")}}
+	newMaker := &zeroex.SignedOrder{Order: zeroex.Order{MakerAddress: common.HexToAddress("0x3")}}
+	hashA := common.HexToHash("0xa")
+	hashB := common.HexToHash("0xb")
+	hashC := common.HexToHash("0xc")
+
+	scorer.HandleOrderEvents([]*zeroex.OrderEvent{
+		{OrderHash: hashA, SignedOrder: goodMaker, EndState: zeroex.ESOrderAdded},
+		{OrderHash: hashA, SignedOrder: goodMaker, EndState: zeroex.ESOrderFilled},
+		{OrderHash: hashA, SignedOrder: goodMaker, EndState: zeroex.ESOrderFilled},
+		{OrderHash: hashB, SignedOrder: badMaker, EndState: zeroex.ESOrderAdded},
+		{OrderHash: hashB, SignedOrder: badMaker, EndState: zeroex.ESOrderBecameUnfunded},
+		{OrderHash: hashB, SignedOrder: badMaker, EndState: zeroex.ESOrderCancelled},
+	})
+
+	goodScore := scorer.Score(hashA, goodMaker)
+	badScore := scorer.Score(hashB, badMaker)
+	newScore := scorer.Score(hashC, newMaker)
+	assert.True(t, goodScore > newScore, "good maker (%f) should score higher than new maker (%f)", goodScore, newScore)
+	assert.True(t, newScore > badScore, "new maker (%f) should score higher than bad maker (%f)", newScore, badScore)
+	for _, score := range []float64{goodScore, badScore, newScore} {
+		assert.True(t, score >= 0 && score <= 1, "score out of range: %f", score)
+	}
+	// A new maker with a brand new order has a neutral fill rate and full
+	// balance and age scores.
+	assert.InDelta(t, 0.5*0.5+0.3+0.2, newScore, 0.0001)
+
+	// Orders which were never seen being added are treated as brand new and
+	// scoring them does not start tracking them.
+	now = now.Add(ageHalfLife)
+	assert.InDelta(t, newScore, scorer.Score(hashC, newMaker), 0.0001)
+	assert.False(t, scorer.firstSeen.Contains(hashC))
+
+	// Scores decay as orders get older.
+	scorer.HandleOrderEvents([]*zeroex.OrderEvent{
+		{OrderHash: hashC, SignedOrder: newMaker, EndState: zeroex.ESOrderAdded},
+	})
+	now = now.Add(ageHalfLife)
+	assert.InDelta(t, newScore-0.1, scorer.Score(hashC, newMaker), 0.0001)
+}
+
+func TestScorerBounds(t *testing.T) {
+	scorer := newScorer(2, 2)
+	var events []*zeroex.OrderEvent
+	for i := int64(1); i <= 3; i++ {
+		events = append(events, &zeroex.OrderEvent{
+			OrderHash:   common.BigToHash(big.NewInt(i)),
+			SignedOrder: &zeroex.SignedOrder{Order: zeroex.Order{MakerAddress: common.BigToAddress(big.NewInt(i))}},
+			EndState:    zeroex.ESOrderAdded,
+		})
+	}
+	scorer.HandleOrderEvents(events)
+
+	assert.Equal(t, 2, scorer.makers.Len())
+	assert.Equal(t, 2, scorer.firstSeen.Len())
+	// The least recently active maker and order are forgotten first.
+	assert.False(t, scorer.makers.Contains(common.BigToAddress(big.NewInt(1))))
+	assert.False(t, scorer.firstSeen.Contains(common.BigToHash(big.NewInt(1))))
+}
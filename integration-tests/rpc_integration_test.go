@@ -97,9 +97,9 @@ func TestHTTPGetOrders(t *testing.T) {
 }
 
 // TODO(jalextowle): Since the uuid creation process is inherently random, we
-//                   can't meaningfully sanity check the returnedSnapshotID in
-//                   this test. Unit testing should be implemented to verify that
-//                   this logic is correct, if necessary.
+// can't meaningfully sanity check the returnedSnapshotID in this test. Unit
+// testing should be implemented to verify that this logic is correct, if
+// necessary.
 func runGetOrdersTest(t *testing.T, rpcEndpointPrefix, rpcServerType string, rpcPort int) {
 	teardownSubTest := setupSubTest(t)
 	defer teardownSubTest(t)
@@ -328,6 +328,61 @@ func TestOrdersSubscription(t *testing.T) {
 	assert.Equal(t, []*zeroex.ContractEvent{}, orderEvent.ContractEvents)
 }
 
+func TestOrdersSubscriptionWithReconnect(t *testing.T) {
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	removeOldFiles(t, ctx)
+	buildStandaloneForTests(t, ctx)
+
+	// Start a standalone node with a wait group that is completed when the goroutine completes.
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	logMessages := make(chan string, 1024)
+	count := int(atomic.AddInt32(&nodeCount, 1))
+	go func() {
+		defer wg.Done()
+		startStandaloneNode(t, ctx, count, "", logMessages)
+	}()
+
+	// Wait for the rpc server to start and then start the rpc client.
+	_, err := waitForLogSubstring(ctx, logMessages, "started WS RPC server")
+	require.NoError(t, err, "WS RPC server didn't start")
+	client, err := rpc.NewClient(standaloneWSRPCEndpointPrefix + strconv.Itoa(wsRPCPort+count))
+	require.NoError(t, err)
+
+	orderEventChan := make(chan []*zeroex.OrderEvent, 1)
+	subscription := client.SubscribeToOrdersWithReconnect(orderEventChan, types.SubscribeToOrdersOpts{})
+	defer subscription.Unsubscribe()
+
+	// Wait for the subscription to be established before adding an order,
+	// since SubscribeToOrdersWithReconnect subscribes in the background.
+	_, err = waitForLogSubstring(ctx, logMessages, "received order event subscription request via RPC")
+	require.NoError(t, err, "subscription was not established")
+
+	signedTestOrder := scenario.NewSignedTestOrder(t, orderopts.SetupMakerState(true))
+	// See TestOrdersSubscription for why this is needed.
+	time.Sleep(500 * time.Millisecond)
+	expectedOrderHash, err := signedTestOrder.ComputeOrderHash()
+	require.NoError(t, err, "could not compute order hash for standalone order")
+	_, err = client.AddOrders([]*zeroex.SignedOrder{signedTestOrder})
+	require.NoError(t, err)
+
+	select {
+	case orderEvents := <-orderEventChan:
+		require.Len(t, orderEvents, 1)
+		assert.Equal(t, expectedOrderHash, orderEvents[0].OrderHash)
+		assert.Equal(t, zeroex.ESOrderAdded, orderEvents[0].EndState)
+		assert.NotEqual(t, uint64(0), orderEvents[0].SequenceNumber)
+		assert.NotEqual(t, "", orderEvents[0].Epoch)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for order event")
+	}
+}
+
 func TestHeartbeatSubscription(t *testing.T) {
 	teardownSubTest := setupSubTest(t)
 	defer teardownSubTest(t)
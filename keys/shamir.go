@@ -0,0 +1,191 @@
+package keys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// maxShares is the maximum number of shares a secret can be split into. Each
+// share is identified by a distinct non-zero x-coordinate in GF(2^8).
+const maxShares = 255
+
+// SplitSecret splits secret into numShares shares using Shamir's Secret Sharing
+// such that any threshold of them can be combined with CombineShares to recover
+// the secret, while fewer than threshold shares reveal nothing about it. Each
+// share is one byte longer than the secret; the last byte is the x-coordinate
+// which identifies the share.
+func SplitSecret(secret []byte, numShares, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+	if threshold < 2 {
+		return nil, errors.New("threshold must be at least 2")
+	}
+	if numShares < threshold {
+		return nil, errors.New("number of shares cannot be less than the threshold")
+	}
+	if numShares > maxShares {
+		return nil, fmt.Errorf("number of shares cannot be greater than %d", maxShares)
+	}
+
+	shares := make([][]byte, numShares)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+	// For each byte of the secret, generate a random polynomial of degree
+	// threshold-1 whose constant term is that byte and evaluate it at the
+	// x-coordinate of each share.
+	coefficients := make([]byte, threshold)
+	for i, secretByte := range secret {
+		coefficients[0] = secretByte
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share[i] = evaluatePolynomial(coefficients, share[len(secret)])
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares recovers a secret from shares created by SplitSecret. If fewer
+// shares than the threshold are given, or the shares belong to different
+// secrets, the result is garbage rather than an error. Callers should verify
+// the recovered secret if possible.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errors.New("at least 2 shares are required")
+	}
+	shareLen := len(shares[0])
+	if shareLen < 2 {
+		return nil, errors.New("shares must be at least 2 bytes long")
+	}
+	xs := make([]byte, len(shares))
+	seen := map[byte]struct{}{}
+	for i, share := range shares {
+		if len(share) != shareLen {
+			return nil, errors.New("all shares must be the same length")
+		}
+		x := share[shareLen-1]
+		if x == 0 {
+			return nil, errors.New("invalid share: x-coordinate cannot be 0")
+		}
+		if _, found := seen[x]; found {
+			return nil, errors.New("duplicate share")
+		}
+		seen[x] = struct{}{}
+		xs[i] = x
+	}
+
+	// Use Lagrange interpolation to evaluate the polynomial for each byte of
+	// the secret at x = 0.
+	secret := make([]byte, shareLen-1)
+	for i := range secret {
+		var result byte
+		for j, share := range shares {
+			basis := byte(1)
+			for k := range shares {
+				if k == j {
+					continue
+				}
+				// In GF(2^8) subtraction is the same as addition (xor).
+				basis = gfMul(basis, gfDiv(xs[k], xs[k]^xs[j]))
+			}
+			result ^= gfMul(share[i], basis)
+		}
+		secret[i] = result
+	}
+	return secret, nil
+}
+
+// SplitPrivateKey splits the given private key into numShares hex-encoded
+// shares, any threshold of which can be combined with PrivateKeyFromShares to
+// recover the key.
+func SplitPrivateKey(privKey p2pcrypto.PrivKey, numShares, threshold int) ([]string, error) {
+	keyBytes, err := p2pcrypto.MarshalPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := SplitSecret(keyBytes, numShares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	encodedShares := make([]string, len(shares))
+	for i, share := range shares {
+		encodedShares[i] = hex.EncodeToString(share)
+	}
+	return encodedShares, nil
+}
+
+// DecodeShare decodes a hex-encoded share created by SplitPrivateKey.
+func DecodeShare(encodedShare string) ([]byte, error) {
+	share, err := hex.DecodeString(encodedShare)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share: %s", err.Error())
+	}
+	if len(share) < 2 {
+		return nil, errors.New("invalid share: too short")
+	}
+	return share, nil
+}
+
+// PrivateKeyFromShares recovers a private key from hex-encoded shares created
+// by SplitPrivateKey.
+func PrivateKeyFromShares(encodedShares []string) (p2pcrypto.PrivKey, error) {
+	shares := make([][]byte, len(encodedShares))
+	for i, encodedShare := range encodedShares {
+		share, err := DecodeShare(encodedShare)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = share
+	}
+	keyBytes, err := CombineShares(shares)
+	if err != nil {
+		return nil, err
+	}
+	return p2pcrypto.UnmarshalPrivateKey(keyBytes)
+}
+
+// evaluatePolynomial evaluates the polynomial with the given coefficients
+// (lowest degree first) at x using Horner's method.
+func evaluatePolynomial(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = gfMul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// gfMul multiplies a and b in GF(2^8) using the AES reducing polynomial
+// x^8 + x^4 + x^3 + x + 1.
+func gfMul(a, b byte) byte {
+	var result byte
+	for b > 0 {
+		if b&1 == 1 {
+			result ^= a
+		}
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return result
+}
+
+// gfDiv divides a by b in GF(2^8). b must not be 0.
+func gfDiv(a, b byte) byte {
+	// The multiplicative inverse of b is b^254.
+	inverse := byte(1)
+	for i := 0; i < 254; i++ {
+		inverse = gfMul(inverse, b)
+	}
+	return gfMul(a, inverse)
+}
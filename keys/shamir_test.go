@@ -0,0 +1,61 @@
+package keys
+
+import (
+	"crypto/rand"
+	"testing"
+
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAndCombineShares(t *testing.T) {
+	secret := []byte("the quick brown fox jumps over the lazy dog")
+	shares, err := SplitSecret(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	// Any 3 shares should recover the secret.
+	for _, indexes := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		subset := [][]byte{}
+		for _, i := range indexes {
+			subset = append(subset, shares[i])
+		}
+		actual, err := CombineShares(subset)
+		require.NoError(t, err)
+		assert.Equal(t, secret, actual, "shares: %v", indexes)
+	}
+
+	// Fewer than 3 shares should not recover the secret.
+	actual, err := CombineShares(shares[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, actual)
+}
+
+func TestSplitSecretInvalidArgs(t *testing.T) {
+	_, err := SplitSecret([]byte{}, 3, 2)
+	assert.Error(t, err)
+	_, err = SplitSecret([]byte{1}, 3, 1)
+	assert.Error(t, err)
+	_, err = SplitSecret([]byte{1}, 2, 3)
+	assert.Error(t, err)
+	_, err = SplitSecret([]byte{1}, 256, 3)
+	assert.Error(t, err)
+}
+
+func TestCombineSharesDuplicate(t *testing.T) {
+	shares, err := SplitSecret([]byte{1, 2, 3}, 3, 2)
+	require.NoError(t, err)
+	_, err = CombineShares([][]byte{shares[0], shares[0]})
+	assert.Error(t, err)
+}
+
+func TestSplitPrivateKey(t *testing.T) {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	shares, err := SplitPrivateKey(privKey, 3, 2)
+	require.NoError(t, err)
+	actual, err := PrivateKeyFromShares([]string{shares[2], shares[0]})
+	require.NoError(t, err)
+	assert.True(t, privKey.Equals(actual))
+}
@@ -0,0 +1,195 @@
+package loghooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// fluentdBufferSize is the maximum number of log entries that can be queued
+	// for sending to Fluentd. If the queue is full (e.g. because the Fluentd
+	// endpoint is unreachable), new log entries are dropped rather than
+	// blocking the logger.
+	fluentdBufferSize = 4096
+	// fluentdDialTimeout is the timeout for connecting to Fluentd.
+	fluentdDialTimeout = 5 * time.Second
+	// fluentdWriteTimeout is the timeout for sending a single message.
+	fluentdWriteTimeout = 5 * time.Second
+	// fluentdMaxRetryDelay is the maximum amount of time to wait before trying
+	// to reconnect to Fluentd.
+	fluentdMaxRetryDelay = 30 * time.Second
+)
+
+// FluentdHook is a logger hook that sends all logs to a Fluentd or Fluent Bit
+// endpoint using the forward protocol
+// (https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1).
+// Messages are sent asynchronously from a background goroutine so that a slow
+// or unreachable endpoint never blocks logging.
+type FluentdHook struct {
+	addr      string
+	tag       string
+	formatter *log.JSONFormatter
+	messages  chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	dropped   uint64
+}
+
+// NewFluentdHook creates and returns a new FluentdHook which sends logs to the
+// forward input listening on the given TCP address (e.g. "localhost:24224")
+// with the given tag.
+func NewFluentdHook(addr string, tag string) *FluentdHook {
+	h := &FluentdHook{
+		addr:      addr,
+		tag:       tag,
+		formatter: &log.JSONFormatter{},
+		messages:  make(chan []byte, fluentdBufferSize),
+		done:      make(chan struct{}),
+	}
+	h.wg.Add(1)
+	go h.sendLoop()
+	return h
+}
+
+// Ensure that FluentdHook implements log.Hook.
+var _ log.Hook = &FluentdHook{}
+
+func (h *FluentdHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *FluentdHook) Fire(entry *log.Entry) error {
+	message, err := h.encode(entry)
+	if err != nil {
+		return err
+	}
+	select {
+	case h.messages <- message:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of log entries that were dropped because the
+// send queue was full.
+func (h *FluentdHook) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close stops the background goroutine after making a best effort attempt to
+// send any queued log entries.
+func (h *FluentdHook) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+	h.wg.Wait()
+	return nil
+}
+
+// encode encodes the entry as a forward protocol message in "Message Mode":
+// [tag, time, record]. The record has the same fields as the JSON logs that
+// Mesh writes to stdout.
+func (h *FluentdHook) encode(entry *log.Entry) ([]byte, error) {
+	formatted, err := h.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(formatted, &record); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	message := []interface{}{h.tag, entry.Time.Unix(), record}
+	if err := writeMsgpack(buf, message); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *FluentdHook) sendLoop() {
+	defer h.wg.Done()
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}()
+	retryDelay := time.Second
+	for {
+		var message []byte
+		select {
+		case message = <-h.messages:
+		case <-h.done:
+			h.flush(conn)
+			return
+		}
+		for {
+			if conn == nil {
+				var err error
+				conn, err = net.DialTimeout("tcp", h.addr, fluentdDialTimeout)
+				if err != nil {
+					conn = nil
+					select {
+					case <-time.After(retryDelay):
+					case <-h.done:
+						return
+					}
+					retryDelay *= 2
+					if retryDelay > fluentdMaxRetryDelay {
+						retryDelay = fluentdMaxRetryDelay
+					}
+					continue
+				}
+				retryDelay = time.Second
+			}
+			if err := writeFluentdMessage(conn, message); err != nil {
+				_ = conn.Close()
+				conn = nil
+				continue
+			}
+			break
+		}
+	}
+}
+
+// flush sends any queued messages over conn without retrying. If conn is nil, a
+// new connection is opened (and closed) for this purpose.
+func (h *FluentdHook) flush(conn net.Conn) {
+	if len(h.messages) == 0 {
+		return
+	}
+	if conn == nil {
+		var err error
+		conn, err = net.DialTimeout("tcp", h.addr, fluentdDialTimeout)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+	}
+	for {
+		select {
+		case message := <-h.messages:
+			if err := writeFluentdMessage(conn, message); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func writeFluentdMessage(conn net.Conn, message []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(fluentdWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(message)
+	return err
+}
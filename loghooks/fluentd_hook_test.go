@@ -0,0 +1,73 @@
+package loghooks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMsgpack(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{false, []byte{0xc2}},
+		{float64(5), []byte{0x05}},
+		{float64(-1), []byte{0xff}},
+		{float64(1000), []byte{0xd3, 0, 0, 0, 0, 0, 0, 0x03, 0xe8}},
+		{float64(1.5), []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"abc", []byte{0xa3, 'a', 'b', 'c'}},
+		{[]interface{}{"a", float64(1)}, []byte{0x92, 0xa1, 'a', 0x01}},
+		{map[string]interface{}{"b": true, "a": nil}, []byte{0x82, 0xa1, 'a', 0xc0, 0xa1, 'b', 0xc3}},
+	}
+	for _, testCase := range testCases {
+		buf := &bytes.Buffer{}
+		require.NoError(t, writeMsgpack(buf, testCase.value))
+		assert.Equal(t, testCase.expected, buf.Bytes(), "value: %v", testCase.value)
+	}
+}
+
+func TestFluentdHook(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := ioutil.ReadAll(conn)
+		received <- data
+	}()
+
+	hook := NewFluentdHook(listener.Addr().String(), "mesh")
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	logger.AddHook(hook)
+	logger.WithField("myKey", "myValue").Info("hello")
+	require.NoError(t, hook.Close())
+
+	select {
+	case data := <-received:
+		// The message should be an array of [tag, time, record].
+		require.NotEmpty(t, data)
+		assert.Equal(t, byte(0x93), data[0])
+		assert.Equal(t, byte(0xa4), data[1])
+		assert.Equal(t, "mesh", string(data[2:6]))
+		assert.Contains(t, string(data), "myValue")
+		assert.Contains(t, string(data), "hello")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Fluentd message")
+	}
+	assert.Equal(t, uint64(0), hook.Dropped())
+}
@@ -0,0 +1,91 @@
+package loghooks
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LevelFilterFormatter is a log.Formatter which drops entries that are more
+// verbose than a base level, unless they were logged from a package which has
+// been given a more verbose level. It can be used to enable debug logging for
+// specific packages without enabling it everywhere. Since the level of the
+// logger is checked before entries are formatted, the logger level must be set
+// to MaxLevel and ReportCaller must be enabled for the package levels to have
+// any effect. Note that hooks still receive entries which are dropped by
+// LevelFilterFormatter.
+type LevelFilterFormatter struct {
+	formatter     log.Formatter
+	mu            sync.RWMutex
+	baseLevel     log.Level
+	packageLevels map[string]log.Level
+}
+
+// NewLevelFilterFormatter returns a LevelFilterFormatter which uses formatter
+// to format entries which are not dropped.
+func NewLevelFilterFormatter(formatter log.Formatter, baseLevel log.Level) *LevelFilterFormatter {
+	return &LevelFilterFormatter{
+		formatter: formatter,
+		baseLevel: baseLevel,
+	}
+}
+
+// SetLevels sets the base level and the levels for specific packages.
+// packageLevels maps a package path (e.g. "github.com/0xProject/0x-mesh/p2p")
+// to a level which applies to that package and all of its subpackages.
+func (f *LevelFilterFormatter) SetLevels(baseLevel log.Level, packageLevels map[string]log.Level) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.baseLevel = baseLevel
+	f.packageLevels = packageLevels
+}
+
+// MaxLevel returns the most verbose of the base level and the package levels.
+func (f *LevelFilterFormatter) MaxLevel() log.Level {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	maxLevel := f.baseLevel
+	for _, level := range f.packageLevels {
+		if level > maxLevel {
+			maxLevel = level
+		}
+	}
+	return maxLevel
+}
+
+// Format implements log.Formatter. It returns nil for dropped entries.
+func (f *LevelFilterFormatter) Format(entry *log.Entry) ([]byte, error) {
+	if !f.isEnabled(entry) {
+		return nil, nil
+	}
+	return f.formatter.Format(entry)
+}
+
+func (f *LevelFilterFormatter) isEnabled(entry *log.Entry) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if entry.Level <= f.baseLevel {
+		return true
+	}
+	if entry.Caller == nil {
+		return false
+	}
+	for pkg, level := range f.packageLevels {
+		if entry.Level <= level && isInPackage(entry.Caller.Function, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInPackage returns true if the given fully qualified function name (e.g.
+// "github.com/0xProject/0x-mesh/p2p.(*Node).Start") belongs to pkg or one of
+// its subpackages.
+func isInPackage(function string, pkg string) bool {
+	if !strings.HasPrefix(function, pkg) {
+		return false
+	}
+	rest := function[len(pkg):]
+	return strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/")
+}
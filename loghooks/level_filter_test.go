@@ -0,0 +1,45 @@
+package loghooks
+
+import (
+	"runtime"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelFilterFormatter(t *testing.T) {
+	formatter := NewLevelFilterFormatter(&log.TextFormatter{}, log.InfoLevel)
+	formatter.SetLevels(log.InfoLevel, map[string]log.Level{
+		"github.com/0xProject/0x-mesh/p2p": log.DebugLevel,
+	})
+	assert.Equal(t, log.DebugLevel, formatter.MaxLevel())
+
+	newEntry := func(level log.Level, function string) *log.Entry {
+		entry := log.NewEntry(log.New())
+		entry.Level = level
+		entry.Message = "test"
+		if function != "" {
+			entry.Caller = &runtime.Frame{Function: function}
+		}
+		return entry
+	}
+	testCases := []struct {
+		entry   *log.Entry
+		dropped bool
+	}{
+		{newEntry(log.InfoLevel, ""), false},
+		{newEntry(log.DebugLevel, ""), true},
+		{newEntry(log.DebugLevel, "github.com/0xProject/0x-mesh/p2p.(*Node).Start"), false},
+		{newEntry(log.DebugLevel, "github.com/0xProject/0x-mesh/p2p/banner.New"), false},
+		{newEntry(log.TraceLevel, "github.com/0xProject/0x-mesh/p2p.(*Node).Start"), true},
+		{newEntry(log.DebugLevel, "github.com/0xProject/0x-mesh/p2pfoo.Bar"), true},
+		{newEntry(log.DebugLevel, "github.com/0xProject/0x-mesh/core.(*App).Start"), true},
+	}
+	for i, testCase := range testCases {
+		formatted, err := formatter.Format(testCase.entry)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.dropped, len(formatted) == 0, "test case %d", i)
+	}
+}
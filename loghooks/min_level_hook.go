@@ -0,0 +1,41 @@
+package loghooks
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// MinLevelHook is a logger hook which wraps another hook and only passes it
+// the entries that are at least as severe as a minimum level. It prevents
+// external log sinks from receiving debug and trace logs when the verbosity of
+// the logger is increased (e.g. via mesh_setLogLevel).
+type MinLevelHook struct {
+	hook   log.Hook
+	levels []log.Level
+}
+
+// NewMinLevelHook returns a MinLevelHook which passes the entries at minLevel
+// or a more severe level to hook. For example, if minLevel is log.InfoLevel,
+// hook doesn't receive debug and trace logs.
+func NewMinLevelHook(hook log.Hook, minLevel log.Level) *MinLevelHook {
+	levels := []log.Level{}
+	for _, level := range hook.Levels() {
+		if level <= minLevel {
+			levels = append(levels, level)
+		}
+	}
+	return &MinLevelHook{
+		hook:   hook,
+		levels: levels,
+	}
+}
+
+// Ensure that MinLevelHook implements log.Hook.
+var _ log.Hook = &MinLevelHook{}
+
+func (h *MinLevelHook) Levels() []log.Level {
+	return h.levels
+}
+
+func (h *MinLevelHook) Fire(entry *log.Entry) error {
+	return h.hook.Fire(entry)
+}
@@ -0,0 +1,31 @@
+package loghooks
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinLevelHook(t *testing.T) {
+	var buf bytes.Buffer
+	hook := NewMinLevelHook(NewWriterHook(&buf, &log.TextFormatter{DisableTimestamp: true}), log.InfoLevel)
+	assert.Equal(t, []log.Level{log.PanicLevel, log.FatalLevel, log.ErrorLevel, log.WarnLevel, log.InfoLevel}, hook.Levels())
+
+	logger := log.New()
+	logger.SetOutput(&bytes.Buffer{})
+	logger.SetLevel(log.TraceLevel)
+	logger.AddHook(hook)
+	logger.Trace("trace message")
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Error("error message")
+
+	output := buf.String()
+	assert.NotContains(t, output, "trace message")
+	assert.NotContains(t, output, "debug message")
+	assert.Contains(t, output, "info message")
+	require.Contains(t, output, "error message")
+}
@@ -0,0 +1,108 @@
+package loghooks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// writeMsgpack appends the MessagePack encoding of v to buf. It only supports
+// the types produced by json.Unmarshal into an interface{} (i.e. nil, bool,
+// float64, string, []interface{} and map[string]interface{}) plus int64, which
+// is all we need to encode log records for the Fluentd forward protocol.
+// Floats with an integral value are encoded as integers.
+func writeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		writeMsgpackInt(buf, v)
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			writeMsgpackInt(buf, int64(v))
+			return nil
+		}
+		buf.WriteByte(0xcb)
+		_ = binary.Write(buf, binary.BigEndian, v)
+	case string:
+		writeMsgpackString(buf, v)
+	case []interface{}:
+		writeMsgpackHeader(buf, len(v), 0x90, 0xdc, 0xdd)
+		for _, elem := range v {
+			if err := writeMsgpack(buf, elem); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		writeMsgpackHeader(buf, len(v), 0x80, 0xde, 0xdf)
+		// Sort the keys so that the encoding is deterministic.
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			writeMsgpackString(buf, key)
+			if err := writeMsgpack(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode type %T as msgpack", v)
+	}
+	return nil
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, v int64) {
+	switch {
+	case v >= 0 && v < 128:
+		// positive fixint
+		buf.WriteByte(byte(v))
+	case v < 0 && v >= -32:
+		// negative fixint
+		buf.WriteByte(byte(int8(v)))
+	default:
+		buf.WriteByte(0xd3)
+		_ = binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func writeMsgpackString(buf *bytes.Buffer, s string) {
+	switch n := len(s); {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n < 1<<8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n < 1<<16:
+		buf.WriteByte(0xda)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// writeMsgpackHeader writes the header for an array or map with n elements
+// using the given fix, 16-bit and 32-bit type prefixes.
+func writeMsgpackHeader(buf *bytes.Buffer, n int, fix byte, prefix16 byte, prefix32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n < 1<<16:
+		buf.WriteByte(prefix16)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(prefix32)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
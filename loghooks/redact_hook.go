@@ -0,0 +1,160 @@
+package loghooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactAction is the action taken by RedactHook for a matching field.
+type RedactAction string
+
+const (
+	// RedactDrop removes the field entirely.
+	RedactDrop RedactAction = "drop"
+	// RedactHash replaces the value of the field with its SHA-256 hash. This
+	// hides the original value while still allowing logs that contain the same
+	// value to be correlated.
+	RedactHash RedactAction = "hash"
+)
+
+// RedactHook is a logger hook that drops or hashes sensitive fields (e.g.
+// maker addresses or raw signed orders) before logs are written. Fields are
+// matched by key both at the top level of the log entry and inside of nested
+// objects (e.g. "makerAddress" inside of a "signedOrder" field).
+type RedactHook struct {
+	fields map[string]RedactAction
+}
+
+// NewRedactHook creates and returns a new RedactHook from a comma-separated
+// list of field specs of the form "key" or "key:action", where action is
+// either "drop" or "hash". If no action is given, the field is dropped. For
+// example: "signedOrder:drop,makerAddress:hash".
+func NewRedactHook(spec string) (*RedactHook, error) {
+	fields := map[string]RedactAction{}
+	for _, fieldSpec := range strings.Split(spec, ",") {
+		fieldSpec = strings.TrimSpace(fieldSpec)
+		if fieldSpec == "" {
+			continue
+		}
+		key, action := fieldSpec, RedactDrop
+		if i := strings.LastIndex(fieldSpec, ":"); i != -1 {
+			key, action = fieldSpec[:i], RedactAction(fieldSpec[i+1:])
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid redact field spec %q: key cannot be empty", fieldSpec)
+		}
+		if action != RedactDrop && action != RedactHash {
+			return nil, fmt.Errorf("invalid redact field spec %q: action must be %q or %q", fieldSpec, RedactDrop, RedactHash)
+		}
+		fields[key] = action
+	}
+	return &RedactHook{fields: fields}, nil
+}
+
+// Ensure that RedactHook implements log.Hook.
+var _ log.Hook = &RedactHook{}
+
+func (h *RedactHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *RedactHook) Fire(entry *log.Entry) error {
+	if len(h.fields) == 0 {
+		return nil
+	}
+	newFields := make(log.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if action, found := h.fields[key]; found {
+			if action == RedactHash {
+				newFields[key] = hashValue(value)
+			}
+			continue
+		}
+		newFields[key] = h.redactNested(value)
+	}
+	entry.Data = newFields
+	return nil
+}
+
+// redactNested returns a redacted copy of value if its JSON encoding contains
+// an object with a key that should be redacted. Otherwise it returns value
+// unchanged so that its type (and therefore the key suffix added by
+// KeySuffixHook) is preserved.
+func (h *RedactHook) redactNested(value interface{}) interface{} {
+	if _, ok := value.(error); ok {
+		// Errors are always logged as strings.
+		return value
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil || len(encoded) == 0 || (encoded[0] != '{' && encoded[0] != '[') {
+		return value
+	}
+	var holder interface{}
+	if err := json.Unmarshal(encoded, &holder); err != nil {
+		return value
+	}
+	redacted, changed := h.redactJSONValue(holder)
+	if !changed {
+		return value
+	}
+	return redacted
+}
+
+// redactJSONValue recursively redacts matching keys in a value decoded from
+// JSON. It returns the redacted value and whether anything was changed.
+func (h *RedactHook) redactJSONValue(value interface{}) (interface{}, bool) {
+	changed := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range value {
+			if action, found := h.fields[key]; found {
+				if action == RedactHash {
+					value[key] = hashValue(inner)
+				} else {
+					delete(value, key)
+				}
+				changed = true
+				continue
+			}
+			if redacted, innerChanged := h.redactJSONValue(inner); innerChanged {
+				value[key] = redacted
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, inner := range value {
+			if redacted, innerChanged := h.redactJSONValue(inner); innerChanged {
+				value[i] = redacted
+				changed = true
+			}
+		}
+	}
+	return value, changed
+}
+
+// hashValue returns the hex-encoded SHA-256 hash of value. Strings (and values
+// which are encoded as JSON strings, such as addresses) are hashed directly so
+// that the same value always results in the same hash regardless of whether it
+// was logged at the top level or inside of a nested object.
+func hashValue(value interface{}) string {
+	var data []byte
+	if s, ok := value.(string); ok {
+		data = []byte(s)
+	} else if encoded, err := json.Marshal(value); err != nil {
+		data = []byte(fmt.Sprint(value))
+	} else {
+		var s string
+		if err := json.Unmarshal(encoded, &s); err == nil {
+			data = []byte(s)
+		} else {
+			data = encoded
+		}
+	}
+	hash := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,57 @@
+package loghooks
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type redactTestOrder struct {
+	MakerAddress string `json:"makerAddress"`
+	Salt         int    `json:"salt"`
+}
+
+func TestNewRedactHookInvalidSpec(t *testing.T) {
+	for _, spec := range []string{":hash", "makerAddress:encrypt"} {
+		_, err := NewRedactHook(spec)
+		assert.Error(t, err, "spec: %q", spec)
+	}
+}
+
+func TestRedactHook(t *testing.T) {
+	hook, err := NewRedactHook("signature, makerAddress:hash, signedOrder:drop")
+	require.NoError(t, err)
+
+	makerAddress := "0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"
+	entry := &log.Entry{
+		Data: log.Fields{
+			"signature":    "0x1b",
+			"makerAddress": makerAddress,
+			"signedOrder":  redactTestOrder{MakerAddress: makerAddress, Salt: 1},
+			"order":        redactTestOrder{MakerAddress: makerAddress, Salt: 2},
+			"orders":       []redactTestOrder{{MakerAddress: makerAddress, Salt: 3}},
+			"myInt":        42,
+		},
+	}
+	require.NoError(t, hook.Fire(entry))
+
+	hashedAddress := hashValue(makerAddress)
+	expected := log.Fields{
+		"makerAddress": hashedAddress,
+		"order": map[string]interface{}{
+			"makerAddress": hashedAddress,
+			"salt":         float64(2),
+		},
+		"orders": []interface{}{
+			map[string]interface{}{
+				"makerAddress": hashedAddress,
+				"salt":         float64(3),
+			},
+		},
+		// Fields which don't need to be redacted should be left unchanged.
+		"myInt": 42,
+	}
+	assert.Equal(t, expected, entry.Data)
+}
@@ -0,0 +1,61 @@
+package loghooks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotating_file_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mesh.log")
+
+	file, err := NewRotatingFile(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, file.Close())
+
+	expectedContents := map[string]string{
+		path:        "dddddddd\n",
+		path + ".1": "cccccccc\n",
+		path + ".2": "bbbbbbbb\n",
+	}
+	for filePath, expected := range expectedContents {
+		actual, err := ioutil.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(actual), filePath)
+	}
+	// Only maxBackups rotated files should be kept.
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "expected oldest log file to be removed")
+}
+
+func TestRotatingFileAppendsToExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotating_file_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "mesh.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0644))
+
+	file, err := NewRotatingFile(path, 10, 1)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	rotated, err := ioutil.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "existing\n", string(rotated))
+	current, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(current))
+}
@@ -0,0 +1,48 @@
+// +build !windows,!js
+
+package loghooks
+
+import (
+	"fmt"
+	"log/syslog"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+	logrus_syslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// SyslogHook is a logger hook which sends all logs to syslog. Close must be
+// called to close the connection to syslog.
+type SyslogHook struct {
+	*logrus_syslog.SyslogHook
+}
+
+// Ensure that SyslogHook implements log.Hook.
+var _ log.Hook = &SyslogHook{}
+
+// NewSyslogHook creates and returns a logger hook which sends all logs to
+// syslog with the given tag. addr is either "local" (to use the local syslog
+// daemon) or a URL of the form "udp://host:port" or "tcp://host:port".
+func NewSyslogHook(addr string, tag string) (*SyslogHook, error) {
+	network, raddr := "", ""
+	if addr != "local" {
+		parsed, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Scheme != "udp" && parsed.Scheme != "tcp" {
+			return nil, fmt.Errorf("unsupported syslog network %q (expected udp or tcp)", parsed.Scheme)
+		}
+		network, raddr = parsed.Scheme, parsed.Host
+	}
+	hook, err := logrus_syslog.NewSyslogHook(network, raddr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogHook{SyslogHook: hook}, nil
+}
+
+// Close closes the connection to syslog.
+func (h *SyslogHook) Close() error {
+	return h.Writer.Close()
+}
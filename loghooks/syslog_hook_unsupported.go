@@ -0,0 +1,37 @@
+// +build windows js
+
+package loghooks
+
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SyslogHook is a logger hook which sends all logs to syslog. It can't be
+// created on this platform.
+type SyslogHook struct{}
+
+// Ensure that SyslogHook implements log.Hook.
+var _ log.Hook = &SyslogHook{}
+
+// NewSyslogHook always returns an error because syslog is not supported on
+// this platform.
+func NewSyslogHook(addr string, tag string) (*SyslogHook, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
+
+// Levels implements log.Hook.
+func (h *SyslogHook) Levels() []log.Level {
+	return nil
+}
+
+// Fire implements log.Hook.
+func (h *SyslogHook) Fire(entry *log.Entry) error {
+	return nil
+}
+
+// Close implements io.Closer.
+func (h *SyslogHook) Close() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package loghooks
+
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// WriterHook is a logger hook that formats every log entry and writes it to
+// an io.Writer (e.g. a RotatingFile) in addition to the logger's own output.
+type WriterHook struct {
+	writer    io.Writer
+	formatter log.Formatter
+}
+
+// NewWriterHook creates and returns a new WriterHook which formats log entries
+// with the given formatter and writes them to w. If formatter is nil, entries
+// are formatted as JSON.
+func NewWriterHook(w io.Writer, formatter log.Formatter) *WriterHook {
+	if formatter == nil {
+		formatter = &log.JSONFormatter{}
+	}
+	return &WriterHook{
+		writer:    w,
+		formatter: formatter,
+	}
+}
+
+// Ensure that WriterHook implements log.Hook.
+var _ log.Hook = &WriterHook{}
+
+func (h *WriterHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *WriterHook) Fire(entry *log.Entry) error {
+	formatted, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = h.writer.Write(formatted)
+	return err
+}
@@ -0,0 +1,159 @@
+package meshdb
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HistoricalBlock is the database representation of a block that was
+// processed by the block watcher. Unlike MiniHeaders, which are pruned once
+// they are deeper than the maximum re-org depth, historical blocks are kept
+// for a configurable number of blocks so that order events can be correlated
+// with the blocks in which they happened.
+type HistoricalBlock struct {
+	Number    *big.Int
+	Hash      common.Hash
+	Parent    common.Hash
+	Timestamp time.Time
+	// Logs are the logs of the block which were relevant to Mesh (i.e. the
+	// ones that were passed to the order watcher).
+	Logs []types.Log
+}
+
+// ID returns the HistoricalBlock's ID. Blocks are keyed by their number so
+// that a block which is replaced due to a re-org overwrites the old one.
+func (b HistoricalBlock) ID() []byte {
+	return uint256ToConstantLengthBytes(b.Number)
+}
+
+// BlockHistoryCollection represents a DB collection of historical blocks.
+type BlockHistoryCollection struct {
+	*db.Collection
+	numberIndex *db.Index
+}
+
+func setupBlockHistory(database *db.DB) (*BlockHistoryCollection, error) {
+	col, err := database.NewCollection("historicalBlock", &HistoricalBlock{})
+	if err != nil {
+		return nil, err
+	}
+	numberIndex := col.AddIndex("number", func(model db.Model) []byte {
+		return uint256ToConstantLengthBytes(model.(*HistoricalBlock).Number)
+	})
+	return &BlockHistoryCollection{
+		Collection:  col,
+		numberIndex: numberIndex,
+	}, nil
+}
+
+// AddHistoricalBlocks adds the given MiniHeaders to the block history. A
+// block that has the same number as an existing one replaces it.
+func (m *MeshDB) AddHistoricalBlocks(miniHeaders []*miniheader.MiniHeader) error {
+	txn := m.blockHistory.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	// Each block number can only be affected by one operation per
+	// transaction, so only the last header for each number is kept.
+	byNumber := map[string]*HistoricalBlock{}
+	for _, miniHeader := range miniHeaders {
+		block := &HistoricalBlock{
+			Number:    miniHeader.Number,
+			Hash:      miniHeader.Hash,
+			Parent:    miniHeader.Parent,
+			Timestamp: miniHeader.Timestamp,
+			Logs:      miniHeader.Logs,
+		}
+		byNumber[string(block.ID())] = block
+	}
+	for _, block := range byNumber {
+		var existing HistoricalBlock
+		if err := m.blockHistory.FindByID(block.ID(), &existing); err == nil {
+			if err := txn.Update(block); err != nil {
+				return err
+			}
+			continue
+		} else if _, ok := err.(db.NotFoundError); !ok {
+			return err
+		}
+		if err := txn.Insert(block); err != nil {
+			return err
+		}
+	}
+
+	return txn.Commit()
+}
+
+// RemoveHistoricalBlock removes the block with the given number from the block
+// history if its hash matches the given hash. It is used when a block is
+// removed due to a re-org and no replacement block has been added yet.
+func (m *MeshDB) RemoveHistoricalBlock(number *big.Int, hash common.Hash) error {
+	var existing HistoricalBlock
+	if err := m.blockHistory.FindByID(uint256ToConstantLengthBytes(number), &existing); err != nil {
+		if _, ok := err.(db.NotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	if existing.Hash != hash {
+		return nil
+	}
+	return m.blockHistory.Delete(existing.ID())
+}
+
+// FindHistoricalBlocks returns up to max historical blocks with a block number
+// in the range [from, to], sorted in ascending block number order.
+func (m *MeshDB) FindHistoricalBlocks(from, to *big.Int, max int) ([]*HistoricalBlock, error) {
+	blocks := []*HistoricalBlock{}
+	if to.Cmp(from) < 0 {
+		return blocks, nil
+	}
+	filter := m.blockHistory.numberIndex.RangeFilter(
+		uint256ToConstantLengthBytes(from),
+		uint256ToConstantLengthBytes(big.NewInt(0).Add(to, big.NewInt(1))),
+	)
+	if err := m.blockHistory.NewQuery(filter).Max(max).Run(&blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// PruneBlockHistory removes all historical blocks with a block number less
+// than the given minBlockNumber and returns the number of blocks that were
+// removed.
+func (m *MeshDB) PruneBlockHistory(minBlockNumber *big.Int) (int, error) {
+	filter := m.blockHistory.numberIndex.RangeFilter(
+		uint256ToConstantLengthBytes(big.NewInt(0)),
+		uint256ToConstantLengthBytes(minBlockNumber),
+	)
+	total := 0
+	for {
+		txn := m.blockHistory.OpenTransaction()
+		var blocks []*HistoricalBlock
+		if err := m.blockHistory.NewQuery(filter).Max(miniHeadersMaxPerPage).Run(&blocks); err != nil {
+			_ = txn.Discard()
+			return total, err
+		}
+		if len(blocks) == 0 {
+			_ = txn.Discard()
+			return total, nil
+		}
+		for _, block := range blocks {
+			if err := txn.Delete(block.ID()); err != nil {
+				_ = txn.Discard()
+				return total, err
+			}
+		}
+		if err := txn.Commit(); err != nil {
+			_ = txn.Discard()
+			return total, err
+		}
+		total += len(blocks)
+	}
+}
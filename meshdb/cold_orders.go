@@ -0,0 +1,368 @@
+package meshdb
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// coldOrdersMaxPerTransaction is the maximum number of orders which are moved
+// between the hot and cold tiers in a single transaction.
+const coldOrdersMaxPerTransaction = 1000
+
+// ColdOrdersCollection represents a DB collection of orders which are rarely
+// queried (e.g. because they are deep out of the money or expire far in the
+// future). Cold orders are stored with the same compression as hot orders but
+// with only a few indexes, which keeps the indexes of the orders collection
+// small for very large stores. Cold orders are never pinned or flagged for
+// removal. They are moved back to the orders collection (i.e. "thawed") before
+// anything can change their state.
+type ColdOrdersCollection struct {
+	*db.Collection
+	MakerAddressIndex   *db.Index
+	LastUpdatedIndex    *db.Index
+	ExpirationTimeIndex *db.Index
+	TopicIndex          *db.Index
+}
+
+func setupColdOrders(database *db.DB) (*ColdOrdersCollection, error) {
+	col, err := database.NewCollection("coldOrder", &Order{})
+	if err != nil {
+		return nil, err
+	}
+	col.SetCompressionDictionary([]byte(orderCompressionDictionary))
+	makerAddressIndex := col.AddIndex("makerAddress", func(m db.Model) []byte {
+		return []byte(m.(*Order).SignedOrder.MakerAddress.Hex())
+	})
+	lastUpdatedIndex := col.AddIndex("lastUpdated", func(m db.Model) []byte {
+		return []byte(m.(*Order).LastUpdated.UTC().Format(time.RFC3339Nano))
+	})
+	expirationTimeIndex := col.AddIndex("expirationTime", func(m db.Model) []byte {
+		return uint256ToConstantLengthBytes(m.(*Order).SignedOrder.ExpirationTimeSeconds)
+	})
+	topicIndex := col.AddMultiIndex("topic", func(m db.Model) [][]byte {
+		order := m.(*Order)
+		indexValues := make([][]byte, len(order.Topics))
+		for i, topic := range order.Topics {
+			indexValues[i] = []byte(topic)
+		}
+		return indexValues
+	})
+	return &ColdOrdersCollection{
+		Collection:          col,
+		MakerAddressIndex:   makerAddressIndex,
+		LastUpdatedIndex:    lastUpdatedIndex,
+		ExpirationTimeIndex: expirationTimeIndex,
+		TopicIndex:          topicIndex,
+	}, nil
+}
+
+// ColdOrderCriteria determines which orders are stored in the cold tier. An
+// order is cold if it matches at least one of the enabled criteria.
+type ColdOrderCriteria struct {
+	// MinTimeToExpiry is the minimum amount of time until an order expires for
+	// it to be cold. 0 disables this criterion.
+	MinTimeToExpiry time.Duration
+	// MinPriceDistance is the minimum relative distance between the price of an
+	// order and the best price on the same side of its market for it to be cold.
+	// E.g. 0.5 means that asks must be priced at least 50% above the best ask
+	// and bids at least 50% below the best bid (in terms of the quote asset per
+	// unit of the base asset). 0 disables this criterion.
+	MinPriceDistance float64
+	// ExpirationBuffer is the amount of time before an order expires during
+	// which it is always hot, regardless of the other criteria.
+	ExpirationBuffer time.Duration
+}
+
+// Enabled returns true if at least one of the criteria is enabled.
+func (c ColdOrderCriteria) Enabled() bool {
+	return c.MinTimeToExpiry > 0 || c.MinPriceDistance > 0
+}
+
+func (c ColdOrderCriteria) isCold(order *Order, aggregates map[string]*MarketAggregate, now time.Time) bool {
+	if order.IsPinned || order.IsRemoved {
+		return false
+	}
+	expirationTimeSeconds := order.SignedOrder.ExpirationTimeSeconds
+	if expirationTimeSeconds.IsInt64() {
+		timeToExpiry := time.Unix(expirationTimeSeconds.Int64(), 0).Sub(now)
+		if timeToExpiry <= c.ExpirationBuffer {
+			return false
+		}
+		if c.MinTimeToExpiry > 0 && timeToExpiry >= c.MinTimeToExpiry {
+			return true
+		}
+	} else if c.MinTimeToExpiry > 0 {
+		// The order expires too far in the future to be represented as a
+		// time.Time, so it effectively never expires.
+		return true
+	}
+	if c.MinPriceDistance > 0 {
+		return c.isFarFromBestPrice(order, aggregates)
+	}
+	return false
+}
+
+func (c ColdOrderCriteria) isFarFromBestPrice(order *Order, aggregates map[string]*MarketAggregate) bool {
+	baseAssetData, quoteAssetData, price, isAsk, ok := orderMarketAndPrice(order)
+	if !ok {
+		return false
+	}
+	aggregate, found := aggregates[marketKey(baseAssetData, quoteAssetData)]
+	if !found {
+		return false
+	}
+	distance := new(big.Rat).SetFloat64(1 + c.MinPriceDistance)
+	if distance == nil {
+		return false
+	}
+	if isAsk {
+		// The ask is cold if price >= bestAsk * (1 + distance).
+		if aggregate.BestAsk == nil {
+			return false
+		}
+		threshold := new(big.Rat).Mul(aggregate.BestAsk, distance)
+		return price.Cmp(threshold) >= 0
+	}
+	// The bid is cold if price * (1 + distance) <= bestBid.
+	if aggregate.BestBid == nil {
+		return false
+	}
+	return new(big.Rat).Mul(price, distance).Cmp(aggregate.BestBid) <= 0
+}
+
+// UpdateOrderTiers moves the hot orders which match the given criteria to the
+// cold tier and moves the cold orders which no longer match them back to the
+// hot tier. If none of the criteria are enabled, all cold orders are moved
+// back to the hot tier. It returns the number of orders that were moved in
+// each direction.
+func (m *MeshDB) UpdateOrderTiers(criteria ColdOrderCriteria, now time.Time) (numFrozen int, numThawed int, err error) {
+	if !criteria.Enabled() {
+		numThawed, err := m.ThawAllOrders()
+		return 0, numThawed, err
+	}
+	notRemovedFilter := m.Orders.IsRemovedIndex.ValueFilter([]byte{0})
+	hotOrders := []*Order{}
+	if err := m.Orders.NewQuery(notRemovedFilter).Run(&hotOrders); err != nil {
+		return 0, 0, err
+	}
+	coldOrders := []*Order{}
+	if err := m.ColdOrders.FindAll(&coldOrders); err != nil {
+		return 0, 0, err
+	}
+	aggregates := map[string]*MarketAggregate{}
+	if criteria.MinPriceDistance > 0 {
+		for _, order := range hotOrders {
+			addToMarketAggregates(aggregates, order)
+		}
+		for _, order := range coldOrders {
+			addToMarketAggregates(aggregates, order)
+		}
+	}
+
+	ordersToFreeze := []*Order{}
+	for _, order := range hotOrders {
+		if criteria.isCold(order, aggregates, now) {
+			ordersToFreeze = append(ordersToFreeze, order)
+		}
+	}
+	ordersToThaw := []*Order{}
+	for _, order := range coldOrders {
+		if !criteria.isCold(order, aggregates, now) {
+			ordersToThaw = append(ordersToThaw, order)
+		}
+	}
+	if err := m.moveOrders(m.Orders.Collection, m.ColdOrders.Collection, ordersToFreeze); err != nil {
+		return 0, 0, err
+	}
+	if err := m.moveOrders(m.ColdOrders.Collection, m.Orders.Collection, ordersToThaw); err != nil {
+		return len(ordersToFreeze), 0, err
+	}
+	return len(ordersToFreeze), len(ordersToThaw), nil
+}
+
+// moveOrders moves the given orders from one collection to another.
+func (m *MeshDB) moveOrders(from, to *db.Collection, orders []*Order) error {
+	for len(orders) > 0 {
+		page := orders
+		if len(page) > coldOrdersMaxPerTransaction {
+			page = page[:coldOrdersMaxPerTransaction]
+		}
+		orders = orders[len(page):]
+		txn := m.database.OpenGlobalTransaction()
+		for _, order := range page {
+			if err := txn.Delete(from, order.ID()); err != nil {
+				_ = txn.Discard()
+				return err
+			}
+			if err := txn.Insert(to, order); err != nil {
+				_ = txn.Discard()
+				return err
+			}
+		}
+		if err := txn.Commit(); err != nil {
+			_ = txn.Discard()
+			return err
+		}
+	}
+	return nil
+}
+
+// thawOrdersWithFilter moves the cold orders which match the given filter back
+// to the hot tier and returns the number of orders that were moved.
+func (m *MeshDB) thawOrdersWithFilter(filter *db.Filter) (int, error) {
+	orders := []*Order{}
+	if err := m.ColdOrders.NewQuery(filter).Run(&orders); err != nil {
+		return 0, err
+	}
+	if err := m.moveOrders(m.ColdOrders.Collection, m.Orders.Collection, orders); err != nil {
+		return 0, err
+	}
+	return len(orders), nil
+}
+
+// ThawAllOrders moves all cold orders back to the hot tier and returns the
+// number of orders that were moved.
+func (m *MeshDB) ThawAllOrders() (int, error) {
+	return m.thawOrdersWithFilter(m.ColdOrders.LastUpdatedIndex.All())
+}
+
+// ThawOrders moves the cold orders with the given hashes back to the hot tier
+// and returns the number of orders that were moved. Hashes of orders which are
+// not in the cold tier are ignored.
+func (m *MeshDB) ThawOrders(orderHashes []common.Hash) (int, error) {
+	orders := []*Order{}
+	for _, orderHash := range orderHashes {
+		var order Order
+		if err := m.ColdOrders.FindByID(orderHash.Bytes(), &order); err != nil {
+			if _, ok := err.(db.NotFoundError); ok {
+				continue
+			}
+			return 0, err
+		}
+		orders = append(orders, &order)
+	}
+	if err := m.moveOrders(m.ColdOrders.Collection, m.Orders.Collection, orders); err != nil {
+		return 0, err
+	}
+	return len(orders), nil
+}
+
+// ThawOrdersByMakerAddresses moves the cold orders of the given makers back to
+// the hot tier and returns the number of orders that were moved.
+func (m *MeshDB) ThawOrdersByMakerAddresses(makerAddresses []common.Address) (int, error) {
+	total := 0
+	for _, makerAddress := range makerAddresses {
+		filter := m.ColdOrders.MakerAddressIndex.ValueFilter([]byte(makerAddress.Hex()))
+		numThawed, err := m.thawOrdersWithFilter(filter)
+		if err != nil {
+			return total, err
+		}
+		total += numThawed
+	}
+	return total, nil
+}
+
+// ThawOrdersExpiringBefore moves the cold orders which expire at or before the
+// given time back to the hot tier and returns the number of orders that were
+// moved.
+func (m *MeshDB) ThawOrdersExpiringBefore(expiration time.Time) (int, error) {
+	limit := big.NewInt(expiration.Unix() + 1)
+	filter := m.ColdOrders.ExpirationTimeIndex.RangeFilter(
+		uint256ToConstantLengthBytes(big.NewInt(0)),
+		uint256ToConstantLengthBytes(limit),
+	)
+	return m.thawOrdersWithFilter(filter)
+}
+
+// FindColdOrdersLastUpdatedBefore finds all cold orders where the LastUpdated
+// time is less than X. Unlike the Thaw methods, it leaves the orders in the
+// cold tier.
+func (m *MeshDB) FindColdOrdersLastUpdatedBefore(lastUpdated time.Time) ([]*Order, error) {
+	start := []byte(time.Unix(0, 0).Format(time.RFC3339Nano))
+	limit := []byte(lastUpdated.UTC().Format(time.RFC3339Nano))
+	filter := m.ColdOrders.LastUpdatedIndex.RangeFilter(start, limit)
+	orders := []*Order{}
+	if err := m.ColdOrders.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindAllColdOrders returns all cold orders.
+func (m *MeshDB) FindAllColdOrders() ([]*Order, error) {
+	orders := []*Order{}
+	if err := m.ColdOrders.FindAll(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// CountColdOrders returns the number of cold orders.
+func (m *MeshDB) CountColdOrders() (int, error) {
+	return m.ColdOrders.Count()
+}
+
+// FindOrder finds the stored order with the given hash in either the hot or
+// the cold tier without moving it. It returns a db.NotFoundError if the order
+// is not stored.
+func (m *MeshDB) FindOrder(orderHash common.Hash) (*Order, error) {
+	_, order, err := m.findOrderAndCollection(orderHash)
+	return order, err
+}
+
+// findOrderAndCollection finds the stored order with the given hash and
+// returns it along with the collection (hot or cold) it is stored in.
+func (m *MeshDB) findOrderAndCollection(orderHash common.Hash) (*db.Collection, *Order, error) {
+	var order Order
+	err := m.Orders.FindByID(orderHash.Bytes(), &order)
+	if err == nil {
+		return m.Orders.Collection, &order, nil
+	} else if _, ok := err.(db.NotFoundError); !ok {
+		return nil, nil, err
+	}
+	if err := m.ColdOrders.FindByID(orderHash.Bytes(), &order); err != nil {
+		return nil, nil, err
+	}
+	return m.ColdOrders.Collection, &order, nil
+}
+
+// FindNotRemovedOrders returns all orders in both the hot and the cold tier
+// which have not been flagged for removal.
+func (m *MeshDB) FindNotRemovedOrders() ([]*Order, error) {
+	notRemovedFilter := m.Orders.IsRemovedIndex.ValueFilter([]byte{0})
+	orders := []*Order{}
+	if err := m.Orders.NewQuery(notRemovedFilter).Run(&orders); err != nil {
+		return nil, err
+	}
+	coldOrders, err := m.FindAllColdOrders()
+	if err != nil {
+		return nil, err
+	}
+	return append(orders, coldOrders...), nil
+}
+
+// CountStoredOrders returns the number of orders in both the hot and the cold
+// tier, including orders which have been flagged for removal.
+func (m *MeshDB) CountStoredOrders() (int, error) {
+	numHotOrders, err := m.Orders.Count()
+	if err != nil {
+		return 0, err
+	}
+	numColdOrders, err := m.ColdOrders.Count()
+	if err != nil {
+		return 0, err
+	}
+	return numHotOrders + numColdOrders, nil
+}
+
+// sortOrdersByExpirationTimeDescending sorts the given orders by expiration
+// time, with the orders which expire last first.
+func sortOrdersByExpirationTimeDescending(orders []*Order) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].SignedOrder.ExpirationTimeSeconds.Cmp(orders[j].SignedOrder.ExpirationTimeSeconds) == 1
+	})
+}
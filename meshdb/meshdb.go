@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"sort"
 	"time"
 
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/db"
 	"github.com/0xProject/0x-mesh/ethereum"
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/ethereum/go-ethereum/common"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -41,6 +45,38 @@ type Order struct {
 	// IsPinned indicates whether or not the order is pinned. Pinned orders are
 	// not removed from the database unless they become unfillable.
 	IsPinned bool
+	// LastValidatedBlockNumber is the number of the block at which the order was
+	// last validated (i.e., the block at which FillableTakerAssetAmount was
+	// computed).
+	LastValidatedBlockNumber *big.Int
+	// LastValidatedBlockHash is the hash of the block at which the order was last
+	// validated.
+	LastValidatedBlockHash common.Hash
+	// LastValidated is the time at which the order was last validated. Unlike
+	// LastUpdated, it also changes when the order was re-validated but nothing
+	// else about it changed. It is the zero time for orders which have not been
+	// validated since this field was introduced.
+	LastValidated time.Time
+	// Topics are the pubsub topics on which the order has been received. Orders
+	// are keyed by their hash, so an order that is received on multiple topics
+	// (e.g. because of overlapping custom filters) is only stored once and each
+	// topic is associated with it.
+	Topics []string
+	// Annotations are the key-value pairs that the operator-defined order
+	// policy attached to the order when it was added.
+	Annotations map[string]string
+	// RemovedKind, RemovedStatusCode and RemovedStatusMessage describe why the
+	// order was flagged for removal (see ordervalidator.RejectedOrderInfo).
+	// They are used to create a tombstone when the order is permanently
+	// deleted and are empty if IsRemoved is false.
+	RemovedKind          string
+	RemovedStatusCode    string
+	RemovedStatusMessage string
+	// Signer is the peer ID of the node which published and signed the
+	// GossipSub message in which the order was first received. It is empty if
+	// the order was not received through GossipSub or the message was not
+	// signed.
+	Signer string
 }
 
 // ID returns the Order's ID
@@ -61,12 +97,49 @@ func (m Metadata) ID() []byte {
 	return []byte{0}
 }
 
+// Tombstone is the database representation of an order which was permanently
+// deleted. Tombstones allow orders which are re-gossiped by slow peers to be
+// rejected cheaply without revalidating them.
+type Tombstone struct {
+	Hash common.Hash
+	// Kind, StatusCode and StatusMessage are the rejection kind and status
+	// that the order should be rejected with (see
+	// ordervalidator.RejectedOrderInfo).
+	Kind          string
+	StatusCode    string
+	StatusMessage string
+	// ExpiresAt is the time after which the tombstone is ignored and may be
+	// deleted.
+	ExpiresAt time.Time
+}
+
+// ID returns the Tombstone's ID
+func (t Tombstone) ID() []byte {
+	return t.Hash.Bytes()
+}
+
+// PeerReputation is the database representation of the reputation of a peer
+type PeerReputation struct {
+	PeerID peer.ID
+	Stats  reputation.Stats
+}
+
+// ID returns the PeerReputation's ID
+func (r PeerReputation) ID() []byte {
+	return []byte(r.PeerID)
+}
+
 // MeshDB instantiates the DB connection and creates all the collections used by the application
 type MeshDB struct {
 	database                 *db.DB
 	metadata                 *MetadataCollection
+	schemaVersion            *SchemaVersionCollection
+	peerReputations          *PeerReputationsCollection
+	tombstones               *TombstonesCollection
+	blockHistory             *BlockHistoryCollection
 	MiniHeaders              *MiniHeadersCollection
 	Orders                   *OrdersCollection
+	ColdOrders               *ColdOrdersCollection
 	MiniHeaderRetentionLimit int
 }
 
@@ -85,6 +158,11 @@ type OrdersCollection struct {
 	LastUpdatedIndex                             *db.Index
 	IsRemovedIndex                               *db.Index
 	ExpirationTimeIndex                          *db.Index
+	TopicIndex                                   *db.Index
+	TakerAddressIndex                            *db.Index
+	NFTAssetIndex                                *db.Index
+	sortIndexes                                  []*orderSortIndex
+	contractAddresses                            ethereum.ContractAddresses
 }
 
 // MetadataCollection represents a DB collection used to store instance metadata
@@ -92,6 +170,19 @@ type MetadataCollection struct {
 	*db.Collection
 }
 
+// TombstonesCollection represents a DB collection of tombstones for deleted
+// orders
+type TombstonesCollection struct {
+	*db.Collection
+	expiresAtIndex *db.Index
+}
+
+// PeerReputationsCollection represents a DB collection used to store the
+// reputations of peers
+type PeerReputationsCollection struct {
+	*db.Collection
+}
+
 // New instantiates a new MeshDB instance
 func New(path string, contractAddresses ethereum.ContractAddresses) (*MeshDB, error) {
 	database, err := db.Open(path)
@@ -109,25 +200,89 @@ func New(path string, contractAddresses ethereum.ContractAddresses) (*MeshDB, er
 		return nil, err
 	}
 
+	coldOrders, err := setupColdOrders(database)
+	if err != nil {
+		return nil, err
+	}
+
 	metadata, err := setupMetadata(database)
 	if err != nil {
 		return nil, err
 	}
 
-	return &MeshDB{
+	peerReputations, err := setupPeerReputations(database)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones, err := setupTombstones(database)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHistory, err := setupBlockHistory(database)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion, err := setupSchemaVersion(database)
+	if err != nil {
+		return nil, err
+	}
+
+	meshDB := &MeshDB{
 		database:                 database,
 		metadata:                 metadata,
+		schemaVersion:            schemaVersion,
+		peerReputations:          peerReputations,
+		tombstones:               tombstones,
+		blockHistory:             blockHistory,
 		MiniHeaders:              miniHeaders,
 		Orders:                   orders,
+		ColdOrders:               coldOrders,
 		MiniHeaderRetentionLimit: defaultMiniHeaderRetentionLimit,
-	}, nil
+	}
+	if err := meshDB.migrate(); err != nil {
+		database.Close()
+		return nil, err
+	}
+	return meshDB, nil
 }
 
+// orderCompressionDictionary is the preset dictionary used to compress stored
+// orders. It contains a template of the JSON encoding of an Order, followed by
+// the asset data prefixes, token addresses and topics which appear in most
+// orders. Note: The dictionary must never change. Orders which were compressed
+// with a different dictionary cannot be decompressed.
+const orderCompressionDictionary = `"RemovedKind":"","RemovedStatus":{"code":"","message":""}` +
+	`"Annotations":null,"RemovedKind":"","RemovedStatus":null}` +
+	`0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2` +
+	`0xf47261b00000000000000000000000006b175474e89094c44da98b954eedeac495271d0f` +
+	`0xf47261b0000000000000000000000000a0b86991c6218b36c1d19d4a2e9eb0ce3606eb48` +
+	`0x02571792000000000000000000000000` +
+	`0xa7cb5fb7000000000000000000000000` +
+	`0x94cfcdd70000000000000000000000000000000000000000000000000000000000000040` +
+	`0xc339d10a000000000000000000000000` +
+	`"Topics":["/0x-orders/version/3/chain/1/schema/e30="],` +
+	`{"Hash":"0x","SignedOrder":{"chainId":1,"exchangeAddress":"0x61935cbdd02287b511119ddb11aeb42f1593b7ef",` +
+	`"makerAddress":"0x","makerAssetData":"0xf47261b0000000000000000000000000",` +
+	`"makerFeeAssetData":"0x","makerAssetAmount":"000000000000000000","makerFee":"0",` +
+	`"takerAddress":"0x0000000000000000000000000000000000000000",` +
+	`"takerAssetData":"0xf47261b0000000000000000000000000","takerFeeAssetData":"0x",` +
+	`"takerAssetAmount":"000000000000000000","takerFee":"0",` +
+	`"senderAddress":"0x0000000000000000000000000000000000000000",` +
+	`"feeRecipientAddress":"0x0000000000000000000000000000000000000000",` +
+	`"expirationTimeSeconds":"1","salt":"1","signature":"0x1b02"},` +
+	`"LastUpdated":"2020-01-01T00:00:00.000000000Z","FillableTakerAssetAmount":000000000000000000,` +
+	`"IsRemoved":false,"IsPinned":true,"LastValidatedBlockNumber":1,"LastValidatedBlockHash":"0x",` +
+	`"Topics":["/0x-orders/version/3/chain/1/schema/e30="],"Annotations":null,"RemovedKind":"","RemovedStatus":null}`
+
 func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses) (*OrdersCollection, error) {
 	col, err := database.NewCollection("order", &Order{})
 	if err != nil {
 		return nil, err
 	}
+	col.SetCompressionDictionary([]byte(orderCompressionDictionary))
 	lastUpdatedIndex := col.AddIndex("lastUpdated", func(m db.Model) []byte {
 		index := []byte(m.(*Order).LastUpdated.UTC().Format(time.RFC3339Nano))
 		return index
@@ -211,6 +366,27 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 		return []byte(fmt.Sprintf("%s|%s", pinnedString, expTimeString))
 	})
 
+	topicIndex := col.AddMultiIndex("topic", func(m db.Model) [][]byte {
+		order := m.(*Order)
+		indexValues := make([][]byte, len(order.Topics))
+		for i, topic := range order.Topics {
+			indexValues[i] = []byte(topic)
+		}
+		return indexValues
+	})
+
+	takerAddressIndex := col.AddIndex("takerAddress", func(m db.Model) []byte {
+		// Orders which can be filled by anyone have the null address as their
+		// takerAddress.
+		return []byte(m.(*Order).SignedOrder.TakerAddress.Hex())
+	})
+
+	nftAssetIndex := col.AddMultiIndex("nftAsset", func(m db.Model) [][]byte {
+		return nftAssetIndexValues(m.(*Order), contractAddresses)
+	})
+
+	sortIndexes := addOrderSortIndexes(col)
+
 	return &OrdersCollection{
 		Collection:                                   col,
 		MakerAddressTokenAddressTokenIDIndex:         makerAddressTokenAddressTokenIDIndex,
@@ -219,6 +395,11 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 		LastUpdatedIndex:                             lastUpdatedIndex,
 		IsRemovedIndex:                               isRemovedIndex,
 		ExpirationTimeIndex:                          expirationTimeIndex,
+		TopicIndex:                                   topicIndex,
+		TakerAddressIndex:                            takerAddressIndex,
+		NFTAssetIndex:                                nftAssetIndex,
+		sortIndexes:                                  sortIndexes,
+		contractAddresses:                            contractAddresses,
 	}, nil
 }
 
@@ -250,11 +431,63 @@ func setupMetadata(database *db.DB) (*MetadataCollection, error) {
 	return &MetadataCollection{col}, nil
 }
 
+func setupTombstones(database *db.DB) (*TombstonesCollection, error) {
+	col, err := database.NewCollection("tombstone", &Tombstone{})
+	if err != nil {
+		return nil, err
+	}
+	expiresAtIndex := col.AddIndex("expiresAt", func(m db.Model) []byte {
+		return uint256ToConstantLengthBytes(big.NewInt(m.(*Tombstone).ExpiresAt.Unix()))
+	})
+	return &TombstonesCollection{
+		Collection:     col,
+		expiresAtIndex: expiresAtIndex,
+	}, nil
+}
+
+func setupPeerReputations(database *db.DB) (*PeerReputationsCollection, error) {
+	col, err := database.NewCollection("peerReputation", &PeerReputation{})
+	if err != nil {
+		return nil, err
+	}
+	return &PeerReputationsCollection{col}, nil
+}
+
 // Close closes the database connection
 func (m *MeshDB) Close() {
 	m.database.Close()
 }
 
+// ApproximateSize returns the approximate amount of storage space used by the
+// database in bytes.
+func (m *MeshDB) ApproximateSize() (int64, error) {
+	return m.database.ApproximateSize()
+}
+
+// Compact compacts the entire database in order to reclaim the disk space used
+// by deleted and overwritten entries (see db.DB.Compact).
+func (m *MeshDB) Compact() (*db.CompactionResult, error) {
+	return m.database.Compact()
+}
+
+// WriteCheckpoint writes a consistent copy of the entire database to w. It can
+// be restored with db.RestoreCheckpoint.
+func (m *MeshDB) WriteCheckpoint(w io.Writer) error {
+	return m.database.WriteCheckpoint(w)
+}
+
+// SetSlowQueryThreshold sets the duration after which a query is logged as
+// slow. A threshold of 0 disables slow query logging.
+func (m *MeshDB) SetSlowQueryThreshold(threshold time.Duration) {
+	m.database.SetSlowQueryThreshold(threshold)
+}
+
+// SlowQueryCount returns the number of slow queries since the database was
+// opened.
+func (m *MeshDB) SlowQueryCount() uint64 {
+	return m.database.SlowQueryCount()
+}
+
 // FindAllMiniHeadersSortedByNumber returns all MiniHeaders sorted in ascending block number order
 func (m *MeshDB) FindAllMiniHeadersSortedByNumber() ([]*miniheader.MiniHeader, error) {
 	miniHeaders := []*miniheader.MiniHeader{}
@@ -312,8 +545,9 @@ func (m *MeshDB) FindMiniHeaderByBlockNumber(blockNumber *big.Int) (*miniheader.
 	return miniHeaders[0], nil
 }
 
-// UpdateMiniHeaderRetentionLimit updates the MiniHeaderRetentionLimit. This is only used by tests in order
-// to set the retention limit to a smaller size, making the tests shorter in length
+// UpdateMiniHeaderRetentionLimit updates the MiniHeaderRetentionLimit. It is used to tune the retention
+// limit for chains with deeper re-orgs, and by tests in order to set the retention limit to a smaller
+// size, making the tests shorter in length
 func (m *MeshDB) UpdateMiniHeaderRetentionLimit(limit int) error {
 	m.MiniHeaderRetentionLimit = limit
 	return m.PruneMiniHeadersAboveRetentionLimit()
@@ -481,6 +715,299 @@ func (m *MeshDB) FindRemovedOrders() ([]*Order, error) {
 	return removedOrders, nil
 }
 
+// AddTombstones inserts the given tombstones into the database, overwriting any
+// existing tombstones for the same orders.
+func (m *MeshDB) AddTombstones(tombstones []*Tombstone) error {
+	if len(tombstones) == 0 {
+		return nil
+	}
+	txn := m.tombstones.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	// Each order can only be affected by one operation per transaction, so if
+	// there are multiple tombstones for the same order, only the last one is
+	// kept.
+	byHash := map[common.Hash]*Tombstone{}
+	for _, tombstone := range tombstones {
+		byHash[tombstone.Hash] = tombstone
+	}
+	for _, tombstone := range byHash {
+		var existing Tombstone
+		if err := m.tombstones.FindByID(tombstone.ID(), &existing); err == nil {
+			if err := txn.Update(tombstone); err != nil {
+				return err
+			}
+			continue
+		} else if _, ok := err.(db.NotFoundError); !ok {
+			return err
+		}
+		if err := txn.Insert(tombstone); err != nil {
+			return err
+		}
+	}
+
+	return txn.Commit()
+}
+
+// FindTombstone returns the tombstone for the order with the given hash. It
+// returns nil if there is no tombstone or if the tombstone has expired.
+func (m *MeshDB) FindTombstone(orderHash common.Hash, now time.Time) (*Tombstone, error) {
+	var tombstone Tombstone
+	if err := m.tombstones.FindByID(orderHash.Bytes(), &tombstone); err != nil {
+		if _, ok := err.(db.NotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !tombstone.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &tombstone, nil
+}
+
+// DeleteExpiredTombstones deletes all tombstones which expired before the
+// given time and returns the number of tombstones that were deleted.
+func (m *MeshDB) DeleteExpiredTombstones(now time.Time) (int, error) {
+	filter := m.tombstones.expiresAtIndex.RangeFilter(
+		uint256ToConstantLengthBytes(big.NewInt(0)),
+		uint256ToConstantLengthBytes(big.NewInt(now.Unix())),
+	)
+	var expired []*Tombstone
+	if err := m.tombstones.NewQuery(filter).Run(&expired); err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+	txn := m.tombstones.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	for _, tombstone := range expired {
+		if err := txn.Delete(tombstone.ID()); err != nil {
+			return 0, err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// FindOrdersByTopic finds all orders that have been received on the given
+// pubsub topic.
+func (m *MeshDB) FindOrdersByTopic(topic string) ([]*Order, error) {
+	filter := m.Orders.TopicIndex.ValueFilter([]byte(topic))
+	orders := []*Order{}
+	if err := m.Orders.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// CountOrdersByTopic returns the number of orders in both the hot and the
+// cold tier that have been received on the given pubsub topic.
+func (m *MeshDB) CountOrdersByTopic(topic string) (int, error) {
+	filter := m.Orders.TopicIndex.ValueFilter([]byte(topic))
+	numHotOrders, err := m.Orders.NewQuery(filter).Count()
+	if err != nil {
+		return 0, err
+	}
+	coldFilter := m.ColdOrders.TopicIndex.ValueFilter([]byte(topic))
+	numColdOrders, err := m.ColdOrders.NewQuery(coldFilter).Count()
+	if err != nil {
+		return 0, err
+	}
+	return numHotOrders + numColdOrders, nil
+}
+
+// OrderQuery is a read-only query against one of the indexes of the orders
+// collection. It is an escape hatch for advanced users (e.g. dApps running Mesh
+// in the browser) who need to look up orders in ways which aren't supported by
+// the other methods. Index values are strings with the following formats:
+//
+//   - "makerAddressAndSalt": "<maker address>|<salt padded to 80 digits>"
+//   - "makerAddressTokenAddressTokenId": "<maker address>|<token address>|<token ID bytes>"
+//   - "makerAddressMakerFeeAssetAddressTokenID": "<maker address>|<fee token address>|<token ID bytes>"
+//   - "lastUpdated": "<RFC3339Nano timestamp in UTC>"
+//   - "expirationTime": "<0 or 1 for pinned orders>|<expiration time padded to 80 digits>"
+//   - "topic": "<pubsub topic>"
+//   - "takerAddress": "<taker address>" (the null address for orders which can be filled by anyone)
+//   - "nftAsset": "<ask, bid or any>|<ERC721 or ERC1155 contract address>|<token ID padded to 80 digits>"
+//
+// Addresses use the checksummed hex format. At most one of Value, Prefix and
+// Start/Limit may be set. If none of them are set, all orders are matched.
+type OrderQuery struct {
+	// Index is the name of the index to query.
+	Index string `json:"index"`
+	// Value matches orders with exactly this index value.
+	Value string `json:"value,omitempty"`
+	// Prefix matches orders with an index value that starts with this prefix.
+	Prefix string `json:"prefix,omitempty"`
+	// Start and Limit match orders with an index value in the range
+	// [Start, Limit). They must be set together.
+	Start string `json:"start,omitempty"`
+	Limit string `json:"limit,omitempty"`
+	// Max is the maximum number of orders to return. 0 means no maximum.
+	Max int `json:"max,omitempty"`
+	// Offset is the number of matching orders to skip.
+	Offset int `json:"offset,omitempty"`
+	// Reverse returns orders in descending instead of ascending index order.
+	Reverse bool `json:"reverse,omitempty"`
+}
+
+// queryableOrderIndexes returns the indexes which can be used in an
+// OrderQuery, keyed by name. The isRemoved index is not exposed since removed
+// orders are never returned.
+func (m *MeshDB) queryableOrderIndexes() map[string]*db.Index {
+	indexes := map[string]*db.Index{}
+	for _, index := range []*db.Index{
+		m.Orders.MakerAddressAndSaltIndex,
+		m.Orders.MakerAddressTokenAddressTokenIDIndex,
+		m.Orders.MakerAddressMakerFeeAssetAddressTokenIDIndex,
+		m.Orders.LastUpdatedIndex,
+		m.Orders.ExpirationTimeIndex,
+		m.Orders.TopicIndex,
+		m.Orders.TakerAddressIndex,
+		m.Orders.NFTAssetIndex,
+	} {
+		indexes[index.Name()] = index
+	}
+	return indexes
+}
+
+// QueryOrders runs the given read-only query and returns the matching orders.
+// Only the orders collection is queried, so cold orders are not returned.
+// Orders which have been flagged for removal are not returned. Since they are
+// filtered out after running the query, fewer than query.Max orders may be
+// returned even if there are more matching orders.
+func (m *MeshDB) QueryOrders(query *OrderQuery) ([]*Order, error) {
+	index, found := m.queryableOrderIndexes()[query.Index]
+	if !found {
+		return nil, fmt.Errorf("unknown or unsupported order index: %q", query.Index)
+	}
+	hasRange := query.Start != "" || query.Limit != ""
+	numConditions := 0
+	for _, isSet := range []bool{query.Value != "", query.Prefix != "", hasRange} {
+		if isSet {
+			numConditions++
+		}
+	}
+	if numConditions > 1 {
+		return nil, errors.New("at most one of value, prefix and start/limit can be set")
+	}
+	if hasRange && (query.Start == "" || query.Limit == "") {
+		return nil, errors.New("start and limit must be set together")
+	}
+	if query.Max < 0 || query.Offset < 0 {
+		return nil, errors.New("max and offset cannot be negative")
+	}
+
+	var filter *db.Filter
+	switch {
+	case query.Value != "":
+		filter = index.ValueFilter([]byte(query.Value))
+	case query.Prefix != "":
+		filter = index.PrefixFilter([]byte(query.Prefix))
+	case hasRange:
+		filter = index.RangeFilter([]byte(query.Start), []byte(query.Limit))
+	default:
+		filter = index.All()
+	}
+	dbQuery := m.Orders.NewQuery(filter).Offset(query.Offset)
+	if query.Max > 0 {
+		dbQuery = dbQuery.Max(query.Max)
+	}
+	if query.Reverse {
+		dbQuery = dbQuery.Reverse()
+	}
+	var orders []*Order
+	if err := dbQuery.Run(&orders); err != nil {
+		return nil, err
+	}
+	notRemovedOrders := []*Order{}
+	for _, order := range orders {
+		if !order.IsRemoved {
+			notRemovedOrders = append(notRemovedOrders, order)
+		}
+	}
+	return notRemovedOrders, nil
+}
+
+// AddOrderTopics associates the given pubsub topics with the stored order with
+// the given hash. Topics which are already associated with the order are
+// ignored. The order is updated in whichever tier it is stored in. It returns
+// a db.NotFoundError if the order is not stored.
+func (m *MeshDB) AddOrderTopics(orderHash common.Hash, topics []string) error {
+	col, _, err := m.findOrderAndCollection(orderHash)
+	if err != nil {
+		return err
+	}
+	txn := col.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	// The order is found again after opening the transaction in case it was
+	// changed or moved to the other tier in the meantime.
+	var order Order
+	if err := col.FindByID(orderHash.Bytes(), &order); err != nil {
+		return err
+	}
+	updated := false
+	for _, topic := range topics {
+		if !containsString(order.Topics, topic) {
+			order.Topics = append(order.Topics, topic)
+			updated = true
+		}
+	}
+	if !updated {
+		return nil
+	}
+	if err := txn.Update(&order); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+// SetOrderSigner records the peer ID of the node which signed the GossipSub
+// message in which the stored order with the given hash was received. The
+// signer is only recorded once, so it is ignored if the order already has one.
+// The order is updated in whichever tier it is stored in. It returns a
+// db.NotFoundError if the order is not stored.
+func (m *MeshDB) SetOrderSigner(orderHash common.Hash, signer peer.ID) error {
+	col, _, err := m.findOrderAndCollection(orderHash)
+	if err != nil {
+		return err
+	}
+	txn := col.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	var order Order
+	if err := col.FindByID(orderHash.Bytes(), &order); err != nil {
+		return err
+	}
+	if order.Signer != "" {
+		return nil
+	}
+	order.Signer = signer.Pretty()
+	if err := txn.Update(&order); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 // GetMetadata returns the metadata (or a db.NotFoundError if no metadata has been found).
 func (m *MeshDB) GetMetadata() (*Metadata, error) {
 	var metadata Metadata
@@ -520,6 +1047,59 @@ func (m *MeshDB) UpdateMetadata(updater func(oldmetadata Metadata) (newMetadata
 	return txn.Commit()
 }
 
+// LoadPeerReputations returns the stored reputations of all peers. It
+// implements reputation.Store.
+func (m *MeshDB) LoadPeerReputations() (map[peer.ID]*reputation.Stats, error) {
+	var peerReputations []*PeerReputation
+	if err := m.peerReputations.FindAll(&peerReputations); err != nil {
+		return nil, err
+	}
+	stats := make(map[peer.ID]*reputation.Stats, len(peerReputations))
+	for _, peerReputation := range peerReputations {
+		peerStats := peerReputation.Stats
+		stats[peerReputation.PeerID] = &peerStats
+	}
+	return stats, nil
+}
+
+// SavePeerReputations replaces the stored reputations with the given ones via
+// a transaction. It implements reputation.Store.
+func (m *MeshDB) SavePeerReputations(stats map[peer.ID]*reputation.Stats) error {
+	txn := m.peerReputations.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	var existing []*PeerReputation
+	if err := m.peerReputations.FindAll(&existing); err != nil {
+		return err
+	}
+	existingIDs := make(map[peer.ID]struct{}, len(existing))
+	for _, peerReputation := range existing {
+		existingIDs[peerReputation.PeerID] = struct{}{}
+		if _, found := stats[peerReputation.PeerID]; !found {
+			if err := txn.Delete(peerReputation.ID()); err != nil {
+				return err
+			}
+		}
+	}
+	for id, peerStats := range stats {
+		peerReputation := &PeerReputation{
+			PeerID: id,
+			Stats:  *peerStats,
+		}
+		if _, found := existingIDs[id]; found {
+			if err := txn.Update(peerReputation); err != nil {
+				return err
+			}
+		} else if err := txn.Insert(peerReputation); err != nil {
+			return err
+		}
+	}
+
+	return txn.Commit()
+}
+
 type singleAssetData struct {
 	Address common.Address
 	TokenID *big.Int
@@ -619,18 +1199,26 @@ func uint256ToConstantLengthBytes(v *big.Int) []byte {
 
 // TrimOrdersByExpirationTime removes existing orders with the highest
 // expiration time until the number of remaining orders is <= targetMaxOrders.
-// It returns any orders that were removed and the new max expiration time that
-// can be used to eliminate incoming orders that expire too far in the future.
-func (m *MeshDB) TrimOrdersByExpirationTime(targetMaxOrders int) (newMaxExpirationTime *big.Int, removedOrders []*Order, err error) {
-	txn := m.Orders.OpenTransaction()
+// Orders in both the hot and the cold tier are considered. Orders which have
+// been received on one of excludedTopics are neither counted nor removed since
+// they are subject to a separate quota (see TrimOrdersByTopic). It returns any
+// orders that were removed and the new max expiration time that can be used to
+// eliminate incoming orders that expire too far in the future.
+func (m *MeshDB) TrimOrdersByExpirationTime(targetMaxOrders int, excludedTopics []string) (newMaxExpirationTime *big.Int, removedOrders []*Order, err error) {
+	txn := m.database.OpenGlobalTransaction()
 	defer func() {
 		_ = txn.Discard()
 	}()
 
-	numOrders, err := m.Orders.Count()
+	excludedOrderHashes, err := m.findOrderHashesByTopics(excludedTopics)
 	if err != nil {
 		return nil, nil, err
 	}
+	numOrders, err := m.CountStoredOrders()
+	if err != nil {
+		return nil, nil, err
+	}
+	numOrders -= len(excludedOrderHashes)
 	if numOrders <= targetMaxOrders {
 		// If the number of orders is less than the target, we don't need to remove
 		// any orders. Return UnlimitedExpirationTime.
@@ -638,16 +1226,41 @@ func (m *MeshDB) TrimOrdersByExpirationTime(targetMaxOrders int) (newMaxExpirati
 	}
 
 	// Find the orders which we need to remove. We use a prefix filter of "0|: so
-	// that we only remove non-pinned orders.
+	// that we only remove non-pinned orders. Excluded orders are skipped, so we
+	// need to find up to that many additional orders.
 	filter := m.Orders.ExpirationTimeIndex.PrefixFilter([]byte("0|"))
 	numOrdersToRemove := numOrders - targetMaxOrders
-	if err := m.Orders.NewQuery(filter).Reverse().Max(numOrdersToRemove).Run(&removedOrders); err != nil {
+	maxCandidates := numOrdersToRemove + len(excludedOrderHashes)
+	var hotOrders []*Order
+	if err := m.Orders.NewQuery(filter).Reverse().Max(maxCandidates).Run(&hotOrders); err != nil {
+		return nil, nil, err
+	}
+	// Cold orders are never pinned, so all of them can be removed.
+	var coldOrders []*Order
+	if err := m.ColdOrders.NewQuery(m.ColdOrders.ExpirationTimeIndex.All()).Reverse().Max(maxCandidates).Run(&coldOrders); err != nil {
 		return nil, nil, err
 	}
+	coldOrderHashes := map[common.Hash]struct{}{}
+	for _, order := range coldOrders {
+		coldOrderHashes[order.Hash] = struct{}{}
+	}
+	for _, order := range append(hotOrders, coldOrders...) {
+		if _, isExcluded := excludedOrderHashes[order.Hash]; !isExcluded {
+			removedOrders = append(removedOrders, order)
+		}
+	}
+	sortOrdersByExpirationTimeDescending(removedOrders)
+	if len(removedOrders) > numOrdersToRemove {
+		removedOrders = removedOrders[:numOrdersToRemove]
+	}
 
 	// Remove those orders and commit the transaction.
 	for _, order := range removedOrders {
-		if err := txn.Delete(order.Hash.Bytes()); err != nil {
+		col := m.Orders.Collection
+		if _, isCold := coldOrderHashes[order.Hash]; isCold {
+			col = m.ColdOrders.Collection
+		}
+		if err := txn.Delete(col, order.Hash.Bytes()); err != nil {
 			return nil, nil, err
 		}
 	}
@@ -679,3 +1292,209 @@ func (m *MeshDB) CountPinnedOrders() (int, error) {
 	filter := m.Orders.ExpirationTimeIndex.PrefixFilter([]byte("1|"))
 	return m.Orders.NewQuery(filter).Count()
 }
+
+// MakerAggregate contains aggregate information about the open orders of a
+// single maker.
+type MakerAggregate struct {
+	MakerAddress common.Address
+	// NumOrders is the number of orders which have not been flagged for
+	// removal.
+	NumOrders int
+	// MakerAssetAmounts is the sum of the MakerAssetAmount of each order, keyed
+	// by the hex encoded maker asset data.
+	MakerAssetAmounts map[string]*big.Int
+	// LastActivity is the most recent time at which any of the orders was
+	// updated. It is the zero time if the maker has no orders.
+	LastActivity time.Time
+}
+
+// FindMakerAggregates returns aggregate information about the open orders of
+// each of the given makers. If no makers are given, it returns aggregates for
+// every maker with at least one open order. The results are sorted by maker
+// address.
+func (m *MeshDB) FindMakerAggregates(makerAddresses []common.Address) ([]*MakerAggregate, error) {
+	aggregates := map[common.Address]*MakerAggregate{}
+	if len(makerAddresses) == 0 {
+		orders, err := m.FindNotRemovedOrders()
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			addToMakerAggregates(aggregates, order)
+		}
+	} else {
+		for _, makerAddress := range makerAddresses {
+			if _, found := aggregates[makerAddress]; found {
+				continue
+			}
+			aggregates[makerAddress] = newMakerAggregate(makerAddress)
+			// Every order has exactly one entry in the makerAddressAndSalt index,
+			// so there is no need to deduplicate the results.
+			prefix := []byte(makerAddress.Hex() + "|")
+			filter := m.Orders.MakerAddressAndSaltIndex.PrefixFilter(prefix)
+			orders := []*Order{}
+			if err := m.Orders.NewQuery(filter).Run(&orders); err != nil {
+				return nil, err
+			}
+			coldFilter := m.ColdOrders.MakerAddressIndex.ValueFilter([]byte(makerAddress.Hex()))
+			coldOrders := []*Order{}
+			if err := m.ColdOrders.NewQuery(coldFilter).Run(&coldOrders); err != nil {
+				return nil, err
+			}
+			for _, order := range append(orders, coldOrders...) {
+				if order.IsRemoved {
+					continue
+				}
+				addToMakerAggregates(aggregates, order)
+			}
+		}
+	}
+
+	results := make([]*MakerAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		results = append(results, aggregate)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return bytes.Compare(results[i].MakerAddress.Bytes(), results[j].MakerAddress.Bytes()) == -1
+	})
+	return results, nil
+}
+
+func newMakerAggregate(makerAddress common.Address) *MakerAggregate {
+	return &MakerAggregate{
+		MakerAddress:      makerAddress,
+		MakerAssetAmounts: map[string]*big.Int{},
+	}
+}
+
+func addToMakerAggregates(aggregates map[common.Address]*MakerAggregate, order *Order) {
+	makerAddress := order.SignedOrder.MakerAddress
+	aggregate, found := aggregates[makerAddress]
+	if !found {
+		aggregate = newMakerAggregate(makerAddress)
+		aggregates[makerAddress] = aggregate
+	}
+	aggregate.NumOrders++
+	assetData := common.ToHex(order.SignedOrder.MakerAssetData)
+	total, found := aggregate.MakerAssetAmounts[assetData]
+	if !found {
+		total = big.NewInt(0)
+		aggregate.MakerAssetAmounts[assetData] = total
+	}
+	total.Add(total, order.SignedOrder.MakerAssetAmount)
+	if order.LastUpdated.After(aggregate.LastActivity) {
+		aggregate.LastActivity = order.LastUpdated
+	}
+}
+
+// MarketAggregate contains aggregate information about the open orders for a
+// single trading pair. Orders which sell the base asset are asks and orders
+// which sell the quote asset are bids. Prices are the amount of the quote
+// asset per unit of the base asset, both in base units.
+type MarketAggregate struct {
+	BaseAssetData  []byte
+	QuoteAssetData []byte
+	NumBids        int
+	NumAsks        int
+	// BestBid is the highest price of any bid. It is nil if there are no bids.
+	BestBid *big.Rat
+	// BestAsk is the lowest price of any ask. It is nil if there are no asks.
+	BestAsk *big.Rat
+	// BidSize is the remaining fillable amount of the base asset of all bids.
+	BidSize *big.Int
+	// AskSize is the remaining fillable amount of the base asset of all asks.
+	AskSize *big.Int
+}
+
+// FindMarketAggregates returns aggregate information about the open orders
+// for each pair of maker and taker asset data. Since asset data doesn't say
+// which asset is conventionally quoted in terms of the other, the base asset
+// of each market is the one whose asset data sorts first. The results are
+// sorted by base asset data and then quote asset data.
+func (m *MeshDB) FindMarketAggregates() ([]*MarketAggregate, error) {
+	orders, err := m.FindNotRemovedOrders()
+	if err != nil {
+		return nil, err
+	}
+	aggregates := map[string]*MarketAggregate{}
+	for _, order := range orders {
+		addToMarketAggregates(aggregates, order)
+	}
+
+	results := make([]*MarketAggregate, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		results = append(results, aggregate)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if c := bytes.Compare(results[i].BaseAssetData, results[j].BaseAssetData); c != 0 {
+			return c == -1
+		}
+		return bytes.Compare(results[i].QuoteAssetData, results[j].QuoteAssetData) == -1
+	})
+	return results, nil
+}
+
+// orderMarketAndPrice returns the base and quote asset data of the market of
+// the given order, its price and whether it is an ask. ok is false if the
+// price of the order is undefined or it doesn't trade one asset for another.
+func orderMarketAndPrice(order *Order) (baseAssetData, quoteAssetData []byte, price *big.Rat, isAsk bool, ok bool) {
+	signedOrder := order.SignedOrder
+	if signedOrder.MakerAssetAmount.Sign() == 0 || signedOrder.TakerAssetAmount.Sign() == 0 {
+		return nil, nil, nil, false, false
+	}
+	comparison := bytes.Compare(signedOrder.MakerAssetData, signedOrder.TakerAssetData)
+	if comparison == 0 {
+		return nil, nil, nil, false, false
+	}
+	isAsk = comparison == -1
+	if isAsk {
+		price = new(big.Rat).SetFrac(signedOrder.TakerAssetAmount, signedOrder.MakerAssetAmount)
+		return signedOrder.MakerAssetData, signedOrder.TakerAssetData, price, true, true
+	}
+	price = new(big.Rat).SetFrac(signedOrder.MakerAssetAmount, signedOrder.TakerAssetAmount)
+	return signedOrder.TakerAssetData, signedOrder.MakerAssetData, price, false, true
+}
+
+func marketKey(baseAssetData, quoteAssetData []byte) string {
+	return common.ToHex(baseAssetData) + "|" + common.ToHex(quoteAssetData)
+}
+
+func addToMarketAggregates(aggregates map[string]*MarketAggregate, order *Order) {
+	if order.FillableTakerAssetAmount == nil {
+		return
+	}
+	baseAssetData, quoteAssetData, price, isAsk, ok := orderMarketAndPrice(order)
+	if !ok {
+		return
+	}
+	signedOrder := order.SignedOrder
+	key := marketKey(baseAssetData, quoteAssetData)
+	aggregate, found := aggregates[key]
+	if !found {
+		aggregate = &MarketAggregate{
+			BaseAssetData:  baseAssetData,
+			QuoteAssetData: quoteAssetData,
+			BidSize:        big.NewInt(0),
+			AskSize:        big.NewInt(0),
+		}
+		aggregates[key] = aggregate
+	}
+
+	if isAsk {
+		aggregate.NumAsks++
+		if aggregate.BestAsk == nil || price.Cmp(aggregate.BestAsk) == -1 {
+			aggregate.BestAsk = price
+		}
+		// The remaining fillable amount of the maker (i.e. base) asset is
+		// proportional to the remaining fillable amount of the taker asset.
+		size := new(big.Int).Mul(order.FillableTakerAssetAmount, signedOrder.MakerAssetAmount)
+		size.Div(size, signedOrder.TakerAssetAmount)
+		aggregate.AskSize.Add(aggregate.AskSize, size)
+	} else {
+		aggregate.NumBids++
+		if aggregate.BestBid == nil || price.Cmp(aggregate.BestBid) == 1 {
+			aggregate.BestBid = price
+		}
+		aggregate.BidSize.Add(aggregate.BidSize, order.FillableTakerAssetAmount)
+	}
+}
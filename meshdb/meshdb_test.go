@@ -1,6 +1,8 @@
 package meshdb
 
 import (
+	"crypto/rand"
+	"fmt"
 	"math/big"
 	"testing"
 	"time"
@@ -9,9 +11,12 @@ import (
 	"github.com/0xProject/0x-mesh/db"
 	"github.com/0xProject/0x-mesh/ethereum"
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -268,7 +273,7 @@ func TestTrimOrdersByExpirationTime(t *testing.T) {
 
 	// Call CalculateNewMaxExpirationTimeAndTrimDatabase and check the results.
 	targetMaxOrders := 4
-	gotExpirationTime, gotRemovedOrders, err := meshDB.TrimOrdersByExpirationTime(targetMaxOrders)
+	gotExpirationTime, gotRemovedOrders, err := meshDB.TrimOrdersByExpirationTime(targetMaxOrders, nil)
 	require.NoError(t, err)
 	assert.Equal(t, "199", gotExpirationTime.String(), "newMaxExpirationTime")
 	assert.Len(t, gotRemovedOrders, 2, "wrong number of orders removed")
@@ -300,10 +305,82 @@ func TestTrimOrdersByExpirationTime(t *testing.T) {
 
 	// Trying to trim orders when the database is full of pinned orders should
 	// return an error.
-	_, _, err = meshDB.TrimOrdersByExpirationTime(1)
+	_, _, err = meshDB.TrimOrdersByExpirationTime(1, nil)
 	assert.EqualError(t, err, ErrDBFilledWithPinnedOrders.Error(), "expected ErrFilledWithPinnedOrders when targetMaxOrders is less than the number of pinned orders")
 }
 
+func TestTrimOrdersByTopic(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	newRawOrder := func(salt int64, expirationTime int64) *zeroex.Order {
+		return &zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(salt),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(expirationTime),
+			ExchangeAddress:       contractAddresses.Exchange,
+		}
+	}
+	// The busy topic has more orders, all of which expire later than the orders
+	// on the quiet topic.
+	busyOrders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		newRawOrder(0, 500),
+		newRawOrder(1, 400),
+		newRawOrder(2, 300),
+	}, false)
+	pinnedBusyOrders := insertRawOrders(t, meshDB, []*zeroex.Order{newRawOrder(3, 600)}, true)
+	quietOrders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		newRawOrder(4, 200),
+		newRawOrder(5, 100),
+	}, false)
+	for _, order := range append(busyOrders, pinnedBusyOrders...) {
+		require.NoError(t, meshDB.AddOrderTopics(order.Hash, []string{"busy"}))
+	}
+	for _, order := range quietOrders {
+		require.NoError(t, meshDB.AddOrderTopics(order.Hash, []string{"quiet"}))
+	}
+
+	numOrders, err := meshDB.CountStoredOrdersExcludingTopics([]string{"quiet"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, numOrders)
+
+	// Trimming the busy topic only removes unpinned orders on that topic.
+	removedOrders, err := meshDB.TrimOrdersByTopic("busy", 2)
+	require.NoError(t, err)
+	require.Len(t, removedOrders, 2)
+	assert.Equal(t, busyOrders[0].Hash, removedOrders[0].Hash)
+	assert.Equal(t, busyOrders[1].Hash, removedOrders[1].Hash)
+	numOrders, err = meshDB.CountOrdersByTopic("quiet")
+	require.NoError(t, err)
+	assert.Equal(t, 2, numOrders)
+	removedOrders, err = meshDB.TrimOrdersByTopic("busy", 2)
+	require.NoError(t, err)
+	assert.Empty(t, removedOrders)
+
+	// Trimming by expiration time neither counts nor removes orders on excluded
+	// topics.
+	_, removedOrders, err = meshDB.TrimOrdersByExpirationTime(1, []string{"quiet"})
+	require.NoError(t, err)
+	require.Len(t, removedOrders, 1)
+	assert.Equal(t, busyOrders[2].Hash, removedOrders[0].Hash)
+	numOrders, err = meshDB.CountOrdersByTopic("quiet")
+	require.NoError(t, err)
+	assert.Equal(t, 2, numOrders)
+}
+
 func TestFindOrdersByMakerAddressMakerFeeAssetAddressTokenID(t *testing.T) {
 	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
 	require.NoError(t, err)
@@ -449,6 +526,440 @@ func TestFindOrdersByMakerAddressMakerFeeAssetAddressTokenID(t *testing.T) {
 	}
 }
 
+func TestAddOrderTopics(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	rawOrders := []*zeroex.Order{
+		{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001"),
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(1548619145450),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		},
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	orderHash := orders[0].Hash
+
+	require.NoError(t, meshDB.AddOrderTopics(orderHash, []string{"topicA"}))
+	// Adding the same topic twice should be a no-op.
+	require.NoError(t, meshDB.AddOrderTopics(orderHash, []string{"topicA", "topicB"}))
+
+	var foundOrder Order
+	require.NoError(t, meshDB.Orders.FindByID(orderHash.Bytes(), &foundOrder))
+	assert.Equal(t, []string{"topicA", "topicB"}, foundOrder.Topics)
+
+	for _, topic := range []string{"topicA", "topicB"} {
+		foundOrders, err := meshDB.FindOrdersByTopic(topic)
+		require.NoError(t, err)
+		require.Len(t, foundOrders, 1, "topic: %s", topic)
+		assert.Equal(t, orderHash, foundOrders[0].Hash)
+		count, err := meshDB.CountOrdersByTopic(topic)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, "topic: %s", topic)
+	}
+	foundOrders, err := meshDB.FindOrdersByTopic("topicC")
+	require.NoError(t, err)
+	assert.Len(t, foundOrders, 0)
+	topicCCount, err := meshDB.CountOrdersByTopic("topicC")
+	require.NoError(t, err)
+	assert.Equal(t, 0, topicCCount)
+
+	// The order count should be unaffected by the number of topics.
+	count, err := meshDB.Orders.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	err = meshDB.AddOrderTopics(common.HexToHash("0x1"), []string{"topicA"})
+	assert.IsType(t, db.NotFoundError{}, err)
+}
+
+func TestSetOrderSigner(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	rawOrders := []*zeroex.Order{
+		{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000038ae374ecf4db50b0ff37125b591a04997106a32"),
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(1548619145450),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		},
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	orderHash := orders[0].Hash
+
+	signer := newTestPeerID(t)
+	require.NoError(t, meshDB.SetOrderSigner(orderHash, signer))
+	// The signer is only recorded once.
+	require.NoError(t, meshDB.SetOrderSigner(orderHash, newTestPeerID(t)))
+
+	var foundOrder Order
+	require.NoError(t, meshDB.Orders.FindByID(orderHash.Bytes(), &foundOrder))
+	assert.Equal(t, signer.Pretty(), foundOrder.Signer)
+
+	err = meshDB.SetOrderSigner(common.HexToHash("0x1"), signer)
+	assert.IsType(t, db.NotFoundError{}, err)
+}
+
+func TestQueryOrders(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	rawOrders := make([]*zeroex.Order, 3)
+	for i := range rawOrders {
+		rawOrders[i] = &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	// Removed orders should never be returned.
+	orders[2].IsRemoved = true
+	require.NoError(t, meshDB.Orders.Update(orders[2]))
+
+	makerPrefix := constants.GanacheAccount0.Hex() + "|"
+	testCases := []struct {
+		query          *OrderQuery
+		expectedOrders []*Order
+	}{
+		{
+			query:          &OrderQuery{Index: "makerAddressAndSalt"},
+			expectedOrders: orders[:2],
+		},
+		{
+			query:          &OrderQuery{Index: "makerAddressAndSalt", Prefix: makerPrefix, Reverse: true},
+			expectedOrders: []*Order{orders[1], orders[0]},
+		},
+		{
+			query:          &OrderQuery{Index: "makerAddressAndSalt", Prefix: makerPrefix, Offset: 1, Max: 1},
+			expectedOrders: orders[1:2],
+		},
+		{
+			query: &OrderQuery{
+				Index: "makerAddressAndSalt",
+				Value: fmt.Sprintf("%s|%s", constants.GanacheAccount0.Hex(), uint256ToConstantLengthBytes(big.NewInt(1))),
+			},
+			expectedOrders: orders[1:2],
+		},
+		{
+			query: &OrderQuery{
+				Index: "makerAddressAndSalt",
+				Start: fmt.Sprintf("%s|%s", constants.GanacheAccount0.Hex(), uint256ToConstantLengthBytes(big.NewInt(0))),
+				Limit: fmt.Sprintf("%s|%s", constants.GanacheAccount0.Hex(), uint256ToConstantLengthBytes(big.NewInt(1))),
+			},
+			expectedOrders: orders[:1],
+		},
+		{
+			query:          &OrderQuery{Index: "topic", Value: "unknownTopic"},
+			expectedOrders: []*Order{},
+		},
+	}
+	for i, tc := range testCases {
+		foundOrders, err := meshDB.QueryOrders(tc.query)
+		require.NoError(t, err, "test case %d", i)
+		expectedHashes := []common.Hash{}
+		for _, order := range tc.expectedOrders {
+			expectedHashes = append(expectedHashes, order.Hash)
+		}
+		foundHashes := []common.Hash{}
+		for _, order := range foundOrders {
+			foundHashes = append(foundHashes, order.Hash)
+		}
+		assert.Equal(t, expectedHashes, foundHashes, "test case %d", i)
+	}
+
+	invalidQueries := []*OrderQuery{
+		{Index: "isRemoved"},
+		{Index: "makerAddressAndSalt", Value: "a", Prefix: "b"},
+		{Index: "makerAddressAndSalt", Start: "a"},
+		{Index: "makerAddressAndSalt", Max: -1},
+	}
+	for i, query := range invalidQueries {
+		_, err := meshDB.QueryOrders(query)
+		assert.Error(t, err, "invalid query %d", i)
+	}
+}
+
+func TestQueryOrdersByTakerAddress(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	takerAddresses := []common.Address{constants.NullAddress, constants.GanacheAccount1}
+	rawOrders := make([]*zeroex.Order, len(takerAddresses))
+	for i, takerAddress := range takerAddresses {
+		rawOrders[i] = &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          takerAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+
+	for i, takerAddress := range takerAddresses {
+		foundOrders, err := meshDB.QueryOrders(&OrderQuery{Index: "takerAddress", Value: takerAddress.Hex()})
+		require.NoError(t, err)
+		require.Len(t, foundOrders, 1)
+		assert.Equal(t, orders[i].Hash, foundOrders[0].Hash)
+	}
+	foundOrders, err := meshDB.QueryOrders(&OrderQuery{Index: "takerAddress", Value: constants.GanacheAccount2.Hex()})
+	require.NoError(t, err)
+	assert.Len(t, foundOrders, 0)
+}
+
+func TestFindOrderSortIndex(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	// Orders 0 and 1 have the same price (2) and orders 1 and 2 have the same
+	// expiration time.
+	amounts := []struct {
+		makerAssetAmount      int64
+		takerAssetAmount      int64
+		expirationTimeSeconds int64
+	}{
+		{makerAssetAmount: 10, takerAssetAmount: 20, expirationTimeSeconds: 300},
+		{makerAssetAmount: 5, takerAssetAmount: 10, expirationTimeSeconds: 100},
+		{makerAssetAmount: 10, takerAssetAmount: 5, expirationTimeSeconds: 100},
+	}
+	rawOrders := make([]*zeroex.Order, len(amounts))
+	for i, amount := range amounts {
+		rawOrders[i] = &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(amount.makerAssetAmount),
+			TakerAssetAmount:      big.NewInt(amount.takerAssetAmount),
+			ExpirationTimeSeconds: big.NewInt(amount.expirationTimeSeconds),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+
+	price := OrderSortField{Field: OrderSortFieldPrice}
+	priceDesc := OrderSortField{Field: OrderSortFieldPrice, Descending: true}
+	expirationTime := OrderSortField{Field: OrderSortFieldExpirationTime}
+	expirationTimeDesc := OrderSortField{Field: OrderSortFieldExpirationTime, Descending: true}
+	testCases := []struct {
+		fields        []OrderSortField
+		expectedOrder []int
+	}{
+		{fields: []OrderSortField{price, expirationTime}, expectedOrder: []int{2, 1, 0}},
+		{fields: []OrderSortField{price, expirationTimeDesc}, expectedOrder: []int{2, 0, 1}},
+		{fields: []OrderSortField{priceDesc, expirationTime}, expectedOrder: []int{1, 0, 2}},
+		{fields: []OrderSortField{priceDesc, expirationTimeDesc}, expectedOrder: []int{0, 1, 2}},
+		{fields: []OrderSortField{expirationTime, price}, expectedOrder: []int{2, 1, 0}},
+		{fields: []OrderSortField{expirationTime, priceDesc}, expectedOrder: []int{1, 2, 0}},
+		{fields: []OrderSortField{expirationTimeDesc, price}, expectedOrder: []int{0, 2, 1}},
+		{fields: []OrderSortField{expirationTimeDesc, priceDesc}, expectedOrder: []int{0, 1, 2}},
+	}
+	for i, tc := range testCases {
+		index, reverse, err := meshDB.FindOrderSortIndex(tc.fields)
+		require.NoError(t, err, "test case %d", i)
+		query := meshDB.Orders.NewQuery(index.All())
+		if reverse {
+			query = query.Reverse()
+		}
+		var foundOrders []*Order
+		require.NoError(t, query.Run(&foundOrders), "test case %d", i)
+		require.Len(t, foundOrders, len(orders), "test case %d", i)
+		for j, orderIndex := range tc.expectedOrder {
+			assert.Equal(t, orders[orderIndex].Hash, foundOrders[j].Hash, "test case %d, position %d", i, j)
+		}
+	}
+
+	// Sorting by a prefix of the fields of an index uses that index.
+	_, _, err = meshDB.FindOrderSortIndex([]OrderSortField{priceDesc})
+	assert.NoError(t, err)
+
+	invalidFields := [][]OrderSortField{
+		nil,
+		{{Field: "makerAssetAmount"}},
+		{price, price},
+		{price, expirationTime, price},
+	}
+	for _, fields := range invalidFields {
+		_, _, err := meshDB.FindOrderSortIndex(fields)
+		assert.Error(t, err, "fields: %v", fields)
+	}
+}
+
+func TestNFTAssetFilter(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	erc20AssetData := common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+	erc721Token1AssetData := common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001")
+	erc721Token2AssetData := common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000002")
+	erc721Address := common.HexToAddress("0x1dc4c1cefef38a777b15aa20260a54e584b16c48")
+	erc20Address := common.HexToAddress("0x34d402f14d58e001d8efbe6585051bf9706aa064")
+
+	// Order 0 sells token 1, order 1 buys token 1, order 2 sells token 2 and
+	// order 3 doesn't involve any NFTs.
+	assetDatas := []struct {
+		makerAssetData []byte
+		takerAssetData []byte
+	}{
+		{makerAssetData: erc721Token1AssetData, takerAssetData: erc20AssetData},
+		{makerAssetData: erc20AssetData, takerAssetData: erc721Token1AssetData},
+		{makerAssetData: erc721Token2AssetData, takerAssetData: erc20AssetData},
+		{makerAssetData: erc20AssetData, takerAssetData: erc20AssetData},
+	}
+	rawOrders := make([]*zeroex.Order, len(assetDatas))
+	for i, assetData := range assetDatas {
+		rawOrders[i] = &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        assetData.makerAssetData,
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        assetData.takerAssetData,
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+
+	testCases := []struct {
+		filter         NFTFilter
+		expectedOrders []int
+	}{
+		{filter: NFTFilter{Side: NFTSideAny}, expectedOrders: []int{0, 1, 2}},
+		{filter: NFTFilter{Side: NFTSideAsk}, expectedOrders: []int{0, 2}},
+		{filter: NFTFilter{Side: NFTSideBid}, expectedOrders: []int{1}},
+		{filter: NFTFilter{Side: NFTSideAny, ContractAddress: &erc721Address}, expectedOrders: []int{0, 1, 2}},
+		{filter: NFTFilter{Side: NFTSideAny, ContractAddress: &erc721Address, TokenID: big.NewInt(1)}, expectedOrders: []int{0, 1}},
+		{filter: NFTFilter{Side: NFTSideAsk, ContractAddress: &erc721Address, TokenID: big.NewInt(2)}, expectedOrders: []int{2}},
+		{filter: NFTFilter{Side: NFTSideBid, ContractAddress: &erc721Address, TokenID: big.NewInt(2)}, expectedOrders: []int{}},
+		{filter: NFTFilter{Side: NFTSideAny, ContractAddress: &erc20Address}, expectedOrders: []int{}},
+	}
+	for i, tc := range testCases {
+		filter, err := meshDB.NFTAssetFilter(tc.filter)
+		require.NoError(t, err, "test case %d", i)
+		var foundOrders []*Order
+		require.NoError(t, meshDB.Orders.NewQuery(filter).Run(&foundOrders), "test case %d", i)
+		expectedHashes := []common.Hash{}
+		for _, orderIndex := range tc.expectedOrders {
+			expectedHashes = append(expectedHashes, orders[orderIndex].Hash)
+		}
+		foundHashes := []common.Hash{}
+		for _, order := range foundOrders {
+			foundHashes = append(foundHashes, order.Hash)
+		}
+		assert.ElementsMatch(t, expectedHashes, foundHashes, "test case %d", i)
+
+		for j, order := range orders {
+			matches, err := meshDB.OrderMatchesNFTFilter(order, tc.filter)
+			require.NoError(t, err, "test case %d", i)
+			assert.Equal(t, containsInt(tc.expectedOrders, j), matches, "test case %d, order %d", i, j)
+		}
+	}
+
+	invalidFilters := []NFTFilter{
+		{Side: "sell"},
+		{Side: NFTSideAny, TokenID: big.NewInt(1)},
+	}
+	for _, filter := range invalidFilters {
+		_, err := meshDB.NFTAssetFilter(filter)
+		assert.Error(t, err, "filter: %+v", filter)
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func insertRawOrders(t *testing.T, meshDB *MeshDB, rawOrders []*zeroex.Order, isPinned bool) []*Order {
 	results := make([]*Order, len(rawOrders))
 	for i, order := range rawOrders {
@@ -499,3 +1010,446 @@ func TestPruneMiniHeadersAboveRetentionLimit(t *testing.T) {
 	remainingMiniHeaders, err := meshDB.MiniHeaders.Count()
 	assert.Equal(t, defaultMiniHeaderRetentionLimit, remainingMiniHeaders, "wrong number of MiniHeaders remaining")
 }
+
+func TestPeerReputations(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	loaded, err := meshDB.LoadPeerReputations()
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	peerA := newTestPeerID(t)
+	peerB := newTestPeerID(t)
+	peerC := newTestPeerID(t)
+	lastSeen := time.Date(2020, 4, 8, 10, 32, 11, 0, time.UTC)
+	first := map[peer.ID]*reputation.Stats{
+		peerA: {ValidMessages: 10, Latency: 50 * time.Millisecond, LastSeen: lastSeen},
+		peerB: {InvalidMessages: 3, OrderSyncFailures: 1, LastSeen: lastSeen},
+	}
+	require.NoError(t, meshDB.SavePeerReputations(first))
+	loaded, err = meshDB.LoadPeerReputations()
+	require.NoError(t, err)
+	assert.Equal(t, first, loaded)
+
+	// Saving again should replace the existing reputations, including removing
+	// peers which are no longer present.
+	second := map[peer.ID]*reputation.Stats{
+		peerA: {ValidMessages: 11, Latency: 60 * time.Millisecond, LastSeen: lastSeen},
+		peerC: {OrderSyncSuccesses: 2, LastSeen: lastSeen},
+	}
+	require.NoError(t, meshDB.SavePeerReputations(second))
+	loaded, err = meshDB.LoadPeerReputations()
+	require.NoError(t, err)
+	assert.Equal(t, second, loaded)
+}
+
+func newTestPeerID(t *testing.T) peer.ID {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	require.NoError(t, err)
+	return peerID
+}
+
+func TestTombstones(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	now := time.Now()
+	expiredHash := common.HexToHash("0x1")
+	activeHash := common.HexToHash("0x2")
+	require.NoError(t, meshDB.AddTombstones([]*Tombstone{
+		{
+			Hash:       expiredHash,
+			Kind:       "ZEROEX_VALIDATION",
+			StatusCode: "OrderUnfunded",
+			ExpiresAt:  now.Add(-time.Minute),
+		},
+		{
+			Hash:       activeHash,
+			Kind:       "ZEROEX_VALIDATION",
+			StatusCode: "OrderUnfunded",
+			ExpiresAt:  now.Add(time.Hour),
+		},
+	}))
+
+	tombstone, err := meshDB.FindTombstone(expiredHash, now)
+	require.NoError(t, err)
+	assert.Nil(t, tombstone, "expired tombstones should be ignored")
+	tombstone, err = meshDB.FindTombstone(common.HexToHash("0x3"), now)
+	require.NoError(t, err)
+	assert.Nil(t, tombstone)
+
+	// Adding a tombstone for an order that already has one should overwrite it.
+	require.NoError(t, meshDB.AddTombstones([]*Tombstone{
+		{
+			Hash:          activeHash,
+			Kind:          "ZEROEX_VALIDATION",
+			StatusCode:    "OrderCancelled",
+			StatusMessage: "order cancelled",
+			ExpiresAt:     now.Add(time.Hour),
+		},
+	}))
+	tombstone, err = meshDB.FindTombstone(activeHash, now)
+	require.NoError(t, err)
+	require.NotNil(t, tombstone)
+	assert.Equal(t, "ZEROEX_VALIDATION", tombstone.Kind)
+	assert.Equal(t, "OrderCancelled", tombstone.StatusCode)
+	assert.Equal(t, "order cancelled", tombstone.StatusMessage)
+
+	numDeleted, err := meshDB.DeleteExpiredTombstones(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, numDeleted)
+	count, err := meshDB.tombstones.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestBlockHistory(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	miniHeaders := []*miniheader.MiniHeader{}
+	for i := 1; i <= 5; i++ {
+		miniHeaders = append(miniHeaders, &miniheader.MiniHeader{
+			Hash:      common.BigToHash(big.NewInt(int64(i))),
+			Parent:    common.BigToHash(big.NewInt(int64(i - 1))),
+			Number:    big.NewInt(int64(i)),
+			Timestamp: time.Now().UTC(),
+		})
+	}
+	require.NoError(t, meshDB.AddHistoricalBlocks(miniHeaders))
+
+	blocks, err := meshDB.FindHistoricalBlocks(big.NewInt(2), big.NewInt(4), 100)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	for i, block := range blocks {
+		assert.Equal(t, miniHeaders[i+1].Hash, block.Hash)
+	}
+
+	// A block with the same number as an existing one (e.g. due to a re-org)
+	// should replace it.
+	reorgedHeader := &miniheader.MiniHeader{
+		Hash:      common.HexToHash("0xff"),
+		Parent:    miniHeaders[3].Hash,
+		Number:    big.NewInt(5),
+		Timestamp: time.Now().UTC(),
+	}
+	require.NoError(t, meshDB.AddHistoricalBlocks([]*miniheader.MiniHeader{reorgedHeader}))
+	blocks, err = meshDB.FindHistoricalBlocks(big.NewInt(5), big.NewInt(5), 100)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, reorgedHeader.Hash, blocks[0].Hash)
+
+	// Removing a block with a different hash than the stored one should be a
+	// no-op.
+	require.NoError(t, meshDB.RemoveHistoricalBlock(big.NewInt(5), miniHeaders[4].Hash))
+	blocks, err = meshDB.FindHistoricalBlocks(big.NewInt(5), big.NewInt(5), 100)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.NoError(t, meshDB.RemoveHistoricalBlock(big.NewInt(5), reorgedHeader.Hash))
+	blocks, err = meshDB.FindHistoricalBlocks(big.NewInt(5), big.NewInt(5), 100)
+	require.NoError(t, err)
+	assert.Len(t, blocks, 0)
+
+	numPruned, err := meshDB.PruneBlockHistory(big.NewInt(3))
+	require.NoError(t, err)
+	assert.Equal(t, 2, numPruned)
+	blocks, err = meshDB.FindHistoricalBlocks(big.NewInt(0), big.NewInt(10), 100)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	assert.Equal(t, int64(3), blocks[0].Number.Int64())
+	assert.Equal(t, int64(4), blocks[1].Number.Int64())
+}
+
+func TestFindMakerAggregates(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	wethAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	zrxAssetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	newRawOrder := func(makerAddress common.Address, makerAssetData []byte, makerAssetAmount int64, salt int64) *zeroex.Order {
+		return &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          makerAddress,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        makerAssetData,
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        zrxAssetData,
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(salt),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(makerAssetAmount),
+			TakerAssetAmount:      big.NewInt(1),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		newRawOrder(constants.GanacheAccount1, wethAssetData, 100, 1),
+		newRawOrder(constants.GanacheAccount1, wethAssetData, 50, 2),
+		newRawOrder(constants.GanacheAccount1, zrxAssetData, 7, 3),
+		newRawOrder(constants.GanacheAccount2, wethAssetData, 1, 4),
+		newRawOrder(constants.GanacheAccount2, wethAssetData, 1000, 5),
+	}, false)
+
+	// Removed orders should not be counted.
+	removedOrder := orders[4]
+	removedOrder.IsRemoved = true
+	require.NoError(t, meshDB.Orders.Update(removedOrder))
+
+	lastActivity := time.Date(2020, 4, 8, 10, 32, 11, 0, time.UTC)
+	orders[1].LastUpdated = lastActivity
+	require.NoError(t, meshDB.Orders.Update(orders[1]))
+	orders[0].LastUpdated = lastActivity.Add(-time.Hour)
+	require.NoError(t, meshDB.Orders.Update(orders[0]))
+	orders[2].LastUpdated = lastActivity.Add(-time.Hour)
+	require.NoError(t, meshDB.Orders.Update(orders[2]))
+
+	expectedAccount1 := &MakerAggregate{
+		MakerAddress: constants.GanacheAccount1,
+		NumOrders:    3,
+		MakerAssetAmounts: map[string]*big.Int{
+			common.ToHex(wethAssetData): big.NewInt(150),
+			common.ToHex(zrxAssetData):  big.NewInt(7),
+		},
+		LastActivity: lastActivity,
+	}
+	expectedAccount2 := &MakerAggregate{
+		MakerAddress: constants.GanacheAccount2,
+		NumOrders:    1,
+		MakerAssetAmounts: map[string]*big.Int{
+			common.ToHex(wethAssetData): big.NewInt(1),
+		},
+		LastActivity: orders[3].LastUpdated,
+	}
+	assertMakerAggregatesEqual := func(expected, actual []*MakerAggregate) {
+		require.Len(t, actual, len(expected))
+		for i, expectedAggregate := range expected {
+			actualAggregate := actual[i]
+			assert.Equal(t, expectedAggregate.MakerAddress, actualAggregate.MakerAddress)
+			assert.Equal(t, expectedAggregate.NumOrders, actualAggregate.NumOrders)
+			assert.Equal(t, expectedAggregate.MakerAssetAmounts, actualAggregate.MakerAssetAmounts)
+			assert.True(t, expectedAggregate.LastActivity.Equal(actualAggregate.LastActivity), "expected %s but got %s", expectedAggregate.LastActivity, actualAggregate.LastActivity)
+		}
+	}
+
+	// GanacheAccount1 < GanacheAccount2 when sorted by address.
+	aggregates, err := meshDB.FindMakerAggregates(nil)
+	require.NoError(t, err)
+	assertMakerAggregatesEqual([]*MakerAggregate{expectedAccount1, expectedAccount2}, aggregates)
+
+	// Makers without any open orders should still be included when they are
+	// requested explicitly.
+	aggregates, err = meshDB.FindMakerAggregates([]common.Address{constants.GanacheAccount2, constants.GanacheAccount3, constants.GanacheAccount2})
+	require.NoError(t, err)
+	assertMakerAggregatesEqual([]*MakerAggregate{expectedAccount2, newMakerAggregate(constants.GanacheAccount3)}, aggregates)
+}
+
+func TestFindMarketAggregates(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	// When sorted, erc721AssetData < wethAssetData < zrxAssetData.
+	erc721AssetData := common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001")
+	wethAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	zrxAssetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	newRawOrder := func(makerAssetData []byte, makerAssetAmount int64, takerAssetData []byte, takerAssetAmount int64, salt int64) *zeroex.Order {
+		return &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount1,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        makerAssetData,
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        takerAssetData,
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(salt),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(makerAssetAmount),
+			TakerAssetAmount:      big.NewInt(takerAssetAmount),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		// Asks for WETH/ZRX with prices of 2 and 3.
+		newRawOrder(wethAssetData, 100, zrxAssetData, 200, 1),
+		newRawOrder(wethAssetData, 10, zrxAssetData, 30, 2),
+		// Bid for WETH/ZRX with a price of 1.5.
+		newRawOrder(zrxAssetData, 150, wethAssetData, 100, 3),
+		// Removed ask for WETH/ZRX with a price of 1.
+		newRawOrder(wethAssetData, 5, zrxAssetData, 5, 4),
+		// Bid for ERC721/ZRX with a price of 42.
+		newRawOrder(zrxAssetData, 42, erc721AssetData, 1, 5),
+	}, false)
+	fillableTakerAssetAmounts := []int64{100, 30, 40, 5, 1}
+	for i, order := range orders {
+		order.FillableTakerAssetAmount = big.NewInt(fillableTakerAssetAmounts[i])
+		if i == 3 {
+			order.IsRemoved = true
+		}
+		require.NoError(t, meshDB.Orders.Update(order))
+	}
+
+	aggregates, err := meshDB.FindMarketAggregates()
+	require.NoError(t, err)
+	require.Len(t, aggregates, 2)
+
+	erc721Market := aggregates[0]
+	assert.Equal(t, erc721AssetData, erc721Market.BaseAssetData)
+	assert.Equal(t, zrxAssetData, erc721Market.QuoteAssetData)
+	assert.Equal(t, 1, erc721Market.NumBids)
+	assert.Equal(t, 0, erc721Market.NumAsks)
+	assert.Equal(t, 0, big.NewRat(42, 1).Cmp(erc721Market.BestBid))
+	assert.Nil(t, erc721Market.BestAsk)
+	assert.Equal(t, big.NewInt(1), erc721Market.BidSize)
+	assert.Equal(t, big.NewInt(0), erc721Market.AskSize)
+
+	wethMarket := aggregates[1]
+	assert.Equal(t, wethAssetData, wethMarket.BaseAssetData)
+	assert.Equal(t, zrxAssetData, wethMarket.QuoteAssetData)
+	assert.Equal(t, 1, wethMarket.NumBids)
+	assert.Equal(t, 2, wethMarket.NumAsks)
+	assert.Equal(t, 0, big.NewRat(3, 2).Cmp(wethMarket.BestBid))
+	assert.Equal(t, 0, big.NewRat(2, 1).Cmp(wethMarket.BestAsk))
+	assert.Equal(t, big.NewInt(40), wethMarket.BidSize)
+	// 100 * 100 / 200 + 30 * 10 / 30
+	assert.Equal(t, big.NewInt(60), wethMarket.AskSize)
+}
+
+func TestUpdateOrderTiers(t *testing.T) {
+	t.Parallel()
+
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	wethAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	zrxAssetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	now := time.Now()
+	// newAsk returns a WETH/ZRX ask with the given price which expires after
+	// timeToExpiry.
+	newAsk := func(price int64, timeToExpiry time.Duration, salt int64) *zeroex.Order {
+		return &zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount1,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			MakerAssetData:        wethAssetData,
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerAssetData:        zrxAssetData,
+			TakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(salt),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(100),
+			TakerAssetAmount:      big.NewInt(100 * price),
+			ExpirationTimeSeconds: big.NewInt(now.Add(timeToExpiry).Unix()),
+		}
+	}
+	orders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		// The best ask. It stays hot.
+		newAsk(2, 1*time.Hour, 1),
+		// Far from the best ask. It becomes cold.
+		newAsk(4, 2*time.Hour, 2),
+		// Expires in the distant future. It becomes cold.
+		newAsk(2, 365*24*time.Hour, 3),
+		// Far from the best ask but expires soon. It stays hot.
+		newAsk(10, 1*time.Minute, 4),
+	}, false)
+	pinnedOrders := insertRawOrders(t, meshDB, []*zeroex.Order{
+		// Pinned orders always stay hot.
+		newAsk(10, 365*24*time.Hour, 5),
+	}, true)
+	criteria := ColdOrderCriteria{
+		MinTimeToExpiry:  30 * 24 * time.Hour,
+		MinPriceDistance: 0.5,
+		ExpirationBuffer: 10 * time.Minute,
+	}
+
+	numFrozen, numThawed, err := meshDB.UpdateOrderTiers(criteria, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, numFrozen)
+	assert.Equal(t, 0, numThawed)
+	assertOrderTiers(t, meshDB, []*Order{orders[0], orders[3], pinnedOrders[0]}, []*Order{orders[1], orders[2]})
+
+	// Cold orders are still found and can be updated in place.
+	foundOrder, err := meshDB.FindOrder(orders[1].Hash)
+	require.NoError(t, err)
+	assert.Equal(t, orders[1].Hash, foundOrder.Hash)
+	require.NoError(t, meshDB.AddOrderTopics(orders[1].Hash, []string{"topic"}))
+	numOrdersWithTopic, err := meshDB.CountOrdersByTopic("topic")
+	require.NoError(t, err)
+	assert.Equal(t, 1, numOrdersWithTopic)
+	numStoredOrders, err := meshDB.CountStoredOrders()
+	require.NoError(t, err)
+	assert.Equal(t, 5, numStoredOrders)
+	notRemovedOrders, err := meshDB.FindNotRemovedOrders()
+	require.NoError(t, err)
+	assert.Len(t, notRemovedOrders, 5)
+
+	// Thawing moves cold orders back to the hot tier.
+	numThawed, err = meshDB.ThawOrders([]common.Hash{orders[1].Hash, orders[0].Hash})
+	require.NoError(t, err)
+	assert.Equal(t, 1, numThawed)
+	numThawed, err = meshDB.ThawOrdersExpiringBefore(now.Add(366 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, numThawed)
+	assertOrderTiers(t, meshDB, append(orders, pinnedOrders...), []*Order{})
+
+	// Trimming removes orders from both tiers.
+	numFrozen, _, err = meshDB.UpdateOrderTiers(criteria, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, numFrozen)
+	_, removedOrders, err := meshDB.TrimOrdersByExpirationTime(3, nil)
+	require.NoError(t, err)
+	require.Len(t, removedOrders, 2)
+	assert.Equal(t, orders[2].Hash, removedOrders[0].Hash)
+	assert.Equal(t, orders[1].Hash, removedOrders[1].Hash)
+	assertOrderTiers(t, meshDB, []*Order{orders[0], orders[3], pinnedOrders[0]}, []*Order{})
+
+	// Disabling all criteria thaws all cold orders.
+	require.NoError(t, meshDB.ColdOrders.Insert(orders[1]))
+	numFrozen, numThawed, err = meshDB.UpdateOrderTiers(ColdOrderCriteria{}, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, numFrozen)
+	assert.Equal(t, 1, numThawed)
+	assertOrderTiers(t, meshDB, []*Order{orders[0], orders[1], orders[3], pinnedOrders[0]}, []*Order{})
+}
+
+func assertOrderTiers(t *testing.T, meshDB *MeshDB, expectedHotOrders []*Order, expectedColdOrders []*Order) {
+	for _, order := range expectedHotOrders {
+		var foundOrder Order
+		assert.NoError(t, meshDB.Orders.FindByID(order.ID(), &foundOrder), "expected order to be hot")
+	}
+	for _, order := range expectedColdOrders {
+		var foundOrder Order
+		assert.NoError(t, meshDB.ColdOrders.FindByID(order.ID(), &foundOrder), "expected order to be cold")
+	}
+	numHotOrders, err := meshDB.Orders.Count()
+	require.NoError(t, err)
+	assert.Equal(t, len(expectedHotOrders), numHotOrders)
+	numColdOrders, err := meshDB.CountColdOrders()
+	require.NoError(t, err)
+	assert.Equal(t, len(expectedColdOrders), numColdOrders)
+}
@@ -0,0 +1,178 @@
+package meshdb
+
+import (
+	"fmt"
+
+	"github.com/0xProject/0x-mesh/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// migration upgrades the database in place from the previous schema version
+// to version. Migrations must be idempotent, since a migration which was
+// interrupted (e.g. because the browser tab was closed) is run again the next
+// time the database is opened.
+type migration struct {
+	version     int
+	description string
+	migrate     func(m *MeshDB) error
+}
+
+// migrations are all schema migrations, ordered by version. A migration must
+// be added whenever a new release of Mesh can't use the data stored by older
+// releases as is (e.g. because a new index was added to an existing
+// collection). Migrations must never be changed or removed once they have been
+// released.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "index orders stored before indexes were added to the order collections",
+		migrate:     (*MeshDB).reindexOrders,
+	},
+}
+
+// LatestSchemaVersion is the schema version of databases which are created or
+// migrated by this version of Mesh.
+var LatestSchemaVersion = migrations[len(migrations)-1].version
+
+// SchemaVersionTooNewError is returned by New if the database was created or
+// migrated by a newer version of Mesh. Downgrading is not supported.
+type SchemaVersionTooNewError struct {
+	Version       int
+	LatestVersion int
+}
+
+func (e SchemaVersionTooNewError) Error() string {
+	return fmt.Sprintf("database has schema version %d but this version of Mesh only supports schema versions up to %d", e.Version, e.LatestVersion)
+}
+
+// SchemaVersion is the database representation of the schema version of the
+// database.
+type SchemaVersion struct {
+	Version int
+}
+
+// ID returns the id used for the schema version collection (one per DB)
+func (s SchemaVersion) ID() []byte {
+	return []byte{0}
+}
+
+// SchemaVersionCollection represents a DB collection used to store the
+// schema version.
+type SchemaVersionCollection struct {
+	*db.Collection
+}
+
+func setupSchemaVersion(database *db.DB) (*SchemaVersionCollection, error) {
+	col, err := database.NewCollection("schemaVersion", &SchemaVersion{})
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaVersionCollection{col}, nil
+}
+
+// SchemaVersion returns the schema version of the database. It is 0 for
+// databases which were created by a version of Mesh which didn't keep track of
+// schema versions and have not been migrated yet.
+func (m *MeshDB) SchemaVersion() (int, error) {
+	var schemaVersion SchemaVersion
+	if err := m.schemaVersion.FindByID(SchemaVersion{}.ID(), &schemaVersion); err != nil {
+		if _, ok := err.(db.NotFoundError); ok {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return schemaVersion.Version, nil
+}
+
+func (m *MeshDB) saveSchemaVersion(version int) error {
+	schemaVersion := &SchemaVersion{Version: version}
+	if err := m.schemaVersion.Insert(schemaVersion); err != nil {
+		if _, ok := err.(db.AlreadyExistsError); ok {
+			return m.schemaVersion.Update(schemaVersion)
+		}
+		return err
+	}
+	return nil
+}
+
+// migrate runs all migrations which have not been applied to the database
+// yet, in order. The schema version is saved after each migration, so an
+// interrupted upgrade resumes where it left off. New databases start at
+// LatestSchemaVersion without running any migrations. This works the same way
+// in browsers (where the database is stored in IndexedDB), so upgrading to a
+// new version of Mesh never requires deleting the stored orders.
+func (m *MeshDB) migrate() error {
+	version, err := m.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > LatestSchemaVersion {
+		return SchemaVersionTooNewError{
+			Version:       version,
+			LatestVersion: LatestSchemaVersion,
+		}
+	}
+	if version == 0 {
+		isEmpty, err := m.isEmpty()
+		if err != nil {
+			return err
+		}
+		if isEmpty {
+			return m.saveSchemaVersion(LatestSchemaVersion)
+		}
+	}
+	for _, migration := range migrations {
+		if migration.version <= version {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"version":     migration.version,
+			"description": migration.description,
+		}).Info("migrating database")
+		if err := migration.migrate(m); err != nil {
+			return fmt.Errorf("database migration to schema version %d failed: %s", migration.version, err)
+		}
+		if err := m.saveSchemaVersion(migration.version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEmpty returns true if the database doesn't contain any orders or metadata,
+// i.e. it was just created.
+func (m *MeshDB) isEmpty() (bool, error) {
+	for _, col := range []*db.Collection{m.metadata.Collection, m.Orders.Collection, m.ColdOrders.Collection} {
+		count, err := col.Count()
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reindexOrders updates every order in both tiers, which adds any index
+// entries that are missing because the order was stored before the index was
+// added.
+func (m *MeshDB) reindexOrders() error {
+	for _, col := range []*db.Collection{m.Orders.Collection, m.ColdOrders.Collection} {
+		var orders []*Order
+		if err := col.FindAll(&orders); err != nil {
+			return err
+		}
+		txn := col.OpenTransaction()
+		for _, order := range orders {
+			if err := txn.Update(order); err != nil {
+				_ = txn.Discard()
+				return err
+			}
+		}
+		if err := txn.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
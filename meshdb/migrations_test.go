@@ -0,0 +1,88 @@
+package meshdb
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseHasLatestSchemaVersion(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	version, err := meshDB.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion, version)
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	path := "/tmp/meshdb_testing/" + uuid.New().String()
+
+	// Store an order the way releases without schema versions did for orders
+	// which were stored before an index was added.
+	signedOrder, err := zeroex.SignTestOrder(&zeroex.Order{
+		ChainID:               big.NewInt(constants.TestChainID),
+		ExchangeAddress:       contractAddresses.Exchange,
+		MakerAddress:          constants.GanacheAccount0,
+		TakerAddress:          constants.NullAddress,
+		SenderAddress:         constants.NullAddress,
+		FeeRecipientAddress:   constants.NullAddress,
+		MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		MakerFeeAssetData:     constants.NullBytes,
+		TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+		TakerFeeAssetData:     constants.NullBytes,
+		Salt:                  big.NewInt(1),
+		MakerFee:              big.NewInt(0),
+		TakerFee:              big.NewInt(0),
+		MakerAssetAmount:      big.NewInt(1),
+		TakerAssetAmount:      big.NewInt(1),
+		ExpirationTimeSeconds: big.NewInt(time.Now().Add(time.Hour).Unix()),
+	})
+	require.NoError(t, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	legacyDB, err := db.Open(path)
+	require.NoError(t, err)
+	legacyOrders, err := legacyDB.NewCollection("order", &Order{})
+	require.NoError(t, err)
+	require.NoError(t, legacyOrders.Insert(&Order{
+		Hash:                     orderHash,
+		SignedOrder:              signedOrder,
+		LastUpdated:              time.Now().UTC(),
+		FillableTakerAssetAmount: big.NewInt(1),
+	}))
+	require.NoError(t, legacyDB.Close())
+
+	meshDB, err := New(path, contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	version, err := meshDB.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion, version)
+	// The migration should have added the order to the indexes.
+	orders, err := meshDB.FindOrdersByMakerAddress(constants.GanacheAccount0)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, orderHash, orders[0].Hash)
+}
+
+func TestSchemaVersionTooNew(t *testing.T) {
+	path := "/tmp/meshdb_testing/" + uuid.New().String()
+	meshDB, err := New(path, contractAddresses)
+	require.NoError(t, err)
+	require.NoError(t, meshDB.saveSchemaVersion(LatestSchemaVersion+1))
+	meshDB.Close()
+
+	_, err = New(path, contractAddresses)
+	assert.Equal(t, SchemaVersionTooNewError{Version: LatestSchemaVersion + 1, LatestVersion: LatestSchemaVersion}, err)
+}
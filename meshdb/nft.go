@@ -0,0 +1,144 @@
+package meshdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// NFTSideAsk matches orders which sell an NFT, i.e. orders with the NFT in
+	// their maker asset data.
+	NFTSideAsk = "ask"
+	// NFTSideBid matches orders which buy an NFT, i.e. orders with the NFT in
+	// their taker asset data.
+	NFTSideBid = "bid"
+	// NFTSideAny matches orders which either buy or sell an NFT.
+	NFTSideAny = "any"
+)
+
+// NFTFilter matches orders which buy or sell ERC721 or ERC1155 tokens.
+type NFTFilter struct {
+	// Side is one of NFTSideAsk, NFTSideBid or NFTSideAny.
+	Side string
+	// ContractAddress, if not nil, only matches tokens of the given contract.
+	ContractAddress *common.Address
+	// TokenID, if not nil, only matches the token with the given ID.
+	// ContractAddress must be set if TokenID is set.
+	TokenID *big.Int
+}
+
+// indexPrefix returns the prefix of the values of the nftAsset index which are
+// matched by the filter.
+func (f NFTFilter) indexPrefix() ([]byte, error) {
+	switch f.Side {
+	case NFTSideAsk, NFTSideBid, NFTSideAny:
+	default:
+		return nil, fmt.Errorf("unsupported side: %q (must be %q, %q or %q)", f.Side, NFTSideAsk, NFTSideBid, NFTSideAny)
+	}
+	if f.ContractAddress == nil {
+		if f.TokenID != nil {
+			return nil, errors.New("contract address must be set if token ID is set")
+		}
+		return []byte(f.Side + "|"), nil
+	}
+	if f.TokenID == nil {
+		return nftAssetIndexValue(f.Side, *f.ContractAddress, nil), nil
+	}
+	if f.TokenID.Sign() < 0 || f.TokenID.BitLen() > 256 {
+		return nil, fmt.Errorf("token ID out of range: %s", f.TokenID)
+	}
+	return nftAssetIndexValue(f.Side, *f.ContractAddress, f.TokenID), nil
+}
+
+// nftAssetIndexValue returns the value of the nftAsset index for the given
+// token, or the prefix of the values for all tokens of the given contract if
+// tokenID is nil.
+func nftAssetIndexValue(side string, contractAddress common.Address, tokenID *big.Int) []byte {
+	value := []byte(side + "|" + contractAddress.Hex() + "|")
+	if tokenID != nil {
+		value = append(value, uint256ToConstantLengthBytes(tokenID)...)
+	}
+	return value
+}
+
+// nftAssetIndexValues returns the values of the nftAsset index for the given
+// order. Each ERC721 or ERC1155 token in the asset data of the order is
+// indexed both with the side of the order it is on and with NFTSideAny.
+func nftAssetIndexValues(order *Order, contractAddresses ethereum.ContractAddresses) [][]byte {
+	sides := []struct {
+		side      string
+		assetData []byte
+	}{
+		{NFTSideAsk, order.SignedOrder.MakerAssetData},
+		{NFTSideBid, order.SignedOrder.TakerAssetData},
+	}
+	indexValues := [][]byte{}
+	for _, side := range sides {
+		singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(side.assetData, contractAddresses)
+		if err != nil {
+			// Note: Orders are validated before they are stored, so this should
+			// never happen. The order is still stored, but it can't be found
+			// with an NFTFilter.
+			log.WithFields(log.Fields{
+				"error":     err.Error(),
+				"orderHash": order.Hash.Hex(),
+			}).Warn("Parsing assetData failed")
+			continue
+		}
+		for _, singleAssetData := range singleAssetDatas {
+			if singleAssetData.TokenID == nil {
+				continue
+			}
+			for _, indexSide := range []string{side.side, NFTSideAny} {
+				indexValue := nftAssetIndexValue(indexSide, singleAssetData.Address, singleAssetData.TokenID)
+				if !containsBytes(indexValues, indexValue) {
+					indexValues = append(indexValues, indexValue)
+				}
+			}
+		}
+	}
+	return indexValues
+}
+
+func containsBytes(values [][]byte, value []byte) bool {
+	for _, v := range values {
+		if bytes.Equal(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NFTAssetFilter returns a db.Filter for the nftAsset index which matches the
+// orders matched by the given NFTFilter. It returns an error if the filter is
+// invalid.
+func (m *MeshDB) NFTAssetFilter(filter NFTFilter) (*db.Filter, error) {
+	prefix, err := filter.indexPrefix()
+	if err != nil {
+		return nil, err
+	}
+	return m.Orders.NFTAssetIndex.PrefixFilter(prefix), nil
+}
+
+// OrderMatchesNFTFilter returns true if the given order is matched by the
+// given NFTFilter. It can be used to filter orders which were found using a
+// different index.
+func (m *MeshDB) OrderMatchesNFTFilter(order *Order, filter NFTFilter) (bool, error) {
+	prefix, err := filter.indexPrefix()
+	if err != nil {
+		return false, err
+	}
+	for _, indexValue := range nftAssetIndexValues(order, m.Orders.contractAddresses) {
+		if bytes.HasPrefix(indexValue, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,174 @@
+package meshdb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/db"
+)
+
+const (
+	// OrderSortFieldPrice sorts orders by price, i.e. the amount of the taker
+	// asset per unit of the maker asset (takerAssetAmount / makerAssetAmount).
+	OrderSortFieldPrice = "price"
+	// OrderSortFieldExpirationTime sorts orders by expirationTimeSeconds.
+	OrderSortFieldExpirationTime = "expirationTime"
+)
+
+// priceScale is the number of decimal places with which prices are stored in
+// the sort indexes. Prices which differ by less than 10^-priceScale may be
+// sorted in any order (with ties broken by order hash).
+const priceScale = 18
+
+// priceIndexValueLength is the number of digits of a price in the sort
+// indexes. The highest possible price is (2^256 - 1) * 10^priceScale, which
+// has 96 digits.
+const priceIndexValueLength = 96
+
+// OrderSortField is a single field to sort orders by.
+type OrderSortField struct {
+	// Field is one of OrderSortFieldPrice or OrderSortFieldExpirationTime.
+	Field string
+	// Descending sorts by the field in descending instead of ascending order.
+	Descending bool
+}
+
+// orderSortIndex is an index which sorts orders by several fields. Orders
+// with equal values for all of the fields are sorted by hash, so the order of
+// the results is always deterministic.
+type orderSortIndex struct {
+	fields []OrderSortField
+	index  *db.Index
+}
+
+// orderSortIndexFields are the combinations of fields which orders can be
+// sorted by. An index is only needed for each combination of field
+// directions up to reversal, since an index can be iterated in reverse (e.g.
+// the index sorted by price ascending then expiration time descending also
+// serves price descending then expiration time ascending). Sorting by a
+// prefix of the fields of an index (e.g. only by price) uses that index too.
+var orderSortIndexFields = [][]OrderSortField{
+	{{Field: OrderSortFieldPrice}, {Field: OrderSortFieldExpirationTime}},
+	{{Field: OrderSortFieldPrice}, {Field: OrderSortFieldExpirationTime, Descending: true}},
+	{{Field: OrderSortFieldExpirationTime}, {Field: OrderSortFieldPrice}},
+	{{Field: OrderSortFieldExpirationTime}, {Field: OrderSortFieldPrice, Descending: true}},
+}
+
+// addOrderSortIndexes adds an index to the orders collection for each entry
+// of orderSortIndexFields.
+func addOrderSortIndexes(col *db.Collection) []*orderSortIndex {
+	sortIndexes := make([]*orderSortIndex, len(orderSortIndexFields))
+	for i, fields := range orderSortIndexFields {
+		fields := fields
+		index := col.AddIndex(orderSortIndexName(fields), func(m db.Model) []byte {
+			order := m.(*Order)
+			values := make([]string, len(fields))
+			for j, field := range fields {
+				values[j] = string(orderSortValue(order, field))
+			}
+			return []byte(strings.Join(values, "|"))
+		})
+		sortIndexes[i] = &orderSortIndex{
+			fields: fields,
+			index:  index,
+		}
+	}
+	return sortIndexes
+}
+
+// orderSortIndexName returns the name of the index for the given fields, e.g.
+// "sortedByPriceAscExpirationTimeDesc". Note: Index names must not start with
+// the name of another index of the same collection, since filters match index
+// keys by prefix. This is why the names start with "sortedBy".
+func orderSortIndexName(fields []OrderSortField) string {
+	name := "sortedBy"
+	for _, field := range fields {
+		name += strings.ToUpper(field.Field[:1]) + field.Field[1:]
+		if field.Descending {
+			name += "Desc"
+		} else {
+			name += "Asc"
+		}
+	}
+	return name
+}
+
+// orderSortValue returns the value of the given field of the given order,
+// encoded such that the byte order of the encoded values matches the sort
+// order of the field.
+func orderSortValue(order *Order, field OrderSortField) []byte {
+	var value []byte
+	switch field.Field {
+	case OrderSortFieldPrice:
+		value = priceToConstantLengthBytes(order.SignedOrder.TakerAssetAmount, order.SignedOrder.MakerAssetAmount)
+	case OrderSortFieldExpirationTime:
+		value = uint256ToConstantLengthBytes(order.SignedOrder.ExpirationTimeSeconds)
+	}
+	if field.Descending {
+		invertDigits(value)
+	}
+	return value
+}
+
+// priceToConstantLengthBytes returns takerAssetAmount / makerAssetAmount as a
+// fixed-point number with priceScale decimal places, padded with zeroes to
+// priceIndexValueLength digits. Orders with a makerAssetAmount of 0 are
+// considered to have the highest possible price.
+func priceToConstantLengthBytes(takerAssetAmount, makerAssetAmount *big.Int) []byte {
+	if makerAssetAmount == nil || makerAssetAmount.Sign() == 0 || takerAssetAmount == nil {
+		return []byte(strings.Repeat("9", priceIndexValueLength))
+	}
+	price := new(big.Int).Mul(takerAssetAmount, new(big.Int).Exp(big.NewInt(10), big.NewInt(priceScale), nil))
+	price.Quo(price, makerAssetAmount)
+	return []byte(fmt.Sprintf("%0*s", priceIndexValueLength, price.String()))
+}
+
+// invertDigits replaces each decimal digit d in value with 9 - d, which
+// reverses the byte order of constant length numbers.
+func invertDigits(value []byte) {
+	for i, digit := range value {
+		value[i] = '9' - (digit - '0')
+	}
+}
+
+// FindOrderSortIndex returns an index which can be used to iterate through
+// orders in the order described by fields, and whether the index needs to be
+// iterated in reverse. Orders which are equal in all of the fields are sorted
+// by hash (in reverse if reverse is true). It returns an error if there is no
+// index for the given fields.
+func (m *MeshDB) FindOrderSortIndex(fields []OrderSortField) (index *db.Index, reverse bool, err error) {
+	if len(fields) == 0 {
+		return nil, false, errors.New("at least one sort field is required")
+	}
+	for _, field := range fields {
+		if field.Field != OrderSortFieldPrice && field.Field != OrderSortFieldExpirationTime {
+			return nil, false, fmt.Errorf("unsupported sort field: %q", field.Field)
+		}
+	}
+	for _, sortIndex := range m.Orders.sortIndexes {
+		if len(fields) > len(sortIndex.fields) {
+			continue
+		}
+		matchesForward, matchesReverse := true, true
+		for i, field := range fields {
+			if field.Field != sortIndex.fields[i].Field {
+				matchesForward, matchesReverse = false, false
+				break
+			}
+			if field.Descending == sortIndex.fields[i].Descending {
+				matchesReverse = false
+			} else {
+				matchesForward = false
+			}
+		}
+		if matchesForward {
+			return sortIndex.index, false, nil
+		}
+		if matchesReverse {
+			return sortIndex.index, true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("unsupported combination of sort fields: %v", fields)
+}
@@ -0,0 +1,109 @@
+package meshdb
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// findOrdersByTopicInBothTiers returns the orders in the hot and the cold tier
+// respectively that have been received on the given pubsub topic.
+func (m *MeshDB) findOrdersByTopicInBothTiers(topic string) (hotOrders []*Order, coldOrders []*Order, err error) {
+	if err := m.Orders.NewQuery(m.Orders.TopicIndex.ValueFilter([]byte(topic))).Run(&hotOrders); err != nil {
+		return nil, nil, err
+	}
+	if err := m.ColdOrders.NewQuery(m.ColdOrders.TopicIndex.ValueFilter([]byte(topic))).Run(&coldOrders); err != nil {
+		return nil, nil, err
+	}
+	return hotOrders, coldOrders, nil
+}
+
+// findOrderHashesByTopics returns the hashes of the orders in both tiers that
+// have been received on at least one of the given pubsub topics.
+func (m *MeshDB) findOrderHashesByTopics(topics []string) (map[common.Hash]struct{}, error) {
+	orderHashes := map[common.Hash]struct{}{}
+	for _, topic := range topics {
+		hotOrders, coldOrders, err := m.findOrdersByTopicInBothTiers(topic)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range append(hotOrders, coldOrders...) {
+			orderHashes[order.Hash] = struct{}{}
+		}
+	}
+	return orderHashes, nil
+}
+
+// CountStoredOrdersExcludingTopics returns the number of orders in both tiers
+// minus the number of orders that have been received on each of the given
+// pubsub topics. Orders which have been received on more than one of the
+// topics are subtracted once per topic, so the result is a lower bound. This
+// is good enough to decide whether orders need to be trimmed, and much cheaper
+// than finding the exact number.
+func (m *MeshDB) CountStoredOrdersExcludingTopics(topics []string) (int, error) {
+	numOrders, err := m.CountStoredOrders()
+	if err != nil {
+		return 0, err
+	}
+	for _, topic := range topics {
+		numOrdersWithTopic, err := m.CountOrdersByTopic(topic)
+		if err != nil {
+			return 0, err
+		}
+		numOrders -= numOrdersWithTopic
+	}
+	if numOrders < 0 {
+		return 0, nil
+	}
+	return numOrders, nil
+}
+
+// TrimOrdersByTopic removes the orders with the highest expiration time among
+// the orders that have been received on the given pubsub topic until at most
+// targetMaxOrders of them remain. Orders in both the hot and the cold tier are
+// considered. Pinned orders are never removed, so fewer orders are removed if
+// there are too many pinned orders on the topic. Orders on other topics are
+// not affected, which allows each topic to have its own quota. It returns any
+// orders that were removed.
+func (m *MeshDB) TrimOrdersByTopic(topic string, targetMaxOrders int) ([]*Order, error) {
+	txn := m.database.OpenGlobalTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	hotOrders, coldOrders, err := m.findOrdersByTopicInBothTiers(topic)
+	if err != nil {
+		return nil, err
+	}
+	numOrdersToRemove := len(hotOrders) + len(coldOrders) - targetMaxOrders
+	if numOrdersToRemove <= 0 {
+		return nil, nil
+	}
+	removedOrders := make([]*Order, 0, len(hotOrders)+len(coldOrders))
+	for _, order := range hotOrders {
+		if !order.IsPinned {
+			removedOrders = append(removedOrders, order)
+		}
+	}
+	coldOrderHashes := map[common.Hash]struct{}{}
+	for _, order := range coldOrders {
+		coldOrderHashes[order.Hash] = struct{}{}
+		removedOrders = append(removedOrders, order)
+	}
+	sortOrdersByExpirationTimeDescending(removedOrders)
+	if len(removedOrders) > numOrdersToRemove {
+		removedOrders = removedOrders[:numOrdersToRemove]
+	}
+
+	for _, order := range removedOrders {
+		col := m.Orders.Collection
+		if _, isCold := coldOrderHashes[order.Hash]; isCold {
+			col = m.ColdOrders.Collection
+		}
+		if err := txn.Delete(col, order.Hash.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, err
+	}
+	return removedOrders, nil
+}
@@ -0,0 +1,150 @@
+// Package metrics periodically pushes metrics about a Mesh node to external
+// monitoring systems. Metrics are gathered by a collect function and sent to
+// one or more exporters, each of which supports a different backend (e.g.
+// StatsD or an OpenTelemetry collector). Support for other backends can be
+// added by implementing the Exporter interface.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Kind is the kind of a metric.
+type Kind int
+
+const (
+	// Gauge is a metric whose value can go up and down (e.g. the number of
+	// connected peers).
+	Gauge Kind = iota
+	// Counter is a metric whose value is a running total that only goes up
+	// (e.g. the number of dropped messages). Counters start at zero whenever
+	// Mesh is started.
+	Counter
+)
+
+// Measurement is the current value of a single metric.
+type Measurement struct {
+	// Name is the name of the metric (e.g. "mesh.num_orders").
+	Name string
+	Kind Kind
+	// Value is the current value for gauges and the total since Mesh was
+	// started for counters.
+	Value float64
+	// Tags are attached to the measurement in addition to the tags in
+	// Config.Tags. They may be nil.
+	Tags map[string]string
+}
+
+// Exporter pushes measurements to a monitoring backend.
+type Exporter interface {
+	// Export sends measurements that were collected at the given time.
+	Export(ctx context.Context, timestamp time.Time, measurements []Measurement) error
+	// Close releases any resources held by the exporter.
+	Close() error
+}
+
+// CollectFunc returns the current values of all metrics.
+type CollectFunc func() ([]Measurement, error)
+
+// Config contains configuration options for a Reporter.
+type Config struct {
+	// Collect is called once per Interval to collect measurements.
+	Collect CollectFunc
+	// Exporters are the exporters that measurements are sent to.
+	Exporters []Exporter
+	// Interval is how often to collect and export measurements. It is also
+	// used as the timeout for each export.
+	Interval time.Duration
+	// Tags are attached to every measurement (e.g. the chain ID).
+	Tags map[string]string
+}
+
+// Reporter periodically collects measurements and sends them to each
+// exporter.
+type Reporter struct {
+	config Config
+}
+
+// NewReporter creates and returns a new Reporter with the given config.
+func NewReporter(config Config) (*Reporter, error) {
+	if config.Collect == nil {
+		return nil, errors.New("config.Collect is required")
+	}
+	if config.Interval <= 0 {
+		return nil, errors.New("config.Interval must be positive")
+	}
+	return &Reporter{
+		config: config,
+	}, nil
+}
+
+// Run collects and exports measurements once per interval until ctx is
+// canceled. Errors are logged but do not stop the Reporter, and an exporter
+// which fails does not prevent measurements from being sent to the others.
+// The exporters are closed before Run returns.
+func (r *Reporter) Run(ctx context.Context) {
+	defer func() {
+		for _, exporter := range r.config.Exporters {
+			if err := exporter.Close(); err != nil {
+				log.WithError(err).Warn("could not close metrics exporter")
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.report(ctx, now)
+		}
+	}
+}
+
+// report collects measurements once and sends them to each exporter.
+func (r *Reporter) report(ctx context.Context, now time.Time) {
+	measurements, err := r.config.Collect()
+	if err != nil {
+		log.WithError(err).Error("could not collect metrics")
+		return
+	}
+	measurements = r.withGlobalTags(measurements)
+	for _, exporter := range r.config.Exporters {
+		exportCtx, cancel := context.WithTimeout(ctx, r.config.Interval)
+		if err := exporter.Export(exportCtx, now, measurements); err != nil {
+			log.WithFields(log.Fields{
+				"error":    err.Error(),
+				"exporter": fmt.Sprintf("%T", exporter),
+			}).Warn("could not export metrics")
+		}
+		cancel()
+	}
+}
+
+// withGlobalTags returns a copy of measurements with Config.Tags added to the
+// tags of each measurement. Tags of the measurement take precedence.
+func (r *Reporter) withGlobalTags(measurements []Measurement) []Measurement {
+	if len(r.config.Tags) == 0 {
+		return measurements
+	}
+	tagged := make([]Measurement, len(measurements))
+	for i, measurement := range measurements {
+		tags := make(map[string]string, len(r.config.Tags)+len(measurement.Tags))
+		for key, value := range r.config.Tags {
+			tags[key] = value
+		}
+		for key, value := range measurement.Tags {
+			tags[key] = value
+		}
+		measurement.Tags = tags
+		tagged[i] = measurement
+	}
+	return tagged
+}
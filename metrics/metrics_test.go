@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testExporter struct {
+	err      error
+	exported [][]Measurement
+	closed   bool
+}
+
+func (e *testExporter) Export(ctx context.Context, timestamp time.Time, measurements []Measurement) error {
+	e.exported = append(e.exported, measurements)
+	return e.err
+}
+
+func (e *testExporter) Close() error {
+	e.closed = true
+	return nil
+}
+
+func TestNewReporterValidatesConfig(t *testing.T) {
+	collect := func() ([]Measurement, error) { return nil, nil }
+	_, err := NewReporter(Config{Interval: time.Second})
+	assert.Error(t, err)
+	_, err = NewReporter(Config{Collect: collect})
+	assert.Error(t, err)
+	_, err = NewReporter(Config{Collect: collect, Interval: time.Second})
+	assert.NoError(t, err)
+}
+
+func TestReporterReport(t *testing.T) {
+	failingExporter := &testExporter{err: errors.New("backend unavailable")}
+	exporter := &testExporter{}
+	reporter, err := NewReporter(Config{
+		Collect: func() ([]Measurement, error) {
+			return []Measurement{
+				{Name: "mesh.num_peers", Kind: Gauge, Value: 8},
+				{Name: "mesh.topic.num_orders", Kind: Gauge, Value: 2, Tags: map[string]string{"topic": "a", "chain_id": "42"}},
+			}, nil
+		},
+		Exporters: []Exporter{failingExporter, exporter},
+		Interval:  time.Second,
+		Tags:      map[string]string{"chain_id": "1337"},
+	})
+	require.NoError(t, err)
+
+	reporter.report(context.Background(), time.Now())
+
+	// Measurements should still be sent to the second exporter if the first
+	// one fails.
+	assert.Len(t, failingExporter.exported, 1)
+	require.Len(t, exporter.exported, 1)
+	expected := []Measurement{
+		{Name: "mesh.num_peers", Kind: Gauge, Value: 8, Tags: map[string]string{"chain_id": "1337"}},
+		{Name: "mesh.topic.num_orders", Kind: Gauge, Value: 2, Tags: map[string]string{"topic": "a", "chain_id": "42"}},
+	}
+	assert.Equal(t, expected, exporter.exported[0])
+}
+
+func TestReporterRunClosesExporters(t *testing.T) {
+	exporter := &testExporter{}
+	reporter, err := NewReporter(Config{
+		Collect: func() ([]Measurement, error) {
+			return nil, nil
+		},
+		Exporters: []Exporter{exporter},
+		Interval:  time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	reporter.Run(ctx)
+	assert.True(t, exporter.closed, "exporter was not closed")
+	assert.NotEmpty(t, exporter.exported)
+}
@@ -0,0 +1,222 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// otlpServiceName is the value of the service.name resource attribute.
+	otlpServiceName = "0x-mesh"
+	// otlpScopeName is the name of the instrumentation scope for all metrics.
+	otlpScopeName = "github.com/0xProject/0x-mesh"
+	// otlpAggregationTemporalityCumulative is the value of the
+	// AGGREGATION_TEMPORALITY_CUMULATIVE enum in the OTLP protocol.
+	otlpAggregationTemporalityCumulative = 2
+	// otlpMaxErrorBodySize is the maximum number of bytes of the response body
+	// included in errors.
+	otlpMaxErrorBodySize = 512
+)
+
+// OTLPExporter is an Exporter which sends metrics to an OpenTelemetry
+// collector (or any other backend which accepts OTLP) using the OTLP/HTTP
+// protocol with JSON encoding. Gauges are sent as OTLP gauges and counters
+// are sent as cumulative monotonic sums.
+type OTLPExporter struct {
+	endpoint  string
+	headers   map[string]string
+	client    *http.Client
+	startTime time.Time
+}
+
+// NewOTLPExporter creates and returns a new OTLPExporter which sends metrics
+// to the given URL (e.g. "http://localhost:4318/v1/metrics"). headers are
+// added to each request, which can be used for authentication.
+func NewOTLPExporter(endpoint string, headers map[string]string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:  endpoint,
+		headers:   headers,
+		client:    &http.Client{},
+		startTime: time.Now(),
+	}
+}
+
+// ParseOTLPHeaders parses a comma-separated list of headers of the form
+// "key=value" (the same format as the OTEL_EXPORTER_OTLP_HEADERS environment
+// variable).
+func ParseOTLPHeaders(commaSeparatedHeaders string) (map[string]string, error) {
+	headers := map[string]string{}
+	if commaSeparatedHeaders == "" {
+		return headers, nil
+	}
+	for _, header := range strings.Split(commaSeparatedHeaders, ",") {
+		pair := strings.SplitN(header, "=", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("invalid header: %q (expected key=value)", header)
+		}
+		headers[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+	}
+	return headers, nil
+}
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpMetric struct {
+	Name  string     `json:"name"`
+	Gauge *otlpGauge `json:"gauge,omitempty"`
+	Sum   *otlpSum   `json:"sum,omitempty"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality"`
+	IsMonotonic            bool            `json:"isMonotonic"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(ctx context.Context, timestamp time.Time, measurements []Measurement) error {
+	body, err := json.Marshal(e.newRequest(timestamp, measurements))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		resBody, _ := ioutil.ReadAll(io.LimitReader(res.Body, otlpMaxErrorBodySize))
+		return fmt.Errorf("unexpected response status from OTLP endpoint: %s: %s", res.Status, string(resBody))
+	}
+	return nil
+}
+
+// newRequest converts measurements to an OTLP request. All measurements with
+// the same name are combined into a single metric with one data point for
+// each set of tags.
+func (e *OTLPExporter) newRequest(timestamp time.Time, measurements []Measurement) otlpRequest {
+	timeUnixNano := strconv.FormatInt(timestamp.UnixNano(), 10)
+	startTimeUnixNano := strconv.FormatInt(e.startTime.UnixNano(), 10)
+	metrics := []otlpMetric{}
+	metricIndexes := map[string]int{}
+	for _, measurement := range measurements {
+		dataPoint := otlpDataPoint{
+			Attributes:   otlpAttributes(measurement.Tags),
+			TimeUnixNano: timeUnixNano,
+			AsDouble:     measurement.Value,
+		}
+		i, found := metricIndexes[measurement.Name]
+		if !found {
+			i = len(metrics)
+			metricIndexes[measurement.Name] = i
+			metric := otlpMetric{Name: measurement.Name}
+			if measurement.Kind == Counter {
+				metric.Sum = &otlpSum{
+					AggregationTemporality: otlpAggregationTemporalityCumulative,
+					IsMonotonic:            true,
+				}
+			} else {
+				metric.Gauge = &otlpGauge{}
+			}
+			metrics = append(metrics, metric)
+		}
+		if metrics[i].Sum != nil {
+			dataPoint.StartTimeUnixNano = startTimeUnixNano
+			metrics[i].Sum.DataPoints = append(metrics[i].Sum.DataPoints, dataPoint)
+		} else {
+			metrics[i].Gauge.DataPoints = append(metrics[i].Gauge.DataPoints, dataPoint)
+		}
+	}
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{
+			{
+				Resource: otlpResource{
+					Attributes: otlpAttributes(map[string]string{"service.name": otlpServiceName}),
+				},
+				ScopeMetrics: []otlpScopeMetrics{
+					{
+						Scope:   otlpScope{Name: otlpScopeName},
+						Metrics: metrics,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Close implements Exporter.
+func (e *OTLPExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+func otlpAttributes(tags map[string]string) []otlpKeyValue {
+	if len(tags) == 0 {
+		return nil
+	}
+	attributes := make([]otlpKeyValue, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		attributes = append(attributes, otlpKeyValue{
+			Key:   key,
+			Value: otlpAnyValue{StringValue: tags[key]},
+		})
+	}
+	return attributes
+}
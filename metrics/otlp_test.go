@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOTLPHeaders(t *testing.T) {
+	headers, err := ParseOTLPHeaders("")
+	require.NoError(t, err)
+	assert.Empty(t, headers)
+
+	headers, err = ParseOTLPHeaders("Authorization=Bearer abc=, X-Scope-OrgID=mesh")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer abc=", "X-Scope-OrgID": "mesh"}, headers)
+
+	_, err = ParseOTLPHeaders("Authorization")
+	assert.Error(t, err)
+	_, err = ParseOTLPHeaders("=value")
+	assert.Error(t, err)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received otlpRequest
+	var receivedHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, map[string]string{"Authorization": "Bearer abc"})
+	defer exporter.Close()
+	timestamp := time.Unix(1600000000, 0)
+	measurements := []Measurement{
+		{Name: "mesh.topic.num_orders", Kind: Gauge, Value: 3, Tags: map[string]string{"topic": "a"}},
+		{Name: "mesh.inbound_queue_dropped_messages", Kind: Counter, Value: 5},
+		{Name: "mesh.topic.num_orders", Kind: Gauge, Value: 4, Tags: map[string]string{"topic": "b"}},
+	}
+	require.NoError(t, exporter.Export(context.Background(), timestamp, measurements))
+
+	assert.Equal(t, "application/json", receivedHeaders.Get("Content-Type"))
+	assert.Equal(t, "Bearer abc", receivedHeaders.Get("Authorization"))
+	require.Len(t, received.ResourceMetrics, 1)
+	assert.Equal(t, []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: otlpServiceName}}}, received.ResourceMetrics[0].Resource.Attributes)
+	require.Len(t, received.ResourceMetrics[0].ScopeMetrics, 1)
+	metrics := received.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+
+	// Measurements with the same name are combined into one metric.
+	assert.Equal(t, "mesh.topic.num_orders", metrics[0].Name)
+	require.NotNil(t, metrics[0].Gauge)
+	assert.Nil(t, metrics[0].Sum)
+	expectedDataPoints := []otlpDataPoint{
+		{
+			Attributes:   []otlpKeyValue{{Key: "topic", Value: otlpAnyValue{StringValue: "a"}}},
+			TimeUnixNano: "1600000000000000000",
+			AsDouble:     3,
+		},
+		{
+			Attributes:   []otlpKeyValue{{Key: "topic", Value: otlpAnyValue{StringValue: "b"}}},
+			TimeUnixNano: "1600000000000000000",
+			AsDouble:     4,
+		},
+	}
+	assert.Equal(t, expectedDataPoints, metrics[0].Gauge.DataPoints)
+
+	assert.Equal(t, "mesh.inbound_queue_dropped_messages", metrics[1].Name)
+	require.NotNil(t, metrics[1].Sum)
+	assert.Nil(t, metrics[1].Gauge)
+	assert.Equal(t, otlpAggregationTemporalityCumulative, metrics[1].Sum.AggregationTemporality)
+	assert.True(t, metrics[1].Sum.IsMonotonic)
+	require.Len(t, metrics[1].Sum.DataPoints, 1)
+	assert.NotEmpty(t, metrics[1].Sum.DataPoints[0].StartTimeUnixNano)
+	assert.Equal(t, float64(5), metrics[1].Sum.DataPoints[0].AsDouble)
+}
+
+func TestOTLPExporterReturnsErrorForFailedRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid metrics", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, nil)
+	defer exporter.Close()
+	err := exporter.Export(context.Background(), time.Now(), []Measurement{{Name: "mesh.num_peers", Value: 1}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid metrics")
+}
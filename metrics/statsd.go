@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statsDMaxPacketSize is the maximum size of a single UDP packet sent to
+// StatsD. It is small enough to avoid fragmentation on typical networks.
+const statsDMaxPacketSize = 1432
+
+// StatsDFormat determines how tags are sent to StatsD.
+type StatsDFormat string
+
+const (
+	// StatsDFormatPlain sends metrics in the original StatsD format, which
+	// doesn't support tags. Tags are dropped.
+	StatsDFormatPlain StatsDFormat = "plain"
+	// StatsDFormatDatadog sends tags using the DogStatsD extension (e.g.
+	// "mesh.num_peers:8|g|#chain_id:1"), which is also supported by Telegraf
+	// and the StatsD exporter for Prometheus.
+	StatsDFormatDatadog StatsDFormat = "datadog"
+)
+
+// ParseStatsDFormat parses a StatsDFormat. It returns an error if the format
+// is not supported.
+func ParseStatsDFormat(format string) (StatsDFormat, error) {
+	switch StatsDFormat(format) {
+	case StatsDFormatPlain, StatsDFormatDatadog:
+		return StatsDFormat(format), nil
+	default:
+		return "", fmt.Errorf("unsupported StatsD format: %q (expected %q or %q)", format, StatsDFormatPlain, StatsDFormatDatadog)
+	}
+}
+
+// statsDReplacer replaces the characters which have a special meaning in the
+// StatsD protocol.
+var statsDReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_")
+
+// StatsDExporter is an Exporter which sends metrics to StatsD (or a
+// compatible agent such as the Datadog agent) over UDP. Gauges are sent as
+// StatsD gauges and counters are sent as StatsD counters with the increase
+// since the previous export.
+type StatsDExporter struct {
+	conn   net.Conn
+	format StatsDFormat
+	mut    sync.Mutex
+	// lastCounterValues maps each counter (including its tags) to the value
+	// that was last exported.
+	lastCounterValues map[string]float64
+}
+
+// NewStatsDExporter creates and returns a new StatsDExporter which sends
+// metrics to the given UDP address (e.g. "localhost:8125").
+func NewStatsDExporter(addr string, format StatsDFormat) (*StatsDExporter, error) {
+	if _, err := ParseStatsDFormat(string(format)); err != nil {
+		return nil, err
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDExporter{
+		conn:              conn,
+		format:            format,
+		lastCounterValues: map[string]float64{},
+	}, nil
+}
+
+// Export implements Exporter. StatsD doesn't support timestamps, so timestamp
+// is ignored.
+func (e *StatsDExporter) Export(ctx context.Context, timestamp time.Time, measurements []Measurement) error {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := e.conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	packet := &bytes.Buffer{}
+	for _, measurement := range measurements {
+		line, ok := e.formatLine(measurement)
+		if !ok {
+			continue
+		}
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsDMaxPacketSize {
+			if _, err := e.conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	if packet.Len() > 0 {
+		if _, err := e.conn.Write(packet.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatLine formats the measurement as a single StatsD line. It returns false
+// if there is nothing to send (i.e. a counter that hasn't changed).
+func (e *StatsDExporter) formatLine(measurement Measurement) (string, bool) {
+	value := measurement.Value
+	metricType := "g"
+	if measurement.Kind == Counter {
+		key := seriesKey(measurement)
+		lastValue := e.lastCounterValues[key]
+		e.lastCounterValues[key] = value
+		if value >= lastValue {
+			value -= lastValue
+		}
+		// Otherwise the counter was reset, so the whole value is new.
+		if value == 0 {
+			return "", false
+		}
+		metricType = "c"
+	}
+	line := fmt.Sprintf("%s:%s|%s", statsDReplacer.Replace(measurement.Name), strconv.FormatFloat(value, 'f', -1, 64), metricType)
+	if e.format == StatsDFormatDatadog && len(measurement.Tags) > 0 {
+		tags := make([]string, 0, len(measurement.Tags))
+		for _, key := range sortedKeys(measurement.Tags) {
+			tags = append(tags, statsDReplacer.Replace(key)+":"+statsDReplacer.Replace(measurement.Tags[key]))
+		}
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line, true
+}
+
+// Close implements Exporter.
+func (e *StatsDExporter) Close() error {
+	return e.conn.Close()
+}
+
+// seriesKey returns a string which uniquely identifies the metric and tags of
+// the given measurement.
+func seriesKey(measurement Measurement) string {
+	key := measurement.Name
+	for _, tagKey := range sortedKeys(measurement.Tags) {
+		key += "," + tagKey + "=" + measurement.Tags[tagKey]
+	}
+	return key
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
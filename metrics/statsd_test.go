@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatsDFormat(t *testing.T) {
+	format, err := ParseStatsDFormat("datadog")
+	require.NoError(t, err)
+	assert.Equal(t, StatsDFormatDatadog, format)
+	format, err = ParseStatsDFormat("plain")
+	require.NoError(t, err)
+	assert.Equal(t, StatsDFormatPlain, format)
+	_, err = ParseStatsDFormat("graphite")
+	assert.Error(t, err)
+}
+
+func TestStatsDExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	testCases := []struct {
+		format        StatsDFormat
+		expectedFirst []string
+		expectedNext  []string
+	}{
+		{
+			format: StatsDFormatDatadog,
+			expectedFirst: []string{
+				"mesh.num_peers:8|g|#chain_id:1,peer_id:a_b",
+				"mesh.inbound_queue_dropped_messages:5|c",
+			},
+			// Counters are sent as the increase since the last export.
+			expectedNext: []string{
+				"mesh.num_peers:8|g|#chain_id:1,peer_id:a_b",
+				"mesh.inbound_queue_dropped_messages:2|c",
+			},
+		},
+		{
+			format: StatsDFormatPlain,
+			expectedFirst: []string{
+				"mesh.num_peers:8|g",
+				"mesh.inbound_queue_dropped_messages:5|c",
+			},
+			expectedNext: []string{
+				"mesh.num_peers:8|g",
+				"mesh.inbound_queue_dropped_messages:2|c",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		exporter, err := NewStatsDExporter(conn.LocalAddr().String(), tc.format)
+		require.NoError(t, err)
+
+		numPeers := Measurement{Name: "mesh.num_peers", Kind: Gauge, Value: 8, Tags: map[string]string{"peer_id": "a:b", "chain_id": "1"}}
+		dropped := Measurement{Name: "mesh.inbound_queue_dropped_messages", Kind: Counter, Value: 5}
+		require.NoError(t, exporter.Export(context.Background(), time.Now(), []Measurement{numPeers, dropped}))
+		assert.Equal(t, tc.expectedFirst, readStatsDPacket(t, conn), string(tc.format))
+
+		dropped.Value = 7
+		require.NoError(t, exporter.Export(context.Background(), time.Now(), []Measurement{numPeers, dropped}))
+		assert.Equal(t, tc.expectedNext, readStatsDPacket(t, conn), string(tc.format))
+
+		// Counters which haven't changed are not sent at all.
+		require.NoError(t, exporter.Export(context.Background(), time.Now(), []Measurement{numPeers, dropped}))
+		assert.Equal(t, tc.expectedNext[:1], readStatsDPacket(t, conn), string(tc.format))
+
+		require.NoError(t, exporter.Close())
+	}
+}
+
+func TestStatsDExporterSplitsPackets(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	exporter, err := NewStatsDExporter(conn.LocalAddr().String(), StatsDFormatPlain)
+	require.NoError(t, err)
+	defer exporter.Close()
+
+	measurements := make([]Measurement, 200)
+	for i := range measurements {
+		measurements[i] = Measurement{Name: "mesh.some_long_metric_name", Kind: Gauge, Value: float64(i)}
+	}
+	require.NoError(t, exporter.Export(context.Background(), time.Now(), measurements))
+
+	numLines := 0
+	for numLines < len(measurements) {
+		lines := readStatsDPacket(t, conn)
+		require.NotEmpty(t, lines)
+		numLines += len(lines)
+	}
+	assert.Equal(t, len(measurements), numLines)
+}
+
+func readStatsDPacket(t *testing.T, conn net.PacketConn) []string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, statsDMaxPacketSize*2)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.True(t, n <= statsDMaxPacketSize, "packet of %d bytes exceeds the maximum packet size", n)
+	return strings.Split(string(buf[:n]), "\n")
+}
@@ -39,6 +39,10 @@ type Filter struct {
 	orderSchema          *jsonschema.Schema
 	messageSchema        *jsonschema.Schema
 	exchangeAddress      common.Address
+	// networkID is an optional identifier for a private Mesh network. If it is
+	// not empty, it is included in the topic and rendezvous point so that the
+	// filter does not share orders with the main 0x Mesh network.
+	networkID string
 }
 
 // TODO(jalextowle): We do not need `contractAddresses` since we only use `contractAddresses.Exchange`.
@@ -20,6 +20,10 @@ type Filter struct {
 	chainID              int
 	rawCustomOrderSchema string
 	exchangeAddress      common.Address
+	// networkID is an optional identifier for a private Mesh network. If it is
+	// not empty, it is included in the topic and rendezvous point so that the
+	// filter does not share orders with the main 0x Mesh network.
+	networkID string
 }
 
 func New(chainID int, customOrderSchema string, contractAddresses ethereum.ContractAddresses) (*Filter, error) {
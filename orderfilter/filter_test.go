@@ -1,6 +1,7 @@
 package orderfilter
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
@@ -433,3 +434,57 @@ func TestDefaultOrderSchemaTopic(t *testing.T) {
 	expectedTopic := "/0x-orders/version/3/chain/1337/schema/e30="
 	assert.Equal(t, expectedTopic, defaultTopic, "the topic for the default filter should not change")
 }
+
+func TestNetworkIDTopic(t *testing.T) {
+	chainID := 1337
+	defaultFilter, err := GetDefaultFilter(chainID, contractAddresses)
+	require.NoError(t, err)
+	filter := defaultFilter.WithNetworkID("my-network")
+	assert.Equal(t, "my-network", filter.NetworkID())
+	assert.Equal(t, "", defaultFilter.NetworkID(), "WithNetworkID should not modify the original filter")
+
+	expectedTopic := "/0x-orders/network/my-network/version/3/chain/1337/schema/e30="
+	assert.Equal(t, expectedTopic, filter.Topic())
+	assert.NotEqual(t, defaultFilter.Rendezvous(), filter.Rendezvous())
+
+	newFilter, err := NewFromTopic(filter.Topic(), contractAddresses)
+	require.NoError(t, err)
+	assert.Equal(t, "my-network", newFilter.NetworkID())
+	assert.Equal(t, expectedTopic, newFilter.Topic())
+
+	encoded, err := json.Marshal(filter)
+	require.NoError(t, err)
+	var decoded Filter
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, expectedTopic, decoded.Topic())
+
+	_, err = NewFromTopic("/0x-orders/network/not valid/version/3/chain/1337/schema/e30=", contractAddresses)
+	assert.Error(t, err)
+}
+
+func TestFilterEqual(t *testing.T) {
+	filter, err := New(constants.TestChainID, `{"properties":{"senderAddress":{"type":"string","pattern":"0x00000000000000000000000000000000ba5eba11"}}}`, contractAddresses)
+	require.NoError(t, err)
+	// Semantically identical schemas are equal.
+	reorderedFilter, err := New(constants.TestChainID, `{"properties": {"senderAddress": {"pattern": "0x00000000000000000000000000000000ba5eba11", "type": "string"}}}`, contractAddresses)
+	require.NoError(t, err)
+	assert.True(t, filter.Equal(reorderedFilter))
+
+	defaultFilter, err := GetDefaultFilter(constants.TestChainID, contractAddresses)
+	require.NoError(t, err)
+	assert.False(t, filter.Equal(defaultFilter))
+	assert.False(t, defaultFilter.Equal(defaultFilter.WithNetworkID("my-network")))
+
+	// The exchange address is not part of the topic.
+	otherExchangeFilter, err := GetDefaultFilter(constants.TestChainID, ethereum.ContractAddresses{Exchange: common.HexToAddress("0x61935cbdd02287b511119ddb11aeb42f1593b7ef")})
+	require.NoError(t, err)
+	assert.Equal(t, defaultFilter.Topic(), otherExchangeFilter.Topic())
+	assert.False(t, defaultFilter.Equal(otherExchangeFilter))
+}
+
+func TestValidateNetworkID(t *testing.T) {
+	assert.NoError(t, ValidateNetworkID("my_network-1"))
+	assert.Error(t, ValidateNetworkID(""))
+	assert.Error(t, ValidateNetworkID("my/network"))
+	assert.Error(t, ValidateNetworkID(strings.Repeat("a", maxNetworkIDLength+1)))
+}
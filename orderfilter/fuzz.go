@@ -0,0 +1,56 @@
+// +build gofuzz
+
+package orderfilter
+
+import (
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum"
+)
+
+// This file contains harnesses for go-fuzz (https://github.com/dvyukov/go-fuzz)
+// and libFuzzer. They are run with `make fuzz`. Custom order schemas are
+// received from untrusted peers as part of their pubsub topics, and order
+// messages are received via GossipSub, so the functions exercised here must
+// never panic.
+//
+// Each harness returns 1 if the input was parsed successfully (which tells the
+// fuzzer to prioritize it when generating new inputs) and 0 otherwise.
+
+// fuzzOrderMessageJSON is the order message which is validated against the
+// filters compiled by FuzzCustomOrderSchema.
+var fuzzOrderMessageJSON = []byte(`{"messageType":"order","order":{"makerAddress":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149","takerAddress":"0x0000000000000000000000000000000000000000","makerAssetAmount":"100000000000000000000","takerAssetAmount":"100000000000000000000000","expirationTimeSeconds":"1559856615025","makerFee":"0","takerFee":"0","feeRecipientAddress":"0x0000000000000000000000000000000000000000","senderAddress":"0x0000000000000000000000000000000000000000","salt":"46108882540880341679561755865076495033942060608820537332859096815711589201849","makerAssetData":"0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498","takerAssetData":"0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2","makerFeeAssetData":"0x","takerFeeAssetData":"0x","exchangeAddress":"0x48bacb9266a570d521063ef5dd96e61686dbe788","chainId":1337,"signature":"0x1c52f75daa4bd2ad9e6e8a7c35adbd089d709e48ae86463f2abfafa3578747fafc264a04d02fa26227e90476d57bca94e24af32f1cc8da444bba21092ca56cd85603"},"topics":["/0x-orders/version/3/chain/1337/schema/e30="]}`)
+
+var fuzzDefaultFilter *Filter
+
+func init() {
+	var err error
+	fuzzDefaultFilter, err = GetDefaultFilter(constants.TestChainID, ethereum.GanacheAddresses)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// FuzzCustomOrderSchema compiles data as a custom order schema. If it compiles,
+// an order message is validated against it and the topic of the resulting
+// filter must be parsable.
+func FuzzCustomOrderSchema(data []byte) int {
+	filter, err := New(constants.TestChainID, string(data), ethereum.GanacheAddresses)
+	if err != nil {
+		return 0
+	}
+	_, _ = filter.MatchOrderMessageJSON(fuzzOrderMessageJSON)
+	if _, err := NewFromTopic(filter.Topic(), ethereum.GanacheAddresses); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// FuzzOrderMessageJSON validates data as an order message received via
+// GossipSub using the default filter.
+func FuzzOrderMessageJSON(data []byte) int {
+	matches, err := fuzzDefaultFilter.MatchOrderMessageJSON(data)
+	if err != nil || !matches {
+		return 0
+	}
+	return 1
+}
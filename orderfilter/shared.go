@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/0xProject/0x-mesh/ethereum"
 	"github.com/0xProject/0x-mesh/zeroex"
@@ -22,8 +25,34 @@ const (
 	fullTopicFormat             = "/0x-orders/version/%d/chain/%d/schema/%s"
 	rendezvousVersion           = 1
 	fullRendezvousFormat        = "/0x-custom-filter-rendezvous/version/%d/chain/%d/schema/%s"
+	// networkTopicPrefix is the prefix for topics that belong to a private
+	// network. It is followed by the network ID and then a topic in the usual
+	// format without the "/0x-orders" prefix.
+	networkTopicPrefix       = "/0x-orders/network/"
+	networkTopicFormat       = "/0x-orders/network/%s/version/%d/chain/%d/schema/%s"
+	networkRendezvousFormat  = "/0x-custom-filter-rendezvous/network/%s/version/%d/chain/%d/schema/%s"
+	defaultOrdersTopicPrefix = "/0x-orders"
+	maxNetworkIDLength       = 64
 )
 
+var networkIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateNetworkID returns an error if the given network ID cannot be used to
+// identify a private network. Valid network IDs consist of at most 64 letters,
+// digits, hyphens, and underscores.
+func ValidateNetworkID(networkID string) error {
+	if networkID == "" {
+		return errors.New("network ID cannot be empty")
+	}
+	if len(networkID) > maxNetworkIDLength {
+		return fmt.Errorf("network ID cannot be longer than %d characters", maxNetworkIDLength)
+	}
+	if !networkIDRegex.MatchString(networkID) {
+		return fmt.Errorf("network ID %q may only contain letters, digits, hyphens, and underscores", networkID)
+	}
+	return nil
+}
+
 type WrongTopicVersionError struct {
 	expectedVersion int
 	actualVersion   int
@@ -58,6 +87,21 @@ func (f *Filter) MatchOrder(order *zeroex.SignedOrder) (bool, error) {
 
 func NewFromTopic(topic string, contractAddresses ethereum.ContractAddresses) (*Filter, error) {
 	// TODO(albrow): Use a cache for topic -> filter
+	networkID := ""
+	if strings.HasPrefix(topic, networkTopicPrefix) {
+		// Strip the network ID so that the rest of the topic can be parsed as
+		// usual.
+		rest := strings.TrimPrefix(topic, networkTopicPrefix)
+		i := strings.Index(rest, "/")
+		if i == -1 {
+			return nil, fmt.Errorf("could not parse network ID for topic: %q", topic)
+		}
+		networkID = rest[:i]
+		if err := ValidateNetworkID(networkID); err != nil {
+			return nil, err
+		}
+		topic = defaultOrdersTopicPrefix + rest[i:]
+	}
 	var version int
 	var chainIDAndSchema string
 	if _, err := fmt.Sscanf(topic, topicVersionFormat, &version, &chainIDAndSchema); err != nil {
@@ -78,13 +122,49 @@ func NewFromTopic(topic string, contractAddresses ethereum.ContractAddresses) (*
 	if err != nil {
 		return nil, fmt.Errorf("could not base64-decode order schema: %q", base64EncodedSchema)
 	}
-	return New(chainID, string(customOrderSchema), contractAddresses)
+	filter, err := New(chainID, string(customOrderSchema), contractAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return filter.WithNetworkID(networkID), nil
+}
+
+// WithNetworkID returns a copy of the filter which belongs to the private
+// network with the given ID. The copy has a different topic and rendezvous
+// point than the original so that orders are not shared with nodes outside of
+// the private network. An empty network ID refers to the main 0x Mesh network.
+func (f *Filter) WithNetworkID(networkID string) *Filter {
+	filterCopy := *f
+	filterCopy.networkID = networkID
+	return &filterCopy
+}
+
+// NetworkID returns the ID of the private network that the filter belongs to,
+// or an empty string if it belongs to the main 0x Mesh network.
+func (f *Filter) NetworkID() string {
+	return f.networkID
+}
+
+// ExchangeAddress returns the address of the exchange contract that orders
+// must be for in order to match the filter.
+func (f *Filter) ExchangeAddress() common.Address {
+	return f.exchangeAddress
+}
+
+// Equal returns true if both filters match exactly the same orders. Filters
+// with the same topic only differ if they are for different exchange
+// contracts, since the exchange address is not part of the topic.
+func (f *Filter) Equal(other *Filter) bool {
+	return f.Topic() == other.Topic() && f.exchangeAddress == other.exchangeAddress
 }
 
 func (f *Filter) Rendezvous() string {
 	if f.encodedSchema == "" {
 		f.encodedSchema = f.generateEncodedSchema()
 	}
+	if f.networkID != "" {
+		return fmt.Sprintf(networkRendezvousFormat, f.networkID, rendezvousVersion, f.chainID, f.encodedSchema)
+	}
 	return fmt.Sprintf(fullRendezvousFormat, rendezvousVersion, f.chainID, f.encodedSchema)
 }
 
@@ -92,6 +172,9 @@ func (f *Filter) Topic() string {
 	if f.encodedSchema == "" {
 		f.encodedSchema = f.generateEncodedSchema()
 	}
+	if f.networkID != "" {
+		return fmt.Sprintf(networkTopicFormat, f.networkID, pubsubTopicVersion, f.chainID, f.encodedSchema)
+	}
 	return fmt.Sprintf(fullTopicFormat, pubsubTopicVersion, f.chainID, f.encodedSchema)
 }
 
@@ -145,6 +228,7 @@ type jsonMarshallerForFilter struct {
 	CustomOrderSchema string         `json:"customOrderSchema"`
 	ChainID           int            `json:"chainID"`
 	ExchangeAddress   common.Address `json:"exchangeAddress"`
+	NetworkID         string         `json:"networkID,omitempty"`
 }
 
 func (f *Filter) MarshalJSON() ([]byte, error) {
@@ -152,6 +236,7 @@ func (f *Filter) MarshalJSON() ([]byte, error) {
 		CustomOrderSchema: f.rawCustomOrderSchema,
 		ChainID:           f.chainID,
 		ExchangeAddress:   f.exchangeAddress,
+		NetworkID:         f.networkID,
 	}
 	return json.Marshal(j)
 }
@@ -163,6 +248,6 @@ func (f *Filter) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
-	*f = *filter
+	*f = *filter.WithNetworkID(j.NetworkID)
 	return nil
 }
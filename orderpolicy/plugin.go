@@ -0,0 +1,49 @@
+// +build !js
+
+package orderpolicy
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// Load opens the Go plugin at the given path and returns the policy it
+// exports. Plugins must be built with the same version of Go and of this
+// package as Mesh itself (see https://golang.org/pkg/plugin).
+func Load(path string) (Policy, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(SymbolName)
+	if err != nil {
+		return nil, err
+	}
+	return fromSymbol(symbol)
+}
+
+// fromSymbol converts a symbol exported by a plugin into a Policy. Exported
+// functions are returned as-is by plugin.Lookup while exported variables are
+// returned as pointers.
+func fromSymbol(symbol plugin.Symbol) (Policy, error) {
+	switch s := symbol.(type) {
+	case func(*zeroex.SignedOrder) Decision:
+		return Func(s), nil
+	case *func(*zeroex.SignedOrder) Decision:
+		if *s == nil {
+			return nil, fmt.Errorf("plugin symbol %s is nil", SymbolName)
+		}
+		return Func(*s), nil
+	case *Policy:
+		if *s == nil {
+			return nil, fmt.Errorf("plugin symbol %s is nil", SymbolName)
+		}
+		return *s, nil
+	case Policy:
+		return s, nil
+	default:
+		return nil, fmt.Errorf("plugin symbol %s has unsupported type %T", SymbolName, symbol)
+	}
+}
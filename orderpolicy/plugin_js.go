@@ -0,0 +1,9 @@
+// +build js,wasm
+
+package orderpolicy
+
+// Load always returns ErrPluginsNotSupported since Go plugins cannot be loaded
+// in the browser. Set core.Config.OrderPolicy directly instead.
+func Load(path string) (Policy, error) {
+	return nil, ErrPluginsNotSupported
+}
@@ -0,0 +1,55 @@
+// +build !js
+
+package orderpolicy
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rejectAllPolicy struct{}
+
+func (rejectAllPolicy) Evaluate(*zeroex.SignedOrder) Decision {
+	return Decision{Reject: true, Reason: "no orders allowed"}
+}
+
+func annotateAll(*zeroex.SignedOrder) Decision {
+	return Decision{Annotations: map[string]string{"listed": "true"}}
+}
+
+func TestFromSymbol(t *testing.T) {
+	order := &zeroex.SignedOrder{}
+
+	// Exported function.
+	policy, err := fromSymbol(annotateAll)
+	require.NoError(t, err)
+	assert.Equal(t, "true", policy.Evaluate(order).Annotations["listed"])
+
+	// Exported variable holding a function.
+	funcVar := annotateAll
+	policy, err = fromSymbol(&funcVar)
+	require.NoError(t, err)
+	assert.Equal(t, "true", policy.Evaluate(order).Annotations["listed"])
+
+	// Exported variable of an interface type.
+	var policyVar Policy = rejectAllPolicy{}
+	policy, err = fromSymbol(&policyVar)
+	require.NoError(t, err)
+	assert.True(t, policy.Evaluate(order).Reject)
+
+	// Exported variable of a concrete type.
+	concreteVar := rejectAllPolicy{}
+	policy, err = fromSymbol(&concreteVar)
+	require.NoError(t, err)
+	assert.True(t, policy.Evaluate(order).Reject)
+
+	// Unsupported types.
+	_, err = fromSymbol(42)
+	assert.Error(t, err)
+	var nilPolicy Policy
+	_, err = fromSymbol(&nilPolicy)
+	assert.Error(t, err)
+}
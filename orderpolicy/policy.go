@@ -0,0 +1,49 @@
+// Package orderpolicy allows operators to supply custom listing rules without
+// forking Mesh. A policy inspects each new order before it is stored and
+// decides whether to accept it, reject it, or accept it with annotations which
+// are stored alongside the order.
+package orderpolicy
+
+import (
+	"errors"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// SymbolName is the name of the symbol that policy plugins must export. It
+// must either be a function with the same signature as Func or a variable which
+// implements Policy.
+const SymbolName = "OrderPolicy"
+
+// ErrPluginsNotSupported is returned by Load on platforms which do not support
+// loading plugins (e.g. in the browser).
+var ErrPluginsNotSupported = errors.New("order policy plugins are not supported on this platform")
+
+// Decision is the result of evaluating an order against a policy. The zero
+// value accepts the order without any annotations.
+type Decision struct {
+	// Reject indicates that the order should be rejected.
+	Reject bool
+	// Reason is an optional human-readable explanation of why the order was
+	// rejected. It is included in the rejection status.
+	Reason string
+	// Annotations are arbitrary key-value pairs which are stored alongside an
+	// accepted order and returned with it by GetOrders. They are ignored if the
+	// order is rejected.
+	Annotations map[string]string
+}
+
+// Policy decides which new orders should be stored. Evaluate is called once
+// for every new order that passes the built-in Mesh validation, so it should
+// be fast and must be safe for concurrent use.
+type Policy interface {
+	Evaluate(order *zeroex.SignedOrder) Decision
+}
+
+// Func is an adapter which allows the use of ordinary functions as policies.
+type Func func(order *zeroex.SignedOrder) Decision
+
+// Evaluate calls f(order).
+func (f Func) Evaluate(order *zeroex.SignedOrder) Decision {
+	return f(order)
+}
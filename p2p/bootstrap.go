@@ -63,7 +63,14 @@ func BootstrapListToAddrInfos(bootstrapList []string) ([]peer.AddrInfo, error) {
 	return peer.AddrInfosFromP2pAddrs(maddrs...)
 }
 
+// ConnectToBootstrapList connects to the peers in the given bootstrap list.
+// Failed dials are retried with exponential backoff until defaultNetworkTimeout
+// has passed or the circuit breaker for the peer opens.
 func ConnectToBootstrapList(ctx context.Context, host host.Host, bootstrapList []string) error {
+	return connectToBootstrapList(ctx, host, bootstrapList, newDialBackoff())
+}
+
+func connectToBootstrapList(ctx context.Context, host host.Host, bootstrapList []string, backoff *dialBackoff) error {
 	log.WithField("bootstrapList", bootstrapList).Info("connecting to bootstrap peers")
 	bootstrapAddrInfos, err := BootstrapListToAddrInfos(bootstrapList)
 	if err != nil {
@@ -80,12 +87,7 @@ func ConnectToBootstrapList(ctx context.Context, host host.Host, bootstrapList [
 		wg.Add(1)
 		go func(peerInfo peer.AddrInfo) {
 			defer wg.Done()
-			if err := host.Connect(connectCtx, peerInfo); err != nil {
-				log.WithFields(map[string]interface{}{
-					"error":    err.Error(),
-					"peerInfo": peerInfo,
-				}).Warn("failed to connect to bootstrap peer")
-			}
+			dialBootstrapPeer(connectCtx, host, peerInfo, backoff)
 		}(peerInfo)
 	}
 	wg.Wait()
@@ -98,3 +100,44 @@ func ConnectToBootstrapList(ctx context.Context, host host.Host, bootstrapList [
 
 	return nil
 }
+
+// dialBootstrapPeer connects to the given bootstrap peer. Failed dials are
+// retried according to backoff until the connection succeeds, the circuit
+// breaker for the peer opens, or the context is canceled.
+func dialBootstrapPeer(ctx context.Context, host host.Host, peerInfo peer.AddrInfo, backoff *dialBackoff) {
+	for {
+		if backoff.isUnhealthy(peerInfo.ID) {
+			log.WithField("peerInfo", peerInfo).Debug("not connecting to unhealthy bootstrap peer")
+			return
+		}
+		if wait := backoff.waitTime(peerInfo.ID); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		if !backoff.allow(peerInfo.ID) {
+			continue
+		}
+		err := host.Connect(ctx, peerInfo)
+		if err == nil {
+			backoff.recordSuccess(peerInfo.ID)
+			return
+		}
+		logFields := map[string]interface{}{
+			"error":    err.Error(),
+			"peerInfo": peerInfo,
+		}
+		if backoff.recordFailure(peerInfo.ID) {
+			logFields["cooldown"] = circuitBreakerCooldown.String()
+			log.WithFields(logFields).Warn("failed to connect to bootstrap peer too many times; marking it as unhealthy")
+			return
+		}
+		if ctx.Err() != nil {
+			log.WithFields(logFields).Warn("failed to connect to bootstrap peer")
+			return
+		}
+		log.WithFields(logFields).Debug("failed to connect to bootstrap peer; retrying")
+	}
+}
@@ -0,0 +1,173 @@
+package p2p
+
+import (
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// minDialBackoff is the amount of time to wait before dialing a peer again
+	// after the first failed dial.
+	minDialBackoff = 1 * time.Second
+	// maxDialBackoff is the maximum amount of time to wait before dialing a peer
+	// again after a failed dial.
+	maxDialBackoff = 1 * time.Minute
+	// circuitBreakerThreshold is the number of consecutive failed dials after
+	// which a peer is considered unhealthy and is no longer dialed until
+	// circuitBreakerCooldown has passed.
+	circuitBreakerThreshold = 3
+	// circuitBreakerCooldown is how long to wait before dialing an unhealthy
+	// peer again. If that dial fails, the peer is considered unhealthy for
+	// another circuitBreakerCooldown.
+	circuitBreakerCooldown = 10 * time.Minute
+)
+
+// DialStats contains metrics about dials to the peers in the bootstrap list.
+type DialStats struct {
+	// Attempts is the total number of dials.
+	Attempts uint64
+	// Failures is the total number of failed dials.
+	Failures uint64
+	// Skipped is the total number of dials which were skipped because the peer
+	// was backing off or considered unhealthy.
+	Skipped uint64
+	// UnhealthyPeers is the number of peers which are currently not dialed
+	// because too many consecutive dials failed.
+	UnhealthyPeers int
+}
+
+type peerDialState struct {
+	consecutiveFailures int
+	nextDialTime        time.Time
+}
+
+// dialBackoff keeps track of failed dials and decides whether a peer should be
+// dialed. After each failed dial, the peer is backed off exponentially (with
+// jitter) starting at minDialBackoff. After circuitBreakerThreshold consecutive
+// failures, the circuit breaker for the peer opens and the peer isn't dialed
+// for circuitBreakerCooldown. A successful dial resets the state of the peer.
+// It is safe for concurrent use.
+type dialBackoff struct {
+	mu       sync.Mutex
+	peers    map[peer.ID]*peerDialState
+	attempts uint64
+	failures uint64
+	skipped  uint64
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+func newDialBackoff() *dialBackoff {
+	return &dialBackoff{
+		peers: map[peer.ID]*peerDialState{},
+		now:   time.Now,
+	}
+}
+
+// allow returns true if the given peer may be dialed now. If it returns true,
+// the caller must report the result of the dial with recordSuccess or
+// recordFailure.
+func (b *dialBackoff) allow(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, found := b.peers[id]
+	if found && b.now().Before(state.nextDialTime) {
+		b.skipped++
+		return false
+	}
+	b.attempts++
+	return true
+}
+
+// waitTime returns the amount of time until the given peer may be dialed
+// again, which is 0 if it can be dialed now.
+func (b *dialBackoff) waitTime(id peer.ID) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, found := b.peers[id]
+	if !found {
+		return 0
+	}
+	if wait := state.nextDialTime.Sub(b.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// isFailing returns true if the last dial to the given peer failed.
+func (b *dialBackoff) isFailing(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, found := b.peers[id]
+	return found
+}
+
+// isUnhealthy returns true if the circuit breaker for the given peer is open.
+func (b *dialBackoff) isUnhealthy(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, found := b.peers[id]
+	return found && state.consecutiveFailures >= circuitBreakerThreshold && b.now().Before(state.nextDialTime)
+}
+
+func (b *dialBackoff) recordSuccess(id peer.ID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.peers, id)
+}
+
+// recordFailure records a failed dial and returns true if the circuit breaker
+// for the peer is open as a result.
+func (b *dialBackoff) recordFailure(id peer.ID) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	state, found := b.peers[id]
+	if !found {
+		state = &peerDialState{}
+		b.peers[id] = state
+	}
+	state.consecutiveFailures++
+	if state.consecutiveFailures >= circuitBreakerThreshold {
+		state.nextDialTime = b.now().Add(circuitBreakerCooldown)
+		return true
+	}
+	state.nextDialTime = b.now().Add(backoffDuration(state.consecutiveFailures))
+	return false
+}
+
+func (b *dialBackoff) stats() DialStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	unhealthyPeers := 0
+	now := b.now()
+	for _, state := range b.peers {
+		if state.consecutiveFailures >= circuitBreakerThreshold && now.Before(state.nextDialTime) {
+			unhealthyPeers++
+		}
+	}
+	return DialStats{
+		Attempts:       b.attempts,
+		Failures:       b.failures,
+		Skipped:        b.skipped,
+		UnhealthyPeers: unhealthyPeers,
+	}
+}
+
+// backoffDuration returns the amount of time to wait after the given number of
+// consecutive failed dials. It doubles with each failure up to maxDialBackoff,
+// and up to half of it is random jitter so that many nodes which fail to dial
+// the same peer at the same time don't retry in lockstep.
+func backoffDuration(consecutiveFailures int) time.Duration {
+	backoff := minDialBackoff
+	for i := 1; i < consecutiveFailures && backoff < maxDialBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxDialBackoff {
+		backoff = maxDialBackoff
+	}
+	return backoff/2 + time.Duration(mathrand.Int63n(int64(backoff/2)+1))
+}
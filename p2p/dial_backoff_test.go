@@ -0,0 +1,68 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialBackoff(t *testing.T) {
+	now := time.Now()
+	backoff := newDialBackoff()
+	backoff.now = func() time.Time { return now }
+	id := peer.ID("bootstrap")
+
+	assert.True(t, backoff.allow(id))
+	assert.False(t, backoff.isFailing(id))
+
+	// After a failed dial, the peer is backed off for at least half of
+	// minDialBackoff.
+	assert.False(t, backoff.recordFailure(id))
+	assert.True(t, backoff.isFailing(id))
+	assert.False(t, backoff.isUnhealthy(id))
+	assert.False(t, backoff.allow(id))
+	wait := backoff.waitTime(id)
+	assert.True(t, wait >= minDialBackoff/2 && wait <= minDialBackoff, "unexpected backoff: %s", wait)
+
+	now = now.Add(wait)
+	assert.True(t, backoff.allow(id))
+	assert.False(t, backoff.recordFailure(id))
+	now = now.Add(backoff.waitTime(id))
+	assert.True(t, backoff.allow(id))
+
+	// The circuit breaker opens after circuitBreakerThreshold consecutive
+	// failures.
+	assert.True(t, backoff.recordFailure(id))
+	assert.True(t, backoff.isUnhealthy(id))
+	assert.Equal(t, circuitBreakerCooldown, backoff.waitTime(id))
+	assert.Equal(t, DialStats{Attempts: 3, Failures: 3, Skipped: 1, UnhealthyPeers: 1}, backoff.stats())
+
+	// After the cooldown, the peer is dialed once more. If that fails, the
+	// circuit breaker opens again.
+	now = now.Add(circuitBreakerCooldown)
+	assert.False(t, backoff.isUnhealthy(id))
+	assert.True(t, backoff.allow(id))
+	assert.True(t, backoff.recordFailure(id))
+	assert.True(t, backoff.isUnhealthy(id))
+
+	// A successful dial resets the state of the peer.
+	now = now.Add(circuitBreakerCooldown)
+	assert.True(t, backoff.allow(id))
+	backoff.recordSuccess(id)
+	assert.False(t, backoff.isFailing(id))
+	assert.Equal(t, time.Duration(0), backoff.waitTime(id))
+	assert.Equal(t, DialStats{Attempts: 5, Failures: 4, Skipped: 1, UnhealthyPeers: 0}, backoff.stats())
+}
+
+func TestBackoffDuration(t *testing.T) {
+	for consecutiveFailures := 1; consecutiveFailures <= 10; consecutiveFailures++ {
+		expected := minDialBackoff << uint(consecutiveFailures-1)
+		if expected > maxDialBackoff {
+			expected = maxDialBackoff
+		}
+		actual := backoffDuration(consecutiveFailures)
+		assert.True(t, actual >= expected/2 && actual <= expected, "unexpected backoff after %d failures: %s", consecutiveFailures, actual)
+	}
+}
@@ -1,13 +1,83 @@
 package p2p
 
 import (
+	"fmt"
+	"net"
+	"strings"
+
 	libp2p "github.com/libp2p/go-libp2p"
 	filter "github.com/libp2p/go-maddr-filter"
 )
 
+// privateSubnets are the subnets which are denied if Config.DenyPrivateIPs is
+// true. They include the private, shared, loopback and link-local address
+// ranges for IPv4 and IPv6.
+var privateSubnets = []string{
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
 func Filters(filters *filter.Filters) libp2p.Option {
 	return func(cfg *libp2p.Config) error {
 		cfg.Filters = filters
 		return nil
 	}
 }
+
+// ParseSubnets parses a comma-separated list of subnets in CIDR notation
+// (e.g. "192.0.2.0/24,2001:db8::/32").
+func ParseSubnets(commaSeparatedSubnets string) ([]net.IPNet, error) {
+	if commaSeparatedSubnets == "" {
+		return nil, nil
+	}
+	return parseSubnets(strings.Split(commaSeparatedSubnets, ","))
+}
+
+func parseSubnets(cidrs []string) ([]net.IPNet, error) {
+	subnets := make([]net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, err
+		}
+		subnets[i] = *subnet
+	}
+	return subnets, nil
+}
+
+// newFilters returns the filters which are used to gate incoming and outgoing
+// connections according to the given config. The same filters are also used
+// for banning peers by IP address (see the banner package).
+func newFilters(config Config) (*filter.Filters, error) {
+	filters := filter.NewFilters()
+	// If there are any allowed subnets, connections to all other addresses are
+	// denied by default.
+	if len(config.AllowedSubnets) > 0 {
+		filters.DefaultAction = filter.ActionDeny
+	}
+	for _, subnet := range config.AllowedSubnets {
+		filters.AddFilter(subnet, filter.ActionAccept)
+	}
+	// Note: When more than one filter matches an address, the filter that was
+	// added last takes precedence. Denied subnets are added after allowed
+	// subnets so that they can be used to carve out parts of an allowed subnet.
+	deniedSubnets := config.DeniedSubnets
+	if config.DenyPrivateIPs {
+		parsedPrivateSubnets, err := parseSubnets(privateSubnets)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse private subnets: %s", err.Error())
+		}
+		deniedSubnets = append(parsedPrivateSubnets, deniedSubnets...)
+	}
+	for _, subnet := range deniedSubnets {
+		filters.AddFilter(subnet, filter.ActionDeny)
+	}
+	return filters, nil
+}
@@ -0,0 +1,88 @@
+package p2p
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSubnets(t *testing.T) {
+	subnets, err := ParseSubnets("")
+	require.NoError(t, err)
+	assert.Len(t, subnets, 0)
+
+	subnets, err = ParseSubnets("192.0.2.0/24, 2001:db8::/32")
+	require.NoError(t, err)
+	require.Len(t, subnets, 2)
+	assert.Equal(t, "192.0.2.0/24", subnets[0].String())
+	assert.Equal(t, "2001:db8::/32", subnets[1].String())
+
+	_, err = ParseSubnets("192.0.2.1")
+	assert.Error(t, err)
+	_, err = ParseSubnets("192.0.2.0/24,")
+	assert.Error(t, err)
+}
+
+func TestNewFilters(t *testing.T) {
+	allowedSubnets, err := ParseSubnets("198.51.100.0/24,10.1.0.0/16")
+	require.NoError(t, err)
+	deniedSubnets, err := ParseSubnets("198.51.100.128/25")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		config       Config
+		allowedAddrs []string
+		blockedAddrs []string
+	}{
+		{
+			name:         "no rules",
+			config:       Config{},
+			allowedAddrs: []string{"/ip4/10.0.0.1/tcp/60558", "/ip4/203.0.113.1/tcp/60558", "/ip6/::1/tcp/60558"},
+		},
+		{
+			name:         "deny private IPs",
+			config:       Config{DenyPrivateIPs: true},
+			allowedAddrs: []string{"/ip4/203.0.113.1/tcp/60558", "/ip6/2001:db8::1/tcp/60558"},
+			blockedAddrs: []string{"/ip4/10.0.0.1/tcp/60558", "/ip4/127.0.0.1/tcp/60558", "/ip4/192.168.1.1/tcp/60559/ws", "/ip6/::1/tcp/60558", "/ip6/fd00::1/tcp/60558"},
+		},
+		{
+			name:         "denied subnets",
+			config:       Config{DeniedSubnets: deniedSubnets},
+			allowedAddrs: []string{"/ip4/198.51.100.1/tcp/60558", "/ip4/203.0.113.1/tcp/60558"},
+			blockedAddrs: []string{"/ip4/198.51.100.200/tcp/60558"},
+		},
+		{
+			name:         "allowed subnets",
+			config:       Config{AllowedSubnets: allowedSubnets},
+			allowedAddrs: []string{"/ip4/198.51.100.1/tcp/60558", "/ip4/10.1.2.3/tcp/60558"},
+			blockedAddrs: []string{"/ip4/203.0.113.1/tcp/60558", "/ip4/10.2.0.1/tcp/60558"},
+		},
+		{
+			name: "denied subnets take precedence over allowed subnets",
+			config: Config{
+				DenyPrivateIPs: true,
+				DeniedSubnets:  deniedSubnets,
+				AllowedSubnets: allowedSubnets,
+			},
+			allowedAddrs: []string{"/ip4/198.51.100.1/tcp/60558"},
+			blockedAddrs: []string{"/ip4/198.51.100.200/tcp/60558", "/ip4/10.1.2.3/tcp/60558", "/ip4/203.0.113.1/tcp/60558"},
+		},
+	}
+	for _, tc := range testCases {
+		filters, err := newFilters(tc.config)
+		require.NoError(t, err, tc.name)
+		for _, addr := range tc.allowedAddrs {
+			maddr, err := ma.NewMultiaddr(addr)
+			require.NoError(t, err)
+			assert.False(t, filters.AddrBlocked(maddr), "%s: expected %s to be allowed", tc.name, addr)
+		}
+		for _, addr := range tc.blockedAddrs {
+			maddr, err := ma.NewMultiaddr(addr)
+			require.NoError(t, err)
+			assert.True(t, filters.AddrBlocked(maddr), "%s: expected %s to be blocked", tc.name, addr)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// GossipSubParams configures the mesh of peers that GossipSub maintains for
+// each topic. Messages are forwarded to the peers in the mesh, so a larger mesh
+// propagates messages faster at the cost of more bandwidth. Zero values keep
+// the defaults of go-libp2p-pubsub.
+//
+// Note: The version of go-libp2p-pubsub used by Mesh only supports global
+// parameters, so they apply to every topic and to every node in the process.
+type GossipSubParams struct {
+	// D is the target number of peers in the mesh of each topic (6 by
+	// default).
+	D int
+	// DLow is the number of peers below which more peers are added to the
+	// mesh (4 by default).
+	DLow int
+	// DHigh is the number of peers above which peers are removed from the mesh
+	// (12 by default).
+	DHigh int
+	// HeartbeatInterval is how often the mesh is maintained and gossip about
+	// recent messages is sent to peers outside of the mesh (1s by default).
+	HeartbeatInterval time.Duration
+}
+
+// defaultGossipSubParams are the defaults of go-libp2p-pubsub. They are
+// captured when the package is initialized, before apply changes the globals
+// of go-libp2p-pubsub, so that a node which keeps the defaults is not affected
+// by the parameters of a node that was created before it.
+var defaultGossipSubParams = GossipSubParams{
+	D:                 pubsub.GossipSubD,
+	DLow:              pubsub.GossipSubDlo,
+	DHigh:             pubsub.GossipSubDhi,
+	HeartbeatInterval: pubsub.GossipSubHeartbeatInterval,
+}
+
+// withDefaults returns a copy of p where zero values are replaced with the
+// defaults of go-libp2p-pubsub.
+func (p GossipSubParams) withDefaults() GossipSubParams {
+	if p.D == 0 {
+		p.D = defaultGossipSubParams.D
+	}
+	if p.DLow == 0 {
+		p.DLow = defaultGossipSubParams.DLow
+	}
+	if p.DHigh == 0 {
+		p.DHigh = defaultGossipSubParams.DHigh
+	}
+	if p.HeartbeatInterval == 0 {
+		p.HeartbeatInterval = defaultGossipSubParams.HeartbeatInterval
+	}
+	return p
+}
+
+// Validate returns an error if the parameters are inconsistent.
+func (p GossipSubParams) Validate() error {
+	if p.D < 0 || p.DLow < 0 || p.DHigh < 0 || p.HeartbeatInterval < 0 {
+		return fmt.Errorf("gossipsub parameters cannot be negative: %+v", p)
+	}
+	p = p.withDefaults()
+	if p.DLow > p.D || p.D > p.DHigh {
+		return fmt.Errorf("gossipsub parameters must satisfy DLow <= D <= DHigh (got DLow=%d, D=%d, DHigh=%d)", p.DLow, p.D, p.DHigh)
+	}
+	return nil
+}
+
+// apply sets the parameters of go-libp2p-pubsub. It must be called before the
+// GossipSub router is created.
+func (p GossipSubParams) apply() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	p = p.withDefaults()
+	pubsub.GossipSubD = p.D
+	pubsub.GossipSubDlo = p.DLow
+	pubsub.GossipSubDhi = p.DHigh
+	pubsub.GossipSubHeartbeatInterval = p.HeartbeatInterval
+	return nil
+}
@@ -0,0 +1,74 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGossipSubParamsValidate(t *testing.T) {
+	testCases := []struct {
+		params  GossipSubParams
+		isValid bool
+	}{
+		{
+			params:  GossipSubParams{},
+			isValid: true,
+		},
+		{
+			params:  GossipSubParams{D: 8, DLow: 6, DHigh: 16, HeartbeatInterval: 500 * time.Millisecond},
+			isValid: true,
+		},
+		{
+			// DHigh defaults to 12.
+			params:  GossipSubParams{D: 10},
+			isValid: true,
+		},
+		{
+			params:  GossipSubParams{D: 20},
+			isValid: false,
+		},
+		{
+			// DLow defaults to 4.
+			params:  GossipSubParams{D: 3},
+			isValid: false,
+		},
+		{
+			params:  GossipSubParams{D: 6, DLow: 8, DHigh: 12},
+			isValid: false,
+		},
+		{
+			params:  GossipSubParams{HeartbeatInterval: -time.Second},
+			isValid: false,
+		},
+	}
+	for i, testCase := range testCases {
+		err := testCase.params.Validate()
+		if testCase.isValid {
+			assert.NoError(t, err, "test case %d", i)
+		} else {
+			assert.Error(t, err, "test case %d", i)
+		}
+	}
+}
+
+func TestGossipSubParamsApplyRestoresDefaults(t *testing.T) {
+	defer func() {
+		require.NoError(t, GossipSubParams{}.apply())
+	}()
+
+	require.NoError(t, GossipSubParams{D: 8, DLow: 6, DHigh: 16, HeartbeatInterval: 500 * time.Millisecond}.apply())
+	assert.Equal(t, 8, pubsub.GossipSubD)
+	assert.Equal(t, 500*time.Millisecond, pubsub.GossipSubHeartbeatInterval)
+
+	// Zero values must reset the parameters to the defaults of
+	// go-libp2p-pubsub rather than keep the ones which were applied before.
+	require.NoError(t, GossipSubParams{}.apply())
+	assert.Equal(t, defaultGossipSubParams.D, pubsub.GossipSubD)
+	assert.Equal(t, defaultGossipSubParams.DLow, pubsub.GossipSubDlo)
+	assert.Equal(t, defaultGossipSubParams.DHigh, pubsub.GossipSubDhi)
+	assert.Equal(t, defaultGossipSubParams.HeartbeatInterval, pubsub.GossipSubHeartbeatInterval)
+}
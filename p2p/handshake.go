@@ -0,0 +1,181 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	p2pnet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// HandshakeProtocolID is the protocol ID used to exchange protocol versions
+// with new peers.
+const HandshakeProtocolID = protocol.ID("/0x-mesh/handshake/version/0")
+
+// ProtocolVersion is the semantic version of the 0x Mesh p2p protocol
+// implemented by this package. The major version must be incremented for any
+// change which breaks compatibility with older peers, since peers with
+// different major versions refuse to stay connected to each other.
+const ProtocolVersion = "1.0.0"
+
+const (
+	// handshakeTimeout is the timeout for completing a handshake with a peer.
+	handshakeTimeout = 10 * time.Second
+	// maxHandshakeMessageSize is the maximum size of an encoded
+	// handshakeMessage.
+	maxHandshakeMessageSize = 4096
+)
+
+// handshakeMessage is sent by both sides of a handshake. The peer which opened
+// the connection sends its version first and the other peer responds with its
+// own version or with an error if it rejects the handshake.
+type handshakeMessage struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	// Error is the reason the handshake was rejected, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// ValidateMinPeerProtocolVersion returns an error if the given minimum peer
+// protocol version is not a valid version or is newer than ProtocolVersion.
+// Such a minimum would cause this node to reject every peer running the same
+// version of Mesh as itself.
+func ValidateMinPeerProtocolVersion(minVersion string) error {
+	cmp, err := compareVersions(minVersion, ProtocolVersion)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return fmt.Errorf("minimum peer protocol version %s is newer than the current protocol version %s", minVersion, ProtocolVersion)
+	}
+	return nil
+}
+
+// checkPeerProtocolVersion returns an error if a peer with the given protocol
+// version is incompatible with this node.
+func (n *Node) checkPeerProtocolVersion(peerVersion string) error {
+	parsedPeerVersion, err := parseVersion(peerVersion)
+	if err != nil {
+		return err
+	}
+	parsedVersion, _ := parseVersion(ProtocolVersion)
+	if parsedPeerVersion[0] != parsedVersion[0] {
+		return fmt.Errorf("protocol version %s is incompatible with protocol version %s", peerVersion, ProtocolVersion)
+	}
+	if n.config.MinPeerProtocolVersion != "" {
+		// The minimum version was already validated in New.
+		if cmp, _ := compareVersions(peerVersion, n.config.MinPeerProtocolVersion); cmp < 0 {
+			return fmt.Errorf("protocol version %s is older than the minimum accepted protocol version %s", peerVersion, n.config.MinPeerProtocolVersion)
+		}
+	}
+	return nil
+}
+
+// handshakeNotifee returns a Notifiee which starts a handshake whenever this
+// node opens a new connection to a peer. Only the dialing side starts the
+// handshake, but both sides check the version of the other.
+func (n *Node) handshakeNotifee() p2pnet.Notifiee {
+	return &p2pnet.NotifyBundle{
+		ConnectedF: func(_ p2pnet.Network, conn p2pnet.Conn) {
+			if conn.Stat().Direction != p2pnet.DirOutbound {
+				return
+			}
+			go n.handshake(conn.RemotePeer())
+		},
+	}
+}
+
+// handshake sends our protocol version to the given peer and checks the
+// version it responds with. If either side rejects the handshake, we
+// disconnect from the peer. Peers which don't support the handshake protocol
+// (e.g. bootstrap nodes, relays and older versions of Mesh) are left alone.
+func (n *Node) handshake(peerID peer.ID) {
+	ctx, cancel := context.WithTimeout(n.ctx, handshakeTimeout)
+	defer cancel()
+	stream, err := n.host.NewStream(ctx, peerID, HandshakeProtocolID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Trace("could not open handshake stream")
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	_ = stream.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := json.NewEncoder(stream).Encode(handshakeMessage{ProtocolVersion: ProtocolVersion}); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not send handshake")
+		return
+	}
+	var res handshakeMessage
+	if err := json.NewDecoder(io.LimitReader(stream, maxHandshakeMessageSize)).Decode(&res); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not receive handshake response")
+		return
+	}
+	if res.Error != "" {
+		n.rejectPeer(peerID, res.ProtocolVersion, fmt.Errorf("peer rejected handshake: %s", res.Error))
+		return
+	}
+	if err := n.checkPeerProtocolVersion(res.ProtocolVersion); err != nil {
+		n.rejectPeer(peerID, res.ProtocolVersion, err)
+	}
+}
+
+// handleHandshakeStream responds to a handshake started by a peer.
+func (n *Node) handleHandshakeStream(stream p2pnet.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+	peerID := stream.Conn().RemotePeer()
+	_ = stream.SetDeadline(time.Now().Add(handshakeTimeout))
+	var req handshakeMessage
+	if err := json.NewDecoder(io.LimitReader(stream, maxHandshakeMessageSize)).Decode(&req); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not receive handshake")
+		return
+	}
+	res := handshakeMessage{ProtocolVersion: ProtocolVersion}
+	versionErr := n.checkPeerProtocolVersion(req.ProtocolVersion)
+	if versionErr != nil {
+		res.Error = versionErr.Error()
+	}
+	if err := json.NewEncoder(stream).Encode(res); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not send handshake response")
+	}
+	if versionErr != nil {
+		// Wait for the peer to close the stream (or for the deadline) so that
+		// it receives the reason before we disconnect.
+		_ = stream.Close()
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(stream, maxHandshakeMessageSize))
+		n.rejectPeer(peerID, req.ProtocolVersion, versionErr)
+	}
+}
+
+// rejectPeer disconnects from a peer whose protocol version is incompatible.
+func (n *Node) rejectPeer(peerID peer.ID, peerVersion string, reason error) {
+	log.WithFields(log.Fields{
+		"reason":             reason.Error(),
+		"remotePeerID":       peerID.String(),
+		"remoteVersion":      peerVersion,
+		"protocolVersion":    ProtocolVersion,
+		"minProtocolVersion": n.config.MinPeerProtocolVersion,
+	}).Warn("disconnecting from peer with incompatible protocol version")
+	_ = n.host.Network().ClosePeer(peerID)
+}
@@ -0,0 +1,54 @@
+// +build !js
+
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPeerProtocolVersion(t *testing.T) {
+	node := &Node{config: Config{MinPeerProtocolVersion: "1.2.0"}}
+	assert.NoError(t, node.checkPeerProtocolVersion("1.2.0"))
+	assert.NoError(t, node.checkPeerProtocolVersion("1.3.1"))
+	assert.Error(t, node.checkPeerProtocolVersion("1.1.9"))
+	assert.Error(t, node.checkPeerProtocolVersion("2.0.0"))
+	assert.Error(t, node.checkPeerProtocolVersion("not a version"))
+}
+
+func TestHandshakeRejectsOldPeer(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testStreamTimeout)
+	defer cancel()
+
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	node0 := newTestNode(t, ctx, nil)
+	node1 := newTestNodeWithConfig(t, ctx, nil, Config{
+		SubscribeTopic:         testTopic,
+		PublishTopics:          []string{testTopic},
+		PrivateKey:             privKey,
+		MessageHandler:         &dummyMessageHandler{},
+		RendezvousPoints:       testRendezvousPoints,
+		DataDir:                "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+		MinPeerProtocolVersion: "1.1.0",
+	})
+	connectTestNodes(t, node0, node1)
+
+	// node1 should reject node0 because ProtocolVersion is older than the
+	// minimum, and the two nodes should be disconnected.
+	deadline := time.Now().Add(5 * time.Second)
+	for node0.GetNumPeers() != 0 || node1.GetNumPeers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for peers to be disconnected")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,138 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// OverflowPolicy determines which messages are dropped when the inbound
+// message queue is full.
+type OverflowPolicy string
+
+const (
+	// DropOldest drops the oldest message in the queue to make room for the new
+	// one. It favors fresh orders, which are more likely to still be valid.
+	DropOldest OverflowPolicy = "drop-oldest"
+	// DropNew drops the new message and leaves the queue unchanged.
+	DropNew OverflowPolicy = "drop-new"
+)
+
+// ParseOverflowPolicy parses the given string as an OverflowPolicy. An empty
+// string is parsed as DropOldest.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch OverflowPolicy(s) {
+	case "", DropOldest:
+		return DropOldest, nil
+	case DropNew:
+		return DropNew, nil
+	default:
+		return "", fmt.Errorf("invalid overflow policy %q (expected %q or %q)", s, DropOldest, DropNew)
+	}
+}
+
+// InboundQueueStats contains metrics about the inbound message queue.
+type InboundQueueStats struct {
+	// Length is the number of messages currently waiting to be handled.
+	Length int
+	// Capacity is the maximum number of messages that can be queued.
+	Capacity int
+	// Received is the total number of messages added to the queue.
+	Received uint64
+	// Dropped is the total number of messages that were dropped because the
+	// queue was full.
+	Dropped uint64
+}
+
+// inboundQueue is a bounded FIFO queue of messages which sits between pubsub
+// and the MessageHandler. Messages are received from pubsub as fast as they
+// arrive, so a burst of messages which can't be validated in time is shed
+// according to the OverflowPolicy instead of building up an unbounded backlog.
+type inboundQueue struct {
+	mu       sync.Mutex
+	policy   OverflowPolicy
+	messages []*Message
+	// start is the index of the oldest message in messages and length is the
+	// number of queued messages. messages is used as a ring buffer.
+	start    int
+	length   int
+	received uint64
+	dropped  uint64
+	// notify has a buffer of 1 and receives a value whenever a message is
+	// pushed.
+	notify chan struct{}
+}
+
+func newInboundQueue(size int, policy OverflowPolicy) *inboundQueue {
+	return &inboundQueue{
+		policy:   policy,
+		messages: make([]*Message, size),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// push adds msg to the queue. It returns false if a message was dropped.
+func (q *inboundQueue) push(msg *Message) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.received++
+	ok := true
+	if q.length == len(q.messages) {
+		q.dropped++
+		ok = false
+		if q.policy == DropNew {
+			return false
+		}
+		q.messages[q.start] = nil
+		q.start = (q.start + 1) % len(q.messages)
+		q.length--
+	}
+	q.messages[(q.start+q.length)%len(q.messages)] = msg
+	q.length++
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return ok
+}
+
+// popBatch removes and returns up to max messages from the front of the queue.
+// It blocks until at least one message is available or ctx is canceled, in
+// which case it returns nil.
+func (q *inboundQueue) popBatch(ctx context.Context, max int) []*Message {
+	for {
+		q.mu.Lock()
+		if q.length > 0 {
+			n := q.length
+			if n > max {
+				n = max
+			}
+			batch := make([]*Message, n)
+			for i := range batch {
+				batch[i] = q.messages[q.start]
+				q.messages[q.start] = nil
+				q.start = (q.start + 1) % len(q.messages)
+			}
+			q.length -= n
+			q.mu.Unlock()
+			return batch
+		}
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.notify:
+		}
+	}
+}
+
+func (q *inboundQueue) stats() InboundQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return InboundQueueStats{
+		Length:   q.length,
+		Capacity: len(q.messages),
+		Received: q.received,
+		Dropped:  q.dropped,
+	}
+}
@@ -0,0 +1,89 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMessages(count int) []*Message {
+	messages := make([]*Message, count)
+	for i := range messages {
+		messages[i] = &Message{Data: []byte{byte(i)}}
+	}
+	return messages
+}
+
+func TestInboundQueueDropOldest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	messages := newTestMessages(5)
+	queue := newInboundQueue(3, DropOldest)
+	for i, msg := range messages {
+		assert.Equal(t, i < 3, queue.push(msg), "push(messages[%d])", i)
+	}
+	assert.Equal(t, InboundQueueStats{
+		Length:   3,
+		Capacity: 3,
+		Received: 5,
+		Dropped:  2,
+	}, queue.stats())
+
+	assert.Equal(t, messages[2:4], queue.popBatch(ctx, 2))
+	assert.Equal(t, messages[4:], queue.popBatch(ctx, 2))
+	assert.Equal(t, 0, queue.stats().Length)
+}
+
+func TestInboundQueueDropNew(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	messages := newTestMessages(5)
+	queue := newInboundQueue(3, DropNew)
+	for i, msg := range messages {
+		assert.Equal(t, i < 3, queue.push(msg), "push(messages[%d])", i)
+	}
+	assert.Equal(t, uint64(2), queue.stats().Dropped)
+	assert.Equal(t, messages[:3], queue.popBatch(ctx, 10))
+
+	// Once there is room in the queue, new messages are accepted again.
+	assert.True(t, queue.push(messages[3]))
+	assert.Equal(t, messages[3:4], queue.popBatch(ctx, 10))
+}
+
+func TestInboundQueuePopBatchWaits(t *testing.T) {
+	t.Parallel()
+	queue := newInboundQueue(3, DropOldest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, queue.popBatch(ctx, 1), "popBatch should return nil when the context is canceled")
+
+	msg := &Message{Data: []byte("hello")}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		queue.push(msg)
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	batch := queue.popBatch(ctx, 1)
+	require.Len(t, batch, 1)
+	assert.Equal(t, msg, batch[0])
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	t.Parallel()
+	for input, expected := range map[string]OverflowPolicy{
+		"":            DropOldest,
+		"drop-oldest": DropOldest,
+		"drop-new":    DropNew,
+	} {
+		actual, err := ParseOverflowPolicy(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+	_, err := ParseOverflowPolicy("drop-everything")
+	assert.Error(t, err)
+}
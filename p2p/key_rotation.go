@@ -0,0 +1,252 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	p2pnet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// KeyRotationProtocolID is the protocol ID used to announce to peers that this
+// node has rotated its identity key.
+const KeyRotationProtocolID = protocol.ID("/0x-mesh/key-rotation/version/0")
+
+const (
+	// MaxKeyRotationGracePeriod is the maximum amount of time for which peers
+	// accept the previous identity of a node as an alias for its new identity.
+	MaxKeyRotationGracePeriod = 30 * 24 * time.Hour
+	// keyRotationTimeout is the timeout for sending a key rotation
+	// announcement to a peer.
+	keyRotationTimeout = 10 * time.Second
+	// maxKeyRotationMessageSize is the maximum size of an encoded
+	// KeyRotation.
+	maxKeyRotationMessageSize = 4096
+	// maxPeerAliases is the maximum number of peer aliases which are kept in
+	// memory. When the limit is reached, expired aliases are removed first and
+	// then the aliases which expire soonest.
+	maxPeerAliases = 1000
+)
+
+// KeyRotation is an announcement that a node has rotated its identity key. It
+// is signed with the previous key and sent by the node using its new key, so
+// peers know that both identities belong to the same node. Until
+// ExpirationTime, peers transfer the reputation of the previous peer ID to the
+// new one and treat the new peer ID as an alias for the previous one (e.g.
+// for TRUSTED_ORDER_SUBMITTERS).
+type KeyRotation struct {
+	// PreviousPublicKey is the marshaled public key of the previous identity.
+	PreviousPublicKey []byte `json:"previousPublicKey"`
+	// NewPeerID is the peer ID of the new identity.
+	NewPeerID peer.ID `json:"newPeerID"`
+	// ExpirationTime is the end of the grace period.
+	ExpirationTime time.Time `json:"expirationTime"`
+	// Signature is the signature of the announcement by the previous key.
+	Signature []byte `json:"signature"`
+}
+
+// NewKeyRotation creates a KeyRotation which announces that the identity of
+// previousKey has been replaced by newPeerID until expirationTime.
+func NewKeyRotation(previousKey p2pcrypto.PrivKey, newPeerID peer.ID, expirationTime time.Time) (*KeyRotation, error) {
+	previousPublicKey, err := p2pcrypto.MarshalPublicKey(previousKey.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	rotation := &KeyRotation{
+		PreviousPublicKey: previousPublicKey,
+		NewPeerID:         newPeerID,
+		ExpirationTime:    expirationTime.UTC().Truncate(time.Second),
+	}
+	signature, err := previousKey.Sign(rotation.signedData())
+	if err != nil {
+		return nil, err
+	}
+	rotation.Signature = signature
+	return rotation, nil
+}
+
+// signedData returns the data which is signed by the previous key.
+func (r *KeyRotation) signedData() []byte {
+	return []byte("0x-mesh key rotation:" + r.NewPeerID.String() + ":" + strconv.FormatInt(r.ExpirationTime.Unix(), 10))
+}
+
+// PreviousPeerID returns the peer ID of the previous identity.
+func (r *KeyRotation) PreviousPeerID() (peer.ID, error) {
+	previousPublicKey, err := p2pcrypto.UnmarshalPublicKey(r.PreviousPublicKey)
+	if err != nil {
+		return "", err
+	}
+	return peer.IDFromPublicKey(previousPublicKey)
+}
+
+// Verify checks that the announcement was signed by the previous key, that it
+// was sent by senderID and that the grace period is valid at the given time.
+// It returns the previous peer ID.
+func (r *KeyRotation) Verify(senderID peer.ID, now time.Time) (peer.ID, error) {
+	if r.NewPeerID != senderID {
+		return "", fmt.Errorf("key rotation for peer %s was sent by peer %s", r.NewPeerID, senderID)
+	}
+	if !now.Before(r.ExpirationTime) {
+		return "", errors.New("key rotation has expired")
+	}
+	if r.ExpirationTime.After(now.Add(MaxKeyRotationGracePeriod)) {
+		return "", errors.New("key rotation grace period is too long")
+	}
+	previousPublicKey, err := p2pcrypto.UnmarshalPublicKey(r.PreviousPublicKey)
+	if err != nil {
+		return "", err
+	}
+	valid, err := previousPublicKey.Verify(r.signedData(), r.Signature)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", errors.New("invalid key rotation signature")
+	}
+	previousPeerID, err := peer.IDFromPublicKey(previousPublicKey)
+	if err != nil {
+		return "", err
+	}
+	if previousPeerID == senderID {
+		return "", errors.New("key rotation does not change the peer ID")
+	}
+	return previousPeerID, nil
+}
+
+// peerAlias is the previous identity of a peer which has rotated its key.
+type peerAlias struct {
+	previousPeerID peer.ID
+	expirationTime time.Time
+}
+
+// PreviousPeerID returns the previous identity of the given peer if it has
+// announced a key rotation whose grace period hasn't ended yet.
+func (n *Node) PreviousPeerID(id peer.ID) (peer.ID, bool) {
+	n.peerAliasesMu.Lock()
+	defer n.peerAliasesMu.Unlock()
+	alias, found := n.peerAliases[id]
+	if !found {
+		return "", false
+	}
+	if !time.Now().Before(alias.expirationTime) {
+		delete(n.peerAliases, id)
+		return "", false
+	}
+	return alias.previousPeerID, true
+}
+
+// keyRotationNotifee returns a Notifiee which announces our key rotation to
+// every peer we connect to until the grace period ends.
+func (n *Node) keyRotationNotifee() p2pnet.Notifiee {
+	return &p2pnet.NotifyBundle{
+		ConnectedF: func(_ p2pnet.Network, conn p2pnet.Conn) {
+			if !time.Now().Before(n.config.KeyRotation.ExpirationTime) {
+				return
+			}
+			go n.announceKeyRotation(conn.RemotePeer())
+		},
+	}
+}
+
+// announceKeyRotation sends our key rotation announcement to the given peer.
+// Peers which don't support the key rotation protocol are left alone.
+func (n *Node) announceKeyRotation(peerID peer.ID) {
+	ctx, cancel := context.WithTimeout(n.ctx, keyRotationTimeout)
+	defer cancel()
+	stream, err := n.host.NewStream(ctx, peerID, KeyRotationProtocolID)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Trace("could not open key rotation stream")
+		return
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	_ = stream.SetDeadline(time.Now().Add(keyRotationTimeout))
+	if err := json.NewEncoder(stream).Encode(n.config.KeyRotation); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not send key rotation")
+	}
+}
+
+// handleKeyRotationStream handles a key rotation announced by a peer.
+func (n *Node) handleKeyRotationStream(stream p2pnet.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+	peerID := stream.Conn().RemotePeer()
+	_ = stream.SetDeadline(time.Now().Add(keyRotationTimeout))
+	var rotation KeyRotation
+	if err := json.NewDecoder(io.LimitReader(stream, maxKeyRotationMessageSize)).Decode(&rotation); err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Debug("could not receive key rotation")
+		return
+	}
+	previousPeerID, err := rotation.Verify(peerID, time.Now())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error":        err.Error(),
+			"remotePeerID": peerID.String(),
+		}).Warn("received invalid key rotation")
+		return
+	}
+
+	n.peerAliasesMu.Lock()
+	_, alreadyKnown := n.peerAliases[peerID]
+	if !alreadyKnown {
+		n.prunePeerAliases(time.Now())
+	}
+	n.peerAliases[peerID] = peerAlias{
+		previousPeerID: previousPeerID,
+		expirationTime: rotation.ExpirationTime,
+	}
+	n.peerAliasesMu.Unlock()
+	if alreadyKnown {
+		return
+	}
+	log.WithFields(log.Fields{
+		"remotePeerID":   peerID.String(),
+		"previousPeerID": previousPeerID.String(),
+		"expirationTime": rotation.ExpirationTime,
+	}).Info("peer rotated its identity key")
+	n.reputation.Transfer(previousPeerID, peerID)
+}
+
+// prunePeerAliases makes room for a new peer alias. It removes the aliases
+// which have expired at the given time and, if maxPeerAliases is still
+// reached, the aliases which expire soonest. n.peerAliasesMu must be held.
+func (n *Node) prunePeerAliases(now time.Time) {
+	for id, alias := range n.peerAliases {
+		if !now.Before(alias.expirationTime) {
+			delete(n.peerAliases, id)
+		}
+	}
+	if len(n.peerAliases) < maxPeerAliases {
+		return
+	}
+	ids := make([]peer.ID, 0, len(n.peerAliases))
+	for id := range n.peerAliases {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return n.peerAliases[ids[i]].expirationTime.Before(n.peerAliases[ids[j]].expirationTime)
+	})
+	for _, id := range ids[:len(ids)-maxPeerAliases+1] {
+		delete(n.peerAliases, id)
+	}
+}
@@ -0,0 +1,122 @@
+// +build !js
+
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRotationVerify(t *testing.T) {
+	previousKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	previousPeerID, err := peer.IDFromPrivateKey(previousKey)
+	require.NoError(t, err)
+	newKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	newPeerID, err := peer.IDFromPrivateKey(newKey)
+	require.NoError(t, err)
+
+	now := time.Now()
+	rotation, err := NewKeyRotation(previousKey, newPeerID, now.Add(24*time.Hour))
+	require.NoError(t, err)
+	actualPreviousPeerID, err := rotation.Verify(newPeerID, now)
+	require.NoError(t, err)
+	assert.Equal(t, previousPeerID, actualPreviousPeerID)
+
+	// The rotation must be sent by the new identity.
+	_, err = rotation.Verify(previousPeerID, now)
+	assert.Error(t, err)
+
+	// The rotation is only valid until the end of the grace period.
+	_, err = rotation.Verify(newPeerID, now.Add(25*time.Hour))
+	assert.Error(t, err)
+
+	// The grace period can't be too long.
+	longRotation, err := NewKeyRotation(previousKey, newPeerID, now.Add(MaxKeyRotationGracePeriod+time.Hour))
+	require.NoError(t, err)
+	_, err = longRotation.Verify(newPeerID, now)
+	assert.Error(t, err)
+
+	// The rotation must be signed by the previous key.
+	forgedRotation := *rotation
+	forgedRotation.ExpirationTime = rotation.ExpirationTime.Add(time.Hour)
+	_, err = forgedRotation.Verify(newPeerID, now)
+	assert.Error(t, err)
+}
+
+func TestKeyRotationAnnouncement(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testStreamTimeout)
+	defer cancel()
+
+	previousKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	previousPeerID, err := peer.IDFromPrivateKey(previousKey)
+	require.NoError(t, err)
+	newKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	newPeerID, err := peer.IDFromPrivateKey(newKey)
+	require.NoError(t, err)
+	rotation, err := NewKeyRotation(previousKey, newPeerID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	node0 := newTestNode(t, ctx, nil)
+	node1 := newTestNodeWithConfig(t, ctx, nil, Config{
+		SubscribeTopic:   testTopic,
+		PublishTopics:    []string{testTopic},
+		PrivateKey:       newKey,
+		MessageHandler:   &dummyMessageHandler{},
+		RendezvousPoints: testRendezvousPoints,
+		DataDir:          "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+		KeyRotation:      rotation,
+	})
+	connectTestNodes(t, node0, node1)
+
+	// node1 should announce its key rotation to node0.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if actualPreviousPeerID, found := node0.PreviousPeerID(newPeerID); found {
+			assert.Equal(t, previousPeerID, actualPreviousPeerID)
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for key rotation to be announced")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	_, found := node1.PreviousPeerID(node0.ID())
+	assert.False(t, found)
+}
+
+func TestPrunePeerAliases(t *testing.T) {
+	now := time.Now()
+	node := &Node{peerAliases: map[peer.ID]peerAlias{}}
+	for i := 0; i < maxPeerAliases; i++ {
+		node.peerAliases[peer.ID(fmt.Sprintf("peer-%d", i))] = peerAlias{
+			previousPeerID: peer.ID(fmt.Sprintf("previous-%d", i)),
+			expirationTime: now.Add(time.Duration(i+1) * time.Minute),
+		}
+	}
+
+	// The alias which expires soonest is removed to make room.
+	node.prunePeerAliases(now)
+	assert.Len(t, node.peerAliases, maxPeerAliases-1)
+	assert.NotContains(t, node.peerAliases, peer.ID("peer-0"))
+	assert.Contains(t, node.peerAliases, peer.ID("peer-1"))
+
+	// Expired aliases are removed.
+	node.prunePeerAliases(now.Add(10*time.Minute + time.Second))
+	assert.Len(t, node.peerAliases, maxPeerAliases-10)
+	assert.NotContains(t, node.peerAliases, peer.ID("peer-9"))
+	assert.Contains(t, node.peerAliases, peer.ID("peer-10"))
+}
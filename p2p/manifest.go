@@ -0,0 +1,232 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// ManifestProtocolID is the protocol ID bootstrap nodes use to serve signed
+// network manifests.
+const ManifestProtocolID = protocol.ID("/0x-mesh/network-manifest/version/0")
+
+const (
+	// maxManifestSize is the maximum size of an encoded SignedNetworkManifest.
+	maxManifestSize = 1 << 20
+	// manifestRequestTimeout is the timeout for fetching a network manifest
+	// from a single peer.
+	manifestRequestTimeout = 10 * time.Second
+)
+
+// NetworkManifest contains information published by bootstrap nodes which
+// helps regular nodes join the network.
+type NetworkManifest struct {
+	// Peers is a list of multiaddresses (including peer IDs) of recommended
+	// peers to connect to.
+	Peers []string `json:"peers"`
+	// Topics is a list of the pubsub topics currently in use. A node whose
+	// subscribe topic is not in this list is probably running an outdated
+	// topic version.
+	Topics []string `json:"topics"`
+	// MinClientVersion is the minimum recommended version of Mesh, e.g.
+	// "9.4.0". If empty, any version is accepted.
+	MinClientVersion string `json:"minClientVersion"`
+	// Timestamp is the time at which the manifest was signed.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SignedNetworkManifest is a NetworkManifest signed with the private key of
+// the bootstrap node which serves it. Manifest contains the JSON encoding of
+// the NetworkManifest, which is exactly the data that was signed.
+type SignedNetworkManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature []byte          `json:"signature"`
+}
+
+// SignNetworkManifest signs the given manifest with privKey.
+func SignNetworkManifest(privKey p2pcrypto.PrivKey, manifest *NetworkManifest) (*SignedNetworkManifest, error) {
+	encodedManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := privKey.Sign(encodedManifest)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedNetworkManifest{
+		Manifest:  encodedManifest,
+		Signature: signature,
+	}, nil
+}
+
+// Verify checks that the manifest was signed by pubKey and returns the decoded
+// manifest.
+func (s *SignedNetworkManifest) Verify(pubKey p2pcrypto.PubKey) (*NetworkManifest, error) {
+	valid, err := pubKey.Verify(s.Manifest, s.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("invalid network manifest signature")
+	}
+	var manifest NetworkManifest
+	if err := json.Unmarshal(s.Manifest, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// ServeNetworkManifest registers a stream handler on h which sends the given
+// manifest to any peer which requests it.
+func ServeNetworkManifest(h host.Host, manifest *SignedNetworkManifest) error {
+	encodedManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	h.SetStreamHandler(ManifestProtocolID, func(stream network.Stream) {
+		defer func() {
+			_ = stream.Close()
+		}()
+		_ = stream.SetWriteDeadline(time.Now().Add(manifestRequestTimeout))
+		if _, err := stream.Write(encodedManifest); err != nil {
+			log.WithFields(log.Fields{
+				"error":  err.Error(),
+				"peerID": stream.Conn().RemotePeer().String(),
+			}).Debug("could not send network manifest")
+		}
+	})
+	return nil
+}
+
+// FetchNetworkManifest requests a network manifest from the given peer and
+// verifies that it was signed by that peer.
+func FetchNetworkManifest(ctx context.Context, h host.Host, peerID peer.ID) (*NetworkManifest, error) {
+	pubKey, err := peerID.ExtractPublicKey()
+	if err != nil {
+		// The public key is not inlined in the peer ID. Fall back to the key
+		// learned during the secure handshake with the peer.
+		pubKey = h.Peerstore().PubKey(peerID)
+		if pubKey == nil {
+			return nil, fmt.Errorf("unknown public key for peer %s", peerID)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, manifestRequestTimeout)
+	defer cancel()
+	stream, err := h.NewStream(ctx, peerID, ManifestProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	_ = stream.SetReadDeadline(time.Now().Add(manifestRequestTimeout))
+	var signedManifest SignedNetworkManifest
+	if err := json.NewDecoder(io.LimitReader(stream, maxManifestSize)).Decode(&signedManifest); err != nil {
+		return nil, err
+	}
+	return signedManifest.Verify(pubKey)
+}
+
+// fetchNetworkManifest fetches a network manifest from the first peer in the
+// bootstrap list which serves a valid one. Peers which could not be connected
+// to are skipped.
+func (n *Node) fetchNetworkManifest(ctx context.Context) (*NetworkManifest, error) {
+	bootstrapAddrInfos, err := BootstrapListToAddrInfos(n.config.BootstrapList)
+	if err != nil {
+		return nil, err
+	}
+	tried := map[peer.ID]struct{}{}
+	for _, addrInfo := range bootstrapAddrInfos {
+		if _, found := tried[addrInfo.ID]; found || addrInfo.ID == n.host.ID() {
+			continue
+		}
+		tried[addrInfo.ID] = struct{}{}
+		if n.dialBackoff.isFailing(addrInfo.ID) {
+			// Note: Requesting the manifest from a peer we couldn't connect to
+			// would most likely time out, so we skip it.
+			continue
+		}
+		manifest, err := FetchNetworkManifest(ctx, n.host, addrInfo.ID)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":  err.Error(),
+				"peerID": addrInfo.ID.String(),
+			}).Debug("could not fetch network manifest")
+			continue
+		}
+		log.WithFields(log.Fields{
+			"peerID":   addrInfo.ID.String(),
+			"manifest": manifest,
+		}).Info("fetched network manifest")
+		return manifest, nil
+	}
+	return nil, errors.New("no bootstrap peer served a valid network manifest")
+}
+
+// applyNetworkManifest connects to the peers recommended in the manifest and
+// warns if this node appears to be outdated.
+func (n *Node) applyNetworkManifest(ctx context.Context, manifest *NetworkManifest) {
+	if manifest.MinClientVersion != "" && n.config.ClientVersion != "" {
+		cmp, err := compareVersions(n.config.ClientVersion, manifest.MinClientVersion)
+		if err != nil {
+			log.WithError(err).Warn("could not compare client version to network manifest")
+		} else if cmp < 0 {
+			log.WithFields(log.Fields{
+				"version":          n.config.ClientVersion,
+				"minClientVersion": manifest.MinClientVersion,
+			}).Warn("this version of Mesh is older than the minimum version recommended by the network manifest; please upgrade")
+		}
+	}
+	if len(manifest.Topics) > 0 && !containsString(manifest.Topics, n.config.SubscribeTopic) {
+		log.WithFields(log.Fields{
+			"topic":          n.config.SubscribeTopic,
+			"manifestTopics": manifest.Topics,
+		}).Warn("subscribe topic is not listed in the network manifest; this node may be using an outdated topic version")
+	}
+
+	if len(manifest.Peers) == 0 {
+		return
+	}
+	addrInfos, err := BootstrapListToAddrInfos(manifest.Peers)
+	if err != nil {
+		log.WithError(err).Warn("network manifest contains invalid peer addresses")
+		return
+	}
+	connectCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
+	defer cancel()
+	wg := sync.WaitGroup{}
+	for _, addrInfo := range addrInfos {
+		if addrInfo.ID == n.host.ID() {
+			continue
+		}
+		wg.Add(1)
+		go func(addrInfo peer.AddrInfo) {
+			defer wg.Done()
+			if err := n.host.Connect(connectCtx, addrInfo); err != nil {
+				logPeerConnectionError(addrInfo, err)
+			}
+		}(addrInfo)
+	}
+	wg.Wait()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
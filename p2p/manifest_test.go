@@ -0,0 +1,59 @@
+// +build !js
+
+package p2p
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerifyNetworkManifest(t *testing.T) {
+	privKey, pubKey, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	manifest := &NetworkManifest{
+		Peers:            []string{},
+		Topics:           []string{testTopic},
+		MinClientVersion: "9.4.0",
+		Timestamp:        time.Now().UTC().Truncate(time.Second),
+	}
+	signedManifest, err := SignNetworkManifest(privKey, manifest)
+	require.NoError(t, err)
+	actual, err := signedManifest.Verify(pubKey)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, actual)
+
+	// A manifest signed by a different key should be rejected.
+	_, otherPubKey, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	_, err = signedManifest.Verify(otherPubKey)
+	assert.Error(t, err)
+}
+
+func TestFetchNetworkManifest(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testStreamTimeout)
+	defer cancel()
+
+	node0 := newTestNode(t, ctx, nil)
+	node1 := newTestNode(t, ctx, nil)
+	connectTestNodes(t, node0, node1)
+
+	manifest := &NetworkManifest{
+		Peers:     []string{},
+		Topics:    []string{testTopic},
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+	}
+	signedManifest, err := SignNetworkManifest(node1.config.PrivateKey, manifest)
+	require.NoError(t, err)
+	require.NoError(t, ServeNetworkManifest(node1.host, signedManifest))
+
+	actual, err := FetchNetworkManifest(ctx, node0.host, node1.ID())
+	require.NoError(t, err)
+	assert.Equal(t, manifest, actual)
+}
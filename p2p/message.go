@@ -9,8 +9,19 @@ import (
 type Message struct {
 	// From is the peer ID of the peer who sent the message.
 	From peer.ID
+	// ReceivedFrom is the peer ID of the neighbor which relayed the message to
+	// us. Unlike From, which is the peer that originally published the
+	// message, it is always a peer we are directly connected to.
+	ReceivedFrom peer.ID
+	// Signer is the peer ID of the node which published the message, if the
+	// message was signed. Signatures are verified by pubsub before messages
+	// are received, so unlike the identity of an unsigned message, the signer
+	// can't be spoofed. It is empty if the message was not signed.
+	Signer peer.ID
 	// Data is the underlying data for the message.
 	Data []byte
+	// Topic is the pubsub topic on which the message was received.
+	Topic string
 }
 
 // MessageHandler is an interface responsible for validating and storing
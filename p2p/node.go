@@ -9,13 +9,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	mathrand "math/rand"
+	"net"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/0xProject/0x-mesh/chaos"
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/p2p/banner"
 	"github.com/0xProject/0x-mesh/p2p/ratevalidator"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	"github.com/0xProject/0x-mesh/p2p/validatorset"
 	"github.com/albrow/stringset"
 	lru "github.com/hashicorp/golang-lru"
@@ -32,15 +35,12 @@ import (
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	swarm "github.com/libp2p/go-libp2p-swarm"
-	filter "github.com/libp2p/go-maddr-filter"
 	ma "github.com/multiformats/go-multiaddr"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
 
 const (
-	// receiveTimeout is the maximum amount of time to wait for receiving new messages.
-	receiveTimeout = 1 * time.Second
 	// peerGraceDuration is the amount of time a newly opened connection is given
 	// before it becomes subject to pruning.
 	peerGraceDuration = 10 * time.Second
@@ -52,6 +52,9 @@ const (
 	// defaultNetworkTimeout is the default timeout for network requests (e.g.
 	// connecting to a new peer).
 	defaultNetworkTimeout = 10 * time.Second
+	// reputationUpdateInterval is how frequently to record the latency of
+	// connected peers and persist their reputations.
+	reputationUpdateInterval = 1 * time.Minute
 	// advertiseTTL is the TTL for our announcement to the discovery network.
 	advertiseTTL = 5 * time.Minute
 	// pubsubProtocolID is the protocol ID to use for pubsub.
@@ -85,6 +88,8 @@ const (
 	// defaultPerPeerPubSubMessageBurst is the default value for
 	// PerPeerPubSubMessageBurst.
 	defaultPerPeerPubSubMessageBurst = maxShareBatch * 5
+	// defaultInboundQueueSize is the default value for InboundQueueSize.
+	defaultInboundQueueSize = maxReceiveBatch * 20
 )
 
 // Node is the main type for the p2p package. It represents a particpant in the
@@ -101,6 +106,15 @@ type Node struct {
 	pubsub           *pubsub.PubSub
 	sub              *pubsub.Subscription
 	banner           *banner.Banner
+	reputation       *reputation.Engine
+	inboundQueue     *inboundQueue
+	dialBackoff      *dialBackoff
+	peerSlots        *peerSlots
+	// peerAliasesMu protects peerAliases.
+	peerAliasesMu sync.Mutex
+	// peerAliases maps peers which have rotated their identity key to their
+	// previous identities.
+	peerAliases map[peer.ID]peerAlias
 }
 
 // Config contains configuration options for a Node.
@@ -112,6 +126,12 @@ type Config struct {
 	// published to more than one topic (e.g. a topic for all orders and a topic
 	// for orders with a specific asset).
 	PublishTopics []string
+	// AdditionalSubscribeTopics are topics to subscribe to in addition to
+	// SubscribeTopic. Each topic maps to a validator which is used instead of
+	// CustomMessageValidator for messages on that topic. The validator may be
+	// nil. Messages received on any of these topics are passed to the
+	// MessageHandler with the Topic field set accordingly.
+	AdditionalSubscribeTopics map[string]pubsub.Validator
 	// TCPPort is the port on which to listen for incoming TCP connections.
 	TCPPort int
 	// WebSocketsPort is the port on which to listen for incoming WebSockets
@@ -163,6 +183,65 @@ type Config struct {
 	// according to this custom validator, which will be run in addition to the
 	// default validators.
 	CustomMessageValidator pubsub.Validator
+	// InboundQueueSize is the maximum number of messages received from pubsub
+	// which can be waiting to be handled by the MessageHandler. Once the queue
+	// is full, messages are dropped according to InboundQueueOverflowPolicy.
+	InboundQueueSize int
+	// InboundQueueOverflowPolicy determines which messages are dropped when the
+	// inbound message queue is full. Defaults to DropOldest.
+	InboundQueueOverflowPolicy OverflowPolicy
+	// UseNetworkManifest determines whether or not to fetch a signed network
+	// manifest from the bootstrap peers on startup and connect to the peers it
+	// recommends. Only used if UseBootstrapList is true.
+	UseNetworkManifest bool
+	// ClientVersion is the version of Mesh which is running. It is compared to
+	// the minimum client version in the network manifest.
+	ClientVersion string
+	// MinPeerProtocolVersion is the minimum p2p protocol version (see
+	// ProtocolVersion) accepted from peers. Peers which report an older
+	// version during the handshake are disconnected. Peers with a different
+	// major protocol version are always disconnected. If empty, any protocol
+	// version with the same major version is accepted.
+	MinPeerProtocolVersion string
+	// ReputationStore is used to persist the reputations of peers so that they
+	// are remembered across restarts. If nil, reputations are only kept in
+	// memory.
+	ReputationStore reputation.Store
+	// DenyPrivateIPs determines whether or not to deny connections to and from
+	// private, shared, loopback and link-local IP addresses. Operators whose
+	// network policy forbids connecting to internal hosts should enable it.
+	DenyPrivateIPs bool
+	// DeniedSubnets are subnets to and from which connections are always
+	// denied. They take precedence over AllowedSubnets.
+	DeniedSubnets []net.IPNet
+	// AllowedSubnets are the only subnets to and from which connections are
+	// allowed. If empty, connections to and from any IP address which is not
+	// denied are allowed.
+	AllowedSubnets []net.IPNet
+	// KeyRotation, if not nil, is announced to every peer that the node
+	// connects to until it expires, so that peers can carry over their
+	// relationship with the previous identity of the node (see KeyRotation).
+	KeyRotation *KeyRotation
+	// RequireMessageSignatures determines whether or not to drop GossipSub
+	// messages which were not signed by the node which published them.
+	// Messages published by this node are always signed, and signatures are
+	// always verified if present.
+	RequireMessageSignatures bool
+	// GossipSubParams configures the mesh of peers that GossipSub maintains for
+	// each topic.
+	GossipSubParams GossipSubParams
+	// ReservedPeerSlotsFraction is the fraction of the maximum number of
+	// connections which is reserved for the peers that contribute the most
+	// valid orders (see Node.RecordOrderContribution). Connections to these
+	// peers are never pruned by the connection manager. Must be between 0 and
+	// 1. If 0, no connection slots are reserved.
+	ReservedPeerSlotsFraction float64
+	// IdlePeerTimeout is the amount of time a peer may stay connected without
+	// contributing a valid order before it is considered idle. When the node is
+	// at its target number of connections, idle peers are gradually
+	// disconnected to make room for new peers. Bootstrap peers and protected
+	// peers are never considered idle. If 0, idle peers are not disconnected.
+	IdlePeerTimeout time.Duration
 }
 
 func getPeerstoreDir(datadir string) string {
@@ -193,6 +272,27 @@ func New(ctx context.Context, config Config) (*Node, error) {
 	if config.PerPeerPubSubMessageBurst == 0 {
 		config.PerPeerPubSubMessageBurst = defaultPerPeerPubSubMessageBurst
 	}
+	if config.InboundQueueSize == 0 {
+		config.InboundQueueSize = defaultInboundQueueSize
+	} else if config.InboundQueueSize < 0 {
+		return nil, errors.New("config.InboundQueueSize cannot be negative")
+	}
+	overflowPolicy, err := ParseOverflowPolicy(string(config.InboundQueueOverflowPolicy))
+	if err != nil {
+		return nil, err
+	}
+	config.InboundQueueOverflowPolicy = overflowPolicy
+	if config.ReservedPeerSlotsFraction < 0 || config.ReservedPeerSlotsFraction > 1 {
+		return nil, errors.New("config.ReservedPeerSlotsFraction must be between 0 and 1")
+	}
+	if config.IdlePeerTimeout < 0 {
+		return nil, errors.New("config.IdlePeerTimeout cannot be negative")
+	}
+	if config.MinPeerProtocolVersion != "" {
+		if err := ValidateMinPeerProtocolVersion(config.MinPeerProtocolVersion); err != nil {
+			return nil, fmt.Errorf("invalid config.MinPeerProtocolVersion: %s", err.Error())
+		}
+	}
 
 	// We need to declare the newDHT function ahead of time so we can use it in
 	// the libp2p.Routing option.
@@ -214,7 +314,10 @@ func New(ctx context.Context, config Config) (*Node, error) {
 	}
 
 	// Initialize filters.
-	filters := filter.NewFilters()
+	filters, err := newFilters(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set up and append environment agnostic host options.
 	bandwidthCounter := metrics.NewBandwidthCounter()
@@ -244,17 +347,37 @@ func New(ctx context.Context, config Config) (*Node, error) {
 		_ = basicHost.Close()
 	}()
 
+	// Set up the reputation engine. Reputation scores are stored as a tag in the
+	// connection manager so that peers with a good reputation are preferred
+	// when we are at the connection limit.
+	reputationEngine, err := reputation.New(reputation.Config{
+		Store: config.ReputationStore,
+		SetScore: func(id peer.ID, score int) {
+			connManager.TagPeer(id, reputation.Tag, score)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up the notifee.
 	basicHost.Network().Notify(&notifee{
 		ctx:         ctx,
 		connManager: connManager,
+		reputation:  reputationEngine,
 	})
 
 	// Set up DHT for peer discovery.
 	routingDiscovery := discovery.NewRoutingDiscovery(kadDHT)
 
 	// Set up pubsub and custom validators.
+	if err := config.GossipSubParams.apply(); err != nil {
+		return nil, err
+	}
 	pubsubOpts := getPubSubOptions()
+	if config.RequireMessageSignatures {
+		pubsubOpts = append(pubsubOpts, pubsub.WithStrictSignatureVerification(true))
+	}
 	ps, err := pubsub.NewGossipSub(ctx, basicHost, pubsubOpts...)
 	if err != nil {
 		return nil, err
@@ -283,6 +406,24 @@ func New(ctx context.Context, config Config) (*Node, error) {
 		routingDiscovery: routingDiscovery,
 		pubsub:           ps,
 		banner:           banner,
+		reputation:       reputationEngine,
+		inboundQueue:     newInboundQueue(config.InboundQueueSize, config.InboundQueueOverflowPolicy),
+		dialBackoff:      newDialBackoff(),
+		peerSlots:        newPeerSlots(config.ReservedPeerSlotsFraction, config.IdlePeerTimeout, peerCountLow, peerCountHigh),
+		peerAliases:      map[peer.ID]peerAlias{},
+	}
+
+	// Set up the protocol version handshake.
+	basicHost.SetStreamHandler(HandshakeProtocolID, node.handleHandshakeStream)
+	basicHost.Network().Notify(node.handshakeNotifee())
+
+	// Keep track of connection times in order to detect idle peers.
+	basicHost.Network().Notify(node.peerSlotsNotifee())
+
+	// Set up key rotation announcements.
+	basicHost.SetStreamHandler(KeyRotationProtocolID, node.handleKeyRotationStream)
+	if config.KeyRotation != nil {
+		basicHost.Network().Notify(node.keyRotationNotifee())
 	}
 
 	return node, nil
@@ -291,7 +432,6 @@ func New(ctx context.Context, config Config) (*Node, error) {
 // registerValidators registers all the validators we use for incoming and
 // outgoing GossipSub messages.
 func registerValidators(ctx context.Context, basicHost host.Host, config Config, ps *pubsub.PubSub) error {
-	validators := validatorset.New()
 
 	// Add the rate limiting validator.
 	rateValidator, err := ratevalidator.New(ctx, ratevalidator.Config{
@@ -305,12 +445,16 @@ func registerValidators(ctx context.Context, basicHost host.Host, config Config,
 	if err != nil {
 		return err
 	}
-	validators.Add("message rate limiting", rateValidator.Validate)
-
-	// Add the custom validator if there is one.
-	if config.CustomMessageValidator != nil {
-		validators.Add("custom", config.CustomMessageValidator)
+	newValidatorSet := func(customValidator pubsub.Validator) *validatorset.Set {
+		validators := validatorset.New()
+		validators.Add("message rate limiting", rateValidator.Validate)
+		// Add the custom validator if there is one.
+		if customValidator != nil {
+			validators.Add("custom", customValidator)
+		}
+		return validators
 	}
+	validators := newValidatorSet(config.CustomMessageValidator)
 
 	// Register the set of validators for all topics that we publish and/or
 	// subscribe to.
@@ -332,6 +476,17 @@ func registerValidators(ctx context.Context, basicHost host.Host, config Config,
 			return err
 		}
 	}
+
+	// Each additional topic has its own custom validator.
+	for topic, customValidator := range config.AdditionalSubscribeTopics {
+		if allTopics.Contains(topic) {
+			return fmt.Errorf("additional subscribe topic is already used: %s", topic)
+		}
+		topicValidators := newValidatorSet(customValidator)
+		if err := ps.RegisterTopicValidator(topic, topicValidators.Validate, pubsub.WithValidatorInline(true)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -381,7 +536,7 @@ func (n *Node) Start() error {
 
 	// If needed, connect to all peers in the bootstrap list.
 	if n.config.UseBootstrapList {
-		if err := ConnectToBootstrapList(n.ctx, n.host, n.config.BootstrapList); err != nil {
+		if err := connectToBootstrapList(n.ctx, n.host, n.config.BootstrapList, n.dialBackoff); err != nil {
 			return err
 		}
 		// Protect the IP addresses for each bootstrap node.
@@ -393,6 +548,21 @@ func (n *Node) Start() error {
 			for _, addr := range addrInfo.Addrs {
 				_ = n.banner.ProtectIP(addr)
 			}
+			// Bootstrap peers don't contribute orders, so we exempt them from
+			// idle peer pruning.
+			n.peerSlots.exemptFromPruning(addrInfo.ID)
+		}
+
+		// If needed, fetch the network manifest from the bootstrap peers.
+		if n.config.UseNetworkManifest {
+			manifest, err := n.fetchNetworkManifest(n.ctx)
+			if err != nil {
+				// Note: The network manifest is only an optimization, so we
+				// can just log this error.
+				log.WithError(err).Warn("could not fetch network manifest")
+			} else {
+				n.applyNetworkManifest(n.ctx, manifest)
+			}
 		}
 	}
 
@@ -439,6 +609,18 @@ func (n *Node) Start() error {
 		}
 	}()
 
+	// Start the loop which receives messages from pubsub and adds them to the
+	// inbound queue.
+	receiverErrChan := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing p2p message receiver loop")
+		}()
+		receiverErrChan <- n.startMessageReceiver(innerCtx)
+	}()
+
 	// Start message handler loop.
 	messageHandlerErrChan := make(chan error, 1)
 	wg.Add(1)
@@ -461,10 +643,37 @@ func (n *Node) Start() error {
 		peerDiscoveryErrChan <- n.startPeerDiscovery(innerCtx)
 	}()
 
+	// Start the loop which periodically updates and persists peer reputations.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing p2p reputation loop")
+		}()
+		n.startReputationLoop(innerCtx)
+	}()
+
+	// Start the loop which periodically reserves connection slots for high
+	// value peers and prunes idle peers.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			log.Debug("closing p2p peer slots loop")
+		}()
+		n.startPeerSlotsLoop(innerCtx)
+	}()
+
 	// If any error channel returns a non-nil error, we cancel the inner context
 	// and return the error. Note that this means we only return the first error
 	// that occurs.
 	select {
+	case err := <-receiverErrChan:
+		if err != nil {
+			log.WithError(err).Error("message receiver loop exited with error")
+			cancel()
+			return err
+		}
 	case err := <-messageHandlerErrChan:
 		if err != nil {
 			log.WithError(err).Error("message handler loop exited with error")
@@ -508,6 +717,24 @@ func (n *Node) UnsetPeerScore(id peer.ID, tag string) {
 	n.connManager.UntagPeer(id, tag)
 }
 
+// Reputation returns the reputation engine which is used to record the behavior
+// of peers.
+func (n *Node) Reputation() *reputation.Engine {
+	return n.reputation
+}
+
+// ProtectPeer prevents the connection manager from disconnecting the given
+// peer and prevents the IP addresses the peer is currently connected from from
+// being banned. Protected peers are also never pruned for being idle. Tag is a
+// unique identifier for the protection.
+func (n *Node) ProtectPeer(id peer.ID, tag string) {
+	n.connManager.Protect(id, tag)
+	n.peerSlots.exemptFromPruning(id)
+	for _, conn := range n.host.Network().ConnsToPeer(id) {
+		_ = n.banner.ProtectIP(conn.RemoteMultiaddr())
+	}
+}
+
 // GetNumPeers returns the number of peers the node is connected to
 func (n *Node) GetNumPeers() int {
 	return n.connManager.GetInfo().ConnCount
@@ -542,7 +769,70 @@ func (n *Node) Connect(peerInfo peer.AddrInfo, timeout time.Duration) error {
 	return nil
 }
 
-// startMessageHandler continuously receives and processes incoming messages
+// DialStats returns metrics about dials to the peers in the bootstrap list.
+func (n *Node) DialStats() DialStats {
+	return n.dialBackoff.stats()
+}
+
+// InboundQueueStats returns metrics about the queue of messages which have been
+// received from pubsub but not yet handled.
+func (n *Node) InboundQueueStats() InboundQueueStats {
+	return n.inboundQueue.stats()
+}
+
+// startMessageReceiver continuously receives messages from pubsub and adds
+// them to the inbound queue until there is an error or the context is
+// canceled. Messages from each of the additional subscribe topics are received
+// in separate goroutines which feed the same inbound queue.
+func (n *Node) startMessageReceiver(ctx context.Context) error {
+	errChan := make(chan error, len(n.config.AdditionalSubscribeTopics)+1)
+	for topic := range n.config.AdditionalSubscribeTopics {
+		sub, err := n.pubsub.Subscribe(topic)
+		if err != nil {
+			return err
+		}
+		go func() {
+			errChan <- n.receiveIntoQueue(ctx, func(ctx context.Context) (*Message, error) {
+				return receiveFromSubscription(ctx, sub)
+			})
+		}()
+	}
+	go func() {
+		errChan <- n.receiveIntoQueue(ctx, n.receive)
+	}()
+	// Note: If one of the receivers returns an error, the caller cancels ctx
+	// which causes the remaining receivers to exit.
+	return <-errChan
+}
+
+// receiveIntoQueue continuously calls receive and adds the resulting messages
+// to the inbound queue until there is an error or the context is canceled.
+func (n *Node) receiveIntoQueue(ctx context.Context, receive func(context.Context) (*Message, error)) error {
+	for {
+		msg, err := receive(ctx)
+		if err != nil {
+			if err == context.Canceled || err == context.DeadlineExceeded {
+				return nil
+			}
+			return err
+		}
+		if msg.From == n.host.ID() {
+			continue
+		}
+		if chaos.DropPubSubMessage() {
+			log.WithField("from", msg.From.String()).Trace("chaos: dropped message")
+			continue
+		}
+		if !n.inboundQueue.push(msg) {
+			log.WithFields(log.Fields{
+				"from":           msg.From.String(),
+				"overflowPolicy": n.config.InboundQueueOverflowPolicy,
+			}).Trace("inbound message queue is full; dropped message")
+		}
+	}
+}
+
+// startMessageHandler continuously processes messages from the inbound queue
 // until there is an error or the context is canceled. It also checks bandwidth
 // usage on some iterations.
 func (n *Node) startMessageHandler(ctx context.Context) error {
@@ -564,11 +854,8 @@ func (n *Node) startMessageHandler(ctx context.Context) error {
 }
 
 func (n *Node) receiveAndHandleMessages(ctx context.Context) error {
-	// Receive up to maxReceiveBatch messages.
-	incoming, err := n.receiveBatch(ctx)
-	if err != nil {
-		return err
-	}
+	// Wait for up to maxReceiveBatch messages from the inbound queue.
+	incoming := n.inboundQueue.popBatch(ctx, maxReceiveBatch)
 	if len(incoming) == 0 {
 		return nil
 	}
@@ -578,6 +865,29 @@ func (n *Node) receiveAndHandleMessages(ctx context.Context) error {
 	return nil
 }
 
+// startReputationLoop periodically records the latency of connected peers and
+// persists their reputations until the context is canceled.
+func (n *Node) startReputationLoop(ctx context.Context) {
+	ticker := time.NewTicker(reputationUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Note: We don't flush here because the store may already
+			// be closed. At most reputationUpdateInterval worth of changes are
+			// lost.
+			return
+		case <-ticker.C:
+			for _, id := range n.Neighbors() {
+				n.reputation.RecordLatency(id, n.host.Peerstore().LatencyEWMA(id))
+			}
+			if err := n.reputation.Flush(); err != nil {
+				log.WithError(err).Error("could not persist peer reputations")
+			}
+		}
+	}
+}
+
 // startPeerDiscovery continuously finds new peers as needed until there is an
 // error or the context is canceled.
 func (n *Node) startPeerDiscovery(ctx context.Context) error {
@@ -620,6 +930,11 @@ func (n *Node) findNewPeers(ctx context.Context) error {
 			if peer.ID == n.host.ID() || len(peer.Addrs) == 0 {
 				continue
 			}
+			if n.dialBackoff.isUnhealthy(peer.ID) {
+				// Don't waste time dialing bootstrap peers which have recently
+				// failed too many times.
+				continue
+			}
 			log.WithFields(map[string]interface{}{
 				"peerInfo":        peer,
 				"rendezvousPoint": rendezvousPoint,
@@ -666,36 +981,6 @@ func logPeerConnectionError(peerInfo peer.AddrInfo, connectionErr error) {
 	}
 }
 
-// receiveBatch returns up to maxReceiveBatch messages which are received from
-// peers. There is no guarantee that the messages are unique.
-func (n *Node) receiveBatch(ctx context.Context) ([]*Message, error) {
-	messages := []*Message{}
-	for {
-		if len(messages) >= maxReceiveBatch {
-			return messages, nil
-		}
-		select {
-		// If the parent context was canceled, return.
-		case <-ctx.Done():
-			return messages, nil
-		default:
-		}
-		receiveCtx, receiveCancel := context.WithTimeout(n.ctx, receiveTimeout)
-		msg, err := n.receive(receiveCtx)
-		receiveCancel()
-		if err != nil {
-			if err == context.Canceled || err == context.DeadlineExceeded {
-				return messages, nil
-			}
-			return nil, err
-		}
-		if msg.From == n.host.ID() {
-			continue
-		}
-		messages = append(messages, msg)
-	}
-}
-
 // Send sends a message continaing the given data to all connected peers.
 func (n *Node) Send(data []byte) error {
 	// Note: If there is an error, we still try to publish to any remaining
@@ -711,6 +996,13 @@ func (n *Node) Send(data []byte) error {
 	return firstErr
 }
 
+// SendToTopic sends a message to a single topic instead of all the
+// PublishTopics. It is used for sharing messages on the additional subscribe
+// topics.
+func (n *Node) SendToTopic(topic string, data []byte) error {
+	return n.pubsub.Publish(topic, data)
+}
+
 // receive returns the next pending message. It blocks if no messages are
 // available. If the given context is canceled, it returns nil, ctx.Err().
 func (n *Node) receive(ctx context.Context) (*Message, error) {
@@ -721,9 +1013,19 @@ func (n *Node) receive(ctx context.Context) (*Message, error) {
 			return nil, err
 		}
 	}
-	msg, err := n.sub.Next(ctx)
+	return receiveFromSubscription(ctx, n.sub)
+}
+
+// receiveFromSubscription returns the next pending message for the given
+// subscription. It blocks if no messages are available.
+func receiveFromSubscription(ctx context.Context, sub *pubsub.Subscription) (*Message, error) {
+	msg, err := sub.Next(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &Message{From: msg.GetFrom(), Data: msg.Data}, nil
+	message := &Message{From: msg.GetFrom(), ReceivedFrom: msg.ReceivedFrom, Data: msg.Data, Topic: sub.Topic()}
+	if len(msg.Signature) > 0 {
+		message.Signer = msg.GetFrom()
+	}
+	return message, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
 	p2pnet "github.com/libp2p/go-libp2p-core/network"
 	ma "github.com/multiformats/go-multiaddr"
@@ -22,6 +23,7 @@ const (
 type notifee struct {
 	ctx         context.Context
 	connManager *connmgr.BasicConnMgr
+	reputation  *reputation.Engine
 }
 
 var _ p2pnet.Notifiee = &notifee{}
@@ -38,6 +40,9 @@ func (n *notifee) Connected(network p2pnet.Network, conn p2pnet.Conn) {
 		"remotePeerID":       conn.RemotePeer(),
 		"remoteMultiaddress": conn.RemoteMultiaddr(),
 	}).Trace("connected to peer")
+	// Restore the stored reputation of the peer, if any, so that the connection
+	// manager takes it into account.
+	n.reputation.ApplyScore(conn.RemotePeer())
 }
 
 // Disconnected is called when a connection closed
@@ -0,0 +1,310 @@
+package p2p
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	p2pnet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// highValuePeerTag is the tag used to protect connections to the peers
+	// which contribute the most valid orders.
+	highValuePeerTag = "high-value-peer"
+	// peerSlotsUpdateInterval is how frequently to update the set of protected
+	// peers and prune idle peers.
+	peerSlotsUpdateInterval = 1 * time.Minute
+	// contributionHalfLife is the amount of time after which an order
+	// contribution counts for half as much. This lets peers which used to be
+	// valuable but stopped contributing lose their reserved slot over time.
+	contributionHalfLife = 1 * time.Hour
+	// minContributionScore is the score below which the contributions of a
+	// peer that we are no longer connected to are forgotten.
+	minContributionScore = 0.01
+	// maxIdlePeerPruneRatio is the maximum fraction of peerCountLow that may be
+	// pruned for being idle in a single update. Pruning gradually gives the
+	// peer discovery loop time to find replacements.
+	maxIdlePeerPruneRatio = 0.1
+)
+
+// peerContribution keeps track of the valid orders contributed by a peer.
+type peerContribution struct {
+	// score is the number of contributed orders, decayed exponentially with
+	// contributionHalfLife as of lastUpdate.
+	score      float64
+	lastUpdate time.Time
+	// lastContribution is the time at which the peer last contributed an
+	// order.
+	lastContribution time.Time
+}
+
+// peerSlotsUpdate describes the changes to make to the connections of a node
+// as computed by peerSlots.update.
+type peerSlotsUpdate struct {
+	// protect are the peers whose connections should be protected.
+	protect []peer.ID
+	// unprotect are the peers whose connections should no longer be protected.
+	unprotect []peer.ID
+	// prune are the idle peers which should be disconnected.
+	prune []peer.ID
+}
+
+// peerSlots reserves a fraction of the connection slots for the peers which
+// contribute the most valid orders and decides which idle peers to prune when
+// the node is at its target number of connections. A peer is idle if it has
+// been connected for longer than idleTimeout without contributing an order.
+// It is safe for concurrent use.
+type peerSlots struct {
+	mu sync.Mutex
+	// reservedSlots is the number of connections reserved for high value
+	// peers.
+	reservedSlots int
+	// idleTimeout is the amount of time a peer may go without contributing an
+	// order before it is considered idle. If 0, idle peers are never pruned.
+	idleTimeout time.Duration
+	// maxPrunedPerUpdate is the maximum number of idle peers to prune in a
+	// single update.
+	maxPrunedPerUpdate int
+	// minConnections is the number of connections at or above which idle peers
+	// are pruned.
+	minConnections int
+	contributions  map[peer.ID]*peerContribution
+	// connectedAt maps connected peers to the time at which they connected.
+	connectedAt map[peer.ID]time.Time
+	// protected is the set of peers which are currently protected as high
+	// value peers.
+	protected map[peer.ID]struct{}
+	// exempt is the set of peers which are never pruned (e.g. bootstrap peers
+	// and peers which were protected for another reason).
+	exempt map[peer.ID]struct{}
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+// newPeerSlots creates a peerSlots which reserves reservedFraction of
+// maxConnections for high value peers and prunes peers which are idle for
+// longer than idleTimeout once there are at least minConnections connections.
+func newPeerSlots(reservedFraction float64, idleTimeout time.Duration, minConnections int, maxConnections int) *peerSlots {
+	maxPrunedPerUpdate := int(float64(minConnections) * maxIdlePeerPruneRatio)
+	if maxPrunedPerUpdate < 1 {
+		maxPrunedPerUpdate = 1
+	}
+	return &peerSlots{
+		reservedSlots:      int(math.Ceil(reservedFraction * float64(maxConnections))),
+		idleTimeout:        idleTimeout,
+		maxPrunedPerUpdate: maxPrunedPerUpdate,
+		minConnections:     minConnections,
+		contributions:      map[peer.ID]*peerContribution{},
+		connectedAt:        map[peer.ID]time.Time{},
+		protected:          map[peer.ID]struct{}{},
+		exempt:             map[peer.ID]struct{}{},
+		now:                time.Now,
+	}
+}
+
+// recordContribution records that the given peer contributed a valid order.
+func (s *peerSlots) recordContribution(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	contribution, found := s.contributions[id]
+	if !found {
+		contribution = &peerContribution{}
+		s.contributions[id] = contribution
+	}
+	contribution.score = decayedScore(contribution.score, now.Sub(contribution.lastUpdate)) + 1
+	contribution.lastUpdate = now
+	contribution.lastContribution = now
+}
+
+// recordConnected records that the node connected to the given peer. If the
+// node already has a connection to the peer, the original connection time is
+// kept.
+func (s *peerSlots) recordConnected(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.connectedAt[id]; !found {
+		s.connectedAt[id] = s.now()
+	}
+}
+
+// recordDisconnected records that the node no longer has any connections to
+// the given peer.
+func (s *peerSlots) recordDisconnected(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.connectedAt, id)
+}
+
+// exemptFromPruning prevents the given peer from ever being pruned for being
+// idle.
+func (s *peerSlots) exemptFromPruning(id peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exempt[id] = struct{}{}
+}
+
+// update computes which peers should be protected as high value peers and
+// which idle peers should be pruned. It assumes that the changes it returns
+// will be applied.
+func (s *peerSlots) update() peerSlotsUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	// Forget about contributions which have decayed away.
+	for id, contribution := range s.contributions {
+		if _, connected := s.connectedAt[id]; connected {
+			continue
+		}
+		if decayedScore(contribution.score, now.Sub(contribution.lastUpdate)) < minContributionScore {
+			delete(s.contributions, id)
+		}
+	}
+
+	// Rank the connected peers which have contributed orders by their decayed
+	// score and reserve slots for the best ones.
+	contributors := []peer.ID{}
+	scores := map[peer.ID]float64{}
+	for id := range s.connectedAt {
+		contribution, found := s.contributions[id]
+		if !found {
+			continue
+		}
+		contributors = append(contributors, id)
+		scores[id] = decayedScore(contribution.score, now.Sub(contribution.lastUpdate))
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if scores[contributors[i]] != scores[contributors[j]] {
+			return scores[contributors[i]] > scores[contributors[j]]
+		}
+		return contributors[i] < contributors[j]
+	})
+	if len(contributors) > s.reservedSlots {
+		contributors = contributors[:s.reservedSlots]
+	}
+	highValuePeers := map[peer.ID]struct{}{}
+	for _, id := range contributors {
+		highValuePeers[id] = struct{}{}
+	}
+
+	var result peerSlotsUpdate
+	for id := range s.protected {
+		if _, found := highValuePeers[id]; !found {
+			result.unprotect = append(result.unprotect, id)
+			delete(s.protected, id)
+		}
+	}
+	for _, id := range contributors {
+		if _, found := s.protected[id]; !found {
+			result.protect = append(result.protect, id)
+			s.protected[id] = struct{}{}
+		}
+	}
+
+	// Prune the peers which have been idle for the longest amount of time, but
+	// only if we are at the target number of connections. Otherwise, even an
+	// idle peer is better than no peer.
+	if s.idleTimeout == 0 || len(s.connectedAt) < s.minConnections {
+		return result
+	}
+	var idlePeers []peer.ID
+	idleSince := map[peer.ID]time.Time{}
+	for id, connectedAt := range s.connectedAt {
+		if _, found := highValuePeers[id]; found {
+			continue
+		}
+		if _, found := s.exempt[id]; found {
+			continue
+		}
+		lastActive := connectedAt
+		if contribution, found := s.contributions[id]; found && contribution.lastContribution.After(lastActive) {
+			lastActive = contribution.lastContribution
+		}
+		if now.Sub(lastActive) < s.idleTimeout {
+			continue
+		}
+		idlePeers = append(idlePeers, id)
+		idleSince[id] = lastActive
+	}
+	sort.Slice(idlePeers, func(i, j int) bool {
+		if !idleSince[idlePeers[i]].Equal(idleSince[idlePeers[j]]) {
+			return idleSince[idlePeers[i]].Before(idleSince[idlePeers[j]])
+		}
+		return idlePeers[i] < idlePeers[j]
+	})
+	if len(idlePeers) > s.maxPrunedPerUpdate {
+		idlePeers = idlePeers[:s.maxPrunedPerUpdate]
+	}
+	result.prune = idlePeers
+	return result
+}
+
+// decayedScore returns the given contribution score after it decayed for the
+// given amount of time.
+func decayedScore(score float64, elapsed time.Duration) float64 {
+	if score == 0 || elapsed <= 0 {
+		return score
+	}
+	return score * math.Pow(0.5, float64(elapsed)/float64(contributionHalfLife))
+}
+
+// peerSlotsNotifee returns a Notifiee which keeps track of how long this node
+// has been connected to each peer.
+func (n *Node) peerSlotsNotifee() p2pnet.Notifiee {
+	return &p2pnet.NotifyBundle{
+		ConnectedF: func(_ p2pnet.Network, conn p2pnet.Conn) {
+			n.peerSlots.recordConnected(conn.RemotePeer())
+		},
+		DisconnectedF: func(network p2pnet.Network, conn p2pnet.Conn) {
+			// Disconnected is called for each connection, so we need to check
+			// whether there are any other connections to the peer.
+			if network.Connectedness(conn.RemotePeer()) != p2pnet.Connected {
+				n.peerSlots.recordDisconnected(conn.RemotePeer())
+			}
+		},
+	}
+}
+
+// RecordOrderContribution records that the given neighbor sent us a valid
+// order which we stored, either because it published the order or because it
+// relayed it (see Message.ReceivedFrom). The peers which contribute the most
+// orders are given a reserved connection slot (see
+// Config.ReservedPeerSlotsFraction).
+func (n *Node) RecordOrderContribution(id peer.ID) {
+	n.peerSlots.recordContribution(id)
+}
+
+// startPeerSlotsLoop periodically protects the connections to high value peers
+// and prunes idle peers until the context is canceled.
+func (n *Node) startPeerSlotsLoop(ctx context.Context) {
+	ticker := time.NewTicker(peerSlotsUpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.updatePeerSlots()
+		}
+	}
+}
+
+func (n *Node) updatePeerSlots() {
+	update := n.peerSlots.update()
+	for _, id := range update.protect {
+		n.connManager.Protect(id, highValuePeerTag)
+	}
+	for _, id := range update.unprotect {
+		n.connManager.Unprotect(id, highValuePeerTag)
+	}
+	for _, id := range update.prune {
+		log.WithField("remotePeerID", id.String()).Debug("pruning idle peer")
+		_ = n.host.Network().ClosePeer(id)
+	}
+}
@@ -0,0 +1,99 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerSlotsProtectsHighValuePeers(t *testing.T) {
+	now := time.Now()
+	slots := newPeerSlots(0.2, 0, 10, 10)
+	slots.now = func() time.Time { return now }
+	peers := []peer.ID{"a", "b", "c", "d"}
+	for _, id := range peers {
+		slots.recordConnected(id)
+	}
+	// "c" contributes the most orders, followed by "a". "b" contributes one
+	// order and "d" contributes nothing.
+	for i := 0; i < 3; i++ {
+		slots.recordContribution("c")
+	}
+	slots.recordContribution("a")
+	slots.recordContribution("a")
+	slots.recordContribution("b")
+
+	update := slots.update()
+	assert.ElementsMatch(t, []peer.ID{"c", "a"}, update.protect)
+	assert.Empty(t, update.unprotect)
+	assert.Empty(t, update.prune)
+
+	// Nothing changes if no new orders are contributed.
+	assert.Equal(t, peerSlotsUpdate{}, slots.update())
+
+	// "b" overtakes "a" after the contributions of "a" decayed.
+	now = now.Add(2 * contributionHalfLife)
+	slots.recordContribution("b")
+	update = slots.update()
+	assert.Equal(t, []peer.ID{"b"}, update.protect)
+	assert.Equal(t, []peer.ID{"a"}, update.unprotect)
+
+	// Peers which disconnect lose their reserved slot.
+	slots.recordDisconnected("c")
+	update = slots.update()
+	assert.Equal(t, []peer.ID{"a"}, update.protect)
+	assert.Equal(t, []peer.ID{"c"}, update.unprotect)
+}
+
+func TestPeerSlotsPrunesIdlePeers(t *testing.T) {
+	now := time.Now()
+	idleTimeout := 30 * time.Minute
+	slots := newPeerSlots(0.1, idleTimeout, 20, 22)
+	slots.now = func() time.Time { return now }
+
+	// Connect to 20 peers, one per minute.
+	peers := []peer.ID{}
+	for i := 0; i < 20; i++ {
+		id := peer.ID(fmt.Sprintf("peer-%02d", i))
+		peers = append(peers, id)
+		slots.recordConnected(id)
+		now = now.Add(time.Minute)
+	}
+	assert.Empty(t, slots.update().prune, "no peer has been connected for longer than the idle timeout")
+
+	// Only peers which did not contribute any orders within the idle timeout
+	// are pruned, oldest first and at most 10% of the target number of
+	// connections at a time. High value and exempt peers are never pruned.
+	now = now.Add(idleTimeout)
+	slots.recordContribution(peers[0])
+	slots.recordContribution(peers[1])
+	slots.exemptFromPruning(peers[2])
+	now = now.Add(time.Minute)
+	update := slots.update()
+	assert.Equal(t, []peer.ID{peers[0], peers[1]}, update.protect)
+	assert.Equal(t, []peer.ID{peers[3], peers[4]}, update.prune)
+	slots.recordDisconnected(peers[3])
+	slots.recordDisconnected(peers[4])
+
+	// Once we are below the target number of connections, nothing is pruned.
+	assert.Empty(t, slots.update().prune)
+}
+
+func TestPeerSlotsIdleTimeoutDisabled(t *testing.T) {
+	now := time.Now()
+	slots := newPeerSlots(0, 0, 1, 1)
+	slots.now = func() time.Time { return now }
+	slots.recordConnected("a")
+	slots.recordContribution("a")
+	now = now.Add(24 * time.Hour)
+	assert.Equal(t, peerSlotsUpdate{}, slots.update())
+}
+
+func TestDecayedScore(t *testing.T) {
+	assert.Equal(t, 4.0, decayedScore(4, 0))
+	assert.InDelta(t, 2.0, decayedScore(4, contributionHalfLife), 1e-9)
+	assert.InDelta(t, 1.0, decayedScore(4, 2*contributionHalfLife), 1e-9)
+}
@@ -0,0 +1,326 @@
+// Package reputation keeps track of how useful each peer has been to us over
+// time. Unlike the scores in the connection manager, which are forgotten as
+// soon as a peer disconnects, reputations are persisted and restored whenever
+// we reconnect to a peer. When we are at the connection limit, the connection
+// manager uses the reputation score to decide which peers to keep.
+package reputation
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// Tag is the connection manager tag under which reputation scores are
+	// stored.
+	Tag = "reputation"
+	// minMessageSamples is the number of messages we need to have received from
+	// a peer before the ratio of valid to invalid messages affects its score.
+	minMessageSamples = 10
+	// minOrderSyncSamples is the number of ordersync attempts we need to have
+	// made with a peer before they affect its score.
+	minOrderSyncSamples = 3
+	// maxSamples is the maximum number of samples of each kind that we keep
+	// for a peer. Once it is exceeded, the counts are halved so that recent
+	// behavior carries more weight than behavior from a long time ago.
+	maxSamples = 1000
+	// maxMessageScore is the maximum magnitude of the score for the ratio of
+	// valid messages.
+	maxMessageScore = 20
+	// maxOrderSyncScore is the maximum magnitude of the score for ordersync
+	// cooperation.
+	maxOrderSyncScore = 10
+	// latencyScore is the magnitude of the score for latency.
+	latencyScore = 5
+	// lowLatency is the latency under which a peer receives a positive latency
+	// score.
+	lowLatency = 200 * time.Millisecond
+	// highLatency is the latency over which a peer receives a negative latency
+	// score.
+	highLatency = 2 * time.Second
+	// latencySmoothing is the weight given to new latency samples in the
+	// exponentially weighted moving average.
+	latencySmoothing = 0.1
+	// maxStoredPeers is the maximum number of peers for which reputations are
+	// stored. The peers that were seen least recently are forgotten first.
+	maxStoredPeers = 1000
+	// storedPeerTTL is how long we remember the reputation of a peer which we
+	// haven't seen.
+	storedPeerTTL = 30 * 24 * time.Hour
+)
+
+// Stats contains everything we know about the behavior of a peer.
+type Stats struct {
+	// ValidMessages is the number of valid orders received from the peer.
+	ValidMessages uint64
+	// InvalidMessages is the number of invalid orders received from the peer.
+	InvalidMessages uint64
+	// OrderSyncSuccesses is the number of ordersync requests in which the peer
+	// cooperated with us.
+	OrderSyncSuccesses uint64
+	// OrderSyncFailures is the number of ordersync requests in which the peer
+	// sent invalid messages or disconnected unexpectedly.
+	OrderSyncFailures uint64
+	// Latency is a moving average of the round trip time to the peer. It is zero
+	// if the latency is unknown.
+	Latency time.Duration
+	// LastSeen is the last time the peer's behavior was recorded.
+	LastSeen time.Time
+}
+
+// Score returns the reputation score for the given stats. Peers that we know
+// nothing about have a score of zero.
+func (s *Stats) Score() int {
+	score := 0
+	if total := s.ValidMessages + s.InvalidMessages; total >= minMessageSamples {
+		score += ratioScore(s.ValidMessages, total, maxMessageScore)
+	}
+	if total := s.OrderSyncSuccesses + s.OrderSyncFailures; total >= minOrderSyncSamples {
+		score += ratioScore(s.OrderSyncSuccesses, total, maxOrderSyncScore)
+	}
+	switch {
+	case s.Latency == 0:
+		// Latency is unknown.
+	case s.Latency <= lowLatency:
+		score += latencyScore
+	case s.Latency >= highLatency:
+		score -= latencyScore
+	}
+	return score
+}
+
+// ratioScore maps the ratio good/total onto the range [-max, max].
+func ratioScore(good uint64, total uint64, max int) int {
+	ratio := float64(good) / float64(total)
+	score := (2*ratio - 1) * float64(max)
+	if score < 0 {
+		return int(score - 0.5)
+	}
+	return int(score + 0.5)
+}
+
+// halveIfNeeded halves both counts if their sum exceeds maxSamples.
+func halveIfNeeded(good *uint64, bad *uint64) {
+	if *good+*bad > maxSamples {
+		*good /= 2
+		*bad /= 2
+	}
+}
+
+// Store is used to persist reputations. It is implemented by meshdb.MeshDB.
+type Store interface {
+	// LoadPeerReputations returns all the stored reputations.
+	LoadPeerReputations() (map[peer.ID]*Stats, error)
+	// SavePeerReputations replaces all the stored reputations with the given
+	// ones.
+	SavePeerReputations(map[peer.ID]*Stats) error
+}
+
+// Config contains configuration options for an Engine.
+type Config struct {
+	// Store is used to persist reputations. If nil, reputations are only kept
+	// in memory.
+	Store Store
+	// SetScore is called whenever the score for a peer should be updated in
+	// the connection manager.
+	SetScore func(id peer.ID, score int)
+}
+
+// Engine records the behavior of peers and computes their reputation scores.
+// It is safe for concurrent use.
+type Engine struct {
+	config Config
+	mu     sync.Mutex
+	stats  map[peer.ID]*Stats
+	dirty  bool
+}
+
+// New creates a new Engine and loads any previously stored reputations.
+func New(config Config) (*Engine, error) {
+	stats := map[peer.ID]*Stats{}
+	if config.Store != nil {
+		stored, err := config.Store.LoadPeerReputations()
+		if err != nil {
+			return nil, err
+		}
+		stats = stored
+	}
+	return &Engine{
+		config: config,
+		stats:  stats,
+	}, nil
+}
+
+// RecordValidMessage records that the peer sent us a valid order.
+func (e *Engine) RecordValidMessage(id peer.ID) {
+	e.update(id, func(s *Stats) {
+		s.ValidMessages++
+		halveIfNeeded(&s.ValidMessages, &s.InvalidMessages)
+	})
+}
+
+// RecordInvalidMessage records that the peer sent us an invalid order.
+func (e *Engine) RecordInvalidMessage(id peer.ID) {
+	e.update(id, func(s *Stats) {
+		s.InvalidMessages++
+		halveIfNeeded(&s.ValidMessages, &s.InvalidMessages)
+	})
+}
+
+// RecordOrderSyncSuccess records that the peer cooperated with an ordersync
+// request.
+func (e *Engine) RecordOrderSyncSuccess(id peer.ID) {
+	e.update(id, func(s *Stats) {
+		s.OrderSyncSuccesses++
+		halveIfNeeded(&s.OrderSyncSuccesses, &s.OrderSyncFailures)
+	})
+}
+
+// RecordOrderSyncFailure records that the peer did not cooperate with an
+// ordersync request.
+func (e *Engine) RecordOrderSyncFailure(id peer.ID) {
+	e.update(id, func(s *Stats) {
+		s.OrderSyncFailures++
+		halveIfNeeded(&s.OrderSyncSuccesses, &s.OrderSyncFailures)
+	})
+}
+
+// RecordLatency records a new round trip time sample for the peer.
+func (e *Engine) RecordLatency(id peer.ID, latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	e.update(id, func(s *Stats) {
+		if s.Latency == 0 {
+			s.Latency = latency
+			return
+		}
+		s.Latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(s.Latency))
+	})
+}
+
+// Score returns the current reputation score for the peer.
+func (e *Engine) Score(id peer.ID) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats, found := e.stats[id]
+	if !found {
+		return 0
+	}
+	return stats.Score()
+}
+
+// ApplyScore passes the current score for the peer to Config.SetScore. It
+// should be called whenever we connect to a peer so that its stored
+// reputation is taken into account by the connection manager.
+func (e *Engine) ApplyScore(id peer.ID) {
+	e.mu.Lock()
+	stats, found := e.stats[id]
+	if !found {
+		e.mu.Unlock()
+		return
+	}
+	score := stats.Score()
+	e.mu.Unlock()
+	e.setScore(id, score)
+}
+
+// Transfer moves the reputation of a peer to its new peer ID after the peer
+// rotated its identity key. The previous peer ID is left without a
+// reputation, so the same reputation can't be used by both identities at once.
+// It does nothing if the new peer ID already has a reputation.
+func (e *Engine) Transfer(from peer.ID, to peer.ID) {
+	e.mu.Lock()
+	stats, found := e.stats[from]
+	if !found {
+		e.mu.Unlock()
+		return
+	}
+	if _, found := e.stats[to]; found {
+		e.mu.Unlock()
+		return
+	}
+	delete(e.stats, from)
+	stats.LastSeen = time.Now()
+	e.stats[to] = stats
+	e.dirty = true
+	score := stats.Score()
+	e.mu.Unlock()
+	e.setScore(from, 0)
+	e.setScore(to, score)
+}
+
+// Flush persists the reputations if they have changed since the last call to
+// Flush. Reputations for peers that haven't been seen in a long time are
+// forgotten.
+func (e *Engine) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.dirty || e.config.Store == nil {
+		return nil
+	}
+	e.prune(time.Now())
+	toSave := make(map[peer.ID]*Stats, len(e.stats))
+	for id, stats := range e.stats {
+		statsCopy := *stats
+		toSave[id] = &statsCopy
+	}
+	if err := e.config.Store.SavePeerReputations(toSave); err != nil {
+		return err
+	}
+	e.dirty = false
+	return nil
+}
+
+// prune removes the reputations for peers that haven't been seen in a long
+// time and then the least recently seen peers until at most maxStoredPeers
+// remain. e.mu must be held.
+func (e *Engine) prune(now time.Time) {
+	for id, stats := range e.stats {
+		if now.Sub(stats.LastSeen) > storedPeerTTL {
+			delete(e.stats, id)
+		}
+	}
+	if len(e.stats) <= maxStoredPeers {
+		return
+	}
+	ids := make([]peer.ID, 0, len(e.stats))
+	for id := range e.stats {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return e.stats[ids[i]].LastSeen.After(e.stats[ids[j]].LastSeen)
+	})
+	for _, id := range ids[maxStoredPeers:] {
+		delete(e.stats, id)
+	}
+}
+
+// update applies the given function to the stats for the peer and updates its
+// score in the connection manager if it changed.
+func (e *Engine) update(id peer.ID, f func(s *Stats)) {
+	e.mu.Lock()
+	stats, found := e.stats[id]
+	if !found {
+		stats = &Stats{}
+		e.stats[id] = stats
+	}
+	oldScore := stats.Score()
+	f(stats)
+	stats.LastSeen = time.Now()
+	e.dirty = true
+	newScore := stats.Score()
+	e.mu.Unlock()
+	if !found || newScore != oldScore {
+		e.setScore(id, newScore)
+	}
+}
+
+func (e *Engine) setScore(id peer.ID, score int) {
+	if e.config.SetScore != nil {
+		e.config.SetScore(id, score)
+	}
+}
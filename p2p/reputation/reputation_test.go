@@ -0,0 +1,224 @@
+package reputation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	stats map[peer.ID]*Stats
+	saves int
+}
+
+func (s *memoryStore) LoadPeerReputations() (map[peer.ID]*Stats, error) {
+	loaded := map[peer.ID]*Stats{}
+	for id, stats := range s.stats {
+		statsCopy := *stats
+		loaded[id] = &statsCopy
+	}
+	return loaded, nil
+}
+
+func (s *memoryStore) SavePeerReputations(stats map[peer.ID]*Stats) error {
+	s.stats = stats
+	s.saves++
+	return nil
+}
+
+func TestStatsScore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stats    Stats
+		expected int
+	}{
+		{
+			name:     "unknown peer",
+			stats:    Stats{},
+			expected: 0,
+		},
+		{
+			name:     "too few messages",
+			stats:    Stats{InvalidMessages: minMessageSamples - 1},
+			expected: 0,
+		},
+		{
+			name:     "only valid messages",
+			stats:    Stats{ValidMessages: 100},
+			expected: maxMessageScore,
+		},
+		{
+			name:     "only invalid messages",
+			stats:    Stats{InvalidMessages: 100},
+			expected: -maxMessageScore,
+		},
+		{
+			name:     "half valid messages",
+			stats:    Stats{ValidMessages: 50, InvalidMessages: 50},
+			expected: 0,
+		},
+		{
+			name:     "cooperative ordersync",
+			stats:    Stats{OrderSyncSuccesses: 3},
+			expected: maxOrderSyncScore,
+		},
+		{
+			name:     "uncooperative ordersync",
+			stats:    Stats{OrderSyncFailures: 3},
+			expected: -maxOrderSyncScore,
+		},
+		{
+			name:     "low latency",
+			stats:    Stats{Latency: 50 * time.Millisecond},
+			expected: latencyScore,
+		},
+		{
+			name:     "medium latency",
+			stats:    Stats{Latency: time.Second},
+			expected: 0,
+		},
+		{
+			name:     "high latency",
+			stats:    Stats{Latency: 5 * time.Second},
+			expected: -latencyScore,
+		},
+		{
+			name: "best possible peer",
+			stats: Stats{
+				ValidMessages:      100,
+				OrderSyncSuccesses: 10,
+				Latency:            10 * time.Millisecond,
+			},
+			expected: maxMessageScore + maxOrderSyncScore + latencyScore,
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.stats.Score(), tc.name)
+	}
+}
+
+func TestEngineSetsScore(t *testing.T) {
+	scores := map[peer.ID]int{}
+	engine, err := New(Config{
+		SetScore: func(id peer.ID, score int) {
+			scores[id] = score
+		},
+	})
+	require.NoError(t, err)
+
+	id := peer.ID("good-peer")
+	for i := 0; i < minMessageSamples; i++ {
+		engine.RecordValidMessage(id)
+	}
+	assert.Equal(t, maxMessageScore, scores[id])
+	assert.Equal(t, maxMessageScore, engine.Score(id))
+
+	for i := 0; i < minMessageSamples; i++ {
+		engine.RecordInvalidMessage(id)
+	}
+	assert.Equal(t, 0, scores[id])
+	assert.Equal(t, 0, engine.Score(id))
+}
+
+func TestEngineHalvesSamples(t *testing.T) {
+	engine, err := New(Config{})
+	require.NoError(t, err)
+
+	id := peer.ID("peer")
+	for i := 0; i < maxSamples*2; i++ {
+		engine.RecordValidMessage(id)
+	}
+	stats := engine.stats[id]
+	assert.True(t, stats.ValidMessages <= maxSamples, "expected at most %d samples but got %d", maxSamples, stats.ValidMessages)
+}
+
+func TestEngineFlushAndLoad(t *testing.T) {
+	store := &memoryStore{}
+	engine, err := New(Config{Store: store})
+	require.NoError(t, err)
+
+	// Flushing without any changes shouldn't touch the store.
+	require.NoError(t, engine.Flush())
+	assert.Equal(t, 0, store.saves)
+
+	id := peer.ID("peer")
+	for i := 0; i < minOrderSyncSamples; i++ {
+		engine.RecordOrderSyncFailure(id)
+	}
+	engine.RecordLatency(id, 10*time.Millisecond)
+	require.NoError(t, engine.Flush())
+	assert.Equal(t, 1, store.saves)
+	require.NoError(t, engine.Flush())
+	assert.Equal(t, 1, store.saves)
+
+	// A new engine should pick up where the old one left off and apply the
+	// stored score when we reconnect to the peer.
+	scores := map[peer.ID]int{}
+	reloaded, err := New(Config{
+		Store: store,
+		SetScore: func(id peer.ID, score int) {
+			scores[id] = score
+		},
+	})
+	require.NoError(t, err)
+	expectedScore := -maxOrderSyncScore + latencyScore
+	assert.Equal(t, expectedScore, reloaded.Score(id))
+	reloaded.ApplyScore(id)
+	assert.Equal(t, expectedScore, scores[id])
+}
+
+func TestEnginePrune(t *testing.T) {
+	engine, err := New(Config{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	expired := peer.ID("expired")
+	engine.stats[expired] = &Stats{LastSeen: now.Add(-storedPeerTTL - time.Hour)}
+	for i := 0; i < maxStoredPeers+10; i++ {
+		engine.stats[peer.ID(fmt.Sprintf("peer-%d", i))] = &Stats{LastSeen: now.Add(-time.Duration(i) * time.Minute)}
+	}
+	engine.prune(now)
+
+	assert.Len(t, engine.stats, maxStoredPeers)
+	assert.NotContains(t, engine.stats, expired)
+	assert.Contains(t, engine.stats, peer.ID("peer-0"))
+	assert.NotContains(t, engine.stats, peer.ID(fmt.Sprintf("peer-%d", maxStoredPeers+9)))
+}
+
+func TestTransfer(t *testing.T) {
+	scores := map[peer.ID]int{}
+	engine, err := New(Config{
+		SetScore: func(id peer.ID, score int) {
+			scores[id] = score
+		},
+	})
+	require.NoError(t, err)
+	previousID := peer.ID("previous")
+	newID := peer.ID("new")
+	for i := 0; i < 100; i++ {
+		engine.RecordValidMessage(previousID)
+	}
+	require.NotEqual(t, 0, engine.Score(previousID))
+
+	previousScore := engine.Score(previousID)
+
+	// The reputation is moved, not copied.
+	engine.Transfer(previousID, newID)
+	assert.Equal(t, previousScore, engine.Score(newID))
+	assert.Equal(t, previousScore, scores[newID])
+	assert.Equal(t, 0, engine.Score(previousID))
+	assert.Equal(t, 0, scores[previousID])
+	assert.NotContains(t, engine.stats, previousID)
+
+	// The reputation of a peer which already has one is not overwritten.
+	otherID := peer.ID("other")
+	engine.RecordInvalidMessage(otherID)
+	otherScore := engine.Score(otherID)
+	engine.Transfer(newID, otherID)
+	assert.Equal(t, otherScore, engine.Score(otherID))
+	assert.Equal(t, previousScore, engine.Score(newID))
+}
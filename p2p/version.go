@@ -0,0 +1,52 @@
+package p2p
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseVersion parses a semantic version of the form "major.minor.patch". A
+// leading "v" and any pre-release or build metadata suffix are ignored. Minor
+// and patch versions may be omitted and default to 0.
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	trimmed := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(trimmed, "-+"); i != -1 {
+		trimmed = trimmed[:i]
+	}
+	parts := strings.Split(trimmed, ".")
+	if len(parts) > len(parsed) {
+		return parsed, fmt.Errorf("invalid version: %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid version: %q", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareVersions returns -1 if a is older than b, 1 if a is newer than b and 0
+// if they are the same.
+func compareVersions(a, b string) (int, error) {
+	parsedA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	parsedB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range parsedA {
+		switch {
+		case parsedA[i] < parsedB[i]:
+			return -1, nil
+		case parsedA[i] > parsedB[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
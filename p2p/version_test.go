@@ -0,0 +1,44 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"9.4.2", "9.4.2", 0},
+		{"v9.4.2", "9.4.2", 0},
+		{"9.4", "9.4.0", 0},
+		{"9.4.2-beta", "9.4.2", 0},
+		{"9.4.1", "9.4.2", -1},
+		{"9.3.9", "9.4.0", -1},
+		{"10.0.0", "9.4.2", 1},
+	}
+	for _, testCase := range testCases {
+		actual, err := compareVersions(testCase.a, testCase.b)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, actual, "compareVersions(%q, %q)", testCase.a, testCase.b)
+	}
+
+	_, err := compareVersions("9.x.0", "9.4.0")
+	assert.Error(t, err)
+	_, err = compareVersions("9.4.0.1", "9.4.0")
+	assert.Error(t, err)
+}
+
+func TestValidateMinPeerProtocolVersion(t *testing.T) {
+	assert.NoError(t, ValidateMinPeerProtocolVersion(ProtocolVersion))
+	assert.NoError(t, ValidateMinPeerProtocolVersion("0.9.0"))
+	assert.Error(t, ValidateMinPeerProtocolVersion("1.0.1"))
+	assert.Error(t, ValidateMinPeerProtocolVersion("2.0.0"))
+	assert.Error(t, ValidateMinPeerProtocolVersion("-1.0.0"))
+	assert.Error(t, ValidateMinPeerProtocolVersion("1.-1.0"))
+	assert.Error(t, ValidateMinPeerProtocolVersion("latest"))
+}
@@ -0,0 +1,291 @@
+/**
+ * @hidden
+ */
+
+/**
+ * NOTE: This comment must be here so that typedoc knows that the above
+ * comment is a module comment
+ */
+import { SignedOrder } from '@0x/order-utils';
+import { BigNumber } from '@0x/utils';
+
+import {
+    AcceptedOrderInfo,
+    GetOrdersResponse,
+    OrderInfo,
+    RejectedOrderInfo,
+    RejectedOrderKind,
+    ValidationResults,
+} from './types';
+
+// This is the TypeScript implementation of the binary encoding which is used
+// to pass large batches of orders across the Wasm boundary without going
+// through JSON. See encoding/binary.go for a description of the format. The
+// two implementations must be kept in sync.
+
+const uint256Size = 32;
+const addressSize = 20;
+const hashSize = 32;
+const initialBufferSize = 1024;
+const uint32Range = 0x100000000;
+
+const hexChars = '0123456789abcdef';
+const byteToHex: string[] = [];
+for (let i = 0; i < 256; i++) {
+    byteToHex.push(hexChars[i >> 4] + hexChars[i & 0xf]); // tslint:disable-line:no-bitwise
+}
+
+class BinaryWriter {
+    private _buffer: Uint8Array = new Uint8Array(initialBufferSize);
+    private _view: DataView = new DataView(this._buffer.buffer);
+    private _offset: number = 0;
+
+    public bytes(): Uint8Array {
+        return this._buffer.subarray(0, this._offset);
+    }
+
+    public writeUint32(value: number): void {
+        this._ensureCapacity(4);
+        this._view.setUint32(this._offset, value);
+        this._offset += 4;
+    }
+
+    public writeHex(hex: string, expectedSize?: number): void {
+        const start = hex.startsWith('0x') ? 2 : 0;
+        if ((hex.length - start) % 2 !== 0) {
+            throw new Error(`invalid hex string: ${hex}`);
+        }
+        const size = (hex.length - start) / 2;
+        if (expectedSize !== undefined && size !== expectedSize) {
+            throw new Error(`expected ${expectedSize} bytes but got ${size}: ${hex}`);
+        }
+        this._ensureCapacity(size);
+        for (let i = 0; i < size; i++) {
+            const byte = parseInt(hex.substr(start + i * 2, 2), 16);
+            if (isNaN(byte)) {
+                throw new Error(`invalid hex string: ${hex}`);
+            }
+            this._buffer[this._offset + i] = byte;
+        }
+        this._offset += size;
+    }
+
+    public writeBytes(hex: string): void {
+        const start = hex.startsWith('0x') ? 2 : 0;
+        this.writeUint32((hex.length - start) / 2);
+        this.writeHex(hex);
+    }
+
+    public writeAddress(address: string): void {
+        this.writeHex(address, addressSize);
+    }
+
+    public writeUint256(value: BigNumber | number): void {
+        const bigNumber = new BigNumber(value);
+        if (!bigNumber.isInteger() || bigNumber.isNegative()) {
+            throw new Error(`${bigNumber.toString()} is not a valid uint256`);
+        }
+        const hex = bigNumber.toString(16);
+        if (hex.length > uint256Size * 2) {
+            throw new Error(`${bigNumber.toString()} is not a valid uint256`);
+        }
+        this.writeHex(hex.padStart(uint256Size * 2, '0'));
+    }
+
+    public writeSignedOrder(order: SignedOrder): void {
+        this.writeUint256(order.chainId);
+        this.writeAddress(order.exchangeAddress);
+        this.writeAddress(order.makerAddress);
+        this.writeBytes(order.makerAssetData);
+        this.writeBytes(order.makerFeeAssetData);
+        this.writeUint256(order.makerAssetAmount);
+        this.writeUint256(order.makerFee);
+        this.writeAddress(order.takerAddress);
+        this.writeBytes(order.takerAssetData);
+        this.writeBytes(order.takerFeeAssetData);
+        this.writeUint256(order.takerAssetAmount);
+        this.writeUint256(order.takerFee);
+        this.writeAddress(order.senderAddress);
+        this.writeAddress(order.feeRecipientAddress);
+        this.writeUint256(order.expirationTimeSeconds);
+        this.writeUint256(order.salt);
+        this.writeBytes(order.signature);
+    }
+
+    private _ensureCapacity(size: number): void {
+        if (this._offset + size <= this._buffer.length) {
+            return;
+        }
+        let newLength = this._buffer.length * 2;
+        while (this._offset + size > newLength) {
+            newLength *= 2;
+        }
+        const newBuffer = new Uint8Array(newLength);
+        newBuffer.set(this._buffer.subarray(0, this._offset));
+        this._buffer = newBuffer;
+        this._view = new DataView(this._buffer.buffer);
+    }
+}
+
+class BinaryReader {
+    private readonly _data: Uint8Array;
+    private readonly _view: DataView;
+    private _offset: number = 0;
+
+    constructor(data: Uint8Array) {
+        this._data = data;
+        this._view = new DataView(data.buffer, data.byteOffset, data.byteLength);
+    }
+
+    public finish(): void {
+        if (this._offset !== this._data.length) {
+            throw new Error('binary data contains trailing bytes');
+        }
+    }
+
+    public readUint32(): number {
+        this._check(4);
+        const value = this._view.getUint32(this._offset);
+        this._offset += 4;
+        return value;
+    }
+
+    public readBool(): boolean {
+        this._check(1);
+        return this._data[this._offset++] !== 0;
+    }
+
+    public readHex(size: number): string {
+        this._check(size);
+        let hex = '0x';
+        for (let i = 0; i < size; i++) {
+            hex += byteToHex[this._data[this._offset + i]];
+        }
+        this._offset += size;
+        return hex;
+    }
+
+    public readBytes(): string {
+        return this.readHex(this.readUint32());
+    }
+
+    public readString(): string {
+        const length = this.readUint32();
+        this._check(length);
+        const value = new TextDecoder().decode(this._data.subarray(this._offset, this._offset + length));
+        this._offset += length;
+        return value;
+    }
+
+    public readAddress(): string {
+        return this.readHex(addressSize);
+    }
+
+    public readHash(): string {
+        return this.readHex(hashSize);
+    }
+
+    public readUint256(): BigNumber {
+        return new BigNumber(this.readHex(uint256Size).slice(2), 16);
+    }
+
+    public readTimeMs(): number {
+        this._check(8);
+        // Timestamps are signed 64-bit integers. They fit into a number since
+        // they are in milliseconds.
+        const high = this._view.getInt32(this._offset);
+        const low = this._view.getUint32(this._offset + 4);
+        this._offset += 8;
+        return high * uint32Range + low;
+    }
+
+    public readSignedOrder(): SignedOrder {
+        return {
+            chainId: this.readUint256().toNumber(),
+            exchangeAddress: this.readAddress(),
+            makerAddress: this.readAddress(),
+            makerAssetData: this.readBytes(),
+            makerFeeAssetData: this.readBytes(),
+            makerAssetAmount: this.readUint256(),
+            makerFee: this.readUint256(),
+            takerAddress: this.readAddress(),
+            takerAssetData: this.readBytes(),
+            takerFeeAssetData: this.readBytes(),
+            takerAssetAmount: this.readUint256(),
+            takerFee: this.readUint256(),
+            senderAddress: this.readAddress(),
+            feeRecipientAddress: this.readAddress(),
+            expirationTimeSeconds: this.readUint256(),
+            salt: this.readUint256(),
+            signature: this.readBytes(),
+        };
+    }
+
+    private _check(size: number): void {
+        if (this._offset + size > this._data.length) {
+            throw new Error('binary data is truncated');
+        }
+    }
+}
+
+// NOTE: These functions are only exported so that it's easier to share code
+// with the conversion tests. They should not be used outside of mesh.ts.
+// tslint:disable:completed-docs
+export function encodeOrdersBinary(orders: SignedOrder[]): Uint8Array {
+    const writer = new BinaryWriter();
+    writer.writeUint32(orders.length);
+    for (const order of orders) {
+        writer.writeSignedOrder(order);
+    }
+    return writer.bytes();
+}
+
+export function decodeValidationResultsBinary(data: Uint8Array): ValidationResults {
+    const reader = new BinaryReader(data);
+    const accepted: AcceptedOrderInfo[] = [];
+    const numAccepted = reader.readUint32();
+    for (let i = 0; i < numAccepted; i++) {
+        accepted.push({
+            orderHash: reader.readHash(),
+            signedOrder: reader.readSignedOrder(),
+            fillableTakerAssetAmount: reader.readUint256(),
+            isNew: reader.readBool(),
+        });
+    }
+    const rejected: RejectedOrderInfo[] = [];
+    const numRejected = reader.readUint32();
+    for (let i = 0; i < numRejected; i++) {
+        rejected.push({
+            orderHash: reader.readHash(),
+            signedOrder: reader.readBool() ? reader.readSignedOrder() : null,
+            kind: reader.readString() as RejectedOrderKind,
+            status: {
+                code: reader.readString(),
+                message: reader.readString(),
+            },
+        });
+    }
+    reader.finish();
+    return { accepted, rejected };
+}
+
+export function decodeGetOrdersResponseBinary(data: Uint8Array): GetOrdersResponse {
+    const reader = new BinaryReader(data);
+    const snapshotID = reader.readString();
+    const snapshotTimestamp = reader.readTimeMs();
+    const ordersInfos: OrderInfo[] = [];
+    const numOrders = reader.readUint32();
+    for (let i = 0; i < numOrders; i++) {
+        ordersInfos.push({
+            orderHash: reader.readHash(),
+            signedOrder: reader.readSignedOrder(),
+            fillableTakerAssetAmount: reader.readUint256(),
+            lastValidatedBlockNumber: reader.readBool() ? reader.readUint256() : null,
+            lastValidatedBlockHash: reader.readHash(),
+            nextRevalidationTime: reader.readTimeMs(),
+        });
+    }
+    reader.finish();
+    return { snapshotID, snapshotTimestamp, ordersInfos };
+}
+// tslint:enable:completed-docs
@@ -1,6 +1,7 @@
 import { SignedOrder } from '@0x/order-utils';
 import * as BrowserFS from 'browserfs';
 
+import { decodeGetOrdersResponseBinary, decodeValidationResultsBinary, encodeOrdersBinary } from './binary_encoding';
 import { createSchemaValidator } from './schema_validator';
 import './wasm_exec';
 
@@ -13,6 +14,9 @@ import {
     Config,
     ContractAddresses,
     ContractEvent,
+    CustomAssetValidationResult,
+    CustomAssetValidator,
+    EIP1193Provider,
     ERC1155ApprovalForAllEvent,
     ERC1155TransferBatchEvent,
     ERC1155TransferSingleEvent,
@@ -30,11 +34,14 @@ import {
     MeshWrapper,
     OrderEvent,
     OrderEventEndState,
+    OrderIndex,
     OrderInfo,
+    OrderQuery,
     RejectedOrderInfo,
     RejectedOrderKind,
     RejectedOrderStatus,
     Stats,
+    TopicStats,
     ValidationResults,
     Verbosity,
     WethDepositEvent,
@@ -45,10 +52,8 @@ import {
 import {
     configToWrapperConfig,
     orderEventsHandlerToWrapperOrderEventsHandler,
-    signedOrderToWrapperSignedOrder,
-    wrapperGetOrdersResponseToGetOrdersResponse,
+    wrapperOrderInfoToOrderInfo,
     wrapperStatsToStats,
-    wrapperValidationResultsToValidationResults,
 } from './wrapper_conversion';
 
 export {
@@ -56,6 +61,9 @@ export {
     Config,
     ContractAddresses,
     ContractEvent,
+    CustomAssetValidationResult,
+    CustomAssetValidator,
+    EIP1193Provider,
     ERC1155ApprovalForAllEvent,
     ERC1155TransferSingleEvent,
     ERC1155TransferBatchEvent,
@@ -72,11 +80,14 @@ export {
     JsonSchema,
     OrderEvent,
     OrderEventEndState,
+    OrderIndex,
     OrderInfo,
+    OrderQuery,
     RejectedOrderInfo,
     RejectedOrderKind,
     RejectedOrderStatus,
     Stats,
+    TopicStats,
     ValidationResults,
     Verbosity,
     WethDepositEvent,
@@ -269,8 +280,32 @@ export class Mesh {
             return Promise.reject(new Error('Mesh is still loading. Try again soon.'));
         }
 
-        const wrapperOrderResponse = await this._wrapper.getOrdersForPageAsync(page, perPage, snapshotID);
-        return wrapperGetOrdersResponseToGetOrdersResponse(wrapperOrderResponse);
+        const encodedOrderResponse = await this._wrapper.getOrdersForPageBinaryAsync(page, perPage, snapshotID);
+        return decodeGetOrdersResponseBinary(encodedOrderResponse);
+    }
+
+    /**
+     * Runs a read-only query against one of the indexes of the orders stored
+     * by Mesh. This is an escape hatch for advanced use cases which are not
+     * covered by getOrdersAsync, e.g. finding all orders from a particular
+     * maker or the orders which expire soonest. Orders which have been removed
+     * are never returned. Since they are filtered out after running the query,
+     * fewer than query.max orders may be returned even if there are more
+     * matching orders. Unlike getOrdersAsync, queries do not use a snapshot.
+     * @param query The query to run. See OrderIndex for the supported indexes
+     * and the format of their values.
+     * @returns the orders matching the query, in index order
+     */
+    public async queryOrdersAsync(query: OrderQuery): Promise<OrderInfo[]> {
+        await waitForLoadAsync();
+        if (this._wrapper === undefined) {
+            // If this is called after startAsync, this._wrapper is always
+            // defined. This check is here just in case and satisfies the
+            // compiler.
+            return Promise.reject(new Error('Mesh is still loading. Try again soon.'));
+        }
+        const wrapperOrderInfos = await this._wrapper.queryOrdersAsync(query);
+        return wrapperOrderInfos.map(wrapperOrderInfoToOrderInfo);
     }
 
     /**
@@ -297,9 +332,8 @@ export class Mesh {
             // compiler.
             return Promise.reject(new Error('Mesh is still loading. Try again soon.'));
         }
-        const meshOrders = orders.map(signedOrderToWrapperSignedOrder);
-        const meshResults = await this._wrapper.addOrdersAsync(meshOrders, pinned);
-        return wrapperValidationResultsToValidationResults(meshResults);
+        const encodedResults = await this._wrapper.addOrdersBinaryAsync(encodeOrdersBinary(orders), pinned);
+        return decodeValidationResultsBinary(encodedResults);
     }
 }
 
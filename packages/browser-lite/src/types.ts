@@ -2,9 +2,18 @@ import { SignedOrder } from '@0x/order-utils';
 import { BigNumber } from '@0x/utils';
 import { SupportedProvider, ZeroExProvider } from 'ethereum-types';
 
+import { WrapperOrderEvent, WrapperStats, WrapperValidationResults } from './wasm_types';
+
 export { SignedOrder } from '@0x/order-utils';
 export { BigNumber } from '@0x/utils';
 export { SupportedProvider } from 'ethereum-types';
+export {
+    WrapperAcceptedOrderInfo,
+    WrapperOrderEvent,
+    WrapperRejectedOrderInfo,
+    WrapperStats,
+    WrapperValidationResults,
+} from './wasm_types';
 
 /** @ignore */
 export interface WrapperGetOrdersResponse {
@@ -24,12 +33,65 @@ export interface WrapperOrderInfo {
     orderHash: string;
     signedOrder: WrapperSignedOrder;
     fillableTakerAssetAmount: string;
+    lastValidatedBlockNumber: string | null;
+    lastValidatedBlockHash: string;
+    nextRevalidationTime: string;
 }
 
 export interface OrderInfo {
     orderHash: string;
     signedOrder: SignedOrder;
     fillableTakerAssetAmount: BigNumber;
+    lastValidatedBlockNumber: BigNumber | null;
+    lastValidatedBlockHash: string;
+    nextRevalidationTime: number; // unix timestamp (milliseconds)
+}
+
+/**
+ * The indexes which can be used in an OrderQuery. Index values are strings with
+ * the following formats (addresses use the checksummed hex format):
+ *
+ * - makerAddressAndSalt: `<maker address>|<salt padded with zeroes to 80 digits>`
+ * - makerAddressTokenAddressTokenId: `<maker address>|<token address>|<token ID bytes>`
+ * - makerAddressMakerFeeAssetAddressTokenID: `<maker address>|<fee token address>|<token ID bytes>`
+ * - lastUpdated: `<RFC3339 timestamp with nanoseconds in UTC>`
+ * - expirationTime: `<1 for pinned orders, 0 otherwise>|<expiration time padded with zeroes to 80 digits>`
+ * - topic: `<pubsub topic>`
+ * - takerAddress: `<taker address>` (the null address for orders which can be filled by anyone)
+ * - nftAsset: `<ask, bid or any>|<ERC721 or ERC1155 contract address>|<token ID padded with zeroes to 80 digits>`
+ */
+export type OrderIndex =
+    | 'makerAddressAndSalt'
+    | 'makerAddressTokenAddressTokenId'
+    | 'makerAddressMakerFeeAssetAddressTokenID'
+    | 'lastUpdated'
+    | 'expirationTime'
+    | 'topic'
+    | 'takerAddress'
+    | 'nftAsset';
+
+/**
+ * A read-only query against one of the indexes of the orders stored by Mesh.
+ * At most one of value, prefix and start/limit may be set. If none of them are
+ * set, all orders are matched.
+ */
+export interface OrderQuery {
+    // The index to query.
+    index: OrderIndex;
+    // Matches orders with exactly this index value.
+    value?: string;
+    // Matches orders with an index value that starts with this prefix.
+    prefix?: string;
+    // Matches orders with an index value in the range [start, limit). start
+    // and limit must be set together.
+    start?: string;
+    limit?: string;
+    // The maximum number of orders to return.
+    max?: number;
+    // The number of matching orders to skip.
+    offset?: number;
+    // Whether to return orders in descending instead of ascending index order.
+    reverse?: boolean;
 }
 
 /**
@@ -109,12 +171,17 @@ export interface Config {
     // "/ip4/3.214.190.67/tcp/60558/ipfs/16Uiu2HAmGx8Z6gdq5T5AQE54GMtqDhDFhizywTy1o28NJbAMMumF").
     // Defaults to the hard-coded default bootstrap list.
     bootstrapList?: string[];
+    // useNetworkManifest is whether to fetch a signed network manifest from
+    // the bootstrap peers on startup and connect to the peers it recommends.
+    // Defaults to false.
+    useNetworkManifest?: boolean;
     // The polling interval (in seconds) to wait before checking for a new
     // Ethereum block that might contain transactions that impact the
     // fillability of orders stored by Mesh. Different chains have different
     // block producing intervals: POW chains are typically slower (e.g.,
     // Mainnet) and POA chains faster (e.g., Kovan) so one should adjust the
-    // polling interval accordingly. Defaults to 5.
+    // polling interval accordingly. Defaults to a value that is tuned for the
+    // chain (5 for Mainnet).
     blockPollingIntervalSeconds?: number;
     // The maximum request Content-Length accepted by the backing Ethereum RPC
     // endpoint used by Mesh. Geth & Infura both limit a request's content
@@ -185,9 +252,105 @@ export interface Config {
     // all the required fields) are automatically included. For more information
     // on JSON Schemas, see https://json-schema.org/
     customOrderFilter?: JsonSchema;
+    // An optional identifier for a private Mesh network. If provided, Mesh
+    // uses separate pubsub topics and rendezvous points so that it only
+    // shares orders with other nodes that use the same network ID. It may only
+    // contain letters, digits, hyphens, and underscores.
+    networkID?: string;
+    // An optional list of fee recipient addresses. If provided, Mesh will
+    // reject any new orders whose feeRecipientAddress is not in the list.
+    feeRecipientAllowlist?: string[];
+    // The maximum makerFee (in base units of the maker fee asset) that new
+    // orders can have. Defaults to no maximum.
+    maxMakerFee?: BigNumber;
+    // The maximum takerFee (in base units of the taker fee asset) that new
+    // orders can have. Defaults to no maximum.
+    maxTakerFee?: BigNumber;
+    // The minimum makerAssetAmount (in base units of the maker asset) that new
+    // orders can have. Can be used to reject dust orders. Defaults to no
+    // minimum.
+    minMakerAssetAmount?: BigNumber;
+    // The minimum takerAssetAmount (in base units of the taker asset) that new
+    // orders can have. Defaults to no minimum.
+    minTakerAssetAmount?: BigNumber;
+    // Whether to drop orders with a non-zero takerAddress (which can only be
+    // filled by a single taker) that are received from peers instead of
+    // storing and relaying them. Orders added via addOrdersAsync are still
+    // stored but are not shared with peers. Defaults to false.
+    dropTakerRestrictedOrders?: boolean;
+    // The maximum number of order messages received from peers which can be
+    // waiting to be validated. Defaults to 2,000.
+    inboundQueueSize?: number;
+    // Determines which messages are dropped when the inbound queue is full.
+    // Either "drop-oldest" or "drop-new". Defaults to "drop-oldest".
+    inboundQueueOverflowPolicy?: 'drop-oldest' | 'drop-new';
+    // The maximum approximate number of bytes of memory that decoded orders
+    // waiting to be validated can use. Defaults to no maximum.
+    maxValidationMemoryBytes?: number;
+    // Determines what happens when orders would exceed
+    // maxValidationMemoryBytes. Either "wait" (validation waits until other
+    // orders have been validated) or "shed" (the orders are dropped). Defaults
+    // to "wait".
+    validationMemoryPolicy?: 'wait' | 'shed';
+    // The number of batches of order events which are buffered for each
+    // order event subscriber that can't keep up. Defaults to 1,000.
+    orderEventsBufferSize?: number;
+    // Determines what happens when the order event buffer of a subscriber is
+    // full. Either "block" (order events are not delivered to any subscriber
+    // until the slow subscriber catches up), "drop-oldest" or "drop-new".
+    // Defaults to "block".
+    orderEventsOverflowPolicy?: 'block' | 'drop-oldest' | 'drop-new';
     // Offers the ability to use your own web3 provider for all Ethereum RPC
     // requests instead of the default.
     web3Provider?: SupportedProvider;
+    // An EIP-1193 provider (e.g. window.ethereum from MetaMask or a
+    // WalletConnect provider) to use for all Ethereum RPC requests instead of
+    // the default. This allows Mesh to piggyback on the user's wallet
+    // connection. While the provider is connected to a chain other than
+    // ethereumChainID, Ethereum RPC requests fail. Cannot be used together
+    // with web3Provider.
+    ethereumProvider?: EIP1193Provider;
+    // A list of validators which add support for asset types that Mesh does
+    // not support natively (e.g. assets whose ownership is attested
+    // off-chain). Orders involving such assets are validated on-chain as
+    // usual (e.g. to reject cancelled or fully filled orders) and their
+    // fillable amount is then determined by the first validator which
+    // supports one of their assetData fields. They are only re-validated
+    // periodically.
+    customAssetValidators?: CustomAssetValidator[];
+}
+
+/**
+ * A provider which implements the EIP-1193 JavaScript Ethereum Provider API.
+ */
+export interface EIP1193Provider {
+    request(args: { method: string; params?: unknown[] | object }): Promise<unknown>;
+    on?(event: string, listener: (...args: any[]) => void): unknown;
+    removeListener?(event: string, listener: (...args: any[]) => void): unknown;
+}
+
+/**
+ * A custom validator for asset types that Mesh does not support natively.
+ */
+export interface CustomAssetValidator {
+    // Returns true if this validator is responsible for the given hex-encoded
+    // assetData. It is only called for assetData that Mesh does not support
+    // natively.
+    supportsAssetData(assetData: string): boolean;
+    // Returns one result for each of the given orders, in the same order. It
+    // is only called for orders which passed on-chain validation. blockNumber
+    // is the block number at which the orders are being validated (or
+    // undefined for the latest block). If the promise rejects, all of the
+    // orders are rejected.
+    validateOrdersAsync(orders: SignedOrder[], blockNumber?: BigNumber): Promise<CustomAssetValidationResult[]>;
+}
+
+export interface CustomAssetValidationResult {
+    // The amount of the taker asset that can currently be filled. If it is
+    // zero, the order is rejected as unfunded.
+    fillableTakerAssetAmount: BigNumber;
+    // If provided, the order is rejected with the given status.
+    rejectedStatus?: RejectedOrderStatus;
 }
 
 export interface ContractAddresses {
@@ -232,7 +395,12 @@ export interface MeshWrapper {
     onOrderEvents(handler: (events: WrapperOrderEvent[]) => void): void;
     getStatsAsync(): Promise<WrapperStats>;
     getOrdersForPageAsync(page: number, perPage: number, snapshotID?: string): Promise<WrapperGetOrdersResponse>;
+    queryOrdersAsync(query: OrderQuery): Promise<WrapperOrderInfo[]>;
     addOrdersAsync(orders: WrapperSignedOrder[], pinned: boolean): Promise<WrapperValidationResults>;
+    // The binary variants pass orders using the binary encoding (see
+    // binary_encoding.ts), which is much faster for large batches of orders.
+    getOrdersForPageBinaryAsync(page: number, perPage: number, snapshotID?: string): Promise<Uint8Array>;
+    addOrdersBinaryAsync(orders: Uint8Array, pinned: boolean): Promise<Uint8Array>;
 }
 
 /**
@@ -245,6 +413,7 @@ export interface WrapperConfig {
     ethereumChainID: number;
     useBootstrapList?: boolean;
     bootstrapList?: string; // comma-separated string instead of an array of strings.
+    useNetworkManifest?: boolean;
     blockPollingIntervalSeconds?: number;
     ethereumRPCMaxContentLength?: number;
     ethereumRPCMaxRequestsPer24HrUTC?: number;
@@ -253,7 +422,43 @@ export interface WrapperConfig {
     customContractAddresses?: string; // json-encoded string instead of Object.
     maxOrdersInStorage?: number;
     customOrderFilter?: string; // json-encoded string instead of Object
+    networkID?: string;
+    feeRecipientAllowlist?: string; // comma-separated string instead of an array of strings.
+    maxMakerFee?: string; // string instead of BigNumber
+    maxTakerFee?: string; // string instead of BigNumber
+    minMakerAssetAmount?: string; // string instead of BigNumber
+    minTakerAssetAmount?: string; // string instead of BigNumber
+    dropTakerRestrictedOrders?: boolean;
+    inboundQueueSize?: number;
+    inboundQueueOverflowPolicy?: string;
+    maxValidationMemoryBytes?: number;
+    validationMemoryPolicy?: string;
+    orderEventsBufferSize?: number;
+    orderEventsOverflowPolicy?: string;
     web3Provider?: ZeroExProvider; // Standardized ZeroExProvider instead the more permissive SupportedProvider interface
+    ethereumProvider?: EIP1193Provider;
+    customAssetValidators?: WrapperCustomAssetValidator[];
+}
+
+/**
+ * The type for custom asset validators exposed by MeshWrapper.
+ * @ignore
+ */
+export interface WrapperCustomAssetValidator {
+    supportsAssetData(assetData: string): boolean;
+    validateOrdersAsync(
+        orders: WrapperSignedOrder[],
+        blockNumber?: string,
+    ): Promise<WrapperCustomAssetValidationResult[]>;
+}
+
+/**
+ * The type for custom asset validation results exposed by MeshWrapper.
+ * @ignore
+ */
+export interface WrapperCustomAssetValidationResult {
+    fillableTakerAssetAmount: string;
+    rejectedStatus?: RejectedOrderStatus;
 }
 
 /**
@@ -543,19 +748,11 @@ export enum OrderEventEndState {
     Unexpired = 'UNEXPIRED',
     Unfunded = 'UNFUNDED',
     FillabilityIncreased = 'FILLABILITY_INCREASED',
+    FillReverted = 'FILL_REVERTED',
+    CancelReverted = 'CANCEL_REVERTED',
     StoppedWatching = 'STOPPED_WATCHING',
 }
 
-/** @ignore */
-export interface WrapperOrderEvent {
-    timestamp: string;
-    orderHash: string;
-    signedOrder: WrapperSignedOrder;
-    endState: OrderEventEndState;
-    fillableTakerAssetAmount: string;
-    contractEvents: WrapperContractEvent[];
-}
-
 /**
  * Order events are fired by Mesh whenever an order is added, canceled, expired,
  * or filled.
@@ -567,28 +764,8 @@ export interface OrderEvent {
     endState: OrderEventEndState;
     fillableTakerAssetAmount: BigNumber;
     contractEvents: ContractEvent[];
-}
-
-/** @ignore */
-export interface WrapperValidationResults {
-    accepted: WrapperAcceptedOrderInfo[];
-    rejected: WrapperRejectedOrderInfo[];
-}
-
-/** @ignore */
-export interface WrapperAcceptedOrderInfo {
-    orderHash: string;
-    signedOrder: WrapperSignedOrder;
-    fillableTakerAssetAmount: string;
-    isNew: boolean;
-}
-
-/** @ignore */
-export interface WrapperRejectedOrderInfo {
-    orderHash: string;
-    signedOrder: WrapperSignedOrder;
-    kind: RejectedOrderKind;
-    status: RejectedOrderStatus;
+    sequenceNumber: number;
+    epoch: string;
 }
 
 /**
@@ -615,7 +792,8 @@ export interface AcceptedOrderInfo {
  */
 export interface RejectedOrderInfo {
     orderHash: string;
-    signedOrder: SignedOrder;
+    // signedOrder is null if the order could not be parsed.
+    signedOrder: SignedOrder | null;
     kind: RejectedOrderKind;
     status: RejectedOrderStatus;
 }
@@ -643,25 +821,6 @@ export interface LatestBlock {
     hash: string;
 }
 
-/** @ignore */
-export interface WrapperStats {
-    version: string;
-    pubSubTopic: string;
-    rendezvous: string;
-    secondaryRendezvous: string[];
-    peerID: string;
-    ethereumChainID: number;
-    latestBlock: LatestBlock;
-    numPeers: number;
-    numOrders: number;
-    numOrdersIncludingRemoved: number;
-    numPinnedOrders: number;
-    maxExpirationTime: string; // string instead of BigNumber
-    startOfCurrentUTCDay: string; // string instead of Date
-    ethRPCRequestsSentInCurrentUTCDay: number;
-    ethRPCRateLimitExpiredRequests: number;
-}
-
 export interface Stats {
     version: string;
     pubSubTopic: string;
@@ -674,9 +833,50 @@ export interface Stats {
     numOrders: number;
     numOrdersIncludingRemoved: number;
     numPinnedOrders: number;
+    numColdOrders: number;
     maxExpirationTime: BigNumber;
     startOfCurrentUTCDay: Date;
     ethRPCRequestsSentInCurrentUTCDay: number;
     ethRPCRateLimitExpiredRequests: number;
+    ethRPCMaxRequestsPer24HrUTC: number;
+    ethRPCRemainingRequestsInCurrentUTCDay: number;
+    ethRPCBudgetExhaustionETASeconds: number;
+    ethRPCCacheHits: number;
+    ethRPCCacheMisses: number;
+    ethRPCCacheEntries: number;
+    storageUsedBytes: number;
+    maxOrders: number;
+    currentOrders: number;
+    evictedOrdersLast24h: number;
+    storageUtilizationPercent: number;
+    inboundQueueLength: number;
+    inboundQueueDroppedMessages: number;
+    validationMemoryBytes: number;
+    validationMemoryShedOrders: number;
+    dedupedOrderSubmissions: number;
+    mismatchedDomainOrders: number;
+    droppedOrderEvents: number;
+    ingestionPaused: boolean;
+    orderSyncBytesSaved: number;
+    slowDBQueries: number;
+    dbFragmentationPercent: number;
+    dbReclaimedBytes: number;
+    bootstrapDialFailures: number;
+    unhealthyBootstrapPeers: number;
+    startTime: Date;
+    uptimeSeconds: number;
+    gitCommit: string;
+    buildDate: string;
+    goVersion: string;
+    enabledFeatures: string[];
+    configHash: string;
+    topics: TopicStats[];
+}
+
+export interface TopicStats {
+    topic: string;
+    numOrders: number;
+    maxOrders: number;
+    quotaDroppedMessages: number;
 }
 // tslint:disable-next-line:max-file-line-count
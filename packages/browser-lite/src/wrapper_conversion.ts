@@ -13,6 +13,7 @@ import {
     AcceptedOrderInfo,
     Config,
     ContractEvent,
+    CustomAssetValidator,
     ContractEventKind,
     ContractEventParameters,
     ERC1155ApprovalForAllEvent,
@@ -27,6 +28,7 @@ import {
     WrapperAcceptedOrderInfo,
     WrapperConfig,
     WrapperContractEvent,
+    WrapperCustomAssetValidator,
     WrapperERC1155TransferBatchEvent,
     WrapperERC1155TransferSingleEvent,
     WrapperERC20ApprovalEvent,
@@ -54,14 +56,50 @@ export function configToWrapperConfig(config: Config): WrapperConfig {
     const customContractAddresses =
         config.customContractAddresses == null ? undefined : JSON.stringify(config.customContractAddresses);
     const customOrderFilter = config.customOrderFilter == null ? undefined : JSON.stringify(config.customOrderFilter);
+    const feeRecipientAllowlist =
+        config.feeRecipientAllowlist == null ? undefined : config.feeRecipientAllowlist.join(',');
+    const maxMakerFee = config.maxMakerFee == null ? undefined : config.maxMakerFee.toString();
+    const maxTakerFee = config.maxTakerFee == null ? undefined : config.maxTakerFee.toString();
+    const minMakerAssetAmount =
+        config.minMakerAssetAmount == null ? undefined : config.minMakerAssetAmount.toString();
+    const minTakerAssetAmount =
+        config.minTakerAssetAmount == null ? undefined : config.minTakerAssetAmount.toString();
     const standardizedProvider =
         config.web3Provider == null ? undefined : providerUtils.standardizeOrThrow(config.web3Provider);
+    const customAssetValidators =
+        config.customAssetValidators == null
+            ? undefined
+            : config.customAssetValidators.map(customAssetValidatorToWrapperCustomAssetValidator);
     return {
         ...config,
         bootstrapList,
         customContractAddresses,
         customOrderFilter,
+        feeRecipientAllowlist,
+        maxMakerFee,
+        maxTakerFee,
+        minMakerAssetAmount,
+        minTakerAssetAmount,
         web3Provider: standardizedProvider,
+        customAssetValidators,
+    };
+}
+
+export function customAssetValidatorToWrapperCustomAssetValidator(
+    validator: CustomAssetValidator,
+): WrapperCustomAssetValidator {
+    return {
+        supportsAssetData: (assetData: string) => validator.supportsAssetData(assetData),
+        validateOrdersAsync: async (orders: WrapperSignedOrder[], blockNumber?: string) => {
+            const results = await validator.validateOrdersAsync(
+                orders.map(wrapperSignedOrderToSignedOrder),
+                blockNumber === undefined ? undefined : new BigNumber(blockNumber),
+            );
+            return results.map(result => ({
+                fillableTakerAssetAmount: result.fillableTakerAssetAmount.toString(),
+                rejectedStatus: result.rejectedStatus,
+            }));
+        },
     };
 }
 
@@ -275,6 +313,7 @@ export function wrapperStatsToStats(wrapperStats: WrapperStats): Stats {
     return {
         ...wrapperStats,
         startOfCurrentUTCDay: new Date(wrapperStats.startOfCurrentUTCDay),
+        startTime: new Date(wrapperStats.startTime),
         maxExpirationTime: new BigNumber(wrapperStats.maxExpirationTime),
     };
 }
@@ -294,6 +333,11 @@ export function wrapperOrderInfoToOrderInfo(wrapperOrderInfo: WrapperOrderInfo):
         ...wrapperOrderInfo,
         fillableTakerAssetAmount: new BigNumber(wrapperOrderInfo.fillableTakerAssetAmount),
         signedOrder: wrapperSignedOrderToSignedOrder(wrapperOrderInfo.signedOrder),
+        lastValidatedBlockNumber:
+            wrapperOrderInfo.lastValidatedBlockNumber === null
+                ? null
+                : new BigNumber(wrapperOrderInfo.lastValidatedBlockNumber),
+        nextRevalidationTime: new Date(wrapperOrderInfo.nextRevalidationTime).getTime(),
     };
 }
 // tslint:enable:completed-docs
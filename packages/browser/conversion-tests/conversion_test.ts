@@ -714,6 +714,7 @@ function testStats(stats: WrapperStats[]): void {
     printer('numPeers', stats[0].numPeers === 200);
     printer('numOrdersIncludingRemoved', stats[0].numOrdersIncludingRemoved === 200000);
     printer('numPinnedOrders', stats[0].numPinnedOrders === 400);
+    printer('numColdOrders', stats[0].numColdOrders === 50);
     printer(
         'maxExpirationTime',
         stats[0].maxExpirationTime === '115792089237316195423570985008687907853269984665640564039457584007913129639935',
@@ -721,6 +722,49 @@ function testStats(stats: WrapperStats[]): void {
     printer('startOfCurrentUTCDay', stats[0].startOfCurrentUTCDay === '2006-01-01 00:00:00 +0000 UTC');
     printer('ethRPCRequestsSentInCurrentUTCDay', stats[0].ethRPCRequestsSentInCurrentUTCDay === 100000);
     printer('ethRPCRateLimitExpiredRequests', stats[0].ethRPCRateLimitExpiredRequests === 5000);
+    printer('ethRPCMaxRequestsPer24HrUTC', stats[0].ethRPCMaxRequestsPer24HrUTC === 200000);
+    printer('ethRPCRemainingRequestsInCurrentUTCDay', stats[0].ethRPCRemainingRequestsInCurrentUTCDay === 100000);
+    printer('ethRPCBudgetExhaustionETASeconds', stats[0].ethRPCBudgetExhaustionETASeconds === 3600);
+    printer('ethRPCCacheHits', stats[0].ethRPCCacheHits === 300);
+    printer('ethRPCCacheMisses', stats[0].ethRPCCacheMisses === 200);
+    printer('ethRPCCacheEntries', stats[0].ethRPCCacheEntries === 100);
+    printer('storageUsedBytes', stats[0].storageUsedBytes === 1048576);
+    printer('maxOrders', stats[0].maxOrders === 400000);
+    printer('currentOrders', stats[0].currentOrders === 200000);
+    printer('evictedOrdersLast24h', stats[0].evictedOrdersLast24h === 300);
+    printer('storageUtilizationPercent', stats[0].storageUtilizationPercent === 50);
+    printer('inboundQueueLength', stats[0].inboundQueueLength === 20);
+    printer('inboundQueueDroppedMessages', stats[0].inboundQueueDroppedMessages === 10);
+    printer('validationMemoryBytes', stats[0].validationMemoryBytes === 4096);
+    printer('validationMemoryShedOrders', stats[0].validationMemoryShedOrders === 5);
+    printer('dedupedOrderSubmissions', stats[0].dedupedOrderSubmissions === 3);
+    printer('mismatchedDomainOrders', stats[0].mismatchedDomainOrders === 2);
+    printer('droppedOrderEvents', stats[0].droppedOrderEvents === 7);
+    printer('ingestionPaused', stats[0].ingestionPaused === true);
+    printer('orderSyncBytesSaved', stats[0].orderSyncBytesSaved === 2048);
+    printer('slowDBQueries', stats[0].slowDBQueries === 3);
+    printer('dbFragmentationPercent', stats[0].dbFragmentationPercent === 12.5);
+    printer('dbReclaimedBytes', stats[0].dbReclaimedBytes === 589824);
+    printer('bootstrapDialFailures', stats[0].bootstrapDialFailures === 4);
+    printer('unhealthyBootstrapPeers', stats[0].unhealthyBootstrapPeers === 1);
+    printer('startTime', stats[0].startTime === '2006-01-01T12:00:00Z');
+    printer('uptimeSeconds', stats[0].uptimeSeconds === 3600);
+    printer('gitCommit', stats[0].gitCommit === '0123456789abcdef');
+    printer('buildDate', stats[0].buildDate === '2006-01-01T00:00:00Z');
+    printer('goVersion', stats[0].goVersion === 'go1.13.4');
+    printer(
+        'enabledFeatures',
+        stats[0].enabledFeatures.length === 1 && stats[0].enabledFeatures[0] === 'USE_BOOTSTRAP_LIST',
+    );
+    printer('configHash', stats[0].configHash === 'someHash');
+    printer(
+        'topics',
+        stats[0].topics.length === 1 &&
+            stats[0].topics[0].topic === 'someTopic' &&
+            stats[0].topics[0].numOrders === 1000 &&
+            stats[0].topics[0].maxOrders === 2000 &&
+            stats[0].topics[0].quotaDroppedMessages === 5,
+    );
 }
 
 function testValidationResults(validationResults: WrapperValidationResults[]): void {
@@ -0,0 +1,116 @@
+// +build js,wasm
+
+// Package assetvalidatorwrapper wraps a JavaScript object in order to
+// implement the ordervalidator.AssetValidator interface.
+package assetvalidatorwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	"github.com/0xProject/0x-mesh/packages/browser/go/jsutil"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Ensure that we implement the ordervalidator.AssetValidator interface.
+var _ ordervalidator.AssetValidator = &AssetValidator{}
+
+// AssetValidator implements ordervalidator.AssetValidator by calling methods
+// on a JavaScript object with the following interface:
+//
+//	interface WrapperCustomAssetValidator {
+//	    supportsAssetData(assetData: string): boolean;
+//	    validateOrdersAsync(
+//	        orders: WrapperSignedOrder[],
+//	        blockNumber?: string,
+//	    ): Promise<WrapperCustomAssetValidationResult[]>;
+//	}
+//
+//	interface WrapperCustomAssetValidationResult {
+//	    fillableTakerAssetAmount: string;
+//	    rejectedStatus?: RejectedOrderStatus;
+//	}
+type AssetValidator struct {
+	validator js.Value
+}
+
+// NewAssetValidator returns a new AssetValidator which wraps the given
+// JavaScript object.
+func NewAssetValidator(validator js.Value) *AssetValidator {
+	return &AssetValidator{
+		validator: validator,
+	}
+}
+
+// SupportsAssetData calls supportsAssetData on the underlying JavaScript
+// object. If the call throws, it returns false.
+func (a *AssetValidator) SupportsAssetData(assetData []byte) (supported bool) {
+	defer func() {
+		if e := recover(); e != nil {
+			supported = false
+		}
+	}()
+	return a.validator.Call("supportsAssetData", hexutil.Encode(assetData)).Truthy()
+}
+
+type assetValidationResultJSON struct {
+	FillableTakerAssetAmount string                              `json:"fillableTakerAssetAmount"`
+	RejectedStatus           *ordervalidator.RejectedOrderStatus `json:"rejectedStatus"`
+}
+
+// ValidateOrders calls validateOrdersAsync on the underlying JavaScript object
+// and waits for the returned promise to settle.
+func (a *AssetValidator) ValidateOrders(ctx context.Context, signedOrders []*zeroex.SignedOrder, blockNumber *big.Int) (results []*ordervalidator.AssetValidationResult, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			switch e := e.(type) {
+			case error:
+				err = e
+			default:
+				err = fmt.Errorf("unexpected error: (%T) %s", e, e)
+			}
+		}
+	}()
+
+	signedOrdersJS := make([]interface{}, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		signedOrdersJS[i] = signedOrder.JSValue()
+	}
+	blockNumberJS := js.Undefined()
+	if blockNumber != nil {
+		blockNumberJS = js.ValueOf(blockNumber.String())
+	}
+	promise := a.validator.Call("validateOrdersAsync", signedOrdersJS, blockNumberJS)
+	jsResults, err := jsutil.AwaitPromise(ctx, promise)
+	if err != nil {
+		return nil, err
+	}
+	var rawResults []*assetValidationResultJSON
+	if err := jsutil.InefficientlyConvertFromJS(jsResults, &rawResults); err != nil {
+		return nil, err
+	}
+
+	results = make([]*ordervalidator.AssetValidationResult, len(rawResults))
+	for i, rawResult := range rawResults {
+		if rawResult == nil {
+			return nil, errors.New("validateOrdersAsync returned a null result")
+		}
+		result := &ordervalidator.AssetValidationResult{
+			RejectedStatus: rawResult.RejectedStatus,
+		}
+		if rawResult.FillableTakerAssetAmount != "" {
+			fillableTakerAssetAmount, ok := new(big.Int).SetString(rawResult.FillableTakerAssetAmount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid fillableTakerAssetAmount: %q", rawResult.FillableTakerAssetAmount)
+			}
+			result.FillableTakerAssetAmount = fillableTakerAssetAmount
+		}
+		results[i] = result
+	}
+	return results, nil
+}
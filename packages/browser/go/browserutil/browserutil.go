@@ -12,6 +12,7 @@ import (
 
 	"github.com/0xProject/0x-mesh/core"
 	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/packages/browser/go/assetvalidatorwrapper"
 	"github.com/0xProject/0x-mesh/packages/browser/go/jsutil"
 	"github.com/0xProject/0x-mesh/packages/browser/go/providerwrapper"
 )
@@ -30,7 +31,6 @@ func ConvertConfig(jsConfig js.Value) (core.Config, error) {
 		P2PTCPPort:                       0,
 		P2PWebSocketsPort:                0,
 		UseBootstrapList:                 true,
-		BlockPollingInterval:             5 * time.Second,
 		EthereumRPCMaxContentLength:      524288,
 		EthereumRPCMaxRequestsPer24HrUTC: 100000,
 		EthereumRPCMaxRequestsPerSecond:  30,
@@ -56,6 +56,9 @@ func ConvertConfig(jsConfig js.Value) (core.Config, error) {
 	if bootstrapList := jsConfig.Get("bootstrapList"); !jsutil.IsNullOrUndefined(bootstrapList) {
 		config.BootstrapList = bootstrapList.String()
 	}
+	if useNetworkManifest := jsConfig.Get("useNetworkManifest"); !jsutil.IsNullOrUndefined(useNetworkManifest) {
+		config.UseNetworkManifest = useNetworkManifest.Bool()
+	}
 	if blockPollingIntervalSeconds := jsConfig.Get("blockPollingIntervalSeconds"); !jsutil.IsNullOrUndefined(blockPollingIntervalSeconds) {
 		config.BlockPollingInterval = time.Duration(blockPollingIntervalSeconds.Int()) * time.Second
 	}
@@ -80,12 +83,65 @@ func ConvertConfig(jsConfig js.Value) (core.Config, error) {
 	if customOrderFilter := jsConfig.Get("customOrderFilter"); !jsutil.IsNullOrUndefined(customOrderFilter) {
 		config.CustomOrderFilter = customOrderFilter.String()
 	}
+	if networkID := jsConfig.Get("networkID"); !jsutil.IsNullOrUndefined(networkID) {
+		config.NetworkID = networkID.String()
+	}
+	if feeRecipientAllowlist := jsConfig.Get("feeRecipientAllowlist"); !jsutil.IsNullOrUndefined(feeRecipientAllowlist) {
+		config.FeeRecipientAllowlist = feeRecipientAllowlist.String()
+	}
+	if maxMakerFee := jsConfig.Get("maxMakerFee"); !jsutil.IsNullOrUndefined(maxMakerFee) {
+		config.MaxMakerFee = maxMakerFee.String()
+	}
+	if maxTakerFee := jsConfig.Get("maxTakerFee"); !jsutil.IsNullOrUndefined(maxTakerFee) {
+		config.MaxTakerFee = maxTakerFee.String()
+	}
+	if minMakerAssetAmount := jsConfig.Get("minMakerAssetAmount"); !jsutil.IsNullOrUndefined(minMakerAssetAmount) {
+		config.MinMakerAssetAmount = minMakerAssetAmount.String()
+	}
+	if minTakerAssetAmount := jsConfig.Get("minTakerAssetAmount"); !jsutil.IsNullOrUndefined(minTakerAssetAmount) {
+		config.MinTakerAssetAmount = minTakerAssetAmount.String()
+	}
+	if dropTakerRestrictedOrders := jsConfig.Get("dropTakerRestrictedOrders"); !jsutil.IsNullOrUndefined(dropTakerRestrictedOrders) {
+		config.DropTakerRestrictedOrders = dropTakerRestrictedOrders.Bool()
+	}
+	if inboundQueueSize := jsConfig.Get("inboundQueueSize"); !jsutil.IsNullOrUndefined(inboundQueueSize) {
+		config.InboundQueueSize = inboundQueueSize.Int()
+	}
+	if inboundQueueOverflowPolicy := jsConfig.Get("inboundQueueOverflowPolicy"); !jsutil.IsNullOrUndefined(inboundQueueOverflowPolicy) {
+		config.InboundQueueOverflowPolicy = inboundQueueOverflowPolicy.String()
+	}
+	if maxValidationMemoryBytes := jsConfig.Get("maxValidationMemoryBytes"); !jsutil.IsNullOrUndefined(maxValidationMemoryBytes) {
+		config.MaxValidationMemoryBytes = maxValidationMemoryBytes.Int()
+	}
+	if validationMemoryPolicy := jsConfig.Get("validationMemoryPolicy"); !jsutil.IsNullOrUndefined(validationMemoryPolicy) {
+		config.ValidationMemoryPolicy = validationMemoryPolicy.String()
+	}
+	if orderEventsBufferSize := jsConfig.Get("orderEventsBufferSize"); !jsutil.IsNullOrUndefined(orderEventsBufferSize) {
+		config.OrderEventsBufferSize = orderEventsBufferSize.Int()
+	}
+	if orderEventsOverflowPolicy := jsConfig.Get("orderEventsOverflowPolicy"); !jsutil.IsNullOrUndefined(orderEventsOverflowPolicy) {
+		config.OrderEventsOverflowPolicy = orderEventsOverflowPolicy.String()
+	}
 	if ethereumRPCURL := jsConfig.Get("ethereumRPCURL"); !jsutil.IsNullOrUndefined(ethereumRPCURL) && ethereumRPCURL.String() != "" {
 		config.EthereumRPCURL = ethereumRPCURL.String()
 	}
 	if web3Provider := jsConfig.Get("web3Provider"); !jsutil.IsNullOrUndefined(web3Provider) {
 		config.EthereumRPCClient = providerwrapper.NewRPCClient(web3Provider)
 	}
+	if ethereumProvider := jsConfig.Get("ethereumProvider"); !jsutil.IsNullOrUndefined(ethereumProvider) {
+		if config.EthereumRPCClient != nil {
+			return core.Config{}, errors.New("only one of web3Provider and ethereumProvider can be set")
+		}
+		if !providerwrapper.IsEIP1193Provider(ethereumProvider) {
+			return core.Config{}, errors.New("ethereumProvider must be an EIP-1193 provider with a request method")
+		}
+		config.EthereumRPCClient = providerwrapper.NewEIP1193RPCClient(ethereumProvider, config.EthereumChainID)
+	}
+	if customAssetValidators := jsConfig.Get("customAssetValidators"); !jsutil.IsNullOrUndefined(customAssetValidators) {
+		for i := 0; i < customAssetValidators.Length(); i++ {
+			config.CustomAssetValidators = append(config.CustomAssetValidators, assetvalidatorwrapper.NewAssetValidator(customAssetValidators.Index(i)))
+		}
+	}
 
 	return config, nil
 }
@@ -343,10 +343,44 @@ func registerStatsTest(description string) {
 	registerStatsField(description, "numPeers")
 	registerStatsField(description, "numOrdersIncludingRemoved")
 	registerStatsField(description, "numPinnedOrders")
+	registerStatsField(description, "numColdOrders")
 	registerStatsField(description, "maxExpirationTime")
 	registerStatsField(description, "startOfCurrentUTCDay")
 	registerStatsField(description, "ethRPCRequestsSentInCurrentUTCDay")
 	registerStatsField(description, "ethRPCRateLimitExpiredRequests")
+	registerStatsField(description, "ethRPCMaxRequestsPer24HrUTC")
+	registerStatsField(description, "ethRPCRemainingRequestsInCurrentUTCDay")
+	registerStatsField(description, "ethRPCBudgetExhaustionETASeconds")
+	registerStatsField(description, "ethRPCCacheHits")
+	registerStatsField(description, "ethRPCCacheMisses")
+	registerStatsField(description, "ethRPCCacheEntries")
+	registerStatsField(description, "storageUsedBytes")
+	registerStatsField(description, "maxOrders")
+	registerStatsField(description, "currentOrders")
+	registerStatsField(description, "evictedOrdersLast24h")
+	registerStatsField(description, "storageUtilizationPercent")
+	registerStatsField(description, "inboundQueueLength")
+	registerStatsField(description, "inboundQueueDroppedMessages")
+	registerStatsField(description, "validationMemoryBytes")
+	registerStatsField(description, "validationMemoryShedOrders")
+	registerStatsField(description, "dedupedOrderSubmissions")
+	registerStatsField(description, "mismatchedDomainOrders")
+	registerStatsField(description, "droppedOrderEvents")
+	registerStatsField(description, "ingestionPaused")
+	registerStatsField(description, "orderSyncBytesSaved")
+	registerStatsField(description, "slowDBQueries")
+	registerStatsField(description, "dbFragmentationPercent")
+	registerStatsField(description, "dbReclaimedBytes")
+	registerStatsField(description, "bootstrapDialFailures")
+	registerStatsField(description, "unhealthyBootstrapPeers")
+	registerStatsField(description, "startTime")
+	registerStatsField(description, "uptimeSeconds")
+	registerStatsField(description, "gitCommit")
+	registerStatsField(description, "buildDate")
+	registerStatsField(description, "goVersion")
+	registerStatsField(description, "enabledFeatures")
+	registerStatsField(description, "configHash")
+	registerStatsField(description, "topics")
 }
 
 func registerValidationResultsTest(description string, acceptedLength int, rejectedLength int) {
@@ -496,14 +496,55 @@ func setGlobals() {
 						Hash:   common.HexToHash("0x1"),
 						Number: 1500,
 					},
-					NumPeers:                          200,
-					NumOrders:                         100000,
-					NumOrdersIncludingRemoved:         200000,
-					NumPinnedOrders:                   400,
-					MaxExpirationTime:                 "115792089237316195423570985008687907853269984665640564039457584007913129639935",
-					StartOfCurrentUTCDay:              time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC),
-					EthRPCRequestsSentInCurrentUTCDay: 100000,
-					EthRPCRateLimitExpiredRequests:    5000,
+					NumPeers:                               200,
+					NumOrders:                              100000,
+					NumOrdersIncludingRemoved:              200000,
+					NumPinnedOrders:                        400,
+					NumColdOrders:                          50,
+					MaxExpirationTime:                      "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+					StartOfCurrentUTCDay:                   time.Date(2006, time.January, 1, 0, 0, 0, 0, time.UTC),
+					EthRPCRequestsSentInCurrentUTCDay:      100000,
+					EthRPCRateLimitExpiredRequests:         5000,
+					EthRPCMaxRequestsPer24HrUTC:            200000,
+					EthRPCRemainingRequestsInCurrentUTCDay: 100000,
+					EthRPCBudgetExhaustionETASeconds:       3600,
+					EthRPCCacheHits:                        300,
+					EthRPCCacheMisses:                      200,
+					EthRPCCacheEntries:                     100,
+					StorageUsedBytes:                       1048576,
+					MaxOrders:                              400000,
+					CurrentOrders:                          200000,
+					EvictedOrdersLast24h:                   300,
+					StorageUtilizationPercent:              50,
+					InboundQueueLength:                     20,
+					InboundQueueDroppedMessages:            10,
+					ValidationMemoryBytes:                  4096,
+					ValidationMemoryShedOrders:             5,
+					DedupedOrderSubmissions:                3,
+					MismatchedDomainOrders:                 2,
+					DroppedOrderEvents:                     7,
+					IngestionPaused:                        true,
+					OrderSyncBytesSaved:                    2048,
+					SlowDBQueries:                          3,
+					DBFragmentationPercent:                 12.5,
+					DBReclaimedBytes:                       589824,
+					BootstrapDialFailures:                  4,
+					UnhealthyBootstrapPeers:                1,
+					StartTime:                              time.Date(2006, time.January, 1, 12, 0, 0, 0, time.UTC),
+					UptimeSeconds:                          3600,
+					GitCommit:                              "0123456789abcdef",
+					BuildDate:                              "2006-01-01T00:00:00Z",
+					GoVersion:                              "go1.13.4",
+					EnabledFeatures:                        []string{"USE_BOOTSTRAP_LIST"},
+					ConfigHash:                             "someHash",
+					Topics: []types.TopicStats{
+						{
+							Topic:                "someTopic",
+							NumOrders:            1000,
+							MaxOrders:            2000,
+							QuotaDroppedMessages: 5,
+						},
+					},
 				},
 			}
 		}),
@@ -669,7 +710,6 @@ func setGlobals() {
 				P2PTCPPort:                       0,
 				P2PWebSocketsPort:                0,
 				UseBootstrapList:                 true,
-				BlockPollingInterval:             5 * time.Second,
 				EthereumRPCMaxContentLength:      524288,
 				EthereumRPCMaxRequestsPer24HrUTC: 100000,
 				EthereumRPCMaxRequestsPerSecond:  30,
@@ -6,6 +6,7 @@ package jsutil
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"syscall/js"
@@ -44,6 +45,60 @@ func WrapInPromise(f func() (interface{}, error)) js.Value {
 	return js.Global().Get("Promise").New(executor)
 }
 
+// AwaitPromise blocks until the given JavaScript Promise is settled or ctx is
+// canceled. If the promise resolves, it returns the resolved value. If it
+// rejects, it returns the rejection reason as an error. It must not be called
+// from the main JavaScript thread (i.e. directly inside of a js.Func callback).
+func AwaitPromise(ctx context.Context, promise js.Value) (js.Value, error) {
+	resultChan := make(chan js.Value, 1)
+	errChan := make(chan error, 1)
+	var onResolve, onReject js.Func
+	onResolve = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		defer onResolve.Release()
+		defer onReject.Release()
+		if len(args) == 0 {
+			resultChan <- js.Undefined()
+		} else {
+			resultChan <- args[0]
+		}
+		return nil
+	})
+	onReject = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		defer onResolve.Release()
+		defer onReject.Release()
+		if len(args) == 0 {
+			errChan <- fmt.Errorf("promise rejected without a reason")
+		} else {
+			errChan <- js.Error{Value: args[0]}
+		}
+		return nil
+	})
+	promise.Call("then", onResolve, onReject)
+	select {
+	case <-ctx.Done():
+		return js.Undefined(), ctx.Err()
+	case err := <-errChan:
+		return js.Undefined(), err
+	case result := <-resultChan:
+		return result, nil
+	}
+}
+
+// CopyBytesFromJS copies the contents of the given JavaScript Uint8Array into a
+// new byte slice.
+func CopyBytesFromJS(array js.Value) []byte {
+	b := make([]byte, array.Get("length").Int())
+	js.CopyBytesToGo(b, array)
+	return b
+}
+
+// CopyBytesToJS copies the given byte slice into a new JavaScript Uint8Array.
+func CopyBytesToJS(b []byte) js.Value {
+	array := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(array, b)
+	return array
+}
+
 // InefficientlyConvertToJS converts the given Go value to a JS value by
 // encoding to JSON and then decoding it. This function is not very efficient
 // and its use should be phased out over time as much as possible.
@@ -8,7 +8,10 @@ import (
 	"syscall/js"
 	"time"
 
+	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/core"
+	"github.com/0xProject/0x-mesh/encoding"
+	"github.com/0xProject/0x-mesh/meshdb"
 	"github.com/0xProject/0x-mesh/packages/browser/go/browserutil"
 	"github.com/0xProject/0x-mesh/packages/browser/go/jsutil"
 	"github.com/0xProject/0x-mesh/zeroex"
@@ -154,6 +157,37 @@ func (cw *MeshWrapper) AddOrders(rawOrders js.Value, pinned bool) (js.Value, err
 	return resultsJS, nil
 }
 
+// AddOrdersBinary is like AddOrders but the orders and the results are passed
+// as Uint8Arrays using the binary encoding (see encoding.EncodeOrdersBinary).
+// This avoids converting large batches of orders to and from JSON on both
+// sides of the Wasm boundary.
+func (cw *MeshWrapper) AddOrdersBinary(encodedOrders js.Value, pinned bool) (js.Value, error) {
+	signedOrders, err := encoding.DecodeOrdersBinary(jsutil.CopyBytesFromJS(encodedOrders))
+	if err != nil {
+		return js.Undefined(), err
+	}
+	// core.App.AddOrders expects raw JSON so that it can validate the orders
+	// against the JSON Schema.
+	rawMessages := make([]*json.RawMessage, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		encodedOrder, err := json.Marshal(signedOrder)
+		if err != nil {
+			return js.Undefined(), err
+		}
+		rawMessage := json.RawMessage(encodedOrder)
+		rawMessages[i] = &rawMessage
+	}
+	results, err := cw.app.AddOrders(cw.ctx, rawMessages, pinned)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	encodedResults, err := encoding.EncodeValidationResultsBinary(results)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return jsutil.CopyBytesToJS(encodedResults), nil
+}
+
 // GetStats calls core.GetStats, converts the result to a js.Value and returns
 // it.
 func (cw *MeshWrapper) GetStats() (js.Value, error) {
@@ -168,13 +202,43 @@ func (cw *MeshWrapper) GetStats() (js.Value, error) {
 // core.App.GetOrders, converts the result into basic JavaScript types (string,
 // int, etc.) and returns it.
 func (cw *MeshWrapper) GetOrders(page int, perPage int, snapshotID string) (js.Value, error) {
-	ordersResponse, err := cw.app.GetOrders(page, perPage, snapshotID)
+	ordersResponse, err := cw.app.GetOrders(page, perPage, snapshotID, types.GetOrdersOpts{})
 	if err != nil {
 		return js.Undefined(), err
 	}
 	return js.ValueOf(ordersResponse), nil
 }
 
+// GetOrdersBinary is like GetOrders but the response is returned as a
+// Uint8Array using the binary encoding (see
+// encoding.EncodeGetOrdersResponseBinary).
+func (cw *MeshWrapper) GetOrdersBinary(page int, perPage int, snapshotID string) (js.Value, error) {
+	ordersResponse, err := cw.app.GetOrders(page, perPage, snapshotID, types.GetOrdersOpts{})
+	if err != nil {
+		return js.Undefined(), err
+	}
+	encodedResponse, err := encoding.EncodeGetOrdersResponseBinary(ordersResponse)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return jsutil.CopyBytesToJS(encodedResponse), nil
+}
+
+// QueryOrders converts the raw JavaScript query into a meshdb.OrderQuery,
+// calls core.App.QueryOrders, converts the result into basic JavaScript types
+// and returns it.
+func (cw *MeshWrapper) QueryOrders(rawQuery js.Value) (js.Value, error) {
+	var query meshdb.OrderQuery
+	if err := jsutil.InefficientlyConvertFromJS(rawQuery, &query); err != nil {
+		return js.Undefined(), err
+	}
+	ordersInfos, err := cw.app.QueryOrders(&query)
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return jsutil.InefficientlyConvertToJS(ordersInfos)
+}
+
 // JSValue satisfies the js.Wrapper interface. The return value is a JavaScript
 // object consisting of named functions. They act like methods by capturing the
 // MeshWrapper through a closure.
@@ -216,11 +280,33 @@ func (cw *MeshWrapper) JSValue() js.Value {
 				return cw.GetOrders(args[0].Int(), args[1].Int(), snapshotID)
 			})
 		}),
+		// getOrdersForPageBinaryAsync(page: number, perPage: number, snapshotID?: string): Promise<Uint8Array>
+		"getOrdersForPageBinaryAsync": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return jsutil.WrapInPromise(func() (interface{}, error) {
+				snapshotID := ""
+				if !jsutil.IsNullOrUndefined(args[2]) {
+					snapshotID = args[2].String()
+				}
+				return cw.GetOrdersBinary(args[0].Int(), args[1].Int(), snapshotID)
+			})
+		}),
+		// queryOrdersAsync(query: OrderQuery): Promise<Array<OrderInfo>>
+		"queryOrdersAsync": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return jsutil.WrapInPromise(func() (interface{}, error) {
+				return cw.QueryOrders(args[0])
+			})
+		}),
 		// addOrdersAsync(orders: Array<SignedOrder>): Promise<ValidationResults>
 		"addOrdersAsync": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 			return jsutil.WrapInPromise(func() (interface{}, error) {
 				return cw.AddOrders(args[0], args[1].Bool())
 			})
 		}),
+		// addOrdersBinaryAsync(orders: Uint8Array, pinned: boolean): Promise<Uint8Array>
+		"addOrdersBinaryAsync": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return jsutil.WrapInPromise(func() (interface{}, error) {
+				return cw.AddOrdersBinary(args[0], args[1].Bool())
+			})
+		}),
 	})
 }
@@ -0,0 +1,211 @@
+// +build js,wasm
+
+package providerwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall/js"
+
+	"github.com/0xProject/0x-mesh/packages/browser/go/jsutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+)
+
+// Ensure that we implement the ethclient.RPCClient interface.
+var _ ethclient.RPCClient = &EIP1193RPCClient{}
+
+// errWrongChain is returned for all requests while the injected provider is
+// connected to a different chain than the one Mesh is configured for.
+var errWrongChain = errors.New("EIP-1193 provider is connected to the wrong chain")
+
+// EIP1193RPCClient implements the RPCClient interface by routing requests
+// through an injected EIP-1193 provider (e.g. the window.ethereum object of a
+// wallet such as MetaMask, or a WalletConnect provider). This allows browser
+// nodes to use the user's existing wallet connection instead of a separate
+// Ethereum RPC endpoint.
+type EIP1193RPCClient struct {
+	// wrongChain is 1 while the provider is connected to a chain other than
+	// chainID. It must be accessed atomically.
+	wrongChain int32
+	// provider is the underlying EIP-1193 provider which will be used for
+	// sending requests.
+	provider js.Value
+	chainID  int
+	// onChainChanged is the listener for the provider's "chainChanged" event.
+	// It is only set if listening is true.
+	onChainChanged js.Func
+	listening      bool
+}
+
+// NewEIP1193RPCClient returns an RPCClient which sends requests through the
+// given EIP-1193 provider. chainID is the chain that Mesh is configured for.
+// Whenever the provider switches to a different chain (e.g. because the user
+// changed networks in their wallet), requests fail until it switches back so
+// that Mesh never mixes up state from different chains.
+func NewEIP1193RPCClient(provider js.Value, chainID int) *EIP1193RPCClient {
+	c := &EIP1193RPCClient{
+		provider: provider,
+		chainID:  chainID,
+	}
+	if on := provider.Get("on"); on.Type() == js.TypeFunction {
+		c.onChainChanged = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if len(args) == 0 {
+				return nil
+			}
+			c.handleChainChanged(args[0])
+			return nil
+		})
+		provider.Call("on", "chainChanged", c.onChainChanged)
+		c.listening = true
+	}
+	return c
+}
+
+// IsEIP1193Provider returns true if the given value implements the EIP-1193
+// request method.
+func IsEIP1193Provider(provider js.Value) bool {
+	return !jsutil.IsNullOrUndefined(provider) && provider.Get("request").Type() == js.TypeFunction
+}
+
+func (c *EIP1193RPCClient) handleChainChanged(jsChainID js.Value) {
+	// EIP-1193 specifies that the chain ID is a hex string, but some older
+	// providers emit a decimal string or a number.
+	var newChainID int64
+	var err error
+	switch jsChainID.Type() {
+	case js.TypeNumber:
+		newChainID = int64(jsChainID.Int())
+	case js.TypeString:
+		s := jsChainID.String()
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			newChainID, err = strconv.ParseInt(s[2:], 16, 64)
+		} else {
+			newChainID, err = strconv.ParseInt(s, 10, 64)
+		}
+	default:
+		err = fmt.Errorf("unexpected type: %s", jsChainID.Type())
+	}
+	if err != nil {
+		log.WithError(err).Warn("EIP-1193 provider emitted an invalid chain ID")
+		return
+	}
+	if newChainID == int64(c.chainID) {
+		if atomic.SwapInt32(&c.wrongChain, 0) == 1 {
+			log.WithField("chainID", newChainID).Info("EIP-1193 provider switched back to the configured chain")
+		}
+		return
+	}
+	atomic.StoreInt32(&c.wrongChain, 1)
+	log.WithFields(log.Fields{
+		"chainID":         newChainID,
+		"expectedChainID": c.chainID,
+	}).Error("EIP-1193 provider switched to a different chain; Ethereum RPC requests will fail until it switches back")
+}
+
+// CallContext performs a JSON-RPC call with the given arguments. If the context is
+// canceled before the call has successfully returned, CallContext returns immediately.
+//
+// The result must be a pointer so that package json can unmarshal into it. You
+// can also pass nil, in which case the result is ignored.
+func (c *EIP1193RPCClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	// Notable type definitions from EIP-1193:
+	//
+	//     interface RequestArguments {
+	//         readonly method: string;
+	//         readonly params?: readonly unknown[] | object;
+	//     }
+	//
+	//     interface ProviderRpcError extends Error {
+	//         code: number;
+	//         data?: unknown;
+	//     }
+	//
+	//     request(args: RequestArguments): Promise<unknown>;
+	//
+	if atomic.LoadInt32(&c.wrongChain) == 1 {
+		return errWrongChain
+	}
+
+	// Some providers reject requests without params, so an empty array is
+	// always sent.
+	if args == nil {
+		args = []interface{}{}
+	}
+	// Convert args to a value that is compatible with syscall/js. Since we don't
+	// know the underlying type of args, the only reliable way to do this is to
+	// convert to and from JSON.
+	convertedParams, err := jsutil.InefficientlyConvertToJS(args)
+	if err != nil {
+		return fmt.Errorf("invalid args for JSON payload: %s", err.Error())
+	}
+	requestArgs := map[string]interface{}{
+		"method": method,
+		"params": convertedParams,
+	}
+
+	jsResult, err := jsutil.AwaitPromise(ctx, c.provider.Call("request", requestArgs))
+	if err != nil {
+		if jsErr, ok := err.(js.Error); ok {
+			return providerErrorToError(jsErr.Value)
+		}
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := jsutil.InefficientlyConvertFromJS(jsResult, result); err != nil {
+		return fmt.Errorf("could not decode JSON RPC response: %s", err.Error())
+	}
+	return nil
+}
+
+// BatchCallContext sends each request in the batch through the provider one by
+// one, since EIP-1193 does not support batch requests.
+//
+// In contrast to CallContext, BatchCallContext only returns errors that have occurred
+// while sending the request. Any error specific to a request is reported through the
+// Error field of the corresponding BatchElem.
+func (c *EIP1193RPCClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b[i].Error = c.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...)
+	}
+	return nil
+}
+
+// EthSubscribe registers a subscripion under the "eth" namespace.
+func (c *EIP1193RPCClient) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	log.WithField("args", args).Error("EthSubscribe was unexpectedly called in the browser")
+	return nil, errors.New("EthSubscribe not yet implemented")
+}
+
+// Close stops listening for events from the provider. It does not disconnect
+// the provider, since it is owned by the wallet.
+func (c *EIP1193RPCClient) Close() {
+	if !c.listening {
+		return
+	}
+	c.listening = false
+	if removeListener := c.provider.Get("removeListener"); removeListener.Type() == js.TypeFunction {
+		c.provider.Call("removeListener", "chainChanged", c.onChainChanged)
+	}
+	c.onChainChanged.Release()
+}
+
+// providerErrorToError converts an error thrown by an EIP-1193 provider to a
+// Go error. ProviderRpcErrors are converted to rpc.Errors so that their codes
+// are preserved.
+func providerErrorToError(jsError js.Value) error {
+	if jsError.Type() == js.TypeObject && jsError.Get("code").Type() == js.TypeNumber {
+		return jsErrorToRPCError(jsError)
+	}
+	return js.Error{Value: jsError}
+}
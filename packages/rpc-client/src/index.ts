@@ -2,9 +2,13 @@ export { WSClient } from './ws_client';
 export {
     ClientConfig,
     WSOpts,
+    SubscribeToOrdersOpts,
+    AssetDataPair,
     OrderEventEndState,
     OrderEventPayload,
     OrderEvent,
+    OrderEventsNotification,
+    OrderEventsNotificationType,
     OrderInfo,
     AcceptedOrderInfo,
     RejectedKind,
@@ -12,8 +16,23 @@ export {
     RejectedStatus,
     RejectedOrderInfo,
     ValidationResults,
+    ValidationTrace,
+    GetOrdersOpts,
+    OrderSortField,
+    NFTFilter,
     GetOrdersResponse,
     GetStatsResponse,
+    TopicStats,
+    LatestBlock,
+    ResyncResponse,
+    SoftCancel,
+    GetMakersOpts,
+    MakerInfo,
+    MakerAssetAmount,
+    MakerEpoch,
+    MarketInfo,
+    Block,
+    BlockLog,
 } from './types';
 export { SignedOrder } from '@0x/types';
 export { BigNumber } from '@0x/utils';
@@ -35,6 +35,77 @@ export interface WSOpts {
     reconnectDelay?: number;
 }
 
+/**
+ * coalesceIntervalMs: if greater than 0, order events are sent at most once per interval (in milliseconds) and
+ * successive fill updates for the same order within an interval are coalesced into a single event (default: 0)
+ * makerAddress: only sends order events for orders with the given makerAddress (default: doesn't filter order events
+ * by makerAddress)
+ * assetDataPairs: only sends order events for orders which trade one of the given pairs of assets, in either
+ * direction (default: doesn't filter order events by the assets they trade)
+ * endStates: only sends order events with one of the given end states (default: sends order events with any end state)
+ * ackMode: if true, each notification has to be acknowledged before the next one is sent and order events are
+ * buffered on the Mesh node in the meantime (default: false). Can't be combined with coalesceIntervalMs.
+ */
+export interface SubscribeToOrdersOpts {
+    ackMode?: boolean;
+    coalesceIntervalMs?: number;
+    makerAddress?: string;
+    assetDataPairs?: AssetDataPair[];
+    endStates?: OrderEventEndState[];
+}
+
+export interface AssetDataPair {
+    baseAssetData: string;
+    quoteAssetData: string;
+}
+
+/**
+ * maxStalenessSeconds: excludes orders which were last validated more than the given number of seconds ago
+ * (default: 0, which doesn't exclude any orders)
+ * takerAddress: only returns orders with the given takerAddress. Use the null address to only get orders which can be
+ * filled by anyone (default: doesn't filter orders by takerAddress)
+ * sort: an ordered list of fields to sort orders by, e.g. price then expiration time. Orders which are equal in all of
+ * the fields are sorted by hash (default: orders are sorted by hash)
+ * nft: only returns orders which buy or sell ERC721 or ERC1155 tokens matching the filter (default: doesn't filter
+ * orders by the tokens they buy or sell)
+ */
+export interface GetOrdersOpts {
+    maxStalenessSeconds?: number;
+    takerAddress?: string;
+    sort?: OrderSortField[];
+    nft?: NFTFilter;
+}
+
+/**
+ * side: either 'ask' (orders which sell a matching token), 'bid' (orders which buy a matching token) or 'any'
+ * (default: 'any')
+ * contractAddress: only matches tokens of the given contract (default: tokens of any contract)
+ * tokenId: only matches the token with the given ID. contractAddress must be set if tokenId is set (default: tokens
+ * with any ID)
+ */
+export interface NFTFilter {
+    side?: 'ask' | 'bid' | 'any';
+    contractAddress?: string;
+    tokenId?: string;
+}
+
+/**
+ * field: either 'price' (takerAssetAmount / makerAssetAmount) or 'expirationTime'
+ * direction: either 'asc' or 'desc' (default: 'asc')
+ */
+export interface OrderSortField {
+    field: 'price' | 'expirationTime';
+    direction?: 'asc' | 'desc';
+}
+
+/**
+ * makerAddresses: restricts the results to the given makers. Makers without any open orders are included if they are
+ * requested explicitly (default: all makers with at least one open order)
+ */
+export interface GetMakersOpts {
+    makerAddresses?: string[];
+}
+
 export interface StringifiedSignedOrder {
     senderAddress: string;
     makerAddress: string;
@@ -292,6 +363,8 @@ export enum OrderEventEndState {
     StoppedWatching = 'STOPPED_WATCHING',
     Unfunded = 'UNFUNDED',
     FillabilityIncreased = 'FILLABILITY_INCREASED',
+    FillReverted = 'FILL_REVERTED',
+    CancelReverted = 'CANCEL_REVERTED',
 }
 
 export interface OrderEventPayload {
@@ -299,6 +372,33 @@ export interface OrderEventPayload {
     result: RawOrderEvent[];
 }
 
+export type OrderEventsNotificationType = 'orderEvents' | 'overflow';
+
+export interface RawOrderEventsNotification {
+    type: OrderEventsNotificationType;
+    subscriptionId: string;
+    ackId: number;
+    orderEvents: RawOrderEvent[];
+    droppedOrderEvents?: number;
+}
+
+/**
+ * A notification which is sent to subscriptions in ack mode. If type is 'overflow', droppedOrderEvents order events
+ * were dropped because the buffer on the Mesh node was full, and the subscriber should resync its orders.
+ */
+export interface OrderEventsNotification {
+    type: OrderEventsNotificationType;
+    subscriptionId: string;
+    ackId: number;
+    orderEvents: OrderEvent[];
+    droppedOrderEvents: number;
+}
+
+export interface OrderEventsNotificationPayload {
+    subscription: string;
+    result: RawOrderEventsNotification;
+}
+
 export interface HeartbeatEventPayload {
     subscription: string;
     result: string;
@@ -311,6 +411,8 @@ export interface RawOrderEvent {
     endState: OrderEventEndState;
     fillableTakerAssetAmount: string;
     contractEvents: StringifiedContractEvent[];
+    sequenceNumber: number;
+    epoch: string;
 }
 
 export interface OrderEvent {
@@ -320,6 +422,20 @@ export interface OrderEvent {
     endState: OrderEventEndState;
     fillableTakerAssetAmount: BigNumber;
     contractEvents: ContractEvent[];
+    sequenceNumber: number;
+    epoch: string;
+}
+
+/**
+ * Timing information about the validation of a single order. Only included if tracing was requested (see
+ * `addOrdersAsync`). All durations are in nanoseconds. Orders are validated on-chain in batches, so each eth_call
+ * also covers other orders in the same batch.
+ */
+export interface ValidationTrace {
+    schemaValidationDuration: number;
+    signatureValidationDuration: number;
+    ethCallCount: number;
+    ethCallDuration: number;
 }
 
 export interface RawAcceptedOrderInfo {
@@ -327,6 +443,7 @@ export interface RawAcceptedOrderInfo {
     signedOrder: StringifiedSignedOrder;
     fillableTakerAssetAmount: string;
     isNew: boolean;
+    trace?: ValidationTrace;
 }
 
 export interface AcceptedOrderInfo {
@@ -334,18 +451,42 @@ export interface AcceptedOrderInfo {
     signedOrder: SignedOrder;
     fillableTakerAssetAmount: BigNumber;
     isNew: boolean;
+    trace?: ValidationTrace;
 }
 
 export interface RawOrderInfo {
     orderHash: string;
     signedOrder: StringifiedSignedOrder;
     fillableTakerAssetAmount: string;
+    lastValidatedBlockNumber: string | null;
+    lastValidatedBlockHash: string;
+    nextRevalidationTime: string;
+    fillabilityScore?: number;
+    annotations?: { [key: string]: string };
+    staleness: number;
+    signer?: string;
 }
 
 export interface OrderInfo {
     orderHash: string;
     signedOrder: SignedOrder;
     fillableTakerAssetAmount: BigNumber;
+    lastValidatedBlockNumber: BigNumber | null;
+    lastValidatedBlockHash: string;
+    nextRevalidationTime: number; // unix timestamp (milliseconds)
+    // An estimate between 0 and 1 of how likely the order is to be fillable.
+    // Only present if the Mesh node has fillability scores enabled.
+    fillabilityScore?: number;
+    // Key-value pairs attached to the order by the Mesh node's order policy.
+    // Only present if the order policy annotated the order.
+    annotations?: { [key: string]: string };
+    // The number of seconds since the order was last validated, i.e. the age
+    // of fillableTakerAssetAmount.
+    staleness: number;
+    // The peer ID of the node which published and signed the GossipSub message
+    // in which the order was first received. Only present if the order was
+    // received in a signed GossipSub message.
+    signer?: string;
 }
 
 export enum RejectedKind {
@@ -369,6 +510,10 @@ export enum RejectedCode {
     OrderHasInvalidMakerAssetData = 'OrderHasInvalidMakerAssetData',
     OrderHasInvalidTakerAssetData = 'OrderHasInvalidTakerAssetData',
     OrderHasInvalidSignature = 'OrderHasInvalidSignature',
+    OrderNotStored = 'OrderNotStored',
+    OrderPolicyRejected = 'OrderPolicyRejected',
+    OrderTooSmall = 'OrderTooSmall',
+    ValidationTimedOut = 'ValidationTimedOut',
 }
 
 export interface RejectedStatus {
@@ -381,6 +526,7 @@ export interface RawRejectedOrderInfo {
     signedOrder: StringifiedSignedOrder;
     kind: RejectedKind;
     status: RejectedStatus;
+    trace?: ValidationTrace;
 }
 
 export interface RejectedOrderInfo {
@@ -388,6 +534,7 @@ export interface RejectedOrderInfo {
     signedOrder: SignedOrder;
     kind: RejectedKind;
     status: RejectedStatus;
+    trace?: ValidationTrace;
 }
 
 export interface RawValidationResults {
@@ -425,6 +572,18 @@ export interface LatestBlock {
     hash: string;
 }
 
+export interface ResyncResponse {
+    latestBlock: LatestBlock;
+    syncedPeers: string[];
+    failedPeers: string[];
+}
+
+export interface SoftCancel {
+    orderHash: string;
+    makerAddress: string;
+    signature: string;
+}
+
 export interface GetStatsResponse {
     version: string;
     pubSubTopic: string;
@@ -436,8 +595,164 @@ export interface GetStatsResponse {
     numOrders: number;
     numOrdersIncludingRemoved: number;
     numPinnedOrders: number;
+    numColdOrders: number;
     maxExpirationTime: string;
     startOfCurrentUTCDay: string;
     ethRPCRequestsSentInCurrentUTCDay: number;
     ethRPCRateLimitExpiredRequests: number;
+    ethRPCMaxRequestsPer24HrUTC: number;
+    ethRPCRemainingRequestsInCurrentUTCDay: number;
+    ethRPCBudgetExhaustionETASeconds: number;
+    ethRPCCacheHits: number;
+    ethRPCCacheMisses: number;
+    ethRPCCacheEntries: number;
+    storageUsedBytes: number;
+    maxOrders: number;
+    currentOrders: number;
+    evictedOrdersLast24h: number;
+    storageUtilizationPercent: number;
+    inboundQueueLength: number;
+    inboundQueueDroppedMessages: number;
+    validationMemoryBytes: number;
+    validationMemoryShedOrders: number;
+    dedupedOrderSubmissions: number;
+    mismatchedDomainOrders: number;
+    droppedOrderEvents: number;
+    ingestionPaused: boolean;
+    orderSyncBytesSaved: number;
+    slowDBQueries: number;
+    dbFragmentationPercent: number;
+    dbReclaimedBytes: number;
+    bootstrapDialFailures: number;
+    unhealthyBootstrapPeers: number;
+    startTime: string;
+    uptimeSeconds: number;
+    gitCommit: string;
+    buildDate: string;
+    goVersion: string;
+    enabledFeatures: string[];
+    configHash: string;
+    topics: TopicStats[];
+}
+
+export interface TopicStats {
+    topic: string;
+    numOrders: number;
+    maxOrders: number;
+    quotaDroppedMessages: number;
+}
+
+export interface RawMakerAssetAmount {
+    assetData: string;
+    amount: string;
+}
+
+export interface MakerAssetAmount {
+    assetData: string;
+    amount: BigNumber;
+}
+
+export interface RawMakerInfo {
+    makerAddress: string;
+    numOrders: number;
+    makerAssetAmounts: RawMakerAssetAmount[];
+    lastActivity: string;
+}
+
+export interface MakerInfo {
+    makerAddress: string;
+    numOrders: number;
+    makerAssetAmounts: MakerAssetAmount[];
+    lastActivity: number;
+}
+
+export interface RawMakerEpoch {
+    makerAddress: string;
+    senderAddress: string;
+    orderEpoch: string;
+}
+
+export interface MakerEpoch {
+    makerAddress: string;
+    senderAddress: string;
+    orderEpoch: BigNumber;
+}
+
+export interface RawBlockLog {
+    address: string;
+    topics: string[];
+    data: string;
+    blockNumber: string;
+    transactionHash: string;
+    transactionIndex: string;
+    blockHash: string;
+    logIndex: string;
+    removed: boolean;
+}
+
+/**
+ * A log emitted in a block that was relevant to Mesh (e.g. a Fill or Transfer event).
+ */
+export interface BlockLog {
+    address: string;
+    topics: string[];
+    data: string;
+    blockNumber: number;
+    transactionHash: string;
+    transactionIndex: number;
+    blockHash: string;
+    logIndex: number;
+    removed: boolean;
+}
+
+export interface RawBlock {
+    number: number;
+    hash: string;
+    parentHash: string;
+    timestamp: string;
+    logs: RawBlockLog[];
+}
+
+/**
+ * A block that was processed by the Mesh node, along with the logs in the block that were relevant to Mesh.
+ */
+export interface Block {
+    number: number;
+    hash: string;
+    parentHash: string;
+    timestamp: number; // unix timestamp (seconds)
+    logs: BlockLog[];
+}
+
+export interface RawMarketInfo {
+    baseAssetData: string;
+    quoteAssetData: string;
+    numOrders: number;
+    numBids: number;
+    numAsks: number;
+    bestBid: string | null;
+    bestAsk: string | null;
+    midPrice: string | null;
+    spread: string | null;
+    bidSize: string;
+    askSize: string;
+}
+
+/**
+ * Aggregate information about the open orders for a single trading pair. Orders which sell the base asset are asks and
+ * orders which sell the quote asset are bids. Prices are the amount of the quote asset per unit of the base asset and
+ * sizes are amounts of the base asset, all in base units. Prices are null if there are no orders on the relevant side.
+ */
+export interface MarketInfo {
+    baseAssetData: string;
+    quoteAssetData: string;
+    numOrders: number;
+    numBids: number;
+    numAsks: number;
+    bestBid: BigNumber | null;
+    bestAsk: BigNumber | null;
+    midPrice: BigNumber | null;
+    spread: BigNumber | null;
+    bidSize: BigNumber;
+    askSize: BigNumber;
 }
@@ -7,24 +7,38 @@ import * as WebSocket from 'websocket';
 
 import {
     AcceptedOrderInfo,
+    Block,
     ContractEvent,
     ContractEventKind,
     ContractEventParameters,
     ERC1155ApprovalForAllEvent,
     ERC721ApprovalForAllEvent,
     ExchangeCancelEvent,
+    GetMakersOpts,
+    GetOrdersOpts,
     GetOrdersResponse,
     GetStatsResponse,
     HeartbeatEventPayload,
+    MakerEpoch,
+    MakerInfo,
+    MarketInfo,
     OrderEvent,
     OrderEventPayload,
+    OrderEventsNotification,
+    OrderEventsNotificationPayload,
     OrderInfo,
     RawAcceptedOrderInfo,
+    RawBlock,
     RawGetOrdersResponse,
+    RawMakerEpoch,
+    RawMakerInfo,
+    RawMarketInfo,
     RawOrderEvent,
     RawOrderInfo,
     RawValidationResults,
     RejectedOrderInfo,
+    ResyncResponse,
+    SoftCancel,
     StringifiedContractEvent,
     StringifiedERC1155TransferBatchEvent,
     StringifiedERC1155TransferSingleEvent,
@@ -36,6 +50,7 @@ import {
     StringifiedExchangeFillEvent,
     StringifiedWethDepositEvent,
     StringifiedWethWithdrawalEvent,
+    SubscribeToOrdersOpts,
     ValidationResults,
     WSOpts,
 } from './types';
@@ -72,6 +87,9 @@ export class WSClient {
                 fillableTakerAssetAmount: new BigNumber(rawAcceptedOrderInfo.fillableTakerAssetAmount),
                 isNew: rawAcceptedOrderInfo.isNew,
             };
+            if (rawAcceptedOrderInfo.trace !== undefined) {
+                acceptedOrderInfo.trace = rawAcceptedOrderInfo.trace;
+            }
             acceptedOrderInfos.push(acceptedOrderInfo);
         });
         return acceptedOrderInfos;
@@ -83,7 +101,23 @@ export class WSClient {
                 orderHash: rawOrderInfo.orderHash,
                 signedOrder: WSClient._convertOrderStringFieldsToBigNumber(rawOrderInfo.signedOrder),
                 fillableTakerAssetAmount: new BigNumber(rawOrderInfo.fillableTakerAssetAmount),
+                lastValidatedBlockNumber:
+                    rawOrderInfo.lastValidatedBlockNumber === null
+                        ? null
+                        : new BigNumber(rawOrderInfo.lastValidatedBlockNumber),
+                lastValidatedBlockHash: rawOrderInfo.lastValidatedBlockHash,
+                nextRevalidationTime: new Date(rawOrderInfo.nextRevalidationTime).getTime(),
+                staleness: rawOrderInfo.staleness,
             };
+            if (rawOrderInfo.fillabilityScore !== undefined) {
+                orderInfo.fillabilityScore = rawOrderInfo.fillabilityScore;
+            }
+            if (rawOrderInfo.annotations !== undefined) {
+                orderInfo.annotations = rawOrderInfo.annotations;
+            }
+            if (rawOrderInfo.signer !== undefined) {
+                orderInfo.signer = rawOrderInfo.signer;
+            }
             orderInfos.push(orderInfo);
         });
         return orderInfos;
@@ -108,6 +142,30 @@ export class WSClient {
             'salt',
         ]);
     }
+    private static _convertRawValidationResults(rawValidationResults: RawValidationResults): ValidationResults {
+        const validationResults: ValidationResults = {
+            accepted: WSClient._convertRawAcceptedOrderInfos(rawValidationResults.accepted),
+            rejected: [],
+        };
+        rawValidationResults.rejected.forEach(rawRejectedOrderInfo => {
+            const rejectedOrderInfo: RejectedOrderInfo = {
+                orderHash: rawRejectedOrderInfo.orderHash,
+                // signedOrder is null for orders which are not stored (see
+                // revalidateOrdersAsync).
+                signedOrder:
+                    rawRejectedOrderInfo.signedOrder === null
+                        ? rawRejectedOrderInfo.signedOrder
+                        : WSClient._convertOrderStringFieldsToBigNumber(rawRejectedOrderInfo.signedOrder),
+                kind: rawRejectedOrderInfo.kind,
+                status: rawRejectedOrderInfo.status,
+            };
+            if (rawRejectedOrderInfo.trace !== undefined) {
+                rejectedOrderInfo.trace = rawRejectedOrderInfo.trace;
+            }
+            validationResults.rejected.push(rejectedOrderInfo);
+        });
+        return validationResults;
+    }
     private static _convertRawGetOrdersResponse(rawGetOrdersResponse: RawGetOrdersResponse): GetOrdersResponse {
         return {
             snapshotID: rawGetOrdersResponse.snapshotID,
@@ -116,6 +174,46 @@ export class WSClient {
             ordersInfos: WSClient._convertRawOrderInfos(rawGetOrdersResponse.ordersInfos),
         };
     }
+    private static _convertRawMakerInfos(rawMakerInfos: RawMakerInfo[]): MakerInfo[] {
+        return rawMakerInfos.map(rawMakerInfo => ({
+            makerAddress: rawMakerInfo.makerAddress,
+            numOrders: rawMakerInfo.numOrders,
+            makerAssetAmounts: rawMakerInfo.makerAssetAmounts.map(rawMakerAssetAmount => ({
+                assetData: rawMakerAssetAmount.assetData,
+                amount: new BigNumber(rawMakerAssetAmount.amount),
+            })),
+            // tslint:disable-next-line:custom-no-magic-numbers
+            lastActivity: Math.round(new Date(rawMakerInfo.lastActivity).getTime() / 1000),
+        }));
+    }
+    private static _convertRawOrderEvents(rawOrderEvents: RawOrderEvent[]): OrderEvent[] {
+        return rawOrderEvents.map(rawOrderEvent => ({
+            timestampMs: new Date(rawOrderEvent.timestamp).getTime(),
+            orderHash: rawOrderEvent.orderHash,
+            signedOrder: WSClient._convertOrderStringFieldsToBigNumber(rawOrderEvent.signedOrder),
+            endState: rawOrderEvent.endState,
+            fillableTakerAssetAmount: new BigNumber(rawOrderEvent.fillableTakerAssetAmount),
+            contractEvents: WSClient._convertStringifiedContractEvents(rawOrderEvent.contractEvents),
+            sequenceNumber: rawOrderEvent.sequenceNumber,
+            epoch: rawOrderEvent.epoch,
+        }));
+    }
+    private static _convertRawMarketInfos(rawMarketInfos: RawMarketInfo[]): MarketInfo[] {
+        const toBigNumberOrNull = (value: string | null) => (value === null ? null : new BigNumber(value));
+        return rawMarketInfos.map(rawMarketInfo => ({
+            baseAssetData: rawMarketInfo.baseAssetData,
+            quoteAssetData: rawMarketInfo.quoteAssetData,
+            numOrders: rawMarketInfo.numOrders,
+            numBids: rawMarketInfo.numBids,
+            numAsks: rawMarketInfo.numAsks,
+            bestBid: toBigNumberOrNull(rawMarketInfo.bestBid),
+            bestAsk: toBigNumberOrNull(rawMarketInfo.bestAsk),
+            midPrice: toBigNumberOrNull(rawMarketInfo.midPrice),
+            spread: toBigNumberOrNull(rawMarketInfo.spread),
+            bidSize: new BigNumber(rawMarketInfo.bidSize),
+            askSize: new BigNumber(rawMarketInfo.askSize),
+        }));
+    }
     private static _convertStringifiedContractEvents(rawContractEvents: StringifiedContractEvent[]): ContractEvent[] {
         const contractEvents: ContractEvent[] = [];
         if (rawContractEvents === null) {
@@ -276,33 +374,194 @@ export class WSClient {
      * orders will not be affected by any DDoS prevention or incentive
      * mechanisms and will always stay in storage until they are no longer
      * fillable.
+     * @param trace        Whether or not to include a validation trace
+     * (timings and eth_call counts) with each result. Defaults to false.
      * @returns validation results
      */
-    public async addOrdersAsync(signedOrders: SignedOrder[], pinned: boolean = true): Promise<ValidationResults> {
+    public async addOrdersAsync(
+        signedOrders: SignedOrder[],
+        pinned: boolean = true,
+        trace: boolean = false,
+    ): Promise<ValidationResults> {
         assert.isArray('signedOrders', signedOrders);
         const rawValidationResults: RawValidationResults = await this._wsProvider.send('mesh_addOrders', [
             signedOrders,
-            { pinned },
+            { pinned, trace },
         ]);
-        const validationResults: ValidationResults = {
-            accepted: WSClient._convertRawAcceptedOrderInfos(rawValidationResults.accepted),
-            rejected: [],
-        };
-        rawValidationResults.rejected.forEach(rawRejectedOrderInfo => {
-            const rejectedOrderInfo: RejectedOrderInfo = {
-                orderHash: rawRejectedOrderInfo.orderHash,
-                signedOrder: WSClient._convertOrderStringFieldsToBigNumber(rawRejectedOrderInfo.signedOrder),
-                kind: rawRejectedOrderInfo.kind,
-                status: rawRejectedOrderInfo.status,
-            };
-            validationResults.rejected.push(rejectedOrderInfo);
-        });
-        return validationResults;
+        return WSClient._convertRawValidationResults(rawValidationResults);
+    }
+    /**
+     * Forces the Mesh node to immediately re-validate the stored orders with
+     * the given hashes instead of waiting for the next scheduled
+     * re-validation. This is useful after e.g. a maker has increased their
+     * allowance. Orders which are not stored by the Mesh node are rejected
+     * with the `OrderNotStored` status and a null signedOrder.
+     * @param orderHashes hashes of the orders to re-validate
+     * @returns validation results with updated fillableTakerAssetAmounts
+     */
+    public async revalidateOrdersAsync(orderHashes: string[]): Promise<ValidationResults> {
+        assert.isArray('orderHashes', orderHashes);
+        const rawValidationResults: RawValidationResults = await this._wsProvider.send('mesh_revalidateOrders', [
+            orderHashes,
+        ]);
+        return WSClient._convertRawValidationResults(rawValidationResults);
     }
     public async getStatsAsync(): Promise<GetStatsResponse> {
         const stats = await this._wsProvider.send('mesh_getStats', []);
         return stats;
     }
+    /**
+     * Get aggregate information about the open orders of each maker, such as the number of open orders and the total
+     * makerAssetAmount for each maker asset. Useful for monitoring the exposure of individual makers.
+     * @param opts Options for the request
+     * @returns the makers sorted by address, with lastActivity as a unix timestamp in seconds
+     */
+    public async getMakersAsync(opts: GetMakersOpts = {}): Promise<MakerInfo[]> {
+        const rawMakerInfos: RawMakerInfo[] = await this._wsProvider.send('mesh_getMakers', [opts]);
+        return WSClient._convertRawMakerInfos(rawMakerInfos);
+    }
+    /**
+     * Get the order epoch of a maker, which is set by calling cancelOrdersUpTo on the Exchange contract. All orders of
+     * the maker with the given sender address and a salt lower than the order epoch are cancelled.
+     * @param makerAddress the address of the maker
+     * @param senderAddress the sender address of the orders. Defaults to the null address.
+     * @returns the order epoch of the maker
+     */
+    public async getMakerEpochAsync(
+        makerAddress: string,
+        senderAddress: string = '0x0000000000000000000000000000000000000000',
+    ): Promise<MakerEpoch> {
+        assert.isETHAddressHex('makerAddress', makerAddress);
+        assert.isETHAddressHex('senderAddress', senderAddress);
+        const rawMakerEpoch: RawMakerEpoch = await this._wsProvider.send('mesh_getMakerEpoch', [
+            makerAddress,
+            senderAddress,
+        ]);
+        return {
+            makerAddress: rawMakerEpoch.makerAddress,
+            senderAddress: rawMakerEpoch.senderAddress,
+            orderEpoch: new BigNumber(rawMakerEpoch.orderEpoch),
+        };
+    }
+    /**
+     * Get the blocks in the given range which were processed by the Mesh node, along with the logs in each block that
+     * were relevant to Mesh. This can be used to correlate order events with the blocks in which they happened. It
+     * requires the Mesh node to be configured to keep a block history (`BLOCK_HISTORY_RETENTION`).
+     * @param fromBlock the number of the first block to return
+     * @param toBlock the number of the last block to return. At most 1000 blocks can be requested at once.
+     * @returns the stored blocks in the range in ascending order
+     */
+    public async getBlocksAsync(fromBlock: number, toBlock: number): Promise<Block[]> {
+        assert.isNumber('fromBlock', fromBlock);
+        assert.isNumber('toBlock', toBlock);
+        const rawBlocks: RawBlock[] = await this._wsProvider.send('mesh_getBlocks', [fromBlock, toBlock]);
+        return rawBlocks.map(rawBlock => ({
+            number: rawBlock.number,
+            hash: rawBlock.hash,
+            parentHash: rawBlock.parentHash,
+            timestamp: Math.round(new Date(rawBlock.timestamp).getTime() / 1000),
+            logs: rawBlock.logs.map(rawLog => ({
+                ...rawLog,
+                blockNumber: parseInt(rawLog.blockNumber, 16),
+                transactionIndex: parseInt(rawLog.transactionIndex, 16),
+                logIndex: parseInt(rawLog.logIndex, 16),
+            })),
+        }));
+    }
+    /**
+     * Get the recent order events with a sequence number greater than the given one. Order events have consecutive
+     * sequence numbers, so a gap in the sequence numbers received through `subscribeToOrdersAsync` means that some
+     * events were missed. This method can be used to recover them. Sequence numbers start over whenever the Mesh node
+     * is restarted, so they are only meaningful together with the `epoch` of the order event. It throws if the Mesh
+     * node was restarted since the given epoch or if some of the events are no longer retained, in which case the
+     * orders should be fetched again with `getOrdersAsync`.
+     * @param epoch the epoch of the last order event that was received
+     * @param sequenceNumber the sequence number of the last order event that was received
+     * @returns the order events after the given sequence number
+     */
+    public async getOrderEventsSinceAsync(epoch: string, sequenceNumber: number): Promise<OrderEvent[]> {
+        assert.isString('epoch', epoch);
+        assert.isNumber('sequenceNumber', sequenceNumber);
+        const rawOrderEvents: RawOrderEvent[] = await this._wsProvider.send('mesh_getOrderEventsSince', [
+            epoch,
+            sequenceNumber,
+        ]);
+        return WSClient._convertRawOrderEvents(rawOrderEvents);
+    }
+    /**
+     * Get the recent order events which were caused by the given transaction (e.g. a fill or a cancellation). Each
+     * order event includes the contract events that caused the order to change state, which can be used to trace why
+     * specific orders changed state. Only the most recent order events are retained by the Mesh node, so the result
+     * is empty if the transaction is too old or didn't affect any orders.
+     * @param txHash the hash of the transaction
+     * @returns the order events caused by the transaction
+     */
+    public async getOrdersAffectedByTransactionAsync(txHash: string): Promise<OrderEvent[]> {
+        assert.isHexString('txHash', txHash);
+        const rawOrderEvents: RawOrderEvent[] = await this._wsProvider.send('mesh_getOrdersAffectedByTransaction', [
+            txHash,
+        ]);
+        return WSClient._convertRawOrderEvents(rawOrderEvents);
+    }
+    /**
+     * Get aggregate information about the open orders for each trading pair, such as the best bid and ask, the spread
+     * and the total open size. Useful for showing a markets table without fetching every order.
+     * @returns the markets sorted by base asset data and then quote asset data
+     */
+    public async getMarketsAsync(): Promise<MarketInfo[]> {
+        const rawMarketInfos: RawMarketInfo[] = await this._wsProvider.send('mesh_getMarkets', []);
+        return WSClient._convertRawMarketInfos(rawMarketInfos);
+    }
+    /**
+     * Changes the logging verbosity of the Mesh node without restarting it.
+     * @param verbosity logging verbosity: 0=panic, 1=fatal, 2=error, 3=warn, 4=info, 5=debug, 6=trace
+     * @param debugSubsystems subsystems to enable debug logging for regardless of the verbosity. Valid subsystems
+     * are "p2p", "blockwatch", "ordersync" and "orderwatch".
+     */
+    public async setLogLevelAsync(verbosity: number, debugSubsystems: string[] = []): Promise<void> {
+        assert.isNumber('verbosity', verbosity);
+        assert.isArray('debugSubsystems', debugSubsystems);
+        await this._wsProvider.send('mesh_setLogLevel', [verbosity, debugSubsystems]);
+    }
+    /**
+     * Recovers a stuck Mesh node without restarting it. The node drops its stored block headers, starts again from
+     * the latest block, re-validates all orders and then receives orders via ordersync from the given peers (or from
+     * randomly selected neighbors if no peers are given). This can take longer than the default RPC request timeout.
+     * @param peerIDs IDs of the peers to receive orders from via ordersync
+     * @returns the block the node started again from and the peers for which ordersync succeeded or failed
+     */
+    public async resyncAsync(peerIDs: string[] = []): Promise<ResyncResponse> {
+        assert.isArray('peerIDs', peerIDs);
+        const resyncResponse: ResyncResponse = await this._wsProvider.send('mesh_resync', [peerIDs]);
+        return resyncResponse;
+    }
+    /**
+     * Asks the Mesh node and its peers to stop sharing an order without cancelling it on-chain. Soft cancellations
+     * are only advisory, since anyone who already has the order can still fill it. The node must be started with
+     * ENABLE_SOFT_CANCELS=true.
+     * @param softCancel the hash of the order and a signature of keccak256("0x-mesh-soft-cancel:" || orderHash) by
+     * its maker
+     */
+    public async softCancelOrderAsync(softCancel: SoftCancel): Promise<void> {
+        assert.isHexString('orderHash', softCancel.orderHash);
+        assert.isETHAddressHex('makerAddress', softCancel.makerAddress);
+        assert.isHexString('signature', softCancel.signature);
+        await this._wsProvider.send('mesh_softCancelOrder', [softCancel]);
+    }
+    /**
+     * Stops the Mesh node from accepting new orders from the network (via GossipSub and ordersync) until
+     * resumeIngestionAsync is called. The node still serves queries, accepts orders via addOrdersAsync and tracks
+     * the state of the chain. This is useful during maintenance, migrations or incident response.
+     */
+    public async pauseIngestionAsync(): Promise<void> {
+        await this._wsProvider.send('mesh_pauseIngestion', []);
+    }
+    /**
+     * Resumes accepting new orders from the network after pauseIngestionAsync was called.
+     */
+    public async resumeIngestionAsync(): Promise<void> {
+        await this._wsProvider.send('mesh_resumeIngestion', []);
+    }
     /**
      * Get all 0x signed orders currently stored in the Mesh node
      * @param perPage number of signedOrders to fetch per paginated request
@@ -337,12 +596,15 @@ export class WSClient {
      * @param page Page index at which to retrieve orders
      * @param perPage number of signedOrders to fetch per paginated request
      * @param snapshotID The DB snapshot at which to fetch orders. If omitted, a new snapshot is created
+     * @param opts Options for the request (e.g. to exclude stale orders). Excluded orders still count towards
+     * perPage, so a page may contain fewer orders even if it is not the last page.
      * @returns the snapshotID, snapshotTimestamp and all orders, their hashes and fillableTakerAssetAmounts
      */
     public async getOrdersForPageAsync(
         page: number,
         perPage: number = 200,
         snapshotID?: string,
+        opts: GetOrdersOpts = {},
     ): Promise<GetOrdersResponse> {
         const finalSnapshotID = snapshotID === undefined ? '' : snapshotID;
 
@@ -350,6 +612,7 @@ export class WSClient {
             page,
             perPage,
             finalSnapshotID,
+            opts,
         ]);
         const getOrdersResponse = WSClient._convertRawGetOrdersResponse(rawGetOrdersResponse);
         return getOrdersResponse;
@@ -358,34 +621,73 @@ export class WSClient {
      * Subscribe to the 'orders' topic and receive order events from Mesh. This method returns a
      * subscriptionId that can be used to `unsubscribe()` from this subscription.
      * @param   cb   callback function where you'd like to get notified about order events
+     * @param   opts options for the subscription (e.g. to coalesce successive fill updates)
      * @return subscriptionId
      */
-    public async subscribeToOrdersAsync(cb: (orderEvents: OrderEvent[]) => void): Promise<string> {
+    public async subscribeToOrdersAsync(
+        cb: (orderEvents: OrderEvent[]) => void,
+        opts?: SubscribeToOrdersOpts,
+    ): Promise<string> {
         assert.isFunction('cb', cb);
-        const orderEventsSubscriptionId = await this._wsProvider.subscribe('mesh_subscribe', 'orders', []);
+        const params = opts === undefined ? [] : [opts];
+        const orderEventsSubscriptionId = await this._wsProvider.subscribe('mesh_subscribe', 'orders', params);
         const id = uuid();
         this._subscriptionIdToMeshSpecificId[id] = orderEventsSubscriptionId;
 
         const orderEventsCallback = (eventPayload: OrderEventPayload) => {
             this._subscriptionIdToMeshSpecificId[id] = eventPayload.subscription;
             const rawOrderEvents: RawOrderEvent[] = eventPayload.result;
-            const orderEvents: OrderEvent[] = [];
-            rawOrderEvents.forEach(rawOrderEvent => {
-                const orderEvent = {
-                    timestampMs: new Date(rawOrderEvent.timestamp).getTime(),
-                    orderHash: rawOrderEvent.orderHash,
-                    signedOrder: WSClient._convertOrderStringFieldsToBigNumber(rawOrderEvent.signedOrder),
-                    endState: rawOrderEvent.endState,
-                    fillableTakerAssetAmount: new BigNumber(rawOrderEvent.fillableTakerAssetAmount),
-                    contractEvents: WSClient._convertStringifiedContractEvents(rawOrderEvent.contractEvents),
-                };
-                orderEvents.push(orderEvent);
-            });
+            const orderEvents = WSClient._convertRawOrderEvents(rawOrderEvents);
             cb(orderEvents);
         };
         this._wsProvider.on(orderEventsSubscriptionId, orderEventsCallback as any);
         return id;
     }
+    /**
+     * Subscribe to the 'orders' topic in ack mode. Each notification has to be acknowledged with
+     * `ackOrderEventsAsync` before the Mesh node sends the next one, and order events are buffered on the Mesh node
+     * in the meantime. If the buffer overflows, a notification with type 'overflow' is sent and the subscriber
+     * should resync its orders. This method returns a subscriptionId that can be used to `unsubscribe()` from this
+     * subscription.
+     * @param   cb   callback function where you'd like to get notified about order events
+     * @param   opts options for the subscription (e.g. to filter order events). ackMode is set automatically.
+     * @return subscriptionId
+     */
+    public async subscribeToOrdersWithAcksAsync(
+        cb: (notification: OrderEventsNotification) => void,
+        opts: SubscribeToOrdersOpts = {},
+    ): Promise<string> {
+        assert.isFunction('cb', cb);
+        const orderEventsSubscriptionId = await this._wsProvider.subscribe('mesh_subscribe', 'orders', [
+            { ...opts, ackMode: true },
+        ]);
+        const id = uuid();
+        this._subscriptionIdToMeshSpecificId[id] = orderEventsSubscriptionId;
+
+        const notificationCallback = (eventPayload: OrderEventsNotificationPayload) => {
+            this._subscriptionIdToMeshSpecificId[id] = eventPayload.subscription;
+            const rawNotification = eventPayload.result;
+            cb({
+                type: rawNotification.type,
+                subscriptionId: rawNotification.subscriptionId,
+                ackId: rawNotification.ackId,
+                orderEvents: WSClient._convertRawOrderEvents(rawNotification.orderEvents),
+                droppedOrderEvents: rawNotification.droppedOrderEvents || 0,
+            });
+        };
+        this._wsProvider.on(orderEventsSubscriptionId, notificationCallback as any);
+        return id;
+    }
+    /**
+     * Acknowledges a notification which was received by a subscription in ack mode, so that the Mesh node sends
+     * the next one.
+     * @param notification the notification to acknowledge
+     */
+    public async ackOrderEventsAsync(notification: OrderEventsNotification): Promise<void> {
+        assert.isString('subscriptionId', notification.subscriptionId);
+        assert.isNumber('ackId', notification.ackId);
+        await this._wsProvider.send('mesh_ackOrderEvents', [notification.subscriptionId, notification.ackId]);
+    }
     /**
      * Unsubscribe from a subscription
      * @param subscriptionId identifier of the subscription to cancel
@@ -133,7 +133,21 @@ blockchainTests.resets('WSClient', env => {
                 // the block number of the stats in this test a priori.
                 expect(stats.latestBlock).to.not.be.undefined();
                 expect(stats.latestBlock.number).to.be.greaterThan(0);
+                expect(stats.storageUsedBytes).to.be.at.least(0);
+                expect(new Date(stats.startTime).getTime()).to.be.at.most(Date.now());
+                expect(stats.uptimeSeconds).to.be.at.least(0);
+                expect(stats.goVersion).to.match(/^go/);
+                expect(stats.enabledFeatures).to.be.an('array');
+                expect(stats.configHash).to.have.lengthOf(64);
                 stats.version = '';
+                stats.storageUsedBytes = 0;
+                stats.startTime = '';
+                stats.uptimeSeconds = 0;
+                stats.gitCommit = '';
+                stats.buildDate = '';
+                stats.goVersion = '';
+                stats.enabledFeatures = [];
+                stats.configHash = '';
                 stats.latestBlock = {
                     number: 0,
                     hash: '',
@@ -158,10 +172,51 @@ blockchainTests.resets('WSClient', env => {
                     numOrders: 0,
                     numOrdersIncludingRemoved: 0,
                     numPinnedOrders: 0,
+                    numColdOrders: 0,
                     maxExpirationTime: constants.MAX_UINT256.toString(),
                     startOfCurrentUTCDay: expectedStartOfCurrentUTCDay,
                     ethRPCRequestsSentInCurrentUTCDay: 0,
                     ethRPCRateLimitExpiredRequests: 0,
+                    ethRPCMaxRequestsPer24HrUTC: 200000,
+                    ethRPCRemainingRequestsInCurrentUTCDay: 200000,
+                    ethRPCBudgetExhaustionETASeconds: 0,
+                    ethRPCCacheHits: 0,
+                    ethRPCCacheMisses: 0,
+                    ethRPCCacheEntries: 0,
+                    storageUsedBytes: 0,
+                    maxOrders: 100000,
+                    currentOrders: 0,
+                    evictedOrdersLast24h: 0,
+                    storageUtilizationPercent: 0,
+                    inboundQueueLength: 0,
+                    inboundQueueDroppedMessages: 0,
+                    validationMemoryBytes: 0,
+                    validationMemoryShedOrders: 0,
+                    dedupedOrderSubmissions: 0,
+                    mismatchedDomainOrders: 0,
+                    droppedOrderEvents: 0,
+                    ingestionPaused: false,
+                    orderSyncBytesSaved: 0,
+                    slowDBQueries: 0,
+                    dbFragmentationPercent: 0,
+                    dbReclaimedBytes: 0,
+                    bootstrapDialFailures: 0,
+                    unhealthyBootstrapPeers: 0,
+                    startTime: '',
+                    uptimeSeconds: 0,
+                    gitCommit: '',
+                    buildDate: '',
+                    goVersion: '',
+                    enabledFeatures: [],
+                    configHash: '',
+                    topics: [
+                        {
+                            topic: '/0x-orders/version/3/chain/1337/schema/e30=',
+                            numOrders: 0,
+                            maxOrders: 0,
+                            quotaDroppedMessages: 0,
+                        },
+                    ],
                 };
                 expect(stats).to.be.deep.eq(expectedStats);
             });
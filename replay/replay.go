@@ -0,0 +1,270 @@
+// Package replay records the inputs to the order pipeline (pubsub messages
+// and block events) to disk so that they can later be replayed into a fresh
+// Mesh node. Records are replayed one at a time in the order in which they
+// were recorded, which makes it possible to deterministically reproduce bugs
+// that depend on the interleaving of messages and block events.
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/0xProject/0x-mesh/p2p"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// RecordKind is the kind of input stored in a Record.
+type RecordKind string
+
+// RecordKind values
+const (
+	KindMessages    = RecordKind("messages")
+	KindBlockEvents = RecordKind("blockEvents")
+)
+
+// ErrRecorderClosed is returned when attempting to record after the Recorder
+// has been closed.
+var ErrRecorderClosed = errors.New("recorder is closed")
+
+// Record is a single batch of inputs to the order pipeline. Records are stored
+// as newline-delimited JSON.
+type Record struct {
+	// Sequence is the position of the record in the recording, starting at 0.
+	Sequence uint64 `json:"sequence"`
+	// Timestamp is the time at which the record was recorded.
+	Timestamp time.Time `json:"timestamp"`
+	// Kind indicates which of the following fields is set.
+	Kind        RecordKind          `json:"kind"`
+	Messages    []*p2p.Message      `json:"messages,omitempty"`
+	BlockEvents []*blockwatch.Event `json:"blockEvents,omitempty"`
+}
+
+// recordedMessage is the JSON encoding of a p2p.Message. peer.ID can't encode
+// or decode an empty ID (e.g. the Signer of an unsigned message), so peer IDs
+// are stored as base58 strings which are empty for empty IDs.
+type recordedMessage struct {
+	From         string
+	ReceivedFrom string `json:",omitempty"`
+	Signer       string `json:",omitempty"`
+	Data         []byte
+	Topic        string
+}
+
+// recordJSON has the same fields as Record except for the encoding of
+// messages.
+type recordJSON struct {
+	Sequence    uint64              `json:"sequence"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Kind        RecordKind          `json:"kind"`
+	Messages    []*recordedMessage  `json:"messages,omitempty"`
+	BlockEvents []*blockwatch.Event `json:"blockEvents,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r *Record) MarshalJSON() ([]byte, error) {
+	var messages []*recordedMessage
+	for _, message := range r.Messages {
+		messages = append(messages, &recordedMessage{
+			From:         encodePeerID(message.From),
+			ReceivedFrom: encodePeerID(message.ReceivedFrom),
+			Signer:       encodePeerID(message.Signer),
+			Data:         message.Data,
+			Topic:        message.Topic,
+		})
+	}
+	return json.Marshal(recordJSON{
+		Sequence:    r.Sequence,
+		Timestamp:   r.Timestamp,
+		Kind:        r.Kind,
+		Messages:    messages,
+		BlockEvents: r.BlockEvents,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var raw recordJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var messages []*p2p.Message
+	for _, rawMessage := range raw.Messages {
+		from, err := decodePeerID(rawMessage.From)
+		if err != nil {
+			return err
+		}
+		receivedFrom, err := decodePeerID(rawMessage.ReceivedFrom)
+		if err != nil {
+			return err
+		}
+		signer, err := decodePeerID(rawMessage.Signer)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, &p2p.Message{
+			From:         from,
+			ReceivedFrom: receivedFrom,
+			Signer:       signer,
+			Data:         rawMessage.Data,
+			Topic:        rawMessage.Topic,
+		})
+	}
+	*r = Record{
+		Sequence:    raw.Sequence,
+		Timestamp:   raw.Timestamp,
+		Kind:        raw.Kind,
+		Messages:    messages,
+		BlockEvents: raw.BlockEvents,
+	}
+	return nil
+}
+
+func encodePeerID(peerID peer.ID) string {
+	if peerID == "" {
+		return ""
+	}
+	return peer.IDB58Encode(peerID)
+}
+
+func decodePeerID(encoded string) (peer.ID, error) {
+	if encoded == "" {
+		return "", nil
+	}
+	return peer.IDB58Decode(encoded)
+}
+
+// Recorder writes records to a file. It is safe for concurrent use.
+type Recorder struct {
+	mu       sync.Mutex
+	file     *os.File
+	writer   *bufio.Writer
+	encoder  *json.Encoder
+	sequence uint64
+	closed   bool
+}
+
+// NewRecorder creates a new Recorder which writes to the file at the given
+// path. If the file already exists, it is truncated.
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	return &Recorder{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}, nil
+}
+
+// RecordMessages records a batch of messages received via pubsub.
+func (r *Recorder) RecordMessages(messages []*p2p.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	return r.record(&Record{
+		Kind:     KindMessages,
+		Messages: messages,
+	})
+}
+
+// RecordBlockEvents records a batch of events emitted by the block watcher.
+func (r *Recorder) RecordBlockEvents(events []*blockwatch.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.record(&Record{
+		Kind:        KindBlockEvents,
+		BlockEvents: events,
+	})
+}
+
+func (r *Recorder) record(record *Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrRecorderClosed
+	}
+	record.Sequence = r.sequence
+	record.Timestamp = time.Now().UTC()
+	if err := r.encoder.Encode(record); err != nil {
+		return err
+	}
+	r.sequence++
+	// Flush after every record so that the recording is still useful if the
+	// node crashes.
+	return r.writer.Flush()
+}
+
+// Close flushes any buffered records and closes the underlying file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.writer.Flush(); err != nil {
+		_ = r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Handler handles replayed records.
+type Handler interface {
+	HandleMessages(ctx context.Context, messages []*p2p.Message) error
+	HandleBlockEvents(ctx context.Context, events []*blockwatch.Event) error
+}
+
+// Replay reads the recording at the given path and passes each record to
+// handler in order. Each record is fully handled before the next one is read.
+// It returns the number of records that were replayed.
+func Replay(ctx context.Context, path string, handler Handler) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	numReplayed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return numReplayed, ctx.Err()
+		default:
+		}
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			if err == io.EOF {
+				return numReplayed, nil
+			}
+			return numReplayed, fmt.Errorf("could not decode record %d: %s", numReplayed, err.Error())
+		}
+		if record.Sequence != uint64(numReplayed) {
+			return numReplayed, fmt.Errorf("unexpected record sequence: expected %d but got %d", numReplayed, record.Sequence)
+		}
+		switch record.Kind {
+		case KindMessages:
+			err = handler.HandleMessages(ctx, record.Messages)
+		case KindBlockEvents:
+			err = handler.HandleBlockEvents(ctx, record.BlockEvents)
+		default:
+			err = fmt.Errorf("unknown record kind: %q", record.Kind)
+		}
+		if err != nil {
+			return numReplayed, err
+		}
+		numReplayed++
+	}
+}
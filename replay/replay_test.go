@@ -0,0 +1,111 @@
+package replay
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/ethereum/go-ethereum/common"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testHandler records the order in which it receives inputs.
+type testHandler struct {
+	received []interface{}
+	err      error
+}
+
+func (h *testHandler) HandleMessages(ctx context.Context, messages []*p2p.Message) error {
+	h.received = append(h.received, messages)
+	return h.err
+}
+
+func (h *testHandler) HandleBlockEvents(ctx context.Context, events []*blockwatch.Event) error {
+	h.received = append(h.received, events)
+	return h.err
+}
+
+func newTestPeerID(t *testing.T) peer.ID {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	require.NoError(t, err)
+	return peerID
+}
+
+func newTestRecording(t *testing.T) (string, []interface{}, func()) {
+	dir, err := ioutil.TempDir("", "replay_test")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "recording.jsonl")
+
+	peer1 := newTestPeerID(t)
+	peer2 := newTestPeerID(t)
+	peer3 := newTestPeerID(t)
+	firstMessages := []*p2p.Message{
+		{From: peer1, ReceivedFrom: peer1, Signer: peer1, Data: []byte("order1"), Topic: "topic1"},
+		{From: peer2, ReceivedFrom: peer1, Signer: peer2, Data: []byte("order2"), Topic: "topic2"},
+	}
+	blockEvents := []*blockwatch.Event{
+		{
+			Type: blockwatch.Added,
+			BlockHeader: &miniheader.MiniHeader{
+				Hash:      common.HexToHash("0x1"),
+				Parent:    common.HexToHash("0x0"),
+				Number:    big.NewInt(1),
+				Timestamp: time.Unix(1000, 0).UTC(),
+			},
+		},
+	}
+	secondMessages := []*p2p.Message{
+		// Unsigned messages don't have a signer.
+		{From: peer3, ReceivedFrom: peer3, Data: []byte("order3"), Topic: "topic1"},
+	}
+
+	recorder, err := NewRecorder(path)
+	require.NoError(t, err)
+	require.NoError(t, recorder.RecordMessages(firstMessages))
+	require.NoError(t, recorder.RecordBlockEvents(blockEvents))
+	// Empty batches should not be recorded.
+	require.NoError(t, recorder.RecordMessages(nil))
+	require.NoError(t, recorder.RecordMessages(secondMessages))
+	require.NoError(t, recorder.Close())
+	assert.Equal(t, ErrRecorderClosed, recorder.RecordMessages(secondMessages))
+
+	expected := []interface{}{firstMessages, blockEvents, secondMessages}
+	return path, expected, func() { os.RemoveAll(dir) }
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path, expected, cleanup := newTestRecording(t)
+	defer cleanup()
+
+	handler := &testHandler{}
+	numReplayed, err := Replay(context.Background(), path, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 3, numReplayed)
+	assert.Equal(t, expected, handler.received)
+}
+
+func TestReplayStopsOnHandlerError(t *testing.T) {
+	path, _, cleanup := newTestRecording(t)
+	defer cleanup()
+
+	handlerErr := errors.New("handler error")
+	handler := &testHandler{err: handlerErr}
+	numReplayed, err := Replay(context.Background(), path, handler)
+	assert.Equal(t, handlerErr, err)
+	assert.Equal(t, 0, numReplayed)
+	assert.Len(t, handler.received, 1)
+}
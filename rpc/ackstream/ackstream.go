@@ -0,0 +1,209 @@
+// +build !js
+
+// Package ackstream delivers order events to subscribers which acknowledge
+// each notification before they receive the next one (see
+// types.SubscribeToOrdersOpts.AckMode). Order events which are generated while
+// a subscriber hasn't acknowledged the previous notification yet are buffered
+// on disk up to a limit. If the limit is reached, the buffered order events
+// are dropped and the subscriber receives an overflow notification instead,
+// so that slow subscribers neither lose order events silently nor cause the
+// memory usage of the Mesh node to grow without bound.
+package ackstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxOrderEventsPerNotification is the number of order events after which
+	// no more buffered batches are added to a notification.
+	maxOrderEventsPerNotification = 1000
+	// ackBufferSize is the number of acknowledgements which can be waiting to
+	// be handled by a stream. Additional acknowledgements are dropped.
+	ackBufferSize = 16
+)
+
+// ErrUnknownSubscription is returned by Registry.Ack if there is no
+// subscription in ack mode with the given ID.
+var ErrUnknownSubscription = errors.New("unknown subscription ID (the subscription may have been closed or was not created with ackMode)")
+
+// NotifyFunc sends the given notification to the subscriber. It returns false
+// if the subscription should be closed.
+type NotifyFunc func(notification *types.OrderEventsNotification) bool
+
+// Registry keeps track of the subscriptions in ack mode so that
+// acknowledgements can be routed to them. It is safe for concurrent use.
+type Registry struct {
+	dir            string
+	maxBufferBytes int64
+	mu             sync.Mutex
+	streams        map[string]*Stream
+}
+
+// NewRegistry creates a registry for streams which buffer up to
+// maxBufferBytes bytes of encoded order events each in temporary files in dir.
+func NewRegistry(dir string, maxBufferBytes int64) *Registry {
+	return &Registry{
+		dir:            dir,
+		maxBufferBytes: maxBufferBytes,
+		streams:        map[string]*Stream{},
+	}
+}
+
+// Stream delivers order events to a single subscriber in ack mode.
+type Stream struct {
+	registry       *Registry
+	subscriptionID string
+	opts           types.SubscribeToOrdersOpts
+	notify         NotifyFunc
+	acks           chan uint64
+	spool          *spool
+}
+
+// NewStream creates a stream for the subscription with the given ID. Order
+// events which don't match the filters in opts are not sent. The stream
+// doesn't send anything until Run is called.
+func (r *Registry) NewStream(subscriptionID string, opts types.SubscribeToOrdersOpts, notify NotifyFunc) (*Stream, error) {
+	spool, err := newSpool(r.dir, r.maxBufferBytes)
+	if err != nil {
+		return nil, err
+	}
+	stream := &Stream{
+		registry:       r,
+		subscriptionID: subscriptionID,
+		opts:           opts,
+		notify:         notify,
+		acks:           make(chan uint64, ackBufferSize),
+		spool:          spool,
+	}
+	r.mu.Lock()
+	r.streams[subscriptionID] = stream
+	r.mu.Unlock()
+	return stream, nil
+}
+
+// Ack acknowledges the notification with the given ackID which was sent to the
+// subscription with the given ID. Acknowledgements for notifications other
+// than the latest one are ignored.
+func (r *Registry) Ack(subscriptionID string, ackID uint64) error {
+	r.mu.Lock()
+	stream, found := r.streams[subscriptionID]
+	r.mu.Unlock()
+	if !found {
+		return ErrUnknownSubscription
+	}
+	select {
+	case stream.acks <- ackID:
+	default:
+		// The stream is busy handling earlier acknowledgements. Since only the
+		// acknowledgement for the latest notification matters, and it can be
+		// repeated, dropping it is safe.
+	}
+	return nil
+}
+
+// Run sends the order events received from orderEvents to the subscriber until
+// ctx is canceled or notify returns false. Afterwards, the stream is removed
+// from its registry and its buffer is deleted.
+func (s *Stream) Run(ctx context.Context, orderEvents <-chan []*zeroex.OrderEvent) {
+	defer func() {
+		s.registry.mu.Lock()
+		delete(s.registry.streams, s.subscriptionID)
+		s.registry.mu.Unlock()
+		if err := s.spool.close(); err != nil {
+			log.WithError(err).Warn("could not remove order event buffer of subscription")
+		}
+	}()
+
+	var lastAckID uint64
+	// awaitingAck is true if the latest notification hasn't been acknowledged
+	// yet.
+	awaitingAck := false
+	// droppedOrderEvents is the number of order events which were dropped since
+	// the last overflow notification was sent.
+	droppedOrderEvents := 0
+	send := func(notification *types.OrderEventsNotification) bool {
+		lastAckID++
+		notification.SubscriptionID = s.subscriptionID
+		notification.AckID = lastAckID
+		awaitingAck = true
+		return s.notify(notification)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case newOrderEvents := <-orderEvents:
+			newOrderEvents = s.opts.FilterOrderEvents(newOrderEvents)
+			if len(newOrderEvents) == 0 {
+				continue
+			}
+			if !awaitingAck && s.spool.len() == 0 && droppedOrderEvents == 0 {
+				if !send(&types.OrderEventsNotification{
+					Type:        types.OrderEventsNotificationType,
+					OrderEvents: newOrderEvents,
+				}) {
+					return
+				}
+				continue
+			}
+			ok, err := s.spool.push(newOrderEvents)
+			if err != nil {
+				log.WithError(err).Error("could not buffer order events for subscription")
+				return
+			}
+			if !ok {
+				// The subscriber will need to resync anyway, so we drop all of
+				// the buffered order events instead of just the new ones. This
+				// frees up the buffer for the order events after the overflow.
+				droppedOrderEvents += s.spool.len() + len(newOrderEvents)
+				if err := s.spool.reset(); err != nil {
+					log.WithError(err).Error("could not reset order event buffer of subscription")
+					return
+				}
+				log.WithFields(log.Fields{
+					"subscriptionID":     s.subscriptionID,
+					"droppedOrderEvents": droppedOrderEvents,
+				}).Warn("dropped order events because subscriber did not acknowledge them quickly enough")
+			}
+		case ackID := <-s.acks:
+			if !awaitingAck || ackID != lastAckID {
+				continue
+			}
+			awaitingAck = false
+			if droppedOrderEvents > 0 {
+				notification := &types.OrderEventsNotification{
+					Type:               types.OverflowNotificationType,
+					OrderEvents:        []*zeroex.OrderEvent{},
+					DroppedOrderEvents: droppedOrderEvents,
+				}
+				droppedOrderEvents = 0
+				if !send(notification) {
+					return
+				}
+				continue
+			}
+			bufferedOrderEvents, err := s.spool.pop(maxOrderEventsPerNotification)
+			if err != nil {
+				log.WithError(err).Error("could not read buffered order events for subscription")
+				return
+			}
+			if len(bufferedOrderEvents) == 0 {
+				continue
+			}
+			if !send(&types.OrderEventsNotification{
+				Type:        types.OrderEventsNotificationType,
+				OrderEvents: bufferedOrderEvents,
+			}) {
+				return
+			}
+		}
+	}
+}
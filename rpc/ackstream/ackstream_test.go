@@ -0,0 +1,193 @@
+// +build !js
+
+package ackstream
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOrderEvents(firstSequenceNumber uint64, count int) []*zeroex.OrderEvent {
+	orderEvents := make([]*zeroex.OrderEvent, count)
+	for i := range orderEvents {
+		orderEvents[i] = &zeroex.OrderEvent{
+			OrderHash:                common.BigToHash(big.NewInt(int64(i))),
+			EndState:                 zeroex.ESOrderAdded,
+			FillableTakerAssetAmount: big.NewInt(1),
+			ContractEvents:           []*zeroex.ContractEvent{},
+			SequenceNumber:           firstSequenceNumber + uint64(i),
+		}
+	}
+	return orderEvents
+}
+
+func newTempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "ackstream_test")
+	require.NoError(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func sequenceNumbers(orderEvents []*zeroex.OrderEvent) []uint64 {
+	numbers := make([]uint64, len(orderEvents))
+	for i, orderEvent := range orderEvents {
+		numbers[i] = orderEvent.SequenceNumber
+	}
+	return numbers
+}
+
+func TestSpool(t *testing.T) {
+	dir, removeDir := newTempDir(t)
+	defer removeDir()
+	spool, err := newSpool(dir, 1024*1024)
+	require.NoError(t, err)
+	defer spool.close()
+
+	for i := 0; i < 3; i++ {
+		ok, err := spool.push(newOrderEvents(uint64(i*2+1), 2))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	assert.Equal(t, 6, spool.len())
+
+	// Whole batches are popped until the maximum is reached.
+	orderEvents, err := spool.pop(3)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 4}, sequenceNumbers(orderEvents))
+	assert.Equal(t, 2, spool.len())
+
+	// Compaction must not lose or reorder any batches.
+	require.NoError(t, spool.compact())
+	ok, err := spool.push(newOrderEvents(7, 1))
+	require.NoError(t, err)
+	require.True(t, ok)
+	orderEvents, err = spool.pop(10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{5, 6, 7}, sequenceNumbers(orderEvents))
+	assert.Equal(t, 0, spool.len())
+	info, err := spool.file.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size(), "file should be truncated once the spool is drained")
+}
+
+func TestSpoolMaxBytes(t *testing.T) {
+	dir, removeDir := newTempDir(t)
+	defer removeDir()
+	spool, err := newSpool(dir, 1024)
+	require.NoError(t, err)
+	defer spool.close()
+
+	numPushed := 0
+	for {
+		ok, err := spool.push(newOrderEvents(uint64(numPushed+1), 1))
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		numPushed++
+	}
+	require.True(t, numPushed > 1)
+	assert.Equal(t, numPushed, spool.len())
+
+	// Once a batch has been popped, there is room for another one.
+	_, err = spool.pop(1)
+	require.NoError(t, err)
+	ok, err := spool.push(newOrderEvents(uint64(numPushed+1), 1))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	info, err := spool.file.Stat()
+	require.NoError(t, err)
+	assert.True(t, info.Size() <= 1024)
+}
+
+func TestStreamWaitsForAcks(t *testing.T) {
+	dir, removeDir := newTempDir(t)
+	defer removeDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := NewRegistry(dir, 1024*1024)
+	notifications := make(chan *types.OrderEventsNotification, 10)
+	stream, err := registry.NewStream("sub", types.SubscribeToOrdersOpts{AckMode: true}, func(notification *types.OrderEventsNotification) bool {
+		notifications <- notification
+		return true
+	})
+	require.NoError(t, err)
+	orderEvents := make(chan []*zeroex.OrderEvent)
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx, orderEvents)
+		close(done)
+	}()
+
+	orderEvents <- newOrderEvents(1, 1)
+	first := <-notifications
+	assert.Equal(t, types.OrderEventsNotificationType, first.Type)
+	assert.Equal(t, "sub", first.SubscriptionID)
+	assert.Equal(t, []uint64{1}, sequenceNumbers(first.OrderEvents))
+
+	// Order events are buffered until the first notification is acknowledged.
+	orderEvents <- newOrderEvents(2, 1)
+	orderEvents <- newOrderEvents(3, 1)
+	select {
+	case <-notifications:
+		t.Fatal("received notification before acknowledging the previous one")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Acknowledgements for other notifications are ignored.
+	require.NoError(t, registry.Ack("sub", first.AckID+1))
+	require.NoError(t, registry.Ack("sub", first.AckID))
+	second := <-notifications
+	assert.Equal(t, []uint64{2, 3}, sequenceNumbers(second.OrderEvents))
+	assert.NotEqual(t, first.AckID, second.AckID)
+
+	assert.Equal(t, ErrUnknownSubscription, registry.Ack("other", 1))
+	cancel()
+	<-done
+	assert.Equal(t, ErrUnknownSubscription, registry.Ack("sub", second.AckID), "stream should be removed once it stops")
+}
+
+func TestStreamOverflow(t *testing.T) {
+	dir, removeDir := newTempDir(t)
+	defer removeDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := NewRegistry(dir, 1024)
+	notifications := make(chan *types.OrderEventsNotification, 10)
+	stream, err := registry.NewStream("sub", types.SubscribeToOrdersOpts{AckMode: true}, func(notification *types.OrderEventsNotification) bool {
+		notifications <- notification
+		return true
+	})
+	require.NoError(t, err)
+	orderEvents := make(chan []*zeroex.OrderEvent)
+	go stream.Run(ctx, orderEvents)
+
+	orderEvents <- newOrderEvents(1, 1)
+	first := <-notifications
+	// A single batch which is larger than the buffer overflows it.
+	orderEvents <- newOrderEvents(2, 20)
+	orderEvents <- newOrderEvents(22, 1)
+
+	require.NoError(t, registry.Ack("sub", first.AckID))
+	overflow := <-notifications
+	assert.Equal(t, types.OverflowNotificationType, overflow.Type)
+	assert.Equal(t, 20, overflow.DroppedOrderEvents)
+	assert.Empty(t, overflow.OrderEvents)
+
+	// Order events after the overflow are sent once the overflow notification
+	// is acknowledged.
+	require.NoError(t, registry.Ack("sub", overflow.AckID))
+	next := <-notifications
+	assert.Equal(t, types.OrderEventsNotificationType, next.Type)
+	assert.Equal(t, []uint64{22}, sequenceNumbers(next.OrderEvents))
+}
@@ -0,0 +1,159 @@
+// +build !js
+
+package ackstream
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// spool is a FIFO queue of batches of order events which is stored in a
+// temporary file. Each batch is stored as a single line of JSON. The file is
+// truncated whenever the spool is drained, and compacted when a new batch
+// would otherwise exceed the size limit, so it never grows much larger than
+// the order events it currently holds.
+type spool struct {
+	file     *os.File
+	maxBytes int64
+	// readOffset and writeOffset are the offsets in file at which the next
+	// batch is read and written respectively.
+	readOffset  int64
+	writeOffset int64
+	// numOrderEvents is the number of order events in the spool.
+	numOrderEvents int
+	// batchSizes contains the number of order events in each batch in the
+	// spool, oldest first.
+	batchSizes []int
+}
+
+// newSpool creates a spool which is backed by a new temporary file in dir and
+// holds up to maxBytes bytes of encoded order events. If dir is empty, the
+// default directory for temporary files is used.
+func newSpool(dir string, maxBytes int64) (*spool, error) {
+	file, err := ioutil.TempFile(dir, "mesh-subscription-")
+	if err != nil {
+		return nil, err
+	}
+	return &spool{
+		file:     file,
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// push appends the given order events to the spool. It returns false without
+// modifying the spool if they don't fit.
+func (s *spool) push(orderEvents []*zeroex.OrderEvent) (bool, error) {
+	encoded, err := json.Marshal(orderEvents)
+	if err != nil {
+		return false, err
+	}
+	encoded = append(encoded, '\n')
+	size := int64(len(encoded))
+	if s.writeOffset-s.readOffset+size > s.maxBytes {
+		return false, nil
+	}
+	if s.writeOffset+size > s.maxBytes {
+		if err := s.compact(); err != nil {
+			return false, err
+		}
+	}
+	if _, err := s.file.WriteAt(encoded, s.writeOffset); err != nil {
+		return false, err
+	}
+	s.writeOffset += size
+	s.numOrderEvents += len(orderEvents)
+	s.batchSizes = append(s.batchSizes, len(orderEvents))
+	return true, nil
+}
+
+// pop removes batches from the front of the spool until at least maxOrderEvents
+// order events have been removed or the spool is empty, and returns their
+// order events. It returns nil if the spool is empty.
+func (s *spool) pop(maxOrderEvents int) ([]*zeroex.OrderEvent, error) {
+	if s.numOrderEvents == 0 {
+		return nil, nil
+	}
+	reader := bufio.NewReader(io.NewSectionReader(s.file, s.readOffset, s.writeOffset-s.readOffset))
+	orderEvents := []*zeroex.OrderEvent{}
+	for len(s.batchSizes) > 0 && len(orderEvents) < maxOrderEvents {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+		var batch []*zeroex.OrderEvent
+		if err := json.Unmarshal(line, &batch); err != nil {
+			return nil, err
+		}
+		orderEvents = append(orderEvents, batch...)
+		s.readOffset += int64(len(line))
+		s.numOrderEvents -= s.batchSizes[0]
+		s.batchSizes = s.batchSizes[1:]
+	}
+	if len(s.batchSizes) == 0 {
+		if err := s.reset(); err != nil {
+			return nil, err
+		}
+	}
+	return orderEvents, nil
+}
+
+// len returns the number of order events in the spool.
+func (s *spool) len() int {
+	return s.numOrderEvents
+}
+
+// reset removes all order events from the spool.
+func (s *spool) reset() error {
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	s.readOffset = 0
+	s.writeOffset = 0
+	s.numOrderEvents = 0
+	s.batchSizes = nil
+	return nil
+}
+
+// compact moves the batches which haven't been read yet to the beginning of
+// the file so that the space used by batches which have been read is reused.
+func (s *spool) compact() error {
+	if s.readOffset == 0 {
+		return nil
+	}
+	// Note: The destination never overtakes the source since it starts at
+	// offset 0 and both advance by the same number of bytes.
+	size := s.writeOffset - s.readOffset
+	buf := make([]byte, 32*1024)
+	for copied := int64(0); copied < size; {
+		n, err := s.file.ReadAt(buf, s.readOffset+copied)
+		if n == 0 && err != nil {
+			return err
+		}
+		if int64(n) > size-copied {
+			n = int(size - copied)
+		}
+		if _, err := s.file.WriteAt(buf[:n], copied); err != nil {
+			return err
+		}
+		copied += int64(n)
+	}
+	if err := s.file.Truncate(size); err != nil {
+		return err
+	}
+	s.readOffset = 0
+	s.writeOffset = size
+	return nil
+}
+
+// close closes and removes the file which backs the spool.
+func (s *spool) close() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(s.file.Name())
+}
@@ -7,8 +7,10 @@ import (
 	"errors"
 
 	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/core/softcancel"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
@@ -43,6 +45,7 @@ func (c *Client) AddOrders(orders []*zeroex.SignedOrder, opts ...types.AddOrders
 		if err := c.rpcClient.Call(&validationResults, "mesh_addOrders", orders, opts[0]); err != nil {
 			return nil, err
 		}
+		return &validationResults, nil
 	}
 	if err := c.rpcClient.Call(&validationResults, "mesh_addOrders", orders); err != nil {
 		return nil, err
@@ -51,14 +54,34 @@ func (c *Client) AddOrders(orders []*zeroex.SignedOrder, opts ...types.AddOrders
 }
 
 // GetOrders gets all orders stored on the Mesh node at a particular point in time in a paginated fashion
-func (c *Client) GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error) {
+func (c *Client) GetOrders(page, perPage int, snapshotID string, opts ...types.GetOrdersOpts) (*types.GetOrdersResponse, error) {
 	var getOrdersResponse types.GetOrdersResponse
+	if len(opts) > 1 {
+		return nil, errors.New("invalid number of get orders opts")
+	}
+	if len(opts) == 1 {
+		if err := c.rpcClient.Call(&getOrdersResponse, "mesh_getOrders", page, perPage, snapshotID, opts[0]); err != nil {
+			return nil, err
+		}
+		return &getOrdersResponse, nil
+	}
 	if err := c.rpcClient.Call(&getOrdersResponse, "mesh_getOrders", page, perPage, snapshotID); err != nil {
 		return nil, err
 	}
 	return &getOrdersResponse, nil
 }
 
+// RevalidateOrders forces the 0x Mesh node to immediately re-validate the
+// stored orders with the given hashes and returns the updated validation
+// results.
+func (c *Client) RevalidateOrders(orderHashes []common.Hash) (*ordervalidator.ValidationResults, error) {
+	var validationResults ordervalidator.ValidationResults
+	if err := c.rpcClient.Call(&validationResults, "mesh_revalidateOrders", orderHashes); err != nil {
+		return nil, err
+	}
+	return &validationResults, nil
+}
+
 // AddPeer adds the peer to the node's list of peers. The node will attempt to
 // connect to this new peer and return an error if it cannot.
 func (c *Client) AddPeer(peerInfo peerstore.PeerInfo) error {
@@ -82,6 +105,123 @@ func (c *Client) GetStats() (*types.Stats, error) {
 	return getStatsResponse, nil
 }
 
+// GetMakers retrieves aggregate information about the open orders of each
+// maker. If opts.MakerAddresses is empty, it includes every maker with at
+// least one open order.
+func (c *Client) GetMakers(opts types.GetMakersOpts) ([]*types.MakerInfo, error) {
+	var makerInfos []*types.MakerInfo
+	if err := c.rpcClient.Call(&makerInfos, "mesh_getMakers", opts); err != nil {
+		return nil, err
+	}
+	return makerInfos, nil
+}
+
+// GetMakerEpoch retrieves the order epoch of the given maker and sender
+// address. All orders of the maker with that sender address and a salt lower
+// than the epoch have been cancelled with cancelOrdersUpTo.
+func (c *Client) GetMakerEpoch(makerAddress, senderAddress common.Address) (*types.MakerEpoch, error) {
+	var makerEpoch *types.MakerEpoch
+	if err := c.rpcClient.Call(&makerEpoch, "mesh_getMakerEpoch", makerAddress, senderAddress); err != nil {
+		return nil, err
+	}
+	return makerEpoch, nil
+}
+
+// GetBlocks retrieves the blocks with a block number in the range [fromBlock,
+// toBlock] which were processed by the Mesh node, along with the logs in each
+// block that were relevant to Mesh. It requires the node to be configured to
+// keep a block history (BLOCK_HISTORY_RETENTION).
+func (c *Client) GetBlocks(fromBlock, toBlock int) ([]*types.Block, error) {
+	var blocks []*types.Block
+	if err := c.rpcClient.Call(&blocks, "mesh_getBlocks", fromBlock, toBlock); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// GetOrderEventsSince retrieves the recent order events with a sequence number
+// greater than sequenceNumber, which must belong to the given epoch (see
+// zeroex.OrderEvent). It can be used to recover events that were missed after
+// a subscription to orders was interrupted. If the Mesh node was restarted
+// since the epoch or some of the events are no longer available, it returns an
+// error and the client should resync with GetOrders instead.
+func (c *Client) GetOrderEventsSince(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error) {
+	var orderEvents []*zeroex.OrderEvent
+	if err := c.rpcClient.Call(&orderEvents, "mesh_getOrderEventsSince", epoch, sequenceNumber); err != nil {
+		return nil, err
+	}
+	return orderEvents, nil
+}
+
+// GetOrdersAffectedByTransaction retrieves the recent order events which were
+// caused by the transaction with the given hash. Each order event includes the
+// contract events that caused the order to change state. The result is empty if
+// the transaction didn't affect any orders or if the order events are no longer
+// retained.
+func (c *Client) GetOrdersAffectedByTransaction(txHash common.Hash) ([]*zeroex.OrderEvent, error) {
+	var orderEvents []*zeroex.OrderEvent
+	if err := c.rpcClient.Call(&orderEvents, "mesh_getOrdersAffectedByTransaction", txHash); err != nil {
+		return nil, err
+	}
+	return orderEvents, nil
+}
+
+// GetMarkets retrieves aggregate information about the open orders for each
+// trading pair, such as the best bid and ask and the total open size.
+func (c *Client) GetMarkets() ([]*types.MarketInfo, error) {
+	var marketInfos []*types.MarketInfo
+	if err := c.rpcClient.Call(&marketInfos, "mesh_getMarkets"); err != nil {
+		return nil, err
+	}
+	return marketInfos, nil
+}
+
+// SetLogLevel changes the logging verbosity of the Mesh node without
+// restarting it and enables debug logging for the given subsystems (e.g.
+// "p2p", "blockwatch" or "ordersync") regardless of the verbosity.
+func (c *Client) SetLogLevel(verbosity int, debugSubsystems []string) error {
+	if debugSubsystems == nil {
+		debugSubsystems = []string{}
+	}
+	return c.rpcClient.Call(nil, "mesh_setLogLevel", verbosity, debugSubsystems)
+}
+
+// Resync recovers a stuck Mesh node without restarting it. The node drops its
+// stored block headers, starts again from the latest block, re-validates all
+// orders and then receives orders via ordersync from the given peers (or from
+// randomly selected neighbors if no peers are given).
+func (c *Client) Resync(peerIDs []peer.ID) (*types.ResyncResponse, error) {
+	peerIDStrings := make([]string, len(peerIDs))
+	for i, peerID := range peerIDs {
+		peerIDStrings[i] = peer.IDB58Encode(peerID)
+	}
+	var resyncResponse types.ResyncResponse
+	if err := c.rpcClient.Call(&resyncResponse, "mesh_resync", peerIDStrings); err != nil {
+		return nil, err
+	}
+	return &resyncResponse, nil
+}
+
+// SoftCancelOrder asks the Mesh node to stop sharing an order and to share the
+// given soft cancellation with its peers. The soft cancellation must be signed
+// by the maker of the order (see softcancel.Sign).
+func (c *Client) SoftCancelOrder(softCancel *softcancel.SoftCancel) error {
+	return c.rpcClient.Call(nil, "mesh_softCancelOrder", softCancel)
+}
+
+// PauseIngestion stops the Mesh node from accepting new orders from the
+// network until ResumeIngestion is called. The node still serves queries,
+// accepts orders via AddOrders and tracks the state of the chain.
+func (c *Client) PauseIngestion() error {
+	return c.rpcClient.Call(nil, "mesh_pauseIngestion")
+}
+
+// ResumeIngestion resumes accepting new orders from the network after
+// PauseIngestion was called.
+func (c *Client) ResumeIngestion() error {
+	return c.rpcClient.Call(nil, "mesh_resumeIngestion")
+}
+
 // SubscribeToOrders subscribes a stream of order events
 // Note copied from `go-ethereum` codebase: Slow subscribers will be dropped eventually. Client
 // buffers up to 8000 notifications before considering the subscriber dead. The subscription Err
@@ -91,6 +231,29 @@ func (c *Client) SubscribeToOrders(ctx context.Context, ch chan<- []*zeroex.Orde
 	return c.rpcClient.Subscribe(ctx, "mesh", ch, "orders")
 }
 
+// SubscribeToOrdersWithOpts is like SubscribeToOrders but accepts options
+// which control how order events are delivered (e.g. coalescing fill updates).
+func (c *Client) SubscribeToOrdersWithOpts(ctx context.Context, ch chan<- []*zeroex.OrderEvent, opts types.SubscribeToOrdersOpts) (*rpc.ClientSubscription, error) {
+	return c.rpcClient.Subscribe(ctx, "mesh", ch, "orders", opts)
+}
+
+// SubscribeToOrdersWithAcks subscribes to a stream of order events in ack
+// mode (opts.AckMode is set automatically). The Mesh node sends the next
+// notification only after the previous one was acknowledged with
+// AckOrderEvents, and buffers order events on disk in the meantime. If the
+// buffer overflows, the subscriber receives a notification with type
+// types.OverflowNotificationType and should resync its orders.
+func (c *Client) SubscribeToOrdersWithAcks(ctx context.Context, ch chan<- *types.OrderEventsNotification, opts types.SubscribeToOrdersOpts) (*rpc.ClientSubscription, error) {
+	opts.AckMode = true
+	return c.rpcClient.Subscribe(ctx, "mesh", ch, "orders", opts)
+}
+
+// AckOrderEvents acknowledges the notification with the given ackID which was
+// received by the subscription in ack mode with the given ID.
+func (c *Client) AckOrderEvents(subscriptionID string, ackID uint64) error {
+	return c.rpcClient.Call(nil, "mesh_ackOrderEvents", subscriptionID, ackID)
+}
+
 // SubscribeToHeartbeat subscribes a stream of heartbeats in order to have certainty that the WS
 // connection is still alive.
 // Note copied from `go-ethereum` codebase: Slow subscribers will be dropped eventually. Client
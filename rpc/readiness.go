@@ -0,0 +1,38 @@
+// +build !js
+
+package rpc
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// NotReadyError is the error message returned for requests which are
+	// rejected because Mesh is not ready to serve them yet.
+	NotReadyError = "NOT_READY"
+	// IgnoreReadinessHeader is an HTTP header which can be set to "true" to
+	// make requests (or open WebSocket connections) while Mesh is not ready,
+	// e.g. to check its stats while it is starting up.
+	IgnoreReadinessHeader = "X-Mesh-Ignore-Readiness"
+)
+
+// notReadyResponse is the JSON-RPC 2.0 error response sent for requests which
+// are rejected because Mesh is not ready. -32000 is the generic server error
+// code.
+var notReadyResponse = []byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32000,"message":"` + NotReadyError + `"}}` + "\n")
+
+// readinessHandler wraps the given handler and rejects requests with status
+// 503 and a NOT_READY error while isReady returns false. This keeps load
+// balancers from routing traffic to a node which has not synced any orders yet.
+func readinessHandler(handler http.Handler, isReady func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ignore, _ := strconv.ParseBool(r.Header.Get(IgnoreReadinessHeader)); ignore || isReady() {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write(notReadyResponse)
+	})
+}
@@ -23,6 +23,9 @@ type Server struct {
 	rpcHandler   RPCHandler
 	listener     net.Listener
 	rpcServer    *rpc.Server
+	// isReady, if non-nil, is used to reject requests until Mesh is ready to
+	// serve them.
+	isReady func() bool
 }
 
 // NewServer creates and returns a new server which will listen for new
@@ -35,6 +38,15 @@ func NewServer(addr string, rpcHandler RPCHandler) (*Server, error) {
 	}, nil
 }
 
+// SetReadinessCheck causes the server to reject requests with status 503 and a
+// NOT_READY error while isReady returns false, unless the IgnoreReadinessHeader
+// header is set. It must be called before Listen.
+func (s *Server) SetReadinessCheck(isReady func() bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.isReady = isReady
+}
+
 // HandlerType represents the type of handler to attach to the server
 type HandlerType uint8
 
@@ -83,6 +95,9 @@ func (s *Server) Listen(ctx context.Context, handlerType HandlerType) error {
 	default:
 		return fmt.Errorf("Unrecognized HandlerType: %d", handlerType)
 	}
+	if s.isReady != nil {
+		handler = readinessHandler(handler, s.isReady)
+	}
 
 	if err := http.Serve(s.listener, handler); err != nil {
 		// HACK(albrow): http.Serve doesn't accept a context. This means that
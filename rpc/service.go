@@ -11,7 +11,10 @@ import (
 
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/core/softcancel"
+	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	peer "github.com/libp2p/go-libp2p-core/peer"
@@ -33,18 +36,54 @@ type RPCHandler interface {
 	// AddOrders is called when the client sends an AddOrders request.
 	AddOrders(signedOrdersRaw []*json.RawMessage, opts types.AddOrdersOpts) (*ordervalidator.ValidationResults, error)
 	// GetOrders is called when the clients sends a GetOrders request
-	GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error)
+	GetOrders(page, perPage int, snapshotID string, opts types.GetOrdersOpts) (*types.GetOrdersResponse, error)
+	// RevalidateOrders is called when the client sends a RevalidateOrders request.
+	RevalidateOrders(orderHashes []common.Hash) (*ordervalidator.ValidationResults, error)
 	// AddPeer is called when the client sends an AddPeer request.
 	AddPeer(peerInfo peerstore.PeerInfo) error
 	// GetStats is called when the client sends an GetStats request.
 	GetStats() (*types.Stats, error)
+	// GetMakers is called when the client sends a GetMakers request.
+	GetMakers(opts types.GetMakersOpts) ([]*types.MakerInfo, error)
+	// GetMakerEpoch is called when the client sends a GetMakerEpoch request.
+	GetMakerEpoch(makerAddress, senderAddress common.Address) (*types.MakerEpoch, error)
+	// GetBlocks is called when the client sends a GetBlocks request.
+	GetBlocks(fromBlock, toBlock int) ([]*types.Block, error)
+	// GetOrderEventsSince is called when the client sends a GetOrderEventsSince
+	// request.
+	GetOrderEventsSince(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error)
+	// GetOrdersAffectedByTransaction is called when the client sends a
+	// GetOrdersAffectedByTransaction request.
+	GetOrdersAffectedByTransaction(txHash common.Hash) ([]*zeroex.OrderEvent, error)
+	// GetMarkets is called when the client sends a GetMarkets request.
+	GetMarkets() ([]*types.MarketInfo, error)
+	// SetLogLevel is called when the client sends a SetLogLevel request.
+	SetLogLevel(verbosity int, debugSubsystems []string) error
+	// Resync is called when the client sends a Resync request.
+	Resync(peerIDs []peer.ID) (*types.ResyncResponse, error)
+	// SoftCancelOrder is called when the client sends a SoftCancelOrder
+	// request.
+	SoftCancelOrder(softCancel *softcancel.SoftCancel) error
+	// AckOrderEvents is called when the client sends an AckOrderEvents
+	// request.
+	AckOrderEvents(subscriptionID string, ackID uint64) error
+	// PauseIngestion is called when the client sends a PauseIngestion
+	// request.
+	PauseIngestion() error
+	// ResumeIngestion is called when the client sends a ResumeIngestion
+	// request.
+	ResumeIngestion() error
 	// SubscribeToOrders is called when a client sends a Subscribe to `orders` request
-	SubscribeToOrders(ctx context.Context) (*rpc.Subscription, error)
+	SubscribeToOrders(ctx context.Context, opts types.SubscribeToOrdersOpts) (*rpc.Subscription, error)
 }
 
 // Orders calls rpcHandler.SubscribeToOrders and returns the rpc subscription.
-func (s *rpcService) Orders(ctx context.Context) (*rpc.Subscription, error) {
-	return s.rpcHandler.SubscribeToOrders(ctx)
+// opts is optional.
+func (s *rpcService) Orders(ctx context.Context, opts *types.SubscribeToOrdersOpts) (*rpc.Subscription, error) {
+	if opts == nil {
+		opts = &types.SubscribeToOrdersOpts{}
+	}
+	return s.rpcHandler.SubscribeToOrders(ctx, *opts)
 }
 
 // Heartbeat calls rpcHandler.SubscribeToHeartbeat and returns the rpc subscription.
@@ -136,8 +175,18 @@ func (s *rpcService) AddOrders(signedOrdersRaw []*json.RawMessage, opts *types.A
 }
 
 // GetOrders calls rpcHandler.GetOrders and returns the validation results.
-func (s *rpcService) GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error) {
-	return s.rpcHandler.GetOrders(page, perPage, snapshotID)
+// opts is optional.
+func (s *rpcService) GetOrders(page, perPage int, snapshotID string, opts *types.GetOrdersOpts) (*types.GetOrdersResponse, error) {
+	if opts == nil {
+		opts = &types.GetOrdersOpts{}
+	}
+	return s.rpcHandler.GetOrders(page, perPage, snapshotID, *opts)
+}
+
+// RevalidateOrders calls rpcHandler.RevalidateOrders and returns the validation
+// results.
+func (s *rpcService) RevalidateOrders(orderHashes []common.Hash) (*ordervalidator.ValidationResults, error) {
+	return s.rpcHandler.RevalidateOrders(orderHashes)
 }
 
 // AddPeer builds PeerInfo out of the given peer ID and multiaddresses and
@@ -170,3 +219,86 @@ func (s *rpcService) AddPeer(peerID string, multiaddrs []string) error {
 func (s *rpcService) GetStats() (*types.Stats, error) {
 	return s.rpcHandler.GetStats()
 }
+
+// GetMakers calls rpcHandler.GetMakers. opts is optional.
+func (s *rpcService) GetMakers(opts *types.GetMakersOpts) ([]*types.MakerInfo, error) {
+	if opts == nil {
+		opts = &types.GetMakersOpts{}
+	}
+	return s.rpcHandler.GetMakers(*opts)
+}
+
+// GetMakerEpoch calls rpcHandler.GetMakerEpoch. senderAddress is optional and
+// defaults to the null address, which is the sender address of most orders.
+func (s *rpcService) GetMakerEpoch(makerAddress common.Address, senderAddress *common.Address) (*types.MakerEpoch, error) {
+	if senderAddress == nil {
+		senderAddress = &common.Address{}
+	}
+	return s.rpcHandler.GetMakerEpoch(makerAddress, *senderAddress)
+}
+
+// GetBlocks calls rpcHandler.GetBlocks.
+func (s *rpcService) GetBlocks(fromBlock, toBlock int) ([]*types.Block, error) {
+	return s.rpcHandler.GetBlocks(fromBlock, toBlock)
+}
+
+// GetOrderEventsSince calls rpcHandler.GetOrderEventsSince.
+func (s *rpcService) GetOrderEventsSince(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error) {
+	return s.rpcHandler.GetOrderEventsSince(epoch, sequenceNumber)
+}
+
+// GetOrdersAffectedByTransaction calls rpcHandler.GetOrdersAffectedByTransaction.
+func (s *rpcService) GetOrdersAffectedByTransaction(txHash common.Hash) ([]*zeroex.OrderEvent, error) {
+	return s.rpcHandler.GetOrdersAffectedByTransaction(txHash)
+}
+
+// GetMarkets calls rpcHandler.GetMarkets. If there is an error, it returns it.
+func (s *rpcService) GetMarkets() ([]*types.MarketInfo, error) {
+	return s.rpcHandler.GetMarkets()
+}
+
+// SetLogLevel calls rpcHandler.SetLogLevel. If there is an error, it returns
+// it.
+func (s *rpcService) SetLogLevel(verbosity int, debugSubsystems []string) error {
+	return s.rpcHandler.SetLogLevel(verbosity, debugSubsystems)
+}
+
+// Resync parses the given peer IDs and calls rpcHandler.Resync. peerIDs is
+// optional. If there is an error, it returns it.
+func (s *rpcService) Resync(peerIDs *[]string) (*types.ResyncResponse, error) {
+	parsedPeerIDs := []peer.ID{}
+	if peerIDs != nil {
+		for _, peerID := range *peerIDs {
+			parsedPeerID, err := peer.IDB58Decode(peerID)
+			if err != nil {
+				return nil, err
+			}
+			parsedPeerIDs = append(parsedPeerIDs, parsedPeerID)
+		}
+	}
+	return s.rpcHandler.Resync(parsedPeerIDs)
+}
+
+// SoftCancelOrder calls rpcHandler.SoftCancelOrder. If there is an error, it
+// returns it.
+func (s *rpcService) SoftCancelOrder(softCancel softcancel.SoftCancel) error {
+	return s.rpcHandler.SoftCancelOrder(&softCancel)
+}
+
+// AckOrderEvents calls rpcHandler.AckOrderEvents. If there is an error, it
+// returns it.
+func (s *rpcService) AckOrderEvents(subscriptionID string, ackID uint64) error {
+	return s.rpcHandler.AckOrderEvents(subscriptionID, ackID)
+}
+
+// PauseIngestion calls rpcHandler.PauseIngestion. If there is an error, it
+// returns it.
+func (s *rpcService) PauseIngestion() error {
+	return s.rpcHandler.PauseIngestion()
+}
+
+// ResumeIngestion calls rpcHandler.ResumeIngestion. If there is an error, it
+// returns it.
+func (s *rpcService) ResumeIngestion() error {
+	return s.rpcHandler.ResumeIngestion()
+}
@@ -0,0 +1,211 @@
+// +build !js
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/orderwatch"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// maxResubscribeBackoff is the maximum amount of time to wait between attempts
+// to resubscribe after the connection to the Mesh node was lost.
+const maxResubscribeBackoff = 30 * time.Second
+
+// ErrOrderEventsEpochChanged is sent to the channel returned by
+// OrderEventsSubscription.Missed if the Mesh node was restarted while the
+// subscription was disconnected. The sequence numbers of the order events
+// received before the restart belong to a previous epoch, so the order events
+// emitted in between can't be recovered.
+var ErrOrderEventsEpochChanged = errors.New("the Mesh node was restarted and the missed order events can't be recovered")
+
+// OrderEventsSubscription is a subscription to order events which is
+// automatically re-established whenever the connection to the Mesh node is
+// lost. It is created with SubscribeToOrdersWithReconnect.
+type OrderEventsSubscription struct {
+	client *Client
+	ch     chan<- []*zeroex.OrderEvent
+	opts   types.SubscribeToOrdersOpts
+	sub    event.Subscription
+	// missed receives an error whenever order events could not be recovered
+	// after resubscribing.
+	missed chan error
+	// mu protects lastEpoch and lastSequenceNumber.
+	mu sync.Mutex
+	// lastEpoch and lastSequenceNumber are the epoch and sequence number of
+	// the last order event that was sent to ch, or empty if no order events
+	// have been sent since the last time order events were missed.
+	lastEpoch          string
+	lastSequenceNumber uint64
+}
+
+// SubscribeToOrdersWithReconnect is like SubscribeToOrdersWithOpts, but instead
+// of failing when the connection to the Mesh node is lost it reconnects and
+// resubscribes with exponential backoff. After resubscribing, the order events
+// that were emitted while disconnected are recovered with GetOrderEventsSince
+// and sent to ch before any new order events, so ch receives every order event
+// without gaps or duplicates. If the missed order events can't be recovered, an
+// error is sent to the channel returned by Missed and the client should resync
+// with GetOrders. The error is ErrOrderEventsEpochChanged if the Mesh node was
+// restarted.
+//
+// Unlike SubscribeToOrders, this function doesn't return an error if the
+// initial subscription fails. It keeps trying until Unsubscribe is called.
+func (c *Client) SubscribeToOrdersWithReconnect(ch chan<- []*zeroex.OrderEvent, opts types.SubscribeToOrdersOpts) *OrderEventsSubscription {
+	s := &OrderEventsSubscription{
+		client: c,
+		ch:     ch,
+		opts:   opts,
+		missed: make(chan error, 1),
+	}
+	s.sub = event.Resubscribe(maxResubscribeBackoff, s.subscribe)
+	return s
+}
+
+// Unsubscribe stops sending order events to the channel and closes the
+// channel returned by Err.
+func (s *OrderEventsSubscription) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
+// Err returns a channel which is closed when Unsubscribe is called. Errors
+// caused by lost connections are handled by resubscribing, so no errors are
+// sent to this channel.
+func (s *OrderEventsSubscription) Err() <-chan error {
+	return s.sub.Err()
+}
+
+// Missed returns a channel which receives an error whenever some order events
+// could not be recovered after resubscribing. It is ErrOrderEventsEpochChanged
+// if the Mesh node was restarted. The channel has a buffer of 1, so an error is
+// not lost if nobody is receiving from it at the time.
+func (s *OrderEventsSubscription) Missed() <-chan error {
+	return s.missed
+}
+
+// subscribe subscribes to order events with a new underlying subscription.
+// It is called by event.Resubscribe whenever the previous subscription
+// failed.
+func (s *OrderEventsSubscription) subscribe(ctx context.Context) (event.Subscription, error) {
+	orderEventsChan := make(chan []*zeroex.OrderEvent, 100)
+	clientSubscription, err := s.client.SubscribeToOrdersWithOpts(ctx, orderEventsChan, s.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recover the order events that were emitted since the last one we
+	// received. This happens after subscribing so that no order events are
+	// missed in between. Order events which are received twice are
+	// filtered out by sequence number in forward.
+	if lastEpoch, lastSequenceNumber := s.getLastSequenceNumber(); lastSequenceNumber != 0 {
+		missedOrderEvents, err := s.client.GetOrderEventsSince(lastEpoch, lastSequenceNumber)
+		if err != nil {
+			// Errors are returned by the Mesh node as plain messages.
+			if err.Error() == orderwatch.ErrOrderEventsEpochMismatch.Error() {
+				err = ErrOrderEventsEpochChanged
+			}
+			s.notifyMissed(err)
+		} else if !s.forward(ctx.Done(), s.opts.FilterOrderEvents(missedOrderEvents)) {
+			clientSubscription.Unsubscribe()
+			return nil, ctx.Err()
+		}
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer clientSubscription.Unsubscribe()
+		for {
+			select {
+			case <-quit:
+				return nil
+			case err := <-clientSubscription.Err():
+				return err
+			case orderEvents := <-orderEventsChan:
+				if !s.forward(quit, orderEvents) {
+					return nil
+				}
+			}
+		}
+	}), nil
+}
+
+// forward sends the order events which haven't been sent yet to the
+// subscriber's channel. It returns false if quit was closed first.
+//
+// Note: Coalesced order events take the sequence number of the latest event
+// they represent, so the sequence numbers within a single batch are not
+// necessarily in order. They are always greater than those of any previous
+// batch though, so order events are compared to the last sequence number of
+// the previous batch.
+//
+// Sequence numbers from different epochs can't be compared. If an order event
+// has a different epoch than the previous one, the Mesh node was restarted, so
+// ErrOrderEventsEpochChanged is sent to the missed channel and the sequence
+// numbers of the new epoch are tracked from then on.
+func (s *OrderEventsSubscription) forward(quit <-chan struct{}, orderEvents []*zeroex.OrderEvent) bool {
+	s.mu.Lock()
+	previousEpoch := s.lastEpoch
+	previousSequenceNumber := s.lastSequenceNumber
+	epochChanged := false
+	newOrderEvents := make([]*zeroex.OrderEvent, 0, len(orderEvents))
+	for _, orderEvent := range orderEvents {
+		if previousEpoch != "" && orderEvent.Epoch != previousEpoch {
+			epochChanged = true
+			previousEpoch = orderEvent.Epoch
+			previousSequenceNumber = 0
+			s.lastSequenceNumber = 0
+		}
+		if orderEvent.SequenceNumber <= previousSequenceNumber {
+			continue
+		}
+		newOrderEvents = append(newOrderEvents, orderEvent)
+		if orderEvent.SequenceNumber > s.lastSequenceNumber {
+			s.lastEpoch = orderEvent.Epoch
+			s.lastSequenceNumber = orderEvent.SequenceNumber
+		}
+	}
+	s.mu.Unlock()
+	if epochChanged {
+		s.sendMissed(ErrOrderEventsEpochChanged)
+	}
+	if len(newOrderEvents) == 0 {
+		return true
+	}
+	select {
+	case <-quit:
+		return false
+	case s.ch <- newOrderEvents:
+		return true
+	}
+}
+
+func (s *OrderEventsSubscription) getLastSequenceNumber() (string, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEpoch, s.lastSequenceNumber
+}
+
+// notifyMissed resets the last epoch and sequence number, since the missed
+// order events can't be recovered, and notifies the subscriber that order
+// events were missed.
+func (s *OrderEventsSubscription) notifyMissed(err error) {
+	s.mu.Lock()
+	s.lastEpoch = ""
+	s.lastSequenceNumber = 0
+	s.mu.Unlock()
+	s.sendMissed(err)
+}
+
+// sendMissed sends err to the missed channel unless it already holds an
+// error which hasn't been received yet.
+func (s *OrderEventsSubscription) sendMissed(err error) {
+	select {
+	case s.missed <- err:
+	default:
+	}
+}
@@ -0,0 +1,68 @@
+// +build !js
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sequenceNumbers(orderEvents []*zeroex.OrderEvent) []uint64 {
+	numbers := make([]uint64, len(orderEvents))
+	for i, orderEvent := range orderEvents {
+		numbers[i] = orderEvent.SequenceNumber
+	}
+	return numbers
+}
+
+func TestOrderEventsSubscriptionForward(t *testing.T) {
+	ch := make(chan []*zeroex.OrderEvent, 10)
+	s := &OrderEventsSubscription{
+		ch:     ch,
+		missed: make(chan error, 1),
+	}
+	quit := make(chan struct{})
+
+	require.True(t, s.forward(quit, []*zeroex.OrderEvent{
+		{Epoch: "first", SequenceNumber: 1},
+		{Epoch: "first", SequenceNumber: 2},
+	}))
+	assert.Equal(t, []uint64{1, 2}, sequenceNumbers(<-ch))
+
+	// Order events which were already sent are filtered out.
+	require.True(t, s.forward(quit, []*zeroex.OrderEvent{
+		{Epoch: "first", SequenceNumber: 2},
+		{Epoch: "first", SequenceNumber: 3},
+	}))
+	assert.Equal(t, []uint64{3}, sequenceNumbers(<-ch))
+	epoch, sequenceNumber := s.getLastSequenceNumber()
+	assert.Equal(t, "first", epoch)
+	assert.Equal(t, uint64(3), sequenceNumber)
+	select {
+	case err := <-s.Missed():
+		t.Fatalf("unexpected error: %s", err)
+	default:
+	}
+
+	// After a restart, the sequence numbers start over in a new epoch. The
+	// order events of the new epoch are not filtered out even though their
+	// sequence numbers are lower, and the subscriber is told that order events
+	// were missed.
+	require.True(t, s.forward(quit, []*zeroex.OrderEvent{
+		{Epoch: "second", SequenceNumber: 1},
+		{Epoch: "second", SequenceNumber: 2},
+	}))
+	assert.Equal(t, []uint64{1, 2}, sequenceNumbers(<-ch))
+	select {
+	case err := <-s.Missed():
+		assert.Equal(t, ErrOrderEventsEpochChanged, err)
+	default:
+		t.Fatal("expected ErrOrderEventsEpochChanged")
+	}
+	epoch, sequenceNumber = s.getLastSequenceNumber()
+	assert.Equal(t, "second", epoch)
+	assert.Equal(t, uint64(2), sequenceNumber)
+}
@@ -0,0 +1,326 @@
+package scenario
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OrderBookReferenceTime is the time that the expiration times of the orders
+// created by GenerateOrderBook are relative to. It is fixed (instead of being
+// the current time) so that the same seed always results in the same order
+// hashes, and it is far enough in the future that the orders don't expire.
+var OrderBookReferenceTime = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	// minOrderBookExpiration and maxOrderBookExpiration are the bounds of how
+	// long after OrderBookReferenceTime the orders created by GenerateOrderBook
+	// expire.
+	minOrderBookExpiration = 10 * time.Minute
+	maxOrderBookExpiration = 30 * 24 * time.Hour
+)
+
+var (
+	// oneEther is the number of base units in one whole ERC20 token with 18
+	// decimals.
+	oneEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+	// orderBookMakers are the maker addresses used by GenerateOrderBook. They
+	// are all Ganache accounts, which means the orders can be signed with the
+	// test signer.
+	orderBookMakers = []common.Address{
+		constants.GanacheAccount1,
+		constants.GanacheAccount2,
+		constants.GanacheAccount3,
+		constants.GanacheAccount4,
+	}
+
+	// orderBookBaseAssetTypes are the types of the base asset of every pair
+	// except the first one, which is always ZRX/WETH.
+	orderBookBaseAssetTypes = []string{"ERC20Token", "ERC721Token", "ERC1155Assets", "MultiAsset"}
+)
+
+// assetDataEncoderAbi is used to encode asset data without DevUtils, so that
+// order books can be generated without a connection to Ganache.
+const assetDataEncoderAbi = "[" +
+	"{\"inputs\":[{\"name\":\"tokenAddress\",\"type\":\"address\"}],\"name\":\"ERC20Token\",\"type\":\"function\"}," +
+	"{\"inputs\":[{\"name\":\"tokenAddress\",\"type\":\"address\"},{\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"ERC721Token\",\"type\":\"function\"}," +
+	"{\"inputs\":[{\"name\":\"address\",\"type\":\"address\"},{\"name\":\"ids\",\"type\":\"uint256[]\"},{\"name\":\"values\",\"type\":\"uint256[]\"},{\"name\":\"callbackData\",\"type\":\"bytes\"}],\"name\":\"ERC1155Assets\",\"type\":\"function\"}," +
+	"{\"inputs\":[{\"name\":\"amounts\",\"type\":\"uint256[]\"},{\"name\":\"nestedAssetData\",\"type\":\"bytes[]\"}],\"name\":\"MultiAsset\",\"type\":\"function\"}" +
+	"]"
+
+var assetDataEncoder abi.ABI
+
+func init() {
+	var err error
+	assetDataEncoder, err = abi.JSON(strings.NewReader(assetDataEncoderAbi))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// OrderBook is a pseudorandom order book created by GenerateOrderBook.
+type OrderBook struct {
+	Pairs []*OrderBookPair
+}
+
+// OrderBookPair contains the orders for one pair of an OrderBook.
+type OrderBookPair struct {
+	// BaseAssetType is the name of the type of the base asset (e.g.
+	// "ERC20Token" or "ERC721Token"), as returned by AssetDataDecoder.GetName.
+	// Orders for ERC721 pairs each have their own token ID, so the asset data of
+	// the base asset is not the same for all orders of a pair.
+	BaseAssetType string
+	// QuoteAssetData is the asset data of the quote asset, which is always WETH.
+	QuoteAssetData []byte
+	// MidPrice is the price (in WETH per unit of the base asset) around which
+	// the price ladder of the pair was generated.
+	MidPrice float64
+	// Bids are the orders which buy the base asset, ordered from the highest
+	// to the lowest price.
+	Bids []*zeroex.SignedOrder
+	// Asks are the orders which sell the base asset, ordered from the lowest
+	// to the highest price.
+	Asks []*zeroex.SignedOrder
+}
+
+// Orders returns all the orders in the order book.
+func (b *OrderBook) Orders() []*zeroex.SignedOrder {
+	orders := []*zeroex.SignedOrder{}
+	for _, pair := range b.Pairs {
+		orders = append(orders, pair.Bids...)
+		orders = append(orders, pair.Asks...)
+	}
+	return orders
+}
+
+// GenerateOrderBook creates a realistic order book with the given number of
+// pairs, each of which has depth bids and depth asks. The bids and asks of a
+// pair form a price ladder around a random mid price, have varied amounts and
+// expiration times and are signed by one of a few Ganache accounts. The first
+// pair is ZRX/WETH and the base assets of the other pairs are a mix of ERC20,
+// ERC721, ERC1155 and MultiAsset assets.
+//
+// The result only depends on seed, pairs and depth, so it can be used for
+// benchmarks and regression tests which need the same orders (and order
+// hashes) on every run. The orders are not backed by any on-chain state, which
+// means most of them are not fillable. Use NewSignedTestOrdersBatch for
+// fillable orders.
+func GenerateOrderBook(seed int64, pairs int, depth int) (*OrderBook, error) {
+	if pairs < 0 {
+		return nil, errors.New("scenario: pairs must not be negative")
+	}
+	if depth < 0 {
+		return nil, errors.New("scenario: depth must not be negative")
+	}
+	g := &orderBookGenerator{
+		random: rand.New(rand.NewSource(seed)),
+	}
+	orderBook := &OrderBook{
+		Pairs: make([]*OrderBookPair, pairs),
+	}
+	for i := 0; i < pairs; i++ {
+		pair, err := g.generatePair(i, depth)
+		if err != nil {
+			return nil, err
+		}
+		orderBook.Pairs[i] = pair
+	}
+	return orderBook, nil
+}
+
+type orderBookGenerator struct {
+	random *rand.Rand
+	// nextERC721TokenID is used to give each ERC721 order its own token ID.
+	nextERC721TokenID int64
+}
+
+// pairAssets describes the base asset of a pair.
+type pairAssets struct {
+	assetType string
+	// assetData returns the asset data of the base asset for a new order.
+	assetData func() ([]byte, error)
+	// maxUnits is the maximum number of units of the base asset per order.
+	maxUnits int
+	// unitAmount is the amount of the base asset that makes up one unit.
+	unitAmount *big.Int
+}
+
+func (g *orderBookGenerator) generatePair(index int, depth int) (*OrderBookPair, error) {
+	assets, err := g.generatePairAssets(index)
+	if err != nil {
+		return nil, err
+	}
+	// Mid prices are distributed log-uniformly between 0.001 and 1000 WETH per
+	// unit, the spread is between 0.1% and 1% and the distance between two
+	// price levels is between 0.05% and 0.5% of the mid price.
+	midPrice := math.Pow(10, -3+6*g.random.Float64())
+	halfSpread := (0.001 + 0.009*g.random.Float64()) / 2
+	tickSize := 0.0005 + 0.0045*g.random.Float64()
+
+	pair := &OrderBookPair{
+		BaseAssetType:  assets.assetType,
+		QuoteAssetData: WETHAssetData,
+		MidPrice:       midPrice,
+		Bids:           make([]*zeroex.SignedOrder, depth),
+		Asks:           make([]*zeroex.SignedOrder, depth),
+	}
+	for level := 0; level < depth; level++ {
+		bidPrice := midPrice * (1 - halfSpread - float64(level)*tickSize)
+		if bidPrice <= 0 {
+			bidPrice = midPrice * tickSize
+		}
+		bid, err := g.generateOrder(assets, bidPrice, true)
+		if err != nil {
+			return nil, err
+		}
+		pair.Bids[level] = bid
+		askPrice := midPrice * (1 + halfSpread + float64(level)*tickSize)
+		ask, err := g.generateOrder(assets, askPrice, false)
+		if err != nil {
+			return nil, err
+		}
+		pair.Asks[level] = ask
+	}
+	return pair, nil
+}
+
+func (g *orderBookGenerator) generatePairAssets(index int) (*pairAssets, error) {
+	if index == 0 {
+		return erc20PairAssets(ZRXAssetData), nil
+	}
+	assetType := orderBookBaseAssetTypes[g.random.Intn(len(orderBookBaseAssetTypes))]
+	switch assetType {
+	case "ERC20Token":
+		var tokenAddress common.Address
+		_, _ = g.random.Read(tokenAddress[:])
+		assetData, err := assetDataEncoder.Pack("ERC20Token", tokenAddress)
+		if err != nil {
+			return nil, err
+		}
+		return erc20PairAssets(assetData), nil
+	case "ERC721Token":
+		return &pairAssets{
+			assetType: assetType,
+			assetData: func() ([]byte, error) {
+				g.nextERC721TokenID++
+				return assetDataEncoder.Pack("ERC721Token", constants.GanacheDummyERC721TokenAddress, big.NewInt(g.nextERC721TokenID))
+			},
+			maxUnits:   1,
+			unitAmount: big.NewInt(1),
+		}, nil
+	case "ERC1155Assets":
+		assetData, err := encodeERC1155AssetData(big.NewInt(g.random.Int63()), big.NewInt(1))
+		if err != nil {
+			return nil, err
+		}
+		return &pairAssets{
+			assetType:  assetType,
+			assetData:  staticAssetData(assetData),
+			maxUnits:   100,
+			unitAmount: big.NewInt(1),
+		}, nil
+	case "MultiAsset":
+		// A bundle of ZRX and an ERC1155 token.
+		erc1155AssetData, err := encodeERC1155AssetData(big.NewInt(g.random.Int63()), big.NewInt(1))
+		if err != nil {
+			return nil, err
+		}
+		zrxAmount := new(big.Int).Mul(big.NewInt(int64(1+g.random.Intn(100))), oneEther)
+		assetData, err := assetDataEncoder.Pack(
+			"MultiAsset",
+			[]*big.Int{zrxAmount, big.NewInt(1)},
+			[][]byte{ZRXAssetData, erc1155AssetData},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &pairAssets{
+			assetType:  assetType,
+			assetData:  staticAssetData(assetData),
+			maxUnits:   10,
+			unitAmount: big.NewInt(1),
+		}, nil
+	}
+	return nil, errors.New("scenario: unexpected base asset type: " + assetType)
+}
+
+// generateOrder creates an order for the given number of units of the base
+// asset at the given price. If isBid is true, the maker buys the base asset
+// (i.e. the maker asset is WETH), otherwise the maker sells it.
+func (g *orderBookGenerator) generateOrder(assets *pairAssets, price float64, isBid bool) (*zeroex.SignedOrder, error) {
+	baseAssetData, err := assets.assetData()
+	if err != nil {
+		return nil, err
+	}
+	units := int64(1 + g.random.Intn(assets.maxUnits))
+	baseAmount := new(big.Int).Mul(big.NewInt(units), assets.unitAmount)
+	quoteAmount, _ := new(big.Float).Mul(
+		new(big.Float).SetInt(new(big.Int).Mul(big.NewInt(units), oneEther)),
+		big.NewFloat(price),
+	).Int(nil)
+	if quoteAmount.Sign() <= 0 {
+		quoteAmount = big.NewInt(1)
+	}
+	expiration := minOrderBookExpiration + time.Duration(g.random.Int63n(int64(maxOrderBookExpiration-minOrderBookExpiration)))
+
+	order := &zeroex.Order{
+		ChainID:               big.NewInt(constants.TestChainID),
+		MakerAddress:          orderBookMakers[g.random.Intn(len(orderBookMakers))],
+		TakerAddress:          constants.NullAddress,
+		SenderAddress:         constants.NullAddress,
+		FeeRecipientAddress:   constants.NullAddress,
+		MakerFeeAssetData:     constants.NullBytes,
+		TakerFeeAssetData:     constants.NullBytes,
+		Salt:                  big.NewInt(g.random.Int63()),
+		MakerFee:              big.NewInt(0),
+		TakerFee:              big.NewInt(0),
+		ExpirationTimeSeconds: big.NewInt(OrderBookReferenceTime.Add(expiration).Unix()),
+		ExchangeAddress:       ganacheAddresses.Exchange,
+	}
+	if isBid {
+		order.MakerAssetData = WETHAssetData
+		order.MakerAssetAmount = quoteAmount
+		order.TakerAssetData = baseAssetData
+		order.TakerAssetAmount = baseAmount
+	} else {
+		order.MakerAssetData = baseAssetData
+		order.MakerAssetAmount = baseAmount
+		order.TakerAssetData = WETHAssetData
+		order.TakerAssetAmount = quoteAmount
+	}
+	return zeroex.SignTestOrder(order)
+}
+
+func erc20PairAssets(assetData []byte) *pairAssets {
+	return &pairAssets{
+		assetType:  "ERC20Token",
+		assetData:  staticAssetData(assetData),
+		maxUnits:   1000,
+		unitAmount: oneEther,
+	}
+}
+
+func staticAssetData(assetData []byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		return assetData, nil
+	}
+}
+
+func encodeERC1155AssetData(tokenID *big.Int, value *big.Int) ([]byte, error) {
+	return assetDataEncoder.Pack(
+		"ERC1155Assets",
+		constants.GanacheDummyERC1155MintableAddress,
+		[]*big.Int{tokenID},
+		[]*big.Int{value},
+		[]byte{},
+	)
+}
@@ -0,0 +1,82 @@
+package scenario
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOrderBookIsDeterministic(t *testing.T) {
+	t.Parallel()
+	first, err := GenerateOrderBook(42, 8, 5)
+	require.NoError(t, err)
+	second, err := GenerateOrderBook(42, 8, 5)
+	require.NoError(t, err)
+	other, err := GenerateOrderBook(43, 8, 5)
+	require.NoError(t, err)
+
+	firstOrders := first.Orders()
+	require.Len(t, firstOrders, 8*5*2)
+	secondOrders := second.Orders()
+	otherOrders := other.Orders()
+	for i := range firstOrders {
+		firstHash, err := firstOrders[i].ComputeOrderHash()
+		require.NoError(t, err)
+		secondHash, err := secondOrders[i].ComputeOrderHash()
+		require.NoError(t, err)
+		assert.Equal(t, firstHash, secondHash, "order %d", i)
+		assert.Equal(t, firstOrders[i].Signature, secondOrders[i].Signature, "order %d", i)
+		otherHash, err := otherOrders[i].ComputeOrderHash()
+		require.NoError(t, err)
+		assert.NotEqual(t, firstHash, otherHash, "order %d", i)
+	}
+}
+
+func TestGenerateOrderBookPriceLadder(t *testing.T) {
+	t.Parallel()
+	orderBook, err := GenerateOrderBook(1, 20, 10)
+	require.NoError(t, err)
+	require.Len(t, orderBook.Pairs, 20)
+	assert.Equal(t, "ERC20Token", orderBook.Pairs[0].BaseAssetType)
+	assert.Equal(t, ZRXAssetData, orderBook.Pairs[0].Asks[0].MakerAssetData)
+
+	decoder := zeroex.NewAssetDataDecoder()
+	for _, pair := range orderBook.Pairs {
+		require.Len(t, pair.Bids, 10)
+		require.Len(t, pair.Asks, 10)
+		previousBidPrice := pair.MidPrice
+		for _, bid := range pair.Bids {
+			assert.Equal(t, WETHAssetData, bid.MakerAssetData)
+			name, err := decoder.GetName(bid.TakerAssetData)
+			require.NoError(t, err)
+			assert.Equal(t, pair.BaseAssetType, name)
+			price := orderPrice(bid.MakerAssetAmount, bid.TakerAssetAmount, pair.BaseAssetType)
+			assert.True(t, price < previousBidPrice, "bids should be ordered from the highest to the lowest price")
+			previousBidPrice = price
+		}
+		previousAskPrice := pair.MidPrice
+		for _, ask := range pair.Asks {
+			assert.Equal(t, WETHAssetData, ask.TakerAssetData)
+			name, err := decoder.GetName(ask.MakerAssetData)
+			require.NoError(t, err)
+			assert.Equal(t, pair.BaseAssetType, name)
+			price := orderPrice(ask.TakerAssetAmount, ask.MakerAssetAmount, pair.BaseAssetType)
+			assert.True(t, price > previousAskPrice, "asks should be ordered from the lowest to the highest price")
+			previousAskPrice = price
+			assert.True(t, ask.ExpirationTimeSeconds.Int64() > OrderBookReferenceTime.Unix())
+		}
+	}
+}
+
+// orderPrice returns the price in WETH per unit of the base asset.
+func orderPrice(quoteAmount *big.Int, baseAmount *big.Int, baseAssetType string) float64 {
+	units, _ := new(big.Float).SetInt(baseAmount).Float64()
+	if baseAssetType == "ERC20Token" {
+		units /= 1e18
+	}
+	quote, _ := new(big.Float).SetInt(quoteAmount).Float64()
+	return quote / 1e18 / units
+}
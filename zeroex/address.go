@@ -0,0 +1,95 @@
+package zeroex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// signedOrderAddressFields are the JSON fields of a signed order which hold an
+// Ethereum address.
+var signedOrderAddressFields = []string{
+	"exchangeAddress",
+	"makerAddress",
+	"takerAddress",
+	"senderAddress",
+	"feeRecipientAddress",
+}
+
+// AddressChecksumError is returned when an address containing both upper and
+// lowercase letters does not have a valid EIP-55 checksum.
+type AddressChecksumError struct {
+	// Field is the name of the JSON field which contains the address, if known.
+	Field   string
+	Address string
+}
+
+func (e AddressChecksumError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("invalid EIP-55 checksum for address %s", e.Address)
+	}
+	return fmt.Sprintf("invalid EIP-55 checksum for %s: %s", e.Field, e.Address)
+}
+
+// ParseAddress parses a hex-encoded Ethereum address. Addresses which are all
+// lowercase or all uppercase are accepted as is, while addresses which contain
+// both upper and lowercase letters must have a valid EIP-55 checksum. Like
+// common.HexToAddress, ParseAddress is lenient about the length of the address
+// and whether it has a "0x" prefix.
+func ParseAddress(s string) (common.Address, error) {
+	address := common.HexToAddress(s)
+	hex := s
+	if strings.HasPrefix(hex, "0x") || strings.HasPrefix(hex, "0X") {
+		hex = hex[2:]
+	}
+	isMixedCase := strings.ToLower(hex) != hex && strings.ToUpper(hex) != hex
+	if isMixedCase && (!common.IsHexAddress(s) || address.Hex()[2:] != hex) {
+		return common.Address{}, AddressChecksumError{Address: s}
+	}
+	return address, nil
+}
+
+// NormalizeSignedOrderJSON validates the EIP-55 checksums of the addresses in
+// the given JSON encoded signed order (see ParseAddress) and converts them to
+// lowercase, which is how Mesh encodes addresses. This makes orders with
+// checksummed addresses match order filters written for lowercase addresses.
+// All other fields are left as is. If data is not a JSON object, it is returned
+// unchanged so that schema validation can report the problem.
+func NormalizeSignedOrderJSON(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data, nil
+	}
+	for _, field := range signedOrderAddressFields {
+		rawValue, found := fields[field]
+		if !found {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(rawValue, &value); err != nil {
+			// Not a string. Schema validation will reject the order.
+			continue
+		}
+		if _, err := ParseAddress(value); err != nil {
+			return nil, AddressChecksumError{Field: field, Address: value}
+		}
+		normalizedValue, err := json.Marshal(strings.ToLower(value))
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = normalizedValue
+	}
+	return json.Marshal(fields)
+}
+
+// parseAddressField is like ParseAddress but includes the name of the JSON
+// field in the returned error.
+func parseAddressField(field string, s string) (common.Address, error) {
+	address, err := ParseAddress(s)
+	if err != nil {
+		return common.Address{}, AddressChecksumError{Field: field, Address: s}
+	}
+	return address, nil
+}
@@ -0,0 +1,89 @@
+package zeroex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	checksummedAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	lowercaseAddress   = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	// badChecksumAddress is checksummedAddress with the case of one letter
+	// flipped.
+	badChecksumAddress = "0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+)
+
+func TestParseAddress(t *testing.T) {
+	expectedAddress := common.HexToAddress(lowercaseAddress)
+	testCases := []struct {
+		address     string
+		expectedErr error
+	}{
+		{address: checksummedAddress},
+		{address: lowercaseAddress},
+		{address: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"},
+		{address: badChecksumAddress, expectedErr: AddressChecksumError{Address: badChecksumAddress}},
+	}
+	for _, testCase := range testCases {
+		actualAddress, err := ParseAddress(testCase.address)
+		if testCase.expectedErr != nil {
+			assert.Equal(t, testCase.expectedErr, err, testCase.address)
+			continue
+		}
+		require.NoError(t, err, testCase.address)
+		assert.Equal(t, expectedAddress, actualAddress, testCase.address)
+	}
+}
+
+func TestNormalizeSignedOrderJSON(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(signedOrder)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+	fields["makerAddress"] = checksummedAddress
+	checksummedJSON, err := json.Marshal(fields)
+	require.NoError(t, err)
+
+	normalizedJSON, err := NormalizeSignedOrderJSON(checksummedJSON)
+	require.NoError(t, err)
+	var normalizedFields map[string]interface{}
+	require.NoError(t, json.Unmarshal(normalizedJSON, &normalizedFields))
+	assert.Equal(t, lowercaseAddress, normalizedFields["makerAddress"])
+	delete(normalizedFields, "makerAddress")
+	delete(fields, "makerAddress")
+	assert.Equal(t, fields, normalizedFields)
+
+	fields["makerAddress"] = badChecksumAddress
+	badChecksumJSON, err := json.Marshal(fields)
+	require.NoError(t, err)
+	_, err = NormalizeSignedOrderJSON(badChecksumJSON)
+	assert.Equal(t, AddressChecksumError{Field: "makerAddress", Address: badChecksumAddress}, err)
+}
+
+func TestUnmarshalSignedOrderValidatesAddressChecksums(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(signedOrder)
+	require.NoError(t, err)
+	var signedOrderJSON SignedOrderJSON
+	require.NoError(t, json.Unmarshal(encoded, &signedOrderJSON))
+
+	signedOrderJSON.FeeRecipientAddress = checksummedAddress
+	encoded, err = json.Marshal(signedOrderJSON)
+	require.NoError(t, err)
+	var decoded SignedOrder
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, common.HexToAddress(lowercaseAddress), decoded.FeeRecipientAddress)
+
+	signedOrderJSON.FeeRecipientAddress = badChecksumAddress
+	encoded, err = json.Marshal(signedOrderJSON)
+	require.NoError(t, err)
+	err = json.Unmarshal(encoded, &decoded)
+	assert.Equal(t, AddressChecksumError{Field: "feeRecipientAddress", Address: badChecksumAddress}, err)
+}
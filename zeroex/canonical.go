@@ -0,0 +1,107 @@
+package zeroex
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CanonicalSignedOrderFields are the JSON fields of a signed order as defined
+// by the 0x protocol. They are the only fields of an order which are allowed
+// to be sent to peers. Any metadata that Mesh keeps about an order locally
+// (e.g. annotations, pinning or the topics it was received on) must never be
+// added to SignedOrder, since SignedOrder is what is gossiped and sent via
+// ordersync.
+var CanonicalSignedOrderFields = []string{
+	"chainId",
+	"exchangeAddress",
+	"makerAddress",
+	"makerAssetData",
+	"makerFeeAssetData",
+	"makerAssetAmount",
+	"makerFee",
+	"takerAddress",
+	"takerAssetData",
+	"takerFeeAssetData",
+	"takerAssetAmount",
+	"takerFee",
+	"senderAddress",
+	"feeRecipientAddress",
+	"expirationTimeSeconds",
+	"salt",
+	"signature",
+}
+
+var canonicalSignedOrderFieldSet = map[string]struct{}{}
+
+func init() {
+	for _, field := range CanonicalSignedOrderFields {
+		canonicalSignedOrderFieldSet[field] = struct{}{}
+	}
+}
+
+// NonCanonicalOrderFieldError is returned by MarshalCanonicalJSON and
+// CheckCanonicalJSON if an encoded order contains a field which is not in
+// CanonicalSignedOrderFields.
+type NonCanonicalOrderFieldError struct {
+	Field string
+}
+
+func (e NonCanonicalOrderFieldError) Error() string {
+	return fmt.Sprintf("encoded order contains non-canonical field: %q", e.Field)
+}
+
+// MarshalCanonicalJSON encodes the order as JSON and checks that the encoding
+// only contains the fields in CanonicalSignedOrderFields. It should be used
+// whenever an order is encoded to be sent to peers.
+func MarshalCanonicalJSON(order *SignedOrder) ([]byte, error) {
+	encodedOrder, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckCanonicalJSON(encodedOrder); err != nil {
+		return nil, err
+	}
+	return encodedOrder, nil
+}
+
+// MarshalCanonicalOrdersJSON encodes the orders as a JSON array in which each
+// order is encoded with MarshalCanonicalJSON. Like json.Marshal, it encodes a
+// nil slice as null. It should be used whenever a list of orders is encoded to
+// be sent to peers.
+func MarshalCanonicalOrdersJSON(orders []*SignedOrder) ([]byte, error) {
+	if orders == nil {
+		return []byte("null"), nil
+	}
+	encodedOrders := make([]json.RawMessage, len(orders))
+	for i, order := range orders {
+		encodedOrder, err := MarshalCanonicalJSON(order)
+		if err != nil {
+			return nil, err
+		}
+		encodedOrders[i] = encodedOrder
+	}
+	return json.Marshal(encodedOrders)
+}
+
+// CheckCanonicalJSON returns an error if encodedOrder is not a JSON object or
+// contains any fields which are not in CanonicalSignedOrderFields. If there
+// are several such fields, the error is always for the first one in
+// alphabetical order.
+func CheckCanonicalJSON(encodedOrder []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encodedOrder, &fields); err != nil {
+		return err
+	}
+	nonCanonicalFields := []string{}
+	for field := range fields {
+		if _, found := canonicalSignedOrderFieldSet[field]; !found {
+			nonCanonicalFields = append(nonCanonicalFields, field)
+		}
+	}
+	if len(nonCanonicalFields) > 0 {
+		sort.Strings(nonCanonicalFields)
+		return NonCanonicalOrderFieldError{Field: nonCanonicalFields[0]}
+	}
+	return nil
+}
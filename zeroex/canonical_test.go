@@ -0,0 +1,111 @@
+package zeroex
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonFieldNames returns the JSON field names of the exported fields of typ,
+// including the fields of embedded structs.
+func jsonFieldNames(typ reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			// Unexported fields (e.g. the cached order hash) are never encoded.
+			continue
+		}
+		names = append(names, field.Tag.Get("json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestSignedOrderOnlyHasCanonicalFields fails if a field is added to
+// SignedOrder or its JSON encoding. Local metadata must be stored outside of
+// SignedOrder so that it is never sent to peers.
+func TestSignedOrderOnlyHasCanonicalFields(t *testing.T) {
+	t.Parallel()
+	expectedFields := make([]string, len(CanonicalSignedOrderFields))
+	copy(expectedFields, CanonicalSignedOrderFields)
+	sort.Strings(expectedFields)
+
+	assert.Equal(t, expectedFields, jsonFieldNames(reflect.TypeOf(SignedOrder{})))
+	assert.Equal(t, expectedFields, jsonFieldNames(reflect.TypeOf(SignedOrderJSON{})))
+}
+
+func TestMarshalCanonicalJSON(t *testing.T) {
+	t.Parallel()
+	var signedOrder SignedOrder
+	require.NoError(t, json.Unmarshal([]byte(testSignedOrderJSONWithExtraFields), &signedOrder))
+
+	encodedOrder, err := MarshalCanonicalJSON(&signedOrder)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(encodedOrder, &fields))
+	assert.Len(t, fields, len(CanonicalSignedOrderFields))
+	assert.NotContains(t, fields, "annotations")
+	assert.NotContains(t, fields, "isPinned")
+}
+
+func TestMarshalCanonicalOrdersJSON(t *testing.T) {
+	t.Parallel()
+	var signedOrder SignedOrder
+	require.NoError(t, json.Unmarshal([]byte(testSignedOrderJSONWithExtraFields), &signedOrder))
+
+	encodedOrders, err := MarshalCanonicalOrdersJSON([]*SignedOrder{&signedOrder, &signedOrder})
+	require.NoError(t, err)
+	var orders []json.RawMessage
+	require.NoError(t, json.Unmarshal(encodedOrders, &orders))
+	require.Len(t, orders, 2)
+	for _, order := range orders {
+		assert.NoError(t, CheckCanonicalJSON(order))
+	}
+
+	encodedOrders, err = MarshalCanonicalOrdersJSON(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(encodedOrders))
+	encodedOrders, err = MarshalCanonicalOrdersJSON([]*SignedOrder{})
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(encodedOrders))
+}
+
+func TestCheckCanonicalJSON(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, NonCanonicalOrderFieldError{Field: "annotations"}, CheckCanonicalJSON([]byte(testSignedOrderJSONWithExtraFields)))
+	assert.NoError(t, CheckCanonicalJSON([]byte(`{"chainId":1337,"signature":"0x"}`)))
+	assert.Error(t, CheckCanonicalJSON([]byte(`[]`)))
+}
+
+// testSignedOrderJSONWithExtraFields is a signed order with local metadata
+// that must not be sent to peers.
+const testSignedOrderJSONWithExtraFields = `{
+	"chainId": 1337,
+	"exchangeAddress": "0x48bacb9266a570d521063ef5dd96e61686dbe788",
+	"makerAddress": "0x6ecbe1db9ef729cbe972c83fb886247691fb6beb",
+	"makerAssetData": "0xf47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c",
+	"makerFeeAssetData": "0x",
+	"makerAssetAmount": "100",
+	"makerFee": "0",
+	"takerAddress": "0x0000000000000000000000000000000000000000",
+	"takerAssetData": "0xf47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082",
+	"takerFeeAssetData": "0x",
+	"takerAssetAmount": "42",
+	"takerFee": "0",
+	"senderAddress": "0x0000000000000000000000000000000000000000",
+	"feeRecipientAddress": "0x0000000000000000000000000000000000000000",
+	"expirationTimeSeconds": "1588000000",
+	"salt": "1",
+	"signature": "0x1c3582f06356a1314dbf1c0e534c4d8e92e59b056ee607a7ff5a825f5f2cc5e6151c5cc7fdd420f5608e4d5bef108e42ad90c7a4b408caef32e24374cf387b0d7603",
+	"annotations": {"source": "internal"},
+	"isPinned": true
+}`
@@ -0,0 +1,28 @@
+// +build cgo,!purego
+
+package zeroex
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignerRecoveryImplementation is the name of the secp256k1 implementation
+// used by RecoverSigner. It is "libsecp256k1" when Mesh is built with cgo and
+// "btcec" when it is built with CGO_ENABLED=0 or the purego build tag.
+const SignerRecoveryImplementation = "libsecp256k1"
+
+// ecrecover returns the address that signed hash. signature must be 65 bytes
+// in the [V || R || S] format where V is 27 or 28.
+func ecrecover(hash []byte, signature []byte) (common.Address, error) {
+	// libsecp256k1 expects signatures in the [R || S || V] format where V is 0
+	// or 1.
+	ecSignature := make([]byte, 65)
+	copy(ecSignature[0:64], signature[1:65])
+	ecSignature[64] = signature[0] - 27
+	pubKey, err := crypto.SigToPub(hash, ecSignature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
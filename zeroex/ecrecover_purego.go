@@ -0,0 +1,28 @@
+// +build !cgo purego
+
+package zeroex
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignerRecoveryImplementation is the name of the secp256k1 implementation
+// used by RecoverSigner. It is "libsecp256k1" when Mesh is built with cgo and
+// "btcec" when it is built with CGO_ENABLED=0 or the purego build tag.
+const SignerRecoveryImplementation = "btcec"
+
+// ecrecover returns the address that signed hash. signature must be 65 bytes
+// in the [V || R || S] format where V is 27 or 28.
+func ecrecover(hash []byte, signature []byte) (common.Address, error) {
+	// btcec uses the same "compact" [V || R || S] format as 0x signatures, so
+	// no conversion is needed.
+	pubKey, _, err := btcec.RecoverCompact(btcec.S256(), signature, hash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(ecdsa.PublicKey(*pubKey)), nil
+}
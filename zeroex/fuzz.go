@@ -0,0 +1,102 @@
+// +build gofuzz
+
+package zeroex
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// This file contains harnesses for go-fuzz (https://github.com/dvyukov/go-fuzz)
+// and libFuzzer. They are run with `make fuzz`. Orders and asset data are
+// received from untrusted peers, so the functions exercised here must never
+// panic.
+//
+// Each harness returns 1 if the input was parsed successfully (which tells the
+// fuzzer to prioritize it when generating new inputs) and 0 otherwise.
+
+var fuzzAssetDataDecoder = NewAssetDataDecoder()
+
+// FuzzSignedOrderJSON unmarshals data as a signed order and checks that the
+// order hash can be computed and that the order can be marshaled again and
+// unmarshaled to the same order hash.
+func FuzzSignedOrderJSON(data []byte) int {
+	var signedOrder SignedOrder
+	if err := json.Unmarshal(data, &signedOrder); err != nil {
+		return 0
+	}
+	orderHash, err := signedOrder.ComputeOrderHash()
+	if err != nil {
+		return 0
+	}
+	encoded, err := json.Marshal(signedOrder)
+	if err != nil {
+		panic(err)
+	}
+	var decoded SignedOrder
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		panic(err)
+	}
+	decodedOrderHash, err := decoded.ComputeOrderHash()
+	if err != nil {
+		panic(err)
+	}
+	if decodedOrderHash != orderHash {
+		panic("order hash changed after marshaling and unmarshaling the order")
+	}
+	return 1
+}
+
+// FuzzAssetData decodes data as asset data, including the asset data nested in
+// MultiAsset asset data and the data of StaticCall asset data.
+func FuzzAssetData(data []byte) int {
+	if err := decodeAssetDataForFuzzing(data); err != nil {
+		return 0
+	}
+	return 1
+}
+
+func decodeAssetDataForFuzzing(assetData []byte) error {
+	if _, err := fuzzAssetDataDecoder.GetName(assetData); err != nil {
+		return err
+	}
+	switch common.Bytes2Hex(assetData[:4]) {
+	case ERC20AssetDataID:
+		var decoded ERC20AssetData
+		return fuzzAssetDataDecoder.Decode(assetData, &decoded)
+	case ERC721AssetDataID:
+		var decoded ERC721AssetData
+		return fuzzAssetDataDecoder.Decode(assetData, &decoded)
+	case ERC1155AssetDataID:
+		var decoded ERC1155AssetData
+		return fuzzAssetDataDecoder.Decode(assetData, &decoded)
+	case ERC20BridgeAssetDataID:
+		var decoded ERC20BridgeAssetData
+		return fuzzAssetDataDecoder.Decode(assetData, &decoded)
+	case StaticCallAssetDataID:
+		var decoded StaticCallAssetData
+		if err := fuzzAssetDataDecoder.Decode(assetData, &decoded); err != nil {
+			return err
+		}
+		// Note: The static call data is not necessarily asset data, so it is
+		// still parsed successfully if it can't be decoded.
+		_ = decodeAssetDataForFuzzing(decoded.StaticCallData)
+	case CheckGasPriceDefaultID:
+		return fuzzAssetDataDecoder.Decode(assetData, nil)
+	case CheckGasPriceID:
+		var decoded CheckGasPriceStaticCallData
+		return fuzzAssetDataDecoder.Decode(assetData, &decoded)
+	case MultiAssetDataID:
+		var decoded MultiAssetData
+		if err := fuzzAssetDataDecoder.Decode(assetData, &decoded); err != nil {
+			return err
+		}
+		for _, nestedAssetData := range decoded.NestedAssetData {
+			if err := decodeAssetDataForFuzzing(nestedAssetData); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -139,6 +139,13 @@ type OrderEvent struct {
 	// They did not all necessarily cause the orders state change itself, only it's re-evaluation.
 	// Since it's state _did_ change, at least one of them did cause the actual state change.
 	ContractEvents []*ContractEvent `json:"contractEvents"`
+	// SequenceNumber is assigned by the OrderWatcher when the event is emitted.
+	// Consecutive events have consecutive sequence numbers, so a gap means that
+	// events were missed. Sequence numbers start at 1 each time Mesh is started.
+	SequenceNumber uint64 `json:"sequenceNumber"`
+	// Epoch is a random ID which changes each time Mesh is started. Sequence
+	// numbers can only be compared between events with the same epoch.
+	Epoch string `json:"epoch"`
 }
 
 type orderEventJSON struct {
@@ -148,6 +155,8 @@ type orderEventJSON struct {
 	EndState                 string               `json:"endState"`
 	FillableTakerAssetAmount string               `json:"fillableTakerAssetAmount"`
 	ContractEvents           []*contractEventJSON `json:"contractEvents"`
+	SequenceNumber           uint64               `json:"sequenceNumber"`
+	Epoch                    string               `json:"epoch"`
 }
 
 // MarshalJSON implements a custom JSON marshaller for the OrderEvent type
@@ -159,6 +168,8 @@ func (o OrderEvent) MarshalJSON() ([]byte, error) {
 		"endState":                 o.EndState,
 		"fillableTakerAssetAmount": o.FillableTakerAssetAmount.String(),
 		"contractEvents":           o.ContractEvents,
+		"sequenceNumber":           o.SequenceNumber,
+		"epoch":                    o.Epoch,
 	})
 }
 
@@ -177,6 +188,8 @@ func (o *OrderEvent) fromOrderEventJSON(orderEventJSON orderEventJSON) error {
 	o.OrderHash = common.HexToHash(orderEventJSON.OrderHash)
 	o.SignedOrder = orderEventJSON.SignedOrder
 	o.EndState = OrderEventEndState(orderEventJSON.EndState)
+	o.SequenceNumber = orderEventJSON.SequenceNumber
+	o.Epoch = orderEventJSON.Epoch
 	var ok bool
 	o.FillableTakerAssetAmount, ok = math.ParseBig256(orderEventJSON.FillableTakerAssetAmount)
 	if !ok {
@@ -331,6 +344,15 @@ const (
 	// increase if a previously processed fill event gets reverted, or if a maker tops up their balance/allowance
 	// backing an order
 	ESOrderFillabilityIncreased = OrderEventEndState("FILLABILITY_INCREASED")
+	// ESOrderFillReverted means the fillability of an order has increased because a fill was reverted by a
+	// block re-org. It is emitted instead of ESOrderFillabilityIncreased (or ESOrderAdded if the order had been
+	// fully filled) so that subscribers can undo their accounting for the fill. The reverted fill can be found in
+	// ContractEvents with IsRemoved set to true.
+	ESOrderFillReverted = OrderEventEndState("FILL_REVERTED")
+	// ESOrderCancelReverted means a cancelled order is fillable again because its cancellation was reverted by a
+	// block re-org. It is emitted instead of ESOrderAdded. The reverted cancellation can be found in ContractEvents
+	// with IsRemoved set to true.
+	ESOrderCancelReverted = OrderEventEndState("CANCEL_REVERTED")
 	// ESStoppedWatching means an order is potentially still valid but was removed for a different reason (e.g.
 	// the database is full or the peer that sent the order was misbehaving). The order will no longer be watched
 	// and no further events for this order will be emitted. In some cases, the order may be re-added in the
@@ -338,6 +360,29 @@ const (
 	ESStoppedWatching = OrderEventEndState("STOPPED_WATCHING")
 )
 
+// emittedOrderEventEndStates are the OrderEventEndStates which order events
+// can have.
+var emittedOrderEventEndStates = map[OrderEventEndState]struct{}{
+	ESOrderAdded:                {},
+	ESOrderFilled:               {},
+	ESOrderFullyFilled:          {},
+	ESOrderCancelled:            {},
+	ESOrderExpired:              {},
+	ESOrderUnexpired:            {},
+	ESOrderBecameUnfunded:       {},
+	ESOrderFillabilityIncreased: {},
+	ESOrderFillReverted:         {},
+	ESOrderCancelReverted:       {},
+	ESStoppedWatching:           {},
+}
+
+// IsValid returns true if s is one of the end states which order events can
+// have (i.e. any OrderEventEndState except ESInvalid).
+func (s OrderEventEndState) IsValid() bool {
+	_, found := emittedOrderEventEndStates[s]
+	return found
+}
+
 var eip712OrderTypes = gethsigner.Types{
 	"EIP712Domain": {
 		{
@@ -614,8 +659,12 @@ func (s *SignedOrder) UnmarshalJSON(data []byte) error {
 	}
 	var ok bool
 	s.ChainID = big.NewInt(signedOrderJSON.ChainID)
-	s.ExchangeAddress = common.HexToAddress(signedOrderJSON.ExchangeAddress)
-	s.MakerAddress = common.HexToAddress(signedOrderJSON.MakerAddress)
+	if s.ExchangeAddress, err = parseAddressField("exchangeAddress", signedOrderJSON.ExchangeAddress); err != nil {
+		return err
+	}
+	if s.MakerAddress, err = parseAddressField("makerAddress", signedOrderJSON.MakerAddress); err != nil {
+		return err
+	}
 	s.MakerAssetData = common.FromHex(signedOrderJSON.MakerAssetData)
 	s.MakerFeeAssetData = common.FromHex(signedOrderJSON.MakerFeeAssetData)
 	if signedOrderJSON.MakerAssetAmount != "" {
@@ -630,7 +679,9 @@ func (s *SignedOrder) UnmarshalJSON(data []byte) error {
 			s.MakerFee = nil
 		}
 	}
-	s.TakerAddress = common.HexToAddress(signedOrderJSON.TakerAddress)
+	if s.TakerAddress, err = parseAddressField("takerAddress", signedOrderJSON.TakerAddress); err != nil {
+		return err
+	}
 	s.TakerAssetData = common.FromHex(signedOrderJSON.TakerAssetData)
 	s.TakerFeeAssetData = common.FromHex(signedOrderJSON.TakerFeeAssetData)
 	if signedOrderJSON.TakerAssetAmount != "" {
@@ -645,8 +696,12 @@ func (s *SignedOrder) UnmarshalJSON(data []byte) error {
 			s.TakerFee = nil
 		}
 	}
-	s.SenderAddress = common.HexToAddress(signedOrderJSON.SenderAddress)
-	s.FeeRecipientAddress = common.HexToAddress(signedOrderJSON.FeeRecipientAddress)
+	if s.SenderAddress, err = parseAddressField("senderAddress", signedOrderJSON.SenderAddress); err != nil {
+		return err
+	}
+	if s.FeeRecipientAddress, err = parseAddressField("feeRecipientAddress", signedOrderJSON.FeeRecipientAddress); err != nil {
+		return err
+	}
 	if signedOrderJSON.ExpirationTimeSeconds != "" {
 		s.ExpirationTimeSeconds, ok = math.ParseBig256(signedOrderJSON.ExpirationTimeSeconds)
 		if !ok {
@@ -659,7 +714,10 @@ func (s *SignedOrder) UnmarshalJSON(data []byte) error {
 			s.Salt = nil
 		}
 	}
-	s.Signature = common.FromHex(signedOrderJSON.Signature)
+	// Note: Signatures are normalized here so that orders are always stored,
+	// validated and shared with peers in the canonical form, regardless of how
+	// they were signed.
+	s.Signature = NormalizeSignature(common.FromHex(signedOrderJSON.Signature))
 	return nil
 }
 
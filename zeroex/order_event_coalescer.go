@@ -0,0 +1,85 @@
+package zeroex
+
+import "github.com/ethereum/go-ethereum/common"
+
+// OrderEventCoalescer buffers order events and coalesces successive fill
+// updates for the same order (e.g. multiple FILLED events caused by several
+// fills in one block) into a single event. It is used to reduce the number of
+// events sent to subscribers during volatile markets. Events which change
+// whether or not an order is fillable (e.g. EXPIRED or CANCELLED) are never
+// coalesced, and the relative order of events is preserved. OrderEventCoalescer
+// is not safe for concurrent use.
+type OrderEventCoalescer struct {
+	events []*OrderEvent
+	// pending maps an order hash to the index in events of the most recent
+	// event for that order which can still be coalesced with.
+	pending map[common.Hash]int
+}
+
+// NewOrderEventCoalescer creates and returns a new OrderEventCoalescer.
+func NewOrderEventCoalescer() *OrderEventCoalescer {
+	return &OrderEventCoalescer{
+		pending: map[common.Hash]int{},
+	}
+}
+
+// isFillUpdate returns true if the given end state only means that the
+// fillable amount of an order has changed.
+func isFillUpdate(endState OrderEventEndState) bool {
+	return endState == ESOrderFilled || endState == ESOrderFillabilityIncreased
+}
+
+// Add adds the given events to the buffer, coalescing them with any buffered
+// events for the same orders where possible.
+func (c *OrderEventCoalescer) Add(events []*OrderEvent) {
+	for _, event := range events {
+		if i, found := c.pending[event.OrderHash]; found && isFillUpdate(event.EndState) {
+			c.events[i] = coalesceOrderEvents(c.events[i], event)
+			continue
+		}
+		c.events = append(c.events, event)
+		if event.EndState == ESOrderAdded || isFillUpdate(event.EndState) {
+			c.pending[event.OrderHash] = len(c.events) - 1
+		} else {
+			delete(c.pending, event.OrderHash)
+		}
+	}
+}
+
+// Flush returns all buffered events and resets the buffer.
+func (c *OrderEventCoalescer) Flush() []*OrderEvent {
+	events := c.events
+	c.events = nil
+	c.pending = map[common.Hash]int{}
+	return events
+}
+
+// Len returns the number of buffered events.
+func (c *OrderEventCoalescer) Len() int {
+	return len(c.events)
+}
+
+// coalesceOrderEvents returns a single event which represents prev followed by
+// next, where next is a fill update for the same order. The result has the
+// fillable amount, timestamp and sequence number of next and the contract
+// events of both. If prev is an ADDED event, the result is also an ADDED event
+// since subscribers have not yet been told about the order.
+func coalesceOrderEvents(prev, next *OrderEvent) *OrderEvent {
+	endState := next.EndState
+	if prev.EndState == ESOrderAdded {
+		endState = ESOrderAdded
+	}
+	contractEvents := make([]*ContractEvent, 0, len(prev.ContractEvents)+len(next.ContractEvents))
+	contractEvents = append(contractEvents, prev.ContractEvents...)
+	contractEvents = append(contractEvents, next.ContractEvents...)
+	return &OrderEvent{
+		Timestamp:                next.Timestamp,
+		OrderHash:                next.OrderHash,
+		SignedOrder:              next.SignedOrder,
+		EndState:                 endState,
+		FillableTakerAssetAmount: next.FillableTakerAssetAmount,
+		ContractEvents:           contractEvents,
+		SequenceNumber:           next.SequenceNumber,
+		Epoch:                    next.Epoch,
+	}
+}
@@ -0,0 +1,53 @@
+package zeroex
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEventCoalescer(t *testing.T) {
+	hashA := common.HexToHash("0xa")
+	hashB := common.HexToHash("0xb")
+	fillEventA := &ContractEvent{Kind: "ExchangeFillEvent", LogIndex: 1}
+	fillEventB := &ContractEvent{Kind: "ExchangeFillEvent", LogIndex: 2}
+
+	coalescer := NewOrderEventCoalescer()
+	coalescer.Add([]*OrderEvent{
+		{OrderHash: hashA, EndState: ESOrderAdded, FillableTakerAssetAmount: big.NewInt(100), SequenceNumber: 1},
+		{OrderHash: hashB, EndState: ESOrderFilled, FillableTakerAssetAmount: big.NewInt(90), ContractEvents: []*ContractEvent{fillEventA}},
+	})
+	coalescer.Add([]*OrderEvent{
+		{OrderHash: hashA, EndState: ESOrderFilled, FillableTakerAssetAmount: big.NewInt(80), SequenceNumber: 3},
+		{OrderHash: hashB, EndState: ESOrderFilled, FillableTakerAssetAmount: big.NewInt(70), ContractEvents: []*ContractEvent{fillEventB}},
+		{OrderHash: hashB, EndState: ESOrderCancelled, FillableTakerAssetAmount: big.NewInt(0)},
+		{OrderHash: hashB, EndState: ESOrderFilled, FillableTakerAssetAmount: big.NewInt(60)},
+	})
+	require.Equal(t, 4, coalescer.Len())
+
+	events := coalescer.Flush()
+	require.Len(t, events, 4)
+
+	// The fill update for order A is coalesced into the ADDED event.
+	assert.Equal(t, hashA, events[0].OrderHash)
+	assert.Equal(t, ESOrderAdded, events[0].EndState)
+	assert.Equal(t, big.NewInt(80), events[0].FillableTakerAssetAmount)
+	assert.Equal(t, uint64(3), events[0].SequenceNumber)
+
+	// Both fill updates for order B are coalesced into one event.
+	assert.Equal(t, hashB, events[1].OrderHash)
+	assert.Equal(t, ESOrderFilled, events[1].EndState)
+	assert.Equal(t, big.NewInt(70), events[1].FillableTakerAssetAmount)
+	assert.Equal(t, []*ContractEvent{fillEventA, fillEventB}, events[1].ContractEvents)
+
+	// Events after the cancellation are not coalesced with events before it.
+	assert.Equal(t, ESOrderCancelled, events[2].EndState)
+	assert.Equal(t, ESOrderFilled, events[3].EndState)
+	assert.Equal(t, big.NewInt(60), events[3].FillableTakerAssetAmount)
+
+	assert.Equal(t, 0, coalescer.Len())
+	assert.Empty(t, coalescer.Flush())
+}
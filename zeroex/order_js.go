@@ -23,6 +23,8 @@ func (o OrderEvent) JSValue() js.Value {
 		"endState":                 string(o.EndState),
 		"fillableTakerAssetAmount": o.FillableTakerAssetAmount.String(),
 		"contractEvents":           contractEventsJS,
+		"sequenceNumber":           o.SequenceNumber,
+		"epoch":                    o.Epoch,
 	})
 }
 
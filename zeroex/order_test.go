@@ -73,6 +73,29 @@ func TestSignOrder(t *testing.T) {
 	assert.Equal(t, expectedSignature, actualSignature)
 }
 
+func TestUnmarshalSignedOrderNormalizesSignature(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	canonicalSignature := "0x1befcf4b6b1da4d207067a4b06e9bfbf21f85e2b6644f3ecf3a15f009e484756f251e3e00e909447ce45a16c620d14920a9acf516d9f4fe45bc36c914be6c9ec2703"
+	// The EIP-2098 compact form of the same signature. Since V is 27, the Y
+	// parity bit is 0 and the compact form is simply [R || S || type].
+	compactSignature := "0xefcf4b6b1da4d207067a4b06e9bfbf21f85e2b6644f3ecf3a15f009e484756f251e3e00e909447ce45a16c620d14920a9acf516d9f4fe45bc36c914be6c9ec2703"
+
+	signedOrder.Signature = common.FromHex(compactSignature)
+	encoded, err := json.Marshal(signedOrder)
+	require.NoError(t, err)
+	var decoded SignedOrder
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, canonicalSignature, fmt.Sprintf("0x%s", common.Bytes2Hex(decoded.Signature)))
+
+	// The canonical signature should be emitted when the order is encoded again.
+	reencoded, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	var signedOrderJSON SignedOrderJSON
+	require.NoError(t, json.Unmarshal(reencoded, &signedOrderJSON))
+	assert.Equal(t, canonicalSignature, signedOrderJSON.Signature)
+}
+
 func TestMarshalUnmarshalOrderEvent(t *testing.T) {
 	signedOrder, err := SignTestOrder(testOrder)
 	require.NoError(t, err)
@@ -84,6 +107,8 @@ func TestMarshalUnmarshalOrderEvent(t *testing.T) {
 		SignedOrder:              signedOrder,
 		EndState:                 ESOrderAdded,
 		FillableTakerAssetAmount: big.NewInt(2000),
+		SequenceNumber:           42,
+		Epoch:                    "2d9b7f5e-8a38-4e53-9d4c-5b0f0e6f3c1a",
 		ContractEvents: []*ContractEvent{
 			{
 				BlockHash: common.HexToHash("0x3fcd58a6613265e2b0deba902d7ff693f330a0af6e5b04805b44bbffd8a415d4"),
@@ -0,0 +1,193 @@
+package ordervalidator
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	log "github.com/sirupsen/logrus"
+)
+
+// AssetValidator is an extension point which allows Mesh to accept orders for
+// asset types that it does not support natively (e.g. assets whose ownership
+// is attested off-chain). Each AssetValidator decides which assetData it is
+// responsible for. Orders involving that assetData are still validated with
+// DevUtils.getOrderRelevantStates, so that orders which are expired, fully
+// filled, cancelled or have an invalid signature are rejected as usual. Since
+// DevUtils can't determine balances and allowances of custom assets, the
+// AssetValidator is then responsible for determining the fillable amount of
+// the remaining orders. Mesh cannot watch for contract events that affect
+// custom assets, so these orders are only re-validated during periodic order
+// cleanup.
+type AssetValidator interface {
+	// SupportsAssetData returns true if the AssetValidator is responsible for
+	// the given assetData. It is only called for assetData that Mesh does not
+	// support natively and it must not block.
+	SupportsAssetData(assetData []byte) bool
+	// ValidateOrders returns one result for each of the given orders, in the
+	// same order. It is only called for orders which passed on-chain
+	// validation. blockNumber is the block number at which the orders are being
+	// validated (or nil for the latest block). If an error is returned, all the
+	// orders are rejected with ROCustomAssetValidationFailed.
+	ValidateOrders(ctx context.Context, signedOrders []*zeroex.SignedOrder, blockNumber *big.Int) ([]*AssetValidationResult, error)
+}
+
+var errWrongNumberOfResults = errors.New("custom asset validator returned the wrong number of results")
+
+// AssetValidationResult is the result of validating a single order with an
+// AssetValidator.
+type AssetValidationResult struct {
+	// FillableTakerAssetAmount is the amount of the taker asset that can
+	// currently be filled. If it is nil or zero, the order is rejected with
+	// ROUnfunded. It is capped at the amount which hasn't been filled yet.
+	FillableTakerAssetAmount *big.Int
+	// RejectedStatus, if non-nil, causes the order to be rejected with the
+	// given status regardless of FillableTakerAssetAmount.
+	RejectedStatus *RejectedOrderStatus
+}
+
+// RegisterAssetValidator registers a custom AssetValidator. AssetValidators
+// are consulted in the order they were registered, and the first one which
+// supports any of an order's assetData fields is used to validate the order.
+func (o *OrderValidator) RegisterAssetValidator(assetValidator AssetValidator) {
+	o.assetValidatorsMu.Lock()
+	defer o.assetValidatorsMu.Unlock()
+	o.assetValidators = append(o.assetValidators, assetValidator)
+}
+
+// isSupportedByAssetValidator returns true if any registered AssetValidator
+// supports the given assetData.
+func (o *OrderValidator) isSupportedByAssetValidator(assetData []byte) bool {
+	o.assetValidatorsMu.RLock()
+	defer o.assetValidatorsMu.RUnlock()
+	for _, assetValidator := range o.assetValidators {
+		if assetValidator.SupportsAssetData(assetData) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCustomAssetData returns true if the given assetData is not supported
+// natively and is instead handled by a registered AssetValidator.
+func (o *OrderValidator) IsCustomAssetData(assetData []byte) bool {
+	return !o.isNativelySupportedAssetData(assetData) && o.isSupportedByAssetValidator(assetData)
+}
+
+// assetValidatorForOrder returns the index of the AssetValidator responsible
+// for the given order, or -1 if all of the order's assetData is supported
+// natively.
+func (o *OrderValidator) assetValidatorForOrder(signedOrder *zeroex.SignedOrder, assetValidators []AssetValidator) int {
+	if len(assetValidators) == 0 {
+		return -1
+	}
+	for _, assetData := range [][]byte{
+		signedOrder.MakerAssetData,
+		signedOrder.TakerAssetData,
+		signedOrder.MakerFeeAssetData,
+		signedOrder.TakerFeeAssetData,
+	} {
+		if len(assetData) == 0 || o.isNativelySupportedAssetData(assetData) {
+			continue
+		}
+		for i, assetValidator := range assetValidators {
+			if assetValidator.SupportsAssetData(assetData) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// batchValidateCustomAssets validates the given orders, which passed on-chain
+// validation, with the AssetValidators responsible for them.
+// remainingTakerAssetAmounts contains the unfilled taker asset amount of each
+// order, which caps its fillable amount.
+func (o *OrderValidator) batchValidateCustomAssets(ctx context.Context, signedOrders []*zeroex.SignedOrder, remainingTakerAssetAmounts map[common.Hash]*big.Int, assetValidators []AssetValidator, areNewOrders bool, blockNumber *big.Int) ([]*AcceptedOrderInfo, []*RejectedOrderInfo) {
+	acceptedOrderInfos := []*AcceptedOrderInfo{}
+	rejectedOrderInfos := []*RejectedOrderInfo{}
+
+	// Group orders by the AssetValidator responsible for them.
+	ordersByValidator := make([][]*zeroex.SignedOrder, len(assetValidators))
+	for _, signedOrder := range signedOrders {
+		i := o.assetValidatorForOrder(signedOrder, assetValidators)
+		if i == -1 {
+			continue
+		}
+		ordersByValidator[i] = append(ordersByValidator[i], signedOrder)
+	}
+
+	for i, assetValidator := range assetValidators {
+		ordersForValidator := ordersByValidator[i]
+		if len(ordersForValidator) == 0 {
+			continue
+		}
+		results, err := assetValidator.ValidateOrders(ctx, ordersForValidator, blockNumber)
+		if err == nil && len(results) != len(ordersForValidator) {
+			log.WithFields(log.Fields{
+				"numOrders":  len(ordersForValidator),
+				"numResults": len(results),
+			}).Error("custom asset validator returned the wrong number of results")
+			err = errWrongNumberOfResults
+		} else if err != nil {
+			log.WithFields(log.Fields{
+				"error":     err.Error(),
+				"numOrders": len(ordersForValidator),
+			}).Warn("custom asset validator failed to validate orders")
+		}
+		for j, signedOrder := range ordersForValidator {
+			orderHash, hashErr := signedOrder.ComputeOrderHash()
+			if hashErr != nil {
+				log.WithError(hashErr).WithField("signedOrder", signedOrder).Error("Computing the orderHash failed unexpectedly")
+			}
+			if err != nil {
+				rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: signedOrder,
+					Kind:        MeshError,
+					Status:      ROCustomAssetValidationFailed,
+				})
+				continue
+			}
+			result := results[j]
+			switch {
+			case result == nil:
+				rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: signedOrder,
+					Kind:        MeshError,
+					Status:      ROCustomAssetValidationFailed,
+				})
+			case result.RejectedStatus != nil:
+				rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: signedOrder,
+					Kind:        ZeroExValidation,
+					Status:      *result.RejectedStatus,
+				})
+			case result.FillableTakerAssetAmount == nil || result.FillableTakerAssetAmount.Sign() <= 0:
+				rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: signedOrder,
+					Kind:        ZeroExValidation,
+					Status:      ROUnfunded,
+				})
+			default:
+				fillableTakerAssetAmount := result.FillableTakerAssetAmount
+				if remaining, found := remainingTakerAssetAmounts[orderHash]; found && fillableTakerAssetAmount.Cmp(remaining) > 0 {
+					fillableTakerAssetAmount = remaining
+				}
+				acceptedOrderInfos = append(acceptedOrderInfos, &AcceptedOrderInfo{
+					OrderHash:                orderHash,
+					SignedOrder:              signedOrder,
+					FillableTakerAssetAmount: fillableTakerAssetAmount,
+					IsNew:                    areNewOrders,
+				})
+			}
+		}
+	}
+
+	return acceptedOrderInfos, rejectedOrderInfos
+}
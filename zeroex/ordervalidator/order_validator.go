@@ -31,6 +31,26 @@ import (
 // Additional requests will block until an ongoing request has completed.
 const concurrencyLimit = 5
 
+// signatureRecoveryWorkers is the number of goroutines used to recover the
+// signers of new orders. Zero means one goroutine per CPU.
+const signatureRecoveryWorkers = 0
+
+// batchSizeLimitErrorMessages are substrings of the errors returned by Ethereum RPC providers when an
+// `eth_call` is too large for them to handle, either because it exceeds their gas limit or because the
+// request or response is too large. `getOrderRelevantStates` requests which fail with one of these
+// errors are split in half and retried.
+var batchSizeLimitErrorMessages = []string{
+	"out of gas",
+	"gas required exceeds",
+	"exceeds block gas limit",
+	"gas limit reached",
+	"response size",
+	"response too large",
+	"request entity too large",
+	"payload too large",
+	"content length too large",
+}
+
 // RejectedOrderInfo encapsulates all the needed information to understand _why_ a 0x order
 // was rejected (i.e. did not pass) order validation. Since there are many potential reasons, some
 // Mesh-specific, others 0x-specific and others due to external factors (i.e., network
@@ -41,6 +61,8 @@ type RejectedOrderInfo struct {
 	SignedOrder *zeroex.SignedOrder `json:"signedOrder"`
 	Kind        RejectedOrderKind   `json:"kind"`
 	Status      RejectedOrderStatus `json:"status"`
+	// Trace is only set if tracing was enabled (see WithTracing).
+	Trace *ValidationTrace `json:"trace,omitempty"`
 }
 
 // AcceptedOrderInfo represents an fillable order and how much it could be filled for
@@ -49,6 +71,8 @@ type AcceptedOrderInfo struct {
 	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
 	FillableTakerAssetAmount *big.Int            `json:"fillableTakerAssetAmount"`
 	IsNew                    bool                `json:"isNew"`
+	// Trace is only set if tracing was enabled (see WithTracing).
+	Trace *ValidationTrace `json:"trace,omitempty"`
 }
 
 type acceptedOrderInfoJSON struct {
@@ -56,16 +80,21 @@ type acceptedOrderInfoJSON struct {
 	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
 	FillableTakerAssetAmount string              `json:"fillableTakerAssetAmount"`
 	IsNew                    bool                `json:"isNew"`
+	Trace                    *ValidationTrace    `json:"trace,omitempty"`
 }
 
 // MarshalJSON is a custom Marshaler for AcceptedOrderInfo
 func (a AcceptedOrderInfo) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	fields := map[string]interface{}{
 		"orderHash":                a.OrderHash.Hex(),
 		"signedOrder":              a.SignedOrder,
 		"fillableTakerAssetAmount": a.FillableTakerAssetAmount.String(),
 		"isNew":                    a.IsNew,
-	})
+	}
+	if a.Trace != nil {
+		fields["trace"] = a.Trace
+	}
+	return json.Marshal(fields)
 }
 
 // UnmarshalJSON implements a custom JSON unmarshaller for the OrderEvent type
@@ -79,6 +108,7 @@ func (a *AcceptedOrderInfo) UnmarshalJSON(data []byte) error {
 	a.OrderHash = common.HexToHash(acceptedOrderInfoJSON.OrderHash)
 	a.SignedOrder = acceptedOrderInfoJSON.SignedOrder
 	a.IsNew = acceptedOrderInfoJSON.IsNew
+	a.Trace = acceptedOrderInfoJSON.Trace
 	var ok bool
 	a.FillableTakerAssetAmount, ok = math.ParseBig256(acceptedOrderInfoJSON.FillableTakerAssetAmount)
 	if !ok {
@@ -187,6 +217,40 @@ var (
 		Code:    "DatabaseFullOfOrders",
 		Message: "database is full of pinned orders and no orders can be deleted to make space (consider increasing MAX_ORDERS_IN_STORAGE)",
 	}
+	ROCustomAssetValidationFailed = RejectedOrderStatus{
+		Code:    "CustomAssetValidationFailed",
+		Message: "the custom asset validator for this order's assetData failed to validate it",
+	}
+	ROFeeRecipientNotAllowed = RejectedOrderStatus{
+		Code:    "FeeRecipientNotAllowed",
+		Message: "the feeRecipientAddress of this order is not allowed by this Mesh node's fee policy",
+	}
+	ROMaxFeeExceeded = RejectedOrderStatus{
+		Code:    "MaxFeeExceeded",
+		Message: "the makerFee or takerFee of this order exceeds the maximum allowed by this Mesh node's fee policy",
+	}
+	ROOrderTooSmall = RejectedOrderStatus{
+		Code:    "OrderTooSmall",
+		Message: "the makerAssetAmount, takerAssetAmount or USD value of this order is below the minimum allowed by this Mesh node's size policy",
+	}
+	ROOrderNotStored = RejectedOrderStatus{
+		Code:    "OrderNotStored",
+		Message: "order is not stored by this Mesh node and therefore cannot be re-validated",
+	}
+	// ROOrderPolicyRejected is the status for orders which were rejected by an
+	// operator-defined order policy. If the policy gave a reason, it is appended
+	// to the message.
+	ROOrderPolicyRejected = RejectedOrderStatus{
+		Code:    "OrderPolicyRejected",
+		Message: "order was rejected by this Mesh node's order policy",
+	}
+	// ROValidationTimedOut is the status for orders which could not be
+	// validated and stored before a stage timeout (see StageTimeouts) elapsed
+	// or the request was canceled.
+	ROValidationTimedOut = RejectedOrderStatus{
+		Code:    "ValidationTimedOut",
+		Message: "order could not be validated and stored before the deadline of the request",
+	}
 )
 
 // ROInvalidSchemaCode is the RejectedOrderStatus emitted if an order doesn't conform to the order schema
@@ -233,6 +297,8 @@ type ValidationResults struct {
 // OrderValidator validates 0x orders
 type OrderValidator struct {
 	maxRequestContentLength      int
+	maxOrdersPerBatchMu          sync.RWMutex
+	maxOrdersPerBatch            int
 	devUtilsABI                  abi.ABI
 	devUtils                     *wrappers.DevUtilsCaller
 	coordinatorRegistry          *wrappers.CoordinatorRegistryCaller
@@ -240,6 +306,8 @@ type OrderValidator struct {
 	chainID                      int
 	cachedFeeRecipientToEndpoint map[common.Address]string
 	contractAddresses            ethereum.ContractAddresses
+	assetValidatorsMu            sync.RWMutex
+	assetValidators              []AssetValidator
 }
 
 // New instantiates a new order validator
@@ -270,6 +338,23 @@ func New(contractCaller bind.ContractCaller, chainID int, maxRequestContentLengt
 	}, nil
 }
 
+// SetMaxOrdersPerBatch sets the maximum number of orders validated in a single
+// `getOrderRelevantStates` request. If zero (the default), the number of orders
+// per request is only limited by the max request content length. Requests which
+// the Ethereum RPC endpoint rejects for being too large are split in half
+// automatically either way.
+func (o *OrderValidator) SetMaxOrdersPerBatch(maxOrdersPerBatch int) {
+	o.maxOrdersPerBatchMu.Lock()
+	defer o.maxOrdersPerBatchMu.Unlock()
+	o.maxOrdersPerBatch = maxOrdersPerBatch
+}
+
+func (o *OrderValidator) getMaxOrdersPerBatch() int {
+	o.maxOrdersPerBatchMu.RLock()
+	defer o.maxOrdersPerBatchMu.RUnlock()
+	return o.maxOrdersPerBatch
+}
+
 // BatchValidate retrieves all the information needed to validate the supplied orders.
 // It splits the orders into chunks of `chunkSize`, and makes no more then `concurrencyLimit`
 // requests concurrently. If a request fails, re-attempt it up to four times before giving up.
@@ -286,6 +371,16 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 		Accepted: []*AcceptedOrderInfo{},
 		Rejected: rejectedOrderInfos,
 	}
+	tracer := newTraceRecorder(ctx)
+	defer tracer.attach(validationResults)
+
+	// The signatures of orders we have already stored never change, so we only
+	// need to verify them for new orders.
+	if areNewOrders {
+		var signatureRejectedOrderInfos []*RejectedOrderInfo
+		offchainValidSignedOrders, signatureRejectedOrderInfos = o.batchValidateSignatures(offchainValidSignedOrders, tracer)
+		validationResults.Rejected = append(validationResults.Rejected, signatureRejectedOrderInfos...)
+	}
 
 	// Validate Coordinator orders for soft-cancels
 	signedOrders, coordinatorRejectedOrderInfos := o.batchValidateSoftCancelled(ctx, offchainValidSignedOrders)
@@ -293,16 +388,52 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 		validationResults.Rejected = append(validationResults.Rejected, rejectedOrderInfo)
 	}
 
+	// Orders involving custom asset types are validated on-chain like any
+	// other order, except that DevUtils can't determine their fillable amount.
+	// The ones which pass are validated by the corresponding AssetValidator
+	// afterwards. They are sent in separate requests so that a request can't
+	// fail for native orders because of an unusual custom assetData.
+	o.assetValidatorsMu.RLock()
+	assetValidators := o.assetValidators
+	o.assetValidatorsMu.RUnlock()
+	nativeSignedOrders := []*zeroex.SignedOrder{}
+	customSignedOrders := []*zeroex.SignedOrder{}
+	for _, signedOrder := range signedOrders {
+		if o.assetValidatorForOrder(signedOrder, assetValidators) == -1 {
+			nativeSignedOrders = append(nativeSignedOrders, signedOrder)
+		} else {
+			customSignedOrders = append(customSignedOrders, signedOrder)
+		}
+	}
+	isCustomSignedOrder := make(map[*zeroex.SignedOrder]bool, len(customSignedOrders))
+	for _, signedOrder := range customSignedOrders {
+		isCustomSignedOrder[signedOrder] = true
+	}
+	// fillableCustomOrders are the custom orders which are fillable according
+	// to DevUtils, along with the remaining (i.e. unfilled) taker asset amount
+	// of each of them. They are protected by fillableCustomOrdersMu.
+	fillableCustomOrdersMu := sync.Mutex{}
+	fillableCustomOrders := []*zeroex.SignedOrder{}
+	remainingTakerAssetAmounts := map[common.Hash]*big.Int{}
+
 	signedOrderChunks := [][]*zeroex.SignedOrder{}
-	chunkSizes := o.computeOptimalChunkSizes(signedOrders)
-	for _, chunkSize := range chunkSizes {
-		signedOrderChunks = append(signedOrderChunks, signedOrders[:chunkSize])
-		signedOrders = signedOrders[chunkSize:]
+	for _, signedOrders := range [][]*zeroex.SignedOrder{nativeSignedOrders, customSignedOrders} {
+		if len(signedOrders) == 0 {
+			continue
+		}
+		chunkSizes := o.computeOptimalChunkSizes(signedOrders)
+		for _, chunkSize := range chunkSizes {
+			signedOrderChunks = append(signedOrderChunks, signedOrders[:chunkSize])
+			signedOrders = signedOrders[chunkSize:]
+		}
 	}
 
 	semaphoreChan := make(chan struct{}, concurrencyLimit)
 	defer close(semaphoreChan)
 
+	// validationResultsMu protects validationResults, which is updated by
+	// all of the goroutines below.
+	validationResultsMu := sync.Mutex{}
 	wg := &sync.WaitGroup{}
 	for i, signedOrders := range signedOrderChunks {
 		wg.Add(1)
@@ -318,9 +449,32 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 
 			defer wg.Done()
 
+			rejectAll := func(status RejectedOrderStatus) {
+				validationResultsMu.Lock()
+				defer validationResultsMu.Unlock()
+				for _, signedOrder := range signedOrders {
+					orderHash, err := signedOrder.ComputeOrderHash()
+					if err != nil {
+						log.WithField("error", err).Error("Unexpectedly failed to generate orderHash")
+						continue
+					}
+					validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
+						OrderHash:   orderHash,
+						SignedOrder: signedOrder,
+						Kind:        MeshError,
+						Status:      status,
+					})
+				}
+			}
+
 			// Add one to the semaphore chan. If it already has concurrencyLimit values,
-			// the request blocks here until one frees up.
-			semaphoreChan <- struct{}{}
+			// the request blocks here until one frees up or ctx is done.
+			select {
+			case semaphoreChan <- struct{}{}:
+			case <-ctx.Done():
+				rejectAll(ROValidationTimedOut)
+				return
+			}
 
 			// Attempt to make the eth_call request 4 times with an exponential back-off.
 			maxDuration := 4 * time.Second
@@ -341,13 +495,20 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 				}
 				opts.BlockNumber = blockNumber
 
-				results, err := o.devUtils.GetOrderRelevantStates(opts, trimmedOrders, signatures)
+				results, err := o.getOrderRelevantStates(opts, signedOrders, trimmedOrders, signatures, tracer)
 				if err != nil {
 					log.WithFields(log.Fields{
 						"error":     err.Error(),
 						"attempt":   b.Attempt(),
 						"numOrders": len(trimmedOrders),
 					}).Info("GetOrderRelevantStates request failed")
+					// Retrying is pointless once ctx is done (e.g. because the eth
+					// validation stage timed out or the request was canceled).
+					if ctx.Err() != nil {
+						<-semaphoreChan
+						rejectAll(ROValidationTimedOut)
+						return
+					}
 					d := b.Duration()
 					if d == maxDuration {
 						<-semaphoreChan
@@ -369,22 +530,13 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 							}
 						}
 						log.WithFields(fields).Warning("Gave up on GetOrderRelevantStates request after backoff limit reached")
-						for _, signedOrder := range signedOrders {
-							orderHash, err := signedOrder.ComputeOrderHash()
-							if err != nil {
-								log.WithField("error", err).Error("Unexpectedly failed to generate orderHash")
-								continue
-							}
-							validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
-								OrderHash:   orderHash,
-								SignedOrder: signedOrder,
-								Kind:        MeshError,
-								Status:      ROEthRPCRequestFailed,
-							})
-						}
+						rejectAll(ROEthRPCRequestFailed)
 						return // Give up after 4 attempts
 					}
-					time.Sleep(d)
+					select {
+					case <-time.After(d):
+					case <-ctx.Done():
+					}
 					continue
 				}
 
@@ -410,17 +562,27 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 						case zeroex.OSSignatureInvalid:
 							status = ROInvalidSignature
 						}
+						validationResultsMu.Lock()
 						validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
 							OrderHash:   orderHash,
 							SignedOrder: signedOrder,
 							Kind:        ZeroExValidation,
 							Status:      status,
 						})
+						validationResultsMu.Unlock()
 						continue
 					case zeroex.OSFillable:
 						remainingTakerAssetAmount := big.NewInt(0).Sub(signedOrder.TakerAssetAmount, orderInfo.OrderTakerAssetFilledAmount)
+						if isCustomSignedOrder[signedOrder] {
+							fillableCustomOrdersMu.Lock()
+							fillableCustomOrders = append(fillableCustomOrders, signedOrder)
+							remainingTakerAssetAmounts[orderHash] = remainingTakerAssetAmount
+							fillableCustomOrdersMu.Unlock()
+							continue
+						}
 						// If `fillableTakerAssetAmount` != `remainingTakerAssetAmount`, the order is partially fillable. We consider
 						// partially fillable orders as invalid
+						validationResultsMu.Lock()
 						if fillableTakerAssetAmount.Cmp(remainingTakerAssetAmount) != 0 {
 							validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
 								OrderHash:   orderHash,
@@ -436,6 +598,7 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 								IsNew:                    areNewOrders,
 							})
 						}
+						validationResultsMu.Unlock()
 						continue
 					}
 				}
@@ -447,9 +610,69 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 	}
 
 	wg.Wait()
+
+	if len(fillableCustomOrders) > 0 {
+		customAcceptedOrderInfos, customRejectedOrderInfos := o.batchValidateCustomAssets(ctx, fillableCustomOrders, remainingTakerAssetAmounts, assetValidators, areNewOrders, blockNumber)
+		validationResults.Accepted = append(validationResults.Accepted, customAcceptedOrderInfos...)
+		validationResults.Rejected = append(validationResults.Rejected, customRejectedOrderInfos...)
+	}
 	return validationResults
 }
 
+// orderRelevantStates holds the results of a `getOrderRelevantStates` request.
+type orderRelevantStates struct {
+	OrdersInfo                []wrappers.OrderInfo
+	FillableTakerAssetAmounts []*big.Int
+	IsValidSignature          []bool
+}
+
+// getOrderRelevantStates calls `getOrderRelevantStates` on the DevUtils contract. If the Ethereum RPC
+// endpoint rejects the request for being too large, it splits the orders in half and retries each
+// half recursively, merging the results. Any other error is returned as is.
+func (o *OrderValidator) getOrderRelevantStates(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder, trimmedOrders []wrappers.TrimmedOrder, signatures [][]byte, tracer *traceRecorder) (*orderRelevantStates, error) {
+	ethCallStart := time.Now()
+	results, err := o.devUtils.GetOrderRelevantStates(opts, trimmedOrders, signatures)
+	tracer.recordEthCall(signedOrders, time.Since(ethCallStart))
+	if err == nil {
+		return &orderRelevantStates{
+			OrdersInfo:                results.OrdersInfo,
+			FillableTakerAssetAmounts: results.FillableTakerAssetAmounts,
+			IsValidSignature:          results.IsValidSignature,
+		}, nil
+	}
+	if len(trimmedOrders) <= 1 || !isBatchSizeLimitError(err) {
+		return nil, err
+	}
+	log.WithFields(log.Fields{
+		"error":     err.Error(),
+		"numOrders": len(trimmedOrders),
+	}).Debug("GetOrderRelevantStates request was too large; splitting it in half")
+	half := len(trimmedOrders) / 2
+	firstHalf, err := o.getOrderRelevantStates(opts, signedOrders[:half], trimmedOrders[:half], signatures[:half], tracer)
+	if err != nil {
+		return nil, err
+	}
+	secondHalf, err := o.getOrderRelevantStates(opts, signedOrders[half:], trimmedOrders[half:], signatures[half:], tracer)
+	if err != nil {
+		return nil, err
+	}
+	return &orderRelevantStates{
+		OrdersInfo:                append(firstHalf.OrdersInfo, secondHalf.OrdersInfo...),
+		FillableTakerAssetAmounts: append(firstHalf.FillableTakerAssetAmounts, secondHalf.FillableTakerAssetAmounts...),
+		IsValidSignature:          append(firstHalf.IsValidSignature, secondHalf.IsValidSignature...),
+	}, nil
+}
+
+func isBatchSizeLimitError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, substring := range batchSizeLimitErrorMessages {
+		if strings.Contains(message, substring) {
+			return true
+		}
+	}
+	return false
+}
+
 type softCancelResponse struct {
 	OrderHashes []common.Hash `json:"orderHashes"`
 }
@@ -734,7 +957,53 @@ func (o *OrderValidator) BatchOffchainValidation(signedOrders []*zeroex.SignedOr
 	return offchainValidSignedOrders, rejectedOrderInfos
 }
 
+// batchValidateSignatures recovers the signers of all EIP712 and EthSign
+// signatures in parallel and rejects any orders that were not signed by the
+// maker. Signer recovery is CPU intensive, so doing it across multiple
+// goroutines significantly increases the rate at which new orders can be
+// ingested (e.g. during ordersync). Other signature types can only be validated
+// on-chain.
+func (o *OrderValidator) batchValidateSignatures(signedOrders []*zeroex.SignedOrder, tracer *traceRecorder) ([]*zeroex.SignedOrder, []*RejectedOrderInfo) {
+	rejectedOrderInfos := []*RejectedOrderInfo{}
+	validSignedOrders := []*zeroex.SignedOrder{}
+	recoverableSignedOrders := []*zeroex.SignedOrder{}
+	for _, signedOrder := range signedOrders {
+		if zeroex.IsRecoverableSignature(signedOrder.Signature) {
+			recoverableSignedOrders = append(recoverableSignedOrders, signedOrder)
+		} else {
+			validSignedOrders = append(validSignedOrders, signedOrder)
+		}
+	}
+
+	results := zeroex.BatchRecoverSigners(recoverableSignedOrders, signatureRecoveryWorkers)
+	for i, result := range results {
+		signedOrder := recoverableSignedOrders[i]
+		tracer.recordSignatureValidation(signedOrder, result.Duration)
+		if result.Err == nil && result.Signer == signedOrder.MakerAddress {
+			validSignedOrders = append(validSignedOrders, signedOrder)
+			continue
+		}
+		orderHash, err := signedOrder.ComputeOrderHash()
+		if err != nil {
+			log.WithError(err).WithField("signedOrder", signedOrder).Error("Computing the orderHash failed unexpectedly")
+		}
+		rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+			OrderHash:   orderHash,
+			SignedOrder: signedOrder,
+			Kind:        ZeroExValidation,
+			Status:      ROInvalidSignature,
+		})
+	}
+	return validSignedOrders, rejectedOrderInfos
+}
+
+// isSupportedAssetData returns true if the given assetData is either supported
+// natively or by a registered AssetValidator.
 func (o *OrderValidator) isSupportedAssetData(assetData []byte) bool {
+	return o.isNativelySupportedAssetData(assetData) || o.isSupportedByAssetValidator(assetData)
+}
+
+func (o *OrderValidator) isNativelySupportedAssetData(assetData []byte) bool {
 	assetDataName, err := o.assetDataDecoder.GetName(assetData)
 	if err != nil {
 		return false
@@ -859,15 +1128,18 @@ func (o *OrderValidator) computeABIEncodedSignedOrderByteLength(signedOrder *zer
 // computeOptimalChunkSizes splits the signedOrders into chunks where the payload size of each chunk
 // is beneath the maxRequestContentLength. It does this by implementing a greedy algorithm which ABI
 // encodes signedOrders one at a time until the computed payload size is as close to the
-// maxRequestContentLength as possible.
+// maxRequestContentLength as possible. If a max number of orders per batch is set, no chunk contains
+// more orders than that.
 func (o *OrderValidator) computeOptimalChunkSizes(signedOrders []*zeroex.SignedOrder) []int {
 	chunkSizes := []int{}
+	maxOrdersPerBatch := o.getMaxOrdersPerBatch()
 
 	payloadLength := jsonRPCPayloadByteLength
 	nextChunkSize := 0
 	for _, signedOrder := range signedOrders {
 		encodedSignedOrderByteLength, _ := o.computeABIEncodedSignedOrderByteLength(signedOrder)
-		if payloadLength+encodedSignedOrderByteLength < o.maxRequestContentLength {
+		isChunkFull := maxOrdersPerBatch > 0 && nextChunkSize >= maxOrdersPerBatch
+		if !isChunkFull && payloadLength+encodedSignedOrderByteLength < o.maxRequestContentLength {
 			payloadLength += encodedSignedOrderByteLength
 			nextChunkSize++
 		} else {
@@ -898,14 +1170,13 @@ func isSupportedSignature(signature []byte, orderHash common.Hash) bool {
 		if len(signature) != 66 {
 			return false
 		}
-		// TODO(fabio): Do further validation by splitting into r,s,v and do ECRecover
+		// The signer is checked in batchValidateSignatures.
 
 	case zeroex.EthSignSignature:
 		if len(signature) != 66 {
 			return false
 		}
-		// TODO(fabio): Do further validation by splitting into r,s,v, add prefix to hash
-		// and do ECRecover
+		// The signer is checked in batchValidateSignatures.
 
 	case zeroex.ValidatorSignature:
 		if len(signature) < 21 {
@@ -5,7 +5,9 @@
 package ordervalidator
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"math/big"
@@ -421,6 +423,36 @@ func TestComputeOptimalChunkSizes(t *testing.T) {
 	assert.Equal(t, expectedChunkSizes, chunkSizes)
 }
 
+func TestComputeOptimalChunkSizesMaxOrdersPerBatch(t *testing.T) {
+	signedOrder := scenario.NewSignedTestOrder(t)
+	maxContentLength := singleOrderPayloadSize * 3
+	orderValidator, err := New(ethRPCClient, constants.TestChainID, maxContentLength, ganacheAddresses)
+	require.NoError(t, err)
+	orderValidator.SetMaxOrdersPerBatch(2)
+
+	signedOrders := []*zeroex.SignedOrder{signedOrder, signedOrder, signedOrder, signedOrder, signedOrder}
+	chunkSizes := orderValidator.computeOptimalChunkSizes(signedOrders)
+	expectedChunkSizes := []int{2, 2, 1}
+	assert.Equal(t, expectedChunkSizes, chunkSizes)
+}
+
+func TestIsBatchSizeLimitError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{errors.New("out of gas"), true},
+		{errors.New("gas required exceeds allowance (10000000)"), true},
+		{errors.New("413 Request Entity Too Large"), true},
+		{errors.New("Response size exceeded"), true},
+		{errors.New("VM execution error."), false},
+		{errors.New("connection refused"), false},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, isBatchSizeLimitError(testCase.err), testCase.err.Error())
+	}
+}
+
 func TestComputeOptimalChunkSizesMultiAssetOrder(t *testing.T) {
 	signedOrder := scenario.NewSignedTestOrder(t)
 	signedMultiAssetOrder := scenario.NewSignedTestOrder(t, orderopts.MakerAssetData(multiAssetAssetData))
@@ -451,3 +483,139 @@ func signedOrderWithCustomSignature(t *testing.T, signature []byte) *zeroex.Sign
 func copyOrder(order zeroex.Order) zeroex.Order {
 	return order
 }
+
+func TestBatchValidateSignatures(t *testing.T) {
+	validOrder := scenario.NewSignedTestOrder(t)
+	// Changing the maker address after signing means the signature was not
+	// produced by the maker.
+	wrongSignerOrder := scenario.NewSignedTestOrder(t)
+	wrongSignerOrder.MakerAddress = constants.GanacheAccount2
+	wrongSignerOrder.ResetHash()
+	// The signer of PreSigned signatures can only be checked on-chain.
+	preSignedOrder := scenario.NewSignedTestOrder(t)
+	preSignedOrder.Signature = []byte{byte(zeroex.PreSignedSignature)}
+
+	orderValidator := &OrderValidator{}
+	accepted, rejected := orderValidator.batchValidateSignatures([]*zeroex.SignedOrder{validOrder, wrongSignerOrder, preSignedOrder}, nil)
+	assert.ElementsMatch(t, []*zeroex.SignedOrder{validOrder, preSignedOrder}, accepted)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, wrongSignerOrder, rejected[0].SignedOrder)
+	assert.Equal(t, ROInvalidSignature, rejected[0].Status)
+}
+
+func TestValidationTracing(t *testing.T) {
+	assert.Nil(t, newTraceRecorder(context.Background()), "tracing should be disabled by default")
+
+	validOrder := scenario.NewSignedTestOrder(t)
+	tracer := newTraceRecorder(WithTracing(context.Background()))
+	require.NotNil(t, tracer)
+	orderValidator := &OrderValidator{}
+	accepted, _ := orderValidator.batchValidateSignatures([]*zeroex.SignedOrder{validOrder}, tracer)
+	require.Len(t, accepted, 1)
+	tracer.recordEthCall(accepted, time.Second)
+	tracer.recordEthCall(accepted, time.Second)
+
+	results := &ValidationResults{
+		Accepted: []*AcceptedOrderInfo{
+			{SignedOrder: validOrder},
+		},
+	}
+	tracer.attach(results)
+	trace := results.Accepted[0].Trace
+	require.NotNil(t, trace)
+	assert.True(t, trace.SignatureValidationDuration > 0, "expected SignatureValidationDuration to be set")
+	assert.Equal(t, 2, trace.EthCallCount)
+	assert.Equal(t, 2*time.Second, trace.EthCallDuration)
+}
+
+// testAssetValidator is an AssetValidator which supports assetData starting
+// with a fixed prefix and considers every order fillable for the given amount.
+type testAssetValidator struct {
+	prefix                   []byte
+	fillableTakerAssetAmount *big.Int
+	err                      error
+	validatedOrders          []*zeroex.SignedOrder
+}
+
+func (v *testAssetValidator) SupportsAssetData(assetData []byte) bool {
+	return bytes.HasPrefix(assetData, v.prefix)
+}
+
+func (v *testAssetValidator) ValidateOrders(ctx context.Context, signedOrders []*zeroex.SignedOrder, blockNumber *big.Int) ([]*AssetValidationResult, error) {
+	v.validatedOrders = append(v.validatedOrders, signedOrders...)
+	if v.err != nil {
+		return nil, v.err
+	}
+	results := make([]*AssetValidationResult, len(signedOrders))
+	for i := range signedOrders {
+		results[i] = &AssetValidationResult{FillableTakerAssetAmount: v.fillableTakerAssetAmount}
+	}
+	return results, nil
+}
+
+func TestBatchValidateCustomAssets(t *testing.T) {
+	fillableValidator := &testAssetValidator{
+		prefix:                   []byte{0xde, 0xad},
+		fillableTakerAssetAmount: big.NewInt(42),
+	}
+	unfundedValidator := &testAssetValidator{
+		prefix:                   []byte{0xbe, 0xef},
+		fillableTakerAssetAmount: big.NewInt(0),
+	}
+	failingValidator := &testAssetValidator{
+		prefix: []byte{0xfe, 0xed},
+		err:    errors.New("off-chain attestation service unavailable"),
+	}
+	orderValidator := &OrderValidator{
+		assetDataDecoder: zeroex.NewAssetDataDecoder(),
+	}
+	orderValidator.RegisterAssetValidator(fillableValidator)
+	orderValidator.RegisterAssetValidator(unfundedValidator)
+	orderValidator.RegisterAssetValidator(failingValidator)
+
+	nativeOrder := scenario.NewSignedTestOrder(t)
+	newCustomOrder := func(assetData []byte) *zeroex.SignedOrder {
+		signedOrder := scenario.NewSignedTestOrder(t)
+		signedOrder.MakerAssetData = assetData
+		signedOrder.ResetHash()
+		return signedOrder
+	}
+	fillableOrder := newCustomOrder([]byte{0xde, 0xad, 0x01})
+	unfundedOrder := newCustomOrder([]byte{0xbe, 0xef, 0x01})
+	failedOrder := newCustomOrder([]byte{0xfe, 0xed, 0x01})
+
+	assert.True(t, orderValidator.isSupportedAssetData(fillableOrder.MakerAssetData))
+	assert.False(t, orderValidator.isSupportedAssetData([]byte{0x01, 0x02}))
+	assert.True(t, orderValidator.IsCustomAssetData(fillableOrder.MakerAssetData))
+	assert.False(t, orderValidator.IsCustomAssetData(nativeOrder.MakerAssetData))
+
+	assert.Equal(t, -1, orderValidator.assetValidatorForOrder(nativeOrder, orderValidator.assetValidators))
+	assert.Equal(t, 0, orderValidator.assetValidatorForOrder(fillableOrder, orderValidator.assetValidators))
+
+	// The fillable amount is capped at the amount which hasn't been filled
+	// yet according to DevUtils.
+	fillableOrderHash, err := fillableOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	remainingTakerAssetAmounts := map[common.Hash]*big.Int{
+		fillableOrderHash: big.NewInt(10),
+	}
+	accepted, rejected := orderValidator.batchValidateCustomAssets(
+		context.Background(),
+		[]*zeroex.SignedOrder{fillableOrder, unfundedOrder, failedOrder},
+		remainingTakerAssetAmounts,
+		orderValidator.assetValidators,
+		true,
+		nil,
+	)
+	assert.Equal(t, []*zeroex.SignedOrder{fillableOrder}, fillableValidator.validatedOrders)
+	require.Len(t, accepted, 1)
+	assert.Equal(t, fillableOrder, accepted[0].SignedOrder)
+	assert.Equal(t, big.NewInt(10), accepted[0].FillableTakerAssetAmount)
+	assert.True(t, accepted[0].IsNew)
+	require.Len(t, rejected, 2)
+	assert.Equal(t, unfundedOrder, rejected[0].SignedOrder)
+	assert.Equal(t, ROUnfunded, rejected[0].Status)
+	assert.Equal(t, failedOrder, rejected[1].SignedOrder)
+	assert.Equal(t, ROCustomAssetValidationFailed, rejected[1].Status)
+	assert.Equal(t, MeshError, rejected[1].Kind)
+}
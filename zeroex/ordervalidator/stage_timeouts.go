@@ -0,0 +1,49 @@
+package ordervalidator
+
+import (
+	"context"
+	"time"
+)
+
+// StageTimeouts bound how long each stage of validating and storing new
+// orders may take. A timeout of 0 means that the stage is only bounded by the
+// deadline of the context (if any). Orders which could not make it through a
+// stage before its timeout are rejected with ROValidationTimedOut.
+type StageTimeouts struct {
+	// SchemaValidation bounds the time spent validating all of the orders
+	// against the JSON Schema.
+	SchemaValidation time.Duration
+	// EthValidation bounds the time spent validating the orders on-chain,
+	// including any time spent waiting for the Ethereum RPC rate limiter.
+	EthValidation time.Duration
+	// DBWrite bounds the time spent waiting to write the valid orders to the
+	// database. It is checked before the orders are written and before the
+	// write is committed, so a single write which has already started is not
+	// interrupted.
+	DBWrite time.Duration
+}
+
+type stageTimeoutsContextKey struct{}
+
+// WithStageTimeouts returns a copy of ctx which carries the given stage
+// timeouts. They replace any stage timeouts that ctx already carries.
+func WithStageTimeouts(ctx context.Context, timeouts StageTimeouts) context.Context {
+	return context.WithValue(ctx, stageTimeoutsContextKey{}, timeouts)
+}
+
+// StageTimeoutsFromContext returns the stage timeouts carried by ctx. All
+// timeouts are 0 if ctx was not created by WithStageTimeouts.
+func StageTimeoutsFromContext(ctx context.Context) StageTimeouts {
+	timeouts, _ := ctx.Value(stageTimeoutsContextKey{}).(StageTimeouts)
+	return timeouts
+}
+
+// WithStageTimeout returns a copy of ctx which is done once timeout has
+// elapsed. If timeout is 0, the copy is only done once ctx is done. The
+// returned cancel function must always be called once the stage is over.
+func WithStageTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,41 @@
+package ordervalidator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageTimeoutsFromContext(t *testing.T) {
+	assert.Equal(t, StageTimeouts{}, StageTimeoutsFromContext(context.Background()))
+
+	timeouts := StageTimeouts{
+		SchemaValidation: time.Second,
+		EthValidation:    time.Minute,
+	}
+	ctx := WithStageTimeouts(context.Background(), timeouts)
+	assert.Equal(t, timeouts, StageTimeoutsFromContext(ctx))
+
+	// Stage timeouts are replaced rather than merged.
+	ctx = WithStageTimeouts(ctx, StageTimeouts{DBWrite: time.Second})
+	assert.Equal(t, StageTimeouts{DBWrite: time.Second}, StageTimeoutsFromContext(ctx))
+}
+
+func TestWithStageTimeout(t *testing.T) {
+	ctx, cancel := WithStageTimeout(context.Background(), 0)
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "a timeout of 0 should not set a deadline")
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	ctx, cancel = WithStageTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the stage timeout")
+	}
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
@@ -0,0 +1,118 @@
+package ordervalidator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidationTrace contains timing information about the validation of a
+// single order. It is only collected if tracing is enabled for the context
+// passed to BatchValidate (see WithTracing). All durations are in nanoseconds
+// when encoded as JSON.
+type ValidationTrace struct {
+	// SchemaValidationDuration is the time spent validating the order against
+	// the JSON Schema (including any custom order filter).
+	SchemaValidationDuration time.Duration `json:"schemaValidationDuration"`
+	// SignatureValidationDuration is the time spent recovering the signer of
+	// the order. It is zero for signature types which can only be validated
+	// on-chain.
+	SignatureValidationDuration time.Duration `json:"signatureValidationDuration"`
+	// EthCallCount is the number of eth_call requests (including retries) that
+	// were made to validate the order. Orders are validated in batches, so
+	// each request also covers other orders in the same batch.
+	EthCallCount int `json:"ethCallCount"`
+	// EthCallDuration is the total time spent on the eth_call requests which
+	// covered the order.
+	EthCallDuration time.Duration `json:"ethCallDuration"`
+}
+
+type tracingContextKey struct{}
+
+// WithTracing returns a copy of ctx which causes BatchValidate to collect a
+// ValidationTrace for each order.
+func WithTracing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tracingContextKey{}, true)
+}
+
+// IsTracingEnabled returns true if ctx was created by WithTracing.
+func IsTracingEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(tracingContextKey{}).(bool)
+	return enabled
+}
+
+// traceRecorder collects ValidationTraces during a call to BatchValidate. A nil
+// *traceRecorder records nothing, so callers don't need to check whether
+// tracing is enabled. It is safe for concurrent use.
+type traceRecorder struct {
+	mu     sync.Mutex
+	traces map[common.Hash]*ValidationTrace
+}
+
+// newTraceRecorder returns a new traceRecorder if tracing is enabled for ctx
+// and nil otherwise.
+func newTraceRecorder(ctx context.Context) *traceRecorder {
+	if !IsTracingEnabled(ctx) {
+		return nil
+	}
+	return &traceRecorder{
+		traces: map[common.Hash]*ValidationTrace{},
+	}
+}
+
+// trace returns the trace for the given order, creating it if needed. r.mu must
+// be held.
+func (r *traceRecorder) trace(signedOrder *zeroex.SignedOrder) *ValidationTrace {
+	// The order hash is cached on the order, so this is cheap.
+	orderHash, _ := signedOrder.ComputeOrderHash()
+	trace, found := r.traces[orderHash]
+	if !found {
+		trace = &ValidationTrace{}
+		r.traces[orderHash] = trace
+	}
+	return trace
+}
+
+func (r *traceRecorder) recordSignatureValidation(signedOrder *zeroex.SignedOrder, duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trace(signedOrder).SignatureValidationDuration += duration
+}
+
+// recordEthCall records an eth_call request which covered all of the given
+// orders.
+func (r *traceRecorder) recordEthCall(signedOrders []*zeroex.SignedOrder, duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, signedOrder := range signedOrders {
+		trace := r.trace(signedOrder)
+		trace.EthCallCount++
+		trace.EthCallDuration += duration
+	}
+}
+
+// attach sets the Trace field of all the results.
+func (r *traceRecorder) attach(results *ValidationResults) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, acceptedOrderInfo := range results.Accepted {
+		acceptedOrderInfo.Trace = r.trace(acceptedOrderInfo.SignedOrder)
+	}
+	for _, rejectedOrderInfo := range results.Rejected {
+		if rejectedOrderInfo.SignedOrder != nil {
+			rejectedOrderInfo.Trace = r.trace(rejectedOrderInfo.SignedOrder)
+		}
+	}
+}
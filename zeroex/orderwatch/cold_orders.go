@@ -0,0 +1,137 @@
+package orderwatch
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/0xProject/0x-mesh/ethereum/miniheader"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	logger "github.com/sirupsen/logrus"
+)
+
+// UpdateOrderTiers moves stored orders between the hot and cold tiers
+// according to the given criteria (see meshdb.UpdateOrderTiers). Block events
+// are not processed while the orders are being moved.
+func (w *Watcher) UpdateOrderTiers(criteria meshdb.ColdOrderCriteria) error {
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+
+	start := time.Now()
+	numFrozen, numThawed, err := w.meshDB.UpdateOrderTiers(criteria, start)
+	if err != nil {
+		return err
+	}
+	if numFrozen > 0 || numThawed > 0 {
+		logger.WithFields(logger.Fields{
+			"numFrozen": numFrozen,
+			"numThawed": numThawed,
+			"duration":  time.Since(start).String(),
+		}).Debug("updated order tiers")
+	}
+	return nil
+}
+
+// thawOrdersAffectedByBlockEvents moves the cold orders which could be
+// affected by the given block events back to the hot tier, so that they can
+// be re-validated. These are the orders of any maker whose address appears in
+// the indexed parameters of one of the logs (which covers fills, cancellations
+// and changes to balances and allowances) and the orders which expire at or
+// before the latest block. It must be called before opening a transaction on
+// the orders collection.
+func (w *Watcher) thawOrdersAffectedByBlockEvents(events []*blockwatch.Event) error {
+	numColdOrders, err := w.meshDB.CountColdOrders()
+	if err != nil {
+		return err
+	}
+	if numColdOrders == 0 {
+		return nil
+	}
+	var latestTimestamp time.Time
+	addresses := map[common.Address]struct{}{}
+	for _, event := range events {
+		if event.BlockHeader.Timestamp.After(latestTimestamp) {
+			latestTimestamp = event.BlockHeader.Timestamp
+		}
+		for _, log := range event.BlockHeader.Logs {
+			if len(log.Topics) == 0 {
+				continue
+			}
+			// The first topic is the event signature.
+			for _, topic := range log.Topics[1:] {
+				if address, ok := topicToAddress(topic); ok {
+					addresses[address] = struct{}{}
+				}
+			}
+		}
+	}
+	makerAddresses := make([]common.Address, 0, len(addresses))
+	for address := range addresses {
+		makerAddresses = append(makerAddresses, address)
+	}
+	numThawedByMaker, err := w.meshDB.ThawOrdersByMakerAddresses(makerAddresses)
+	if err != nil {
+		return err
+	}
+	numThawedByExpiration, err := w.meshDB.ThawOrdersExpiringBefore(latestTimestamp)
+	if err != nil {
+		return err
+	}
+	if numThawedByMaker > 0 || numThawedByExpiration > 0 {
+		logger.WithFields(logger.Fields{
+			"numThawedByMaker":      numThawedByMaker,
+			"numThawedByExpiration": numThawedByExpiration,
+		}).Trace("thawed cold orders affected by block events")
+	}
+	return nil
+}
+
+// topicToAddress returns the address encoded in the given log topic. ok is
+// false if the topic doesn't look like an address (i.e. it doesn't start with
+// 12 zero bytes).
+func topicToAddress(topic common.Hash) (address common.Address, ok bool) {
+	if !bytes.Equal(topic[:common.HashLength-common.AddressLength], make([]byte, common.HashLength-common.AddressLength)) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(topic[common.HashLength-common.AddressLength:]), true
+}
+
+// thawChangedColdOrders re-validates the cold orders which haven't been
+// updated since lastUpdatedCutOff and moves the ones whose fillability has
+// changed back to the hot tier, where they are picked up by Cleanup. Cold
+// orders whose fillability hasn't changed stay in the cold tier. It must be
+// called before opening a transaction on the orders collection.
+func (w *Watcher) thawChangedColdOrders(ctx context.Context, lastUpdatedCutOff time.Time, latestBlock *miniheader.MiniHeader) error {
+	coldOrders, err := w.meshDB.FindColdOrdersLastUpdatedBefore(lastUpdatedCutOff)
+	if err != nil {
+		return err
+	}
+	if len(coldOrders) == 0 {
+		return nil
+	}
+	orderHashToDBOrder := map[common.Hash]*meshdb.Order{}
+	for _, order := range coldOrders {
+		orderHashToDBOrder[order.Hash] = order
+	}
+	signedOrders := make([]*zeroex.SignedOrder, len(coldOrders))
+	for i, order := range coldOrders {
+		signedOrders[i] = order.SignedOrder
+	}
+	areNewOrders := false
+	results := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, latestBlock.Number)
+	changedOrderHashes := []common.Hash{}
+	for _, acceptedOrderInfo := range results.Accepted {
+		order, found := orderHashToDBOrder[acceptedOrderInfo.OrderHash]
+		if found && order.FillableTakerAssetAmount.Cmp(acceptedOrderInfo.FillableTakerAssetAmount) != 0 {
+			changedOrderHashes = append(changedOrderHashes, acceptedOrderInfo.OrderHash)
+		}
+	}
+	for _, rejectedOrderInfo := range results.Rejected {
+		changedOrderHashes = append(changedOrderHashes, rejectedOrderInfo.OrderHash)
+	}
+	_, err = w.meshDB.ThawOrders(changedOrderHashes)
+	return err
+}
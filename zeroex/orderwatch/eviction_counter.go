@@ -0,0 +1,56 @@
+package orderwatch
+
+import (
+	"sync"
+	"time"
+)
+
+// evictionCounterWindow is the window over which evictions are counted.
+const evictionCounterWindow = 24 * time.Hour
+
+// evictionCounter keeps track of the number of orders that were evicted to
+// make space in the database within a rolling window.
+type evictionCounter struct {
+	mu      sync.Mutex
+	batches []evictionBatch
+}
+
+type evictionBatch struct {
+	time  time.Time
+	count int
+}
+
+// add records that count orders were evicted at the given time.
+func (c *evictionCounter) add(now time.Time, count int) {
+	if count == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+	c.batches = append(c.batches, evictionBatch{time: now, count: count})
+}
+
+// count returns the number of orders that were evicted within
+// evictionCounterWindow of the given time.
+func (c *evictionCounter) count(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+	total := 0
+	for _, batch := range c.batches {
+		total += batch.count
+	}
+	return total
+}
+
+// prune removes batches that are outside of the window. It must be called
+// while holding c.mu.
+func (c *evictionCounter) prune(now time.Time) {
+	cutoff := now.Add(-evictionCounterWindow)
+	i := 0
+	for i < len(c.batches) && !c.batches[i].time.After(cutoff) {
+		i++
+	}
+	c.batches = c.batches[i:]
+}
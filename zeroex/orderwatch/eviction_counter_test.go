@@ -0,0 +1,23 @@
+package orderwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictionCounter(t *testing.T) {
+	counter := &evictionCounter{}
+	start := time.Now()
+	assert.Equal(t, 0, counter.count(start))
+
+	counter.add(start, 5)
+	counter.add(start.Add(1*time.Hour), 0)
+	counter.add(start.Add(2*time.Hour), 3)
+	assert.Equal(t, 8, counter.count(start.Add(2*time.Hour)))
+
+	// The first batch falls out of the window after 24 hours.
+	assert.Equal(t, 3, counter.count(start.Add(evictionCounterWindow)))
+	assert.Equal(t, 0, counter.count(start.Add(evictionCounterWindow+2*time.Hour)))
+}
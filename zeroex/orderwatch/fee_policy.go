@@ -0,0 +1,44 @@
+package orderwatch
+
+import (
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FeePolicy is an operator-defined policy which restricts the fees of the
+// orders that Mesh will store and share with peers. It can be used by relayers
+// which only want to propagate orders that pay fees to them. Orders which do
+// not satisfy the policy are rejected before they are stored or gossiped.
+type FeePolicy struct {
+	// AllowedFeeRecipients, if not empty, is the set of fee recipient addresses
+	// that are allowed. Orders with any other feeRecipientAddress are rejected.
+	AllowedFeeRecipients map[common.Address]struct{}
+	// MaxMakerFee, if not nil, is the maximum makerFee an order can have.
+	MaxMakerFee *big.Int
+	// MaxTakerFee, if not nil, is the maximum takerFee an order can have.
+	MaxTakerFee *big.Int
+}
+
+// check returns the status that the given order should be rejected with or nil
+// if the order satisfies the policy. Note that fees are compared in the base
+// units of their respective fee assets, regardless of what those assets are.
+func (p *FeePolicy) check(order *zeroex.SignedOrder) *ordervalidator.RejectedOrderStatus {
+	if p == nil {
+		return nil
+	}
+	if len(p.AllowedFeeRecipients) > 0 {
+		if _, found := p.AllowedFeeRecipients[order.FeeRecipientAddress]; !found {
+			return &ordervalidator.ROFeeRecipientNotAllowed
+		}
+	}
+	if p.MaxMakerFee != nil && order.MakerFee != nil && order.MakerFee.Cmp(p.MaxMakerFee) == 1 {
+		return &ordervalidator.ROMaxFeeExceeded
+	}
+	if p.MaxTakerFee != nil && order.TakerFee != nil && order.TakerFee.Cmp(p.MaxTakerFee) == 1 {
+		return &ordervalidator.ROMaxFeeExceeded
+	}
+	return nil
+}
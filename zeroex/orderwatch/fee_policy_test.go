@@ -0,0 +1,73 @@
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeePolicyCheck(t *testing.T) {
+	allowedFeeRecipient := common.HexToAddress("0x1")
+	otherFeeRecipient := common.HexToAddress("0x2")
+	policy := &FeePolicy{
+		AllowedFeeRecipients: map[common.Address]struct{}{
+			allowedFeeRecipient: {},
+		},
+		MaxMakerFee: big.NewInt(100),
+		MaxTakerFee: big.NewInt(200),
+	}
+
+	testCases := []struct {
+		policy         *FeePolicy
+		order          *zeroex.SignedOrder
+		expectedStatus *ordervalidator.RejectedOrderStatus
+	}{
+		{
+			policy:         nil,
+			order:          newFeePolicyTestOrder(otherFeeRecipient, 1000, 1000),
+			expectedStatus: nil,
+		},
+		{
+			policy:         policy,
+			order:          newFeePolicyTestOrder(allowedFeeRecipient, 100, 200),
+			expectedStatus: nil,
+		},
+		{
+			policy:         policy,
+			order:          newFeePolicyTestOrder(otherFeeRecipient, 0, 0),
+			expectedStatus: &ordervalidator.ROFeeRecipientNotAllowed,
+		},
+		{
+			policy:         policy,
+			order:          newFeePolicyTestOrder(allowedFeeRecipient, 101, 0),
+			expectedStatus: &ordervalidator.ROMaxFeeExceeded,
+		},
+		{
+			policy:         policy,
+			order:          newFeePolicyTestOrder(allowedFeeRecipient, 0, 201),
+			expectedStatus: &ordervalidator.ROMaxFeeExceeded,
+		},
+		{
+			policy:         &FeePolicy{MaxTakerFee: big.NewInt(0)},
+			order:          newFeePolicyTestOrder(otherFeeRecipient, 1000, 0),
+			expectedStatus: nil,
+		},
+	}
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expectedStatus, tc.policy.check(tc.order), "test case %d", i)
+	}
+}
+
+func newFeePolicyTestOrder(feeRecipient common.Address, makerFee, takerFee int64) *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			FeeRecipientAddress: feeRecipient,
+			MakerFee:            big.NewInt(makerFee),
+			TakerFee:            big.NewInt(takerFee),
+		},
+	}
+}
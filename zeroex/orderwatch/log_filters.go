@@ -0,0 +1,107 @@
+package orderwatch
+
+import (
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// maxTokenAddressesInLogFilter is the max number of token addresses that
+	// LogFilters narrows the token events down to. If more tokens are tracked,
+	// token events emitted by any contract are fetched.
+	maxTokenAddressesInLogFilter = 500
+	// maxMakerAddressesInLogFilter is the max number of maker addresses that
+	// LogFilters narrows the events down to. If there are more makers, events
+	// involving any address are fetched.
+	maxMakerAddressesInLogFilter = 500
+)
+
+var (
+	exchangeEventTopics = []common.Hash{
+		eventTopic("Fill(address,address,bytes,bytes,bytes,bytes,bytes32,address,address,uint256,uint256,uint256,uint256,uint256)"),
+		eventTopic("Cancel(address,address,bytes,bytes,address,bytes32)"),
+		eventTopic("CancelUpTo(address,address,uint256)"),
+	}
+	transferEventTopic = eventTopic("Transfer(address,address,uint256)")
+	// ownerEventTopics are the token events whose first indexed argument is
+	// the owner of the tokens.
+	ownerEventTopics = []common.Hash{
+		transferEventTopic,
+		eventTopic("Approval(address,address,uint256)"),
+		eventTopic("ApprovalForAll(address,address,bool)"),
+		eventTopic("Deposit(address,uint256)"),
+		eventTopic("Withdrawal(address,uint256)"),
+	}
+	erc1155TransferEventTopics = []common.Hash{
+		eventTopic("TransferSingle(address,address,address,uint256,uint256)"),
+		eventTopic("TransferBatch(address,address,address,uint256[],uint256[])"),
+	}
+)
+
+func eventTopic(signature string) common.Hash {
+	return common.BytesToHash(crypto.Keccak256([]byte(signature)))
+}
+
+// LogFilters returns the filters for the logs that can affect the orders
+// currently stored. Events are only relevant if they are emitted by the
+// Exchange contract or one of the tokens in the stored orders and if they
+// involve one of the makers of the stored orders. When there are too many
+// tokens or makers to filter by, LogFilters falls back to broader filters.
+// It is intended to be used as blockwatch.Config.LogFilters.
+func (w *Watcher) LogFilters() []blockwatch.LogFilter {
+	w.trackedAddressesMu.Lock()
+	defer w.trackedAddressesMu.Unlock()
+
+	var makerTopics []common.Hash
+	if len(w.makerAddressToSeenCount) > 0 && len(w.makerAddressToSeenCount) <= maxMakerAddressesInLogFilter {
+		makerTopics = make([]common.Hash, 0, len(w.makerAddressToSeenCount))
+		for makerAddress := range w.makerAddressToSeenCount {
+			makerTopics = append(makerTopics, common.BytesToHash(makerAddress.Bytes()))
+		}
+	}
+	filters := []blockwatch.LogFilter{
+		{
+			Addresses: []common.Address{w.contractAddresses.Exchange},
+			Topics:    [][]common.Hash{exchangeEventTopics, makerTopics},
+		},
+	}
+	if len(w.contractAddressToSeenCount) == 0 {
+		return filters
+	}
+
+	var tokenAddresses []common.Address
+	if len(w.contractAddressToSeenCount) <= maxTokenAddressesInLogFilter {
+		tokenAddresses = make([]common.Address, 0, len(w.contractAddressToSeenCount))
+		for tokenAddress := range w.contractAddressToSeenCount {
+			tokenAddresses = append(tokenAddresses, tokenAddress)
+		}
+	}
+	if makerTopics == nil {
+		tokenEventTopics := append(append([]common.Hash{}, ownerEventTopics...), erc1155TransferEventTopics...)
+		return append(filters, blockwatch.LogFilter{
+			Addresses: tokenAddresses,
+			Topics:    [][]common.Hash{tokenEventTopics},
+		})
+	}
+	// Transfers affect the orders of both the sender and the recipient, which
+	// are in different topics.
+	return append(filters,
+		blockwatch.LogFilter{
+			Addresses: tokenAddresses,
+			Topics:    [][]common.Hash{ownerEventTopics, makerTopics},
+		},
+		blockwatch.LogFilter{
+			Addresses: tokenAddresses,
+			Topics:    [][]common.Hash{{transferEventTopic}, nil, makerTopics},
+		},
+		blockwatch.LogFilter{
+			Addresses: tokenAddresses,
+			Topics:    [][]common.Hash{erc1155TransferEventTopics, nil, makerTopics},
+		},
+		blockwatch.LogFilter{
+			Addresses: tokenAddresses,
+			Topics:    [][]common.Hash{erc1155TransferEventTopics, nil, nil, makerTopics},
+		},
+	)
+}
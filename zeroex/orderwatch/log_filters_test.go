@@ -0,0 +1,59 @@
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFilters(t *testing.T) {
+	exchangeAddress := common.HexToAddress("0xe0")
+	tokenAddress := common.HexToAddress("0x70")
+	makerAddress := common.HexToAddress("0x3a")
+	makerTopics := []common.Hash{common.HexToHash("0x3a")}
+	w := &Watcher{
+		contractAddresses:          ethereum.ContractAddresses{Exchange: exchangeAddress},
+		contractAddressToSeenCount: map[common.Address]uint{},
+		makerAddressToSeenCount:    map[common.Address]uint{},
+	}
+
+	// Without any orders, only Exchange events are relevant.
+	assert.Equal(t, []blockwatch.LogFilter{
+		{
+			Addresses: []common.Address{exchangeAddress},
+			Topics:    [][]common.Hash{exchangeEventTopics, nil},
+		},
+	}, w.LogFilters())
+
+	w.contractAddressToSeenCount[tokenAddress] = 1
+	w.makerAddressToSeenCount[makerAddress] = 1
+	filters := w.LogFilters()
+	require.Len(t, filters, 5)
+	assert.Equal(t, [][]common.Hash{exchangeEventTopics, makerTopics}, filters[0].Topics)
+	for _, filter := range filters[1:] {
+		assert.Equal(t, []common.Address{tokenAddress}, filter.Addresses)
+	}
+	assert.Equal(t, [][]common.Hash{ownerEventTopics, makerTopics}, filters[1].Topics)
+	assert.Equal(t, [][]common.Hash{{transferEventTopic}, nil, makerTopics}, filters[2].Topics)
+	assert.Equal(t, [][]common.Hash{erc1155TransferEventTopics, nil, makerTopics}, filters[3].Topics)
+	assert.Equal(t, [][]common.Hash{erc1155TransferEventTopics, nil, nil, makerTopics}, filters[4].Topics)
+
+	// With too many makers and tokens, the filters fall back to matching any
+	// maker and any token contract.
+	for i := 0; i <= maxMakerAddressesInLogFilter; i++ {
+		address := common.BigToAddress(big.NewInt(int64(1000 + i)))
+		w.makerAddressToSeenCount[address] = 1
+		w.contractAddressToSeenCount[address] = 1
+	}
+	filters = w.LogFilters()
+	require.Len(t, filters, 2)
+	assert.Equal(t, [][]common.Hash{exchangeEventTopics, nil}, filters[0].Topics)
+	assert.Nil(t, filters[1].Addresses)
+	assert.Len(t, filters[1].Topics, 1)
+	assert.Len(t, filters[1].Topics[0], len(ownerEventTopics)+len(erc1155TransferEventTopics))
+}
@@ -0,0 +1,65 @@
+package orderwatch
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// makerEpochKey identifies an order epoch in the Exchange contract, which
+// stores a separate epoch for each pair of maker and sender address.
+type makerEpochKey struct {
+	makerAddress  common.Address
+	senderAddress common.Address
+}
+
+// makerEpochTracker keeps track of the order epochs set by the CancelUpTo
+// events that the Watcher has processed. Epochs set before Mesh started are not
+// known.
+type makerEpochTracker struct {
+	mu     sync.RWMutex
+	epochs map[makerEpochKey]*big.Int
+}
+
+// update records the order epoch set by a CancelUpTo event. If the event was
+// removed by a block re-org, the epoch is forgotten since the previous epoch
+// might not be known.
+func (t *makerEpochTracker) update(makerAddress, senderAddress common.Address, orderEpoch *big.Int, isRemoved bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := makerEpochKey{makerAddress: makerAddress, senderAddress: senderAddress}
+	if isRemoved {
+		delete(t.epochs, key)
+		return
+	}
+	if t.epochs == nil {
+		t.epochs = map[makerEpochKey]*big.Int{}
+	}
+	// The epoch can only increase, but events may be processed more than once.
+	if existing, found := t.epochs[key]; !found || orderEpoch.Cmp(existing) > 0 {
+		t.epochs[key] = new(big.Int).Set(orderEpoch)
+	}
+}
+
+// get returns the order epoch of the given maker and sender address and
+// whether or not it is known.
+func (t *makerEpochTracker) get(makerAddress, senderAddress common.Address) (*big.Int, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	epoch, found := t.epochs[makerEpochKey{makerAddress: makerAddress, senderAddress: senderAddress}]
+	if !found {
+		return nil, false
+	}
+	return new(big.Int).Set(epoch), true
+}
+
+// MakerEpoch returns the order epoch of the given maker and sender address as
+// of the latest CancelUpTo event processed by the Watcher. Orders of the maker
+// with that sender address and a salt lower than the epoch are cancelled. The
+// second return value is false if no CancelUpTo event has been processed for
+// them since Mesh started, in which case the epoch has to be read from the
+// Exchange contract.
+func (w *Watcher) MakerEpoch(makerAddress, senderAddress common.Address) (*big.Int, bool) {
+	return w.makerEpochs.get(makerAddress, senderAddress)
+}
@@ -0,0 +1,36 @@
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakerEpochTracker(t *testing.T) {
+	tracker := &makerEpochTracker{}
+	maker := common.HexToAddress("0x1")
+	sender := common.HexToAddress("0x2")
+	_, found := tracker.get(maker, sender)
+	assert.False(t, found)
+
+	tracker.update(maker, sender, big.NewInt(10), false)
+	epoch, found := tracker.get(maker, sender)
+	assert.True(t, found)
+	assert.Equal(t, big.NewInt(10), epoch)
+
+	// Epochs are tracked separately for each sender address.
+	_, found = tracker.get(maker, common.Address{})
+	assert.False(t, found)
+
+	// Processing an older event again doesn't decrease the epoch.
+	tracker.update(maker, sender, big.NewInt(5), false)
+	epoch, _ = tracker.get(maker, sender)
+	assert.Equal(t, big.NewInt(10), epoch)
+
+	// The epoch is forgotten when the event is removed by a block re-org.
+	tracker.update(maker, sender, big.NewInt(10), true)
+	_, found = tracker.get(maker, sender)
+	assert.False(t, found)
+}
@@ -0,0 +1,103 @@
+package orderwatch
+
+import (
+	"errors"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// maxRetainedOrderEvents is the number of recent order events that are kept in
+// memory so that clients can catch up on events that they missed.
+const maxRetainedOrderEvents = 10000
+
+// ErrOrderEventsUnavailable is returned by OrderEventsSince if some of the
+// order events after the given sequence number are no longer retained, or if
+// the sequence number is greater than that of the latest order event. Clients
+// should resync their state with GetOrders in this case.
+var ErrOrderEventsUnavailable = errors.New("order events after the given sequence number are not available")
+
+// ErrOrderEventsEpochMismatch is returned by OrderEventsSince if the given
+// epoch is not the current one, i.e. the sequence number was assigned before
+// Mesh was restarted. Sequence numbers from different epochs can't be
+// compared, so clients should resync their state with GetOrders in this case.
+var ErrOrderEventsEpochMismatch = errors.New("order events are from a previous epoch (Mesh was restarted)")
+
+// orderEventLog assigns sequence numbers to order events and retains the most
+// recent ones. It is not safe for concurrent use.
+type orderEventLog struct {
+	// epoch is a random ID which is generated when the log is created, i.e.
+	// whenever Mesh is started. Together with a sequence number, it identifies
+	// an order event across restarts.
+	epoch string
+	// events contains at least the most recent maxRetainedOrderEvents events
+	// in order. Up to maxRetainedOrderEvents older events are kept as well so
+	// that the slice doesn't need to be copied every time an event is added.
+	events             []*zeroex.OrderEvent
+	lastSequenceNumber uint64
+}
+
+// newOrderEventLog creates an empty orderEventLog with a new epoch.
+func newOrderEventLog() *orderEventLog {
+	return &orderEventLog{
+		epoch: uuid.New().String(),
+	}
+}
+
+// add assigns the epoch and the next sequence numbers to the given events and
+// retains them.
+func (l *orderEventLog) add(events []*zeroex.OrderEvent) {
+	for _, event := range events {
+		l.lastSequenceNumber++
+		event.SequenceNumber = l.lastSequenceNumber
+		event.Epoch = l.epoch
+	}
+	l.events = append(l.events, events...)
+	if len(l.events) > 2*maxRetainedOrderEvents {
+		l.events = append([]*zeroex.OrderEvent{}, l.retained()...)
+	}
+}
+
+// retained returns the most recent maxRetainedOrderEvents events.
+func (l *orderEventLog) retained() []*zeroex.OrderEvent {
+	if len(l.events) > maxRetainedOrderEvents {
+		return l.events[len(l.events)-maxRetainedOrderEvents:]
+	}
+	return l.events
+}
+
+// since returns all events with a sequence number greater than the given one.
+// The sequence number must belong to the given epoch.
+func (l *orderEventLog) since(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error) {
+	if epoch != l.epoch {
+		return nil, ErrOrderEventsEpochMismatch
+	}
+	if sequenceNumber > l.lastSequenceNumber {
+		return nil, ErrOrderEventsUnavailable
+	}
+	if sequenceNumber == l.lastSequenceNumber {
+		return []*zeroex.OrderEvent{}, nil
+	}
+	retained := l.retained()
+	oldestSequenceNumber := retained[0].SequenceNumber
+	if sequenceNumber+1 < oldestSequenceNumber {
+		return nil, ErrOrderEventsUnavailable
+	}
+	return append([]*zeroex.OrderEvent{}, retained[sequenceNumber+1-oldestSequenceNumber:]...), nil
+}
+
+// byTransaction returns the retained events which were caused by a contract
+// event emitted in the transaction with the given hash, in order.
+func (l *orderEventLog) byTransaction(txHash common.Hash) []*zeroex.OrderEvent {
+	events := []*zeroex.OrderEvent{}
+	for _, event := range l.retained() {
+		for _, contractEvent := range event.ContractEvents {
+			if contractEvent.TxHash == txHash {
+				events = append(events, event)
+				break
+			}
+		}
+	}
+	return events
+}
@@ -0,0 +1,91 @@
+package orderwatch
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrderEvents(n int) []*zeroex.OrderEvent {
+	events := make([]*zeroex.OrderEvent, n)
+	for i := range events {
+		events[i] = &zeroex.OrderEvent{}
+	}
+	return events
+}
+
+func TestOrderEventLog(t *testing.T) {
+	log := newOrderEventLog()
+	events, err := log.since(log.epoch, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 0)
+
+	log.add(newTestOrderEvents(3))
+	log.add(nil)
+	log.add(newTestOrderEvents(2))
+	events, err = log.since(log.epoch, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.SequenceNumber)
+	}
+	events, err = log.since(log.epoch, 3)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(4), events[0].SequenceNumber)
+	events, err = log.since(log.epoch, 5)
+	require.NoError(t, err)
+	assert.Len(t, events, 0)
+
+	for _, event := range events {
+		assert.Equal(t, log.epoch, event.Epoch)
+	}
+
+	// Sequence numbers from the future are not available.
+	_, err = log.since(log.epoch, 6)
+	assert.Equal(t, ErrOrderEventsUnavailable, err)
+
+	// Sequence numbers from a previous epoch (i.e. from before Mesh was
+	// restarted) are rejected even if they are in range.
+	restartedLog := newOrderEventLog()
+	restartedLog.add(newTestOrderEvents(5))
+	assert.NotEqual(t, log.epoch, restartedLog.epoch)
+	_, err = restartedLog.since(log.epoch, 3)
+	assert.Equal(t, ErrOrderEventsEpochMismatch, err)
+}
+
+func TestOrderEventLogPrunesOldEvents(t *testing.T) {
+	log := newOrderEventLog()
+	for i := 0; i < 3; i++ {
+		log.add(newTestOrderEvents(maxRetainedOrderEvents))
+	}
+	assert.True(t, len(log.events) <= 2*maxRetainedOrderEvents, "expected at most %d events to be kept but got %d", 2*maxRetainedOrderEvents, len(log.events))
+
+	lastSequenceNumber := uint64(3 * maxRetainedOrderEvents)
+	events, err := log.since(log.epoch, lastSequenceNumber-maxRetainedOrderEvents)
+	require.NoError(t, err)
+	require.Len(t, events, maxRetainedOrderEvents)
+	assert.Equal(t, lastSequenceNumber, events[len(events)-1].SequenceNumber)
+	_, err = log.since(log.epoch, lastSequenceNumber-maxRetainedOrderEvents-1)
+	assert.Equal(t, ErrOrderEventsUnavailable, err)
+}
+
+func TestOrderEventLogByTransaction(t *testing.T) {
+	log := newOrderEventLog()
+	txHash := common.HexToHash("0x1")
+	otherTxHash := common.HexToHash("0x2")
+	events := newTestOrderEvents(3)
+	events[0].ContractEvents = []*zeroex.ContractEvent{{TxHash: txHash}}
+	events[1].ContractEvents = []*zeroex.ContractEvent{{TxHash: otherTxHash}}
+	events[2].ContractEvents = []*zeroex.ContractEvent{{TxHash: otherTxHash}, {TxHash: txHash}}
+	log.add(events)
+
+	affected := log.byTransaction(txHash)
+	require.Len(t, affected, 2)
+	assert.Equal(t, uint64(1), affected[0].SequenceNumber)
+	assert.Equal(t, uint64(3), affected[1].SequenceNumber)
+	assert.Len(t, log.byTransaction(common.HexToHash("0x3")), 0)
+}
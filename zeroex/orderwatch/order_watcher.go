@@ -16,6 +16,7 @@ import (
 	"github.com/0xProject/0x-mesh/ethereum/miniheader"
 	"github.com/0xProject/0x-mesh/expirationwatch"
 	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/orderpolicy"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch/decoder"
@@ -76,6 +77,11 @@ const (
 	slowCounterInterval = 5 * time.Minute
 )
 
+// errDBWriteTimedOut is returned by add if the DB write timeout (see
+// ordervalidator.WithStageTimeouts) was exceeded before the orders were
+// stored. None of the orders are stored in that case.
+var errDBWriteTimedOut = errors.New("timed out before orders could be stored in the database")
+
 // Watcher watches all order-relevant state and handles the state transitions
 type Watcher struct {
 	meshDB                     *meshdb.MeshDB
@@ -95,12 +101,40 @@ type Watcher struct {
 	maxExpirationTime          *big.Int
 	maxExpirationCounter       *slowcounter.SlowCounter
 	maxOrders                  int
+	feePolicy                  *FeePolicy
+	sizePolicy                 *SizePolicy
+	orderPolicy                orderpolicy.Policy
 	handleBlockEventsMu        sync.RWMutex
 	// atLeastOneBlockProcessed is closed to signal that the BlockWatcher has processed at least one
 	// block. Validation of orders should block until this has completed
 	atLeastOneBlockProcessed   chan struct{}
 	atLeastOneBlockProcessedMu sync.Mutex
 	didProcessABlock           bool
+	// nextCleanupTime is the time at which the cleanup worker is next expected
+	// to run.
+	nextCleanupTime   time.Time
+	nextCleanupTimeMu sync.RWMutex
+	// evictions counts the orders that were removed to make space in the
+	// database.
+	evictions evictionCounter
+	// sendOrderEventsMu ensures that order events are sent in the order of
+	// their sequence numbers.
+	sendOrderEventsMu sync.Mutex
+	// orderEventsMu guards orderEventLog. It is not held while order events
+	// are sent, so slow subscribers don't block OrderEventsSince.
+	orderEventsMu sync.Mutex
+	orderEventLog *orderEventLog
+	// trackedAddressesMu guards contractAddressToSeenCount and
+	// makerAddressToSeenCount, which are read by LogFilters while blocks are
+	// being fetched.
+	trackedAddressesMu      sync.Mutex
+	makerAddressToSeenCount map[common.Address]uint
+	// makerEpochs keeps track of the order epochs set by CancelUpTo events.
+	makerEpochs makerEpochTracker
+	// topicQuotas are the quotas from Config.TopicQuotas and quotaTopics are
+	// the topics which have one, in sorted order.
+	topicQuotas map[string]int
+	quotaTopics []string
 }
 
 type Config struct {
@@ -111,6 +145,21 @@ type Config struct {
 	ContractAddresses ethereum.ContractAddresses
 	MaxOrders         int
 	MaxExpirationTime *big.Int
+	// FeePolicy is an optional policy that restricts the fees of new orders.
+	// If nil, orders are not restricted based on their fees.
+	FeePolicy *FeePolicy
+	// SizePolicy is an optional policy that rejects new orders whose asset
+	// amounts are too small. If nil, orders are not restricted based on their
+	// size.
+	SizePolicy *SizePolicy
+	// OrderPolicy is an optional operator-defined policy which can reject or
+	// annotate new orders. If nil, all orders are accepted without annotations.
+	OrderPolicy orderpolicy.Policy
+	// TopicQuotas optionally maps pubsub topics to the maximum number of orders
+	// received on them which are stored. Orders received on those topics don't
+	// count toward MaxOrders and are only evicted to make space for other
+	// orders on the same topic (see TrimOrdersOverTopicQuotas).
+	TopicQuotas map[string]int
 }
 
 // New instantiates a new order watcher
@@ -149,6 +198,7 @@ func New(config Config) (*Watcher, error) {
 		blockWatcher:               config.BlockWatcher,
 		expirationWatcher:          expirationwatch.New(),
 		contractAddressToSeenCount: map[common.Address]uint{},
+		makerAddressToSeenCount:    map[common.Address]uint{},
 		orderValidator:             config.OrderValidator,
 		eventDecoder:               decoder,
 		assetDataDecoder:           assetDataDecoder,
@@ -156,9 +206,16 @@ func New(config Config) (*Watcher, error) {
 		maxExpirationTime:          big.NewInt(0).Set(config.MaxExpirationTime),
 		maxExpirationCounter:       maxExpirationCounter,
 		maxOrders:                  config.MaxOrders,
+		feePolicy:                  config.FeePolicy,
+		sizePolicy:                 config.SizePolicy,
+		orderPolicy:                config.OrderPolicy,
+		topicQuotas:                config.TopicQuotas,
+		quotaTopics:                sortedQuotaTopics(config.TopicQuotas),
 		blockEventsChan:            make(chan []*blockwatch.Event, 100),
 		atLeastOneBlockProcessed:   make(chan struct{}),
 		didProcessABlock:           false,
+		nextCleanupTime:            time.Now().Add(minCleanupInterval),
+		orderEventLog:              newOrderEventLog(),
 	}
 
 	// Check if any orders need to be removed right away due to high expiration
@@ -167,15 +224,20 @@ func New(config Config) (*Watcher, error) {
 	if err != nil {
 		return nil, err
 	}
-	w.orderFeed.Send(orderEvents)
+	w.sendOrderEvents(orderEvents)
 
-	// Pre-populate the OrderWatcher with all orders already stored in the DB
+	// Pre-populate the OrderWatcher with all orders already stored in the DB,
+	// including cold orders.
 	orders := []*meshdb.Order{}
 	err = w.meshDB.Orders.FindAll(&orders)
 	if err != nil {
 		return nil, err
 	}
-	for _, order := range orders {
+	coldOrders, err := w.meshDB.FindAllColdOrders()
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range append(orders, coldOrders...) {
 		err := w.setupInMemoryOrderState(order.SignedOrder)
 		if err != nil {
 			return nil, err
@@ -283,16 +345,24 @@ func (w *Watcher) mainLoop(ctx context.Context) error {
 			// we might as well process _all_ events in the channel.
 			drainedEvents := drainBlockEventsChan(w.blockEventsChan, maxBlockEventsToHandle)
 			events = append(events, drainedEvents...)
-			w.handleBlockEventsMu.Lock()
-			if err := w.handleBlockEvents(ctx, events); err != nil {
-				w.handleBlockEventsMu.Unlock()
+			if err := w.HandleBlockEvents(ctx, events); err != nil {
 				return err
 			}
-			w.handleBlockEventsMu.Unlock()
 		}
 	}
 }
 
+// HandleBlockEvents processes the given block events and revalidates any
+// orders affected by them. It is called automatically for every event emitted
+// by the block watcher once Watch has been called. It can also be called
+// directly (e.g. when replaying recorded block events) without starting the
+// Watcher.
+func (w *Watcher) HandleBlockEvents(ctx context.Context, events []*blockwatch.Event) error {
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+	return w.handleBlockEvents(ctx, events)
+}
+
 func drainBlockEventsChan(blockEventsChan chan []*blockwatch.Event, max int) []*blockwatch.Event {
 	allEvents := []*blockwatch.Event{}
 Loop:
@@ -312,6 +382,7 @@ Loop:
 
 func (w *Watcher) cleanupLoop(ctx context.Context) error {
 	start := time.Now()
+	w.setNextCleanupTime(start.Add(minCleanupInterval))
 	for {
 		select {
 		case <-ctx.Done():
@@ -324,12 +395,38 @@ func (w *Watcher) cleanupLoop(ctx context.Context) error {
 		}
 
 		start = time.Now()
+		w.setNextCleanupTime(start.Add(minCleanupInterval))
 		if err := w.Cleanup(ctx, defaultLastUpdatedBuffer); err != nil {
 			return err
 		}
 	}
 }
 
+func (w *Watcher) setNextCleanupTime(nextCleanupTime time.Time) {
+	w.nextCleanupTimeMu.Lock()
+	defer w.nextCleanupTimeMu.Unlock()
+	w.nextCleanupTime = nextCleanupTime
+}
+
+// NextRevalidationTime returns the latest time at which the given order will be
+// re-validated by the cleanup worker. Orders are also re-validated whenever a
+// relevant contract event is detected, so they may be re-validated sooner.
+func (w *Watcher) NextRevalidationTime(order *meshdb.Order) time.Time {
+	w.nextCleanupTimeMu.RLock()
+	nextCleanupTime := w.nextCleanupTime
+	w.nextCleanupTimeMu.RUnlock()
+
+	// The cleanup worker only re-validates orders that have not been updated
+	// for at least defaultLastUpdatedBuffer. Find the first cleanup at which
+	// the order will be eligible.
+	eligibleAt := order.LastUpdated.Add(defaultLastUpdatedBuffer)
+	if !nextCleanupTime.Before(eligibleAt) {
+		return nextCleanupTime
+	}
+	numIntervals := int64((eligibleAt.Sub(nextCleanupTime) + minCleanupInterval - 1) / minCleanupInterval)
+	return nextCleanupTime.Add(time.Duration(numIntervals) * minCleanupInterval)
+}
+
 func (w *Watcher) maxExpirationTimeLoop(ctx context.Context) error {
 	ticker := time.NewTicker(maxExpirationTimeCheckInterval)
 	for {
@@ -351,6 +448,9 @@ func (w *Watcher) removedCheckerLoop(ctx context.Context) error {
 		if err := w.permanentlyDeleteStaleRemovedOrders(ctx); err != nil {
 			return err
 		}
+		if err := w.deleteExpiredTombstones(); err != nil {
+			return err
+		}
 
 		select {
 		case <-ctx.Done():
@@ -393,7 +493,7 @@ func (w *Watcher) handleOrderExpirations(ordersColTxn *db.Transaction, latestBlo
 				}).Trace("Order expired that was no longer in DB")
 				continue
 			}
-			w.unwatchOrder(ordersColTxn, order, order.FillableTakerAssetAmount)
+			w.unwatchOrder(ordersColTxn, order, order.FillableTakerAssetAmount, ordervalidator.ZeroExValidation, ordervalidator.ROExpired)
 
 			orderEvent := &zeroex.OrderEvent{
 				Timestamp:                latestBlockTimestamp,
@@ -452,6 +552,10 @@ func (w *Watcher) handleBlockEvents(
 		return nil
 	}
 
+	if err := w.thawOrdersAffectedByBlockEvents(events); err != nil {
+		return err
+	}
+
 	miniHeadersColTxn := w.meshDB.MiniHeaders.OpenTransaction()
 	defer func() {
 		_ = miniHeadersColTxn.Discard()
@@ -472,7 +576,8 @@ func (w *Watcher) handleBlockEvents(
 	if previousLatestBlock != nil {
 		previousLatestBlockTimestamp = previousLatestBlock.Timestamp
 	}
-	latestBlockNumber, latestBlockTimestamp := w.getBlockchainState(events)
+	latestBlock := w.getBlockchainState(events)
+	latestBlockTimestamp := latestBlock.Timestamp
 
 	err = updateBlockHeadersStoredInDB(miniHeadersColTxn, events)
 	if err != nil {
@@ -746,6 +851,7 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = exchangeCancelUpToEvent
+				w.makerEpochs.update(exchangeCancelUpToEvent.MakerAddress, exchangeCancelUpToEvent.OrderSenderAddress, exchangeCancelUpToEvent.OrderEpoch, log.Removed)
 				cancelledOrders, err := w.meshDB.FindOrdersByMakerAddressAndMaxSalt(exchangeCancelUpToEvent.MakerAddress, exchangeCancelUpToEvent.OrderEpoch)
 				if err != nil {
 					logger.WithFields(logger.Fields{
@@ -781,7 +887,7 @@ func (w *Watcher) handleBlockEvents(
 	// This timeout of 1min is for limiting how long this call should block at the ETH RPC rate limiter
 	ctx, done := context.WithTimeout(ctx, 1*time.Minute)
 	defer done()
-	postValidationOrderEvents, err := w.generateOrderEventsIfChanged(ctx, ordersColTxn, orderHashToDBOrder, orderHashToEvents, latestBlockNumber, latestBlockTimestamp)
+	postValidationOrderEvents, err := w.generateOrderEventsIfChanged(ctx, ordersColTxn, orderHashToDBOrder, orderHashToEvents, latestBlock)
 	if err != nil {
 		return err
 	}
@@ -801,7 +907,7 @@ func (w *Watcher) handleBlockEvents(
 
 	orderEvents := append(expirationOrderEvents, postValidationOrderEvents...)
 	if len(orderEvents) > 0 {
-		w.orderFeed.Send(orderEvents)
+		w.sendOrderEvents(orderEvents)
 	}
 
 	w.atLeastOneBlockProcessedMu.Lock()
@@ -828,11 +934,22 @@ func (w *Watcher) Cleanup(ctx context.Context, lastUpdatedBuffer time.Duration)
 	w.handleBlockEventsMu.RLock()
 	defer w.handleBlockEventsMu.RUnlock()
 
+	lastUpdatedCutOff := time.Now().Add(-lastUpdatedBuffer)
+	latestBlock, err := w.meshDB.FindLatestMiniHeader()
+	if err != nil {
+		return err
+	}
+	// This timeout of 30min is for limiting how long this call should block at the ETH RPC rate limiter
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+	if err := w.thawChangedColdOrders(ctx, lastUpdatedCutOff, latestBlock); err != nil {
+		return err
+	}
+
 	ordersColTxn := w.meshDB.Orders.OpenTransaction()
 	defer func() {
 		_ = ordersColTxn.Discard()
 	}()
-	lastUpdatedCutOff := time.Now().Add(-lastUpdatedBuffer)
 	orders, err := w.meshDB.FindOrdersLastUpdatedBefore(lastUpdatedCutOff)
 	if err != nil {
 		logger.WithFields(logger.Fields{
@@ -853,14 +970,7 @@ func (w *Watcher) Cleanup(ctx context.Context, lastUpdatedBuffer time.Duration)
 		orderHashToEvents[order.Hash] = []*zeroex.ContractEvent{}
 	}
 
-	latestBlock, err := w.meshDB.FindLatestMiniHeader()
-	if err != nil {
-		return err
-	}
-	// This timeout of 30min is for limiting how long this call should block at the ETH RPC rate limiter
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
-	defer cancel()
-	orderEvents, err := w.generateOrderEventsIfChanged(ctx, ordersColTxn, orderHashToDBOrder, orderHashToEvents, latestBlock.Number, latestBlock.Timestamp)
+	orderEvents, err := w.generateOrderEventsIfChanged(ctx, ordersColTxn, orderHashToDBOrder, orderHashToEvents, latestBlock)
 	if err != nil {
 		return err
 	}
@@ -872,12 +982,106 @@ func (w *Watcher) Cleanup(ctx context.Context, lastUpdatedBuffer time.Duration)
 	}
 
 	if len(orderEvents) > 0 {
-		w.orderFeed.Send(orderEvents)
+		w.sendOrderEvents(orderEvents)
 	}
 
 	return nil
 }
 
+// ResetMiniHeaders replaces all stored block headers with the given latest
+// block header. Orders are subsequently validated at that block. It is meant to
+// be called when the BlockWatcher is reset (see blockwatch.Watcher.Reset), and
+// should be followed by a call to Cleanup with a lastUpdatedBuffer of 0 since
+// the events of any blocks which were skipped are never processed.
+func (w *Watcher) ResetMiniHeaders(latestHeader *miniheader.MiniHeader) error {
+	// Pause block event processing and validation so that no block header is
+	// added while the stored headers are being replaced.
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+
+	if err := w.meshDB.ClearAllMiniHeaders(); err != nil {
+		return err
+	}
+	return w.meshDB.MiniHeaders.Insert(latestHeader)
+}
+
+// RevalidateOrders immediately re-validates the stored orders with the given
+// hashes at the latest block, outside of the normal cleanup schedule, and
+// emits order events for any orders whose state has changed. This is useful
+// when a maker knows that the fillability of their orders has changed in a way
+// that Mesh cannot detect from contract events (e.g. a custom asset). Orders
+// which are not stored are rejected with ROOrderNotStored.
+func (w *Watcher) RevalidateOrders(ctx context.Context, orderHashes []common.Hash) (*ordervalidator.ValidationResults, error) {
+	// Pause block event processing until we finished re-validating at current
+	// block height
+	w.handleBlockEventsMu.RLock()
+	defer w.handleBlockEventsMu.RUnlock()
+
+	// Cold orders need to be moved back to the hot tier before they can be
+	// re-validated.
+	if _, err := w.meshDB.ThawOrders(orderHashes); err != nil {
+		return nil, err
+	}
+
+	validationResults := &ordervalidator.ValidationResults{
+		Accepted: []*ordervalidator.AcceptedOrderInfo{},
+		Rejected: []*ordervalidator.RejectedOrderInfo{},
+	}
+	orderHashToDBOrder := map[common.Hash]*meshdb.Order{}
+	orderHashToEvents := map[common.Hash][]*zeroex.ContractEvent{} // No events when re-validating on demand
+	signedOrders := []*zeroex.SignedOrder{}
+	for _, orderHash := range orderHashes {
+		if _, alreadySeen := orderHashToDBOrder[orderHash]; alreadySeen {
+			continue
+		}
+		order := w.findOrder(orderHash)
+		if order == nil {
+			validationResults.Rejected = append(validationResults.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash: orderHash,
+				Kind:      ordervalidator.MeshValidation,
+				Status:    ordervalidator.ROOrderNotStored,
+			})
+			continue
+		}
+		orderHashToDBOrder[orderHash] = order
+		orderHashToEvents[orderHash] = []*zeroex.ContractEvent{}
+		signedOrders = append(signedOrders, order.SignedOrder)
+	}
+	if len(signedOrders) == 0 {
+		return validationResults, nil
+	}
+
+	latestBlock, err := w.meshDB.FindLatestMiniHeader()
+	if err != nil {
+		return nil, err
+	}
+	ordersColTxn := w.meshDB.Orders.OpenTransaction()
+	defer func() {
+		_ = ordersColTxn.Discard()
+	}()
+	areNewOrders := false
+	results := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, latestBlock.Number)
+	orderEvents, err := w.convertValidationResultsIntoOrderEvents(
+		ordersColTxn, results, orderHashToDBOrder, orderHashToEvents, latestBlock,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := ordersColTxn.Commit(); err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+		}).Error("Failed to commit orders collection transaction")
+		return nil, err
+	}
+	if len(orderEvents) > 0 {
+		w.sendOrderEvents(orderEvents)
+	}
+
+	validationResults.Accepted = append(validationResults.Accepted, results.Accepted...)
+	validationResults.Rejected = append(validationResults.Rejected, results.Rejected...)
+	return validationResults, nil
+}
+
 func (w *Watcher) permanentlyDeleteStaleRemovedOrders(ctx context.Context) error {
 	removedOrders, err := w.meshDB.FindRemovedOrders()
 	if err != nil {
@@ -901,11 +1105,17 @@ func (w *Watcher) permanentlyDeleteStaleRemovedOrders(ctx context.Context) error
 // true, the orders will be marked as pinned. Pinned orders will not be affected
 // by any DDoS prevention or incentive mechanisms and will always stay in
 // storage until they are no longer fillable.
-func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validationBlockNumber *big.Int, pinned bool) ([]*zeroex.OrderEvent, error) {
+func (w *Watcher) add(ctx context.Context, orderInfos []*ordervalidator.AcceptedOrderInfo, annotations map[common.Hash]map[string]string, validationBlock *miniheader.MiniHeader, pinned bool) ([]*zeroex.OrderEvent, error) {
+	if ctx.Err() != nil {
+		return nil, errDBWriteTimedOut
+	}
 	orderEvents, err := w.decreaseMaxExpirationTimeIfNeeded()
 	if err != nil {
 		return orderEvents, err
 	}
+	// The events for any orders which were evicted above are sent even if
+	// adding the new orders times out below.
+	evictionEvents := orderEvents
 
 	// TODO(albrow): technically we should count the current number of orders,
 	// remove some if needed, and then insert the order in a single transaction to
@@ -926,6 +1136,10 @@ func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validation
 			FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
 			IsRemoved:                false,
 			IsPinned:                 pinned,
+			LastValidatedBlockNumber: validationBlock.Number,
+			LastValidatedBlockHash:   validationBlock.Hash,
+			LastValidated:            now,
+			Annotations:              annotations[orderInfo.OrderHash],
 		}
 		// Final expiration time check before inserting the order. We might have just
 		// changed max expiration time above.
@@ -979,6 +1193,9 @@ func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validation
 		}
 	}
 
+	if ctx.Err() != nil {
+		return evictionEvents, errDBWriteTimedOut
+	}
 	if err := txn.Commit(); err != nil {
 		return orderEvents, err
 	}
@@ -1003,12 +1220,10 @@ func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validation
 }
 
 func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
-	orderEvents := []*zeroex.OrderEvent{}
-
 	targetMaxOrders := int(maxOrdersTrimRatio * float64(w.maxOrders))
-	newMaxExpirationTime, removedOrders, err := w.meshDB.TrimOrdersByExpirationTime(targetMaxOrders)
+	newMaxExpirationTime, removedOrders, err := w.meshDB.TrimOrdersByExpirationTime(targetMaxOrders, w.quotaTopics)
 	if err != nil {
-		return orderEvents, err
+		return []*zeroex.OrderEvent{}, err
 	}
 	if len(removedOrders) > 0 {
 		logger.WithFields(logger.Fields{
@@ -1016,8 +1231,37 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 			"targetMaxOrders":  targetMaxOrders,
 		}).Debug("removing orders to make space")
 	}
+	orderEvents, err := w.stopWatchingEvictedOrders(removedOrders)
+	if err != nil {
+		return orderEvents, err
+	}
+	if newMaxExpirationTime.Cmp(w.maxExpirationTime) == -1 {
+		// Decrease the max expiration time to account for the fact that orders were
+		// removed.
+		logger.WithFields(logger.Fields{
+			"oldMaxExpirationTime": w.maxExpirationTime.String(),
+			"newMaxExpirationTime": newMaxExpirationTime.String(),
+		}).Debug("decreasing max expiration time")
+		w.maxExpirationTime = newMaxExpirationTime
+		w.maxExpirationCounter.Reset(newMaxExpirationTime)
+		w.saveMaxExpirationTime(newMaxExpirationTime)
+	}
+	return orderEvents, nil
+}
+
+// stopWatchingEvictedOrders removes the in-memory state of the given orders,
+// which were removed from the database to make space, stores tombstones for
+// them and returns a STOPPED_WATCHING order event for each of them.
+func (w *Watcher) stopWatchingEvictedOrders(removedOrders []*meshdb.Order) ([]*zeroex.OrderEvent, error) {
+	orderEvents := []*zeroex.OrderEvent{}
 	now := time.Now().UTC()
+	w.evictions.add(now, len(removedOrders))
+	tombstones := make([]*meshdb.Tombstone, 0, len(removedOrders))
 	for _, removedOrder := range removedOrders {
+		// Evicted orders expire too far in the future to be accepted again until
+		// the max expiration time increases.
+		tombstones = append(tombstones, newTombstone(removedOrder, ordervalidator.MeshValidation, ordervalidator.ROMaxExpirationExceeded, now))
+
 		// Fire a "STOPPED_WATCHING" event for each order that was removed.
 		orderEvent := &zeroex.OrderEvent{
 			Timestamp:                now,
@@ -1031,7 +1275,7 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 		// Remove in-memory state
 		expirationTimestamp := time.Unix(removedOrder.SignedOrder.ExpirationTimeSeconds.Int64(), 0)
 		w.expirationWatcher.Remove(expirationTimestamp, removedOrder.Hash.Hex())
-		err = w.removeAssetDataAddressFromEventDecoder(removedOrder.SignedOrder.MakerAssetData)
+		err := w.untrackOrderAddresses(removedOrder.SignedOrder)
 		if err != nil {
 			// This should never happen since the same error would have happened when adding
 			// the assetData to the EventDecoder.
@@ -1042,18 +1286,7 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 			return orderEvents, err
 		}
 	}
-	if newMaxExpirationTime.Cmp(w.maxExpirationTime) == -1 {
-		// Decrease the max expiration time to account for the fact that orders were
-		// removed.
-		logger.WithFields(logger.Fields{
-			"oldMaxExpirationTime": w.maxExpirationTime.String(),
-			"newMaxExpirationTime": newMaxExpirationTime.String(),
-		}).Debug("decreasing max expiration time")
-		w.maxExpirationTime = newMaxExpirationTime
-		w.maxExpirationCounter.Reset(newMaxExpirationTime)
-		w.saveMaxExpirationTime(newMaxExpirationTime)
-	}
-
+	w.addTombstones(tombstones)
 	return orderEvents, nil
 }
 
@@ -1113,6 +1346,45 @@ func updateBlockHeadersStoredInDB(miniHeadersColTxn *db.Transaction, events []*b
 	return nil
 }
 
+// EvictedOrdersLast24h returns the number of orders that were removed within
+// the last 24 hours in order to make space in the database.
+func (w *Watcher) EvictedOrdersLast24h() int {
+	return w.evictions.count(time.Now().UTC())
+}
+
+// PinOrders pins the stored orders with the given hashes, so that they are
+// treated as if they had been added with pinned set to true. Orders which are
+// not stored, removed or already pinned are skipped.
+func (w *Watcher) PinOrders(orderHashes []common.Hash) error {
+	// Block events update the stored orders, so we must not interleave with
+	// them.
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+	for _, orderHash := range orderHashes {
+		order := w.findOrder(orderHash)
+		if order == nil || order.IsRemoved || order.IsPinned {
+			continue
+		}
+		order.IsPinned = true
+		if err := w.meshDB.Orders.Update(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddOrderTopics associates the given pubsub topics with the stored order with
+// the given hash (see meshdb.MeshDB.AddOrderTopics). It returns a
+// db.NotFoundError if the order is not stored.
+func (w *Watcher) AddOrderTopics(orderHash common.Hash, topics []string) error {
+	// Block events update the stored orders, so we must not interleave with
+	// them. Otherwise the topics could be overwritten by an older copy of the
+	// order.
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+	return w.meshDB.AddOrderTopics(orderHash, topics)
+}
+
 // MaxExpirationTime returns the current maximum expiration time for incoming
 // orders.
 func (w *Watcher) MaxExpirationTime() *big.Int {
@@ -1126,6 +1398,10 @@ func (w *Watcher) setupInMemoryOrderState(signedOrder *zeroex.SignedOrder) error
 	}
 	w.eventDecoder.AddKnownExchange(signedOrder.ExchangeAddress)
 
+	w.trackedAddressesMu.Lock()
+	defer w.trackedAddressesMu.Unlock()
+	w.makerAddressToSeenCount[signedOrder.MakerAddress]++
+
 	// Add MakerAssetData and MakerFeeAssetData to EventDecoder
 	err = w.addAssetDataAddressToEventDecoder(signedOrder.MakerAssetData)
 	if err != nil {
@@ -1152,6 +1428,39 @@ func (w *Watcher) Subscribe(sink chan<- []*zeroex.OrderEvent) event.Subscription
 	return w.orderScope.Track(w.orderFeed.Subscribe(sink))
 }
 
+// OrderEventsSince returns the recent order events with a sequence number
+// greater than the given one, which allows subscribers to recover events they
+// missed. The sequence number must belong to the given epoch (see
+// zeroex.OrderEvent). It returns ErrOrderEventsEpochMismatch if the epoch is
+// not the current one and ErrOrderEventsUnavailable if some of the events are
+// no longer retained.
+func (w *Watcher) OrderEventsSince(epoch string, sequenceNumber uint64) ([]*zeroex.OrderEvent, error) {
+	w.orderEventsMu.Lock()
+	defer w.orderEventsMu.Unlock()
+	return w.orderEventLog.since(epoch, sequenceNumber)
+}
+
+// OrderEventsByTransaction returns the retained order events which were caused
+// by the transaction with the given hash (e.g. a fill or a cancellation), which
+// explain why the affected orders changed state. Only the most recent order
+// events are retained, so the result is empty for older transactions.
+func (w *Watcher) OrderEventsByTransaction(txHash common.Hash) []*zeroex.OrderEvent {
+	w.orderEventsMu.Lock()
+	defer w.orderEventsMu.Unlock()
+	return w.orderEventLog.byTransaction(txHash)
+}
+
+// sendOrderEvents assigns sequence numbers to the given order events and sends
+// them to all subscribers.
+func (w *Watcher) sendOrderEvents(orderEvents []*zeroex.OrderEvent) {
+	w.sendOrderEventsMu.Lock()
+	defer w.sendOrderEventsMu.Unlock()
+	w.orderEventsMu.Lock()
+	w.orderEventLog.add(orderEvents)
+	w.orderEventsMu.Unlock()
+	w.orderFeed.Send(orderEvents)
+}
+
 func (w *Watcher) findOrder(orderHash common.Hash) *meshdb.Order {
 	order := meshdb.Order{}
 	err := w.meshDB.Orders.FindByID(orderHash.Bytes(), &order)
@@ -1191,13 +1500,39 @@ func (w *Watcher) findOrdersByTokenAddressAndTokenID(makerAddress, tokenAddress
 	return append(ordersWithAffectedMakerAsset, ordersWithAffectedMakerFeeAsset...), nil
 }
 
+// revertedEndState returns the end state for an order which became more
+// fillable after being re-validated because of the given contract events. If
+// any of the events is a fill or cancellation which was removed by a block
+// re-org, it returns ESOrderFillReverted or ESOrderCancelReverted and true.
+// Otherwise it returns false. Cancellations take precedence since a cancelled
+// order can't have been filled afterwards.
+func revertedEndState(contractEvents []*zeroex.ContractEvent) (zeroex.OrderEventEndState, bool) {
+	fillReverted := false
+	for _, contractEvent := range contractEvents {
+		if !contractEvent.IsRemoved {
+			continue
+		}
+		switch contractEvent.Kind {
+		case "ExchangeCancelEvent", "ExchangeCancelUpToEvent":
+			return zeroex.ESOrderCancelReverted, true
+		case "ExchangeFillEvent":
+			fillReverted = true
+		}
+	}
+	if fillReverted {
+		return zeroex.ESOrderFillReverted, true
+	}
+	return zeroex.ESInvalid, false
+}
+
 func (w *Watcher) convertValidationResultsIntoOrderEvents(
 	ordersColTxn *db.Transaction,
 	validationResults *ordervalidator.ValidationResults,
 	orderHashToDBOrder map[common.Hash]*meshdb.Order,
 	orderHashToEvents map[common.Hash][]*zeroex.ContractEvent,
-	validationBlockTimestamp time.Time,
+	validationBlock *miniheader.MiniHeader,
 ) ([]*zeroex.OrderEvent, error) {
+	validationBlockTimestamp := validationBlock.Timestamp
 	orderEvents := []*zeroex.OrderEvent{}
 	for _, acceptedOrderInfo := range validationResults.Accepted {
 		order, found := orderHashToDBOrder[acceptedOrderInfo.OrderHash]
@@ -1209,6 +1544,9 @@ func (w *Watcher) convertValidationResultsIntoOrderEvents(
 			}).Error("validationResults.Accepted contained unknown order hash")
 			continue
 		}
+		order.LastValidatedBlockNumber = validationBlock.Number
+		order.LastValidatedBlockHash = validationBlock.Hash
+		order.LastValidated = time.Now().UTC()
 		oldFillableAmount := order.FillableTakerAssetAmount
 		newFillableAmount := acceptedOrderInfo.FillableTakerAssetAmount
 		oldAmountIsMoreThenNewAmount := oldFillableAmount.Cmp(newFillableAmount) == 1
@@ -1218,12 +1556,18 @@ func (w *Watcher) convertValidationResultsIntoOrderEvents(
 			// fillableAmount became 0, but it has now been revived (e.g., block re-org
 			// causes order fill txn to get reverted). We need to re-add order and emit an event.
 			w.rewatchOrder(ordersColTxn, order, acceptedOrderInfo.FillableTakerAssetAmount)
+			// If the order was revived because the fill or cancellation which
+			// made it unfillable was reverted, say so explicitly.
+			endState, reverted := revertedEndState(orderHashToEvents[order.Hash])
+			if !reverted {
+				endState = zeroex.ESOrderAdded
+			}
 			orderEvent := &zeroex.OrderEvent{
 				Timestamp:                validationBlockTimestamp,
 				OrderHash:                acceptedOrderInfo.OrderHash,
 				SignedOrder:              order.SignedOrder,
 				FillableTakerAssetAmount: acceptedOrderInfo.FillableTakerAssetAmount,
-				EndState:                 zeroex.ESOrderAdded,
+				EndState:                 endState,
 				ContractEvents:           orderHashToEvents[order.Hash],
 			}
 			orderEvents = append(orderEvents, orderEvent)
@@ -1242,8 +1586,11 @@ func (w *Watcher) convertValidationResultsIntoOrderEvents(
 						EndState:                 zeroex.ESOrderUnexpired,
 					}
 					orderEvents = append(orderEvents, orderEvent)
+				} else {
+					// No important state-change happened, but we still need to record
+					// the block at which the order was validated.
+					w.updateOrderLastValidatedBlock(ordersColTxn, order)
 				}
-				// No important state-change happened
 				continue
 			}
 			if oldFillableAmount.Cmp(big.NewInt(0)) == 1 && oldAmountIsMoreThenNewAmount {
@@ -1289,11 +1636,15 @@ func (w *Watcher) convertValidationResultsIntoOrderEvents(
 					order.FillableTakerAssetAmount = newFillableAmount
 					w.updateOrderDBEntry(ordersColTxn, order)
 				}
+				endState := zeroex.ESOrderFillabilityIncreased
+				if revertedState, reverted := revertedEndState(orderHashToEvents[order.Hash]); reverted && revertedState == zeroex.ESOrderFillReverted {
+					endState = zeroex.ESOrderFillReverted
+				}
 				orderEvent := &zeroex.OrderEvent{
 					Timestamp:                validationBlockTimestamp,
 					OrderHash:                acceptedOrderInfo.OrderHash,
 					SignedOrder:              order.SignedOrder,
-					EndState:                 zeroex.ESOrderFillabilityIncreased,
+					EndState:                 endState,
 					FillableTakerAssetAmount: acceptedOrderInfo.FillableTakerAssetAmount,
 					ContractEvents:           orderHashToEvents[order.Hash],
 				}
@@ -1315,12 +1666,15 @@ func (w *Watcher) convertValidationResultsIntoOrderEvents(
 				}).Error("validationResults.Rejected contained unknown order hash")
 				continue
 			}
+			order.LastValidatedBlockNumber = validationBlock.Number
+			order.LastValidatedBlockHash = validationBlock.Hash
+			order.LastValidated = time.Now().UTC()
 			oldFillableAmount := order.FillableTakerAssetAmount
 			if oldFillableAmount.Cmp(big.NewInt(0)) == 0 {
 				// If the oldFillableAmount was already 0, this order is already flagged for removal.
 			} else {
 				// If oldFillableAmount > 0, it got fullyFilled, cancelled, expired or unfunded
-				w.unwatchOrder(ordersColTxn, order, big.NewInt(0))
+				w.unwatchOrder(ordersColTxn, order, big.NewInt(0), rejectedOrderInfo.Kind, rejectedOrderInfo.Status)
 				endState, ok := ordervalidator.ConvertRejectOrderCodeToOrderEventEndState(rejectedOrderInfo.Status)
 				if !ok {
 					err := fmt.Errorf("no OrderEventEndState corresponding to RejectedOrderStatus: %q", rejectedOrderInfo.Status)
@@ -1352,8 +1706,7 @@ func (w *Watcher) generateOrderEventsIfChanged(
 	ordersColTxn *db.Transaction,
 	orderHashToDBOrder map[common.Hash]*meshdb.Order,
 	orderHashToEvents map[common.Hash][]*zeroex.ContractEvent,
-	validationBlockNumber *big.Int,
-	validationBlockTimestamp time.Time,
+	validationBlock *miniheader.MiniHeader,
 ) ([]*zeroex.OrderEvent, error) {
 	signedOrders := []*zeroex.SignedOrder{}
 	for _, order := range orderHashToDBOrder {
@@ -1369,17 +1722,17 @@ func (w *Watcher) generateOrderEventsIfChanged(
 		return nil, nil
 	}
 	areNewOrders := false
-	validationResults := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, validationBlockNumber)
+	validationResults := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, validationBlock.Number)
 
 	return w.convertValidationResultsIntoOrderEvents(
-		ordersColTxn, validationResults, orderHashToDBOrder, orderHashToEvents, validationBlockTimestamp,
+		ordersColTxn, validationResults, orderHashToDBOrder, orderHashToEvents, validationBlock,
 	)
 }
 
 // ValidateAndStoreValidOrders applies general 0x validation and Mesh-specific validation to
 // the given orders and if they are valid, adds them to the OrderWatcher
 func (w *Watcher) ValidateAndStoreValidOrders(ctx context.Context, orders []*zeroex.SignedOrder, pinned bool, chainID int) (*ordervalidator.ValidationResults, error) {
-	results, validMeshOrders, err := w.meshSpecificOrderValidation(orders, chainID)
+	results, validMeshOrders, annotations, err := w.meshSpecificOrderValidation(orders, chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -1407,8 +1760,12 @@ func (w *Watcher) ValidateAndStoreValidOrders(ctx context.Context, orders []*zer
 	// Add the order to the OrderWatcher. This also saves the order in the
 	// database.
 	allOrderEvents := []*zeroex.OrderEvent{}
-	orderEvents, err := w.add(newOrderInfos, validationBlock.Number, pinned)
-	if err != nil {
+	dbWriteCtx, cancelDBWrite := ordervalidator.WithStageTimeout(ctx, ordervalidator.StageTimeoutsFromContext(ctx).DBWrite)
+	defer cancelDBWrite()
+	orderEvents, err := w.add(dbWriteCtx, newOrderInfos, annotations, validationBlock, pinned)
+	if err == errDBWriteTimedOut {
+		results = rejectTimedOutOrders(results, newOrderInfos)
+	} else if err != nil {
 		return nil, err
 	}
 	allOrderEvents = append(allOrderEvents, orderEvents...)
@@ -1419,7 +1776,7 @@ func (w *Watcher) ValidateAndStoreValidOrders(ctx context.Context, orders []*zer
 		// is done.
 		done := make(chan interface{})
 		go func() {
-			w.orderFeed.Send(allOrderEvents)
+			w.sendOrderEvents(allOrderEvents)
 			done <- struct{}{}
 		}()
 		select {
@@ -1444,17 +1801,48 @@ func (w *Watcher) onchainOrderValidation(ctx context.Context, orders []*zeroex.S
 	if err != nil {
 		return nil, nil, err
 	}
-	// This timeout of 1min is for limiting how long this call should block at the ETH RPC rate limiter
-	ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	// The timeout limits how long this call blocks at the ETH RPC rate limiter
+	// and waits for the Ethereum RPC endpoint.
+	ctx, cancel := ordervalidator.WithStageTimeout(ctx, ordervalidator.StageTimeoutsFromContext(ctx).EthValidation)
 	defer cancel()
 	areNewOrders := true
 	zeroexResults := w.orderValidator.BatchValidate(ctx, orders, areNewOrders, validationBlock.Number)
 	return validationBlock, zeroexResults, nil
 }
 
-func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chainID int) (*ordervalidator.ValidationResults, []*zeroex.SignedOrder, error) {
+// rejectTimedOutOrders moves the given orders, which could not be stored
+// before the DB write timeout was exceeded, from results.Accepted to
+// results.Rejected.
+func rejectTimedOutOrders(results *ordervalidator.ValidationResults, timedOutOrderInfos []*ordervalidator.AcceptedOrderInfo) *ordervalidator.ValidationResults {
+	timedOut := map[common.Hash]struct{}{}
+	for _, orderInfo := range timedOutOrderInfos {
+		timedOut[orderInfo.OrderHash] = struct{}{}
+	}
+	accepted := []*ordervalidator.AcceptedOrderInfo{}
+	for _, orderInfo := range results.Accepted {
+		if _, found := timedOut[orderInfo.OrderHash]; found && orderInfo.IsNew {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:   orderInfo.OrderHash,
+				SignedOrder: orderInfo.SignedOrder,
+				Kind:        ordervalidator.MeshError,
+				Status:      ordervalidator.ROValidationTimedOut,
+				Trace:       orderInfo.Trace,
+			})
+			continue
+		}
+		accepted = append(accepted, orderInfo)
+	}
+	results.Accepted = accepted
+	return results
+}
+
+// meshSpecificOrderValidation returns the results of Mesh-specific validation,
+// the orders which still need to be validated on-chain, and the annotations
+// attached to those orders by the order policy (if any).
+func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chainID int) (*ordervalidator.ValidationResults, []*zeroex.SignedOrder, map[common.Hash]map[string]string, error) {
 	results := &ordervalidator.ValidationResults{}
 	validMeshOrders := []*zeroex.SignedOrder{}
+	annotations := map[common.Hash]map[string]string{}
 	for _, order := range orders {
 		orderHash, err := order.ComputeOrderHash()
 		if err != nil {
@@ -1467,6 +1855,33 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			})
 			continue
 		}
+		// The chain ID and exchange address are checked first since the order
+		// can't be valid on this chain regardless of its other fields.
+		if order.ChainID.Cmp(big.NewInt(int64(chainID))) != 0 {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:   orderHash,
+				SignedOrder: order,
+				Kind:        ordervalidator.MeshValidation,
+				Status:      ordervalidator.ROIncorrectChain,
+			})
+			continue
+		}
+		if err == nil {
+			// Only check the ExchangeAddress if we know the expected address for the
+			// given chainID/networkID. If we don't know it, the order could still be
+			// valid.
+			expectedExchangeAddress := w.contractAddresses.Exchange
+			if order.ExchangeAddress != expectedExchangeAddress {
+				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: order,
+					Kind:        ordervalidator.MeshValidation,
+					Status:      ordervalidator.ROIncorrectExchangeAddress,
+				})
+				continue
+			}
+		}
+
 		if order.ExpirationTimeSeconds.Cmp(w.MaxExpirationTime()) == 1 {
 			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
 				OrderHash:   orderHash,
@@ -1491,31 +1906,24 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			})
 			continue
 		}
-		if order.ChainID.Cmp(big.NewInt(int64(chainID))) != 0 {
+		if status := w.feePolicy.check(order); status != nil {
 			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
 				OrderHash:   orderHash,
 				SignedOrder: order,
 				Kind:        ordervalidator.MeshValidation,
-				Status:      ordervalidator.ROIncorrectChain,
+				Status:      *status,
 			})
 			continue
 		}
-		if err == nil {
-			// Only check the ExchangeAddress if we know the expected address for the
-			// given chainID/networkID. If we don't know it, the order could still be
-			// valid.
-			expectedExchangeAddress := w.contractAddresses.Exchange
-			if order.ExchangeAddress != expectedExchangeAddress {
-				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
-					OrderHash:   orderHash,
-					SignedOrder: order,
-					Kind:        ordervalidator.MeshValidation,
-					Status:      ordervalidator.ROIncorrectExchangeAddress,
-				})
-				continue
-			}
+		if status := w.sizePolicy.check(order); status != nil {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:   orderHash,
+				SignedOrder: order,
+				Kind:        ordervalidator.MeshValidation,
+				Status:      *status,
+			})
+			continue
 		}
-
 		if err := validateOrderSize(order); err != nil {
 			if err == constants.ErrMaxOrderSize {
 				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
@@ -1537,16 +1945,25 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			}
 		}
 
-		// Check if order is already stored in DB
-		var dbOrder meshdb.Order
-		err = w.meshDB.Orders.FindByID(orderHash.Bytes(), &dbOrder)
+		// Check if order is already stored in DB (in either tier)
+		dbOrder, err := w.meshDB.FindOrder(orderHash)
 		if err != nil {
 			if _, ok := err.(db.NotFoundError); !ok {
 				logger.WithField("error", err).Error("could not check if order was already stored")
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			// If the error is a db.NotFoundError, it just means the order is not currently stored in
-			// the database. There's nothing else in the database to check, so we can continue.
+			// the database. If the order was deleted recently, there may be a tombstone for it, in which
+			// case we can reject it without revalidating it.
+			tombstone, err := w.meshDB.FindTombstone(orderHash, time.Now())
+			if err != nil {
+				logger.WithField("error", err).Error("could not check for tombstone")
+				return nil, nil, nil, err
+			}
+			if tombstone != nil {
+				results.Rejected = append(results.Rejected, rejectedOrderInfoFromTombstone(tombstone, orderHash, order))
+				continue
+			}
 		} else {
 			// If stored but flagged for removal, reject it
 			if dbOrder.IsRemoved {
@@ -1569,10 +1986,32 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			}
 		}
 
+		// The order policy is checked last since it is the only check which
+		// can't be done cheaply by Mesh itself.
+		if w.orderPolicy != nil {
+			decision := w.orderPolicy.Evaluate(order)
+			if decision.Reject {
+				status := ordervalidator.ROOrderPolicyRejected
+				if decision.Reason != "" {
+					status.Message += ": " + decision.Reason
+				}
+				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: order,
+					Kind:        ordervalidator.MeshValidation,
+					Status:      status,
+				})
+				continue
+			}
+			if len(decision.Annotations) > 0 {
+				annotations[orderHash] = decision.Annotations
+			}
+		}
+
 		validMeshOrders = append(validMeshOrders, order)
 	}
 
-	return results, validMeshOrders, nil
+	return results, validMeshOrders, annotations, nil
 }
 
 func validateOrderSize(order *zeroex.SignedOrder) error {
@@ -1601,8 +2040,24 @@ func (w *Watcher) updateOrderDBEntry(u orderUpdater, order *meshdb.Order) {
 	}
 }
 
+// updateOrderLastValidatedBlock updates the DB entry for the order without
+// changing LastUpdated. It is used when an order was re-validated but nothing
+// else about it changed.
+func (w *Watcher) updateOrderLastValidatedBlock(u orderUpdater, order *meshdb.Order) {
+	err := u.Update(order)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+			"order": order,
+		}).Error("Failed to update order")
+	}
+}
+
 func (w *Watcher) rewatchOrder(u orderUpdater, order *meshdb.Order, fillableTakerAssetAmount *big.Int) {
 	order.IsRemoved = false
+	order.RemovedKind = ""
+	order.RemovedStatusCode = ""
+	order.RemovedStatusMessage = ""
 	order.LastUpdated = time.Now().UTC()
 	order.FillableTakerAssetAmount = fillableTakerAssetAmount
 	err := u.Update(order)
@@ -1618,8 +2073,14 @@ func (w *Watcher) rewatchOrder(u orderUpdater, order *meshdb.Order, fillableTake
 	w.expirationWatcher.Add(expirationTimestamp, order.Hash.Hex())
 }
 
-func (w *Watcher) unwatchOrder(u orderUpdater, order *meshdb.Order, newFillableAmount *big.Int) {
+// unwatchOrder flags the order for removal. The kind and status describe why
+// the order was removed and are used for its tombstone once it is permanently
+// deleted.
+func (w *Watcher) unwatchOrder(u orderUpdater, order *meshdb.Order, newFillableAmount *big.Int, kind ordervalidator.RejectedOrderKind, status ordervalidator.RejectedOrderStatus) {
 	order.IsRemoved = true
+	order.RemovedKind = string(kind)
+	order.RemovedStatusCode = status.Code
+	order.RemovedStatusMessage = status.Message
 	order.LastUpdated = time.Now().UTC()
 	order.FillableTakerAssetAmount = newFillableAmount
 	err := u.Update(order)
@@ -1654,8 +2115,20 @@ func (w *Watcher) permanentlyDeleteOrder(deleter orderDeleter, order *meshdb.Ord
 		return err
 	}
 
+	// Keep a tombstone so that the order is rejected cheaply if a peer sends it
+	// to us again. Orders which were removed before RemovedStatusCode was
+	// introduced don't get a tombstone.
+	if order.RemovedStatusCode != "" {
+		kind := ordervalidator.RejectedOrderKind(order.RemovedKind)
+		status := ordervalidator.RejectedOrderStatus{
+			Code:    order.RemovedStatusCode,
+			Message: order.RemovedStatusMessage,
+		}
+		w.addTombstones([]*meshdb.Tombstone{newTombstone(order, kind, status, time.Now())})
+	}
+
 	// After permanently deleting an order, we also remove it's assetData from the Decoder
-	err = w.removeAssetDataAddressFromEventDecoder(order.SignedOrder.MakerAssetData)
+	err = w.untrackOrderAddresses(order.SignedOrder)
 	if err != nil {
 		// This should never happen since the same error would have happened when adding
 		// the assetData to the EventDecoder.
@@ -1669,6 +2142,19 @@ func (w *Watcher) permanentlyDeleteOrder(deleter orderDeleter, order *meshdb.Ord
 	return nil
 }
 
+// untrackOrderAddresses removes the maker and the token addresses of an order
+// which is being deleted from the addresses used for decoding and filtering
+// contract events.
+func (w *Watcher) untrackOrderAddresses(signedOrder *zeroex.SignedOrder) error {
+	w.trackedAddressesMu.Lock()
+	defer w.trackedAddressesMu.Unlock()
+	w.makerAddressToSeenCount[signedOrder.MakerAddress]--
+	if w.makerAddressToSeenCount[signedOrder.MakerAddress] == 0 {
+		delete(w.makerAddressToSeenCount, signedOrder.MakerAddress)
+	}
+	return w.removeAssetDataAddressFromEventDecoder(signedOrder.MakerAssetData)
+}
+
 // Logs the error and returns true if the error is non-critical.
 func (w *Watcher) checkDecodeErr(err error, eventType string) bool {
 	if _, ok := err.(decoder.UnsupportedEventError); ok {
@@ -1693,6 +2179,11 @@ func (w *Watcher) checkDecodeErr(err error, eventType string) bool {
 // In order to unregister token addresses when the last order involving it is deleted, we
 // also keep track of the number of tokens seen referencing a particular token address.
 func (w *Watcher) addAssetDataAddressToEventDecoder(assetData []byte) error {
+	if w.orderValidator.IsCustomAssetData(assetData) {
+		// Custom asset types are validated by a custom AssetValidator and we
+		// have no way of knowing which contract events affect them.
+		return nil
+	}
 	assetDataName, err := w.assetDataDecoder.GetName(assetData)
 	if err != nil {
 		return err
@@ -1754,6 +2245,11 @@ func (w *Watcher) addAssetDataAddressToEventDecoder(assetData []byte) error {
 // count, and if it reaches 0 for a given token, it removes the token address from the
 // contract event decoder.
 func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error {
+	if w.orderValidator.IsCustomAssetData(assetData) {
+		// Custom asset types are validated by a custom AssetValidator and we
+		// have no way of knowing which contract events affect them.
+		return nil
+	}
 	assetDataName, err := w.assetDataDecoder.GetName(assetData)
 	if err != nil {
 		return err
@@ -1767,6 +2263,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		}
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
+			delete(w.contractAddressToSeenCount, decodedAssetData.Address)
 			w.eventDecoder.RemoveKnownERC20(decodedAssetData.Address)
 		}
 	case "ERC721Token":
@@ -1777,6 +2274,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		}
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
+			delete(w.contractAddressToSeenCount, decodedAssetData.Address)
 			w.eventDecoder.RemoveKnownERC721(decodedAssetData.Address)
 		}
 	case "ERC1155Assets":
@@ -1787,6 +2285,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		}
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
+			delete(w.contractAddressToSeenCount, decodedAssetData.Address)
 			w.eventDecoder.RemoveKnownERC1155(decodedAssetData.Address)
 		}
 	case "StaticCall":
@@ -1817,7 +2316,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 
 func (w *Watcher) decreaseMaxExpirationTimeIfNeeded() ([]*zeroex.OrderEvent, error) {
 	orderEvents := []*zeroex.OrderEvent{}
-	if orderCount, err := w.meshDB.Orders.Count(); err != nil {
+	if orderCount, err := w.meshDB.CountStoredOrdersExcludingTopics(w.quotaTopics); err != nil {
 		return orderEvents, err
 	} else if orderCount+1 > w.maxOrders {
 		return w.trimOrdersAndGenerateEvents()
@@ -1826,7 +2325,7 @@ func (w *Watcher) decreaseMaxExpirationTimeIfNeeded() ([]*zeroex.OrderEvent, err
 }
 
 func (w *Watcher) increaseMaxExpirationTimeIfPossible() error {
-	if orderCount, err := w.meshDB.Orders.Count(); err != nil {
+	if orderCount, err := w.meshDB.CountStoredOrdersExcludingTopics(w.quotaTopics); err != nil {
 		return err
 	} else if orderCount < w.maxOrders {
 		// We have enough space for new orders. Set the new max expiration time to the
@@ -1855,14 +2354,12 @@ func (w *Watcher) saveMaxExpirationTime(maxExpirationTime *big.Int) {
 	}
 }
 
-func (w *Watcher) getBlockchainState(events []*blockwatch.Event) (*big.Int, time.Time) {
-	var latestBlockNumber *big.Int
-	var latestBlockTimestamp time.Time
+func (w *Watcher) getBlockchainState(events []*blockwatch.Event) *miniheader.MiniHeader {
+	var latestBlock *miniheader.MiniHeader
 	for _, event := range events {
-		latestBlockNumber = event.BlockHeader.Number
-		latestBlockTimestamp = event.BlockHeader.Timestamp
+		latestBlock = event.BlockHeader
 	}
-	return latestBlockNumber, latestBlockTimestamp
+	return latestBlock
 }
 
 // WaitForAtLeastOneBlockToBeProcessed waits until the OrderWatcher has processed it's
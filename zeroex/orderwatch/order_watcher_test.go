@@ -1105,6 +1105,49 @@ func TestOrderWatcherCleanup(t *testing.T) {
 	}
 }
 
+func TestOrderWatcherRevalidateOrders(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	blockWatcher, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
+
+	signedOrder := scenario.NewSignedTestOrder(t, orderopts.SetupMakerState(true))
+	watchOrder(ctx, t, orderWatcher, blockWatcher, ethClient, signedOrder)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	unknownOrderHash := common.HexToHash("0x1")
+
+	// Subscribe to OrderWatcher
+	orderEventsChan := make(chan []*zeroex.OrderEvent, 10)
+	orderWatcher.Subscribe(orderEventsChan)
+
+	results, err := orderWatcher.RevalidateOrders(ctx, []common.Hash{orderHash, unknownOrderHash, orderHash})
+	require.NoError(t, err)
+	require.Len(t, results.Accepted, 1)
+	assert.Equal(t, orderHash, results.Accepted[0].OrderHash)
+	assert.Equal(t, signedOrder.TakerAssetAmount, results.Accepted[0].FillableTakerAssetAmount)
+	require.Len(t, results.Rejected, 1)
+	assert.Equal(t, unknownOrderHash, results.Rejected[0].OrderHash)
+	assert.Equal(t, ordervalidator.ROOrderNotStored, results.Rejected[0].Status)
+
+	// Since the order did not change, we expect no order events to be emitted
+	select {
+	case _ = <-orderEventsChan:
+		t.Error("Expected no orderEvents to fire after calling RevalidateOrders()")
+	case <-time.After(100 * time.Millisecond):
+		// Noop
+	}
+}
+
 func TestOrderWatcherUpdateBlockHeadersStoredInDBHeaderExists(t *testing.T) {
 	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
 	require.NoError(t, err)
@@ -1579,8 +1622,12 @@ func TestConvertValidationResultsIntoOrderEventsUnexpired(t *testing.T) {
 			},
 		},
 	}
-	validationBlockTimestamp := expirationTime.Add(-1 * time.Minute)
-	orderEvents, err = orderWatcher.convertValidationResultsIntoOrderEvents(ordersColTxn, &validationResults, orderHashToDBOrder, orderHashToEvents, validationBlockTimestamp)
+	validationBlock := &miniheader.MiniHeader{
+		Number:    big.NewInt(1),
+		Hash:      common.HexToHash("0x1"),
+		Timestamp: expirationTime.Add(-1 * time.Minute),
+	}
+	orderEvents, err = orderWatcher.convertValidationResultsIntoOrderEvents(ordersColTxn, &validationResults, orderHashToDBOrder, orderHashToEvents, validationBlock)
 	require.NoError(t, err)
 
 	require.Len(t, orderEvents, 2)
@@ -1601,6 +1648,82 @@ func TestConvertValidationResultsIntoOrderEventsUnexpired(t *testing.T) {
 	err = meshDB.Orders.FindByID(orderHash.Bytes(), &existingOrder)
 	require.NoError(t, err)
 	assert.Equal(t, false, existingOrder.IsRemoved)
+	assert.Equal(t, validationBlock.Number, existingOrder.LastValidatedBlockNumber)
+	assert.Equal(t, validationBlock.Hash, existingOrder.LastValidatedBlockHash)
+}
+
+func TestNextRevalidationTime(t *testing.T) {
+	nextCleanupTime := time.Now().Add(10 * time.Minute)
+	orderWatcher := &Watcher{nextCleanupTime: nextCleanupTime}
+
+	testCases := []struct {
+		lastUpdated              time.Time
+		expectedRevalidationTime time.Time
+	}{
+		{
+			// The order will be eligible before the next cleanup.
+			lastUpdated:              nextCleanupTime.Add(-defaultLastUpdatedBuffer),
+			expectedRevalidationTime: nextCleanupTime,
+		},
+		{
+			// The order will not be eligible until shortly after the next cleanup.
+			lastUpdated:              nextCleanupTime.Add(-defaultLastUpdatedBuffer + time.Second),
+			expectedRevalidationTime: nextCleanupTime.Add(minCleanupInterval),
+		},
+		{
+			lastUpdated:              nextCleanupTime.Add(minCleanupInterval),
+			expectedRevalidationTime: nextCleanupTime.Add(2 * minCleanupInterval),
+		},
+	}
+	for i, tc := range testCases {
+		actual := orderWatcher.NextRevalidationTime(&meshdb.Order{LastUpdated: tc.lastUpdated})
+		assert.Equal(t, tc.expectedRevalidationTime, actual, "test case %d", i)
+	}
+}
+
+func TestRevertedEndState(t *testing.T) {
+	fill := &zeroex.ContractEvent{Kind: "ExchangeFillEvent"}
+	removedFill := &zeroex.ContractEvent{Kind: "ExchangeFillEvent", IsRemoved: true}
+	removedCancel := &zeroex.ContractEvent{Kind: "ExchangeCancelEvent", IsRemoved: true}
+	removedCancelUpTo := &zeroex.ContractEvent{Kind: "ExchangeCancelUpToEvent", IsRemoved: true}
+	removedTransfer := &zeroex.ContractEvent{Kind: "ERC20TransferEvent", IsRemoved: true}
+
+	testCases := []struct {
+		contractEvents   []*zeroex.ContractEvent
+		expectedEndState zeroex.OrderEventEndState
+		expectedReverted bool
+	}{
+		{
+			contractEvents:   nil,
+			expectedEndState: zeroex.ESInvalid,
+			expectedReverted: false,
+		},
+		{
+			contractEvents:   []*zeroex.ContractEvent{fill, removedTransfer},
+			expectedEndState: zeroex.ESInvalid,
+			expectedReverted: false,
+		},
+		{
+			contractEvents:   []*zeroex.ContractEvent{removedFill},
+			expectedEndState: zeroex.ESOrderFillReverted,
+			expectedReverted: true,
+		},
+		{
+			contractEvents:   []*zeroex.ContractEvent{removedFill, removedCancel},
+			expectedEndState: zeroex.ESOrderCancelReverted,
+			expectedReverted: true,
+		},
+		{
+			contractEvents:   []*zeroex.ContractEvent{removedCancelUpTo},
+			expectedEndState: zeroex.ESOrderCancelReverted,
+			expectedReverted: true,
+		},
+	}
+	for i, tc := range testCases {
+		endState, reverted := revertedEndState(tc.contractEvents)
+		assert.Equal(t, tc.expectedEndState, endState, "test case %d", i)
+		assert.Equal(t, tc.expectedReverted, reverted, "test case %d", i)
+	}
 }
 
 func TestDrainAllBlockEventsChan(t *testing.T) {
@@ -0,0 +1,92 @@
+package orderwatch
+
+import (
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	log "github.com/sirupsen/logrus"
+)
+
+// PriceOracle returns the value of assets in USD. It is used by SizePolicy to
+// enforce a minimum USD value for new orders. Implementations must be safe for
+// concurrent use and should be fast (e.g. by caching prices), since they are
+// called for every new order.
+type PriceOracle interface {
+	// USDValue returns the value in USD of the given amount (in base units) of
+	// the asset encoded by assetData. found is false if the price of the asset
+	// is unknown.
+	USDValue(assetData []byte, amount *big.Int) (value *big.Float, found bool, err error)
+}
+
+// SizePolicy is an operator-defined policy which rejects new orders whose
+// asset amounts are too small. It can be used by public nodes to avoid storing
+// and propagating dust orders which are not worth filling.
+type SizePolicy struct {
+	// MinMakerAssetAmount, if not nil, is the minimum makerAssetAmount an order
+	// can have.
+	MinMakerAssetAmount *big.Int
+	// MinTakerAssetAmount, if not nil, is the minimum takerAssetAmount an order
+	// can have.
+	MinTakerAssetAmount *big.Int
+	// MinUSDValue, if not nil, is the minimum value in USD of an order, as
+	// determined by PriceOracle. PriceOracle must be set if MinUSDValue is set.
+	MinUSDValue *big.Float
+	// PriceOracle is used to determine the value of orders in USD.
+	PriceOracle PriceOracle
+}
+
+// check returns the status that the given order should be rejected with or nil
+// if the order satisfies the policy. Note that asset amounts are compared in
+// the base units of their respective assets, regardless of what those assets
+// are.
+//
+// The USD value of an order is the value of its maker asset or, if the price
+// of the maker asset is unknown, the value of its taker asset. Orders whose
+// value cannot be determined (because neither price is known or the price
+// oracle returned an error) are accepted, so that an unavailable price oracle
+// doesn't cause every order to be rejected.
+func (p *SizePolicy) check(order *zeroex.SignedOrder) *ordervalidator.RejectedOrderStatus {
+	if p == nil {
+		return nil
+	}
+	if p.MinMakerAssetAmount != nil && order.MakerAssetAmount != nil && order.MakerAssetAmount.Cmp(p.MinMakerAssetAmount) == -1 {
+		return &ordervalidator.ROOrderTooSmall
+	}
+	if p.MinTakerAssetAmount != nil && order.TakerAssetAmount != nil && order.TakerAssetAmount.Cmp(p.MinTakerAssetAmount) == -1 {
+		return &ordervalidator.ROOrderTooSmall
+	}
+	if p.MinUSDValue != nil && p.PriceOracle != nil {
+		value, found := p.usdValue(order)
+		if found && value.Cmp(p.MinUSDValue) == -1 {
+			return &ordervalidator.ROOrderTooSmall
+		}
+	}
+	return nil
+}
+
+// usdValue returns the value of the given order in USD. found is false if the
+// value could not be determined.
+func (p *SizePolicy) usdValue(order *zeroex.SignedOrder) (*big.Float, bool) {
+	assets := []struct {
+		assetData []byte
+		amount    *big.Int
+	}{
+		{order.MakerAssetData, order.MakerAssetAmount},
+		{order.TakerAssetData, order.TakerAssetAmount},
+	}
+	for _, asset := range assets {
+		if asset.amount == nil {
+			continue
+		}
+		value, found, err := p.PriceOracle.USDValue(asset.assetData, asset.amount)
+		if err != nil {
+			log.WithField("error", err.Error()).Warn("price oracle returned an error; accepting order without checking its USD value")
+			return nil, false
+		}
+		if found && value != nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,114 @@
+package orderwatch
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pricedAssetData   = []byte("priced")
+	unpricedAssetData = []byte("unpriced")
+	brokenAssetData   = []byte("broken")
+)
+
+// fakePriceOracle values each base unit of pricedAssetData at $0.01 and
+// doesn't know the price of any other asset.
+type fakePriceOracle struct{}
+
+func (fakePriceOracle) USDValue(assetData []byte, amount *big.Int) (*big.Float, bool, error) {
+	switch string(assetData) {
+	case string(pricedAssetData):
+		value := new(big.Float).SetInt(amount)
+		return value.Quo(value, big.NewFloat(100)), true, nil
+	case string(brokenAssetData):
+		return nil, false, errors.New("price feed unavailable")
+	default:
+		return nil, false, nil
+	}
+}
+
+func TestSizePolicyCheck(t *testing.T) {
+	policy := &SizePolicy{
+		MinMakerAssetAmount: big.NewInt(100),
+		MinTakerAssetAmount: big.NewInt(200),
+	}
+	usdPolicy := &SizePolicy{
+		MinUSDValue: big.NewFloat(5),
+		PriceOracle: fakePriceOracle{},
+	}
+
+	testCases := []struct {
+		policy         *SizePolicy
+		order          *zeroex.SignedOrder
+		expectedStatus *ordervalidator.RejectedOrderStatus
+	}{
+		{
+			policy:         nil,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 1, unpricedAssetData, 1),
+			expectedStatus: nil,
+		},
+		{
+			policy:         policy,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 100, unpricedAssetData, 200),
+			expectedStatus: nil,
+		},
+		{
+			policy:         policy,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 99, unpricedAssetData, 200),
+			expectedStatus: &ordervalidator.ROOrderTooSmall,
+		},
+		{
+			policy:         policy,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 100, unpricedAssetData, 199),
+			expectedStatus: &ordervalidator.ROOrderTooSmall,
+		},
+		// The maker asset is worth $5.
+		{
+			policy:         usdPolicy,
+			order:          newSizePolicyTestOrder(pricedAssetData, 500, unpricedAssetData, 1),
+			expectedStatus: nil,
+		},
+		// The maker asset is worth $4.99.
+		{
+			policy:         usdPolicy,
+			order:          newSizePolicyTestOrder(pricedAssetData, 499, unpricedAssetData, 1),
+			expectedStatus: &ordervalidator.ROOrderTooSmall,
+		},
+		// The price of the maker asset is unknown so the taker asset is used.
+		{
+			policy:         usdPolicy,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 1000, pricedAssetData, 1),
+			expectedStatus: &ordervalidator.ROOrderTooSmall,
+		},
+		// Orders whose value is unknown are accepted.
+		{
+			policy:         usdPolicy,
+			order:          newSizePolicyTestOrder(unpricedAssetData, 1, unpricedAssetData, 1),
+			expectedStatus: nil,
+		},
+		{
+			policy:         usdPolicy,
+			order:          newSizePolicyTestOrder(brokenAssetData, 1, pricedAssetData, 1),
+			expectedStatus: nil,
+		},
+	}
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expectedStatus, tc.policy.check(tc.order), "test case %d", i)
+	}
+}
+
+func newSizePolicyTestOrder(makerAssetData []byte, makerAssetAmount int64, takerAssetData []byte, takerAssetAmount int64) *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			MakerAssetData:   makerAssetData,
+			MakerAssetAmount: big.NewInt(makerAssetAmount),
+			TakerAssetData:   takerAssetData,
+			TakerAssetAmount: big.NewInt(takerAssetAmount),
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package orderwatch
+
+import (
+	"time"
+
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// recoverableTombstoneTTL is how long tombstones are kept for orders which
+	// could become valid again (e.g. orders which became unfunded or were
+	// evicted to make space).
+	recoverableTombstoneTTL = 10 * time.Minute
+	// permanentTombstoneTTL is how long tombstones are kept for orders which
+	// can never become valid again (e.g. orders which were cancelled or fully
+	// filled). Slow peers are unlikely to re-gossip an order after this long.
+	permanentTombstoneTTL = 24 * time.Hour
+)
+
+// newTombstone returns a tombstone for an order which was removed with the
+// given kind and status.
+func newTombstone(order *meshdb.Order, kind ordervalidator.RejectedOrderKind, status ordervalidator.RejectedOrderStatus, now time.Time) *meshdb.Tombstone {
+	ttl := permanentTombstoneTTL
+	switch status.Code {
+	case ordervalidator.ROUnfunded.Code, ordervalidator.ROMaxExpirationExceeded.Code:
+		ttl = recoverableTombstoneTTL
+	}
+	return &meshdb.Tombstone{
+		Hash:          order.Hash,
+		Kind:          string(kind),
+		StatusCode:    status.Code,
+		StatusMessage: status.Message,
+		ExpiresAt:     now.Add(ttl),
+	}
+}
+
+// rejectedOrderInfoFromTombstone returns the RejectedOrderInfo for an order
+// which is rejected because of the given tombstone.
+func rejectedOrderInfoFromTombstone(tombstone *meshdb.Tombstone, orderHash common.Hash, order *zeroex.SignedOrder) *ordervalidator.RejectedOrderInfo {
+	return &ordervalidator.RejectedOrderInfo{
+		OrderHash:   orderHash,
+		SignedOrder: order,
+		Kind:        ordervalidator.RejectedOrderKind(tombstone.Kind),
+		Status: ordervalidator.RejectedOrderStatus{
+			Code:    tombstone.StatusCode,
+			Message: tombstone.StatusMessage,
+		},
+	}
+}
+
+// addTombstones stores the given tombstones. Tombstones are only an
+// optimization, so errors are logged instead of returned.
+func (w *Watcher) addTombstones(tombstones []*meshdb.Tombstone) {
+	if err := w.meshDB.AddTombstones(tombstones); err != nil {
+		logger.WithFields(logger.Fields{
+			"error":         err.Error(),
+			"numTombstones": len(tombstones),
+		}).Error("could not store tombstones for removed orders")
+	}
+}
+
+// deleteExpiredTombstones deletes all tombstones which have expired.
+func (w *Watcher) deleteExpiredTombstones() error {
+	numDeleted, err := w.meshDB.DeleteExpiredTombstones(time.Now())
+	if err != nil {
+		return err
+	}
+	if numDeleted > 0 {
+		logger.WithField("numDeleted", numDeleted).Debug("deleted expired tombstones")
+	}
+	return nil
+}
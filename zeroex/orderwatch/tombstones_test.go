@@ -0,0 +1,55 @@
+package orderwatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTombstone(t *testing.T) {
+	now := time.Now()
+	order := &meshdb.Order{Hash: common.HexToHash("0x1")}
+	testCases := []struct {
+		kind        ordervalidator.RejectedOrderKind
+		status      ordervalidator.RejectedOrderStatus
+		expectedTTL time.Duration
+	}{
+		{
+			kind:        ordervalidator.ZeroExValidation,
+			status:      ordervalidator.ROCancelled,
+			expectedTTL: permanentTombstoneTTL,
+		},
+		{
+			kind:        ordervalidator.ZeroExValidation,
+			status:      ordervalidator.ROFullyFilled,
+			expectedTTL: permanentTombstoneTTL,
+		},
+		{
+			kind:        ordervalidator.ZeroExValidation,
+			status:      ordervalidator.ROExpired,
+			expectedTTL: permanentTombstoneTTL,
+		},
+		{
+			kind:        ordervalidator.ZeroExValidation,
+			status:      ordervalidator.ROUnfunded,
+			expectedTTL: recoverableTombstoneTTL,
+		},
+		{
+			kind:        ordervalidator.MeshValidation,
+			status:      ordervalidator.ROMaxExpirationExceeded,
+			expectedTTL: recoverableTombstoneTTL,
+		},
+	}
+	for _, tc := range testCases {
+		tombstone := newTombstone(order, tc.kind, tc.status, now)
+		assert.Equal(t, order.Hash, tombstone.Hash, tc.status.Code)
+		rejectedOrderInfo := rejectedOrderInfoFromTombstone(tombstone, order.Hash, nil)
+		assert.Equal(t, tc.kind, rejectedOrderInfo.Kind, tc.status.Code)
+		assert.Equal(t, tc.status, rejectedOrderInfo.Status, tc.status.Code)
+		assert.Equal(t, now.Add(tc.expectedTTL), tombstone.ExpiresAt, tc.status.Code)
+	}
+}
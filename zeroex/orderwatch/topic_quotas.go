@@ -0,0 +1,62 @@
+package orderwatch
+
+import (
+	"sort"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	logger "github.com/sirupsen/logrus"
+)
+
+// sortedQuotaTopics returns the topics in the given quotas in sorted order.
+func sortedQuotaTopics(topicQuotas map[string]int) []string {
+	topics := make([]string, 0, len(topicQuotas))
+	for topic, maxOrders := range topicQuotas {
+		if maxOrders > 0 {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// TrimOrdersOverTopicQuotas evicts the orders with the highest expiration time
+// on each topic in Config.TopicQuotas which has more orders than its quota
+// (e.g. because the quota was lowered) until the number of orders on the topic
+// is back to maxOrdersTrimRatio times the quota. Orders on other topics are not
+// affected, so one busy topic can't evict the orders of another one. Block
+// events are not processed while orders are being evicted.
+func (w *Watcher) TrimOrdersOverTopicQuotas() error {
+	w.handleBlockEventsMu.Lock()
+	defer w.handleBlockEventsMu.Unlock()
+
+	allOrderEvents := []*zeroex.OrderEvent{}
+	for _, topic := range w.quotaTopics {
+		maxOrders := w.topicQuotas[topic]
+		numOrders, err := w.meshDB.CountOrdersByTopic(topic)
+		if err != nil {
+			return err
+		}
+		if numOrders <= maxOrders {
+			continue
+		}
+		targetMaxOrders := int(maxOrdersTrimRatio * float64(maxOrders))
+		removedOrders, err := w.meshDB.TrimOrdersByTopic(topic, targetMaxOrders)
+		if err != nil {
+			return err
+		}
+		logger.WithFields(logger.Fields{
+			"topic":            topic,
+			"numOrdersRemoved": len(removedOrders),
+			"targetMaxOrders":  targetMaxOrders,
+		}).Debug("removing orders to make space on topic")
+		orderEvents, err := w.stopWatchingEvictedOrders(removedOrders)
+		allOrderEvents = append(allOrderEvents, orderEvents...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(allOrderEvents) > 0 {
+		w.sendOrderEvents(allOrderEvents)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package zeroex
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrSignatureNotRecoverable is returned by RecoverSigner if the signer of a
+	// signature cannot be determined off-chain (e.g. for Wallet or Validator
+	// signatures).
+	ErrSignatureNotRecoverable = errors.New("signer cannot be recovered for this signature type")
+	// ErrInvalidSignatureLength is returned by RecoverSigner if an EIP712 or
+	// EthSign signature does not have the expected length.
+	ErrInvalidSignatureLength = errors.New("invalid signature length")
+	// ErrInvalidSignatureV is returned by RecoverSigner if the V value of an
+	// EIP712 or EthSign signature is not 27 or 28.
+	ErrInvalidSignatureV = errors.New("invalid signature V value")
+)
+
+// ethSignPrefix is prepended to the order hash before it is signed with
+// `eth_sign`.
+var ethSignPrefix = []byte("\x19Ethereum Signed Message:\n32")
+
+var (
+	// secp256k1N is the order of the secp256k1 curve.
+	secp256k1N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	// secp256k1HalfN is half of the order of the secp256k1 curve. Canonical
+	// signatures have an S value which is less than or equal to it.
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// NormalizeSignature returns the canonical form of the given 0x signature.
+// EIP712 and EthSign signatures are canonically encoded as
+// [V || R || S || type] where V is 27 or 28 and S is in the lower half of the
+// curve order. The following non-canonical encodings are converted to the
+// canonical one:
+//
+//   - EIP-2098 compact signatures encoded as [R || YParityAndS || type]
+//   - Signatures where V is 0 or 1 instead of 27 or 28
+//   - Signatures where S is in the upper half of the curve order
+//
+// The canonical form recovers the same signer as the original. All other
+// signatures, including invalid ones, are returned unchanged so that they are
+// rejected by the usual validation. The given signature is never modified.
+func NormalizeSignature(signature []byte) []byte {
+	if !IsRecoverableSignature(signature) {
+		return signature
+	}
+	var normalized []byte
+	switch len(signature) {
+	case 65:
+		// In an EIP-2098 compact signature, the highest bit of the second word
+		// is the Y parity (i.e. V - 27) and the remaining bits are S.
+		normalized = make([]byte, 66)
+		normalized[0] = 27 + signature[32]>>7
+		copy(normalized[1:33], signature[0:32])
+		copy(normalized[33:65], signature[32:64])
+		normalized[33] &= 0x7f
+		normalized[65] = signature[64]
+	case 66:
+		normalized = make([]byte, 66)
+		copy(normalized, signature)
+		if normalized[0] == 0 || normalized[0] == 1 {
+			normalized[0] += 27
+		}
+	default:
+		return signature
+	}
+	if normalized[0] != 27 && normalized[0] != 28 {
+		return signature
+	}
+	// Signatures are malleable: (R, N - S) with the opposite Y parity is also a
+	// valid signature for the same signer.
+	sValue := new(big.Int).SetBytes(normalized[33:65])
+	if sValue.Cmp(secp256k1HalfN) > 0 && sValue.Cmp(secp256k1N) < 0 {
+		sValue.Sub(secp256k1N, sValue)
+		copy(normalized[33:65], common.LeftPadBytes(sValue.Bytes(), 32))
+		if normalized[0] == 27 {
+			normalized[0] = 28
+		} else {
+			normalized[0] = 27
+		}
+	}
+	return normalized
+}
+
+// IsRecoverableSignature returns true if the signer of the given 0x signature
+// can be recovered off-chain (i.e. it is an EIP712 or EthSign signature).
+func IsRecoverableSignature(signature []byte) bool {
+	if len(signature) == 0 {
+		return false
+	}
+	switch SignatureType(signature[len(signature)-1]) {
+	case EIP712Signature, EthSignSignature:
+		return true
+	default:
+		return false
+	}
+}
+
+// RecoverSigner returns the address that produced the given 0x signature for
+// the given order hash. Only EIP712 and EthSign signatures are supported. For
+// all other signature types, ErrSignatureNotRecoverable is returned.
+func RecoverSigner(orderHash common.Hash, signature []byte) (common.Address, error) {
+	if !IsRecoverableSignature(signature) {
+		return common.Address{}, ErrSignatureNotRecoverable
+	}
+	// 0x EIP712 and EthSign signatures are encoded as [V || R || S || type].
+	if len(signature) != 66 {
+		return common.Address{}, ErrInvalidSignatureLength
+	}
+	v := signature[0]
+	if v != 27 && v != 28 {
+		return common.Address{}, ErrInvalidSignatureV
+	}
+	var hash []byte
+	if SignatureType(signature[65]) == EthSignSignature {
+		hash = keccak256(ethSignPrefix, orderHash.Bytes())
+	} else {
+		hash = orderHash.Bytes()
+	}
+	return ecrecover(hash, signature[0:65])
+}
+
+// SignerRecoveryResult is the result of recovering the signer of a single
+// order.
+type SignerRecoveryResult struct {
+	Signer common.Address
+	Err    error
+	// Duration is the time it took to recover the signer.
+	Duration time.Duration
+}
+
+// BatchRecoverSigners recovers the signers of the given orders using up to
+// numWorkers goroutines. If numWorkers is less than or equal to zero,
+// runtime.NumCPU() workers are used. The i-th result corresponds to the i-th
+// order. The orders must be distinct, since computing the order hash caches
+// it on the order.
+func BatchRecoverSigners(orders []*SignedOrder, numWorkers int) []SignerRecoveryResult {
+	results := make([]SignerRecoveryResult, len(orders))
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	if numWorkers > len(orders) {
+		numWorkers = len(orders)
+	}
+	// Workers pull the index of the next order to process from a shared
+	// counter. This balances the load across workers without the overhead of a
+	// channel send per order.
+	next := int64(-1)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				j := int(atomic.AddInt64(&next, 1))
+				if j >= len(orders) {
+					return
+				}
+				results[j] = recoverOrderSigner(orders[j])
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func recoverOrderSigner(order *SignedOrder) SignerRecoveryResult {
+	start := time.Now()
+	orderHash, err := order.ComputeOrderHash()
+	if err != nil {
+		return SignerRecoveryResult{Err: err, Duration: time.Since(start)}
+	}
+	signer, err := RecoverSigner(orderHash, order.Signature)
+	return SignerRecoveryResult{Signer: signer, Err: err, Duration: time.Since(start)}
+}
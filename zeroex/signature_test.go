@@ -0,0 +1,251 @@
+package zeroex
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverSignerEthSign(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+
+	signer, err := RecoverSigner(orderHash, signedOrder.Signature)
+	require.NoError(t, err)
+	assert.Equal(t, testOrder.MakerAddress, signer)
+}
+
+func TestRecoverSignerEIP712(t *testing.T) {
+	orderHash, err := testOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	privateKey, err := crypto.ToECDSA(constants.GanacheAccountToPrivateKey[testOrder.MakerAddress])
+	require.NoError(t, err)
+	ecSignature, err := crypto.Sign(orderHash.Bytes(), privateKey)
+	require.NoError(t, err)
+
+	signature := make([]byte, 66)
+	signature[0] = ecSignature[64] + 27
+	copy(signature[1:65], ecSignature[0:64])
+	signature[65] = byte(EIP712Signature)
+
+	signer, err := RecoverSigner(orderHash, signature)
+	require.NoError(t, err)
+	assert.Equal(t, testOrder.MakerAddress, signer)
+}
+
+func TestRecoverSignerErrors(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+
+	_, err = RecoverSigner(orderHash, []byte{byte(PreSignedSignature)})
+	assert.Equal(t, ErrSignatureNotRecoverable, err)
+
+	_, err = RecoverSigner(orderHash, signedOrder.Signature[1:])
+	assert.Equal(t, ErrInvalidSignatureLength, err)
+
+	invalidV := make([]byte, len(signedOrder.Signature))
+	copy(invalidV, signedOrder.Signature)
+	invalidV[0] = 29
+	_, err = RecoverSigner(orderHash, invalidV)
+	assert.Equal(t, ErrInvalidSignatureV, err)
+
+	// Signing a different hash should recover a different address.
+	signer, err := RecoverSigner(common.HexToHash("0x1"), signedOrder.Signature)
+	require.NoError(t, err)
+	assert.NotEqual(t, testOrder.MakerAddress, signer)
+}
+
+func TestNormalizeSignature(t *testing.T) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(t, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	canonical := signedOrder.Signature
+	v, r, s, signatureType := canonical[0], canonical[1:33], canonical[33:65], canonical[65]
+
+	compact := make([]byte, 65)
+	copy(compact[0:32], r)
+	copy(compact[32:64], s)
+	compact[32] |= (v - 27) << 7
+	compact[64] = signatureType
+
+	zeroBasedV := make([]byte, 66)
+	copy(zeroBasedV, canonical)
+	zeroBasedV[0] = v - 27
+
+	highS := make([]byte, 66)
+	copy(highS, canonical)
+	highS[0] = 55 - v
+	copy(highS[33:65], common.LeftPadBytes(new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(s)).Bytes(), 32))
+
+	highSAndZeroBasedV := make([]byte, 66)
+	copy(highSAndZeroBasedV, highS)
+	highSAndZeroBasedV[0] -= 27
+
+	invalidV := make([]byte, 66)
+	copy(invalidV, canonical)
+	invalidV[0] = 29
+
+	// A signature which is one byte longer than a canonical one, but still
+	// ends with the signature type.
+	tooLong := make([]byte, 67)
+	copy(tooLong, canonical[:65])
+	tooLong[66] = canonical[65]
+
+	testCases := []struct {
+		name      string
+		signature []byte
+		expected  []byte
+	}{
+		{
+			name:      "canonical",
+			signature: canonical,
+			expected:  canonical,
+		},
+		{
+			name:      "compact",
+			signature: compact,
+			expected:  canonical,
+		},
+		{
+			name:      "zero-based V",
+			signature: zeroBasedV,
+			expected:  canonical,
+		},
+		{
+			name:      "high S",
+			signature: highS,
+			expected:  canonical,
+		},
+		{
+			name:      "high S and zero-based V",
+			signature: highSAndZeroBasedV,
+			expected:  canonical,
+		},
+		{
+			name:      "invalid V",
+			signature: invalidV,
+			expected:  invalidV,
+		},
+		{
+			name:      "invalid length",
+			signature: tooLong,
+			expected:  tooLong,
+		},
+		{
+			name:      "not recoverable",
+			signature: []byte{byte(PreSignedSignature)},
+			expected:  []byte{byte(PreSignedSignature)},
+		},
+	}
+	for _, tc := range testCases {
+		original := make([]byte, len(tc.signature))
+		copy(original, tc.signature)
+		normalized := NormalizeSignature(tc.signature)
+		assert.Equal(t, tc.expected, normalized, tc.name)
+		assert.Equal(t, original, tc.signature, "%s: signature was modified", tc.name)
+	}
+
+	// Every non-canonical form should recover the same signer as the canonical
+	// one.
+	for _, signature := range [][]byte{compact, zeroBasedV, highS, highSAndZeroBasedV} {
+		signer, err := RecoverSigner(orderHash, NormalizeSignature(signature))
+		require.NoError(t, err)
+		assert.Equal(t, testOrder.MakerAddress, signer)
+	}
+}
+
+func TestBatchRecoverSigners(t *testing.T) {
+	signedOrders := newSignedTestOrders(t, 50)
+	// Replace one signature with a type that can't be recovered.
+	signedOrders[10].Signature = []byte{byte(PreSignedSignature)}
+
+	for _, numWorkers := range []int{0, 1, 4, 100} {
+		results := BatchRecoverSigners(signedOrders, numWorkers)
+		require.Len(t, results, len(signedOrders))
+		for i, result := range results {
+			if i == 10 {
+				assert.Equal(t, ErrSignatureNotRecoverable, result.Err, "numWorkers: %d", numWorkers)
+				continue
+			}
+			require.NoError(t, result.Err, "numWorkers: %d, order: %d", numWorkers, i)
+			assert.Equal(t, signedOrders[i].MakerAddress, result.Signer, "numWorkers: %d, order: %d", numWorkers, i)
+		}
+	}
+}
+
+func newSignedTestOrders(t testing.TB, n int) []*SignedOrder {
+	signedOrders := make([]*SignedOrder, n)
+	for i := 0; i < n; i++ {
+		order := *testOrder
+		order.Salt = big.NewInt(int64(i))
+		signedOrder, err := SignTestOrder(&order)
+		require.NoError(t, err)
+		signedOrders[i] = signedOrder
+	}
+	return signedOrders
+}
+
+const benchmarkSignatureOrders = 1000
+
+func BenchmarkComputeOrderHash(b *testing.B) {
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// ComputeOrderHash caches the hash, so we need to reset it each time.
+		signedOrder.ResetHash()
+		if _, err := signedOrder.ComputeOrderHash(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverSigner(b *testing.B) {
+	b.Logf("secp256k1 implementation: %s", SignerRecoveryImplementation)
+	signedOrder, err := SignTestOrder(testOrder)
+	require.NoError(b, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RecoverSigner(orderHash, signedOrder.Signature); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverSignersSequential(b *testing.B) {
+	signedOrders := newSignedTestOrders(b, benchmarkSignatureOrders)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		for _, signedOrder := range signedOrders {
+			orderHash, _ := signedOrder.ComputeOrderHash()
+			if _, err := RecoverSigner(orderHash, signedOrder.Signature); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.ReportMetric(float64(b.N*benchmarkSignatureOrders)/time.Since(start).Seconds(), "orders/s")
+}
+
+func BenchmarkBatchRecoverSigners(b *testing.B) {
+	signedOrders := newSignedTestOrders(b, benchmarkSignatureOrders)
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		BatchRecoverSigners(signedOrders, 0)
+	}
+	b.ReportMetric(float64(b.N*benchmarkSignatureOrders)/time.Since(start).Seconds(), "orders/s")
+}